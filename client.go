@@ -2,8 +2,11 @@ package x402
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"sync"
+	"time"
 
 	"x402-go/types"
 )
@@ -25,6 +28,12 @@ type x402Client struct {
 	beforePaymentCreationHooks    []BeforePaymentCreationHook
 	afterPaymentCreationHooks     []AfterPaymentCreationHook
 	onPaymentCreationFailureHooks []OnPaymentCreationFailureHook
+
+	// Optional tracing (nil = disabled)
+	tracer Tracer
+
+	// Logger for payload creation (no-op by default)
+	logger Logger
 }
 
 // ClientOption configures the client
@@ -44,6 +53,21 @@ func WithPolicy(policy PaymentPolicy) ClientOption {
 	}
 }
 
+// WithClientTracer traces payment payload creation. The payload carries the client's trace
+// context in its extensions so the resource server and facilitator can join the same trace.
+func WithClientTracer(tracer Tracer) ClientOption {
+	return func(c *x402Client) {
+		c.tracer = tracer
+	}
+}
+
+// WithClientLogger logs payment payload creation: failures at Warn and created payloads at Debug
+func WithClientLogger(logger Logger) ClientOption {
+	return func(c *x402Client) {
+		c.logger = loggerOrNoop(logger)
+	}
+}
+
 // Newx402Client creates a new x402 client
 func Newx402Client(opts ...ClientOption) *x402Client {
 	c := &x402Client{
@@ -51,6 +75,7 @@ func Newx402Client(opts ...ClientOption) *x402Client {
 		schemes:              make(map[Network]map[string]SchemeNetworkClient),
 		requirementsSelector: DefaultPaymentSelector,
 		policies:             []PaymentPolicy{},
+		logger:               noopLogger{},
 	}
 
 	for _, opt := range opts {
@@ -60,27 +85,27 @@ func Newx402Client(opts ...ClientOption) *x402Client {
 	return c
 }
 
-// RegisterV1 registers a V1 payment mechanism
+// RegisterV1 registers a V1 payment mechanism.
+// Panics with a *NetworkPatternError if network is malformed (see ValidateNetworkPatternV1).
 func (c *x402Client) RegisterV1(network Network, client SchemeNetworkClientV1) *x402Client {
+	mustValidateNetworks(ValidateNetworkPatternV1, network)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.schemesV1[network] == nil {
-		c.schemesV1[network] = make(map[string]SchemeNetworkClientV1)
-	}
-	c.schemesV1[network][client.Scheme()] = client
+	registerScheme(c.schemesV1, network, client.Scheme(), client, c.logger)
 	return c
 }
 
-// Register registers a payment mechanism (V2, default)
+// Register registers a payment mechanism (V2, default).
+// Panics with a *NetworkPatternError if network is malformed (see ValidateNetworkPattern).
 func (c *x402Client) Register(network Network, client SchemeNetworkClient) *x402Client {
+	mustValidateNetworks(ValidateNetworkPattern, network)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.schemes[network] == nil {
-		c.schemes[network] = make(map[string]SchemeNetworkClient)
-	}
-	c.schemes[network][client.Scheme()] = client
+	registerScheme(c.schemes, network, client.Scheme(), client, c.logger)
 	return c
 }
 
@@ -165,6 +190,37 @@ func (c *x402Client) SelectPaymentRequirements(requirements []types.PaymentRequi
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	filtered, err := c.filterPaymentRequirements(requirements)
+	if err != nil {
+		return types.PaymentRequirements{}, err
+	}
+
+	// Select final and convert back
+	selected := c.requirementsSelector(toViews(filtered))
+	return fromView[types.PaymentRequirements](selected), nil
+}
+
+// SelectPaymentRequirementsWith selects a payment requirement (V2) using strategy instead of the
+// client's selector. Requirements are filtered by registered schemes and policies first.
+// A nil strategy uses the client's selector.
+func (c *x402Client) SelectPaymentRequirementsWith(requirements []types.PaymentRequirements, strategy SelectionStrategy) (types.PaymentRequirements, error) {
+	if strategy == nil {
+		return c.SelectPaymentRequirements(requirements)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	filtered, err := c.filterPaymentRequirements(requirements)
+	if err != nil {
+		return types.PaymentRequirements{}, err
+	}
+	return strategy(filtered)
+}
+
+// filterPaymentRequirements keeps the V2 requirements with a registered scheme that pass every policy.
+// Callers must hold c.mu.
+func (c *x402Client) filterPaymentRequirements(requirements []types.PaymentRequirements) ([]types.PaymentRequirements, error) {
 	// Filter to supported (use wildcard matching helper)
 	var supported []types.PaymentRequirements
 	for _, req := range requirements {
@@ -178,40 +234,51 @@ func (c *x402Client) SelectPaymentRequirements(requirements []types.PaymentRequi
 	}
 
 	if len(supported) == 0 {
-		return types.PaymentRequirements{}, &PaymentError{
+		return nil, &PaymentError{
 			Code:    ErrCodeUnsupportedScheme,
 			Message: "no supported payment schemes available",
 		}
 	}
 
-	// Convert to views for selector/policies
-	views := toViews(supported)
+	// Convert to views for policies
+	filtered := toViews(supported)
 
 	// Apply policies
-	filtered := views
 	for _, policy := range c.policies {
 		filtered = policy(filtered)
 		if len(filtered) == 0 {
-			return types.PaymentRequirements{}, &PaymentError{
+			return nil, &PaymentError{
 				Code:    ErrCodeUnsupportedScheme,
 				Message: "all payment requirements were filtered out by policies",
 			}
 		}
 	}
 
-	// Select final and convert back
-	selected := c.requirementsSelector(filtered)
-	return fromView[types.PaymentRequirements](selected), nil
+	result := make([]types.PaymentRequirements, len(filtered))
+	for i, view := range filtered {
+		result[i] = fromView[types.PaymentRequirements](view)
+	}
+	return result, nil
 }
 
 // CreatePaymentPayloadV1 creates a V1 payment payload
 func (c *x402Client) CreatePaymentPayloadV1(
 	ctx context.Context,
 	requirements types.PaymentRequirementsV1,
-) (types.PaymentPayloadV1, error) {
+) (payload types.PaymentPayloadV1, err error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	ctx, span := StartSpan(ctx, c.tracer, "x402.client.CreatePaymentPayload")
+	setSpanAttribute(span, AttrScheme, requirements.Scheme)
+	setSpanAttribute(span, AttrNetwork, requirements.Network)
+	setSpanAttribute(span, AttrAmount, requirements.MaxAmountRequired)
+	defer func() {
+		reason := paymentErrorReason(err)
+		endSpan(span, "", reason, err)
+		c.logPayload(requirements.Scheme, string(requirements.Network), reason, err)
+	}()
+
 	// Direct field access for routing
 	scheme := requirements.Scheme
 	network := Network(requirements.Network)
@@ -242,10 +309,22 @@ func (c *x402Client) CreatePaymentPayload(
 	requirements types.PaymentRequirements,
 	resource *types.ResourceInfo,
 	extensions map[string]interface{},
-) (types.PaymentPayload, error) {
+) (payload types.PaymentPayload, err error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	// Join the resource server's trace when the caller isn't already tracing
+	ctx = ExtractTraceContext(ctx, c.tracer, extensions)
+	ctx, span := StartSpan(ctx, c.tracer, "x402.client.CreatePaymentPayload")
+	setSpanAttribute(span, AttrScheme, requirements.Scheme)
+	setSpanAttribute(span, AttrNetwork, requirements.Network)
+	setSpanAttribute(span, AttrAmount, requirements.Amount)
+	defer func() {
+		reason := paymentErrorReason(err)
+		endSpan(span, "", reason, err)
+		c.logPayload(requirements.Scheme, string(requirements.Network), reason, err)
+	}()
+
 	scheme := requirements.Scheme
 	network := Network(requirements.Network)
 
@@ -275,11 +354,231 @@ func (c *x402Client) CreatePaymentPayload(
 	// Wrap with accepted/resource/extensions
 	partial.Accepted = requirements
 	partial.Resource = resource
-	partial.Extensions = extensions
+	partial.Extensions = InjectTraceContext(ctx, c.tracer, extensions)
 
 	return partial, nil
 }
 
+// CreatePaymentPayloadFor selects one of required's accepted requirements and creates its
+// payment payload (V2), running the payment creation hooks. With fallback, a recoverable
+// creation failure (e.g. the network's RPC is down or the balance is too low) moves on to the
+// next supported requirement, in the server's order, until one succeeds; the last failure is
+// returned when none does. Failures stop the fallback once ctx is done, and hook errors and
+// aborts always do.
+//
+// Hooks run for every attempt and see its index in PaymentCreationContext.Attempt.
+func (c *x402Client) CreatePaymentPayloadFor(
+	ctx context.Context,
+	required types.PaymentRequired,
+	fallback bool,
+) (types.PaymentPayload, error) {
+	c.mu.RLock()
+	filtered, err := c.filterPaymentRequirements(required.Accepts)
+	if err != nil {
+		c.mu.RUnlock()
+		return types.PaymentPayload{}, err
+	}
+	selected := fromView[types.PaymentRequirements](c.requirementsSelector(toViews(filtered)))
+	c.mu.RUnlock()
+
+	candidates := []types.PaymentRequirements{selected}
+	if fallback {
+		candidates = append(candidates, withoutRequirement(filtered, selected)...)
+	}
+
+	var lastErr error
+	for attempt, requirements := range candidates {
+		payload, retry, err := c.createPaymentPayloadAttempt(ctx, attempt, requirements, required.Resource, required.Extensions)
+		if err == nil {
+			return payload, nil
+		}
+		lastErr = err
+		if !retry || ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			break
+		}
+	}
+	return types.PaymentPayload{}, lastErr
+}
+
+// PayloadExpiry returns when a payment payload (V2) stops being accepted, as reported by the
+// mechanism registered for its accepted network and scheme. It returns false when that
+// mechanism doesn't implement PayloadExpirer or the payload carries no expiry.
+func (c *x402Client) PayloadExpiry(payload types.PaymentPayload) (time.Time, bool) {
+	c.mu.RLock()
+	expirer, ok := findSchemesByNetwork(c.schemes, Network(payload.Accepted.Network))[payload.Accepted.Scheme].(PayloadExpirer)
+	c.mu.RUnlock()
+	if !ok {
+		return time.Time{}, false
+	}
+	return expirer.PayloadExpiry(payload)
+}
+
+// PrepareBest probes every supported option in accepts at once and creates the payment
+// payload (V2) for the best one, running the payment creation hooks.
+//
+// Options whose mechanism implements PaymentProber are checked for feasibility (e.g. enough
+// balance) and cost; other options count as feasible at no extra cost. The best option is the
+// first feasible one needing no on-chain approval, then the first feasible one, in the order
+// CreatePaymentPayloadFor tries them: the selector's pick, then the rest in the server's order.
+// Once the winner is known, probes still in flight are canceled. When no option is feasible,
+// the most preferred option's probe failure is returned.
+func (c *x402Client) PrepareBest(ctx context.Context, accepts []types.PaymentRequirements) (types.PaymentPayload, error) {
+	c.mu.RLock()
+	filtered, err := c.filterPaymentRequirements(accepts)
+	if err != nil {
+		c.mu.RUnlock()
+		return types.PaymentPayload{}, err
+	}
+	selected := fromView[types.PaymentRequirements](c.requirementsSelector(toViews(filtered)))
+	candidates := append([]types.PaymentRequirements{selected}, withoutRequirement(filtered, selected)...)
+
+	probers := make([]PaymentProber, len(candidates))
+	for i, req := range candidates {
+		probers[i], _ = findSchemesByNetwork(c.schemes, Network(req.Network))[req.Scheme].(PaymentProber)
+	}
+	c.mu.RUnlock()
+
+	best, err := probeBest(ctx, candidates, probers)
+	if err != nil {
+		return types.PaymentPayload{}, err
+	}
+
+	payload, _, err := c.createPaymentPayloadAttempt(ctx, 0, best, nil, nil)
+	return payload, err
+}
+
+// probeResult is the outcome of probing candidate index
+type probeResult struct {
+	index int
+	probe PaymentProbe
+	err   error
+}
+
+// probeBest probes candidates concurrently and returns the best feasible one (see PrepareBest).
+// A nil prober means the candidate can't be probed and counts as feasible at no extra cost.
+func probeBest(ctx context.Context, candidates []types.PaymentRequirements, probers []PaymentProber) (types.PaymentRequirements, error) {
+	probeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered so probes finishing after the winner is chosen don't block
+	results := make(chan probeResult, len(candidates))
+	for i := range candidates {
+		if probers[i] == nil {
+			results <- probeResult{index: i}
+			continue
+		}
+		go func(i int) {
+			probe, err := probers[i].ProbePayment(probeCtx, candidates[i])
+			results <- probeResult{index: i, probe: probe, err: err}
+		}(i)
+	}
+
+	done := make([]bool, len(candidates))
+	outcomes := make([]probeResult, len(candidates))
+	for remaining := len(candidates); remaining > 0; remaining-- {
+		select {
+		case r := <-results:
+			done[r.index] = true
+			outcomes[r.index] = r
+		case <-ctx.Done():
+			return types.PaymentRequirements{}, ctx.Err()
+		}
+
+		// A feasible candidate needing no approval wins once every candidate preferred over it
+		// has been ruled out or needs an approval
+		for i := range candidates {
+			if !done[i] {
+				break
+			}
+			if outcomes[i].err == nil && !outcomes[i].probe.NeedsApproval {
+				return candidates[i], nil
+			}
+		}
+	}
+
+	// Every feasible candidate needs an approval
+	for i := range candidates {
+		if outcomes[i].err == nil {
+			return candidates[i], nil
+		}
+	}
+	return types.PaymentRequirements{}, outcomes[0].err
+}
+
+// createPaymentPayloadAttempt creates the payment for one attempt of CreatePaymentPayloadFor,
+// running the hooks around it. retry reports whether a failure may fall back.
+func (c *x402Client) createPaymentPayloadAttempt(
+	ctx context.Context,
+	attempt int,
+	requirements types.PaymentRequirements,
+	resource *types.ResourceInfo,
+	extensions map[string]interface{},
+) (payload types.PaymentPayload, retry bool, err error) {
+	c.mu.RLock()
+	beforeHooks := c.beforePaymentCreationHooks
+	afterHooks := c.afterPaymentCreationHooks
+	failureHooks := c.onPaymentCreationFailureHooks
+	c.mu.RUnlock()
+
+	// Execute beforePaymentCreation hooks
+	hookCtx := PaymentCreationContext{
+		Ctx:                  ctx,
+		Version:              2,
+		SelectedRequirements: requirements,
+		Attempt:              attempt,
+	}
+	for _, hook := range beforeHooks {
+		result, err := hook(hookCtx)
+		if err != nil {
+			return types.PaymentPayload{}, false, err
+		}
+		if result != nil && result.Abort {
+			return types.PaymentPayload{}, false, &PaymentError{
+				Code:    ErrCodePaymentAborted,
+				Message: result.Reason,
+			}
+		}
+	}
+
+	payload, err = c.CreatePaymentPayload(ctx, requirements, resource, extensions)
+
+	// Handle failure
+	if err != nil {
+		failureCtx := PaymentCreationFailureContext{PaymentCreationContext: hookCtx, Error: err}
+		for _, hook := range failureHooks {
+			result, _ := hook(failureCtx)
+			if result != nil && result.Recovered {
+				if recovered, ok := result.Payload.(types.PaymentPayload); ok {
+					return recovered, false, nil
+				}
+			}
+		}
+		return types.PaymentPayload{}, true, err
+	}
+
+	// Execute afterPaymentCreation hooks
+	createdCtx := PaymentCreatedContext{PaymentCreationContext: hookCtx, Payload: payload}
+	for _, hook := range afterHooks {
+		_ = hook(createdCtx) // Log errors but don't fail
+	}
+
+	return payload, false, nil
+}
+
+// withoutRequirement returns requirements without the first one equal to excluded
+func withoutRequirement(requirements []types.PaymentRequirements, excluded types.PaymentRequirements) []types.PaymentRequirements {
+	rest := make([]types.PaymentRequirements, 0, len(requirements))
+	skipped := false
+	for _, req := range requirements {
+		if !skipped && reflect.DeepEqual(req, excluded) {
+			skipped = true
+			continue
+		}
+		rest = append(rest, req)
+	}
+	return rest
+}
+
 // GetRegisteredSchemes returns a list of registered schemes for debugging
 func (c *x402Client) GetRegisteredSchemes() map[int][]struct {
 	Network Network
@@ -14,6 +14,7 @@ type PaymentCreationContext struct {
 	Ctx                  context.Context
 	Version              int // V1 or V2
 	SelectedRequirements PaymentRequirementsView
+	Attempt              int // 0 for the selected requirement, then 1, 2, ... for each fallback
 }
 
 // PaymentCreatedContext contains payment creation result and context
@@ -40,7 +41,8 @@ type BeforePaymentCreationHookResult struct {
 }
 
 // PaymentCreationFailureHookResult represents the result of a payment creation failure hook
-// If Recovered is true, the hook has recovered from the failure with the given payload
+// If Recovered is true, the hook has recovered from the failure with the given payload,
+// which must be a types.PaymentPayload for V2
 type PaymentCreationFailureHookResult struct {
 	Recovered bool
 	Payload   PaymentPayloadView
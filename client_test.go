@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"x402-go/types"
 )
@@ -197,6 +198,140 @@ func TestClientSelectPaymentRequirementsWithCustomSelector(t *testing.T) {
 	}
 }
 
+func TestClientSelectPaymentRequirementsWith(t *testing.T) {
+	client := Newx402Client()
+	client.Register("eip155:*", &mockSchemeNetworkClientV2{scheme: "exact"})
+	client.Register("solana:*", &mockSchemeNetworkClientV2{scheme: "exact"})
+
+	requirements := []types.PaymentRequirements{
+		{
+			Scheme:  "exact",
+			Network: "eip155:1",
+			Asset:   "0xDAI",
+			Amount:  "2000000000000000000",
+			PayTo:   "0xrecipient",
+			Extra:   map[string]interface{}{"decimals": 18, "symbol": "DAI"},
+		},
+		{
+			Scheme:  "exact",
+			Network: "eip155:8453",
+			Asset:   "0xUSDC",
+			Amount:  "1500000",
+			PayTo:   "0xrecipient",
+			Extra:   map[string]interface{}{"decimals": float64(6), "symbol": "USDC"},
+		},
+		{
+			Scheme:  "exact",
+			Network: "solana:mainnet",
+			Asset:   "USDCMint",
+			Amount:  "1800000",
+			PayTo:   "recipient",
+			Extra:   map[string]interface{}{"decimals": "6", "symbol": "USDC"},
+		},
+		{
+			Scheme:  "unsupported",
+			Network: "eip155:1",
+			Asset:   "0xUSDC",
+			Amount:  "1",
+			PayTo:   "0xrecipient",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		strategy    SelectionStrategy
+		wantNetwork string
+	}{
+		{name: "nil strategy uses the selector", strategy: nil, wantNetwork: "eip155:1"},
+		{name: "prefer network", strategy: PreferNetwork("solana:mainnet"), wantNetwork: "solana:mainnet"},
+		{name: "prefer network wildcard", strategy: PreferNetwork("solana:*"), wantNetwork: "solana:mainnet"},
+		{name: "prefer missing network falls back", strategy: PreferNetwork("eip155:10"), wantNetwork: "eip155:1"},
+		{name: "prefer asset by symbol", strategy: PreferAsset("usdc"), wantNetwork: "eip155:8453"},
+		{name: "prefer asset by address", strategy: PreferAsset("USDCMint"), wantNetwork: "solana:mainnet"},
+		{name: "cheapest across decimals", strategy: CheapestByAmount(), wantNetwork: "eip155:8453"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := client.SelectPaymentRequirementsWith(requirements, tt.strategy)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if selected.Network != tt.wantNetwork {
+				t.Errorf("Expected network %s, got %s", tt.wantNetwork, selected.Network)
+			}
+		})
+	}
+}
+
+func TestCheapestByAmount(t *testing.T) {
+	tests := []struct {
+		name         string
+		requirements []types.PaymentRequirements
+		wantAmount   string
+		wantErr      error
+	}{
+		{
+			name: "equal value keeps server order",
+			requirements: []types.PaymentRequirements{
+				{Asset: "DAI", Amount: "1000000000000000000", Extra: map[string]interface{}{"decimals": 18}},
+				{Asset: "USDC", Amount: "1000000", Extra: map[string]interface{}{"decimals": 6}},
+			},
+			wantAmount: "1000000000000000000",
+		},
+		{
+			name: "smaller raw amount is not cheaper",
+			requirements: []types.PaymentRequirements{
+				{Asset: "USDC", Amount: "1000000", Extra: map[string]interface{}{"decimals": 6}},
+				{Asset: "DAI", Amount: "900000000000000000", Extra: map[string]interface{}{"decimals": 18}},
+			},
+			wantAmount: "900000000000000000",
+		},
+		{
+			name: "same asset without decimals",
+			requirements: []types.PaymentRequirements{
+				{Network: "eip155:1", Asset: "USDC", Amount: "2000"},
+				{Network: "eip155:8453", Asset: "USDC", Amount: "1000"},
+			},
+			wantAmount: "1000",
+		},
+		{
+			name: "mixed assets skip unknown decimals",
+			requirements: []types.PaymentRequirements{
+				{Asset: "TOKEN", Amount: "1"},
+				{Asset: "USDC", Amount: "5000000", Extra: map[string]interface{}{"decimals": 6}},
+			},
+			wantAmount: "5000000",
+		},
+		{
+			name: "nothing comparable",
+			requirements: []types.PaymentRequirements{
+				{Asset: "TOKEN", Amount: "1"},
+				{Asset: "USDC", Amount: "not-a-number", Extra: map[string]interface{}{"decimals": 6}},
+			},
+			wantErr: ErrNoComparableAmount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := CheapestByAmount()(tt.requirements)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if selected.Amount != tt.wantAmount {
+				t.Errorf("Expected amount %s, got %s", tt.wantAmount, selected.Amount)
+			}
+		})
+	}
+}
+
 func TestClientCreatePaymentPayload(t *testing.T) {
 	ctx := context.Background()
 	client := Newx402Client()
@@ -249,6 +384,413 @@ func TestClientCreatePaymentPayload(t *testing.T) {
 	}
 }
 
+// Mock V2 client that records which registration signed the payment
+type namedSchemeNetworkClient struct {
+	scheme string
+	name   string
+}
+
+func (m *namedSchemeNetworkClient) Scheme() string {
+	return m.scheme
+}
+
+func (m *namedSchemeNetworkClient) CreatePaymentPayload(ctx context.Context, requirements types.PaymentRequirements) (types.PaymentPayload, error) {
+	return types.PaymentPayload{X402Version: 2, Payload: map[string]interface{}{"signer": m.name}}, nil
+}
+
+func TestClientRegisterExactNetworkOverridesWildcard(t *testing.T) {
+	ctx := context.Background()
+	client := Newx402Client().
+		Register("eip155:*", &namedSchemeNetworkClient{scheme: "exact", name: "wildcard"}).
+		Register("eip155:*", &namedSchemeNetworkClient{scheme: "upto", name: "wildcard"}).
+		Register("eip155:8453", &namedSchemeNetworkClient{scheme: "exact", name: "base"})
+
+	tests := []struct {
+		scheme  string
+		network string
+		want    string
+	}{
+		{scheme: "exact", network: "eip155:8453", want: "base"},
+		{scheme: "exact", network: "eip155:1", want: "wildcard"},
+		// Base has no "upto" registration of its own, so the wildcard still covers it
+		{scheme: "upto", network: "eip155:8453", want: "wildcard"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scheme+" on "+tt.network, func(t *testing.T) {
+			requirements := types.PaymentRequirements{Scheme: tt.scheme, Network: tt.network, Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"}
+
+			selected, err := client.SelectPaymentRequirements([]types.PaymentRequirements{requirements})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			payload, err := client.CreatePaymentPayload(ctx, selected, nil, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if payload.Payload["signer"] != tt.want {
+				t.Fatalf("Expected %s registration to sign, got %v", tt.want, payload.Payload["signer"])
+			}
+		})
+	}
+}
+
+func TestClientRegisterReplacesAndWarns(t *testing.T) {
+	logger := &recordingLogger{}
+	client := Newx402Client(WithClientLogger(logger)).
+		Register("eip155:8453", &namedSchemeNetworkClient{scheme: "exact", name: "first"}).
+		Register("eip155:8453", &namedSchemeNetworkClient{scheme: "exact", name: "second"})
+
+	if len(logger.entries) != 1 || logger.entries[0].level != "warn" || logger.entries[0].fields["network"] != "eip155:8453" {
+		t.Fatalf("Expected one warning about the replaced registration, got %+v", logger.entries)
+	}
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:8453", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"}
+	payload, err := client.CreatePaymentPayload(context.Background(), requirements, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if payload.Payload["signer"] != "second" {
+		t.Fatalf("Expected last registration to win, got %v", payload.Payload["signer"])
+	}
+}
+
+// Mock V2 client whose payments fail on some networks
+type failingSchemeNetworkClient struct {
+	scheme string
+	errs   map[string]error
+}
+
+func (m *failingSchemeNetworkClient) Scheme() string {
+	return m.scheme
+}
+
+func (m *failingSchemeNetworkClient) CreatePaymentPayload(ctx context.Context, requirements types.PaymentRequirements) (types.PaymentPayload, error) {
+	if err := m.errs[requirements.Network]; err != nil {
+		return types.PaymentPayload{}, err
+	}
+	return types.PaymentPayload{X402Version: 2, Payload: map[string]interface{}{"signature": "mock_signature"}}, nil
+}
+
+func TestClientCreatePaymentPayloadFor(t *testing.T) {
+	ctx := context.Background()
+	rpcDown := errors.New("rpc unavailable")
+
+	required := types.PaymentRequired{
+		X402Version: 2,
+		Accepts: []types.PaymentRequirements{
+			{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"},
+			{Scheme: "exact", Network: "eip155:137", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"},
+			{Scheme: "exact", Network: "eip155:8453", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"},
+		},
+	}
+
+	newClient := func(errs map[string]error) (*x402Client, *[]PaymentCreationFailureContext) {
+		var failures []PaymentCreationFailureContext
+		client := Newx402Client()
+		client.Register("eip155:*", &failingSchemeNetworkClient{scheme: "exact", errs: errs})
+		client.OnPaymentCreationFailure(func(ctx PaymentCreationFailureContext) (*PaymentCreationFailureHookResult, error) {
+			failures = append(failures, ctx)
+			return nil, nil
+		})
+		return client, &failures
+	}
+
+	t.Run("falls back to the next requirement", func(t *testing.T) {
+		client, failures := newClient(map[string]error{"eip155:1": rpcDown, "eip155:137": rpcDown})
+
+		payload, err := client.CreatePaymentPayloadFor(ctx, required, true)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if payload.Accepted.Network != "eip155:8453" {
+			t.Errorf("Expected payment on eip155:8453, got %s", payload.Accepted.Network)
+		}
+		if len(*failures) != 2 {
+			t.Fatalf("Expected 2 failure hook calls, got %d", len(*failures))
+		}
+		for i, failure := range *failures {
+			if failure.Attempt != i || failure.SelectedRequirements.GetNetwork() != required.Accepts[i].Network {
+				t.Errorf("Unexpected failure %d: attempt %d on %s", i, failure.Attempt, failure.SelectedRequirements.GetNetwork())
+			}
+		}
+	})
+
+	t.Run("returns the last failure", func(t *testing.T) {
+		client, failures := newClient(map[string]error{"eip155:1": rpcDown, "eip155:137": rpcDown, "eip155:8453": rpcDown})
+
+		_, err := client.CreatePaymentPayloadFor(ctx, required, true)
+		if !errors.Is(err, rpcDown) {
+			t.Errorf("Expected the creation failure, got %v", err)
+		}
+		if len(*failures) != 3 {
+			t.Errorf("Expected 3 failure hook calls, got %d", len(*failures))
+		}
+	})
+
+	t.Run("without fallback", func(t *testing.T) {
+		client, failures := newClient(map[string]error{"eip155:1": rpcDown})
+
+		_, err := client.CreatePaymentPayloadFor(ctx, required, false)
+		if !errors.Is(err, rpcDown) {
+			t.Errorf("Expected the creation failure, got %v", err)
+		}
+		if len(*failures) != 1 {
+			t.Errorf("Expected 1 failure hook call, got %d", len(*failures))
+		}
+	})
+
+	t.Run("canceled context stops the fallback", func(t *testing.T) {
+		client, failures := newClient(map[string]error{"eip155:1": context.Canceled})
+
+		_, err := client.CreatePaymentPayloadFor(ctx, required, true)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if len(*failures) != 1 {
+			t.Errorf("Expected 1 failure hook call, got %d", len(*failures))
+		}
+	})
+
+	t.Run("abort stops the fallback", func(t *testing.T) {
+		client, _ := newClient(nil)
+		attempts := 0
+		client.OnBeforePaymentCreation(func(ctx PaymentCreationContext) (*BeforePaymentCreationHookResult, error) {
+			attempts++
+			return &BeforePaymentCreationHookResult{Abort: true, Reason: "over budget"}, nil
+		})
+
+		_, err := client.CreatePaymentPayloadFor(ctx, required, true)
+		var paymentErr *PaymentError
+		if !errors.As(err, &paymentErr) || paymentErr.Code != ErrCodePaymentAborted || paymentErr.Message != "over budget" {
+			t.Errorf("Expected payment_aborted, got %v", err)
+		}
+		if attempts != 1 {
+			t.Errorf("Expected 1 attempt, got %d", attempts)
+		}
+	})
+
+	t.Run("failure hook recovers", func(t *testing.T) {
+		client, _ := newClient(map[string]error{"eip155:1": rpcDown})
+		client.OnPaymentCreationFailure(func(ctx PaymentCreationFailureContext) (*PaymentCreationFailureHookResult, error) {
+			return &PaymentCreationFailureHookResult{Recovered: true, Payload: types.PaymentPayload{X402Version: 2, Payload: map[string]interface{}{"recovered": true}}}, nil
+		})
+
+		payload, err := client.CreatePaymentPayloadFor(ctx, required, true)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if payload.Payload["recovered"] != true {
+			t.Errorf("Expected the recovered payload, got %+v", payload)
+		}
+	})
+
+	t.Run("after hooks see the created payment", func(t *testing.T) {
+		client, _ := newClient(map[string]error{"eip155:1": rpcDown})
+		var created []PaymentCreatedContext
+		client.OnAfterPaymentCreation(func(ctx PaymentCreatedContext) error {
+			created = append(created, ctx)
+			return nil
+		})
+
+		if _, err := client.CreatePaymentPayloadFor(ctx, required, true); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(created) != 1 || created[0].Attempt != 1 || created[0].Payload.GetNetwork() != "eip155:137" {
+			t.Errorf("Unexpected after hook calls: %+v", created)
+		}
+	})
+}
+
+// probingSchemeNetworkClient probes each network with the configured result. Probes on a
+// network in blocks wait for their context to end and report the cancellation on it.
+type probingSchemeNetworkClient struct {
+	failingSchemeNetworkClient
+	probes map[string]PaymentProbe
+	blocks map[string]chan error
+}
+
+func (m *probingSchemeNetworkClient) ProbePayment(ctx context.Context, requirements types.PaymentRequirements) (PaymentProbe, error) {
+	if canceled, ok := m.blocks[requirements.Network]; ok {
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return PaymentProbe{}, ctx.Err()
+	}
+	if err := m.errs[requirements.Network]; err != nil {
+		return PaymentProbe{}, err
+	}
+	return m.probes[requirements.Network], nil
+}
+
+func TestClientPrepareBest(t *testing.T) {
+	ctx := context.Background()
+	lowBalance := NewPaymentError(ReasonInsufficientBalance, "balance too low", nil)
+
+	accepts := []types.PaymentRequirements{
+		{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"},
+		{Scheme: "exact", Network: "eip155:137", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"},
+		{Scheme: "exact", Network: "eip155:8453", Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"},
+	}
+
+	newClient := func(prober *probingSchemeNetworkClient) *x402Client {
+		prober.scheme = "exact"
+		client := Newx402Client()
+		client.Register("eip155:*", prober)
+		return client
+	}
+
+	t.Run("prefers options needing no approval", func(t *testing.T) {
+		client := newClient(&probingSchemeNetworkClient{
+			failingSchemeNetworkClient: failingSchemeNetworkClient{errs: map[string]error{"eip155:137": lowBalance}},
+			probes:                     map[string]PaymentProbe{"eip155:1": {NeedsApproval: true}},
+		})
+
+		payload, err := client.PrepareBest(ctx, accepts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if payload.Accepted.Network != "eip155:8453" {
+			t.Errorf("Expected payment on eip155:8453, got %s", payload.Accepted.Network)
+		}
+	})
+
+	t.Run("falls back to an option needing approval", func(t *testing.T) {
+		client := newClient(&probingSchemeNetworkClient{
+			failingSchemeNetworkClient: failingSchemeNetworkClient{errs: map[string]error{"eip155:1": lowBalance}},
+			probes:                     map[string]PaymentProbe{"eip155:137": {NeedsApproval: true}, "eip155:8453": {NeedsApproval: true}},
+		})
+
+		payload, err := client.PrepareBest(ctx, accepts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if payload.Accepted.Network != "eip155:137" {
+			t.Errorf("Expected payment on eip155:137, got %s", payload.Accepted.Network)
+		}
+	})
+
+	t.Run("returns the preferred option's failure", func(t *testing.T) {
+		rpcDown := errors.New("rpc unavailable")
+		client := newClient(&probingSchemeNetworkClient{
+			failingSchemeNetworkClient: failingSchemeNetworkClient{errs: map[string]error{"eip155:1": lowBalance, "eip155:137": rpcDown, "eip155:8453": rpcDown}},
+		})
+
+		_, err := client.PrepareBest(ctx, accepts)
+		var paymentErr *PaymentError
+		if !errors.As(err, &paymentErr) || paymentErr.Code != ReasonInsufficientBalance {
+			t.Errorf("Expected insufficient_balance, got %v", err)
+		}
+	})
+
+	t.Run("cancels probes once a winner is chosen", func(t *testing.T) {
+		canceled := make(chan error, 2)
+		client := newClient(&probingSchemeNetworkClient{
+			blocks: map[string]chan error{"eip155:137": canceled, "eip155:8453": canceled},
+		})
+
+		payload, err := client.PrepareBest(ctx, accepts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if payload.Accepted.Network != "eip155:1" {
+			t.Errorf("Expected payment on eip155:1, got %s", payload.Accepted.Network)
+		}
+		for i := 0; i < 2; i++ {
+			if err := <-canceled; !errors.Is(err, context.Canceled) {
+				t.Errorf("Expected the probe to be canceled, got %v", err)
+			}
+		}
+	})
+
+	t.Run("bounded by the context", func(t *testing.T) {
+		canceled := make(chan error, 3)
+		client := newClient(&probingSchemeNetworkClient{
+			blocks: map[string]chan error{"eip155:1": canceled, "eip155:137": canceled, "eip155:8453": canceled},
+		})
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := client.PrepareBest(cancelCtx, accepts); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("mechanisms without probing count as feasible", func(t *testing.T) {
+		client := Newx402Client()
+		client.Register("eip155:*", &mockSchemeNetworkClientV2{scheme: "exact"})
+
+		payload, err := client.PrepareBest(ctx, accepts)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if payload.Accepted.Network != "eip155:1" {
+			t.Errorf("Expected payment on eip155:1, got %s", payload.Accepted.Network)
+		}
+	})
+}
+
+// expiringSchemeNetworkClient reports the unix time in its payloads' validBefore as their expiry
+type expiringSchemeNetworkClient struct {
+	mockSchemeNetworkClientV2
+}
+
+func (m *expiringSchemeNetworkClient) PayloadExpiry(payload types.PaymentPayload) (time.Time, bool) {
+	validBefore, ok := payload.Payload["validBefore"].(int64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(validBefore, 0), true
+}
+
+func TestClientPayloadExpiry(t *testing.T) {
+	client := Newx402Client()
+	client.Register("eip155:*", &expiringSchemeNetworkClient{mockSchemeNetworkClientV2{scheme: "exact"}})
+	client.Register("solana:*", &mockSchemeNetworkClientV2{scheme: "exact"})
+
+	payload := func(network string, fields map[string]interface{}) types.PaymentPayload {
+		return types.PaymentPayload{
+			X402Version: 2,
+			Accepted:    types.PaymentRequirements{Scheme: "exact", Network: network},
+			Payload:     fields,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		payload  types.PaymentPayload
+		expected time.Time
+		ok       bool
+	}{
+		{
+			name:     "reported by the mechanism",
+			payload:  payload("eip155:8453", map[string]interface{}{"validBefore": int64(1700000000)}),
+			expected: time.Unix(1700000000, 0),
+			ok:       true,
+		},
+		{
+			name:    "payload without expiry",
+			payload: payload("eip155:8453", map[string]interface{}{}),
+		},
+		{
+			name:    "mechanism without expiry",
+			payload: payload("solana:mainnet", map[string]interface{}{"validBefore": int64(1700000000)}),
+		},
+		{
+			name:    "unregistered network",
+			payload: payload("cosmos:hub", map[string]interface{}{"validBefore": int64(1700000000)}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiry, ok := client.PayloadExpiry(tt.payload)
+			if ok != tt.ok || !expiry.Equal(tt.expected) {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", tt.expected, tt.ok, expiry, ok)
+			}
+		})
+	}
+}
+
 func TestClientCreatePaymentPayloadValidation(t *testing.T) {
 	ctx := context.Background()
 	client := Newx402Client()
@@ -25,8 +25,21 @@ const (
 	ErrCodeSettlementFailed   = "settlement_failed"
 	ErrCodeUnsupportedScheme  = "unsupported_scheme"
 	ErrCodeUnsupportedNetwork = "unsupported_network"
+	ErrCodePaymentAborted     = "payment_aborted"
 )
 
+// NetworkPatternError reports a network or network pattern that is malformed and would never
+// match a payment, e.g. the typo "eip156:*". The fluent Register methods panic with it.
+type NetworkPatternError struct {
+	Network Network
+	Reason  string
+}
+
+// Error implements the error interface
+func (e *NetworkPatternError) Error() string {
+	return fmt.Sprintf("invalid network pattern %q: %s", e.Network, e.Reason)
+}
+
 // NewPaymentError creates a new payment error
 func NewPaymentError(code, message string, details map[string]interface{}) *PaymentError {
 	return &PaymentError{
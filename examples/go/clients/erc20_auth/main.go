@@ -35,16 +35,19 @@ import (
 func init() {
 	// SIMULATION: Force USDC to be treated as non-EIP-3009 for this example
 	// This forces the library to use the standard ERC-20 'approve' flow
-	config := evm.NetworkConfigs["eip155:84532"] // Base Sepolia
-
-	// Create a copy of the asset info but with SupportsEIP3009 = false
-	usdcInfo := config.SupportedAssets["USDC"]
-	usdcInfo.SupportsEIP3009 = false
+	usdcInfo, err := evm.GetAssetInfo("eip155:84532", "USDC") // Base Sepolia
+	if err != nil {
+		fmt.Printf("❌ Failed to load USDC config: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Update the map
-	config.SupportedAssets["USDC"] = usdcInfo
-	config.DefaultAsset = usdcInfo
-	evm.NetworkConfigs["eip155:84532"] = config
+	// Re-register a copy of the asset info but with SupportsEIP3009 = false
+	info := *usdcInfo
+	info.SupportsEIP3009 = false
+	if err := evm.RegisterDefaultAsset("eip155:84532", "USDC", info); err != nil {
+		fmt.Printf("❌ Failed to register USDC config: %v\n", err)
+		os.Exit(1)
+	}
 
 	fmt.Println("🔧 Configured USDC on Base Sepolia to verify via standard ERC-20 flow (SupportsEIP3009=false)")
 }
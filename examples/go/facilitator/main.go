@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	x402 "x402-go"
+	x402http "x402-go/http"
 	evm "x402-go/mechanisms/evm/exact/facilitator"
 	evmv1 "x402-go/mechanisms/evm/exact/v1/facilitator"
 	svm "x402-go/mechanisms/svm/exact/facilitator"
@@ -49,8 +53,10 @@ func main() {
 	facilitator := x402.Newx402Facilitator()
 	
 	// Register V2 EVM scheme with smart wallet deployment enabled
+	// /health fails once the signer holds less than 0.001 ETH for gas
 	evmConfig := &evm.ExactEvmSchemeConfig{
 		DeployERC4337WithEIP6492: true,
+		MinSignerBalance:         big.NewInt(1_000_000_000_000_000),
 	}
 	facilitator.Register([]x402.Network{evmNetwork}, evm.NewExactEvmScheme(evmSigner, evmConfig))
 
@@ -75,15 +81,39 @@ func main() {
 		return nil
 	})
 
+	// Settlement results by idempotency key, so a resource server retrying a settle
+	// that timed out on its side gets the original result instead of a second payment.
+	// Use a shared store (e.g. Redis) when running more than one facilitator instance.
+	settlements := x402http.NewInMemoryIdempotencyStore()
+	var inFlight sync.Map
+
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(gin.Recovery())
 
-	// Supported endpoint - returns supported networks and schemes
+	// Supported endpoint - returns supported networks and schemes,
+	// optionally filtered with ?scheme=exact&network=eip155:8453 (repeatable or comma-separated)
 	r.GET("/supported", func(c *gin.Context) {
-		// Get supported kinds - networks already registered
-		supported := facilitator.GetSupported()
-		c.JSON(http.StatusOK, supported)
+		schemes := queryList(c, "scheme")
+		var networks []x402.Network
+		for _, network := range queryList(c, "network") {
+			networks = append(networks, x402.Network(network))
+		}
+		c.JSON(http.StatusOK, facilitator.GetSupportedFor(schemes, networks))
+	})
+
+	// Health endpoint - checks each network's RPC, facilitator contract and signer
+	// gas balance, answering 503 when settlements would fail
+	r.GET("/health", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+		defer cancel()
+
+		status := facilitator.HealthCheck(ctx)
+		if !status.Healthy {
+			c.JSON(http.StatusServiceUnavailable, status)
+			return
+		}
+		c.JSON(http.StatusOK, status)
 	})
 
 	// Verify endpoint - verifies payment signatures
@@ -135,6 +165,22 @@ func main() {
 			return
 		}
 
+		// Return the cached result for a settlement we've already performed. The key is
+		// derived from the body rather than taken from the Idempotency-Key header, so a
+		// caller can't replay another payment's result by reusing its key.
+		idempotencyKey := x402http.IdempotencyKeyFromJSON(reqBody.PaymentPayload, reqBody.PaymentRequirements)
+		if cached, found, _ := settlements.Get(ctx, idempotencyKey); found {
+			c.JSON(http.StatusOK, cached)
+			return
+		}
+
+		// Reject concurrent duplicates while the first attempt is still settling
+		if _, busy := inFlight.LoadOrStore(idempotencyKey, struct{}{}); busy {
+			c.JSON(http.StatusConflict, gin.H{"error": "Settlement already in progress"})
+			return
+		}
+		defer inFlight.Delete(idempotencyKey)
+
 		// Settle payment
 		result, err := facilitator.Settle(ctx, reqBody.PaymentPayload, reqBody.PaymentRequirements)
 		if err != nil {
@@ -149,6 +195,7 @@ func main() {
 		}
 
 		// Success! result.Success is guaranteed to be true
+		_ = settlements.Put(ctx, idempotencyKey, result)
 		c.JSON(http.StatusOK, result)
 	})
 
@@ -165,3 +212,15 @@ func main() {
 	}
 }
 
+// queryList collects a query parameter given repeatedly or as a comma-separated list
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, value := range strings.Split(param, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
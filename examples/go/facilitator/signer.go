@@ -11,6 +11,7 @@ import (
 
 	evmmech "x402-go/mechanisms/evm"
 	svmmech "x402-go/mechanisms/svm"
+	evmsigners "x402-go/signers/evm"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -38,6 +39,8 @@ type facilitatorEvmSigner struct {
 	address    common.Address
 	client     *ethclient.Client
 	chainID    *big.Int
+	nonces     *evmsigners.NonceManager
+	gas        evmsigners.GasConfig // gas price and limit caps, none by default
 }
 
 // newFacilitatorEvmSigner creates a new EVM facilitator signer
@@ -79,6 +82,7 @@ func newFacilitatorEvmSigner(privateKeyHex string, rpcURL string) (*facilitatorE
 		address:    address,
 		client:     client,
 		chainID:    chainID,
+		nonces:     evmsigners.NewNonceManager(client),
 	}, nil
 }
 
@@ -257,42 +261,8 @@ func (s *facilitatorEvmSigner) WriteContract(
 		return "", fmt.Errorf("failed to pack method call: %w", err)
 	}
 
-	// Get nonce
-	nonce, err := s.client.PendingNonceAt(ctx, s.address)
-	if err != nil {
-		return "", fmt.Errorf("failed to get nonce: %w", err)
-	}
-
-	// Get gas price
-	gasPrice, err := s.client.SuggestGasPrice(ctx)
-	if err != nil {
-		return "", fmt.Errorf("failed to get gas price: %w", err)
-	}
-
-	// Create transaction
 	to := common.HexToAddress(contractAddress)
-	tx := types.NewTransaction(
-		nonce,
-		to,
-		big.NewInt(0), // value
-		300000,        // gas limit
-		gasPrice,
-		data,
-	)
-
-	// Sign transaction
-	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(s.chainID), s.privateKey)
-	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %w", err)
-	}
-
-	// Send transaction
-	err = s.client.SendTransaction(ctx, signedTx)
-	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %w", err)
-	}
-
-	return signedTx.Hash().Hex(), nil
+	return s.signAndSend(ctx, to, data)
 }
 
 func (s *facilitatorEvmSigner) SendTransaction(
@@ -300,44 +270,55 @@ func (s *facilitatorEvmSigner) SendTransaction(
 	to string,
 	data []byte,
 ) (string, error) {
-	// Get nonce
-	nonce, err := s.client.PendingNonceAt(ctx, s.address)
-	if err != nil {
-		return "", fmt.Errorf("failed to get nonce: %w", err)
-	}
-
-	// Get gas price
-	gasPrice, err := s.client.SuggestGasPrice(ctx)
-	if err != nil {
-		return "", fmt.Errorf("failed to get gas price: %w", err)
-	}
-
-	// Create transaction with raw data
 	toAddr := common.HexToAddress(to)
-	tx := types.NewTransaction(
-		nonce,
-		toAddr,
-		big.NewInt(0), // value
-		300000,        // gas limit
-		gasPrice,
-		data,
-	)
-
-	// Sign transaction
-	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(s.chainID), s.privateKey)
+	return s.signAndSend(ctx, toAddr, data)
+}
+
+// signAndSend signs and sends a transaction using a locally allocated nonce, so
+// concurrent settlements never reuse one. The gas limit is estimated, and both it and
+// the gas price are checked against the signer's GasConfig caps.
+func (s *facilitatorEvmSigner) signAndSend(ctx context.Context, to common.Address, data []byte) (string, error) {
+	gasPrice, gasLimit, err := s.gas.Estimate(ctx, s.client, ethereum.CallMsg{From: s.address, To: &to, Data: data})
 	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %w", err)
-	}
+		return "", err
+	}
+
+	var signedTx *types.Transaction
+	err = s.nonces.Send(ctx, s.address, func(nonce uint64) error {
+		tx := types.NewTransaction(
+			nonce,
+			to,
+			big.NewInt(0), // value
+			gasLimit,
+			gasPrice,
+			data,
+		)
+
+		// Sign transaction
+		signed, err := types.SignTx(tx, types.LatestSignerForChainID(s.chainID), s.privateKey)
+		if err != nil {
+			return fmt.Errorf("failed to sign transaction: %w", err)
+		}
 
-	// Send transaction
-	err = s.client.SendTransaction(ctx, signedTx)
+		// Send transaction
+		if err := s.client.SendTransaction(ctx, signed); err != nil {
+			return fmt.Errorf("failed to send transaction: %w", err)
+		}
+		signedTx = signed
+		return nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %w", err)
+		return "", err
 	}
 
 	return signedTx.Hash().Hex(), nil
 }
 
+// ResetNonce drops the locally tracked nonce so the next transaction resyncs from the chain
+func (s *facilitatorEvmSigner) ResetNonce() {
+	s.nonces.Reset(s.address)
+}
+
 func (s *facilitatorEvmSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evmmech.TransactionReceipt, error) {
 	hash := common.HexToHash(txHash)
 
@@ -391,6 +372,14 @@ func (s *facilitatorEvmSigner) GetCode(ctx context.Context, address string) ([]b
 	return code, nil
 }
 
+func (s *facilitatorEvmSigner) GetBlockTimestamp(ctx context.Context) (uint64, error) {
+	header, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	return header.Time, nil
+}
+
 // ============================================================================
 // SVM (Solana) Facilitator Signer
 // ============================================================================
@@ -572,6 +561,32 @@ func (s *facilitatorSvmSigner) GetAddresses(ctx context.Context, network string)
 	return []solana.PublicKey{s.privateKey.PublicKey()}
 }
 
+// GetMintInfo implements svm.MintReader so Token-2022 transfer fees are accounted for
+func (s *facilitatorSvmSigner) GetMintInfo(ctx context.Context, mint solana.PublicKey, network string) (*svmmech.MintInfo, error) {
+	rpcClient, err := s.getRPC(ctx, network)
+	if err != nil {
+		return nil, err
+	}
+	return svmmech.GetMintInfo(ctx, rpcClient, mint)
+}
+
+func (s *facilitatorSvmSigner) GetCurrentEpoch(ctx context.Context, network string) (uint64, error) {
+	rpcClient, err := s.getRPC(ctx, network)
+	if err != nil {
+		return 0, err
+	}
+	return svmmech.GetCurrentEpoch(ctx, rpcClient)
+}
+
+// GetTokenBalance implements svm.TokenBalanceReader so underfunded payers are rejected before simulation
+func (s *facilitatorSvmSigner) GetTokenBalance(ctx context.Context, owner solana.PublicKey, mint solana.PublicKey, tokenProgram solana.PublicKey, network string) (uint64, error) {
+	rpcClient, err := s.getRPC(ctx, network)
+	if err != nil {
+		return 0, err
+	}
+	return svmmech.GetTokenBalance(ctx, rpcClient, owner, mint, tokenProgram)
+}
+
 // ============================================================================
 // Helper Functions
 // ============================================================================
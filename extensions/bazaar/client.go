@@ -0,0 +1,298 @@
+package bazaar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"x402-go/extensions/types"
+	x402http "x402-go/http"
+)
+
+// DiscoveryResourcesPath is the facilitator endpoint listing the resources its Bazaar catalog
+// has discovered
+const DiscoveryResourcesPath = "/discovery/resources"
+
+// DefaultCatalogPageSize is how many resources AllResources requests per page
+const DefaultCatalogPageSize = 100
+
+// Pagination describes one page of a facilitator's catalog
+type Pagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// resourcesResponse is the body of a DiscoveryResourcesPath response
+type resourcesResponse struct {
+	X402Version int                  `json:"x402Version"`
+	Items       []DiscoveredResource `json:"items"`
+	Pagination  Pagination           `json:"pagination"`
+}
+
+// CatalogClient lists the payable resources a facilitator's Bazaar catalog has discovered, so
+// agents can find resources, inspect their input schemas and call them (see NewResourceRequest)
+type CatalogClient struct {
+	url          string
+	httpClient   *http.Client
+	authProvider x402http.AuthProvider
+}
+
+// NewCatalogClient creates a client for the catalog of the facilitator described by config.
+// URL, HTTPClient, Transport, AuthProvider (its Discovery headers) and Timeout are used; a nil
+// config or empty URL uses x402http.DefaultFacilitatorURL.
+//
+// Example:
+//
+//	catalog := bazaar.NewCatalogClient(&x402http.FacilitatorConfig{URL: "https://facilitator.example.com"})
+//	resources, err := catalog.AllResources(ctx)
+func NewCatalogClient(config *x402http.FacilitatorConfig) *CatalogClient {
+	if config == nil {
+		config = &x402http.FacilitatorConfig{}
+	}
+
+	baseURL := config.URL
+	if baseURL == "" {
+		baseURL = x402http.DefaultFacilitatorURL
+	}
+
+	return &CatalogClient{
+		url:          strings.TrimSuffix(baseURL, "/"),
+		httpClient:   x402http.NewFacilitatorHTTPClient(config),
+		authProvider: config.AuthProvider,
+	}
+}
+
+// ListResources returns one page of the catalog, starting at offset. A limit <= 0 leaves the
+// page size to the facilitator. Method is filled in from each resource's discovery info when
+// the facilitator doesn't report it.
+func (c *CatalogClient) ListResources(ctx context.Context, limit, offset int) ([]DiscoveredResource, Pagination, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+
+	endpoint := c.url + DiscoveryResourcesPath
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+
+	if c.authProvider != nil {
+		authHeaders, err := c.authProvider.GetAuthHeaders(ctx)
+		if err != nil {
+			return nil, Pagination{}, fmt.Errorf("failed to get auth headers: %w", err)
+		}
+		for k, v := range authHeaders.Discovery {
+			req.Header.Set(k, v)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, Pagination{}, fmt.Errorf("discovery request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, Pagination{}, fmt.Errorf("facilitator discovery failed (%d): %s", resp.StatusCode, string(body))
+	}
+
+	var page resourcesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, Pagination{}, fmt.Errorf("failed to decode discovery response: %w", err)
+	}
+
+	for i := range page.Items {
+		if page.Items[i].Method == "" && page.Items[i].DiscoveryInfo != nil {
+			page.Items[i].Method = discoveryMethod(*page.Items[i].DiscoveryInfo)
+		}
+	}
+	return page.Items, page.Pagination, nil
+}
+
+// AllResources walks the catalog DefaultCatalogPageSize resources at a time and returns every
+// resource in it. It stops at the reported total or at the first empty page.
+func (c *CatalogClient) AllResources(ctx context.Context) ([]DiscoveredResource, error) {
+	var all []DiscoveredResource
+	for {
+		items, pagination, err := c.ListResources(ctx, DefaultCatalogPageSize, len(all))
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+		if len(items) == 0 || len(all) >= pagination.Total {
+			return all, nil
+		}
+	}
+}
+
+// discoveryMethod returns the HTTP method info's input is called with
+func discoveryMethod(info types.DiscoveryInfo) string {
+	switch input := info.Input.(type) {
+	case types.QueryInput:
+		return string(input.Method)
+	case types.BodyInput:
+		return string(input.Method)
+	}
+	return ""
+}
+
+// NewResourceRequest builds the HTTP request calling resourceURL as info describes: its
+// method, headers and query parameters, plus the body for POST, PUT and PATCH inputs.
+//
+// input replaces the example values in info: the query parameters (a map[string]interface{})
+// for GET, HEAD and DELETE, or the body for POST, PUT and PATCH. A nil input sends the examples.
+// Bodies are encoded by the input's body type: JSON, multipart form data (from a map) or text.
+func NewResourceRequest(ctx context.Context, resourceURL string, info types.DiscoveryInfo, input interface{}) (*http.Request, error) {
+	var (
+		method      string
+		queryParams map[string]interface{}
+		headers     map[string]string
+		body        io.Reader
+		contentType string
+	)
+
+	switch in := info.Input.(type) {
+	case types.QueryInput:
+		method, queryParams, headers = string(in.Method), in.QueryParams, in.Headers
+		if input != nil {
+			params, ok := input.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("query input must be a map[string]interface{}, got %T", input)
+			}
+			queryParams = params
+		}
+	case types.BodyInput:
+		method, queryParams, headers = string(in.Method), in.QueryParams, in.Headers
+		if input == nil {
+			input = in.Body
+		}
+		var err error
+		body, contentType, err = encodeBody(in.BodyType, input)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported discovery input type: %T", info.Input)
+	}
+
+	if method == "" {
+		return nil, fmt.Errorf("discovery input has no method")
+	}
+
+	target, err := url.Parse(resourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resource URL: %w", err)
+	}
+	if len(queryParams) > 0 {
+		query := target.Query()
+		for key, value := range queryParams {
+			query.Del(key)
+			for _, v := range queryValues(value) {
+				query.Add(key, v)
+			}
+		}
+		target.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
+
+// encodeBody encodes body as bodyType, returning it with its content type
+func encodeBody(bodyType types.BodyType, body interface{}) (io.Reader, string, error) {
+	if body == nil {
+		return nil, "", nil
+	}
+
+	switch bodyType {
+	case types.BodyTypeJSON, "":
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode JSON body: %w", err)
+		}
+		return bytes.NewReader(encoded), "application/json", nil
+
+	case types.BodyTypeFormData:
+		fields, ok := body.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("form-data body must be a map[string]interface{}, got %T", body)
+		}
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		for _, key := range keys {
+			for _, v := range queryValues(fields[key]) {
+				if err := writer.WriteField(key, v); err != nil {
+					return nil, "", fmt.Errorf("failed to encode form field %s: %w", key, err)
+				}
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", fmt.Errorf("failed to encode form body: %w", err)
+		}
+		return &buf, writer.FormDataContentType(), nil
+
+	case types.BodyTypeText:
+		text, ok := body.(string)
+		if !ok {
+			return nil, "", fmt.Errorf("text body must be a string, got %T", body)
+		}
+		return strings.NewReader(text), "text/plain; charset=utf-8", nil
+	}
+
+	return nil, "", fmt.Errorf("unsupported body type: %s", bodyType)
+}
+
+// queryValues formats a query parameter or form field, one value per element of a slice
+func queryValues(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case float64:
+		// JSON numbers decode as float64; avoid exponent notation for whole numbers
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, element := range v {
+			values = append(values, queryValues(element)...)
+		}
+		return values
+	}
+	return []string{fmt.Sprint(value)}
+}
@@ -0,0 +1,176 @@
+package bazaar_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"x402-go/extensions/bazaar"
+	"x402-go/extensions/types"
+	x402http "x402-go/http"
+)
+
+// catalogAuth sets a discovery API key
+type catalogAuth struct{}
+
+func (catalogAuth) GetAuthHeaders(ctx context.Context) (x402http.AuthHeaders, error) {
+	return x402http.AuthHeaders{Discovery: map[string]string{"X-API-Key": "secret"}}, nil
+}
+
+func TestCatalogClient(t *testing.T) {
+	var items []map[string]interface{}
+	for i := 0; i < 5; i++ {
+		items = append(items, map[string]interface{}{
+			"resource":    "https://api.example.com/items/" + strconv.Itoa(i),
+			"type":        "http",
+			"x402Version": 2,
+			"accepts":     []map[string]interface{}{{"scheme": "exact", "network": "eip155:8453", "amount": "1000"}},
+			"discoveryInfo": map[string]interface{}{
+				"input": map[string]interface{}{"type": "http", "method": "DELETE", "queryParams": map[string]interface{}{"soft": "true"}},
+			},
+		})
+	}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, bazaar.DiscoveryResourcesPath, r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-API-Key"))
+		requests = append(requests, r.URL.RawQuery)
+
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if limit == 0 {
+			limit = 2
+		}
+		end := min(offset+limit, len(items))
+		page := []map[string]interface{}{}
+		if offset < len(items) {
+			page = items[offset:end]
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"x402Version": 1,
+			"items":       page,
+			"pagination":  map[string]int{"limit": limit, "offset": offset, "total": len(items)},
+		})
+	}))
+	defer server.Close()
+
+	catalog := bazaar.NewCatalogClient(&x402http.FacilitatorConfig{URL: server.URL + "/", AuthProvider: catalogAuth{}})
+
+	t.Run("ListResources", func(t *testing.T) {
+		resources, pagination, err := catalog.ListResources(context.Background(), 2, 2)
+		require.NoError(t, err)
+		require.Len(t, resources, 2)
+		assert.Equal(t, bazaar.Pagination{Limit: 2, Offset: 2, Total: 5}, pagination)
+
+		resource := resources[0]
+		assert.Equal(t, "https://api.example.com/items/2", resource.ResourceURL)
+		assert.Equal(t, "DELETE", resource.Method)
+		assert.Equal(t, 2, resource.X402Version)
+		require.Len(t, resource.Accepts, 1)
+		assert.Equal(t, "1000", resource.Accepts[0].Amount)
+		require.NotNil(t, resource.DiscoveryInfo)
+		_, ok := resource.DiscoveryInfo.Input.(types.QueryInput)
+		assert.True(t, ok, "expected query input, got %T", resource.DiscoveryInfo.Input)
+	})
+
+	t.Run("AllResources", func(t *testing.T) {
+		requests = nil
+		resources, err := catalog.AllResources(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, resources, 5)
+		assert.Equal(t, []string{"limit=100"}, requests)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "catalog unavailable", http.StatusServiceUnavailable)
+		}))
+		defer failing.Close()
+
+		_, _, err := bazaar.NewCatalogClient(&x402http.FacilitatorConfig{URL: failing.URL}).ListResources(context.Background(), 0, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "503")
+	})
+}
+
+func TestNewResourceRequest(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("query input", func(t *testing.T) {
+		info := types.DiscoveryInfo{Input: types.QueryInput{
+			Type:        "http",
+			Method:      types.MethodDELETE,
+			QueryParams: map[string]interface{}{"soft": "true", "ids": []interface{}{float64(1), float64(2000000)}},
+			Headers:     map[string]string{"X-Client": "agent"},
+		}}
+
+		req, err := bazaar.NewResourceRequest(ctx, "https://api.example.com/items/7?v=1", info, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodDelete, req.Method)
+		assert.Equal(t, "agent", req.Header.Get("X-Client"))
+		assert.Equal(t, "true", req.URL.Query().Get("soft"))
+		assert.Equal(t, []string{"1", "2000000"}, req.URL.Query()["ids"])
+		assert.Equal(t, "1", req.URL.Query().Get("v"))
+		assert.Nil(t, req.Body)
+
+		req, err = bazaar.NewResourceRequest(ctx, "https://api.example.com/items/7", info, map[string]interface{}{"soft": "false"})
+		require.NoError(t, err)
+		assert.Equal(t, "soft=false", req.URL.RawQuery)
+
+		_, err = bazaar.NewResourceRequest(ctx, "https://api.example.com/items/7", info, "soft")
+		assert.Error(t, err)
+	})
+
+	t.Run("JSON body", func(t *testing.T) {
+		info := types.DiscoveryInfo{Input: types.BodyInput{
+			Type:     "http",
+			Method:   types.MethodPOST,
+			BodyType: types.BodyTypeJSON,
+			Body:     map[string]interface{}{"prompt": "example"},
+		}}
+
+		req, err := bazaar.NewResourceRequest(ctx, "https://api.example.com/generate", info, map[string]interface{}{"prompt": "hello"})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(req.Body)
+		assert.JSONEq(t, `{"prompt":"hello"}`, string(body))
+	})
+
+	t.Run("form-data body", func(t *testing.T) {
+		info := types.DiscoveryInfo{Input: types.BodyInput{
+			Type:     "http",
+			Method:   types.MethodPUT,
+			BodyType: types.BodyTypeFormData,
+			Body:     map[string]interface{}{"name": "report", "pages": float64(3)},
+		}}
+
+		req, err := bazaar.NewResourceRequest(ctx, "https://api.example.com/upload", info, nil)
+		require.NoError(t, err)
+		require.NoError(t, req.ParseMultipartForm(1<<20))
+		assert.Equal(t, "report", req.FormValue("name"))
+		assert.Equal(t, "3", req.FormValue("pages"))
+	})
+
+	t.Run("text body", func(t *testing.T) {
+		info := types.DiscoveryInfo{Input: types.BodyInput{
+			Type:     "http",
+			Method:   types.MethodPATCH,
+			BodyType: types.BodyTypeText,
+			Body:     "hello",
+		}}
+
+		req, err := bazaar.NewResourceRequest(ctx, "https://api.example.com/notes/1", info, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain; charset=utf-8", req.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(req.Body)
+		assert.Equal(t, "hello", string(body))
+	})
+}
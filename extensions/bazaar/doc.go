@@ -71,6 +71,16 @@ The v2 extension follows a pattern where:
 		// Use discovered resource to build UI or automate calls
 	}
 
+# For Agents (Browsing a Facilitator's Catalog)
+
+	catalog := bazaar.NewCatalogClient(&x402http.FacilitatorConfig{URL: facilitatorURL})
+
+	// One page at a time, or AllResources(ctx) for the whole catalog
+	resources, pagination, err := catalog.ListResources(ctx, 50, 0)
+
+	// Build the request a resource's discovery info describes (nil sends its examples)
+	req, err := bazaar.NewResourceRequest(ctx, resources[0].ResourceURL, *resources[0].DiscoveryInfo, nil)
+
 # V1 Support
 
 V1 discovery information is stored in the `outputSchema` field of PaymentRequirements.
@@ -85,11 +85,19 @@ func ValidateDiscoveryExtension(extension types.DiscoveryExtension) ValidationRe
 	}
 }
 
+// DiscoveredResource is a payable resource found through the Bazaar extension, either extracted
+// from a payment or listed by a facilitator's catalog (see CatalogClient)
 type DiscoveredResource struct {
-	ResourceURL   string
-	Method        string
-	X402Version   int
-	DiscoveryInfo *types.DiscoveryInfo
+	ResourceURL   string               `json:"resource"`
+	Method        string               `json:"method,omitempty"`
+	X402Version   int                  `json:"x402Version"`
+	DiscoveryInfo *types.DiscoveryInfo `json:"discoveryInfo,omitempty"`
+
+	// Catalog fields, set on resources listed by a facilitator
+	Type        string                     `json:"type,omitempty"`
+	Accepts     []x402.PaymentRequirements `json:"accepts,omitempty"`
+	LastUpdated string                     `json:"lastUpdated,omitempty"`
+	Metadata    map[string]interface{}     `json:"metadata,omitempty"`
 }
 
 // ExtractDiscoveredResourceFromPaymentPayload extracts a discovered resource from a client's payment payload and requirements.
@@ -20,6 +20,22 @@ func (e *bazaarResourceServerExtension) EnrichDeclaration(
 		return declaration
 	}
 
+	// Declarations from DeclareDiscovery carry the config the extension is built from
+	switch config := declaration.(type) {
+	case types.DeclareQueryDiscoveryConfig:
+		built, err := DeclareDiscoveryExtension(config.Method, config.Input, config.InputSchema, "", config.Output)
+		if err != nil {
+			return declaration
+		}
+		declaration = built
+	case types.DeclareBodyDiscoveryConfig:
+		built, err := DeclareDiscoveryExtension(config.Method, config.Input, config.InputSchema, config.BodyType, config.Output)
+		if err != nil {
+			return declaration
+		}
+		declaration = built
+	}
+
 	extension, ok := declaration.(types.DiscoveryExtension)
 	if !ok {
 		return declaration
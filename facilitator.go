@@ -2,9 +2,13 @@ package x402
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"x402-go/types"
 )
@@ -18,6 +22,13 @@ type schemeData struct {
 
 // x402Facilitator manages payment verification and settlement
 // Supports both V1 and V2 for legacy interoperability
+//
+// Verify, Settle, SettleBatch, SimulateSettle, HealthCheck and GetSupported are safe for
+// concurrent use, and mechanisms can be registered while payments are being processed. The
+// registry is only locked to look a mechanism up, never while it runs, so payments on
+// independent networks proceed in parallel. Mechanisms are called concurrently, including
+// for the same network, and must be safe for concurrent use themselves. Hooks, metrics,
+// tracing and logging must be configured before the facilitator starts serving.
 type x402Facilitator struct {
 	mu sync.RWMutex
 
@@ -34,6 +45,13 @@ type x402Facilitator struct {
 	beforeSettleHooks    []FacilitatorBeforeSettleHook
 	afterSettleHooks     []FacilitatorAfterSettleHook
 	onSettleFailureHooks []FacilitatorOnSettleFailureHook
+
+	// Optional verify/settle metrics and tracing (nil = disabled)
+	metrics MetricsCollector
+	tracer  Tracer
+
+	// Logger for verify/settle outcomes (no-op by default)
+	logger Logger
 }
 
 func Newx402Facilitator() *x402Facilitator {
@@ -41,12 +59,16 @@ func Newx402Facilitator() *x402Facilitator {
 		schemesV1:  []*schemeData{},
 		schemes:    []*schemeData{},
 		extensions: []string{},
+		logger:     noopLogger{},
 	}
 }
 
 // RegisterV1 registers a V1 facilitator mechanism for multiple networks (legacy)
 // Networks are stored and used for GetSupported() - no need to specify them later.
+// Panics with a *NetworkPatternError if any network is malformed (see ValidateNetworkPatternV1).
 func (f *x402Facilitator) RegisterV1(networks []Network, facilitator SchemeNetworkFacilitatorV1) *x402Facilitator {
+	mustValidateNetworks(ValidateNetworkPatternV1, networks...)
+
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
@@ -56,6 +78,8 @@ func (f *x402Facilitator) RegisterV1(networks []Network, facilitator SchemeNetwo
 		networkSet[network] = true
 	}
 
+	warnShadowedNetworks(f.schemesV1, facilitator.Scheme(), networks, f.logger)
+
 	// Append to array (supports multiple facilitators with same scheme name)
 	f.schemesV1 = append(f.schemesV1, &schemeData{
 		facilitator: facilitator,
@@ -68,7 +92,10 @@ func (f *x402Facilitator) RegisterV1(networks []Network, facilitator SchemeNetwo
 
 // Register registers a facilitator mechanism for multiple networks (V2, default)
 // Networks are stored and used for GetSupported() - no need to specify them later.
+// Panics with a *NetworkPatternError if any network is malformed (see ValidateNetworkPattern).
 func (f *x402Facilitator) Register(networks []Network, facilitator SchemeNetworkFacilitator) *x402Facilitator {
+	mustValidateNetworks(ValidateNetworkPattern, networks...)
+
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
@@ -78,6 +105,8 @@ func (f *x402Facilitator) Register(networks []Network, facilitator SchemeNetwork
 		networkSet[network] = true
 	}
 
+	warnShadowedNetworks(f.schemes, facilitator.Scheme(), networks, f.logger)
+
 	// Append to array (supports multiple facilitators with same scheme name)
 	f.schemes = append(f.schemes, &schemeData{
 		facilitator: facilitator,
@@ -156,10 +185,36 @@ func (f *x402Facilitator) OnSettleFailure(hook FacilitatorOnSettleFailureHook) *
 
 // Verify verifies a payment (detects version from bytes, routes to typed mechanism)
 func (f *x402Facilitator) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*VerifyResponse, error) {
+	if f.metrics == nil && f.tracer == nil && isNoopLogger(f.logger) {
+		return f.verify(ctx, payloadBytes, requirementsBytes)
+	}
+
+	start := time.Now()
+	req := parseFacilitatorRequest(payloadBytes, requirementsBytes)
+	ctx, span := f.startSpan(ctx, "x402.facilitator.Verify", req)
+
+	result, err := f.verify(ctx, payloadBytes, requirementsBytes)
+
+	reason := verifyMetricReason(result, err)
+	if f.metrics != nil {
+		f.metrics.ObserveVerify(req.scheme, req.network, reason, time.Since(start))
+	}
+	payer := errorPayer(err)
+	if result != nil {
+		payer = result.Payer
+	}
+	endSpan(span, payer, reason, err)
+	f.logVerify(req.scheme, req.network, payer, reason, err)
+
+	return result, err
+}
+
+// verify runs hooks and routes a verification to the mechanism for its version
+func (f *x402Facilitator) verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*VerifyResponse, error) {
 	// Detect version
 	version, err := types.DetectVersion(payloadBytes)
 	if err != nil {
-		return nil, NewVerifyError("invalid_version", "", "", err)
+		return nil, NewVerifyError(ReasonInvalidVersion, "", "", err)
 	}
 
 	// Unmarshal to typed structs for hooks
@@ -171,11 +226,11 @@ func (f *x402Facilitator) Verify(ctx context.Context, payloadBytes []byte, requi
 	case 1:
 		payload, err := types.ToPaymentPayloadV1(payloadBytes)
 		if err != nil {
-			return nil, NewVerifyError("invalid_v1_payload", "", "", err)
+			return nil, NewVerifyError(ReasonInvalidV1Payload, "", "", err)
 		}
 		requirements, err := types.ToPaymentRequirementsV1(requirementsBytes)
 		if err != nil {
-			return nil, NewVerifyError("invalid_v1_requirements", "", "", err)
+			return nil, NewVerifyError(ReasonInvalidV1Requirements, "", "", err)
 		}
 
 		hookPayload = *payload
@@ -225,11 +280,11 @@ func (f *x402Facilitator) Verify(ctx context.Context, payloadBytes []byte, requi
 	case 2:
 		payload, err := types.ToPaymentPayload(payloadBytes)
 		if err != nil {
-			return nil, NewVerifyError("invalid_v2_payload", "", "", err)
+			return nil, NewVerifyError(ReasonInvalidV2Payload, "", "", err)
 		}
 		requirements, err := types.ToPaymentRequirements(requirementsBytes)
 		if err != nil {
-			return nil, NewVerifyError("invalid_v2_requirements", "", "", err)
+			return nil, NewVerifyError(ReasonInvalidV2Requirements, "", "", err)
 		}
 
 		hookPayload = *payload
@@ -283,125 +338,91 @@ func (f *x402Facilitator) Verify(ctx context.Context, payloadBytes []byte, requi
 
 // Settle settles a payment (detects version from bytes, routes to typed mechanism)
 func (f *x402Facilitator) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*SettleResponse, error) {
+	if f.metrics == nil && f.tracer == nil && isNoopLogger(f.logger) {
+		return f.settle(ctx, payloadBytes, requirementsBytes)
+	}
+
+	start := time.Now()
+	req := parseFacilitatorRequest(payloadBytes, requirementsBytes)
+	ctx, span := f.startSpan(ctx, "x402.facilitator.Settle", req)
+
+	result, err := f.settle(ctx, payloadBytes, requirementsBytes)
+
+	reason := settleMetricReason(result, err)
+	if f.metrics != nil {
+		f.metrics.ObserveSettle(req.scheme, req.network, reason, time.Since(start))
+	}
+	payer := errorPayer(err)
+	if result != nil {
+		payer = result.Payer
+	}
+	endSpan(span, payer, reason, err)
+	f.logSettle(req.scheme, req.network, result, reason, err)
+
+	return result, err
+}
+
+// settle runs hooks and routes a settlement to the mechanism for its version
+func (f *x402Facilitator) settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*SettleResponse, error) {
 	// Detect version
 	version, err := types.DetectVersion(payloadBytes)
 	if err != nil {
-		return nil, NewSettleError("invalid_version", "", "", "", err)
+		return nil, NewSettleError(ReasonInvalidVersion, "", "", "", err)
 	}
 
-	// Unmarshal to typed structs for hooks
-	var hookPayload PaymentPayloadView
-	var hookRequirements PaymentRequirementsView
-
 	// Route to version-specific method
 	switch version {
 	case 1:
 		payload, err := types.ToPaymentPayloadV1(payloadBytes)
 		if err != nil {
-			return nil, NewSettleError("invalid_v1_payload", "", "", "", err)
+			return nil, NewSettleError(ReasonInvalidV1Payload, "", "", "", err)
 		}
 		requirements, err := types.ToPaymentRequirementsV1(requirementsBytes)
 		if err != nil {
-			return nil, NewSettleError("invalid_v1_requirements", "", "", "", err)
+			return nil, NewSettleError(ReasonInvalidV1Requirements, "", "", "", err)
 		}
 
-		hookPayload = *payload
-		hookRequirements = *requirements
-
 		// Execute beforeSettle hooks
 		hookCtx := FacilitatorSettleContext{
 			Ctx:               ctx,
-			Payload:           hookPayload,
-			Requirements:      hookRequirements,
+			Payload:           *payload,
+			Requirements:      *requirements,
 			PayloadBytes:      payloadBytes,
 			RequirementsBytes: requirementsBytes,
 		}
-		for _, hook := range f.beforeSettleHooks {
-			result, err := hook(hookCtx)
-			if err != nil {
-				return nil, err
-			}
-			if result != nil && result.Abort {
-				return nil, NewSettleError(result.Reason, "", "", "", nil)
-			}
+		if err := f.runBeforeSettleHooks(hookCtx); err != nil {
+			return nil, err
 		}
 
 		// Call mechanism
 		settleResult, settleErr := f.settleV1(ctx, *payload, *requirements)
-
-		// Handle failure
-		if settleErr != nil {
-			failureCtx := FacilitatorSettleFailureContext{FacilitatorSettleContext: hookCtx, Error: settleErr}
-			for _, hook := range f.onSettleFailureHooks {
-				result, _ := hook(failureCtx)
-				if result != nil && result.Recovered {
-					return result.Result, nil
-				}
-			}
-			return nil, settleErr
-		}
-
-		// Execute afterSettle hooks
-		resultCtx := FacilitatorSettleResultContext{FacilitatorSettleContext: hookCtx, Result: settleResult}
-		for _, hook := range f.afterSettleHooks {
-			_ = hook(resultCtx) // Log errors but don't fail
-		}
-
-		return settleResult, nil
+		return f.finishSettle(hookCtx, settleResult, settleErr)
 
 	case 2:
 		payload, err := types.ToPaymentPayload(payloadBytes)
 		if err != nil {
-			return nil, NewSettleError("invalid_v2_payload", "", "", "", err)
+			return nil, NewSettleError(ReasonInvalidV2Payload, "", "", "", err)
 		}
 		requirements, err := types.ToPaymentRequirements(requirementsBytes)
 		if err != nil {
-			return nil, NewSettleError("invalid_v2_requirements", "", "", "", err)
+			return nil, NewSettleError(ReasonInvalidV2Requirements, "", "", "", err)
 		}
 
-		hookPayload = *payload
-		hookRequirements = *requirements
-
 		// Execute beforeSettle hooks
 		hookCtx := FacilitatorSettleContext{
 			Ctx:               ctx,
-			Payload:           hookPayload,
-			Requirements:      hookRequirements,
+			Payload:           *payload,
+			Requirements:      *requirements,
 			PayloadBytes:      payloadBytes,
 			RequirementsBytes: requirementsBytes,
 		}
-		for _, hook := range f.beforeSettleHooks {
-			result, err := hook(hookCtx)
-			if err != nil {
-				return nil, err
-			}
-			if result != nil && result.Abort {
-				return nil, NewSettleError(result.Reason, "", "", "", nil)
-			}
+		if err := f.runBeforeSettleHooks(hookCtx); err != nil {
+			return nil, err
 		}
 
 		// Call mechanism
 		settleResult, settleErr := f.settleV2(ctx, *payload, *requirements)
-
-		// Handle failure
-		if settleErr != nil {
-			failureCtx := FacilitatorSettleFailureContext{FacilitatorSettleContext: hookCtx, Error: settleErr}
-			for _, hook := range f.onSettleFailureHooks {
-				result, _ := hook(failureCtx)
-				if result != nil && result.Recovered {
-					return result.Result, nil
-				}
-			}
-			return nil, settleErr
-		}
-
-		// Execute afterSettle hooks
-		resultCtx := FacilitatorSettleResultContext{FacilitatorSettleContext: hookCtx, Result: settleResult}
-		for _, hook := range f.afterSettleHooks {
-			_ = hook(resultCtx) // Log errors but don't fail
-		}
-
-		return settleResult, nil
+		return f.finishSettle(hookCtx, settleResult, settleErr)
 
 	default:
 		return nil, NewSettleError(fmt.Sprintf("unsupported_version_%d", version), "", "", "", nil)
@@ -414,98 +435,423 @@ func (f *x402Facilitator) Settle(ctx context.Context, payloadBytes []byte, requi
 
 // verifyV1 verifies a V1 payment (internal, typed)
 func (f *x402Facilitator) verifyV1(ctx context.Context, payload types.PaymentPayloadV1, requirements types.PaymentRequirementsV1) (*VerifyResponse, error) {
-	f.mu.RLock()
-	defer f.mu.RUnlock()
-
 	scheme := requirements.Scheme
 	network := Network(requirements.Network)
 
-	// Find matching facilitator from array
-	for _, data := range f.schemesV1 {
-		facilitator := data.facilitator.(SchemeNetworkFacilitatorV1)
-		if facilitator.Scheme() != scheme {
-			continue
-		}
-
-		// Check if network matches (exact or pattern)
-		if matchesSchemeData(data, network) {
-			return facilitator.Verify(ctx, payload, requirements)
-		}
+	// Find matching facilitator (explicit network before wildcard pattern)
+	if facilitator := f.findFacilitatorV1(scheme, network); facilitator != nil {
+		return facilitator.Verify(ctx, payload, requirements)
 	}
 
-	return nil, NewVerifyError("no_facilitator_for_network", "", network, fmt.Errorf("no facilitator for scheme %s on network %s", scheme, network))
+	return nil, NewVerifyError(ReasonNoFacilitatorForNetwork, "", network, fmt.Errorf("no facilitator for scheme %s on network %s", scheme, network))
 }
 
 // verifyV2 verifies a V2 payment (internal, typed)
 func (f *x402Facilitator) verifyV2(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*VerifyResponse, error) {
+	scheme := requirements.Scheme
+	network := Network(requirements.Network)
+
+	// Find matching facilitator (explicit network before wildcard pattern)
+	if facilitator := f.findFacilitator(scheme, network); facilitator != nil {
+		return facilitator.Verify(ctx, payload, requirements)
+	}
+
+	return nil, NewVerifyError(ReasonNoFacilitatorForNetwork, "", network, fmt.Errorf("no facilitator for scheme %s on network %s", scheme, network))
+}
+
+// settleV1 settles a V1 payment (internal, typed)
+func (f *x402Facilitator) settleV1(ctx context.Context, payload types.PaymentPayloadV1, requirements types.PaymentRequirementsV1) (*SettleResponse, error) {
+	scheme := requirements.Scheme
+	network := Network(requirements.Network)
+
+	// Find matching facilitator (explicit network before wildcard pattern)
+	if facilitator := f.findFacilitatorV1(scheme, network); facilitator != nil {
+		return facilitator.Settle(ctx, payload, requirements)
+	}
+
+	return nil, NewSettleError(ReasonNoFacilitatorForNetwork, "", network, "", fmt.Errorf("no facilitator for scheme %s on network %s", scheme, network))
+}
+
+// settleV2 settles a V2 payment (internal, typed)
+func (f *x402Facilitator) settleV2(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+	scheme := requirements.Scheme
+	network := Network(requirements.Network)
+
+	// Find matching facilitator (explicit network before wildcard pattern)
+	if facilitator := f.findFacilitator(scheme, network); facilitator != nil {
+		return facilitator.Settle(ctx, payload, requirements)
+	}
+
+	return nil, NewSettleError(ReasonNoFacilitatorForNetwork, "", network, "", fmt.Errorf("no facilitator for scheme %s on network %s", scheme, network))
+}
+
+// findFacilitatorV1 returns the V1 mechanism registered for scheme on network, or nil. The
+// registry is only locked for the lookup, so a slow settlement never holds up registrations
+// or payments on other networks.
+func (f *x402Facilitator) findFacilitatorV1(scheme string, network Network) SchemeNetworkFacilitatorV1 {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
-	scheme := requirements.Scheme
+	if data := findSchemeData(f.schemesV1, scheme, network); data != nil {
+		return data.facilitator.(SchemeNetworkFacilitatorV1)
+	}
+	return nil
+}
+
+// findFacilitator returns the V2 mechanism registered for scheme on network, or nil (see
+// findFacilitatorV1)
+func (f *x402Facilitator) findFacilitator(scheme string, network Network) SchemeNetworkFacilitator {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if data := findSchemeData(f.schemes, scheme, network); data != nil {
+		return data.facilitator.(SchemeNetworkFacilitator)
+	}
+	return nil
+}
+
+// ============================================================================
+// Settlement Simulation
+// ============================================================================
+
+// SimulateSettle reports whether Settle would succeed for a payment, without submitting
+// anything. The payment's V2 mechanism must implement SettlementSimulator; V1 payments and
+// other mechanisms fail with ReasonSimulationNotSupported. Settle hooks, metrics and logs
+// are not run, since nothing is settled.
+func (f *x402Facilitator) SimulateSettle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*SettleResponse, error) {
+	version, err := types.DetectVersion(payloadBytes)
+	if err != nil {
+		return nil, NewSettleError(ReasonInvalidVersion, "", "", "", err)
+	}
+	if version != 2 {
+		return nil, NewSettleError(ReasonSimulationNotSupported, "", "", "", fmt.Errorf("cannot simulate v%d payments", version))
+	}
+
+	payload, err := types.ToPaymentPayload(payloadBytes)
+	if err != nil {
+		return nil, NewSettleError(ReasonInvalidV2Payload, "", "", "", err)
+	}
+	requirements, err := types.ToPaymentRequirements(requirementsBytes)
+	if err != nil {
+		return nil, NewSettleError(ReasonInvalidV2Requirements, "", "", "", err)
+	}
+
 	network := Network(requirements.Network)
+	simulator, found, ok := f.findSettlementSimulator(requirements)
+	if !found {
+		return nil, NewSettleError(ReasonNoFacilitatorForNetwork, "", network, "", fmt.Errorf("no facilitator for scheme %s on network %s", requirements.Scheme, network))
+	}
+	if !ok {
+		return nil, NewSettleError(ReasonSimulationNotSupported, "", network, "", fmt.Errorf("scheme %s on network %s cannot simulate settlements", requirements.Scheme, network))
+	}
+
+	return simulator.SimulateSettle(ctx, *payload, *requirements)
+}
+
+// findSettlementSimulator returns the V2 mechanism for requirements if it can simulate
+// settlements, and whether any mechanism matched at all
+func (f *x402Facilitator) findSettlementSimulator(requirements *types.PaymentRequirements) (SettlementSimulator, bool, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	data := findSchemeData(f.schemes, requirements.Scheme, Network(requirements.Network))
+	if data == nil {
+		return nil, false, false
+	}
+	simulator, ok := data.facilitator.(SettlementSimulator)
+	return simulator, true, ok
+}
+
+// ============================================================================
+// Health Check
+// ============================================================================
+
+// HealthCheck checks every network registered with a V2 mechanism that implements
+// HealthChecker, all at once. Networks whose mechanism can't check its health are left out.
+// The status is healthy when every checked network is, so a /health endpoint can return
+// 503 when it isn't.
+//
+// Networks are grouped by mechanism in registration order.
+func (f *x402Facilitator) HealthCheck(ctx context.Context) HealthStatus {
+	type check struct {
+		checker HealthChecker
+		network Network
+	}
 
-	// Find matching facilitator from array
+	f.mu.RLock()
+	var checks []check
 	for _, data := range f.schemes {
-		facilitator := data.facilitator.(SchemeNetworkFacilitator)
-		if facilitator.Scheme() != scheme {
+		checker, ok := data.facilitator.(HealthChecker)
+		if !ok {
 			continue
 		}
+		networks := make([]Network, 0, len(data.networks))
+		for network := range data.networks {
+			networks = append(networks, network)
+		}
+		slices.Sort(networks)
+		for _, network := range networks {
+			checks = append(checks, check{checker: checker, network: network})
+		}
+	}
+	f.mu.RUnlock()
+
+	status := HealthStatus{Healthy: true, Networks: make([]NetworkHealth, len(checks))}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status.Networks[i] = c.checker.HealthCheck(ctx, c.network)
+		}()
+	}
+	wg.Wait()
 
-		// Check if network matches (exact or pattern)
-		if matchesSchemeData(data, network) {
-			return facilitator.Verify(ctx, payload, requirements)
+	for _, health := range status.Networks {
+		if !health.Healthy {
+			status.Healthy = false
 		}
 	}
+	return status
+}
 
-	return nil, NewVerifyError("no_facilitator_for_network", "", network, fmt.Errorf("no facilitator for scheme %s on network %s", scheme, network))
+// ============================================================================
+// Batch Settlement
+// ============================================================================
+
+// batchSettleItem is a decoded V2 payment waiting to be settled as part of a batch
+type batchSettleItem struct {
+	index        int
+	hookCtx      FacilitatorSettleContext
+	payload      types.PaymentPayload
+	requirements types.PaymentRequirements
 }
 
-// settleV1 settles a V1 payment (internal, typed)
-func (f *x402Facilitator) settleV1(ctx context.Context, payload types.PaymentPayloadV1, requirements types.PaymentRequirementsV1) (*SettleResponse, error) {
-	f.mu.RLock()
-	defer f.mu.RUnlock()
+// batchSettleGroup collects payments settled by the same mechanism on the same network
+type batchSettleGroup struct {
+	settler BatchSettler
+	items   []batchSettleItem
+}
 
-	scheme := requirements.Scheme
-	network := Network(requirements.Network)
+// SettleBatch settles several payments, batching them where the mechanism supports it.
+//
+// V2 payments handled by a mechanism implementing BatchSettler are grouped by mechanism
+// and network and handed over together. Everything else, and any group the mechanism
+// rejects as a whole, is settled one payment at a time.
+//
+// Returns one result per request, in order. Failed payments are reported as
+// SettleResponse{Success: false} with their own ErrorReason rather than failing the call;
+// the error is only returned if the context is cancelled before settlement starts.
+// Settle hooks run for each payment as they do for Settle.
+func (f *x402Facilitator) SettleBatch(ctx context.Context, requests []SettlementRequest) ([]*SettleResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	// Find matching facilitator from array
-	for _, data := range f.schemesV1 {
-		facilitator := data.facilitator.(SchemeNetworkFacilitatorV1)
-		if facilitator.Scheme() != scheme {
+	ctx, span := StartSpan(ctx, f.tracer, "x402.facilitator.SettleBatch")
+	defer span.End()
+	span.SetAttribute(AttrBatchSize, strconv.Itoa(len(requests)))
+
+	results := make([]*SettleResponse, len(requests))
+	groups := make(map[string]*batchSettleGroup)
+	var groupOrder []string
+
+	for i, req := range requests {
+		version, err := types.DetectVersion(req.PayloadBytes)
+		if err != nil || version != 2 {
+			results[i] = f.settleRequest(ctx, req)
+			continue
+		}
+		payload, err := types.ToPaymentPayload(req.PayloadBytes)
+		if err != nil {
+			results[i] = f.settleRequest(ctx, req)
+			continue
+		}
+		requirements, err := types.ToPaymentRequirements(req.RequirementsBytes)
+		if err != nil {
+			results[i] = f.settleRequest(ctx, req)
+			continue
+		}
+
+		settler, ok := f.findBatchSettler(requirements)
+		if !ok {
+			results[i] = f.settleRequest(ctx, req)
+			continue
+		}
+
+		// Execute beforeSettle hooks
+		hookCtx := FacilitatorSettleContext{
+			Ctx:               ctx,
+			Payload:           *payload,
+			Requirements:      *requirements,
+			PayloadBytes:      req.PayloadBytes,
+			RequirementsBytes: req.RequirementsBytes,
+		}
+		if err := f.runBeforeSettleHooks(hookCtx); err != nil {
+			results[i] = settleErrorResponse(err, Network(requirements.Network))
 			continue
 		}
 
-		// Check if network matches (exact or pattern)
-		if matchesSchemeData(data, network) {
-			return facilitator.Settle(ctx, payload, requirements)
+		key := fmt.Sprintf("%p|%s", settler, requirements.Network)
+		group, exists := groups[key]
+		if !exists {
+			group = &batchSettleGroup{settler: settler}
+			groups[key] = group
+			groupOrder = append(groupOrder, key)
+		}
+		group.items = append(group.items, batchSettleItem{
+			index:        i,
+			hookCtx:      hookCtx,
+			payload:      *payload,
+			requirements: *requirements,
+		})
+	}
+
+	for _, key := range groupOrder {
+		group := groups[key]
+		batchStart := time.Now()
+
+		var batchResults []*SettleResponse
+		if len(group.items) > 1 {
+			payloads := make([]types.PaymentPayload, len(group.items))
+			requirements := make([]types.PaymentRequirements, len(group.items))
+			for j, item := range group.items {
+				payloads[j] = item.payload
+				requirements[j] = item.requirements
+			}
+			var err error
+			batchResults, err = group.settler.SettleBatch(ctx, payloads, requirements)
+			if err == nil && len(batchResults) != len(group.items) {
+				err = fmt.Errorf("batch settler returned %d results for %d payments", len(batchResults), len(group.items))
+			}
+			if err != nil {
+				f.logger.Warn("batch settlement failed, settling individually",
+					"scheme", group.items[0].requirements.Scheme,
+					"network", group.items[0].requirements.Network,
+					"batchSize", len(group.items),
+					"error", err)
+				batchResults = nil
+			}
+		}
+
+		batchElapsed := time.Since(batchStart)
+
+		for j, item := range group.items {
+			var settleResult *SettleResponse
+			var settleErr error
+			elapsed := batchElapsed // Payments settled together share the batch's latency
+			if batchResults != nil {
+				settleResult = batchResults[j]
+				if settleResult == nil || !settleResult.Success {
+					settleErr = settleResponseError(settleResult, Network(item.requirements.Network))
+					settleResult = nil
+				}
+			} else {
+				start := time.Now()
+				settleResult, settleErr = f.settleV2(ctx, item.payload, item.requirements)
+				elapsed = time.Since(start)
+			}
+			result, err := f.finishSettle(item.hookCtx, settleResult, settleErr)
+			if err != nil {
+				result = settleErrorResponse(err, Network(item.requirements.Network))
+			}
+			results[item.index] = result
+
+			reason := settleMetricReason(results[item.index], nil)
+			if f.metrics != nil {
+				f.metrics.ObserveSettle(item.requirements.Scheme, item.requirements.Network, reason, elapsed)
+			}
+			f.logSettle(item.requirements.Scheme, item.requirements.Network, results[item.index], reason, settleErr)
 		}
 	}
 
-	return nil, NewSettleError("no_facilitator_for_network", "", network, "", fmt.Errorf("no facilitator for scheme %s on network %s", scheme, network))
+	return results, nil
 }
 
-// settleV2 settles a V2 payment (internal, typed)
-func (f *x402Facilitator) settleV2(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+// settleRequest settles a single request through Settle, reporting errors as a failed response
+func (f *x402Facilitator) settleRequest(ctx context.Context, req SettlementRequest) *SettleResponse {
+	result, err := f.Settle(ctx, req.PayloadBytes, req.RequirementsBytes)
+	if err != nil {
+		return settleErrorResponse(err, "")
+	}
+	return result
+}
+
+// findBatchSettler returns the V2 mechanism for requirements if it supports batch settlement
+func (f *x402Facilitator) findBatchSettler(requirements *types.PaymentRequirements) (BatchSettler, bool) {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
-	scheme := requirements.Scheme
-	network := Network(requirements.Network)
+	data := findSchemeData(f.schemes, requirements.Scheme, Network(requirements.Network))
+	if data == nil {
+		return nil, false
+	}
+	settler, ok := data.facilitator.(BatchSettler)
+	return settler, ok
+}
 
-	// Find matching facilitator from array
-	for _, data := range f.schemes {
-		facilitator := data.facilitator.(SchemeNetworkFacilitator)
-		if facilitator.Scheme() != scheme {
-			continue
+// runBeforeSettleHooks executes beforeSettle hooks, returning an error if one aborts
+func (f *x402Facilitator) runBeforeSettleHooks(hookCtx FacilitatorSettleContext) error {
+	for _, hook := range f.beforeSettleHooks {
+		result, err := hook(hookCtx)
+		if err != nil {
+			return err
+		}
+		if result != nil && result.Abort {
+			return NewSettleError(result.Reason, "", "", "", nil)
 		}
+	}
+	return nil
+}
 
-		// Check if network matches (exact or pattern)
-		if matchesSchemeData(data, network) {
-			return facilitator.Settle(ctx, payload, requirements)
+// finishSettle runs the failure or afterSettle hooks for a settled payment and returns its
+// result, or the mechanism's error unless a failure hook recovered it
+func (f *x402Facilitator) finishSettle(hookCtx FacilitatorSettleContext, settleResult *SettleResponse, settleErr error) (*SettleResponse, error) {
+	if settleErr != nil {
+		failureCtx := FacilitatorSettleFailureContext{FacilitatorSettleContext: hookCtx, Error: settleErr}
+		for _, hook := range f.onSettleFailureHooks {
+			result, _ := hook(failureCtx)
+			if result != nil && result.Recovered {
+				return result.Result, nil
+			}
 		}
+		return nil, settleErr
+	}
+
+	resultCtx := FacilitatorSettleResultContext{FacilitatorSettleContext: hookCtx, Result: settleResult}
+	for _, hook := range f.afterSettleHooks {
+		_ = hook(resultCtx) // Log errors but don't fail
 	}
+	return settleResult, nil
+}
 
-	return nil, NewSettleError("no_facilitator_for_network", "", network, "", fmt.Errorf("no facilitator for scheme %s on network %s", scheme, network))
+// settleErrorResponse converts a settle error into a failed SettleResponse
+func settleErrorResponse(err error, network Network) *SettleResponse {
+	var se *SettleError
+	if errors.As(err, &se) {
+		if se.Network != "" {
+			network = se.Network
+		}
+		return &SettleResponse{
+			Success:     false,
+			ErrorReason: se.Reason,
+			Payer:       se.Payer,
+			Transaction: se.Transaction,
+			Network:     network,
+		}
+	}
+	return &SettleResponse{Success: false, ErrorReason: err.Error(), Network: network}
+}
+
+// settleResponseError converts a failed SettleResponse from a batch into a SettleError
+func settleResponseError(result *SettleResponse, network Network) error {
+	if result == nil {
+		return NewSettleError(ReasonTransactionFailed, "", network, "", errors.New("missing batch result"))
+	}
+	if result.Network != "" {
+		network = result.Network
+	}
+	return NewSettleError(result.ErrorReason, result.Payer, network, result.Transaction, nil)
 }
 
 // GetSupported returns supported payment kinds
@@ -516,6 +862,22 @@ func (f *x402Facilitator) settleV2(ctx context.Context, payload types.PaymentPay
 //
 //	SupportedResponse with kinds as array (with version in each element), extensions, and signers
 func (f *x402Facilitator) GetSupported() SupportedResponse {
+	return f.GetSupportedFor(nil, nil)
+}
+
+// GetSupportedFor returns the supported payment kinds for the given schemes and networks.
+// An empty filter matches everything, and network filters may be patterns such as "eip155:*".
+// Signers are only listed for the CAIP families of the kinds returned.
+//
+// Args:
+//
+//	schemes: Schemes to include (e.g. "exact"), or nil for all
+//	networks: Networks to include, or nil for all
+//
+// Returns:
+//
+//	SupportedResponse with the matching kinds, the extensions, and their signers
+func (f *x402Facilitator) GetSupportedFor(schemes []string, networks []Network) SupportedResponse {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
@@ -528,6 +890,9 @@ func (f *x402Facilitator) GetSupported() SupportedResponse {
 		scheme := facilitator.Scheme()
 
 		for network := range data.networks {
+			if !supportedKindMatches(scheme, network, schemes, networks) {
+				continue
+			}
 			kind := SupportedKind{
 				X402Version: 1,
 				Scheme:      scheme,
@@ -555,6 +920,9 @@ func (f *x402Facilitator) GetSupported() SupportedResponse {
 		scheme := facilitator.Scheme()
 
 		for network := range data.networks {
+			if !supportedKindMatches(scheme, network, schemes, networks) {
+				continue
+			}
 			kind := SupportedKind{
 				X402Version: 2,
 				Scheme:      scheme,
@@ -593,6 +961,22 @@ func (f *x402Facilitator) GetSupported() SupportedResponse {
 	}
 }
 
+// supportedKindMatches reports whether a registered scheme and network pass GetSupportedFor's filters
+func supportedKindMatches(scheme string, network Network, schemes []string, networks []Network) bool {
+	if len(schemes) > 0 && !slices.Contains(schemes, scheme) {
+		return false
+	}
+	if len(networks) == 0 {
+		return true
+	}
+	for _, filter := range networks {
+		if network.Match(filter) {
+			return true
+		}
+	}
+	return false
+}
+
 // derivePattern creates a wildcard pattern from an array of networks
 // If all networks share the same namespace, returns wildcard pattern
 // Otherwise returns the first network for exact matching
@@ -624,16 +1008,38 @@ func derivePattern(networks []Network) Network {
 	return networks[0]
 }
 
-// matchesSchemeData checks if a network matches the scheme data
-// Returns true if network is in registered networks or matches the pattern
-func matchesSchemeData(data *schemeData, network Network) bool {
-	// Check exact match first
-	if data.networks[network] {
-		return true
+// warnShadowedNetworks logs the networks that an earlier registration of scheme already lists,
+// since that registration keeps handling them (first wins)
+func warnShadowedNetworks(registrations []*schemeData, scheme string, networks []Network, logger Logger) {
+	for _, data := range registrations {
+		if data.facilitator.(interface{ Scheme() string }).Scheme() != scheme {
+			continue
+		}
+		for _, network := range networks {
+			if data.networks[network] {
+				logger.Warn("network already registered for scheme, keeping the earlier registration", "scheme", scheme, "network", string(network))
+			}
+		}
 	}
+}
 
-	// Try pattern matching
-	return matchesNetworkPattern(string(network), string(data.pattern))
+// findSchemeData returns the registration of scheme that handles network, or nil.
+// A registration listing the network explicitly wins over one that only matches it through
+// a wildcard pattern; among equally specific registrations the first registered wins.
+func findSchemeData(registrations []*schemeData, scheme string, network Network) *schemeData {
+	var patternMatch *schemeData
+	for _, data := range registrations {
+		if data.facilitator.(interface{ Scheme() string }).Scheme() != scheme {
+			continue
+		}
+		if data.networks[network] {
+			return data
+		}
+		if patternMatch == nil && matchesNetworkPattern(string(network), string(data.pattern)) {
+			patternMatch = data
+		}
+	}
+	return patternMatch
 }
 
 // matchesNetworkPattern checks if a concrete network matches a registered pattern
@@ -0,0 +1,117 @@
+// Package grpc connects resource servers to an x402 facilitator over gRPC instead of
+// JSON-over-HTTP. Client implements x402.FacilitatorClient; RegisterFacilitatorServer serves a
+// facilitator. The service is defined in facilitator.proto.
+//
+//	conn, _ := grpc.NewClient("facilitator:50051", grpc.WithTransportCredentials(creds))
+//	server := x402.Newx402ResourceServer(x402.WithFacilitatorClient(x402grpc.NewClient(conn)))
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	x402 "x402-go"
+)
+
+// Client is an x402.FacilitatorClient calling a facilitator's gRPC service
+type Client struct {
+	conn gogrpc.ClientConnInterface
+	opts []gogrpc.CallOption
+}
+
+// NewClient creates a facilitator client on conn. opts apply to every call (e.g. per-RPC
+// credentials); deadlines come from the context.
+func NewClient(conn gogrpc.ClientConnInterface, opts ...gogrpc.CallOption) *Client {
+	return &Client{conn: conn, opts: opts}
+}
+
+// Verify checks if a payment is valid (supports both V1 and V2)
+func (c *Client) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	reply := newMessage(verifyReplyDesc)
+	if err := c.conn.Invoke(ctx, VerifyMethod, paymentRequest(payloadBytes, requirementsBytes), reply, c.opts...); err != nil {
+		return nil, fmt.Errorf("verify request failed: %w", err)
+	}
+
+	if reply.Has(verifyReplyDesc.Fields().ByName("error")) {
+		fe := facilitatorError(reply)
+		return nil, x402.NewVerifyError(fe.reason, fe.payer, x402.Network(fe.network), fe.err)
+	}
+
+	return &x402.VerifyResponse{
+		IsValid:       getBool(reply, "is_valid"),
+		InvalidReason: getString(reply, "invalid_reason"),
+		Payer:         getString(reply, "payer"),
+	}, nil
+}
+
+// Settle executes a payment (supports both V1 and V2)
+func (c *Client) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	reply := newMessage(settleReplyDesc)
+	if err := c.conn.Invoke(ctx, SettleMethod, paymentRequest(payloadBytes, requirementsBytes), reply, c.opts...); err != nil {
+		return nil, fmt.Errorf("settle request failed: %w", err)
+	}
+
+	if reply.Has(settleReplyDesc.Fields().ByName("error")) {
+		fe := facilitatorError(reply)
+		return nil, x402.NewSettleError(fe.reason, fe.payer, x402.Network(fe.network), fe.transaction, fe.err)
+	}
+
+	return &x402.SettleResponse{
+		Success:     getBool(reply, "success"),
+		ErrorReason: getString(reply, "error_reason"),
+		Payer:       getString(reply, "payer"),
+		Transaction: getString(reply, "transaction"),
+		Network:     x402.Network(getString(reply, "network")),
+	}, nil
+}
+
+// GetSupported gets supported payment kinds (shared by both V1 and V2)
+func (c *Client) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	reply := newMessage(supportedReplyDesc)
+	if err := c.conn.Invoke(ctx, SupportedMethod, newMessage(supportedRequestDesc), reply, c.opts...); err != nil {
+		return x402.SupportedResponse{}, fmt.Errorf("supported request failed: %w", err)
+	}
+
+	var supported x402.SupportedResponse
+	if err := json.Unmarshal(getBytes(reply, "supported"), &supported); err != nil {
+		return x402.SupportedResponse{}, fmt.Errorf("failed to decode supported response: %w", err)
+	}
+	return supported, nil
+}
+
+// paymentRequest builds the request shared by Verify and Settle
+func paymentRequest(payloadBytes, requirementsBytes []byte) protoreflect.ProtoMessage {
+	request := newMessage(paymentRequestDesc)
+	setBytes(request, "payment_payload", payloadBytes)
+	setBytes(request, "payment_requirements", requirementsBytes)
+	return request
+}
+
+// decodedError holds the fields of a FacilitatorError message
+type decodedError struct {
+	reason      string
+	payer       string
+	network     string
+	transaction string
+	err         error
+}
+
+// facilitatorError reads the error field of a Verify or Settle reply
+func facilitatorError(reply protoreflect.Message) decodedError {
+	m := reply.Get(reply.Descriptor().Fields().ByName("error")).Message()
+	decoded := decodedError{
+		reason:      getString(m, "reason"),
+		payer:       getString(m, "payer"),
+		network:     getString(m, "network"),
+		transaction: getString(m, "transaction"),
+	}
+	if message := getString(m, "message"); message != "" {
+		decoded.err = errors.New(message)
+	}
+	return decoded
+}
@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	x402 "x402-go"
+	"x402-go/types"
+)
+
+// fakeFacilitator answers with fixed results, recording what it received
+type fakeFacilitator struct {
+	verifyErr    error
+	settleErr    error
+	payload      []byte
+	requirements []byte
+}
+
+func (f *fakeFacilitator) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	f.payload, f.requirements = payloadBytes, requirementsBytes
+	if f.verifyErr != nil {
+		return nil, f.verifyErr
+	}
+	return &x402.VerifyResponse{IsValid: true, Payer: "0xPayer"}, nil
+}
+
+func (f *fakeFacilitator) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	if f.settleErr != nil {
+		return nil, f.settleErr
+	}
+	return &x402.SettleResponse{Success: true, Payer: "0xPayer", Transaction: "0xabc", Network: "eip155:8453"}, nil
+}
+
+func (f *fakeFacilitator) GetSupported() x402.SupportedResponse {
+	return x402.SupportedResponse{
+		Kinds:      []types.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:8453"}},
+		Extensions: []string{},
+		Signers:    map[string][]string{"eip155:*": {"0xFacilitator"}},
+	}
+}
+
+// newTestClient serves facilitator over an in-memory connection
+func newTestClient(t *testing.T, facilitator Facilitator) *Client {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := gogrpc.NewServer()
+	RegisterFacilitatorServer(server, facilitator)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := gogrpc.NewClient("passthrough:///bufnet",
+		gogrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		gogrpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewClient(conn)
+}
+
+func TestClientVerify(t *testing.T) {
+	ctx := context.Background()
+	payload := []byte(`{"x402Version":2}`)
+	requirements := []byte(`{"scheme":"exact"}`)
+
+	t.Run("valid payment", func(t *testing.T) {
+		facilitator := &fakeFacilitator{}
+		client := newTestClient(t, facilitator)
+
+		result, err := client.Verify(ctx, payload, requirements)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.IsValid || result.Payer != "0xPayer" {
+			t.Errorf("Unexpected response: %+v", result)
+		}
+		if string(facilitator.payload) != string(payload) || string(facilitator.requirements) != string(requirements) {
+			t.Errorf("Expected the payload and requirements to be forwarded unchanged")
+		}
+	})
+
+	t.Run("verify error keeps its reason", func(t *testing.T) {
+		client := newTestClient(t, &fakeFacilitator{
+			verifyErr: x402.NewVerifyError(x402.ReasonInsufficientAmount, "0xPayer", "eip155:8453", errors.New("too little")),
+		})
+
+		_, err := client.Verify(ctx, payload, requirements)
+		var ve *x402.VerifyError
+		if !errors.As(err, &ve) {
+			t.Fatalf("Expected VerifyError, got %v", err)
+		}
+		if ve.Reason != x402.ReasonInsufficientAmount || ve.Payer != "0xPayer" || ve.Network != "eip155:8453" {
+			t.Errorf("Unexpected error fields: %+v", ve)
+		}
+		if ve.Err == nil || ve.Err.Error() != "too little" {
+			t.Errorf("Expected the underlying error message, got %v", ve.Err)
+		}
+	})
+
+	t.Run("other errors are transport failures", func(t *testing.T) {
+		client := newTestClient(t, &fakeFacilitator{verifyErr: errors.New("boom")})
+
+		_, err := client.Verify(ctx, payload, requirements)
+		var ve *x402.VerifyError
+		if err == nil || errors.As(err, &ve) {
+			t.Fatalf("Expected a plain error, got %v", err)
+		}
+	})
+}
+
+func TestClientSettle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful settlement", func(t *testing.T) {
+		client := newTestClient(t, &fakeFacilitator{})
+
+		result, err := client.Settle(ctx, []byte(`{}`), []byte(`{}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.Success || result.Transaction != "0xabc" || result.Network != "eip155:8453" {
+			t.Errorf("Unexpected response: %+v", result)
+		}
+	})
+
+	t.Run("settle error keeps its transaction", func(t *testing.T) {
+		client := newTestClient(t, &fakeFacilitator{
+			settleErr: x402.NewSettleError(x402.ReasonTransactionFailed, "0xPayer", "eip155:8453", "0xdef", nil),
+		})
+
+		_, err := client.Settle(ctx, []byte(`{}`), []byte(`{}`))
+		var se *x402.SettleError
+		if !errors.As(err, &se) {
+			t.Fatalf("Expected SettleError, got %v", err)
+		}
+		if se.Reason != x402.ReasonTransactionFailed || se.Transaction != "0xdef" || se.Err != nil {
+			t.Errorf("Unexpected error fields: %+v", se)
+		}
+	})
+}
+
+func TestClientGetSupported(t *testing.T) {
+	client := newTestClient(t, &fakeFacilitator{})
+
+	supported, err := client.GetSupported(context.Background())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(supported.Kinds) != 1 || supported.Kinds[0].Network != "eip155:8453" {
+		t.Errorf("Unexpected kinds: %+v", supported.Kinds)
+	}
+	if supported.Signers["eip155:*"][0] != "0xFacilitator" {
+		t.Errorf("Unexpected signers: %+v", supported.Signers)
+	}
+}
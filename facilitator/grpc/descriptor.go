@@ -0,0 +1,153 @@
+package grpc
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ServiceName is the fully-qualified name of the Facilitator service in facilitator.proto
+const ServiceName = "x402.facilitator.v1.Facilitator"
+
+// Full method names, as gRPC interceptors see them
+const (
+	VerifyMethod    = "/" + ServiceName + "/Verify"
+	SettleMethod    = "/" + ServiceName + "/Settle"
+	SupportedMethod = "/" + ServiceName + "/Supported"
+)
+
+// Message descriptors of facilitator.proto. The file is described in code rather than generated,
+// and its messages are handled as dynamic messages, which marshal to the same protobuf wire format.
+var (
+	paymentRequestDesc   protoreflect.MessageDescriptor
+	verifyReplyDesc      protoreflect.MessageDescriptor
+	settleReplyDesc      protoreflect.MessageDescriptor
+	facilitatorErrorDesc protoreflect.MessageDescriptor
+	supportedRequestDesc protoreflect.MessageDescriptor
+	supportedReplyDesc   protoreflect.MessageDescriptor
+)
+
+func init() {
+	file, err := protodesc.NewFile(facilitatorProto(), nil)
+	if err != nil {
+		panic("x402 facilitator grpc: invalid descriptor: " + err.Error())
+	}
+
+	messages := file.Messages()
+	paymentRequestDesc = messages.ByName("PaymentRequest")
+	verifyReplyDesc = messages.ByName("VerifyReply")
+	settleReplyDesc = messages.ByName("SettleReply")
+	facilitatorErrorDesc = messages.ByName("FacilitatorError")
+	supportedRequestDesc = messages.ByName("SupportedRequest")
+	supportedReplyDesc = messages.ByName("SupportedReply")
+}
+
+// facilitatorProto describes facilitator.proto. TestDescriptorMatchesProtoFile parses the .proto
+// file and fails when the two drift apart.
+func facilitatorProto() *descriptorpb.FileDescriptorProto {
+	errorType := ".x402.facilitator.v1.FacilitatorError"
+
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("facilitator.proto"),
+		Package: proto.String("x402.facilitator.v1"),
+		Syntax:  proto.String("proto3"),
+		Options: &descriptorpb.FileOptions{GoPackage: proto.String("x402-go/facilitator/grpc")},
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("PaymentRequest",
+				field("payment_payload", 1, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+				field("payment_requirements", 2, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+			),
+			message("VerifyReply",
+				field("is_valid", 1, descriptorpb.FieldDescriptorProto_TYPE_BOOL, ""),
+				field("invalid_reason", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("payer", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("error", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, errorType),
+			),
+			message("SettleReply",
+				field("success", 1, descriptorpb.FieldDescriptorProto_TYPE_BOOL, ""),
+				field("error_reason", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("payer", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("transaction", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("network", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("error", 6, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, errorType),
+			),
+			message("FacilitatorError",
+				field("reason", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("payer", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("network", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("transaction", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+				field("message", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, ""),
+			),
+			message("SupportedRequest"),
+			message("SupportedReply",
+				field("supported", 1, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+			),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Facilitator"),
+			Method: []*descriptorpb.MethodDescriptorProto{
+				method("Verify", "PaymentRequest", "VerifyReply"),
+				method("Settle", "PaymentRequest", "SettleReply"),
+				method("Supported", "SupportedRequest", "SupportedReply"),
+			},
+		}},
+	}
+}
+
+func message(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+}
+
+func field(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+	f := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   kind.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+func method(name, input, output string) *descriptorpb.MethodDescriptorProto {
+	return &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String(".x402.facilitator.v1." + input),
+		OutputType: proto.String(".x402.facilitator.v1." + output),
+	}
+}
+
+// newMessage returns an empty message of desc
+func newMessage(desc protoreflect.MessageDescriptor) *dynamicpb.Message {
+	return dynamicpb.NewMessage(desc)
+}
+
+// getBytes, getString and getBool read a field of m by name
+func getBytes(m protoreflect.Message, name protoreflect.Name) []byte {
+	return m.Get(m.Descriptor().Fields().ByName(name)).Bytes()
+}
+
+func getString(m protoreflect.Message, name protoreflect.Name) string {
+	return m.Get(m.Descriptor().Fields().ByName(name)).String()
+}
+
+func getBool(m protoreflect.Message, name protoreflect.Name) bool {
+	return m.Get(m.Descriptor().Fields().ByName(name)).Bool()
+}
+
+// setBytes, setString and setBool set a field of m by name
+func setBytes(m protoreflect.Message, name protoreflect.Name, value []byte) {
+	m.Set(m.Descriptor().Fields().ByName(name), protoreflect.ValueOfBytes(value))
+}
+
+func setString(m protoreflect.Message, name protoreflect.Name, value string) {
+	m.Set(m.Descriptor().Fields().ByName(name), protoreflect.ValueOfString(value))
+}
+
+func setBool(m protoreflect.Message, name protoreflect.Name, value bool) {
+	m.Set(m.Descriptor().Fields().ByName(name), protoreflect.ValueOfBool(value))
+}
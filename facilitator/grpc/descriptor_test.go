@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+var (
+	protoComment = regexp.MustCompile(`//[^\n]*`)
+	protoPackage = regexp.MustCompile(`(?m)^package\s+([\w.]+)\s*;`)
+	protoOption  = regexp.MustCompile(`(?m)^option\s+go_package\s*=\s*"([^"]*)"\s*;`)
+	protoService = regexp.MustCompile(`(?s)service\s+(\w+)\s*\{(.*?)\n\}`)
+	protoRPC     = regexp.MustCompile(`rpc\s+(\w+)\s*\(\s*(\w+)\s*\)\s*returns\s*\(\s*(\w+)\s*\)\s*;`)
+	protoMessage = regexp.MustCompile(`(?s)message\s+(\w+)\s*\{(.*?)\}`)
+	protoField   = regexp.MustCompile(`(\w+)\s+(\w+)\s*=\s*(\d+)\s*;`)
+)
+
+// parseProto builds the descriptor of a proto3 file using the subset of the language
+// facilitator.proto is written in: scalar and message fields, one service, no nesting
+func parseProto(t *testing.T, name, source string) *descriptorpb.FileDescriptorProto {
+	t.Helper()
+	source = protoComment.ReplaceAllString(source, "")
+
+	pkg := protoPackage.FindStringSubmatch(source)
+	if pkg == nil {
+		t.Fatalf("%s: no package", name)
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String(name),
+		Package: proto.String(pkg[1]),
+		Syntax:  proto.String("proto3"),
+	}
+	if option := protoOption.FindStringSubmatch(source); option != nil {
+		file.Options = &descriptorpb.FileOptions{GoPackage: proto.String(option[1])}
+	}
+	qualify := func(typeName string) string { return "." + pkg[1] + "." + typeName }
+
+	scalars := map[string]descriptorpb.FieldDescriptorProto_Type{
+		"bytes":  descriptorpb.FieldDescriptorProto_TYPE_BYTES,
+		"string": descriptorpb.FieldDescriptorProto_TYPE_STRING,
+		"bool":   descriptorpb.FieldDescriptorProto_TYPE_BOOL,
+		"int32":  descriptorpb.FieldDescriptorProto_TYPE_INT32,
+		"int64":  descriptorpb.FieldDescriptorProto_TYPE_INT64,
+		"uint32": descriptorpb.FieldDescriptorProto_TYPE_UINT32,
+		"uint64": descriptorpb.FieldDescriptorProto_TYPE_UINT64,
+	}
+	for _, m := range protoMessage.FindAllStringSubmatch(source, -1) {
+		var fields []*descriptorpb.FieldDescriptorProto
+		for _, f := range protoField.FindAllStringSubmatch(m[2], -1) {
+			number, err := strconv.ParseInt(f[3], 10, 32)
+			if err != nil {
+				t.Fatalf("%s.%s: invalid field number %s", m[1], f[2], f[3])
+			}
+			kind, typeName := scalars[f[1]], ""
+			if kind == 0 {
+				kind, typeName = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, qualify(f[1])
+			}
+			fields = append(fields, field(f[2], int32(number), kind, typeName))
+		}
+		file.MessageType = append(file.MessageType, message(m[1], fields...))
+	}
+
+	for _, s := range protoService.FindAllStringSubmatch(source, -1) {
+		service := &descriptorpb.ServiceDescriptorProto{Name: proto.String(s[1])}
+		for _, rpc := range protoRPC.FindAllStringSubmatch(s[2], -1) {
+			service.Method = append(service.Method, &descriptorpb.MethodDescriptorProto{
+				Name:       proto.String(rpc[1]),
+				InputType:  proto.String(qualify(rpc[2])),
+				OutputType: proto.String(qualify(rpc[3])),
+			})
+		}
+		file.Service = append(file.Service, service)
+	}
+	return file
+}
+
+func TestDescriptorMatchesProtoFile(t *testing.T) {
+	source, err := os.ReadFile("facilitator.proto")
+	if err != nil {
+		t.Fatalf("Failed to read facilitator.proto: %v", err)
+	}
+
+	// Every message and rpc must be picked up by the parser, or the comparison proves nothing
+	parsed := parseProto(t, "facilitator.proto", string(source))
+	if got, want := len(parsed.MessageType), strings.Count(string(source), "\nmessage "); got != want {
+		t.Fatalf("Parsed %d messages, facilitator.proto declares %d", got, want)
+	}
+	if len(parsed.Service) != 1 || len(parsed.Service[0].Method) != strings.Count(string(source), "rpc ") {
+		t.Fatal("Failed to parse the Facilitator service")
+	}
+
+	if described := facilitatorProto(); !proto.Equal(parsed, described) {
+		t.Errorf("descriptor.go is out of sync with facilitator.proto\nfacilitator.proto:\n%s\ndescriptor.go:\n%s",
+			prototext.Format(parsed), prototext.Format(described))
+	}
+}
@@ -0,0 +1,20 @@
+module x402-go/facilitator/grpc
+
+go 1.24.0
+
+toolchain go1.24.1
+
+replace x402-go => ../..
+
+require (
+	google.golang.org/grpc v1.67.1
+	google.golang.org/protobuf v1.36.9
+	x402-go v0.0.0-00010101000000-000000000000
+)
+
+require (
+	golang.org/x/net v0.43.0 // indirect
+	golang.org/x/sys v0.36.0 // indirect
+	golang.org/x/text v0.28.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240814211410-ddb44dafa142 // indirect
+)
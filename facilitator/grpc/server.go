@@ -0,0 +1,149 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	x402 "x402-go"
+)
+
+// Facilitator is what RegisterFacilitatorServer serves, implemented by the facilitator
+// returned from x402.Newx402Facilitator
+type Facilitator interface {
+	Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error)
+	Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error)
+	GetSupported() x402.SupportedResponse
+}
+
+// RegisterFacilitatorServer serves facilitator as the Facilitator gRPC service on registrar
+// (typically a *grpc.Server).
+//
+// x402 VerifyError and SettleError failures are answered in the reply's error field so clients
+// get the facilitator's reason back; any other error becomes an Internal status.
+func RegisterFacilitatorServer(registrar gogrpc.ServiceRegistrar, facilitator Facilitator) {
+	registrar.RegisterService(&serviceDesc, facilitator)
+}
+
+// serviceDesc is the Facilitator service of facilitator.proto
+var serviceDesc = gogrpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*Facilitator)(nil),
+	Methods: []gogrpc.MethodDesc{
+		{MethodName: "Verify", Handler: verifyHandler},
+		{MethodName: "Settle", Handler: settleHandler},
+		{MethodName: "Supported", Handler: supportedHandler},
+	},
+	Metadata: "facilitator.proto",
+}
+
+func verifyHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor gogrpc.UnaryServerInterceptor) (interface{}, error) {
+	request := newMessage(paymentRequestDesc)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return verify(ctx, srv.(Facilitator), req.(protoreflect.ProtoMessage).ProtoReflect())
+	}
+	if interceptor == nil {
+		return handler(ctx, request)
+	}
+	return interceptor(ctx, request, &gogrpc.UnaryServerInfo{Server: srv, FullMethod: VerifyMethod}, handler)
+}
+
+func settleHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor gogrpc.UnaryServerInterceptor) (interface{}, error) {
+	request := newMessage(paymentRequestDesc)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return settle(ctx, srv.(Facilitator), req.(protoreflect.ProtoMessage).ProtoReflect())
+	}
+	if interceptor == nil {
+		return handler(ctx, request)
+	}
+	return interceptor(ctx, request, &gogrpc.UnaryServerInfo{Server: srv, FullMethod: SettleMethod}, handler)
+}
+
+func supportedHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor gogrpc.UnaryServerInterceptor) (interface{}, error) {
+	request := newMessage(supportedRequestDesc)
+	if err := dec(request); err != nil {
+		return nil, err
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return supported(srv.(Facilitator))
+	}
+	if interceptor == nil {
+		return handler(ctx, request)
+	}
+	return interceptor(ctx, request, &gogrpc.UnaryServerInfo{Server: srv, FullMethod: SupportedMethod}, handler)
+}
+
+func verify(ctx context.Context, facilitator Facilitator, request protoreflect.Message) (protoreflect.ProtoMessage, error) {
+	result, err := facilitator.Verify(ctx, getBytes(request, "payment_payload"), getBytes(request, "payment_requirements"))
+
+	reply := newMessage(verifyReplyDesc)
+	if err != nil {
+		var ve *x402.VerifyError
+		if !errors.As(err, &ve) {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		setError(reply, ve.Reason, ve.Payer, ve.Network, "", ve.Err)
+		return reply, nil
+	}
+
+	setBool(reply, "is_valid", result.IsValid)
+	setString(reply, "invalid_reason", result.InvalidReason)
+	setString(reply, "payer", result.Payer)
+	return reply, nil
+}
+
+func settle(ctx context.Context, facilitator Facilitator, request protoreflect.Message) (protoreflect.ProtoMessage, error) {
+	result, err := facilitator.Settle(ctx, getBytes(request, "payment_payload"), getBytes(request, "payment_requirements"))
+
+	reply := newMessage(settleReplyDesc)
+	if err != nil {
+		var se *x402.SettleError
+		if !errors.As(err, &se) {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		setError(reply, se.Reason, se.Payer, se.Network, se.Transaction, se.Err)
+		return reply, nil
+	}
+
+	setBool(reply, "success", result.Success)
+	setString(reply, "error_reason", result.ErrorReason)
+	setString(reply, "payer", result.Payer)
+	setString(reply, "transaction", result.Transaction)
+	setString(reply, "network", string(result.Network))
+	return reply, nil
+}
+
+func supported(facilitator Facilitator) (protoreflect.ProtoMessage, error) {
+	supportedBytes, err := json.Marshal(facilitator.GetSupported())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	reply := newMessage(supportedReplyDesc)
+	setBytes(reply, "supported", supportedBytes)
+	return reply, nil
+}
+
+// setError fills the error field of a Verify or Settle reply
+func setError(reply protoreflect.Message, reason, payer string, network x402.Network, transaction string, err error) {
+	m := newMessage(facilitatorErrorDesc)
+	setString(m, "reason", reason)
+	setString(m, "payer", payer)
+	setString(m, "network", string(network))
+	setString(m, "transaction", transaction)
+	if err != nil {
+		setString(m, "message", err.Error())
+	}
+	reply.Set(reply.Descriptor().Fields().ByName("error"), protoreflect.ValueOfMessage(m))
+}
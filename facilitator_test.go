@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"x402-go/types"
 )
@@ -221,6 +225,41 @@ func TestFacilitatorVerify(t *testing.T) {
 	}
 }
 
+func TestFacilitatorRegisterExplicitNetworkOverridesWildcard(t *testing.T) {
+	ctx := context.Background()
+	logger := &recordingLogger{}
+	payerFunc := func(payer string) func(context.Context, types.PaymentPayload, types.PaymentRequirements) (*VerifyResponse, error) {
+		return func(context.Context, types.PaymentPayload, types.PaymentRequirements) (*VerifyResponse, error) {
+			return &VerifyResponse{IsValid: true, Payer: payer}, nil
+		}
+	}
+
+	facilitator := Newx402Facilitator().WithLogger(logger)
+	facilitator.
+		Register([]Network{"eip155:*"}, &mockSchemeNetworkFacilitator{scheme: "exact", verifyFunc: payerFunc("wildcard")}).
+		Register([]Network{"eip155:8453"}, &mockSchemeNetworkFacilitator{scheme: "exact", verifyFunc: payerFunc("base")}).
+		Register([]Network{"eip155:8453"}, &mockSchemeNetworkFacilitator{scheme: "exact", verifyFunc: payerFunc("shadowed")})
+
+	if len(logger.entries) != 1 || logger.entries[0].level != "warn" || logger.entries[0].fields["network"] != "eip155:8453" {
+		t.Fatalf("Expected one warning about the shadowed registration, got %+v", logger.entries)
+	}
+
+	for network, want := range map[string]string{"eip155:8453": "base", "eip155:1": "wildcard"} {
+		requirements := types.PaymentRequirements{Scheme: "exact", Network: network, Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"}
+		payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}}
+		payloadBytes, _ := json.Marshal(payload)
+		requirementsBytes, _ := json.Marshal(requirements)
+
+		response, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+		if err != nil {
+			t.Fatalf("Unexpected error on %s: %v", network, err)
+		}
+		if response.Payer != want {
+			t.Fatalf("Expected %s registration to verify %s, got %s", want, network, response.Payer)
+		}
+	}
+}
+
 func TestFacilitatorVerifyValidation(t *testing.T) {
 	ctx := context.Background()
 	facilitator := Newx402Facilitator()
@@ -279,6 +318,56 @@ func TestFacilitatorVerifyValidation(t *testing.T) {
 	// This test needs the mock to actually validate if needed
 }
 
+func TestFacilitatorVerifyReasons(t *testing.T) {
+	ctx := context.Background()
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+
+	requirements := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "solana:mainnet",
+		Asset:   "USDC",
+		Amount:  "1000000",
+		PayTo:   "0xrecipient",
+	}
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload:     map[string]interface{}{},
+	}
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	tests := []struct {
+		name              string
+		payloadBytes      []byte
+		requirementsBytes []byte
+		want              string
+	}{
+		{"invalid version", []byte(`{`), requirementsBytes, ReasonInvalidVersion},
+		{"invalid payload", []byte(`{"x402Version":2,"accepted":1}`), requirementsBytes, ReasonInvalidV2Payload},
+		{"no facilitator for network", payloadBytes, requirementsBytes, ReasonNoFacilitatorForNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := facilitator.Verify(ctx, tt.payloadBytes, tt.requirementsBytes)
+			var verifyErr *VerifyError
+			if !errors.As(err, &verifyErr) {
+				t.Fatalf("Expected VerifyError, got %v", err)
+			}
+			if verifyErr.Reason != tt.want {
+				t.Errorf("Expected reason %q, got %q", tt.want, verifyErr.Reason)
+			}
+		})
+	}
+
+	// Wire values must not change
+	if ReasonInsufficientBalance != "insufficient_balance" || ReasonInvalidExactEVMPayloadSignature != "invalid_exact_evm_payload_signature" {
+		t.Error("Reason constants changed their wire values")
+	}
+}
+
 func TestFacilitatorVerifySchemeMismatch(t *testing.T) {
 	ctx := context.Background()
 	facilitator := Newx402Facilitator()
@@ -444,6 +533,194 @@ func TestFacilitatorSettle(t *testing.T) {
 	}
 }
 
+// Mock V2 facilitator that can simulate settlements
+type mockSimulatingSchemeNetworkFacilitator struct {
+	mockSchemeNetworkFacilitator
+	simulateErr error
+	simulated   int
+}
+
+func (m *mockSimulatingSchemeNetworkFacilitator) SimulateSettle(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+	m.simulated++
+	if m.simulateErr != nil {
+		return nil, m.simulateErr
+	}
+	return &SettleResponse{Success: true, Payer: "0xmockpayer", Network: Network(requirements.Network)}, nil
+}
+
+func TestFacilitatorSimulateSettle(t *testing.T) {
+	ctx := context.Background()
+
+	simulating := &mockSimulatingSchemeNetworkFacilitator{mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"}}
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1"}, simulating)
+	facilitator.Register([]Network{"eip155:8453"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+
+	request := func(network string) ([]byte, []byte) {
+		requirements := types.PaymentRequirements{Scheme: "exact", Network: network, Asset: "USDC", Amount: "1000000", PayTo: "0xrecipient"}
+		payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{"signature": "test"}}
+		payloadBytes, _ := json.Marshal(payload)
+		requirementsBytes, _ := json.Marshal(requirements)
+		return payloadBytes, requirementsBytes
+	}
+
+	t.Run("routes to the mechanism", func(t *testing.T) {
+		payloadBytes, requirementsBytes := request("eip155:1")
+		result, err := facilitator.SimulateSettle(ctx, payloadBytes, requirementsBytes)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !result.Success || result.Transaction != "" || simulating.simulated != 1 {
+			t.Errorf("Unexpected simulation: %+v (simulated %d)", result, simulating.simulated)
+		}
+	})
+
+	t.Run("mechanism without simulation", func(t *testing.T) {
+		payloadBytes, requirementsBytes := request("eip155:8453")
+		_, err := facilitator.SimulateSettle(ctx, payloadBytes, requirementsBytes)
+		var se *SettleError
+		if !errors.As(err, &se) || se.Reason != ReasonSimulationNotSupported {
+			t.Errorf("Expected simulation_not_supported, got %v", err)
+		}
+	})
+
+	t.Run("no mechanism", func(t *testing.T) {
+		payloadBytes, requirementsBytes := request("eip155:137")
+		_, err := facilitator.SimulateSettle(ctx, payloadBytes, requirementsBytes)
+		var se *SettleError
+		if !errors.As(err, &se) || se.Reason != ReasonNoFacilitatorForNetwork {
+			t.Errorf("Expected no_facilitator_for_network, got %v", err)
+		}
+	})
+
+	t.Run("v1 payments", func(t *testing.T) {
+		_, err := facilitator.SimulateSettle(ctx, []byte(`{"x402Version":1}`), []byte(`{}`))
+		var se *SettleError
+		if !errors.As(err, &se) || se.Reason != ReasonSimulationNotSupported {
+			t.Errorf("Expected simulation_not_supported, got %v", err)
+		}
+	})
+}
+
+// Mock batch-capable V2 facilitator for testing
+type mockBatchSchemeNetworkFacilitator struct {
+	mockSchemeNetworkFacilitator
+	batchFunc func(ctx context.Context, payloads []types.PaymentPayload, requirements []types.PaymentRequirements) ([]*SettleResponse, error)
+}
+
+func (m *mockBatchSchemeNetworkFacilitator) SettleBatch(ctx context.Context, payloads []types.PaymentPayload, requirements []types.PaymentRequirements) ([]*SettleResponse, error) {
+	return m.batchFunc(ctx, payloads, requirements)
+}
+
+func TestFacilitatorSettleBatch(t *testing.T) {
+	ctx := context.Background()
+
+	newRequest := func(network, nonce string) SettlementRequest {
+		requirements := types.PaymentRequirements{
+			Scheme:  "exact",
+			Network: network,
+			Asset:   "USDC",
+			Amount:  "1000000",
+			PayTo:   "0xrecipient",
+		}
+		payload := types.PaymentPayload{
+			X402Version: 2,
+			Accepted:    requirements,
+			Payload:     map[string]interface{}{"nonce": nonce},
+		}
+		payloadBytes, _ := json.Marshal(payload)
+		requirementsBytes, _ := json.Marshal(requirements)
+		return SettlementRequest{PayloadBytes: payloadBytes, RequirementsBytes: requirementsBytes}
+	}
+
+	var batchSizes []int
+	var sequential int
+	mock := &mockBatchSchemeNetworkFacilitator{
+		mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{
+			scheme: "exact",
+			settleFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+				sequential++
+				return &SettleResponse{Success: true, Transaction: "0xsingle", Network: Network(requirements.Network)}, nil
+			},
+		},
+		batchFunc: func(ctx context.Context, payloads []types.PaymentPayload, requirements []types.PaymentRequirements) ([]*SettleResponse, error) {
+			batchSizes = append(batchSizes, len(payloads))
+			results := make([]*SettleResponse, len(payloads))
+			for i, payload := range payloads {
+				if payload.Payload["nonce"] == "bad" {
+					results[i] = &SettleResponse{Success: false, ErrorReason: ReasonInsufficientBalance, Network: Network(requirements[i].Network)}
+					continue
+				}
+				results[i] = &SettleResponse{Success: true, Transaction: "0xbatch", Network: Network(requirements[i].Network)}
+			}
+			return results, nil
+		},
+	}
+
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1", "eip155:8453"}, mock)
+
+	var failures, successes int
+	facilitator.OnSettleFailure(func(ctx FacilitatorSettleFailureContext) (*FacilitatorSettleFailureHookResult, error) {
+		failures++
+		return nil, nil
+	})
+	facilitator.OnAfterSettle(func(ctx FacilitatorSettleResultContext) error {
+		successes++
+		return nil
+	})
+
+	requests := []SettlementRequest{
+		newRequest("eip155:1", "a"),
+		newRequest("eip155:8453", "b"),
+		newRequest("eip155:1", "bad"),
+		newRequest("eip155:1", "c"),
+		newRequest("solana:mainnet", "d"),
+	}
+
+	results, err := facilitator.SettleBatch(ctx, requests)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(results) != len(requests) {
+		t.Fatalf("Expected %d results, got %d", len(requests), len(results))
+	}
+
+	if len(batchSizes) != 1 || batchSizes[0] != 3 {
+		t.Errorf("Expected one batch of 3, got %v", batchSizes)
+	}
+	if sequential != 1 {
+		t.Errorf("Expected the lone eip155:8453 payment to settle sequentially, got %d", sequential)
+	}
+
+	if !results[0].Success || results[0].Transaction != "0xbatch" || !results[3].Success {
+		t.Errorf("Expected batched payments to succeed, got %+v, %+v", results[0], results[3])
+	}
+	if !results[1].Success || results[1].Transaction != "0xsingle" {
+		t.Errorf("Expected sequential settlement, got %+v", results[1])
+	}
+	if results[2].Success || results[2].ErrorReason != ReasonInsufficientBalance {
+		t.Errorf("Expected per-payment failure, got %+v", results[2])
+	}
+	if results[4].Success || results[4].ErrorReason != ReasonNoFacilitatorForNetwork {
+		t.Errorf("Expected unsupported network failure, got %+v", results[4])
+	}
+
+	if successes != 3 || failures != 2 {
+		t.Errorf("Expected 3 afterSettle and 2 failure hook calls, got %d and %d", successes, failures)
+	}
+
+	// A batch rejected as a whole falls back to sequential settlement
+	mock.batchFunc = func(ctx context.Context, payloads []types.PaymentPayload, requirements []types.PaymentRequirements) ([]*SettleResponse, error) {
+		return nil, errors.New("batching unavailable")
+	}
+	sequential = 0
+	results, _ = facilitator.SettleBatch(ctx, requests[:2])
+	if sequential != 2 || !results[0].Success || !results[1].Success {
+		t.Errorf("Expected sequential fallback, got %d calls and %+v", sequential, results)
+	}
+}
+
 func TestFacilitatorSettleVerifiesFirst(t *testing.T) {
 	ctx := context.Background()
 	facilitator := Newx402Facilitator()
@@ -549,6 +826,45 @@ func TestFacilitatorGetSupported(t *testing.T) {
 	}
 }
 
+func TestFacilitatorGetSupportedFor(t *testing.T) {
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1", "eip155:8453"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+	facilitator.Register([]Network{"eip155:8453"}, &mockSchemeNetworkFacilitator{scheme: "transfer"})
+	facilitator.Register([]Network{"solana:mainnet"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+	facilitator.RegisterV1([]Network{"eip155:8453"}, &mockSchemeNetworkFacilitatorV1{scheme: "exact"})
+
+	tests := []struct {
+		name      string
+		schemes   []string
+		networks  []Network
+		wantKinds int
+	}{
+		{name: "no filter", wantKinds: 5},
+		{name: "scheme", schemes: []string{"exact"}, wantKinds: 4},
+		{name: "network", networks: []Network{"eip155:8453"}, wantKinds: 3},
+		{name: "scheme and network", schemes: []string{"exact"}, networks: []Network{"eip155:8453"}, wantKinds: 2},
+		{name: "network pattern", networks: []Network{"eip155:*"}, wantKinds: 4},
+		{name: "no match", schemes: []string{"upto"}, wantKinds: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			supported := facilitator.GetSupportedFor(tt.schemes, tt.networks)
+			if len(supported.Kinds) != tt.wantKinds {
+				t.Fatalf("Expected %d kinds, got %d: %+v", tt.wantKinds, len(supported.Kinds), supported.Kinds)
+			}
+			for _, kind := range supported.Kinds {
+				if !supportedKindMatches(kind.Scheme, Network(kind.Network), tt.schemes, tt.networks) {
+					t.Errorf("Unexpected kind %+v", kind)
+				}
+			}
+			if tt.wantKinds == 0 && len(supported.Signers) != 0 {
+				t.Errorf("Expected no signers without kinds, got %v", supported.Signers)
+			}
+		})
+	}
+}
+
 // TestFacilitatorCanHandle - SKIPPED: CanHandle method removed in refactoring
 // TODO: Reimplement if needed
 
@@ -590,3 +906,155 @@ func TestFacilitatorNetworkPatternMatching(t *testing.T) {
 		t.Fatal("Expected valid verification with pattern match")
 	}
 }
+
+type mockHealthCheckingSchemeNetworkFacilitator struct {
+	mockSchemeNetworkFacilitator
+	unhealthy Network
+}
+
+func (m *mockHealthCheckingSchemeNetworkFacilitator) HealthCheck(ctx context.Context, network Network) NetworkHealth {
+	health := NetworkHealth{Network: network, Scheme: m.scheme, Healthy: true, RPCReachable: true}
+	if network == m.unhealthy {
+		health.Healthy = false
+		health.Errors = []string{"signer balance low"}
+	}
+	return health
+}
+
+func TestFacilitatorHealthCheck(t *testing.T) {
+	ctx := context.Background()
+
+	checking := &mockHealthCheckingSchemeNetworkFacilitator{mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"}}
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:8453", "eip155:1"}, checking)
+	facilitator.Register([]Network{"solana:mainnet"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+
+	status := facilitator.HealthCheck(ctx)
+	if !status.Healthy {
+		t.Errorf("Expected a healthy status, got %+v", status)
+	}
+	if len(status.Networks) != 2 {
+		t.Fatalf("Expected 2 checked networks, got %+v", status.Networks)
+	}
+	if status.Networks[0].Network != "eip155:1" || status.Networks[1].Network != "eip155:8453" {
+		t.Errorf("Unexpected network order: %s, %s", status.Networks[0].Network, status.Networks[1].Network)
+	}
+
+	checking.unhealthy = "eip155:8453"
+	status = facilitator.HealthCheck(ctx)
+	if status.Healthy {
+		t.Error("Expected an unhealthy status when a network is unhealthy")
+	}
+	if status.Networks[0].Healthy == status.Networks[1].Healthy {
+		t.Errorf("Expected only eip155:8453 to be unhealthy, got %+v", status.Networks)
+	}
+}
+
+// TestFacilitatorConcurrentSettlements settles on two networks at once while one of them is
+// stuck mid-settlement and a mechanism is registered, so shared state between networks shows
+// up under -race and a registry lock held across settlements shows up as a timeout
+func TestFacilitatorConcurrentSettlements(t *testing.T) {
+	const perNetwork = 50
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	var slowStarted sync.WaitGroup
+	slowStarted.Add(perNetwork)
+	var slowSettled, fastSettled, fastVerified atomic.Int64
+
+	slow := &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		settleFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+			slowStarted.Done()
+			<-release
+			slowSettled.Add(1)
+			return &SettleResponse{Success: true, Transaction: "0xslow", Network: Network(requirements.Network)}, nil
+		},
+	}
+	fast := &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		verifyFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*VerifyResponse, error) {
+			fastVerified.Add(1)
+			return &VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		settleFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+			fastSettled.Add(1)
+			return &SettleResponse{Success: true, Transaction: "0xfast", Network: Network(requirements.Network)}, nil
+		},
+	}
+
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1"}, slow)
+	facilitator.Register([]Network{"eip155:8453"}, fast)
+
+	encode := func(network string, i int) ([]byte, []byte) {
+		requirements := types.PaymentRequirements{Scheme: "exact", Network: network, Asset: "USDC", Amount: "1000", PayTo: "0xrecipient"}
+		payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{"nonce": i}}
+		payloadBytes, _ := json.Marshal(payload)
+		requirementsBytes, _ := json.Marshal(requirements)
+		return payloadBytes, requirementsBytes
+	}
+
+	settle := func(network string, i int, want string, errs chan<- error) {
+		payloadBytes, requirementsBytes := encode(network, i)
+		result, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+		if err == nil && (result.Transaction != want || result.Network != Network(network)) {
+			err = fmt.Errorf("settlement on %s got %+v", network, result)
+		}
+		errs <- err
+	}
+
+	slowErrs := make(chan error, perNetwork)
+	for i := 0; i < perNetwork; i++ {
+		go settle("eip155:1", i, "0xslow", slowErrs)
+	}
+	slowStarted.Wait()
+
+	// A registration while eip155:1 is mid-settlement must not block other networks
+	registered := make(chan struct{})
+	go func() {
+		facilitator.Register([]Network{"eip155:137"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+		close(registered)
+	}()
+
+	fastErrs := make(chan error, 2*perNetwork)
+	for i := 0; i < perNetwork; i++ {
+		go settle("eip155:8453", i, "0xfast", fastErrs)
+		go func() {
+			payloadBytes, requirementsBytes := encode("eip155:8453", i)
+			_, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+			fastErrs <- err
+		}()
+	}
+
+	timeout := time.After(5 * time.Second)
+	for i := 0; i < 2*perNetwork; i++ {
+		select {
+		case err := <-fastErrs:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-timeout:
+			close(release)
+			t.Fatal("Payments on eip155:8453 blocked behind settlements on eip155:1")
+		}
+	}
+	select {
+	case <-registered:
+	case <-timeout:
+		close(release)
+		t.Fatal("Registration blocked behind settlements on eip155:1")
+	}
+
+	close(release)
+	for i := 0; i < perNetwork; i++ {
+		if err := <-slowErrs; err != nil {
+			t.Error(err)
+		}
+	}
+
+	if slowSettled.Load() != perNetwork || fastSettled.Load() != perNetwork || fastVerified.Load() != perNetwork {
+		t.Errorf("Expected %d payments per network, got slow %d, fast %d settled and %d verified",
+			perNetwork, slowSettled.Load(), fastSettled.Load(), fastVerified.Load())
+	}
+}
@@ -0,0 +1,105 @@
+package chi
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	x402 "x402-go"
+	x402http "x402-go/http"
+	"x402-go/http/nethttp"
+)
+
+// ============================================================================
+// Chi Adapter Implementation
+// ============================================================================
+
+// ChiAdapter implements HTTPAdapter for chi-routed requests. It is the net/http adapter
+// plus access to the chi route pattern.
+type ChiAdapter struct {
+	*nethttp.NetHTTPAdapter
+	req *http.Request
+}
+
+// NewChiAdapter creates a new chi adapter
+func NewChiAdapter(req *http.Request) *ChiAdapter {
+	return &ChiAdapter{NetHTTPAdapter: nethttp.NewNetHTTPAdapter(req), req: req}
+}
+
+// GetRoutePattern gets the chi route pattern matched by the request (e.g. "/weather/{city}").
+// Returns an empty string if the request is not routed by chi or matches no route.
+//
+// When the middleware runs inside a route (r.With, r.Group, r.Route) chi has already
+// recorded the pattern. When it is installed with r.Use on a router, routing has not
+// happened yet, so the pattern is resolved by matching against the router.
+func (a *ChiAdapter) GetRoutePattern() string {
+	rctx := chi.RouteContext(a.req.Context())
+	if rctx == nil {
+		return ""
+	}
+
+	if pattern := rctx.RoutePattern(); pattern != "" {
+		return pattern
+	}
+
+	if rctx.Routes == nil {
+		return ""
+	}
+
+	path := a.req.URL.RawPath
+	if path == "" {
+		path = a.req.URL.Path
+	}
+
+	match := chi.NewRouteContext()
+	if !rctx.Routes.Match(match, a.req.Method, path) {
+		return ""
+	}
+	return match.RoutePattern()
+}
+
+// ============================================================================
+// Middleware Configuration
+// ============================================================================
+
+// MiddlewareConfig configures the payment middleware. The chi middleware is the net/http
+// middleware with chi route pattern matching, so it shares its configuration.
+type MiddlewareConfig = nethttp.MiddlewareConfig
+
+// MiddlewareOption configures the middleware
+type MiddlewareOption = nethttp.MiddlewareOption
+
+// Options shared with the net/http middleware
+var (
+	WithPaywallConfig          = nethttp.WithPaywallConfig
+	WithPaywallTemplate        = nethttp.WithPaywallTemplate
+	WithSyncFacilitatorOnStart = nethttp.WithSyncFacilitatorOnStart
+	WithErrorHandler           = nethttp.WithErrorHandler
+	WithSettlementHandler      = nethttp.WithSettlementHandler
+	WithTimeout                = nethttp.WithTimeout
+	WithPaymentRequiredBody    = nethttp.WithPaymentRequiredBody
+)
+
+// ============================================================================
+// Payment Middleware
+// ============================================================================
+
+// Middleware creates chi middleware for x402 payment handling using a pre-configured server.
+//
+// Routes are matched against the chi route pattern first, so a RoutesConfig key such as
+// "GET /weather/{city}" protects every city. If no route matches the pattern, the request
+// path is used instead, so wildcard keys like "GET /weather/*" also work.
+//
+// Example:
+//
+//	r := chi.NewRouter()
+//	r.Use(chimw.Middleware(routes, server))
+//	r.Get("/weather/{city}", weatherHandler)
+func Middleware(routes x402http.RoutesConfig, server *x402.X402ResourceServer, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	opts = append([]MiddlewareOption{nethttp.WithRoutePattern(routePattern)}, opts...)
+	return nethttp.Middleware(routes, server, opts...)
+}
+
+// routePattern resolves the chi route pattern matched by r
+func routePattern(r *http.Request) string {
+	return NewChiAdapter(r).GetRoutePattern()
+}
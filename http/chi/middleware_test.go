@@ -0,0 +1,348 @@
+package chi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	x402 "x402-go"
+	x402http "x402-go/http"
+	"x402-go/types"
+)
+
+// ============================================================================
+// Mock Implementations
+// ============================================================================
+
+// mockSchemeServer implements x402.SchemeNetworkServer for testing
+type mockSchemeServer struct {
+	scheme string
+}
+
+func (m *mockSchemeServer) Scheme() string {
+	return m.scheme
+}
+
+func (m *mockSchemeServer) ParsePrice(price x402.Price, network x402.Network) (x402.AssetAmount, error) {
+	return x402.AssetAmount{
+		Asset:  "USDC",
+		Amount: "1000000",
+	}, nil
+}
+
+func (m *mockSchemeServer) EnhancePaymentRequirements(ctx context.Context, base types.PaymentRequirements, supported types.SupportedKind, extensions []string) (types.PaymentRequirements, error) {
+	return base, nil
+}
+
+// mockFacilitatorClient implements x402.FacilitatorClient for testing
+type mockFacilitatorClient struct {
+	verifyFunc func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error)
+	settleFunc func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error)
+}
+
+func (m *mockFacilitatorClient) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	if m.verifyFunc != nil {
+		return m.verifyFunc(ctx, payloadBytes, requirementsBytes)
+	}
+	return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+}
+
+func (m *mockFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	if m.settleFunc != nil {
+		return m.settleFunc(ctx, payloadBytes, requirementsBytes)
+	}
+	return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+}
+
+func (m *mockFacilitatorClient) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	return x402.SupportedResponse{
+		Kinds: []x402.SupportedKind{
+			{X402Version: 2, Scheme: "exact", Network: "eip155:1"},
+		},
+		Extensions: []string{},
+		Signers:    make(map[string][]string),
+	}, nil
+}
+
+func (m *mockFacilitatorClient) Identifier() string {
+	return "mock"
+}
+
+// ============================================================================
+// Test Helpers
+// ============================================================================
+
+// testRoutes protects GET /weather/{city} by chi pattern and GET /files/* by path
+func testRoutes() x402http.RoutesConfig {
+	option := x402http.PaymentOptions{
+		{
+			Scheme:  "exact",
+			PayTo:   "0xtest",
+			Price:   "$1.00",
+			Network: "eip155:1",
+		},
+	}
+	return x402http.RoutesConfig{
+		"GET /weather/{city}": {Accepts: option, Description: "Weather"},
+		"GET /files/*":        {Accepts: option, Description: "Files"},
+	}
+}
+
+// createTestRouter creates a chi router with the payment middleware installed via Use
+func createTestRouter(client x402.FacilitatorClient, handler http.HandlerFunc, opts ...MiddlewareOption) *chi.Mux {
+	server := x402.Newx402ResourceServer(x402.WithFacilitatorClient(client))
+	server.Register("eip155:1", &mockSchemeServer{scheme: "exact"})
+
+	opts = append([]MiddlewareOption{WithTimeout(5 * time.Second)}, opts...)
+
+	r := chi.NewRouter()
+	r.Use(Middleware(testRoutes(), server, opts...))
+	r.Get("/weather/{city}", handler)
+	r.Get("/files/{name}", handler)
+	r.Get("/public", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("public"))
+	})
+	return r
+}
+
+// newPaidRequest creates a request to path carrying a payment header
+func newPaidRequest(path string) *http.Request {
+	payload := x402.PaymentPayload{
+		X402Version: 2,
+		Payload:     map[string]interface{}{"sig": "test"},
+		Accepted: x402.PaymentRequirements{
+			Scheme:            "exact",
+			Network:           "eip155:1",
+			Asset:             "USDC",
+			Amount:            "1000000",
+			PayTo:             "0xtest",
+			MaxTimeoutSeconds: 300,
+			Extra: map[string]interface{}{
+				"resourceUrl": "http://example.com" + path,
+			},
+		},
+	}
+	payloadJSON, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("PAYMENT-SIGNATURE", base64.StdEncoding.EncodeToString(payloadJSON))
+	req.Host = "example.com"
+	return req
+}
+
+// ============================================================================
+// ChiAdapter Tests
+// ============================================================================
+
+func TestChiAdapter_GetRoutePattern(t *testing.T) {
+	var fromUse, fromWith string
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			fromUse = NewChiAdapter(req).GetRoutePattern()
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Route("/api", func(r chi.Router) {
+		r.With(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				fromWith = NewChiAdapter(req).GetRoutePattern()
+				next.ServeHTTP(w, req)
+			})
+		}).Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/users/123", nil))
+
+	if fromUse != "/api/users/{id}" {
+		t.Errorf("Expected pattern '/api/users/{id}' from Use, got '%s'", fromUse)
+	}
+	if fromWith != "/api/users/{id}" {
+		t.Errorf("Expected pattern '/api/users/{id}' from With, got '%s'", fromWith)
+	}
+
+	if pattern := NewChiAdapter(httptest.NewRequest("GET", "/api/users/123", nil)).GetRoutePattern(); pattern != "" {
+		t.Errorf("Expected empty pattern outside chi, got '%s'", pattern)
+	}
+}
+
+func TestChiAdapter(t *testing.T) {
+	req := httptest.NewRequest("GET", "/weather/london?units=metric", nil)
+	req.Host = "example.com"
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+
+	adapter := NewChiAdapter(req)
+
+	if adapter.GetMethod() != "GET" {
+		t.Errorf("Expected method GET, got %s", adapter.GetMethod())
+	}
+	if adapter.GetPath() != "/weather/london" {
+		t.Errorf("Expected path '/weather/london', got '%s'", adapter.GetPath())
+	}
+	if adapter.GetURL() != "http://example.com/weather/london" {
+		t.Errorf("Expected URL 'http://example.com/weather/london', got '%s'", adapter.GetURL())
+	}
+	if adapter.GetAcceptHeader() != "text/html" {
+		t.Errorf("Expected Accept 'text/html', got '%s'", adapter.GetAcceptHeader())
+	}
+	if adapter.GetUserAgent() != "Mozilla/5.0" {
+		t.Errorf("Expected User-Agent 'Mozilla/5.0', got '%s'", adapter.GetUserAgent())
+	}
+}
+
+// ============================================================================
+// Middleware Tests
+// ============================================================================
+
+func TestMiddleware_MatchesRoutePattern(t *testing.T) {
+	r := createTestRouter(&mockFacilitatorClient{}, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("protected"))
+	}, WithSyncFacilitatorOnStart(false))
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/weather/london", http.StatusPaymentRequired},
+		{"/weather/paris", http.StatusPaymentRequired},
+		{"/files/report.pdf", http.StatusPaymentRequired},
+		{"/public", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if tt.status == http.StatusPaymentRequired && w.Header().Get("PAYMENT-REQUIRED") == "" {
+				t.Error("Expected PAYMENT-REQUIRED header")
+			}
+		})
+	}
+}
+
+func TestMiddleware_SettlesBeforeWritingStatus(t *testing.T) {
+	settleCalled := false
+	client := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			settleCalled = true
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+		},
+	}
+
+	r := createTestRouter(client, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"city":"` + chi.URLParam(r, "city") + `"}`))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newPaidRequest("/weather/london"))
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if !settleCalled {
+		t.Error("Expected settlement to be called")
+	}
+	if w.Header().Get("PAYMENT-RESPONSE") == "" {
+		t.Error("Expected PAYMENT-RESPONSE header")
+	}
+	if w.Body.String() != `{"city":"london"}` {
+		t.Errorf("Expected protected body, got %s", w.Body.String())
+	}
+}
+
+func TestMiddleware_SkipsSettlementWhenHandlerReturns400OrHigher(t *testing.T) {
+	settleCalled := false
+	client := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			settleCalled = true
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx"}, nil
+		},
+	}
+
+	r := createTestRouter(client, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unknown city", http.StatusNotFound)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newPaidRequest("/weather/atlantis"))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if settleCalled {
+		t.Error("Settlement should NOT be called when handler returns >= 400")
+	}
+	if w.Header().Get("PAYMENT-RESPONSE") != "" {
+		t.Error("Expected no PAYMENT-RESPONSE header")
+	}
+}
+
+func TestMiddleware_Returns402WhenSettlementFails(t *testing.T) {
+	client := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{Success: false, ErrorReason: "Insufficient funds"}, nil
+		},
+	}
+
+	r := createTestRouter(client, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Secret", "value")
+		_, _ = w.Write([]byte("protected-data"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newPaidRequest("/weather/london"))
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "protected-data") {
+		t.Error("Protected body must not be sent when settlement fails")
+	}
+	if w.Header().Get("X-Secret") != "" {
+		t.Error("Protected handler headers must not be sent when settlement fails")
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response["details"] != "Insufficient funds" {
+		t.Errorf("Expected details 'Insufficient funds', got '%v'", response["details"])
+	}
+}
+
+func TestMiddleware_SettlesEmptyResponse(t *testing.T) {
+	var settled *x402.SettleResponse
+	r := createTestRouter(&mockFacilitatorClient{}, func(w http.ResponseWriter, r *http.Request) {},
+		WithSettlementHandler(func(w http.ResponseWriter, r *http.Request, response *x402.SettleResponse) {
+			settled = response
+		}),
+	)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, newPaidRequest("/weather/london"))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if settled == nil || settled.Transaction != "0xtx" {
+		t.Errorf("Expected settlement for empty response, got %+v", settled)
+	}
+	if w.Header().Get("PAYMENT-RESPONSE") == "" {
+		t.Error("Expected PAYMENT-RESPONSE header")
+	}
+}
@@ -1,14 +1,17 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	x402 "x402-go"
 	"x402-go/types"
@@ -18,22 +21,98 @@ import (
 // x402HTTPClient - HTTP-aware payment client
 // ============================================================================
 
+// DefaultMaxRechallenges is how many times a paid request is paid again by default when the
+// server answers it with a fresh 402
+const DefaultMaxRechallenges = 1
+
+// DefaultExpiryMargin is how long a V2 payment must stay valid by default for the client to
+// send it; one expiring sooner is regenerated (see WithExpiryMargin)
+const DefaultExpiryMargin = 10 * time.Second
+
 // x402HTTPClient wraps x402Client with HTTP-specific payment handling
 type x402HTTPClient struct {
-	client *x402.X402Client
+	client          *x402.X402Client
+	maxRechallenges int
+	fallback        bool
+	expiryMargin    time.Duration
+}
+
+// HTTPClientOption configures the HTTP client
+type HTTPClientOption func(*x402HTTPClient)
+
+// WithMaxRechallenges sets how many times a paid request is paid again when the server
+// answers it with a fresh 402 (e.g. the price changed or the payment was rejected as stale).
+// 0 disables re-challenge handling, so the 402 is returned to the caller.
+func WithMaxRechallenges(n int) HTTPClientOption {
+	return func(c *x402HTTPClient) {
+		if n >= 0 {
+			c.maxRechallenges = n
+		}
+	}
+}
+
+// WithPaymentFallback makes V2 payments fall back to the next accepted requirement the client
+// supports when creating the selected one's payment fails, e.g. because its network's RPC is
+// down or the balance is too low. OnPaymentCreationFailure hooks run for each failed attempt
+// (see x402.X402Client.CreatePaymentPayloadFor).
+func WithPaymentFallback() HTTPClientOption {
+	return func(c *x402HTTPClient) {
+		c.fallback = true
+	}
+}
+
+// WithExpiryMargin sets how long a V2 payment must stay valid for the client to send it.
+// A payment whose mechanism reports it expires sooner (see x402.PayloadExpirer), e.g. one an
+// OnPaymentCreationFailure hook recovered from an earlier attempt, is created again instead
+// of being sent for the facilitator to reject. 0 only regenerates payments already expired.
+func WithExpiryMargin(margin time.Duration) HTTPClientOption {
+	return func(c *x402HTTPClient) {
+		if margin >= 0 {
+			c.expiryMargin = margin
+		}
+	}
 }
 
 // Newx402HTTPClient creates a new HTTP-aware x402 client
-func Newx402HTTPClient(client *x402.X402Client) *x402HTTPClient {
-	return &x402HTTPClient{
-		client: client,
+func Newx402HTTPClient(client *x402.X402Client, opts ...HTTPClientOption) *x402HTTPClient {
+	c := &x402HTTPClient{
+		client:          client,
+		maxRechallenges: DefaultMaxRechallenges,
+		expiryMargin:    DefaultExpiryMargin,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 // ============================================================================
 // Header Encoding/Decoding
 // ============================================================================
 
+// Request headers carrying a payment, named after the protocol version the server spoke
+const (
+	// PaymentSignatureHeader carries a V2 payment
+	PaymentSignatureHeader = "PAYMENT-SIGNATURE"
+	// PaymentHeaderV1 carries a V1 payment
+	PaymentHeaderV1 = "X-PAYMENT"
+)
+
+// PaymentHeaderName returns the request header that carries a payment for the given
+// protocol version
+func PaymentHeaderName(version int) (string, error) {
+	switch version {
+	case 2:
+		return PaymentSignatureHeader, nil
+	case 1:
+		return PaymentHeaderV1, nil
+	default:
+		return "", fmt.Errorf("unsupported x402 version: %d", version)
+	}
+}
+
 // EncodePaymentSignatureHeader encodes a payment payload into HTTP headers
 // Returns appropriate headers based on protocol version
 // Works with raw payload bytes
@@ -44,25 +123,39 @@ func (c *x402HTTPClient) EncodePaymentSignatureHeader(payloadBytes []byte) map[s
 		panic(fmt.Sprintf("failed to detect version: %v", err))
 	}
 
-	// Base64 encode the payload bytes
-	encoded := base64.StdEncoding.EncodeToString(payloadBytes)
+	headers, err := c.EncodePaymentHeader(version, payloadBytes)
+	if err != nil {
+		panic(err.Error())
+	}
+	return headers
+}
+
+// EncodePaymentHeader encodes a payment payload into the request header for the protocol
+// version of the 402 challenge it answers (see PaymentHeaderName), so a client registered
+// for both versions answers each server in its own version. The payload must be of that
+// version.
+func (c *x402HTTPClient) EncodePaymentHeader(version int, payloadBytes []byte) (map[string]string, error) {
+	name, err := PaymentHeaderName(version)
+	if err != nil {
+		return nil, err
+	}
 
-	switch version {
-	case 2:
-		return map[string]string{
-			"PAYMENT-SIGNATURE": encoded,
-		}
-	case 1:
-		return map[string]string{
-			"X-PAYMENT": encoded,
-		}
-	default:
-		panic(fmt.Sprintf("unsupported x402 version: %d", version))
+	payloadVersion, err := types.DetectVersion(payloadBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect version: %w", err)
+	}
+	if payloadVersion != version {
+		return nil, fmt.Errorf("v%d payment cannot answer a v%d challenge", payloadVersion, version)
 	}
+
+	return map[string]string{
+		name: base64.StdEncoding.EncodeToString(payloadBytes),
+	}, nil
 }
 
 // GetPaymentRequiredResponse extracts payment requirements from HTTP response
-// Handles both v1 (body) and v2 (header) formats
+// Handles both v1 (body) and v2 (header) formats, and v2 servers that also send the
+// requirements as the JSON body. The header wins when both are present.
 func (c *x402HTTPClient) GetPaymentRequiredResponse(headers map[string]string, body []byte) (x402.PaymentRequired, error) {
 	// Normalize headers to uppercase
 	normalizedHeaders := make(map[string]string)
@@ -75,11 +168,11 @@ func (c *x402HTTPClient) GetPaymentRequiredResponse(headers map[string]string, b
 		return decodePaymentRequiredHeader(header)
 	}
 
-	// Fall back to v1 body format
+	// Fall back to the body (v1, or a v2 server sending the requirements as JSON)
 	if len(body) > 0 {
 		var required x402.PaymentRequired
 		if err := json.Unmarshal(body, &required); err == nil {
-			if required.X402Version == 1 {
+			if required.X402Version == 1 || required.X402Version == 2 {
 				return required, nil
 			}
 		}
@@ -155,6 +248,13 @@ func (t *PaymentRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 		return nil, fmt.Errorf("payment retry limit exceeded")
 	}
 
+	// Make the body replayable so the paid request carries it too
+	req, err := replayableRequest(req)
+	if err != nil {
+		t.retryCount.Delete(requestID)
+		return nil, err
+	}
+
 	// Make initial request
 	resp, err := t.Transport.RoundTrip(req)
 	if err != nil {
@@ -170,29 +270,7 @@ func (t *PaymentRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 
 	// Increment retry count
 	t.retryCount.Store(requestID, retries+1)
-
-	// Extract headers
-	headers := make(map[string]string)
-	for k, v := range resp.Header {
-		if len(v) > 0 {
-			headers[k] = v[0]
-		}
-	}
-
-	// Read response body for V1 support
-	body, err := io.ReadAll(resp.Body)
-	resp.Body.Close()
-	if err != nil {
-		t.retryCount.Delete(requestID)
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	// Detect version from response
-	version, err := detectPaymentRequiredVersion(headers, body)
-	if err != nil {
-		t.retryCount.Delete(requestID)
-		return nil, fmt.Errorf("failed to detect payment version: %w", err)
-	}
+	defer t.retryCount.Delete(requestID)
 
 	//nolint:contextcheck // Intentionally using request's context for payment flow
 	ctx := req.Context()
@@ -200,38 +278,137 @@ func (t *PaymentRoundTripper) RoundTrip(req *http.Request) (*http.Response, erro
 		ctx = context.Background()
 	}
 
-	// Fork based on version
-	var payloadBytes []byte
-	if version == 1 {
-		// V1 flow: body-based PaymentRequired, V1 types
-		payloadBytes, err = t.handleV1Payment(ctx, body)
+	// Payloads already sent, so a re-challenge never replays a payment
+	sent := make(map[string]bool)
+
+	for rechallenges := 0; ; rechallenges++ {
+		// Extract headers
+		headers := make(map[string]string)
+		for k, v := range resp.Header {
+			if len(v) > 0 {
+				headers[k] = v[0]
+			}
+		}
+
+		// Read response body for V1 support
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+
+		version, payloadBytes, err := t.createPayment(ctx, headers, body)
 		if err != nil {
-			t.retryCount.Delete(requestID)
 			return nil, err
 		}
-	} else {
-		// V2 flow: header-based PaymentRequired, V2 types
-		payloadBytes, err = t.handleV2Payment(ctx, headers, body)
+
+		if sent[string(payloadBytes)] {
+			// The new challenge produced a payment we already sent; hand the 402 back instead
+			resp.Body = io.NopCloser(bytes.NewReader(body))
+			return resp, nil
+		}
+		sent[string(payloadBytes)] = true
+
+		// Create new request with payment header
+		paymentReq, err := cloneRequest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		paymentHeaders, err := t.x402Client.EncodePaymentHeader(version, payloadBytes)
 		if err != nil {
-			t.retryCount.Delete(requestID)
 			return nil, err
 		}
+		for k, v := range paymentHeaders {
+			paymentReq.Header.Set(k, v)
+		}
+
+		// Retry with payment
+		resp, err = t.Transport.RoundTrip(paymentReq)
+		if err != nil || !isRechallenge(resp) || rechallenges >= t.x402Client.maxRechallenges {
+			return resp, err
+		}
+	}
+}
+
+// createPayment creates an encoded payment payload for a 402 response, returning the
+// protocol version it answers.
+//
+// A 402 can offer both versions, a V2 PAYMENT-REQUIRED header next to a V1 body. V2 is
+// tried first, and V1 when the client has no V2 mechanism for any accepted option.
+func (t *PaymentRoundTripper) createPayment(ctx context.Context, headers map[string]string, body []byte) (int, []byte, error) {
+	versions, err := paymentRequiredVersions(headers, body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to detect payment version: %w", err)
 	}
 
-	// Encode payment header (works for both V1 and V2)
-	paymentHeaders := t.x402Client.EncodePaymentSignatureHeader(payloadBytes)
+	for i, version := range versions {
+		var payloadBytes []byte
+		if version == 1 {
+			// V1 flow: body-based PaymentRequired, V1 types
+			payloadBytes, err = t.handleV1Payment(ctx, body)
+		} else {
+			// V2 flow: header-based PaymentRequired, V2 types
+			payloadBytes, err = t.handleV2Payment(ctx, headers, body)
+		}
 
-	// Create new request with payment header
-	paymentReq := req.Clone(ctx)
-	for k, v := range paymentHeaders {
-		paymentReq.Header.Set(k, v)
+		var paymentErr *x402.PaymentError
+		if err != nil && i < len(versions)-1 && errors.As(err, &paymentErr) && paymentErr.Code == x402.ErrCodeUnsupportedScheme {
+			// Try the server's other version
+			continue
+		}
+		return version, payloadBytes, err
 	}
+	return 0, nil, fmt.Errorf("failed to detect payment version: no supported version offered")
+}
 
-	// Retry with payment
-	newResp, err := t.Transport.RoundTrip(paymentReq)
-	t.retryCount.Delete(requestID)
+// isRechallenge reports whether the response to a paid request asks for a new payment:
+// a 402 with a fresh PAYMENT-REQUIRED header and no successful settlement. A 402 that still
+// carries a successful PAYMENT-RESPONSE was paid, so paying again would pay twice.
+func isRechallenge(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusPaymentRequired || resp.Header.Get("PAYMENT-REQUIRED") == "" {
+		return false
+	}
+	if header := resp.Header.Get("PAYMENT-RESPONSE"); header != "" {
+		if settle, err := decodePaymentResponseHeader(header); err == nil && settle.Success {
+			return false
+		}
+	}
+	return true
+}
+
+// replayableRequest returns req, or a copy with its body buffered in memory when the body
+// can't be re-read through GetBody (e.g. a request built from a plain io.Reader)
+func replayableRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return req, nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	buffered := req.Clone(req.Context())
+	buffered.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	buffered.Body, _ = buffered.GetBody()
+	buffered.ContentLength = int64(len(data))
+	return buffered, nil
+}
 
-	return newResp, err
+// cloneRequest copies req for a paid retry, rewinding its body when possible
+func cloneRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	clone := req.Clone(ctx)
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewind request body: %w", err)
+		}
+		clone.Body = body
+	}
+	return clone, nil
 }
 
 // handleV1Payment processes V1 PaymentRequired and creates V1 payload
@@ -285,20 +462,22 @@ func (t *PaymentRoundTripper) handleV2Payment(ctx context.Context, headers map[s
 		return nil, fmt.Errorf("no V2 payment required information found")
 	}
 
-	// Select V2 requirements
-	selectedV2, err := t.x402Client.client.SelectPaymentRequirements(paymentRequiredV2.Accepts)
-	if err != nil {
-		return nil, fmt.Errorf("cannot fulfill V2 payment requirements: %w", err)
+	// Select V2 requirements and create the payment payload
+	payloadV2, err := t.x402Client.client.CreatePaymentPayloadFor(ctx, paymentRequiredV2, t.x402Client.fallback)
+	if err == nil && t.x402Client.expiresSoon(payloadV2) {
+		// Stale authorization (e.g. reused by a hook); create it again once
+		payloadV2, err = t.x402Client.client.CreatePaymentPayloadFor(ctx, paymentRequiredV2, t.x402Client.fallback)
+		if err == nil {
+			if expiry, ok := t.x402Client.client.PayloadExpiry(payloadV2); ok && !time.Now().Before(expiry) {
+				return nil, fmt.Errorf("failed to create V2 payment: authorization expired at %s", expiry.UTC().Format(time.RFC3339))
+			}
+		}
 	}
-
-	// Create V2 payment payload
-	payloadV2, err := t.x402Client.client.CreatePaymentPayload(
-		ctx,
-		selectedV2,
-		paymentRequiredV2.Resource,
-		paymentRequiredV2.Extensions,
-	)
 	if err != nil {
+		var paymentErr *x402.PaymentError
+		if errors.As(err, &paymentErr) && paymentErr.Code == x402.ErrCodeUnsupportedScheme {
+			return nil, fmt.Errorf("cannot fulfill V2 payment requirements: %w", err)
+		}
 		return nil, fmt.Errorf("failed to create V2 payment: %w", err)
 	}
 
@@ -306,35 +485,42 @@ func (t *PaymentRoundTripper) handleV2Payment(ctx context.Context, headers map[s
 	return json.Marshal(payloadV2)
 }
 
-// detectPaymentRequiredVersion detects protocol version from HTTP response
-func detectPaymentRequiredVersion(headers map[string]string, body []byte) (int, error) {
+// expiresSoon reports whether payload expires within the client's expiry margin
+func (c *x402HTTPClient) expiresSoon(payload types.PaymentPayload) bool {
+	expiry, ok := c.client.PayloadExpiry(payload)
+	return ok && time.Until(expiry) < c.expiryMargin
+}
+
+// paymentRequiredVersions returns the protocol versions a 402 response offers, newest first.
+// V2 offers its challenge in the PAYMENT-REQUIRED header, V1 in the body (some V2 servers
+// also use the body).
+func paymentRequiredVersions(headers map[string]string, body []byte) ([]int, error) {
 	// Normalize headers
 	normalizedHeaders := make(map[string]string)
 	for k, v := range headers {
 		normalizedHeaders[strings.ToUpper(k)] = v
 	}
 
-	// V2 uses PAYMENT-REQUIRED header
+	var versions []int
 	if _, exists := normalizedHeaders["PAYMENT-REQUIRED"]; exists {
-		return 2, nil
+		versions = append(versions, 2)
 	}
 
-	// V1 uses body with x402Version field
 	if len(body) > 0 {
-		var versionCheck struct {
-			X402Version int `json:"x402Version"`
-		}
-		if err := json.Unmarshal(body, &versionCheck); err == nil {
-			if versionCheck.X402Version == 1 {
-				return 1, nil
-			}
-			if versionCheck.X402Version == 2 {
-				return 2, nil
+		if version, err := types.DetectVersion(body); err == nil {
+			switch {
+			case version == 1:
+				versions = append(versions, 1)
+			case version == 2 && len(versions) == 0:
+				versions = append(versions, 2)
 			}
 		}
 	}
 
-	return 0, fmt.Errorf("could not detect x402 version from response")
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("could not detect x402 version from response")
+	}
+	return versions, nil
 }
 
 // ============================================================================
@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	x402 "x402-go"
 	"x402-go/types"
@@ -154,6 +157,15 @@ func TestGetPaymentRequiredResponse(t *testing.T) {
 		t.Errorf("Expected version 1, got %d", result.X402Version)
 	}
 
+	// Test v2 requirements sent as the JSON body
+	result, err = client.GetPaymentRequiredResponse(map[string]string{}, reqJSON)
+	if err != nil {
+		t.Fatalf("Unexpected error for v2 body: %v", err)
+	}
+	if result.X402Version != 2 || len(result.Accepts) != 1 {
+		t.Errorf("Expected v2 requirements from body, got %+v", result)
+	}
+
 	// Test no payment required found
 	_, err = client.GetPaymentRequiredResponse(map[string]string{}, nil)
 	if err == nil {
@@ -284,6 +296,347 @@ func TestPaymentRoundTripper(t *testing.T) {
 	}
 }
 
+func TestEncodePaymentHeader(t *testing.T) {
+	client := Newx402HTTPClient(x402.Newx402Client())
+	v1Payload, _ := json.Marshal(types.PaymentPayloadV1{X402Version: 1, Scheme: "mock", Network: "test:1"})
+
+	headers, err := client.EncodePaymentHeader(1, v1Payload)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, exists := headers[PaymentHeaderV1]; !exists || len(headers) != 1 {
+		t.Errorf("Expected only the %s header, got %v", PaymentHeaderV1, headers)
+	}
+
+	if _, err := client.EncodePaymentHeader(2, v1Payload); err == nil {
+		t.Error("Expected an error for a v1 payment answering a v2 challenge")
+	}
+	if _, err := client.EncodePaymentHeader(3, v1Payload); err == nil {
+		t.Error("Expected an error for an unsupported version")
+	}
+}
+
+// TestPaymentRoundTripperHeaderVersion tests that a client registered for both versions
+// answers each server with the payment header of the version it spoke
+func TestPaymentRoundTripperHeaderVersion(t *testing.T) {
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	x402Client.RegisterV1("test:1", &mockSchemeClientV1{scheme: "mock"})
+	httpClient := WrapHTTPClientWithPayment(&http.Client{}, Newx402HTTPClient(x402Client))
+
+	tests := []struct {
+		name      string
+		challenge func(w http.ResponseWriter)
+		header    string
+		other     string
+	}{
+		{
+			name: "v1 server",
+			challenge: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusPaymentRequired)
+				_ = json.NewEncoder(w).Encode(types.PaymentRequiredV1{
+					X402Version: 1,
+					Accepts: []types.PaymentRequirementsV1{
+						{Scheme: "mock", Network: "test:1", MaxAmountRequired: "1000", PayTo: "0xtest", Asset: "TEST"},
+					},
+				})
+			},
+			header: PaymentHeaderV1,
+			other:  PaymentSignatureHeader,
+		},
+		{
+			name: "v2 server",
+			challenge: func(w http.ResponseWriter) {
+				w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader("1000"))
+				w.WriteHeader(http.StatusPaymentRequired)
+			},
+			header: PaymentSignatureHeader,
+			other:  PaymentHeaderV1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get(tt.header) == "" {
+					tt.challenge(w)
+					return
+				}
+				if r.Header.Get(tt.other) != "" {
+					t.Errorf("Expected no %s header", tt.other)
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			resp, err := httpClient.Get(server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", resp.StatusCode)
+			}
+		})
+	}
+}
+
+// TestPaymentRoundTripperVersionNegotiation tests that a 402 offering both versions is
+// answered in V2 when the client can, and in V1 otherwise
+func TestPaymentRoundTripperVersionNegotiation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get(PaymentSignatureHeader) != "":
+			w.Write([]byte("v2"))
+		case r.Header.Get(PaymentHeaderV1) != "":
+			w.Write([]byte("v1"))
+		default:
+			w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader("1000"))
+			w.WriteHeader(http.StatusPaymentRequired)
+			_ = json.NewEncoder(w).Encode(types.PaymentRequiredV1{
+				X402Version: 1,
+				Accepts: []types.PaymentRequirementsV1{
+					{Scheme: "mock", Network: "test:1", MaxAmountRequired: "1000", PayTo: "0xtest", Asset: "TEST"},
+				},
+			})
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		register func(c *x402.X402Client)
+		expected string
+	}{
+		{
+			name: "prefers v2",
+			register: func(c *x402.X402Client) {
+				c.Register("test:1", &mockSchemeClient{scheme: "mock"})
+				c.RegisterV1("test:1", &mockSchemeClientV1{scheme: "mock"})
+			},
+			expected: "v2",
+		},
+		{
+			name: "falls back to v1",
+			register: func(c *x402.X402Client) {
+				c.RegisterV1("test:1", &mockSchemeClientV1{scheme: "mock"})
+			},
+			expected: "v1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x402Client := x402.Newx402Client()
+			tt.register(x402Client)
+
+			resp, err := Newx402HTTPClient(x402Client).GetWithPayment(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != http.StatusOK || string(body) != tt.expected {
+				t.Errorf("Expected a %s payment, got %d %q", tt.expected, resp.StatusCode, body)
+			}
+		})
+	}
+}
+
+// paymentRequiredHeader encodes a V2 PaymentRequired for the given amount
+func paymentRequiredHeader(amount string) string {
+	required := x402.PaymentRequired{
+		X402Version: 2,
+		Accepts: []x402.PaymentRequirements{
+			{Scheme: "mock", Network: "test:1", Asset: "TEST", Amount: amount, PayTo: "0xtest"},
+		},
+	}
+	reqJSON, _ := json.Marshal(required)
+	return base64.StdEncoding.EncodeToString(reqJSON)
+}
+
+// paidAmount decodes the amount accepted by the payment in r, or "" when unpaid
+func paidAmount(r *http.Request) string {
+	header := r.Header.Get("PAYMENT-SIGNATURE")
+	if header == "" {
+		return ""
+	}
+	data, _ := base64.StdEncoding.DecodeString(header)
+	var payload x402.PaymentPayload
+	_ = json.Unmarshal(data, &payload)
+	return payload.Accepted.Amount
+}
+
+func TestPaymentRoundTripperRechallenge(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxRechallenges int
+		handler         func(w http.ResponseWriter, r *http.Request)
+		expectedStatus  int
+		expectedCalls   int
+	}{
+		{
+			name:            "price changed after the first payment",
+			maxRechallenges: DefaultMaxRechallenges,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch paidAmount(r) {
+				case "":
+					w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader("1000"))
+					w.WriteHeader(http.StatusPaymentRequired)
+				case "1000":
+					w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader("2000"))
+					w.WriteHeader(http.StatusPaymentRequired)
+				default:
+					w.WriteHeader(http.StatusOK)
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedCalls:  3,
+		},
+		{
+			name:            "re-challenges are bounded",
+			maxRechallenges: 2,
+			handler: func() func(w http.ResponseWriter, r *http.Request) {
+				price := 1000
+				return func(w http.ResponseWriter, r *http.Request) {
+					price++
+					w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader(strconv.Itoa(price)))
+					w.WriteHeader(http.StatusPaymentRequired)
+				}
+			}(),
+			expectedStatus: http.StatusPaymentRequired,
+			expectedCalls:  4,
+		},
+		{
+			name:            "disabled",
+			maxRechallenges: 0,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader("1000"))
+				w.WriteHeader(http.StatusPaymentRequired)
+			},
+			expectedStatus: http.StatusPaymentRequired,
+			expectedCalls:  2,
+		},
+		{
+			name:            "same payment is not replayed",
+			maxRechallenges: 3,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader("1000"))
+				w.WriteHeader(http.StatusPaymentRequired)
+			},
+			expectedStatus: http.StatusPaymentRequired,
+			expectedCalls:  2,
+		},
+		{
+			name:            "settled payment is not paid again",
+			maxRechallenges: 3,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if paidAmount(r) != "" {
+					w.Header().Set("PAYMENT-RESPONSE", encodePaymentResponseHeader(x402.SettleResponse{Success: true, Transaction: "0xtx"}))
+				}
+				w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader(strconv.Itoa(1000+len(r.Header.Get("PAYMENT-SIGNATURE")))))
+				w.WriteHeader(http.StatusPaymentRequired)
+			},
+			expectedStatus: http.StatusPaymentRequired,
+			expectedCalls:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				tt.handler(w, r)
+			}))
+			defer server.Close()
+
+			x402Client := x402.Newx402Client()
+			x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+			client := Newx402HTTPClient(x402Client, WithMaxRechallenges(tt.maxRechallenges))
+
+			resp, err := client.GetWithPayment(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("Expected %d calls to server, got %d", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestPaymentRoundTripperRechallengeReplaysBody(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "test body" {
+			t.Errorf("Call %d: expected 'test body', got %q", calls, string(body))
+		}
+		if paidAmount(r) != "2000" {
+			w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader(strconv.Itoa(1000*calls)))
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+
+	resp, err := Newx402HTTPClient(x402Client).PostWithPayment(context.Background(), server.URL, strings.NewReader("test body"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Errorf("Expected 200 after 3 calls, got %d after %d", resp.StatusCode, calls)
+	}
+}
+
+func TestPaymentRoundTripperReplaysBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("PAYMENT-SIGNATURE") == "" {
+			w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader("1000"))
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	x402Client := x402.Newx402Client()
+	x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+	client := Newx402HTTPClient(x402Client)
+
+	// A plain io.Reader leaves GetBody unset, so the body must be buffered to be sent twice
+	body := io.MultiReader(strings.NewReader(`{"query":`), strings.NewReader(`"x402"}`))
+	resp, err := client.PostWithPayment(context.Background(), server.URL, body)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if len(bodies) != 2 || bodies[0] != `{"query":"x402"}` || bodies[1] != bodies[0] {
+		t.Errorf("Expected the body on both requests, got %q", bodies)
+	}
+}
+
 func TestPaymentRoundTripperNoRetryOn200(t *testing.T) {
 	// Server that always returns 200
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -328,6 +681,192 @@ func TestDoWithPayment(t *testing.T) {
 	}
 }
 
+// unavailableSchemeClient fails to create payments, as when its network's RPC is down
+type unavailableSchemeClient struct {
+	scheme string
+}
+
+func (m *unavailableSchemeClient) Scheme() string {
+	return m.scheme
+}
+
+func (m *unavailableSchemeClient) CreatePaymentPayload(ctx context.Context, requirements types.PaymentRequirements) (types.PaymentPayload, error) {
+	return types.PaymentPayload{}, errors.New("rpc unavailable")
+}
+
+func TestDoWithPaymentFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("PAYMENT-SIGNATURE")
+		if header == "" {
+			required := x402.PaymentRequired{
+				X402Version: 2,
+				Accepts: []x402.PaymentRequirements{
+					{Scheme: "mock", Network: "down:1", Asset: "TEST", Amount: "1000", PayTo: "0xtest"},
+					{Scheme: "mock", Network: "test:1", Asset: "TEST", Amount: "1000", PayTo: "0xtest"},
+				},
+			}
+			reqJSON, _ := json.Marshal(required)
+			w.Header().Set("PAYMENT-REQUIRED", base64.StdEncoding.EncodeToString(reqJSON))
+			w.WriteHeader(http.StatusPaymentRequired)
+			return
+		}
+
+		data, _ := base64.StdEncoding.DecodeString(header)
+		var payload x402.PaymentPayload
+		_ = json.Unmarshal(data, &payload)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(payload.Accepted.Network))
+	}))
+	defer server.Close()
+
+	newClient := func(attempts *[]int, opts ...HTTPClientOption) *x402HTTPClient {
+		x402Client := x402.Newx402Client()
+		x402Client.Register("down:1", &unavailableSchemeClient{scheme: "mock"})
+		x402Client.Register("test:1", &mockSchemeClient{scheme: "mock"})
+		x402Client.OnPaymentCreationFailure(func(ctx x402.PaymentCreationFailureContext) (*x402.PaymentCreationFailureHookResult, error) {
+			*attempts = append(*attempts, ctx.Attempt)
+			return nil, nil
+		})
+		return Newx402HTTPClient(x402Client, opts...)
+	}
+
+	t.Run("falls back", func(t *testing.T) {
+		var attempts []int
+		client := newClient(&attempts, WithPaymentFallback())
+		req, _ := http.NewRequest("GET", server.URL, nil)
+
+		resp, err := client.DoWithPayment(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != "test:1" {
+			t.Errorf("Expected payment on test:1, got %d %s", resp.StatusCode, body)
+		}
+		if len(attempts) != 1 || attempts[0] != 0 {
+			t.Errorf("Expected one failed attempt 0, got %v", attempts)
+		}
+	})
+
+	t.Run("fails without fallback", func(t *testing.T) {
+		var attempts []int
+		client := newClient(&attempts)
+		req, _ := http.NewRequest("GET", server.URL, nil)
+
+		_, err := client.DoWithPayment(context.Background(), req)
+		if err == nil || !strings.Contains(err.Error(), "rpc unavailable") {
+			t.Errorf("Expected the creation failure, got %v", err)
+		}
+		if len(attempts) != 1 {
+			t.Errorf("Expected one failed attempt, got %v", attempts)
+		}
+	})
+}
+
+// expiringSchemeClient creates payments valid for the given durations in turn, reusing the
+// last one, and reports their expiry
+type expiringSchemeClient struct {
+	mockSchemeClient
+	validFor []time.Duration
+	created  int
+}
+
+func (m *expiringSchemeClient) CreatePaymentPayload(ctx context.Context, requirements types.PaymentRequirements) (types.PaymentPayload, error) {
+	validFor := m.validFor[min(m.created, len(m.validFor)-1)]
+	m.created++
+	return types.PaymentPayload{
+		X402Version: 2,
+		Payload:     map[string]interface{}{"validBefore": time.Now().Add(validFor).Unix(), "attempt": m.created},
+	}, nil
+}
+
+func (m *expiringSchemeClient) PayloadExpiry(payload types.PaymentPayload) (time.Time, bool) {
+	validBefore, ok := payload.Payload["validBefore"].(int64)
+	return time.Unix(validBefore, 0), ok
+}
+
+func TestDoWithPaymentRefreshesExpiringPayment(t *testing.T) {
+	tests := []struct {
+		name            string
+		validFor        []time.Duration
+		opts            []HTTPClientOption
+		expectedCreated int
+		expectError     bool
+	}{
+		{
+			name:            "fresh payment is sent",
+			validFor:        []time.Duration{time.Minute},
+			expectedCreated: 1,
+		},
+		{
+			name:            "expired payment is regenerated",
+			validFor:        []time.Duration{-time.Minute, time.Minute},
+			expectedCreated: 2,
+		},
+		{
+			name:            "payment expiring within the margin is regenerated",
+			validFor:        []time.Duration{5 * time.Second, time.Minute},
+			expectedCreated: 2,
+		},
+		{
+			name:            "payment outside a smaller margin is sent",
+			validFor:        []time.Duration{5 * time.Second},
+			opts:            []HTTPClientOption{WithExpiryMargin(time.Second)},
+			expectedCreated: 1,
+		},
+		{
+			name:            "payment still expired after regenerating fails",
+			validFor:        []time.Duration{-time.Minute},
+			expectedCreated: 2,
+			expectError:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paid := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if paidAmount(r) == "" {
+					w.Header().Set("PAYMENT-REQUIRED", paymentRequiredHeader("1000"))
+					w.WriteHeader(http.StatusPaymentRequired)
+					return
+				}
+				paid++
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			scheme := &expiringSchemeClient{mockSchemeClient: mockSchemeClient{scheme: "mock"}, validFor: tt.validFor}
+			x402Client := x402.Newx402Client()
+			x402Client.Register("test:1", scheme)
+			req, _ := http.NewRequest("GET", server.URL, nil)
+
+			resp, err := Newx402HTTPClient(x402Client, tt.opts...).DoWithPayment(context.Background(), req)
+			if tt.expectError {
+				if err == nil || !strings.Contains(err.Error(), "expired") {
+					t.Errorf("Expected an expiry error, got %v", err)
+				}
+				if paid != 0 {
+					t.Errorf("Expected no payment to be sent, got %d", paid)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK || paid != 1 {
+					t.Errorf("Expected one payment accepted, got %d after %d", resp.StatusCode, paid)
+				}
+			}
+			if scheme.created != tt.expectedCreated {
+				t.Errorf("Expected %d payments created, got %d", tt.expectedCreated, scheme.created)
+			}
+		})
+	}
+}
+
 func TestGetWithPayment(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "GET" {
@@ -385,3 +924,21 @@ func (m *mockSchemeClient) CreatePaymentPayload(ctx context.Context, requirement
 		Payload:     map[string]interface{}{"mock": "payload"},
 	}, nil
 }
+
+// Mock V1 scheme client for testing
+type mockSchemeClientV1 struct {
+	scheme string
+}
+
+func (m *mockSchemeClientV1) Scheme() string {
+	return m.scheme
+}
+
+func (m *mockSchemeClientV1) CreatePaymentPayload(ctx context.Context, requirements types.PaymentRequirementsV1) (types.PaymentPayloadV1, error) {
+	return types.PaymentPayloadV1{
+		X402Version: 1,
+		Scheme:      requirements.Scheme,
+		Network:     requirements.Network,
+		Payload:     map[string]interface{}{"mock": "payload"},
+	}, nil
+}
@@ -0,0 +1,130 @@
+package echo
+
+import (
+	"html/template"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	x402 "x402-go"
+	x402http "x402-go/http"
+)
+
+// Config provides struct-based configuration for x402 payment middleware.
+// Fields match ginmw.Config so Gin setups can be ported field-for-field.
+type Config struct {
+	// Routes maps HTTP patterns to payment requirements
+	Routes x402http.RoutesConfig
+
+	// Facilitator is a single facilitator client (most common case)
+	// Use this OR Facilitators (not both)
+	Facilitator x402.FacilitatorClient
+
+	// Facilitators is an array of facilitator clients (for fallback/redundancy)
+	// Use this OR Facilitator (not both)
+	Facilitators []x402.FacilitatorClient
+
+	// Schemes to register with the server
+	Schemes []SchemeConfig
+
+	// PaywallConfig for browser-based payment UI (optional)
+	PaywallConfig *x402http.PaywallConfig
+
+	// PaywallTemplate renders the browser paywall instead of the built-in page (optional)
+	PaywallTemplate *template.Template
+
+	// SyncFacilitatorOnStart fetches supported kinds from facilitators on startup
+	// Default: true when facilitators are configured
+	SyncFacilitatorOnStart bool
+
+	// Timeout for payment operations
+	// Default: 30 seconds
+	Timeout time.Duration
+
+	// ErrorHandler for custom settlement error handling (optional)
+	ErrorHandler func(echo.Context, error) error
+
+	// SettlementHandler called after successful settlement (optional)
+	SettlementHandler func(echo.Context, *x402.SettleResponse)
+
+	// PaymentRequiredBody sets whether 402 responses to API clients carry the PaymentRequired
+	// structure as their JSON body, for routes that don't set it themselves (optional)
+	PaymentRequiredBody x402http.PaymentRequiredBody
+}
+
+// SchemeConfig configures a payment scheme for a network.
+type SchemeConfig struct {
+	Network x402.Network
+	Server  x402.SchemeNetworkServer
+}
+
+// X402Payment creates payment middleware using struct-based configuration.
+//
+// Args:
+//
+//	config: Payment middleware configuration
+//
+// Returns:
+//
+//	Echo middleware function
+//
+// Example:
+//
+//	e.Use(echomw.X402Payment(echomw.Config{
+//	    Routes: routes,
+//	    Facilitator: facilitatorClient,
+//	    Schemes: []echomw.SchemeConfig{
+//	        {Network: "eip155:*", Server: evm.NewExactEvmScheme()},
+//	    },
+//	    Timeout: 30 * time.Second,
+//	}))
+func X402Payment(config Config) echo.MiddlewareFunc {
+	// Set defaults
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	// Normalize facilitators list
+	var facilitators []x402.FacilitatorClient
+	if config.Facilitator != nil {
+		facilitators = append(facilitators, config.Facilitator)
+	}
+	facilitators = append(facilitators, config.Facilitators...)
+
+	// Sync whenever facilitators are configured, matching the Gin builder
+	syncOnStart := config.SyncFacilitatorOnStart || len(facilitators) > 0
+
+	// Convert to middleware options
+	opts := []MiddlewareOption{
+		WithSyncFacilitatorOnStart(syncOnStart),
+		WithTimeout(config.Timeout),
+	}
+
+	// Add facilitators
+	for _, facilitator := range facilitators {
+		opts = append(opts, WithFacilitatorClient(facilitator))
+	}
+
+	// Add schemes
+	for _, scheme := range config.Schemes {
+		opts = append(opts, WithScheme(scheme.Network, scheme.Server))
+	}
+
+	// Add optional handlers
+	if config.PaywallConfig != nil {
+		opts = append(opts, WithPaywallConfig(config.PaywallConfig))
+	}
+	if config.PaywallTemplate != nil {
+		opts = append(opts, WithPaywallTemplate(config.PaywallTemplate))
+	}
+	if config.ErrorHandler != nil {
+		opts = append(opts, WithErrorHandler(config.ErrorHandler))
+	}
+	if config.SettlementHandler != nil {
+		opts = append(opts, WithSettlementHandler(config.SettlementHandler))
+	}
+	if config.PaymentRequiredBody != "" {
+		opts = append(opts, WithPaymentRequiredBody(config.PaymentRequiredBody))
+	}
+
+	return PaymentMiddlewareFromConfig(config.Routes, opts...)
+}
@@ -0,0 +1,429 @@
+package echo
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	x402 "x402-go"
+	"x402-go/extensions/bazaar"
+	x402http "x402-go/http"
+)
+
+// ============================================================================
+// Echo Adapter Implementation
+// ============================================================================
+
+// EchoAdapter implements HTTPAdapter for the Echo framework
+type EchoAdapter struct {
+	ctx echo.Context
+}
+
+// NewEchoAdapter creates a new Echo adapter
+func NewEchoAdapter(ctx echo.Context) *EchoAdapter {
+	return &EchoAdapter{ctx: ctx}
+}
+
+// GetHeader gets a request header
+func (a *EchoAdapter) GetHeader(name string) string {
+	return a.ctx.Request().Header.Get(name)
+}
+
+// GetMethod gets the HTTP method
+func (a *EchoAdapter) GetMethod() string {
+	return a.ctx.Request().Method
+}
+
+// GetPath gets the request path
+func (a *EchoAdapter) GetPath() string {
+	return a.ctx.Request().URL.Path
+}
+
+// GetURL gets the full request URL
+func (a *EchoAdapter) GetURL() string {
+	req := a.ctx.Request()
+	host := req.Host
+	if host == "" {
+		host = req.Header.Get("Host")
+	}
+	return fmt.Sprintf("%s://%s%s", a.ctx.Scheme(), host, req.URL.Path)
+}
+
+// GetAcceptHeader gets the Accept header
+func (a *EchoAdapter) GetAcceptHeader() string {
+	return a.ctx.Request().Header.Get("Accept")
+}
+
+// GetUserAgent gets the User-Agent header
+func (a *EchoAdapter) GetUserAgent() string {
+	return a.ctx.Request().Header.Get("User-Agent")
+}
+
+// GetQueryParams gets the URL query parameters
+func (a *EchoAdapter) GetQueryParams() map[string][]string {
+	return a.ctx.Request().URL.Query()
+}
+
+// GetBody gets the request body, leaving it readable by the next handler
+func (a *EchoAdapter) GetBody() ([]byte, error) {
+	return x402http.ReadRequestBody(a.ctx.Request())
+}
+
+// ============================================================================
+// Middleware Configuration
+// ============================================================================
+
+// MiddlewareConfig configures the payment middleware
+type MiddlewareConfig struct {
+	// Routes configuration
+	Routes x402http.RoutesConfig
+
+	// Facilitator client(s)
+	FacilitatorClients []x402.FacilitatorClient
+
+	// Scheme registrations
+	Schemes []SchemeRegistration
+
+	// Paywall configuration
+	PaywallConfig *x402http.PaywallConfig
+
+	// Custom paywall page template (overrides PaywallConfig.Template)
+	PaywallTemplate *template.Template
+
+	// Sync with facilitator on start
+	SyncFacilitatorOnStart bool
+
+	// Custom error handler
+	ErrorHandler func(echo.Context, error) error
+
+	// Custom settlement handler
+	SettlementHandler func(echo.Context, *x402.SettleResponse)
+
+	// Context timeout for payment operations
+	Timeout time.Duration
+
+	// Default for routes that don't set RouteConfig.PaymentRequiredBody
+	PaymentRequiredBody x402http.PaymentRequiredBody
+
+	// Logger for the server created by PaymentMiddlewareFromConfig
+	Logger x402.Logger
+}
+
+// SchemeRegistration registers a scheme with the server
+type SchemeRegistration struct {
+	Network x402.Network
+	Server  x402.SchemeNetworkServer
+}
+
+// MiddlewareOption configures the middleware
+type MiddlewareOption func(*MiddlewareConfig)
+
+// WithFacilitatorClient adds a facilitator client
+func WithFacilitatorClient(client x402.FacilitatorClient) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.FacilitatorClients = append(c.FacilitatorClients, client)
+	}
+}
+
+// WithScheme registers a scheme server
+func WithScheme(network x402.Network, schemeServer x402.SchemeNetworkServer) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.Schemes = append(c.Schemes, SchemeRegistration{
+			Network: network,
+			Server:  schemeServer,
+		})
+	}
+}
+
+// WithPaywallConfig sets the paywall configuration
+func WithPaywallConfig(config *x402http.PaywallConfig) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.PaywallConfig = config
+	}
+}
+
+// WithPaywallTemplate renders the browser paywall with tmpl, which is executed with a
+// *x402http.PaywallTemplateData. The built-in page is served if it fails.
+func WithPaywallTemplate(tmpl *template.Template) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.PaywallTemplate = tmpl
+	}
+}
+
+// WithSyncFacilitatorOnStart sets whether to sync with facilitator on startup
+func WithSyncFacilitatorOnStart(sync bool) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.SyncFacilitatorOnStart = sync
+	}
+}
+
+// WithErrorHandler sets a custom error handler
+func WithErrorHandler(handler func(echo.Context, error) error) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.ErrorHandler = handler
+	}
+}
+
+// WithSettlementHandler sets a custom settlement handler
+func WithSettlementHandler(handler func(echo.Context, *x402.SettleResponse)) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.SettlementHandler = handler
+	}
+}
+
+// WithTimeout sets the context timeout for payment operations
+func WithTimeout(timeout time.Duration) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.Timeout = timeout
+	}
+}
+
+// WithPaymentRequiredBody sets whether 402 responses to API clients carry the PaymentRequired
+// structure as their JSON body as well as in the PAYMENT-REQUIRED header, for routes that
+// don't set RouteConfig.PaymentRequiredBody themselves
+func WithPaymentRequiredBody(mode x402http.PaymentRequiredBody) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.PaymentRequiredBody = mode
+	}
+}
+
+// WithLogger sets the logger used by PaymentMiddlewareFromConfig.
+// PaymentMiddleware uses the logger of the server it is given.
+func WithLogger(logger x402.Logger) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.Logger = logger
+	}
+}
+
+// ============================================================================
+// Payment Middleware
+// ============================================================================
+
+// PaymentMiddleware creates Echo middleware for x402 payment handling using a pre-configured server.
+func PaymentMiddleware(routes x402http.RoutesConfig, server *x402.X402ResourceServer, opts ...MiddlewareOption) echo.MiddlewareFunc {
+	config := &MiddlewareConfig{
+		Routes:                 routes,
+		SyncFacilitatorOnStart: true,
+		Timeout:                30 * time.Second,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	// Wrap the resource server with HTTP functionality
+	httpServer := x402http.Wrappedx402HTTPResourceServer(routes.WithPaymentRequiredBody(config.PaymentRequiredBody), server)
+
+	httpServer.RegisterExtension(bazaar.BazaarResourceServerExtension)
+
+	// Initialize if requested - queries facilitator /supported to populate facilitatorClients map
+	if config.SyncFacilitatorOnStart {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+		defer cancel()
+		if err := httpServer.Initialize(ctx); err != nil {
+			httpServer.Logger().Warn("failed to initialize x402 server", "error", err)
+		}
+	}
+
+	return createMiddlewareFunc(httpServer, config)
+}
+
+// PaymentMiddlewareFromConfig creates Echo middleware for x402 payment handling.
+// This creates the server internally from the provided options.
+func PaymentMiddlewareFromConfig(routes x402http.RoutesConfig, opts ...MiddlewareOption) echo.MiddlewareFunc {
+	config := &MiddlewareConfig{
+		Routes:                 routes,
+		FacilitatorClients:     []x402.FacilitatorClient{},
+		Schemes:                []SchemeRegistration{},
+		SyncFacilitatorOnStart: true,
+		Timeout:                30 * time.Second,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	serverOpts := []x402.ResourceServerOption{}
+	for _, client := range config.FacilitatorClients {
+		serverOpts = append(serverOpts, x402.WithFacilitatorClient(client))
+	}
+	if config.Logger != nil {
+		serverOpts = append(serverOpts, x402.WithServerLogger(config.Logger))
+	}
+
+	httpServer := x402http.Newx402HTTPResourceServer(config.Routes.WithPaymentRequiredBody(config.PaymentRequiredBody), serverOpts...)
+
+	httpServer.RegisterExtension(bazaar.BazaarResourceServerExtension)
+
+	// Register schemes
+	for _, scheme := range config.Schemes {
+		httpServer.Register(scheme.Network, scheme.Server)
+	}
+
+	// Initialize if requested - queries facilitator /supported to populate facilitatorClients map
+	if config.SyncFacilitatorOnStart {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+		defer cancel()
+		if err := httpServer.Initialize(ctx); err != nil {
+			httpServer.Logger().Warn("failed to initialize x402 server", "error", err)
+		}
+	}
+
+	return createMiddlewareFunc(httpServer, config)
+}
+
+// createMiddlewareFunc creates the actual Echo middleware function.
+func createMiddlewareFunc(server *x402http.HTTPServer, config *MiddlewareConfig) echo.MiddlewareFunc {
+	paywallConfig := config.PaywallConfig.WithTemplate(config.PaywallTemplate)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// Create adapter and request context
+			adapter := NewEchoAdapter(c)
+			reqCtx := x402http.HTTPRequestContext{
+				Adapter: adapter,
+				Path:    c.Request().URL.Path,
+				Method:  c.Request().Method,
+			}
+
+			// Check if route requires payment before waiting for initialization
+			if !server.RequiresPayment(reqCtx) {
+				return next(c)
+			}
+
+			// Create context with timeout
+			ctx, cancel := context.WithTimeout(c.Request().Context(), config.Timeout)
+			defer cancel()
+
+			result := server.ProcessHTTPRequest(ctx, reqCtx, paywallConfig)
+
+			// Handle result
+			switch result.Type {
+			case x402http.ResultPaymentError:
+				// Payment required but not provided or invalid
+				return handlePaymentError(c, result.Response)
+
+			case x402http.ResultPaymentVerified:
+				if result.SettlesBeforeResponse() {
+					// Settle first; the handler only runs once the payment is confirmed
+					if settled, err := settle(c, server, ctx, result, config); !settled {
+						return err
+					}
+					return next(c)
+				}
+
+				// Payment verified, settle when the handler commits its status
+				return handlePaymentVerified(c, next, server, ctx, result, config)
+
+			default:
+				// No payment required, continue to next handler
+				return next(c)
+			}
+		}
+	}
+}
+
+// handlePaymentError handles payment error responses
+func handlePaymentError(c echo.Context, response *x402http.HTTPResponseInstructions) error {
+	// Set headers
+	for key, value := range response.Headers {
+		c.Response().Header().Set(key, value)
+	}
+
+	// Send response body
+	if response.IsHTML {
+		return c.HTML(response.Status, response.Body.(string))
+	}
+	return c.JSON(response.Status, response.Body)
+}
+
+// handlePaymentVerified runs the protected handler behind a x402http.SettlingWriter
+func handlePaymentVerified(c echo.Context, next echo.HandlerFunc, server *x402http.HTTPServer, ctx context.Context, result x402http.HTTPProcessResult, config *MiddlewareConfig) error {
+	res := c.Response()
+
+	var settleErr error
+	var writer *x402http.SettlingWriter
+	writer = x402http.NewSettlingWriter(res.Writer, func(w http.ResponseWriter) bool {
+		// Settlement failure responses are written to the real writer
+		res.Writer = w
+		defer func() { res.Writer = writer }()
+		ok, err := settle(c, server, ctx, result, config)
+		settleErr = err
+		return ok
+	})
+	res.Writer = writer
+
+	// Continue to protected handler
+	handlerErr := next(c)
+
+	// Handlers that write nothing still get an implicit 200, which is settled.
+	// Handler errors that were not yet rendered are left to Echo's error
+	// handler; nothing was delivered, so nothing is settled.
+	if handlerErr == nil && !res.Committed {
+		res.WriteHeader(http.StatusOK)
+	}
+
+	// Restore original writer
+	res.Writer = writer.ResponseWriter
+
+	if handlerErr != nil {
+		return handlerErr
+	}
+	return settleErr
+}
+
+// settle processes settlement and either injects the settlement headers (returning true)
+// or writes the settlement failure response (returning false)
+func settle(c echo.Context, server *x402http.HTTPServer, ctx context.Context, result x402http.HTTPProcessResult, config *MiddlewareConfig) (bool, error) {
+	res := c.Response()
+
+	settleResult := server.ProcessSettlement(
+		ctx,
+		*result.PaymentPayload,
+		*result.PaymentRequirements,
+	)
+
+	// Check settlement success
+	if !settleResult.Success {
+		errorReason := settleResult.ErrorReason
+		if errorReason == "" {
+			errorReason = "Settlement failed"
+		}
+
+		// Drop headers the protected handler set for the response that is no longer sent
+		for key := range res.Header() {
+			res.Header().Del(key)
+		}
+
+		if config.ErrorHandler != nil {
+			return false, config.ErrorHandler(c, fmt.Errorf("settlement failed: %s", errorReason))
+		}
+		return false, c.JSON(http.StatusPaymentRequired, map[string]interface{}{
+			"error":   "Settlement failed",
+			"details": errorReason,
+		})
+	}
+
+	// Add settlement headers
+	for key, value := range settleResult.Headers {
+		res.Header().Set(key, value)
+	}
+
+	// Call settlement handler if configured
+	if config.SettlementHandler != nil {
+		settleResponse := &x402.SettleResponse{
+			Success:     true,
+			Transaction: settleResult.Transaction,
+			Network:     settleResult.Network,
+			Payer:       settleResult.Payer,
+		}
+		config.SettlementHandler(c, settleResponse)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,460 @@
+package echo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	x402 "x402-go"
+	x402http "x402-go/http"
+	"x402-go/types"
+)
+
+// ============================================================================
+// Mock Implementations
+// ============================================================================
+
+// mockSchemeServer implements x402.SchemeNetworkServer for testing
+type mockSchemeServer struct {
+	scheme string
+}
+
+func (m *mockSchemeServer) Scheme() string {
+	return m.scheme
+}
+
+func (m *mockSchemeServer) ParsePrice(price x402.Price, network x402.Network) (x402.AssetAmount, error) {
+	return x402.AssetAmount{
+		Asset:  "USDC",
+		Amount: "1000000",
+	}, nil
+}
+
+func (m *mockSchemeServer) EnhancePaymentRequirements(ctx context.Context, base types.PaymentRequirements, supported types.SupportedKind, extensions []string) (types.PaymentRequirements, error) {
+	return base, nil
+}
+
+// mockFacilitatorClient implements x402.FacilitatorClient for testing
+type mockFacilitatorClient struct {
+	verifyFunc func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error)
+	settleFunc func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error)
+}
+
+func (m *mockFacilitatorClient) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	if m.verifyFunc != nil {
+		return m.verifyFunc(ctx, payloadBytes, requirementsBytes)
+	}
+	return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+}
+
+func (m *mockFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	if m.settleFunc != nil {
+		return m.settleFunc(ctx, payloadBytes, requirementsBytes)
+	}
+	return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+}
+
+func (m *mockFacilitatorClient) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	return x402.SupportedResponse{
+		Kinds: []x402.SupportedKind{
+			{X402Version: 2, Scheme: "exact", Network: "eip155:1"},
+		},
+		Extensions: []string{},
+		Signers:    make(map[string][]string),
+	}, nil
+}
+
+func (m *mockFacilitatorClient) Identifier() string {
+	return "mock"
+}
+
+// ============================================================================
+// Test Helpers
+// ============================================================================
+
+// createTestServer registers handler for GET and POST /api behind the payment middleware
+func createTestServer(client x402.FacilitatorClient, handler echo.HandlerFunc, opts ...MiddlewareOption) *echo.Echo {
+	option := x402http.PaymentOptions{
+		{
+			Scheme:  "exact",
+			PayTo:   "0xtest",
+			Price:   "$1.00",
+			Network: "eip155:1",
+		},
+	}
+	routes := x402http.RoutesConfig{
+		"GET /api":  {Accepts: option, Description: "API access"},
+		"POST /api": {Accepts: option, Description: "API access"},
+	}
+
+	server := x402.Newx402ResourceServer(x402.WithFacilitatorClient(client))
+	server.Register("eip155:1", &mockSchemeServer{scheme: "exact"})
+
+	opts = append([]MiddlewareOption{WithTimeout(5 * time.Second)}, opts...)
+
+	e := echo.New()
+	e.Use(PaymentMiddleware(routes, server, opts...))
+	e.GET("/api", handler)
+	e.POST("/api", handler)
+	e.GET("/public", func(c echo.Context) error {
+		return c.String(http.StatusOK, "public")
+	})
+	return e
+}
+
+// newPaidRequest creates a request carrying a payment header
+func newPaidRequest(method string) *http.Request {
+	payload := x402.PaymentPayload{
+		X402Version: 2,
+		Payload:     map[string]interface{}{"sig": "test"},
+		Accepted: x402.PaymentRequirements{
+			Scheme:            "exact",
+			Network:           "eip155:1",
+			Asset:             "USDC",
+			Amount:            "1000000",
+			PayTo:             "0xtest",
+			MaxTimeoutSeconds: 300,
+			Extra: map[string]interface{}{
+				"resourceUrl": "http://example.com/api",
+			},
+		},
+	}
+	payloadJSON, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(method, "/api", nil)
+	req.Header.Set("PAYMENT-SIGNATURE", base64.StdEncoding.EncodeToString(payloadJSON))
+	req.Host = "example.com"
+	return req
+}
+
+// ============================================================================
+// EchoAdapter Tests
+// ============================================================================
+
+func TestEchoAdapter(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/users/123?x=1", nil)
+	req.Host = "example.com"
+	req.Header.Set("X-Custom-Header", "test-value")
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	adapter := NewEchoAdapter(c)
+
+	if adapter.GetHeader("X-Custom-Header") != "test-value" {
+		t.Error("Expected X-Custom-Header to be 'test-value'")
+	}
+	if adapter.GetMethod() != "POST" {
+		t.Errorf("Expected method POST, got %s", adapter.GetMethod())
+	}
+	if adapter.GetPath() != "/api/users/123" {
+		t.Errorf("Expected path '/api/users/123', got '%s'", adapter.GetPath())
+	}
+	if adapter.GetURL() != "http://example.com/api/users/123" {
+		t.Errorf("Expected URL 'http://example.com/api/users/123', got '%s'", adapter.GetURL())
+	}
+	if adapter.GetAcceptHeader() != "text/html" {
+		t.Errorf("Expected Accept 'text/html', got '%s'", adapter.GetAcceptHeader())
+	}
+	if adapter.GetUserAgent() != "Mozilla/5.0" {
+		t.Errorf("Expected User-Agent 'Mozilla/5.0', got '%s'", adapter.GetUserAgent())
+	}
+}
+
+// ============================================================================
+// Middleware Tests
+// ============================================================================
+
+func TestPaymentMiddleware_CallsNextWhenNoPaymentRequired(t *testing.T) {
+	e := createTestServer(&mockFacilitatorClient{}, func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, WithSyncFacilitatorOnStart(false))
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/public", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if w.Body.String() != "public" {
+		t.Errorf("Expected body 'public', got '%s'", w.Body.String())
+	}
+}
+
+func TestPaymentMiddleware_Returns402JSONForPaymentError(t *testing.T) {
+	nextCalled := false
+	e := createTestServer(&mockFacilitatorClient{}, func(c echo.Context) error {
+		nextCalled = true
+		return nil
+	})
+
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402, got %d", w.Code)
+	}
+	if w.Header().Get("PAYMENT-REQUIRED") == "" {
+		t.Error("Expected PAYMENT-REQUIRED header")
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("Expected JSON content type, got %s", w.Header().Get("Content-Type"))
+	}
+	if nextCalled {
+		t.Error("Protected handler should not run without payment")
+	}
+}
+
+func TestPaymentMiddleware_SettlesAndReturnsResponseForVerifiedPayment(t *testing.T) {
+	settleCalled := false
+	client := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			settleCalled = true
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+		},
+	}
+
+	e := createTestServer(client, func(c echo.Context) error {
+		return c.JSONBlob(http.StatusCreated, []byte(`{"data":"protected-data"}`))
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, newPaidRequest("POST"))
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if !settleCalled {
+		t.Error("Expected settlement to be called")
+	}
+	if w.Header().Get("PAYMENT-RESPONSE") == "" {
+		t.Error("Expected PAYMENT-RESPONSE header")
+	}
+	if w.Body.String() != `{"data":"protected-data"}` {
+		t.Errorf("Expected protected body, got %s", w.Body.String())
+	}
+}
+
+func TestPaymentMiddleware_SkipsSettlementOnHandlerFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler echo.HandlerFunc
+		status  int
+	}{
+		{
+			name: "error status",
+			handler: func(c echo.Context) error {
+				return c.String(http.StatusInternalServerError, "internal error")
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name: "returned error",
+			handler: func(c echo.Context) error {
+				return echo.NewHTTPError(http.StatusBadRequest, "bad input")
+			},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settleCalled := false
+			client := &mockFacilitatorClient{
+				settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+					settleCalled = true
+					return &x402.SettleResponse{Success: true, Transaction: "0xtx"}, nil
+				},
+			}
+
+			e := createTestServer(client, tt.handler)
+
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, newPaidRequest("POST"))
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if settleCalled {
+				t.Error("Settlement should NOT be called when the handler fails")
+			}
+			if w.Header().Get("PAYMENT-RESPONSE") != "" {
+				t.Error("Expected no PAYMENT-RESPONSE header")
+			}
+		})
+	}
+}
+
+func TestPaymentMiddleware_SettlesCommittedResponseDespiteHandlerError(t *testing.T) {
+	settleCalled := false
+	client := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			settleCalled = true
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx"}, nil
+		},
+	}
+
+	// The success status was already sent, so the response was delivered
+	e := createTestServer(client, func(c echo.Context) error {
+		_ = c.String(http.StatusOK, "partial")
+		return errors.New("boom")
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, newPaidRequest("POST"))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if !settleCalled {
+		t.Error("Expected settlement to be called")
+	}
+	if w.Body.String() != "partial" {
+		t.Errorf("Expected body 'partial', got '%s'", w.Body.String())
+	}
+}
+
+func TestPaymentMiddleware_Returns402WhenSettlementFails(t *testing.T) {
+	client := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{Success: false, ErrorReason: "Insufficient funds"}, nil
+		},
+	}
+
+	e := createTestServer(client, func(c echo.Context) error {
+		return c.String(http.StatusOK, "protected-data")
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, newPaidRequest("POST"))
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402, got %d", w.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response["details"] != "Insufficient funds" {
+		t.Errorf("Expected details 'Insufficient funds', got '%v'", response["details"])
+	}
+}
+
+func TestPaymentMiddleware_CustomHandlers(t *testing.T) {
+	var settled *x402.SettleResponse
+	e := createTestServer(&mockFacilitatorClient{}, func(c echo.Context) error {
+		return c.String(http.StatusOK, "protected-data")
+	}, WithSettlementHandler(func(c echo.Context, response *x402.SettleResponse) {
+		settled = response
+		c.Response().Header().Set("X-Settled-Tx", response.Transaction)
+	}))
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, newPaidRequest("POST"))
+
+	if settled == nil || settled.Transaction != "0xtx" {
+		t.Fatalf("Expected settlement handler to be called, got %+v", settled)
+	}
+	if w.Header().Get("X-Settled-Tx") != "0xtx" {
+		t.Error("Expected settlement handler headers on the response")
+	}
+
+	failing := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{Success: false, ErrorReason: "Insufficient funds"}, nil
+		},
+	}
+	e = createTestServer(failing, func(c echo.Context) error {
+		return c.String(http.StatusOK, "protected-data")
+	}, WithErrorHandler(func(c echo.Context, err error) error {
+		return c.String(http.StatusTeapot, err.Error())
+	}))
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, newPaidRequest("POST"))
+
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Expected status 418, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "Insufficient funds") {
+		t.Errorf("Expected settlement error in body, got '%s'", w.Body.String())
+	}
+}
+
+// ============================================================================
+// Builder Tests
+// ============================================================================
+
+func TestX402Payment_CreatesWorkingMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(X402Payment(Config{
+		Routes: x402http.RoutesConfig{
+			"GET /api": {
+				Accepts: x402http.PaymentOptions{
+					{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"},
+				},
+			},
+		},
+		Facilitator: &mockFacilitatorClient{},
+		Schemes: []SchemeConfig{
+			{Network: "eip155:1", Server: &mockSchemeServer{scheme: "exact"}},
+		},
+		Timeout: 5 * time.Second,
+	}))
+	e.GET("/api", func(c echo.Context) error {
+		return c.String(http.StatusOK, "protected-data")
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/api", nil))
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402 without payment, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	e.ServeHTTP(w, newPaidRequest("GET"))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 with payment, got %d. Body: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPaymentMiddleware_StreamsBodyAfterSettlement(t *testing.T) {
+	w := httptest.NewRecorder()
+	streamed := false
+
+	e := createTestServer(&mockFacilitatorClient{}, func(c echo.Context) error {
+		res := c.Response()
+		res.WriteHeader(http.StatusOK)
+		_, _ = res.Write([]byte("chunk-1"))
+		res.Flush()
+
+		// The first chunk must reach the client before the handler returns
+		streamed = w.Body.String() == "chunk-1" && w.Header().Get("PAYMENT-RESPONSE") != ""
+
+		_, err := res.Write([]byte("chunk-2"))
+		return err
+	})
+
+	e.ServeHTTP(w, newPaidRequest("POST"))
+
+	if !streamed {
+		t.Error("Expected first chunk and PAYMENT-RESPONSE header to be flushed before the handler returned")
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if w.Body.String() != "chunk-1chunk-2" {
+		t.Errorf("Expected body 'chunk-1chunk-2', got '%s'", w.Body.String())
+	}
+}
@@ -6,7 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
 	"time"
 
 	x402 "x402-go"
@@ -20,10 +24,12 @@ import (
 // HTTPFacilitatorClient communicates with remote facilitator services over HTTP
 // Implements FacilitatorClient interface (supports both V1 and V2)
 type HTTPFacilitatorClient struct {
-	url          string
-	httpClient   *http.Client
-	authProvider AuthProvider
-	identifier   string
+	url              string
+	httpClient       *http.Client
+	authProvider     AuthProvider
+	identifier       string
+	retryPolicy      *RetryPolicy
+	idempotencyStore IdempotencyStore
 }
 
 // AuthProvider generates authentication headers for facilitator requests
@@ -37,6 +43,7 @@ type AuthHeaders struct {
 	Verify    map[string]string
 	Settle    map[string]string
 	Supported map[string]string
+	Discovery map[string]string // Bazaar catalog listing (see bazaar.CatalogClient)
 }
 
 // FacilitatorConfig configures the HTTP facilitator client
@@ -44,21 +51,109 @@ type FacilitatorConfig struct {
 	// URL is the base URL of the facilitator service
 	URL string
 
-	// HTTPClient is the HTTP client to use (optional)
+	// HTTPClient is the HTTP client to use for every facilitator call (optional). It is used
+	// as is, so Transport and Timeout are ignored; give it a timeout, since a client without
+	// one waits forever on a stalled facilitator.
 	HTTPClient *http.Client
 
+	// Transport carries requests when HTTPClient is not set, e.g. for custom TLS, proxies or
+	// connection pool sizes (optional, defaults to a transport shared by facilitator clients
+	// that keeps DefaultFacilitatorMaxIdleConnsPerHost idle connections per host)
+	Transport http.RoundTripper
+
 	// AuthProvider provides authentication headers (optional)
 	AuthProvider AuthProvider
 
-	// Timeout for requests (optional, defaults to 30s)
+	// Timeout for requests when HTTPClient is not set (optional, defaults to DefaultFacilitatorTimeout)
 	Timeout time.Duration
 
 	// Identifier for this facilitator (optional)
 	Identifier string
+
+	// RetryPolicy enables retries for transient failures (optional, defaults to no retries)
+	RetryPolicy *RetryPolicy
+
+	// IdempotencyStore caches successful settlements by idempotency key so a repeated
+	// Settle for the same payment returns the original result without calling /settle (optional)
+	IdempotencyStore IdempotencyStore
+}
+
+// RetryPolicy configures retries for facilitator requests.
+//
+// Connection errors, 5xx and 429 responses are retried with exponential backoff
+// and jitter, waiting at least as long as any Retry-After header asks. Other 4xx
+// responses are never retried.
+//
+// Settle requests are only retried when the facilitator cannot have acted on
+// them: the request failed before it was fully sent, or the facilitator answered
+// 429 or 503. A settle that times out after being sent, or fails with another
+// 5xx, is returned to the caller since the transaction may already be submitted.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first
+	// (optional, defaults to DefaultRetryMaxAttempts)
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, doubled for each later one
+	// (optional, defaults to DefaultRetryBaseDelay)
+	BaseDelay time.Duration
+
+	// MaxDelay caps the backoff delay (optional, defaults to DefaultRetryMaxDelay)
+	MaxDelay time.Duration
 }
 
-// DefaultFacilitatorURL is the default public facilitator
-const DefaultFacilitatorURL = "https://x402.org/facilitator"
+const (
+	// DefaultFacilitatorURL is the default public facilitator
+	DefaultFacilitatorURL = "https://x402.org/facilitator"
+
+	// DefaultFacilitatorTimeout is the default timeout of a facilitator request
+	DefaultFacilitatorTimeout = 30 * time.Second
+
+	// DefaultFacilitatorMaxIdleConnsPerHost is how many idle connections the default transport
+	// keeps to each facilitator host, so concurrent requests reuse connections
+	DefaultFacilitatorMaxIdleConnsPerHost = 32
+
+	// DefaultRetryMaxAttempts is the default total number of attempts when a RetryPolicy is set
+	DefaultRetryMaxAttempts = 3
+
+	// DefaultRetryBaseDelay is the default delay before the first retry
+	DefaultRetryBaseDelay = 200 * time.Millisecond
+
+	// DefaultRetryMaxDelay is the default cap on the backoff delay
+	DefaultRetryMaxDelay = 5 * time.Second
+)
+
+// defaultFacilitatorTransport is the transport shared by facilitator clients without their own,
+// so clients created per request don't each keep a pool of idle connections
+var defaultFacilitatorTransport = sync.OnceValue(func() http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = DefaultFacilitatorMaxIdleConnsPerHost
+	return transport
+})
+
+// NewFacilitatorHTTPClient returns the HTTP client for talking to the facilitator described by
+// config: its HTTPClient when set, otherwise a client using its Transport (or a shared default
+// transport) with its Timeout (or DefaultFacilitatorTimeout)
+func NewFacilitatorHTTPClient(config *FacilitatorConfig) *http.Client {
+	if config == nil {
+		config = &FacilitatorConfig{}
+	}
+	if config.HTTPClient != nil {
+		return config.HTTPClient
+	}
+
+	transport := config.Transport
+	if transport == nil {
+		transport = defaultFacilitatorTransport()
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultFacilitatorTimeout
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
 
 // NewHTTPFacilitatorClient creates a new HTTP facilitator client
 func NewHTTPFacilitatorClient(config *FacilitatorConfig) *HTTPFacilitatorClient {
@@ -71,27 +166,35 @@ func NewHTTPFacilitatorClient(config *FacilitatorConfig) *HTTPFacilitatorClient
 		url = DefaultFacilitatorURL
 	}
 
-	httpClient := config.HTTPClient
-	if httpClient == nil {
-		timeout := config.Timeout
-		if timeout == 0 {
-			timeout = 30 * time.Second
-		}
-		httpClient = &http.Client{
-			Timeout: timeout,
-		}
-	}
+	httpClient := NewFacilitatorHTTPClient(config)
 
 	identifier := config.Identifier
 	if identifier == "" {
 		identifier = url
 	}
 
+	var retryPolicy *RetryPolicy
+	if config.RetryPolicy != nil {
+		policy := *config.RetryPolicy
+		if policy.MaxAttempts <= 0 {
+			policy.MaxAttempts = DefaultRetryMaxAttempts
+		}
+		if policy.BaseDelay <= 0 {
+			policy.BaseDelay = DefaultRetryBaseDelay
+		}
+		if policy.MaxDelay <= 0 {
+			policy.MaxDelay = DefaultRetryMaxDelay
+		}
+		retryPolicy = &policy
+	}
+
 	return &HTTPFacilitatorClient{
-		url:          url,
-		httpClient:   httpClient,
-		authProvider: config.AuthProvider,
-		identifier:   identifier,
+		url:              url,
+		httpClient:       httpClient,
+		authProvider:     config.AuthProvider,
+		identifier:       identifier,
+		retryPolicy:      retryPolicy,
+		idempotencyStore: config.IdempotencyStore,
 	}
 }
 
@@ -143,7 +246,7 @@ func (c *HTTPFacilitatorClient) GetSupported(ctx context.Context) (x402.Supporte
 	}
 
 	// Make request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(ctx, req, true)
 	if err != nil {
 		return x402.SupportedResponse{}, fmt.Errorf("supported request failed: %w", err)
 	}
@@ -209,7 +312,7 @@ func (c *HTTPFacilitatorClient) verifyHTTP(ctx context.Context, version int, pay
 	}
 
 	// Make request
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(ctx, req, true)
 	if err != nil {
 		return nil, fmt.Errorf("verify request failed: %w", err)
 	}
@@ -251,6 +354,21 @@ func (c *HTTPFacilitatorClient) settleHTTP(ctx context.Context, version int, pay
 		return nil, fmt.Errorf("failed to marshal settle request: %w", err)
 	}
 
+	// Resolve the idempotency key and return a cached settlement if there is one
+	idempotencyKey, ok := IdempotencyKeyFromContext(ctx)
+	if !ok {
+		idempotencyKey = IdempotencyKeyFromJSON(payloadBytes, requirementsBytes)
+	}
+	if c.idempotencyStore != nil {
+		cached, found, err := c.idempotencyStore.Get(ctx, idempotencyKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read idempotency store: %w", err)
+		}
+		if found {
+			return cached, nil
+		}
+	}
+
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "POST", c.url+"/settle", bytes.NewReader(body))
 	if err != nil {
@@ -258,6 +376,7 @@ func (c *HTTPFacilitatorClient) settleHTTP(ctx context.Context, version int, pay
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
 
 	// Add auth headers if available
 	if c.authProvider != nil {
@@ -271,7 +390,8 @@ func (c *HTTPFacilitatorClient) settleHTTP(ctx context.Context, version int, pay
 	}
 
 	// Make request
-	resp, err := c.httpClient.Do(req)
+	// Only retried when the facilitator cannot have submitted the transaction
+	resp, err := c.send(ctx, req, false)
 	if err != nil {
 		return nil, fmt.Errorf("settle request failed: %w", err)
 	}
@@ -289,5 +409,138 @@ func (c *HTTPFacilitatorClient) settleHTTP(ctx context.Context, version int, pay
 		return nil, fmt.Errorf("failed to decode settle response: %w", err)
 	}
 
+	// Cache successful settlements; failures may succeed on a later attempt
+	if c.idempotencyStore != nil && settleResponse.Success {
+		if err := c.idempotencyStore.Put(ctx, idempotencyKey, &settleResponse); err != nil {
+			return nil, fmt.Errorf("failed to write idempotency store: %w", err)
+		}
+	}
+
 	return &settleResponse, nil
 }
+
+// ============================================================================
+// Retry Handling
+// ============================================================================
+
+// send performs req, retrying according to the client's retry policy.
+// Retried attempts use a clone of req with a fresh body.
+//
+// idempotent marks requests that are safe to repeat after the facilitator may have
+// processed them. Non-idempotent requests (settle) are only retried when the request
+// never reached the facilitator or it answered 429 or 503.
+func (c *HTTPFacilitatorClient) send(ctx context.Context, req *http.Request, idempotent bool) (*http.Response, error) {
+	maxAttempts := 1
+	if c.retryPolicy != nil {
+		maxAttempts = c.retryPolicy.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		attemptReq := req
+		if attempt > 1 {
+			attemptReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("failed to reset request body: %w", err)
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		// Track whether the request was fully written, to know if the facilitator may have seen it
+		wroteRequest := false
+		trace := &httptrace.ClientTrace{
+			WroteRequest: func(info httptrace.WroteRequestInfo) {
+				if info.Err == nil {
+					wroteRequest = true
+				}
+			},
+		}
+		attemptReq = attemptReq.WithContext(httptrace.WithClientTrace(attemptReq.Context(), trace))
+
+		resp, err := c.httpClient.Do(attemptReq)
+
+		var retryable bool
+		var retryAfter time.Duration
+		if err != nil {
+			retryable = idempotent || !wroteRequest
+		} else {
+			retryable = isRetryableStatus(resp.StatusCode, idempotent)
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+
+		if !retryable || attempt >= maxAttempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := c.retryPolicy.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+
+		// Give up rather than sleep past the caller's deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, err
+		}
+
+		// Discard this response before retrying
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isRetryableStatus reports whether a response status is worth retrying
+func isRetryableStatus(status int, idempotent bool) bool {
+	switch {
+	case status == http.StatusTooManyRequests, status == http.StatusServiceUnavailable:
+		// The facilitator turned the request away without processing it
+		return true
+	case status >= 500:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+// backoff returns the jittered delay before the given retry (1-based)
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	// Equal jitter: wait between half and the full delay
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
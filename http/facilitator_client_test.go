@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	x402 "x402-go"
 )
@@ -144,6 +147,55 @@ func TestNewHTTPFacilitatorClient(t *testing.T) {
 	}
 }
 
+// countingTransport counts the requests it forwards to http.DefaultTransport
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewFacilitatorHTTPClient(t *testing.T) {
+	// The default client has a timeout and doesn't use http.DefaultClient's transport
+	client := NewFacilitatorHTTPClient(nil)
+	if client.Timeout != DefaultFacilitatorTimeout {
+		t.Errorf("Expected timeout %v, got %v", DefaultFacilitatorTimeout, client.Timeout)
+	}
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok || transport == http.DefaultTransport {
+		t.Fatalf("Expected a dedicated *http.Transport, got %T", client.Transport)
+	}
+	if transport.MaxIdleConnsPerHost != DefaultFacilitatorMaxIdleConnsPerHost {
+		t.Errorf("Expected %d idle connections per host, got %d", DefaultFacilitatorMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	}
+	if other := NewFacilitatorHTTPClient(&FacilitatorConfig{Timeout: time.Second}); other.Transport != client.Transport || other.Timeout != time.Second {
+		t.Errorf("Expected the shared transport with a 1s timeout, got %+v", other)
+	}
+
+	// A custom client is used as is
+	custom := &http.Client{}
+	if NewFacilitatorHTTPClient(&FacilitatorConfig{HTTPClient: custom, Timeout: time.Second}) != custom {
+		t.Error("Expected the configured HTTPClient")
+	}
+
+	// A custom transport carries every facilitator call
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(x402.SupportedResponse{Kinds: []x402.SupportedKind{}})
+	}))
+	defer server.Close()
+
+	counting := &countingTransport{}
+	facilitator := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, Transport: counting})
+	if _, err := facilitator.GetSupported(context.Background()); err != nil {
+		t.Fatalf("GetSupported() failed: %v", err)
+	}
+	if counting.requests.Load() != 1 {
+		t.Errorf("Expected the request to go through the configured transport, got %d", counting.requests.Load())
+	}
+}
+
 func TestHTTPFacilitatorClientVerify(t *testing.T) {
 	ctx := context.Background()
 
@@ -447,6 +499,190 @@ func TestHTTPFacilitatorClientErrorHandling(t *testing.T) {
 	}
 }
 
+func TestHTTPFacilitatorClientRetry(t *testing.T) {
+	ctx := context.Background()
+
+	requirements := x402.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000000",
+		PayTo:   "0xrecipient",
+	}
+	payload := x402.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload:     map[string]interface{}{},
+	}
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	// newServer fails the first `failures` requests with `status`, then succeeds
+	newServer := func(t *testing.T, failures int32, status int, header map[string]string) (*httptest.Server, *atomic.Int32) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("Retried request has unreadable body: %v", err)
+			}
+			if calls.Add(1) <= failures {
+				for k, v := range header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(status)
+				return
+			}
+			switch r.URL.Path {
+			case "/verify":
+				json.NewEncoder(w).Encode(x402.VerifyResponse{IsValid: true, Payer: "0xpayer"})
+			case "/settle":
+				json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xtx"})
+			}
+		}))
+		t.Cleanup(server.Close)
+		return server, &calls
+	}
+
+	policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		settle    bool
+		failures  int32
+		status    int
+		header    map[string]string
+		wantErr   bool
+		wantCalls int32
+	}{
+		{name: "verify retries 5xx", failures: 2, status: http.StatusBadGateway, wantCalls: 3},
+		{name: "verify retries 429", failures: 1, status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "0"}, wantCalls: 2},
+		{name: "verify gives up after max attempts", failures: 5, status: http.StatusInternalServerError, wantErr: true, wantCalls: 3},
+		{name: "verify does not retry 4xx", failures: 5, status: http.StatusBadRequest, wantErr: true, wantCalls: 1},
+		{name: "settle retries 503", settle: true, failures: 1, status: http.StatusServiceUnavailable, wantCalls: 2},
+		{name: "settle retries 429", settle: true, failures: 1, status: http.StatusTooManyRequests, wantCalls: 2},
+		{name: "settle does not retry 500", settle: true, failures: 1, status: http.StatusInternalServerError, wantErr: true, wantCalls: 1},
+		{name: "settle does not retry 4xx", settle: true, failures: 1, status: http.StatusBadRequest, wantErr: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newServer(t, tt.failures, tt.status, tt.header)
+			client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, RetryPolicy: policy})
+
+			var err error
+			if tt.settle {
+				_, err = client.Settle(ctx, payloadBytes, requirementsBytes)
+			} else {
+				_, err = client.Verify(ctx, payloadBytes, requirementsBytes)
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error=%v, got %v", tt.wantErr, err)
+			}
+			if n := calls.Load(); n != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, n)
+			}
+		})
+	}
+
+	t.Run("no retries without policy", func(t *testing.T) {
+		server, calls := newServer(t, 1, http.StatusServiceUnavailable, nil)
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL})
+
+		if _, err := client.Verify(ctx, payloadBytes, requirementsBytes); err == nil {
+			t.Error("Expected error")
+		}
+		if n := calls.Load(); n != 1 {
+			t.Errorf("Expected 1 call, got %d", n)
+		}
+	})
+
+	t.Run("settle retries connection failures before the request is sent", func(t *testing.T) {
+		server, calls := newServer(t, 0, 0, nil)
+
+		var dials atomic.Int32
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				if dials.Add(1) == 1 {
+					return nil, fmt.Errorf("connection refused")
+				}
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		}
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{
+			URL:         server.URL,
+			HTTPClient:  &http.Client{Transport: transport},
+			RetryPolicy: policy,
+		})
+
+		if _, err := client.Settle(ctx, payloadBytes, requirementsBytes); err != nil {
+			t.Fatalf("Expected settle to succeed after retry, got %v", err)
+		}
+		if n := calls.Load(); n != 1 {
+			t.Errorf("Expected 1 call to reach the server, got %d", n)
+		}
+	})
+
+	t.Run("settle does not retry after the request is sent", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			// Drop the connection after the facilitator has the request
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		}))
+		defer server.Close()
+
+		client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, RetryPolicy: policy})
+
+		if _, err := client.Settle(ctx, payloadBytes, requirementsBytes); err == nil {
+			t.Error("Expected settle error")
+		}
+		if n := calls.Load(); n != 1 {
+			t.Errorf("Expected settle not to be retried, got %d calls", n)
+		}
+
+		calls.Store(0)
+		if _, err := client.Verify(ctx, payloadBytes, requirementsBytes); err == nil {
+			t.Error("Expected verify error")
+		}
+		if n := calls.Load(); n != 3 {
+			t.Errorf("Expected verify to be retried, got %d calls", n)
+		}
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter(""); d != 0 {
+		t.Errorf("Expected 0 for empty header, got %v", d)
+	}
+	if d := parseRetryAfter("2"); d != 2*time.Second {
+		t.Errorf("Expected 2s, got %v", d)
+	}
+	if d := parseRetryAfter("invalid"); d != 0 {
+		t.Errorf("Expected 0 for invalid header, got %v", d)
+	}
+	date := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(date); d <= 0 || d > 10*time.Second {
+		t.Errorf("Expected delay up to 10s for HTTP date, got %v", d)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+
+	for attempt, max := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: 300 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			d := policy.backoff(attempt)
+			if d < max/2 || d > max {
+				t.Errorf("backoff(%d) = %v, want between %v and %v", attempt, d, max/2, max)
+			}
+		}
+	}
+}
+
 func TestStaticAuthProvider(t *testing.T) {
 	provider := NewStaticAuthProvider("api-key-123")
 
@@ -1,6 +1,7 @@
 package gin
 
 import (
+	"html/template"
 	"time"
 
 	x402 "x402-go"
@@ -28,6 +29,9 @@ type Config struct {
 	// PaywallConfig for browser-based payment UI (optional)
 	PaywallConfig *x402http.PaywallConfig
 
+	// PaywallTemplate renders the browser paywall instead of the built-in page (optional)
+	PaywallTemplate *template.Template
+
 	// SyncFacilitatorOnStart fetches supported kinds from facilitators on startup
 	// Default: true
 	SyncFacilitatorOnStart bool
@@ -41,6 +45,10 @@ type Config struct {
 
 	// SettlementHandler called after successful settlement (optional)
 	SettlementHandler func(*gin.Context, *x402.SettleResponse)
+
+	// PaymentRequiredBody sets whether 402 responses to API clients carry the PaymentRequired
+	// structure as their JSON body, for routes that don't set it themselves (optional)
+	PaymentRequiredBody x402http.PaymentRequiredBody
 }
 
 // SchemeConfig configures a payment scheme for a network.
@@ -118,12 +126,18 @@ func X402Payment(config Config) gin.HandlerFunc {
 	if config.PaywallConfig != nil {
 		opts = append(opts, WithPaywallConfig(config.PaywallConfig))
 	}
+	if config.PaywallTemplate != nil {
+		opts = append(opts, WithPaywallTemplate(config.PaywallTemplate))
+	}
 	if config.ErrorHandler != nil {
 		opts = append(opts, WithErrorHandler(config.ErrorHandler))
 	}
 	if config.SettlementHandler != nil {
 		opts = append(opts, WithSettlementHandler(config.SettlementHandler))
 	}
+	if config.PaymentRequiredBody != "" {
+		opts = append(opts, WithPaymentRequiredBody(config.PaymentRequiredBody))
+	}
 
 	// Delegate to PaymentMiddlewareFromConfig (reuse all logic)
 	return PaymentMiddlewareFromConfig(config.Routes, opts...)
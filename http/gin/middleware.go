@@ -1,11 +1,10 @@
 package gin
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	"html/template"
 	"net/http"
-	"sync"
 	"time"
 
 	x402 "x402-go"
@@ -66,6 +65,16 @@ func (a *GinAdapter) GetUserAgent() string {
 	return a.ctx.GetHeader("User-Agent")
 }
 
+// GetQueryParams gets the URL query parameters
+func (a *GinAdapter) GetQueryParams() map[string][]string {
+	return a.ctx.Request.URL.Query()
+}
+
+// GetBody gets the request body, leaving it readable by the next handler
+func (a *GinAdapter) GetBody() ([]byte, error) {
+	return x402http.ReadRequestBody(a.ctx.Request)
+}
+
 // ============================================================================
 // Middleware Configuration
 // ============================================================================
@@ -84,6 +93,9 @@ type MiddlewareConfig struct {
 	// Paywall configuration
 	PaywallConfig *x402http.PaywallConfig
 
+	// Custom paywall page template (overrides PaywallConfig.Template)
+	PaywallTemplate *template.Template
+
 	// Sync with facilitator on start
 	SyncFacilitatorOnStart bool
 
@@ -95,6 +107,12 @@ type MiddlewareConfig struct {
 
 	// Context timeout for payment operations
 	Timeout time.Duration
+
+	// Default for routes that don't set RouteConfig.PaymentRequiredBody
+	PaymentRequiredBody x402http.PaymentRequiredBody
+
+	// Logger for the server created by PaymentMiddlewareFromConfig
+	Logger x402.Logger
 }
 
 // SchemeRegistration registers a scheme with the server
@@ -130,6 +148,14 @@ func WithPaywallConfig(config *x402http.PaywallConfig) MiddlewareOption {
 	}
 }
 
+// WithPaywallTemplate renders the browser paywall with tmpl, which is executed with a
+// *x402http.PaywallTemplateData. The built-in page is served if it fails.
+func WithPaywallTemplate(tmpl *template.Template) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.PaywallTemplate = tmpl
+	}
+}
+
 // WithSyncFacilitatorOnStart sets whether to sync with facilitator on startup
 func WithSyncFacilitatorOnStart(sync bool) MiddlewareOption {
 	return func(c *MiddlewareConfig) {
@@ -158,6 +184,23 @@ func WithTimeout(timeout time.Duration) MiddlewareOption {
 	}
 }
 
+// WithPaymentRequiredBody sets whether 402 responses to API clients carry the PaymentRequired
+// structure as their JSON body as well as in the PAYMENT-REQUIRED header, for routes that
+// don't set RouteConfig.PaymentRequiredBody themselves
+func WithPaymentRequiredBody(mode x402http.PaymentRequiredBody) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.PaymentRequiredBody = mode
+	}
+}
+
+// WithLogger sets the logger used by PaymentMiddlewareFromConfig.
+// PaymentMiddleware uses the logger of the server it is given.
+func WithLogger(logger x402.Logger) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.Logger = logger
+	}
+}
+
 // ============================================================================
 // Payment Middleware
 // ============================================================================
@@ -176,7 +219,7 @@ func PaymentMiddleware(routes x402http.RoutesConfig, server *x402.X402ResourceSe
 	}
 
 	// Wrap the resource server with HTTP functionality
-	httpServer := x402http.Wrappedx402HTTPResourceServer(routes, server)
+	httpServer := x402http.Wrappedx402HTTPResourceServer(routes.WithPaymentRequiredBody(config.PaymentRequiredBody), server)
 
 	httpServer.RegisterExtension(bazaar.BazaarResourceServerExtension)
 
@@ -185,7 +228,7 @@ func PaymentMiddleware(routes x402http.RoutesConfig, server *x402.X402ResourceSe
 		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 		defer cancel()
 		if err := httpServer.Initialize(ctx); err != nil {
-			fmt.Printf("Warning: failed to initialize x402 server: %v\n", err)
+			httpServer.Logger().Warn("failed to initialize x402 server", "error", err)
 		}
 	}
 
@@ -213,8 +256,11 @@ func PaymentMiddlewareFromConfig(routes x402http.RoutesConfig, opts ...Middlewar
 	for _, client := range config.FacilitatorClients {
 		serverOpts = append(serverOpts, x402.WithFacilitatorClient(client))
 	}
+	if config.Logger != nil {
+		serverOpts = append(serverOpts, x402.WithServerLogger(config.Logger))
+	}
 
-	httpServer := x402http.Newx402HTTPResourceServer(config.Routes, serverOpts...)
+	httpServer := x402http.Newx402HTTPResourceServer(config.Routes.WithPaymentRequiredBody(config.PaymentRequiredBody), serverOpts...)
 
 	httpServer.RegisterExtension(bazaar.BazaarResourceServerExtension)
 
@@ -228,7 +274,7 @@ func PaymentMiddlewareFromConfig(routes x402http.RoutesConfig, opts ...Middlewar
 		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 		defer cancel()
 		if err := httpServer.Initialize(ctx); err != nil {
-			fmt.Printf("Warning: failed to initialize x402 server: %v\n", err)
+			httpServer.Logger().Warn("failed to initialize x402 server", "error", err)
 		}
 	}
 
@@ -238,6 +284,8 @@ func PaymentMiddlewareFromConfig(routes x402http.RoutesConfig, opts ...Middlewar
 
 // createMiddlewareHandler creates the actual Gin handler function.
 func createMiddlewareHandler(server *x402http.HTTPServer, config *MiddlewareConfig) gin.HandlerFunc {
+	paywallConfig := config.PaywallConfig.WithTemplate(config.PaywallTemplate)
+
 	return func(c *gin.Context) {
 		// Create adapter and request context
 		adapter := NewGinAdapter(c)
@@ -257,12 +305,9 @@ func createMiddlewareHandler(server *x402http.HTTPServer, config *MiddlewareConf
 		ctx, cancel := context.WithTimeout(c.Request.Context(), config.Timeout)
 		defer cancel()
 
-		result := server.ProcessHTTPRequest(ctx, reqCtx, config.PaywallConfig)
+		result := server.ProcessHTTPRequest(ctx, reqCtx, paywallConfig)
 
-		// Debug logging for request processing
-		fmt.Printf("🔍 [GIN REQUEST DEBUG] Processed HTTP request\n")
-		fmt.Printf("   Result Type: %v\n", result.Type)
-		fmt.Printf("   Path: %s, Method: %s\n", reqCtx.Path, reqCtx.Method)
+		server.Logger().Debug("processed HTTP request", "path", reqCtx.Path, "method", reqCtx.Method, "result", result.Type)
 
 		// Handle result
 		switch result.Type {
@@ -275,7 +320,17 @@ func createMiddlewareHandler(server *x402http.HTTPServer, config *MiddlewareConf
 			handlePaymentError(c, result.Response, config)
 
 		case x402http.ResultPaymentVerified:
-			// Payment verified, continue with settlement handling
+			if result.SettlesBeforeResponse() {
+				// Settle first; the handler only runs once the payment is confirmed
+				if settle(c, server, ctx, result, config) {
+					c.Next()
+				} else {
+					c.Abort()
+				}
+				return
+			}
+
+			// Payment verified, settle when the handler commits its status
 			handlePaymentVerified(c, server, ctx, result, config)
 		}
 	}
@@ -302,52 +357,41 @@ func handlePaymentError(c *gin.Context, response *x402http.HTTPResponseInstructi
 	c.Abort()
 }
 
-// handlePaymentVerified handles verified payments with settlement
+// handlePaymentVerified runs the protected handlers behind a settlingWriter
 func handlePaymentVerified(c *gin.Context, server *x402http.HTTPServer, ctx context.Context, result x402http.HTTPProcessResult, config *MiddlewareConfig) {
-	// Capture response for settlement
-	writer := &responseCapture{
-		ResponseWriter: c.Writer,
-		body:           &bytes.Buffer{},
-		statusCode:     http.StatusOK,
-	}
+	original := c.Writer
+	var writer *settlingWriter
+	writer = newSettlingWriter(original, func(http.ResponseWriter) bool {
+		// Settlement failure responses are written to the real writer
+		c.Writer = original
+		defer func() { c.Writer = writer }()
+		return settle(c, server, ctx, result, config)
+	})
 	c.Writer = writer
 
 	// Continue to protected handler
 	c.Next()
 
-	// Check if aborted
-	if c.IsAborted() {
-		return
+	// Handlers that write nothing still get an implicit 200, which is settled,
+	// unless the chain was aborted without a response
+	if !c.IsAborted() {
+		writer.WriteHeaderNow()
 	}
 
 	// Restore original writer
-	c.Writer = writer.ResponseWriter
-
-	// Don't settle if response failed
-	if writer.statusCode >= 400 {
-		// Write captured response
-		c.Writer.WriteHeader(writer.statusCode)
-		_, _ = c.Writer.Write(writer.body.Bytes())
-		return
-	}
-
-	// Debug logging for settlement
-	fmt.Printf("🔍 [GIN SETTLEMENT DEBUG] Starting settlement process\n")
-	fmt.Printf("   StatusCode: %d\n", writer.statusCode)
-	fmt.Printf("   Context Error: %v\n", ctx.Err())
-	fmt.Printf("   PaymentPayload: %+v\n", result.PaymentPayload)
-	fmt.Printf("   PaymentRequirements: %+v\n", result.PaymentRequirements)
+	c.Writer = original
+}
 
-	// Process settlement
+// settle processes settlement and either injects the settlement headers (returning true)
+// or writes the settlement failure response (returning false)
+func settle(c *gin.Context, server *x402http.HTTPServer, ctx context.Context, result x402http.HTTPProcessResult, config *MiddlewareConfig) bool {
 	settleResult := server.ProcessSettlement(
 		ctx,
 		*result.PaymentPayload,
 		*result.PaymentRequirements,
 	)
 
-	fmt.Printf("🔍 [GIN SETTLEMENT DEBUG] Settlement completed\n")
-	fmt.Printf("   Success: %v\n", settleResult.Success)
-	fmt.Printf("   ErrorReason: %v\n", settleResult.ErrorReason)
+	server.Logger().Debug("settlement completed", "success", settleResult.Success, "reason", settleResult.ErrorReason, "transaction", settleResult.Transaction)
 
 	// Check settlement success
 	if !settleResult.Success {
@@ -355,6 +399,12 @@ func handlePaymentVerified(c *gin.Context, server *x402http.HTTPServer, ctx cont
 		if errorReason == "" {
 			errorReason = "Settlement failed"
 		}
+
+		// Drop headers the protected handler set for the response that is no longer sent
+		for key := range c.Writer.Header() {
+			c.Writer.Header().Del(key)
+		}
+
 		if config.ErrorHandler != nil {
 			config.ErrorHandler(c, fmt.Errorf("settlement failed: %s", errorReason))
 		} else {
@@ -363,7 +413,7 @@ func handlePaymentVerified(c *gin.Context, server *x402http.HTTPServer, ctx cont
 				"details": errorReason,
 			})
 		}
-		return
+		return false
 	}
 
 	// Add settlement headers
@@ -382,52 +432,57 @@ func handlePaymentVerified(c *gin.Context, server *x402http.HTTPServer, ctx cont
 		config.SettlementHandler(c, settleResponse)
 	}
 
-	// Write captured response
-	c.Writer.WriteHeader(writer.statusCode)
-	_, _ = c.Writer.Write(writer.body.Bytes())
+	return true
 }
 
 // ============================================================================
-// Response Capture
+// Settling Writer
 // ============================================================================
 
-// responseCapture captures the response for settlement processing
-type responseCapture struct {
+// settlingWriter exposes a x402http.SettlingWriter as a gin.ResponseWriter.
+// Writes go through the settling writer; status and size queries are
+// answered by the underlying gin writer.
+type settlingWriter struct {
 	gin.ResponseWriter
-	body       *bytes.Buffer
-	statusCode int
-	written    bool
-	mu         sync.Mutex
+	settling *x402http.SettlingWriter
 }
 
-// WriteHeader captures the status code
-func (w *responseCapture) WriteHeader(code int) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
+// newSettlingWriter wraps w so that settle runs before the first success status
+func newSettlingWriter(w gin.ResponseWriter, settle func(http.ResponseWriter) bool) *settlingWriter {
+	return &settlingWriter{
+		ResponseWriter: w,
+		settling:       x402http.NewSettlingWriter(w, settle),
+	}
+}
 
-	w.writeHeaderLocked(code)
+// WriteHeader settles the payment before committing a success status
+func (w *settlingWriter) WriteHeader(code int) {
+	w.settling.WriteHeader(code)
 }
 
-// writeHeaderLocked sets the status code (must be called with lock held)
-func (w *responseCapture) writeHeaderLocked(code int) {
-	if !w.written {
-		w.statusCode = code
-		w.written = true
+// WriteHeaderNow commits an implicit 200 if no status was set, then sends the headers
+func (w *settlingWriter) WriteHeaderNow() {
+	if w.settling.Commit() {
+		w.ResponseWriter.WriteHeaderNow()
 	}
 }
 
-// Write captures the response body
-func (w *responseCapture) Write(data []byte) (int, error) {
-	w.mu.Lock()
-	defer w.mu.Unlock()
+// Write commits an implicit 200 on first use, then passes the body through
+func (w *settlingWriter) Write(data []byte) (int, error) {
+	return w.settling.Write(data)
+}
 
-	if !w.written {
-		w.writeHeaderLocked(http.StatusOK)
-	}
-	return w.body.Write(data)
+// WriteString commits an implicit 200 on first use, then passes the string through
+func (w *settlingWriter) WriteString(s string) (int, error) {
+	return w.settling.WriteString(s)
+}
+
+// Flush implements http.Flusher, committing the status first if needed
+func (w *settlingWriter) Flush() {
+	w.settling.Flush()
 }
 
-// WriteString captures string responses
-func (w *responseCapture) WriteString(s string) (int, error) {
-	return w.Write([]byte(s))
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *settlingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
 }
@@ -636,6 +636,73 @@ func TestPaymentMiddleware_Returns402WhenSettlementFails(t *testing.T) {
 	}
 }
 
+func TestPaymentMiddleware_SettlementBeforeResponseFailureSkipsHandler(t *testing.T) {
+	mockClient := &mockFacilitatorClient{
+		verifyFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{
+				Success:     false,
+				ErrorReason: "Insufficient funds",
+			}, nil
+		},
+		supportedFunc: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds: []x402.SupportedKind{
+					{X402Version: 2, Scheme: "exact", Network: "eip155:1"},
+				},
+				Extensions: []string{},
+				Signers:    make(map[string][]string),
+			}, nil
+		},
+	}
+
+	mockServer := &mockSchemeServer{scheme: "exact"}
+
+	routes := x402http.RoutesConfig{
+		"POST /api": x402http.RouteConfig{
+			Accepts: x402http.PaymentOptions{
+				{
+					Scheme:  "exact",
+					PayTo:   "0xtest",
+					Price:   "$1.00",
+					Network: "eip155:1",
+				},
+			},
+			SettlementTiming: x402http.SettlementTimingBeforeResponse,
+		},
+	}
+
+	router := createTestRouter()
+	router.Use(PaymentMiddlewareFromConfig(routes,
+		WithFacilitatorClient(mockClient),
+		WithScheme("eip155:1", mockServer),
+		WithSyncFacilitatorOnStart(true),
+		WithTimeout(5*time.Second),
+	))
+
+	handlerCalled := false
+	router.POST("/api", func(c *gin.Context) {
+		handlerCalled = true
+		c.JSON(http.StatusOK, gin.H{"data": "protected-data"})
+	})
+
+	req := httptest.NewRequest("POST", "/api", nil)
+	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader("0xtest"))
+	req.Host = "example.com"
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if handlerCalled {
+		t.Error("Handler should NOT run when settlement before the response fails")
+	}
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402, got %d", w.Code)
+	}
+}
+
 func TestPaymentMiddleware_CustomErrorHandler(t *testing.T) {
 	customHandlerCalled := false
 
@@ -1034,84 +1101,178 @@ func TestX402Payment_RegistersMultipleSchemes(t *testing.T) {
 }
 
 // ============================================================================
-// responseCapture Tests
+// Settling Writer Tests
 // ============================================================================
 
-func TestResponseCapture_CapturesStatusCode(t *testing.T) {
-	capture := &responseCapture{
-		ResponseWriter: &mockGinResponseWriter{
-			ResponseRecorder: httptest.NewRecorder(),
-		},
-		body:       &bytes.Buffer{},
-		statusCode: http.StatusOK,
-	}
+func TestSettlingWriter_SettlesBeforeSuccessStatus(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	settleCalls := 0
+	writer := newSettlingWriter(&mockGinResponseWriter{ResponseRecorder: recorder}, func(w http.ResponseWriter) bool {
+		settleCalls++
+		w.Header().Set("PAYMENT-RESPONSE", "settled")
+		return true
+	})
 
-	capture.WriteHeader(http.StatusCreated)
+	writer.WriteHeader(http.StatusCreated)
+	writer.WriteHeader(http.StatusAccepted) // Should be ignored
 
-	if capture.statusCode != http.StatusCreated {
-		t.Errorf("Expected status 201, got %d", capture.statusCode)
+	if settleCalls != 1 {
+		t.Errorf("Expected settle to be called once, got %d", settleCalls)
+	}
+	if recorder.Code != http.StatusCreated {
+		t.Errorf("Expected status 201 (first call), got %d", recorder.Code)
+	}
+	if recorder.Header().Get("PAYMENT-RESPONSE") != "settled" {
+		t.Error("Expected PAYMENT-RESPONSE header to be set before the status")
 	}
 }
 
-func TestResponseCapture_CapturesBody(t *testing.T) {
-	capture := &responseCapture{
-		ResponseWriter: &mockGinResponseWriter{
-			ResponseRecorder: httptest.NewRecorder(),
-		},
-		body:       &bytes.Buffer{},
-		statusCode: http.StatusOK,
-	}
+func TestSettlingWriter_WriteCommitsImplicitOK(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	settleCalled := false
+	writer := newSettlingWriter(&mockGinResponseWriter{ResponseRecorder: recorder}, func(w http.ResponseWriter) bool {
+		settleCalled = true
+		return true
+	})
 
-	data := []byte(`{"message":"test"}`)
-	n, err := capture.Write(data)
+	n, err := writer.WriteString("hello world")
 
 	if err != nil {
-		t.Fatalf("Write failed: %v", err)
+		t.Fatalf("WriteString failed: %v", err)
 	}
-	if n != len(data) {
-		t.Errorf("Expected to write %d bytes, wrote %d", len(data), n)
+	if n != 11 {
+		t.Errorf("Expected to write 11 bytes, wrote %d", n)
 	}
-	if capture.body.String() != `{"message":"test"}` {
-		t.Errorf("Expected body '%s', got '%s'", `{"message":"test"}`, capture.body.String())
+	if !settleCalled {
+		t.Error("Expected settle to be called before the first write")
+	}
+	if recorder.Body.String() != "hello world" {
+		t.Errorf("Expected body 'hello world', got '%s'", recorder.Body.String())
 	}
 }
 
-func TestResponseCapture_WriteString(t *testing.T) {
-	capture := &responseCapture{
-		ResponseWriter: &mockGinResponseWriter{
-			ResponseRecorder: httptest.NewRecorder(),
-		},
-		body:       &bytes.Buffer{},
-		statusCode: http.StatusOK,
+func TestSettlingWriter_SkipsSettlementForErrorStatus(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	settleCalled := false
+	writer := newSettlingWriter(&mockGinResponseWriter{ResponseRecorder: recorder}, func(w http.ResponseWriter) bool {
+		settleCalled = true
+		return true
+	})
+
+	writer.WriteHeader(http.StatusInternalServerError)
+	_, _ = writer.Write([]byte("error"))
+
+	if settleCalled {
+		t.Error("Settlement should NOT be called for error statuses")
+	}
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", recorder.Code)
+	}
+	if recorder.Body.String() != "error" {
+		t.Errorf("Expected body 'error', got '%s'", recorder.Body.String())
 	}
+}
+
+func TestSettlingWriter_DiscardsBodyWhenSettlementFails(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := newSettlingWriter(&mockGinResponseWriter{ResponseRecorder: recorder}, func(w http.ResponseWriter) bool {
+		w.WriteHeader(http.StatusPaymentRequired)
+		_, _ = w.Write([]byte("settlement failed"))
+		return false
+	})
 
-	n, err := capture.WriteString("hello world")
+	data := []byte("protected-data")
+	n, err := writer.Write(data)
 
 	if err != nil {
-		t.Fatalf("WriteString failed: %v", err)
+		t.Fatalf("Write failed: %v", err)
 	}
-	if n != 11 {
-		t.Errorf("Expected to write 11 bytes, wrote %d", n)
+	if n != len(data) {
+		t.Errorf("Expected to report %d bytes, got %d", len(data), n)
+	}
+	if recorder.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402, got %d", recorder.Code)
 	}
-	if capture.body.String() != "hello world" {
-		t.Errorf("Expected body 'hello world', got '%s'", capture.body.String())
+	if recorder.Body.String() != "settlement failed" {
+		t.Errorf("Expected body 'settlement failed', got '%s'", recorder.Body.String())
 	}
 }
 
-func TestResponseCapture_WriteHeaderOnlyOnce(t *testing.T) {
-	capture := &responseCapture{
-		ResponseWriter: &mockGinResponseWriter{
-			ResponseRecorder: httptest.NewRecorder(),
+func TestPaymentMiddleware_StreamsBodyAfterSettlement(t *testing.T) {
+	mockClient := &mockFacilitatorClient{
+		verifyFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{
+				Success:     true,
+				Transaction: "0xtx",
+				Network:     "eip155:1",
+				Payer:       "0xpayer",
+			}, nil
+		},
+		supportedFunc: func(ctx context.Context) (x402.SupportedResponse, error) {
+			return x402.SupportedResponse{
+				Kinds: []x402.SupportedKind{
+					{X402Version: 2, Scheme: "exact", Network: "eip155:1"},
+				},
+				Extensions: []string{},
+				Signers:    make(map[string][]string),
+			}, nil
 		},
-		body:       &bytes.Buffer{},
-		statusCode: http.StatusOK,
 	}
 
-	capture.WriteHeader(http.StatusCreated)
-	capture.WriteHeader(http.StatusAccepted) // Should be ignored
+	mockServer := &mockSchemeServer{scheme: "exact"}
 
-	if capture.statusCode != http.StatusCreated {
-		t.Errorf("Expected status 201 (first call), got %d", capture.statusCode)
+	routes := x402http.RoutesConfig{
+		"GET /stream": x402http.RouteConfig{
+			Accepts: x402http.PaymentOptions{
+				{
+					Scheme:  "exact",
+					PayTo:   "0xtest",
+					Price:   "$1.00",
+					Network: "eip155:1",
+				},
+			},
+		},
+	}
+
+	router := createTestRouter()
+	router.Use(PaymentMiddlewareFromConfig(routes,
+		WithFacilitatorClient(mockClient),
+		WithScheme("eip155:1", mockServer),
+		WithSyncFacilitatorOnStart(true),
+		WithTimeout(5*time.Second),
+	))
+
+	w := httptest.NewRecorder()
+	streamed := false
+
+	router.GET("/stream", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("chunk-1")
+		c.Writer.Flush()
+
+		// The first chunk must reach the client before the handler returns
+		streamed = w.Body.String() == "chunk-1" && w.Header().Get("PAYMENT-RESPONSE") != ""
+
+		_, _ = c.Writer.WriteString("chunk-2")
+	})
+
+	req := httptest.NewRequest("GET", "/stream", nil)
+	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader("0xtest"))
+	req.Host = "example.com"
+
+	router.ServeHTTP(w, req)
+
+	if !streamed {
+		t.Error("Expected first chunk and PAYMENT-RESPONSE header to be flushed before the handler returned")
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if w.Body.String() != "chunk-1chunk-2" {
+		t.Errorf("Expected body 'chunk-1chunk-2', got '%s'", w.Body.String())
 	}
 }
 
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	x402 "x402-go"
+	"x402-go/types"
+)
+
+// ============================================================================
+// Settlement Idempotency
+// ============================================================================
+
+// IdempotencyKeyHeader is the header carrying the settlement idempotency key.
+// Facilitators can use it to return the original result when the same settlement
+// is submitted again, e.g. after a client-side timeout.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyStore caches settlement results by idempotency key.
+// Plug one into FacilitatorConfig to skip duplicate /settle calls for a payment
+// that was already settled through this client.
+type IdempotencyStore interface {
+	// Get returns the cached settlement for key, if any
+	Get(ctx context.Context, key string) (*x402.SettleResponse, bool, error)
+
+	// Put caches a successful settlement under key
+	Put(ctx context.Context, key string, response *x402.SettleResponse) error
+}
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey returns a context that makes HTTPFacilitatorClient.Settle send key
+// as the Idempotency-Key header instead of deriving one from the request
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key set with WithIdempotencyKey
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key, ok && key != ""
+}
+
+// IdempotencyKey derives a stable settlement idempotency key from the signed
+// payment and a hash of the requirements. The same payment for the same
+// requirements always produces the same key.
+func IdempotencyKey(payload types.PaymentPayload, requirements types.PaymentRequirements) string {
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+	return IdempotencyKeyFromJSON(payloadBytes, requirementsBytes)
+}
+
+// IdempotencyKeyFromJSON derives the idempotency key from a serialized payload and requirements
+// of either protocol version, as a facilitator receives them in a /settle body. Both are decoded
+// and re-encoded so key order and whitespace don't affect the result.
+//
+// The key covers the whole scheme payload, signature and payer included, not just the nonce:
+// a nonce is public once settled, and another payer's authorization reusing it must not map to
+// the cached result.
+func IdempotencyKeyFromJSON(payloadBytes, requirementsBytes []byte) string {
+	var payload struct {
+		Payload json.RawMessage `json:"payload"`
+	}
+	_ = json.Unmarshal(payloadBytes, &payload)
+	payloadHash := sha256.Sum256(canonicalJSON(payload.Payload))
+	requirementsHash := sha256.Sum256(canonicalJSON(requirementsBytes))
+
+	hash := sha256.New()
+	hash.Write(payloadHash[:])
+	hash.Write([]byte{0})
+	hash.Write(requirementsHash[:])
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// canonicalJSON re-encodes data with sorted keys and no whitespace, keeping numbers exact
+func canonicalJSON(data []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return data
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return data
+	}
+	return canonical
+}
+
+// ============================================================================
+// In-Memory Idempotency Store
+// ============================================================================
+
+// InMemoryIdempotencyStore is an IdempotencyStore backed by a map.
+// Entries are never evicted, so it suits tests and single-process deployments;
+// use a shared store such as Redis when running multiple instances.
+type InMemoryIdempotencyStore struct {
+	mu      sync.RWMutex
+	results map[string]*x402.SettleResponse
+}
+
+// NewInMemoryIdempotencyStore creates an empty in-memory idempotency store
+func NewInMemoryIdempotencyStore() *InMemoryIdempotencyStore {
+	return &InMemoryIdempotencyStore{
+		results: make(map[string]*x402.SettleResponse),
+	}
+}
+
+// Get returns the cached settlement for key, if any
+func (s *InMemoryIdempotencyStore) Get(ctx context.Context, key string) (*x402.SettleResponse, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	response, ok := s.results[key]
+	return response, ok, nil
+}
+
+// Put caches a settlement under key
+func (s *InMemoryIdempotencyStore) Put(ctx context.Context, key string, response *x402.SettleResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.results[key] = response
+	return nil
+}
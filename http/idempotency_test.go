@@ -0,0 +1,186 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	x402 "x402-go"
+	"x402-go/types"
+)
+
+func idempotencyTestPayment(nonce string) (types.PaymentPayload, types.PaymentRequirements) {
+	return idempotencyTestPaymentFrom("0xpayer", nonce)
+}
+
+func idempotencyTestPaymentFrom(from string, nonce string) (types.PaymentPayload, types.PaymentRequirements) {
+	requirements := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000000",
+		PayTo:   "0xtest",
+	}
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload: map[string]interface{}{
+			"signature": "0xsig",
+			"authorization": map[string]interface{}{
+				"from":  from,
+				"nonce": nonce,
+			},
+		},
+	}
+	return payload, requirements
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	payload, requirements := idempotencyTestPayment("0x01")
+	key := IdempotencyKey(payload, requirements)
+
+	if len(key) != 64 {
+		t.Errorf("Expected 64-char hex key, got %q", key)
+	}
+	if IdempotencyKey(payload, requirements) != key {
+		t.Error("Expected key to be stable")
+	}
+
+	otherPayload, _ := idempotencyTestPayment("0x02")
+	if IdempotencyKey(otherPayload, requirements) == key {
+		t.Error("Expected different nonce to change the key")
+	}
+
+	otherRequirements := requirements
+	otherRequirements.Amount = "2000000"
+	if IdempotencyKey(payload, otherRequirements) == key {
+		t.Error("Expected different requirements to change the key")
+	}
+
+	// Field order and whitespace in serialized requirements don't matter
+	payloadBytes, _ := json.Marshal(payload)
+	reordered := []byte(`{ "payTo":"0xtest","amount":"1000000","asset":"USDC","network":"eip155:1","scheme":"exact","maxTimeoutSeconds":0 }`)
+	if IdempotencyKeyFromJSON(payloadBytes, reordered) != key {
+		t.Error("Expected key derived from reordered JSON to match")
+	}
+
+	// Payloads without an authorization (e.g. signed SVM transactions) are keyed the same way
+	svmPayload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{"transaction": "tx1"}}
+	svmOther := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{"transaction": "tx2"}}
+	if IdempotencyKey(svmPayload, requirements) == IdempotencyKey(svmOther, requirements) {
+		t.Error("Expected different transactions to produce different keys")
+	}
+}
+
+func TestIdempotencyKeyCoversPayer(t *testing.T) {
+	victim, requirements := idempotencyTestPaymentFrom("0xvictim", "0x01")
+	key := IdempotencyKey(victim, requirements)
+
+	// Another payer reusing the victim's (public) nonce must not hit the victim's settlement
+	attacker, _ := idempotencyTestPaymentFrom("0xattacker", "0x01")
+	attacker.Payload["signature"] = "0xattackersig"
+	if IdempotencyKey(attacker, requirements) == key {
+		t.Error("Expected a different payer with the same nonce to produce a different key")
+	}
+
+	// A different signature over the same authorization is a different payment too
+	resigned, _ := idempotencyTestPaymentFrom("0xvictim", "0x01")
+	resigned.Payload["signature"] = "0xother"
+	if IdempotencyKey(resigned, requirements) == key {
+		t.Error("Expected a different signature to produce a different key")
+	}
+
+	// Key order and whitespace in the serialized payload don't matter
+	requirementsBytes, _ := json.Marshal(requirements)
+	reordered := []byte(`{"payload":{"authorization":{"nonce":"0x01","from":"0xvictim"}, "signature":"0xsig"},"x402Version":2}`)
+	if IdempotencyKeyFromJSON(reordered, requirementsBytes) != key {
+		t.Error("Expected key derived from reordered payload JSON to match")
+	}
+}
+
+func TestHTTPFacilitatorClientIdempotency(t *testing.T) {
+	ctx := context.Background()
+	payload, requirements := idempotencyTestPayment("0x01")
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+	wantKey := IdempotencyKey(payload, requirements)
+
+	var calls atomic.Int32
+	var succeed atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if got := r.Header.Get(IdempotencyKeyHeader); got != wantKey {
+			t.Errorf("Expected %s header %q, got %q", IdempotencyKeyHeader, wantKey, got)
+		}
+		if !succeed.Load() {
+			json.NewEncoder(w).Encode(x402.SettleResponse{Success: false, ErrorReason: "insufficient_funds"})
+			return
+		}
+		json.NewEncoder(w).Encode(x402.SettleResponse{Success: true, Transaction: "0xtx"})
+	}))
+	defer server.Close()
+
+	store := NewInMemoryIdempotencyStore()
+	client := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: server.URL, IdempotencyStore: store})
+
+	// Failed settlements are not cached
+	if result, err := client.Settle(ctx, payloadBytes, requirementsBytes); err != nil || result.Success {
+		t.Fatalf("Expected unsuccessful settlement, got %+v, %v", result, err)
+	}
+	if _, found, _ := store.Get(ctx, wantKey); found {
+		t.Error("Expected failed settlement not to be cached")
+	}
+
+	succeed.Store(true)
+	for i := 0; i < 3; i++ {
+		result, err := client.Settle(ctx, payloadBytes, requirementsBytes)
+		if err != nil || result.Transaction != "0xtx" {
+			t.Fatalf("Expected cached settlement, got %+v, %v", result, err)
+		}
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("Expected 2 calls to /settle, got %d", n)
+	}
+
+	// A key from the context takes precedence
+	var gotKey string
+	keyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKey = r.Header.Get(IdempotencyKeyHeader)
+		json.NewEncoder(w).Encode(x402.SettleResponse{Success: true})
+	}))
+	defer keyServer.Close()
+
+	keyClient := NewHTTPFacilitatorClient(&FacilitatorConfig{URL: keyServer.URL})
+	if _, err := keyClient.Settle(WithIdempotencyKey(ctx, "custom-key"), payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if gotKey != "custom-key" {
+		t.Errorf("Expected context key 'custom-key', got %q", gotKey)
+	}
+}
+
+func TestProcessSettlementSetsIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	payload, requirements := idempotencyTestPayment("0x01")
+
+	var gotKey string
+	mockClient := &mockFacilitatorClient{
+		settle: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			gotKey, _ = IdempotencyKeyFromContext(ctx)
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx"}, nil
+		},
+	}
+
+	server := Newx402HTTPResourceServer(RoutesConfig{}, x402.WithFacilitatorClient(mockClient))
+	server.Initialize(ctx)
+
+	if result := server.ProcessSettlement(ctx, payload, requirements); !result.Success {
+		t.Fatalf("Unexpected failure: %v", result.ErrorReason)
+	}
+	if gotKey != IdempotencyKey(payload, requirements) {
+		t.Errorf("Expected idempotency key on settle context, got %q", gotKey)
+	}
+}
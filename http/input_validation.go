@@ -0,0 +1,301 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	exttypes "x402-go/extensions/types"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ============================================================================
+// Request Input Validation
+// ============================================================================
+
+// MaxValidatedBodySize is the largest request body validated against a route's input schema.
+// Larger bodies on routes with ValidateInput are rejected with 413.
+const MaxValidatedBodySize = 1 << 20
+
+// ErrBodyTooLarge is returned by ReadRequestBody for bodies over MaxValidatedBodySize
+var ErrBodyTooLarge = errors.New("request body too large to validate")
+
+// RequestInputAdapter is implemented by adapters that can expose the request's query
+// parameters and body, which routes with ValidateInput check against their discovery schema.
+// Requests whose adapter doesn't implement it are not validated.
+type RequestInputAdapter interface {
+	// GetQueryParams gets the URL query parameters
+	GetQueryParams() map[string][]string
+
+	// GetBody gets the request body, leaving it readable by the next handler
+	GetBody() ([]byte, error)
+}
+
+// ReadRequestBody reads up to MaxValidatedBodySize bytes of req's body and puts them back,
+// so handlers further down still see the whole body. Returns ErrBodyTooLarge, with the body
+// intact, if it is any longer.
+func ReadRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, MaxValidatedBodySize+1))
+	req.Body = readCloser{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > MaxValidatedBodySize {
+		return nil, ErrBodyTooLarge
+	}
+	return body, nil
+}
+
+// readCloser joins the replayed body with the original body's Close
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// inputSchemas is the part of a bazaar discovery extension needed to validate a request
+type inputSchemas struct {
+	bodyType    exttypes.BodyType
+	queryParams map[string]interface{}
+	body        map[string]interface{}
+}
+
+// discoveryInputSchemas returns the input schemas declared by the bazaar extension in
+// extensions, or false if the route declares none.
+// The extension is read through JSON so both typed and raw map declarations work.
+func discoveryInputSchemas(extensions map[string]interface{}) (inputSchemas, bool) {
+	declaration, ok := extensions[exttypes.BAZAAR]
+	if !ok {
+		return inputSchemas{}, false
+	}
+
+	data, err := json.Marshal(declaration)
+	if err != nil {
+		return inputSchemas{}, false
+	}
+	var extension struct {
+		Info struct {
+			Input struct {
+				BodyType exttypes.BodyType `json:"bodyType"`
+			} `json:"input"`
+		} `json:"info"`
+		Schema struct {
+			Properties struct {
+				Input struct {
+					Properties struct {
+						QueryParams map[string]interface{} `json:"queryParams"`
+						Body        map[string]interface{} `json:"body"`
+					} `json:"properties"`
+				} `json:"input"`
+			} `json:"properties"`
+		} `json:"schema"`
+	}
+	if err := json.Unmarshal(data, &extension); err != nil {
+		return inputSchemas{}, false
+	}
+
+	properties := extension.Schema.Properties.Input.Properties
+	if properties.QueryParams == nil && properties.Body == nil {
+		return inputSchemas{}, false
+	}
+	return inputSchemas{
+		bodyType:    extension.Info.Input.BodyType,
+		queryParams: properties.QueryParams,
+		body:        properties.Body,
+	}, true
+}
+
+// validateInput checks the request's query parameters and body against the input schemas
+// declared in extensions. It returns nil when the input is valid, the route declares no
+// schema or the adapter can't expose the input, and otherwise the response to send instead
+// of the payment flow.
+func validateInput(reqCtx HTTPRequestContext, extensions map[string]interface{}) *HTTPResponseInstructions {
+	schemas, ok := discoveryInputSchemas(extensions)
+	if !ok {
+		return nil
+	}
+	adapter, ok := reqCtx.Adapter.(RequestInputAdapter)
+	if !ok {
+		return nil
+	}
+
+	var violations []string
+
+	if schemas.queryParams != nil {
+		query := coerceParams(adapter.GetQueryParams(), schemas.queryParams)
+		violations = append(violations, validateAgainst(schemas.queryParams, query, "queryParams")...)
+	}
+
+	if schemas.body != nil {
+		raw, err := adapter.GetBody()
+		if errors.Is(err, ErrBodyTooLarge) {
+			return invalidInputResponse(413, []string{err.Error()})
+		}
+		if err != nil {
+			return invalidInputResponse(400, []string{fmt.Sprintf("body: %v", err)})
+		}
+
+		body, err := decodeBody(raw, schemas.bodyType, reqCtx.Adapter.GetHeader("Content-Type"), schemas.body)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("body: %v", err))
+		} else {
+			violations = append(violations, validateAgainst(schemas.body, body, "body")...)
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return invalidInputResponse(400, violations)
+}
+
+// invalidInputResponse is the response for a request failing input validation
+func invalidInputResponse(status int, details []string) *HTTPResponseInstructions {
+	return &HTTPResponseInstructions{
+		Status:  status,
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body: map[string]interface{}{
+			"error":   "Invalid request input",
+			"details": details,
+		},
+	}
+}
+
+// validateAgainst validates document against schema, returning one message per violation
+// prefixed with the part of the request it concerns
+func validateAgainst(schema map[string]interface{}, document interface{}, part string) []string {
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(schema), gojsonschema.NewGoLoader(document))
+	if err != nil {
+		return []string{fmt.Sprintf("%s: schema validation failed: %v", part, err)}
+	}
+
+	var violations []string
+	for _, desc := range result.Errors() {
+		field := part
+		if desc.Field() != gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+			field = part + "." + desc.Field()
+		}
+		violations = append(violations, fmt.Sprintf("%s: %s", field, desc.Description()))
+	}
+	return violations
+}
+
+// decodeBody decodes a request body of the declared body type into a document to validate
+func decodeBody(raw []byte, bodyType exttypes.BodyType, contentType string, schema map[string]interface{}) (interface{}, error) {
+	switch bodyType {
+	case exttypes.BodyTypeText:
+		return string(raw), nil
+
+	case exttypes.BodyTypeFormData:
+		fields, err := parseForm(raw, contentType)
+		if err != nil {
+			return nil, err
+		}
+		return coerceParams(fields, schema), nil
+
+	default:
+		if len(bytes.TrimSpace(raw)) == 0 {
+			return nil, nil
+		}
+		var body interface{}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return body, nil
+	}
+}
+
+// parseForm parses a multipart or URL-encoded form body into its fields.
+// File parts are represented by their file name.
+func parseForm(raw []byte, contentType string) (map[string][]string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/form-data" {
+		return url.ParseQuery(string(raw))
+	}
+
+	form, err := multipart.NewReader(bytes.NewReader(raw), params["boundary"]).ReadForm(MaxValidatedBodySize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid multipart form: %w", err)
+	}
+	defer form.RemoveAll()
+
+	fields := make(map[string][]string, len(form.Value)+len(form.File))
+	for name, values := range form.Value {
+		fields[name] = values
+	}
+	for name, files := range form.File {
+		for _, file := range files {
+			fields[name] = append(fields[name], file.Filename)
+		}
+	}
+	return fields, nil
+}
+
+// coerceParams turns string parameters into a JSON document shaped by schema's properties:
+// numbers, integers and booleans are parsed, arrays keep every value, and anything else takes
+// the first value. Values that don't parse stay strings so validation reports them.
+func coerceParams(params map[string][]string, schema map[string]interface{}) map[string]interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	document := make(map[string]interface{}, len(params))
+	for name, values := range params {
+		if len(values) == 0 {
+			continue
+		}
+		property, _ := properties[name].(map[string]interface{})
+		if schemaType(property) == "array" {
+			items, _ := property["items"].(map[string]interface{})
+			list := make([]interface{}, len(values))
+			for i, value := range values {
+				list[i] = coerceValue(value, schemaType(items))
+			}
+			document[name] = list
+			continue
+		}
+		document[name] = coerceValue(values[0], schemaType(property))
+	}
+	return document
+}
+
+// coerceValue parses value as the given JSON Schema type, leaving it a string if it doesn't parse
+func coerceValue(value, typ string) interface{} {
+	switch typ {
+	case "number", "integer":
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number
+		}
+	case "boolean":
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	}
+	return value
+}
+
+// schemaType returns the type declared by a property schema, taking the first non-null type
+// when several are allowed
+func schemaType(property map[string]interface{}) string {
+	switch typ := property["type"].(type) {
+	case string:
+		return typ
+	case []interface{}:
+		for _, t := range typ {
+			if s, ok := t.(string); ok && !strings.EqualFold(s, "null") {
+				return s
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,348 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	x402 "x402-go"
+	exttypes "x402-go/extensions/types"
+	"x402-go/types"
+)
+
+// inputAdapter is a mockHTTPAdapter that also exposes the request input
+type inputAdapter struct {
+	mockHTTPAdapter
+	query map[string][]string
+	body  []byte
+}
+
+func (a *inputAdapter) GetQueryParams() map[string][]string {
+	return a.query
+}
+
+func (a *inputAdapter) GetBody() ([]byte, error) {
+	return a.body, nil
+}
+
+// validatingServer serves GET /search and POST /items, both validating their input
+func validatingServer(t *testing.T) *x402HTTPResourceServer {
+	t.Helper()
+
+	option := PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}}
+	search := exttypes.DiscoveryExtension{
+		Info: exttypes.DiscoveryInfo{Input: exttypes.QueryInput{Type: "http", Method: exttypes.MethodGET}},
+		Schema: exttypes.JSONSchema{
+			"properties": map[string]interface{}{
+				"input": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"queryParams": map[string]interface{}{
+							"type": "object",
+							"properties": map[string]interface{}{
+								"q":     map[string]interface{}{"type": "string"},
+								"limit": map[string]interface{}{"type": "integer", "maximum": 50},
+								"tags":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+							},
+							"required": []string{"q"},
+						},
+					},
+				},
+			},
+		},
+	}
+	items := map[string]interface{}{
+		"info": map[string]interface{}{
+			"input": map[string]interface{}{"type": "http", "method": "POST", "bodyType": "json"},
+		},
+		"schema": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"input": map[string]interface{}{
+					"properties": map[string]interface{}{
+						"body": map[string]interface{}{
+							"type":       "object",
+							"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+							"required":   []string{"name"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	routes := RoutesConfig{
+		"GET /search": {
+			Accepts:       option,
+			Extensions:    map[string]interface{}{exttypes.BAZAAR: search},
+			ValidateInput: true,
+		},
+		"POST /items": {
+			Accepts:       option,
+			Extensions:    map[string]interface{}{exttypes.BAZAAR: items},
+			ValidateInput: true,
+		},
+	}
+
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(&mockFacilitatorClient{
+			supported: func(ctx context.Context) (x402.SupportedResponse, error) {
+				return x402.SupportedResponse{
+					Kinds: []x402.SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}},
+				}, nil
+			},
+		}),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+	)
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() failed: %v", err)
+	}
+	return server
+}
+
+func TestProcessHTTPRequestValidatesInput(t *testing.T) {
+	server := validatingServer(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		query      map[string][]string
+		body       string
+		wantStatus int
+		wantDetail string
+	}{
+		{
+			name:       "valid query",
+			method:     "GET",
+			path:       "/search",
+			query:      map[string][]string{"q": {"coffee"}, "limit": {"10"}, "tags": {"a", "b"}},
+			wantStatus: 402,
+		},
+		{
+			name:       "missing required param",
+			method:     "GET",
+			path:       "/search",
+			query:      map[string][]string{"limit": {"10"}},
+			wantStatus: 400,
+			wantDetail: "q is required",
+		},
+		{
+			name:       "param of the wrong type",
+			method:     "GET",
+			path:       "/search",
+			query:      map[string][]string{"q": {"coffee"}, "limit": {"ten"}},
+			wantStatus: 400,
+			wantDetail: "queryParams.limit",
+		},
+		{
+			name:       "param out of range",
+			method:     "GET",
+			path:       "/search",
+			query:      map[string][]string{"q": {"coffee"}, "limit": {"100"}},
+			wantStatus: 400,
+			wantDetail: "queryParams.limit",
+		},
+		{
+			name:       "valid body",
+			method:     "POST",
+			path:       "/items",
+			body:       `{"name": "widget"}`,
+			wantStatus: 402,
+		},
+		{
+			name:       "body missing required field",
+			method:     "POST",
+			path:       "/items",
+			body:       `{"size": 3}`,
+			wantStatus: 400,
+			wantDetail: "name is required",
+		},
+		{
+			name:       "body not JSON",
+			method:     "POST",
+			path:       "/items",
+			body:       `name=widget`,
+			wantStatus: 400,
+			wantDetail: "invalid JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &inputAdapter{
+				mockHTTPAdapter: mockHTTPAdapter{method: tt.method, path: tt.path, url: "http://example.com" + tt.path},
+				query:           tt.query,
+				body:            []byte(tt.body),
+			}
+			result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{
+				Adapter: adapter,
+				Path:    tt.path,
+				Method:  tt.method,
+			}, nil)
+
+			if result.Response == nil {
+				t.Fatalf("Expected response instructions, got %s", result.Type)
+			}
+			if result.Response.Status != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d (%v)", tt.wantStatus, result.Response.Status, result.Response.Body)
+			}
+			if tt.wantDetail == "" {
+				return
+			}
+			body, ok := result.Response.Body.(map[string]interface{})
+			if !ok {
+				t.Fatalf("Expected a JSON body, got %T", result.Response.Body)
+			}
+			details, _ := body["details"].([]string)
+			if !strings.Contains(strings.Join(details, "\n"), tt.wantDetail) {
+				t.Errorf("Expected a detail mentioning %q, got %v", tt.wantDetail, details)
+			}
+		})
+	}
+}
+
+func TestProcessHTTPRequestValidatesInputBeforePayment(t *testing.T) {
+	server := validatingServer(t)
+
+	// An invalid request is rejected before its payment is verified
+	payload, _ := json.Marshal(types.PaymentPayload{
+		X402Version: 2,
+		Payload:     map[string]interface{}{"sig": "test"},
+		Accepted: types.PaymentRequirements{
+			Scheme:            "exact",
+			Network:           "eip155:1",
+			Asset:             "USDC",
+			Amount:            "1000000",
+			PayTo:             "0xtest",
+			MaxTimeoutSeconds: 300,
+			Extra:             map[string]interface{}{"resourceUrl": "http://example.com/search"},
+		},
+	})
+	adapter := &inputAdapter{
+		mockHTTPAdapter: mockHTTPAdapter{
+			method:  "GET",
+			path:    "/search",
+			url:     "http://example.com/search",
+			headers: map[string]string{"PAYMENT-SIGNATURE": base64.StdEncoding.EncodeToString(payload)},
+		},
+	}
+	result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{
+		Adapter: adapter,
+		Path:    "/search",
+		Method:  "GET",
+	}, nil)
+
+	if result.Response == nil || result.Response.Status != 400 {
+		t.Fatalf("Expected status 400, got %+v", result.Response)
+	}
+	if body, _ := result.Response.Body.(map[string]interface{}); body["error"] != "Invalid request input" {
+		t.Errorf("Expected an input error, got %v", result.Response.Body)
+	}
+}
+
+func TestProcessHTTPRequestSkipsValidationWithoutInputAdapter(t *testing.T) {
+	server := validatingServer(t)
+
+	adapter := &mockHTTPAdapter{method: "GET", path: "/search", url: "http://example.com/search"}
+	result := server.ProcessHTTPRequest(context.Background(), HTTPRequestContext{
+		Adapter: adapter,
+		Path:    "/search",
+		Method:  "GET",
+	}, nil)
+
+	if result.Response == nil || result.Response.Status != 402 {
+		t.Fatalf("Expected status 402, got %+v", result.Response)
+	}
+}
+
+func TestCoerceParams(t *testing.T) {
+	schema := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"n":    map[string]interface{}{"type": "number"},
+			"ok":   map[string]interface{}{"type": []interface{}{"null", "boolean"}},
+			"ids":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}},
+			"name": map[string]interface{}{"type": "string"},
+		},
+	}
+	params := map[string][]string{
+		"n":     {"1.5"},
+		"ok":    {"true"},
+		"ids":   {"1", "x"},
+		"name":  {"a", "b"},
+		"extra": {"7"},
+	}
+
+	document := coerceParams(params, schema)
+
+	if document["n"] != 1.5 {
+		t.Errorf("n = %#v, want 1.5", document["n"])
+	}
+	if document["ok"] != true {
+		t.Errorf("ok = %#v, want true", document["ok"])
+	}
+	ids, _ := document["ids"].([]interface{})
+	if len(ids) != 2 || ids[0] != float64(1) || ids[1] != "x" {
+		t.Errorf("ids = %#v, want [1 \"x\"]", document["ids"])
+	}
+	if document["name"] != "a" {
+		t.Errorf("name = %#v, want \"a\"", document["name"])
+	}
+	if document["extra"] != "7" {
+		t.Errorf("extra = %#v, want \"7\"", document["extra"])
+	}
+}
+
+func TestDecodeFormBody(t *testing.T) {
+	schema := map[string]interface{}{
+		"properties": map[string]interface{}{"count": map[string]interface{}{"type": "integer"}},
+	}
+
+	body, err := decodeBody([]byte("count=3&label=x"), exttypes.BodyTypeFormData, "application/x-www-form-urlencoded", schema)
+	if err != nil {
+		t.Fatalf("decodeBody() failed: %v", err)
+	}
+	fields := body.(map[string]interface{})
+	if fields["count"] != float64(3) || fields["label"] != "x" {
+		t.Errorf("Unexpected fields: %#v", fields)
+	}
+
+	multipartBody := "--b\r\nContent-Disposition: form-data; name=\"count\"\r\n\r\n4\r\n--b--\r\n"
+	body, err = decodeBody([]byte(multipartBody), exttypes.BodyTypeFormData, "multipart/form-data; boundary=b", schema)
+	if err != nil {
+		t.Fatalf("decodeBody() failed: %v", err)
+	}
+	if fields := body.(map[string]interface{}); fields["count"] != float64(4) {
+		t.Errorf("Unexpected fields: %#v", fields)
+	}
+}
+
+func TestReadRequestBody(t *testing.T) {
+	req, _ := http.NewRequest("POST", "http://example.com/items", strings.NewReader(`{"name":"widget"}`))
+
+	body, err := ReadRequestBody(req)
+	if err != nil {
+		t.Fatalf("ReadRequestBody() failed: %v", err)
+	}
+	if string(body) != `{"name":"widget"}` {
+		t.Errorf("Unexpected body %q", body)
+	}
+	rest, _ := io.ReadAll(req.Body)
+	if !bytes.Equal(rest, body) {
+		t.Errorf("Body not restored for the handler, got %q", rest)
+	}
+
+	large := bytes.Repeat([]byte("a"), MaxValidatedBodySize+10)
+	req, _ = http.NewRequest("POST", "http://example.com/items", bytes.NewReader(large))
+	if _, err := ReadRequestBody(req); err != ErrBodyTooLarge {
+		t.Fatalf("Expected ErrBodyTooLarge, got %v", err)
+	}
+	rest, _ = io.ReadAll(req.Body)
+	if len(rest) != len(large) {
+		t.Errorf("Expected the whole %d-byte body for the handler, got %d", len(large), len(rest))
+	}
+}
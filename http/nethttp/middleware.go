@@ -0,0 +1,362 @@
+package nethttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	x402 "x402-go"
+	"x402-go/extensions/bazaar"
+	x402http "x402-go/http"
+)
+
+// ============================================================================
+// net/http Adapter Implementation
+// ============================================================================
+
+// NetHTTPAdapter implements HTTPAdapter for net/http requests
+type NetHTTPAdapter struct {
+	req *http.Request
+}
+
+// NewNetHTTPAdapter creates a new net/http adapter
+func NewNetHTTPAdapter(req *http.Request) *NetHTTPAdapter {
+	return &NetHTTPAdapter{req: req}
+}
+
+// GetHeader gets a request header
+func (a *NetHTTPAdapter) GetHeader(name string) string {
+	return a.req.Header.Get(name)
+}
+
+// GetMethod gets the HTTP method
+func (a *NetHTTPAdapter) GetMethod() string {
+	return a.req.Method
+}
+
+// GetPath gets the request path
+func (a *NetHTTPAdapter) GetPath() string {
+	return a.req.URL.Path
+}
+
+// GetURL gets the full request URL
+func (a *NetHTTPAdapter) GetURL() string {
+	scheme := "http"
+	if a.req.TLS != nil {
+		scheme = "https"
+	}
+	host := a.req.Host
+	if host == "" {
+		host = a.req.Header.Get("Host")
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, host, a.req.URL.Path)
+}
+
+// GetAcceptHeader gets the Accept header
+func (a *NetHTTPAdapter) GetAcceptHeader() string {
+	return a.req.Header.Get("Accept")
+}
+
+// GetUserAgent gets the User-Agent header
+func (a *NetHTTPAdapter) GetUserAgent() string {
+	return a.req.Header.Get("User-Agent")
+}
+
+// GetQueryParams gets the URL query parameters
+func (a *NetHTTPAdapter) GetQueryParams() map[string][]string {
+	return a.req.URL.Query()
+}
+
+// GetBody gets the request body, leaving it readable by the next handler
+func (a *NetHTTPAdapter) GetBody() ([]byte, error) {
+	return x402http.ReadRequestBody(a.req)
+}
+
+// ============================================================================
+// Middleware Configuration
+// ============================================================================
+
+// MiddlewareConfig configures the payment middleware
+type MiddlewareConfig struct {
+	// Paywall configuration
+	PaywallConfig *x402http.PaywallConfig
+
+	// Custom paywall page template (overrides PaywallConfig.Template)
+	PaywallTemplate *template.Template
+
+	// Sync with facilitator on start
+	SyncFacilitatorOnStart bool
+
+	// Custom error handler
+	ErrorHandler func(http.ResponseWriter, *http.Request, error)
+
+	// Custom settlement handler
+	SettlementHandler func(http.ResponseWriter, *http.Request, *x402.SettleResponse)
+
+	// Context timeout for payment operations
+	Timeout time.Duration
+
+	// Default for routes that don't set RouteConfig.PaymentRequiredBody
+	PaymentRequiredBody x402http.PaymentRequiredBody
+
+	// Resolves the router's route pattern for a request (e.g. "/weather/{city}")
+	RoutePattern func(*http.Request) string
+}
+
+// MiddlewareOption configures the middleware
+type MiddlewareOption func(*MiddlewareConfig)
+
+// WithPaywallConfig sets the paywall configuration
+func WithPaywallConfig(config *x402http.PaywallConfig) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.PaywallConfig = config
+	}
+}
+
+// WithPaywallTemplate renders the browser paywall with tmpl, which is executed with a
+// *x402http.PaywallTemplateData. The built-in page is served if it fails.
+func WithPaywallTemplate(tmpl *template.Template) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.PaywallTemplate = tmpl
+	}
+}
+
+// WithSyncFacilitatorOnStart sets whether to sync with facilitator on startup
+func WithSyncFacilitatorOnStart(sync bool) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.SyncFacilitatorOnStart = sync
+	}
+}
+
+// WithErrorHandler sets a custom error handler
+func WithErrorHandler(handler func(http.ResponseWriter, *http.Request, error)) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.ErrorHandler = handler
+	}
+}
+
+// WithSettlementHandler sets a custom settlement handler
+func WithSettlementHandler(handler func(http.ResponseWriter, *http.Request, *x402.SettleResponse)) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.SettlementHandler = handler
+	}
+}
+
+// WithTimeout sets the context timeout for payment operations
+func WithTimeout(timeout time.Duration) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.Timeout = timeout
+	}
+}
+
+// WithPaymentRequiredBody sets whether 402 responses to API clients carry the PaymentRequired
+// structure as their JSON body as well as in the PAYMENT-REQUIRED header, for routes that
+// don't set RouteConfig.PaymentRequiredBody themselves
+func WithPaymentRequiredBody(mode x402http.PaymentRequiredBody) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.PaymentRequiredBody = mode
+	}
+}
+
+// WithRoutePattern matches routes against the pattern resolve returns for a request, such as
+// "/weather/{city}", before falling back to the request path. Router integrations like the chi
+// middleware use it; resolve returns an empty string when the request matched no pattern.
+func WithRoutePattern(resolve func(*http.Request) string) MiddlewareOption {
+	return func(c *MiddlewareConfig) {
+		c.RoutePattern = resolve
+	}
+}
+
+// ============================================================================
+// Payment Middleware
+// ============================================================================
+
+// Middleware creates net/http middleware for x402 payment handling using a pre-configured server.
+// The returned function wraps any http.Handler, including http.ServeMux.
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("/weather", weatherHandler)
+//	handler := nethttp.Middleware(routes, server)(mux)
+//	http.ListenAndServe(":8080", handler)
+func Middleware(routes x402http.RoutesConfig, server *x402.X402ResourceServer, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	config := &MiddlewareConfig{
+		SyncFacilitatorOnStart: true,
+		Timeout:                30 * time.Second,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	// Wrap the resource server with HTTP functionality
+	httpServer := x402http.Wrappedx402HTTPResourceServer(routes.WithPaymentRequiredBody(config.PaymentRequiredBody), server)
+
+	httpServer.RegisterExtension(bazaar.BazaarResourceServerExtension)
+
+	// Initialize if requested - queries facilitator /supported to populate facilitatorClients map
+	if config.SyncFacilitatorOnStart {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+		defer cancel()
+		if err := httpServer.Initialize(ctx); err != nil {
+			httpServer.Logger().Warn("failed to initialize x402 server", "error", err)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return createMiddlewareHandler(httpServer, config, next)
+	}
+}
+
+// createMiddlewareHandler creates the actual http.Handler.
+func createMiddlewareHandler(server *x402http.HTTPServer, config *MiddlewareConfig, next http.Handler) http.Handler {
+	paywallConfig := config.PaywallConfig.WithTemplate(config.PaywallTemplate)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Create adapter and request context
+		adapter := NewNetHTTPAdapter(r)
+		reqCtx := x402http.HTTPRequestContext{
+			Adapter: adapter,
+			Path:    r.URL.Path,
+			Method:  r.Method,
+		}
+		if config.RoutePattern != nil {
+			if pattern := config.RoutePattern(r); pattern != "" {
+				patternCtx := reqCtx
+				patternCtx.Path = pattern
+				if server.RequiresPayment(patternCtx) {
+					reqCtx = patternCtx
+				}
+			}
+		}
+
+		// Check if route requires payment before waiting for initialization
+		if !server.RequiresPayment(reqCtx) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Create context with timeout
+		ctx, cancel := context.WithTimeout(r.Context(), config.Timeout)
+		defer cancel()
+
+		result := server.ProcessHTTPRequest(ctx, reqCtx, paywallConfig)
+
+		// Handle result
+		switch result.Type {
+		case x402http.ResultNoPaymentRequired:
+			// No payment required, continue to next handler
+			next.ServeHTTP(w, r)
+
+		case x402http.ResultPaymentError:
+			// Payment required but not provided or invalid
+			handlePaymentError(w, result.Response)
+
+		case x402http.ResultPaymentVerified:
+			if result.SettlesBeforeResponse() {
+				// Settle first; the handler only runs once the payment is confirmed
+				if settle(w, r, server, ctx, result, config) {
+					next.ServeHTTP(w, r)
+				}
+				return
+			}
+
+			// Payment verified, settle when the handler commits its status
+			handlePaymentVerified(w, r, next, server, ctx, result, config)
+		}
+	})
+}
+
+// handlePaymentError handles payment error responses
+func handlePaymentError(w http.ResponseWriter, response *x402http.HTTPResponseInstructions) {
+	// Set headers
+	for key, value := range response.Headers {
+		w.Header().Set(key, value)
+	}
+
+	// Send response body
+	if response.IsHTML {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(response.Status)
+		_, _ = w.Write([]byte(response.Body.(string)))
+		return
+	}
+
+	writeJSON(w, response.Status, response.Body)
+}
+
+// handlePaymentVerified runs the protected handler behind a x402http.SettlingWriter
+func handlePaymentVerified(w http.ResponseWriter, r *http.Request, next http.Handler, server *x402http.HTTPServer, ctx context.Context, result x402http.HTTPProcessResult, config *MiddlewareConfig) {
+	writer := x402http.NewSettlingWriter(w, func(w http.ResponseWriter) bool {
+		return settle(w, r, server, ctx, result, config)
+	})
+
+	// Continue to protected handler
+	next.ServeHTTP(writer, r)
+
+	// Handlers that write nothing still get an implicit 200, which is settled
+	writer.WriteHeader(http.StatusOK)
+}
+
+// settle processes settlement and either injects the settlement headers (returning true)
+// or writes the settlement failure response (returning false)
+func settle(w http.ResponseWriter, r *http.Request, server *x402http.HTTPServer, ctx context.Context, result x402http.HTTPProcessResult, config *MiddlewareConfig) bool {
+	settleResult := server.ProcessSettlement(
+		ctx,
+		*result.PaymentPayload,
+		*result.PaymentRequirements,
+	)
+
+	// Check settlement success
+	if !settleResult.Success {
+		errorReason := settleResult.ErrorReason
+		if errorReason == "" {
+			errorReason = "Settlement failed"
+		}
+
+		// Drop headers the protected handler set for the response that is no longer sent
+		for key := range w.Header() {
+			w.Header().Del(key)
+		}
+
+		if config.ErrorHandler != nil {
+			config.ErrorHandler(w, r, fmt.Errorf("settlement failed: %s", errorReason))
+		} else {
+			writeJSON(w, http.StatusPaymentRequired, map[string]interface{}{
+				"error":   "Settlement failed",
+				"details": errorReason,
+			})
+		}
+		return false
+	}
+
+	// Add settlement headers
+	for key, value := range settleResult.Headers {
+		w.Header().Set(key, value)
+	}
+
+	// Call settlement handler if configured
+	if config.SettlementHandler != nil {
+		settleResponse := &x402.SettleResponse{
+			Success:     true,
+			Transaction: settleResult.Transaction,
+			Network:     settleResult.Network,
+			Payer:       settleResult.Payer,
+		}
+		config.SettlementHandler(w, r, settleResponse)
+	}
+
+	return true
+}
+
+// writeJSON writes a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
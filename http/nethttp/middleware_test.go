@@ -0,0 +1,682 @@
+package nethttp
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	x402 "x402-go"
+	exttypes "x402-go/extensions/types"
+	x402http "x402-go/http"
+	"x402-go/types"
+)
+
+// ============================================================================
+// Mock Implementations
+// ============================================================================
+
+// mockSchemeServer implements x402.SchemeNetworkServer for testing
+type mockSchemeServer struct {
+	scheme string
+}
+
+func (m *mockSchemeServer) Scheme() string {
+	return m.scheme
+}
+
+func (m *mockSchemeServer) ParsePrice(price x402.Price, network x402.Network) (x402.AssetAmount, error) {
+	return x402.AssetAmount{
+		Asset:  "USDC",
+		Amount: "1000000",
+	}, nil
+}
+
+func (m *mockSchemeServer) EnhancePaymentRequirements(ctx context.Context, base types.PaymentRequirements, supported types.SupportedKind, extensions []string) (types.PaymentRequirements, error) {
+	return base, nil
+}
+
+// mockFacilitatorClient implements x402.FacilitatorClient for testing
+type mockFacilitatorClient struct {
+	verifyFunc func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error)
+	settleFunc func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error)
+}
+
+func (m *mockFacilitatorClient) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+	if m.verifyFunc != nil {
+		return m.verifyFunc(ctx, payloadBytes, requirementsBytes)
+	}
+	return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+}
+
+func (m *mockFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+	if m.settleFunc != nil {
+		return m.settleFunc(ctx, payloadBytes, requirementsBytes)
+	}
+	return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+}
+
+func (m *mockFacilitatorClient) GetSupported(ctx context.Context) (x402.SupportedResponse, error) {
+	return x402.SupportedResponse{
+		Kinds: []x402.SupportedKind{
+			{X402Version: 2, Scheme: "exact", Network: "eip155:1"},
+		},
+		Extensions: []string{},
+		Signers:    make(map[string][]string),
+	}, nil
+}
+
+func (m *mockFacilitatorClient) Identifier() string {
+	return "mock"
+}
+
+// ============================================================================
+// Test Helpers
+// ============================================================================
+
+// testRoutes protects POST /api, GET /api and DELETE /resource/:id
+func testRoutes() x402http.RoutesConfig {
+	option := x402http.PaymentOptions{
+		{
+			Scheme:  "exact",
+			PayTo:   "0xtest",
+			Price:   "$1.00",
+			Network: "eip155:1",
+		},
+	}
+	return x402http.RoutesConfig{
+		"GET /api":  {Accepts: option, Description: "API access"},
+		"POST /api": {Accepts: option, Description: "API access"},
+
+		"DELETE /resource/:id": {Accepts: option, Description: "Resource deletion"},
+	}
+}
+
+// createTestHandler wraps handler with the payment middleware backed by client
+func createTestHandler(client x402.FacilitatorClient, handler http.Handler, opts ...MiddlewareOption) http.Handler {
+	server := x402.Newx402ResourceServer(x402.WithFacilitatorClient(client))
+	server.Register("eip155:1", &mockSchemeServer{scheme: "exact"})
+
+	opts = append([]MiddlewareOption{WithTimeout(5 * time.Second)}, opts...)
+	return Middleware(testRoutes(), server, opts...)(handler)
+}
+
+// createPaymentHeader creates a base64-encoded payment header for testing
+func createPaymentHeader(payTo string) string {
+	payload := x402.PaymentPayload{
+		X402Version: 2,
+		Payload:     map[string]interface{}{"sig": "test"},
+		Accepted: x402.PaymentRequirements{
+			Scheme:            "exact",
+			Network:           "eip155:1",
+			Asset:             "USDC",
+			Amount:            "1000000",
+			PayTo:             payTo,
+			MaxTimeoutSeconds: 300,
+			Extra: map[string]interface{}{
+				"resourceUrl": "http://example.com/api",
+			},
+		},
+	}
+
+	payloadJSON, _ := json.Marshal(payload)
+	return base64.StdEncoding.EncodeToString(payloadJSON)
+}
+
+// newPaidRequest creates a request carrying a payment header
+func newPaidRequest(method string) *http.Request {
+	req := httptest.NewRequest(method, "/api", nil)
+	req.Header.Set("PAYMENT-SIGNATURE", createPaymentHeader("0xtest"))
+	req.Host = "example.com"
+	return req
+}
+
+// ============================================================================
+// NetHTTPAdapter Tests
+// ============================================================================
+
+func TestNetHTTPAdapter(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/users/123?x=1", nil)
+	req.Host = "example.com"
+	req.Header.Set("X-Custom-Header", "test-value")
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+
+	adapter := NewNetHTTPAdapter(req)
+
+	if adapter.GetHeader("X-Custom-Header") != "test-value" {
+		t.Error("Expected X-Custom-Header to be 'test-value'")
+	}
+	if adapter.GetMethod() != "POST" {
+		t.Errorf("Expected method POST, got %s", adapter.GetMethod())
+	}
+	if adapter.GetPath() != "/api/users/123" {
+		t.Errorf("Expected path '/api/users/123', got '%s'", adapter.GetPath())
+	}
+	if adapter.GetURL() != "http://example.com/api/users/123" {
+		t.Errorf("Expected URL 'http://example.com/api/users/123', got '%s'", adapter.GetURL())
+	}
+	if adapter.GetAcceptHeader() != "text/html" {
+		t.Errorf("Expected Accept 'text/html', got '%s'", adapter.GetAcceptHeader())
+	}
+	if adapter.GetUserAgent() != "Mozilla/5.0" {
+		t.Errorf("Expected User-Agent 'Mozilla/5.0', got '%s'", adapter.GetUserAgent())
+	}
+
+	req.TLS = &tls.ConnectionState{}
+	if !strings.HasPrefix(adapter.GetURL(), "https://") {
+		t.Errorf("Expected https URL for TLS request, got '%s'", adapter.GetURL())
+	}
+}
+
+// ============================================================================
+// Middleware Tests
+// ============================================================================
+
+func TestMiddleware_CallsNextWhenNoPaymentRequired(t *testing.T) {
+	nextCalled := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/public", func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		w.WriteHeader(http.StatusOK)
+	})
+
+	handler := createTestHandler(&mockFacilitatorClient{}, mux, WithSyncFacilitatorOnStart(false))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/public", nil))
+
+	if !nextCalled {
+		t.Error("Expected next handler to be called for non-protected route")
+	}
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestMiddleware_Returns402JSONForPaymentError(t *testing.T) {
+	nextCalled := false
+	handler := createTestHandler(&mockFacilitatorClient{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+	}))
+
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402, got %d", w.Code)
+	}
+	if w.Header().Get("PAYMENT-REQUIRED") == "" {
+		t.Error("Expected PAYMENT-REQUIRED header")
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("Expected JSON content type, got %s", w.Header().Get("Content-Type"))
+	}
+	if nextCalled {
+		t.Error("Protected handler should not run without payment")
+	}
+}
+
+func TestMiddleware_Returns402BodyWithPaymentRequiredBody(t *testing.T) {
+	handler := createTestHandler(&mockFacilitatorClient{}, http.NotFoundHandler(), WithPaymentRequiredBody(x402http.PaymentRequiredBodyWhenAccepted))
+
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.Header.Set("Accept", "application/json")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Fatalf("Expected status 402, got %d", w.Code)
+	}
+	if w.Header().Get("PAYMENT-REQUIRED") == "" {
+		t.Error("Expected PAYMENT-REQUIRED header alongside the body")
+	}
+
+	client := x402http.Newx402HTTPClient(x402.Newx402Client())
+	required, err := client.GetPaymentRequiredResponse(map[string]string{}, w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("Expected PaymentRequired in body: %v", err)
+	}
+	if required.X402Version != 2 || len(required.Accepts) == 0 {
+		t.Errorf("Unexpected PaymentRequired body: %+v", required)
+	}
+}
+
+func TestMiddleware_MatchesDeleteRoute(t *testing.T) {
+	nextCalled := false
+	handler := createTestHandler(&mockFacilitatorClient{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		w.WriteHeader(http.StatusOK)
+	}), WithSyncFacilitatorOnStart(false))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("DELETE", "/resource/42", nil))
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402 for DELETE /resource/42, got %d", w.Code)
+	}
+	if nextCalled {
+		t.Error("Protected handler should not run without payment")
+	}
+
+	// Only DELETE is payable on the route
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/resource/42", nil))
+	if w.Code != http.StatusOK || !nextCalled {
+		t.Errorf("Expected GET /resource/42 to pass through, got status %d", w.Code)
+	}
+}
+
+func TestMiddleware_MatchesRoutePattern(t *testing.T) {
+	handler := createTestHandler(&mockFacilitatorClient{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), WithSyncFacilitatorOnStart(false), WithRoutePattern(func(r *http.Request) string {
+		switch r.URL.Path {
+		case "/v1/api":
+			return "/api"
+		case "/api":
+			return "/unrouted"
+		}
+		return ""
+	}))
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/v1/api", http.StatusPaymentRequired}, // matched through the pattern
+		{"/api", http.StatusPaymentRequired},    // pattern has no route, path is used
+		{"/public", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("GET %s: expected status %d, got %d", tt.path, tt.want, w.Code)
+		}
+	}
+}
+
+func TestMiddleware_AdvertisesDeclaredDiscovery(t *testing.T) {
+	server := x402.Newx402ResourceServer(x402.WithFacilitatorClient(&mockFacilitatorClient{}))
+	server.Register("eip155:1", &mockSchemeServer{scheme: "exact"})
+	err := server.DeclareDiscovery("DELETE /resource/:id", exttypes.DeclareQueryDiscoveryConfig{
+		Input: map[string]interface{}{"soft": "true"},
+		InputSchema: exttypes.JSONSchema{
+			"properties": map[string]interface{}{"soft": map[string]interface{}{"type": "string"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("DeclareDiscovery() failed: %v", err)
+	}
+
+	handler := Middleware(testRoutes(), server, WithTimeout(5*time.Second))(http.NotFoundHandler())
+
+	req := httptest.NewRequest("DELETE", "/resource/42", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Fatalf("Expected status 402, got %d", w.Code)
+	}
+	header, err := base64.StdEncoding.DecodeString(w.Header().Get("PAYMENT-REQUIRED"))
+	if err != nil {
+		t.Fatalf("Failed to decode PAYMENT-REQUIRED: %v", err)
+	}
+	var paymentRequired struct {
+		Extensions map[string]exttypes.DiscoveryExtension `json:"extensions"`
+	}
+	if err := json.Unmarshal(header, &paymentRequired); err != nil {
+		t.Fatalf("Failed to unmarshal PAYMENT-REQUIRED: %v", err)
+	}
+
+	discovery, ok := paymentRequired.Extensions[exttypes.BAZAAR]
+	if !ok {
+		t.Fatalf("Expected a bazaar extension, got %s", header)
+	}
+	input, ok := discovery.Info.Input.(exttypes.QueryInput)
+	if !ok {
+		t.Fatalf("Expected query input, got %T", discovery.Info.Input)
+	}
+	if input.Method != exttypes.MethodDELETE || input.QueryParams["soft"] != "true" {
+		t.Errorf("Unexpected discovery input: %+v", input)
+	}
+	if discovery.Schema == nil {
+		t.Error("Expected an input schema")
+	}
+}
+
+func TestMiddleware_RejectsInvalidInput(t *testing.T) {
+	server := x402.Newx402ResourceServer(x402.WithFacilitatorClient(&mockFacilitatorClient{}))
+	server.Register("eip155:1", &mockSchemeServer{scheme: "exact"})
+	err := server.DeclareDiscovery("POST /api", exttypes.DeclareBodyDiscoveryConfig{
+		Input: map[string]interface{}{"prompt": "hello"},
+		InputSchema: exttypes.JSONSchema{
+			"type":       "object",
+			"properties": map[string]interface{}{"prompt": map[string]interface{}{"type": "string"}},
+			"required":   []string{"prompt"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("DeclareDiscovery() failed: %v", err)
+	}
+
+	routes := testRoutes()
+	route := routes["POST /api"]
+	route.ValidateInput = true
+	routes["POST /api"] = route
+
+	var handlerBody string
+	handler := Middleware(routes, server, WithTimeout(5*time.Second))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		handlerBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("POST", "/api", strings.NewReader(`{"prompt": 42}`))
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "body.prompt") {
+		t.Errorf("Expected the violation in the response, got %s", w.Body.String())
+	}
+
+	// A valid body still gets the 402 and reaches the handler intact once paid
+	req = httptest.NewRequest("POST", "/api", strings.NewReader(`{"prompt": "hi"}`))
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Fatalf("Expected status 402, got %d", w.Code)
+	}
+
+	req = newPaidRequest("POST")
+	req.Body = io.NopCloser(strings.NewReader(`{"prompt": "hi"}`))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if handlerBody != `{"prompt": "hi"}` {
+		t.Errorf("Expected the handler to read the body, got %q", handlerBody)
+	}
+}
+
+func TestMiddleware_Returns402HTMLForBrowserRequest(t *testing.T) {
+	handler := createTestHandler(&mockFacilitatorClient{}, http.NotFoundHandler(),
+		WithPaywallConfig(&x402http.PaywallConfig{AppName: "Test App"}),
+	)
+
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402, got %d", w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Expected HTML content type, got %s", w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), "<html") {
+		t.Error("Expected HTML paywall body")
+	}
+}
+
+func TestMiddleware_RendersPaywallTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("paywall").Parse(
+		`<html><h1>{{.Config.AppName}}</h1>{{range .Options}}<p>Pay {{.PayTo}} on {{.Network}}</p>{{end}}</html>`))
+	handler := createTestHandler(&mockFacilitatorClient{}, http.NotFoundHandler(),
+		WithPaywallTemplate(tmpl),
+		WithPaywallConfig(&x402http.PaywallConfig{AppName: "Branded"}),
+	)
+
+	req := httptest.NewRequest("GET", "/api", nil)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "<h1>Branded</h1>") || !strings.Contains(w.Body.String(), "on eip155:") {
+		t.Errorf("Expected templated paywall, got %s", w.Body.String())
+	}
+}
+
+func TestMiddleware_SettlesAndReturnsResponseForVerifiedPayment(t *testing.T) {
+	settleCalled := false
+	client := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			settleCalled = true
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+		},
+	}
+
+	handler := createTestHandler(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":"protected-data"}`))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newPaidRequest("POST"))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	if !settleCalled {
+		t.Error("Expected settlement to be called")
+	}
+	if w.Header().Get("PAYMENT-RESPONSE") == "" {
+		t.Error("Expected PAYMENT-RESPONSE header")
+	}
+	if w.Body.String() != `{"data":"protected-data"}` {
+		t.Errorf("Expected protected body, got %s", w.Body.String())
+	}
+}
+
+func TestMiddleware_StreamsBodyAfterSettlement(t *testing.T) {
+	w := httptest.NewRecorder()
+	handler := createTestHandler(&mockFacilitatorClient{}, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte("chunk-1"))
+		rw.(http.Flusher).Flush()
+
+		// The first chunk has reached the client, after the settlement headers
+		if w.Body.String() != "chunk-1" || !w.Flushed {
+			t.Errorf("Expected first chunk to be streamed, got %q", w.Body.String())
+		}
+		if w.Header().Get("PAYMENT-RESPONSE") == "" {
+			t.Error("Expected PAYMENT-RESPONSE header before the body")
+		}
+		_, _ = rw.Write([]byte("chunk-2"))
+	}))
+
+	handler.ServeHTTP(w, newPaidRequest("GET"))
+
+	if w.Code != http.StatusOK || w.Body.String() != "chunk-1chunk-2" {
+		t.Errorf("Expected 200 'chunk-1chunk-2', got %d '%s'", w.Code, w.Body.String())
+	}
+}
+
+func TestMiddleware_SkipsSettlementWhenHandlerReturns400OrHigher(t *testing.T) {
+	settleCalled := false
+	client := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			settleCalled = true
+			return &x402.SettleResponse{Success: true, Transaction: "0xtx"}, nil
+		},
+	}
+
+	handler := createTestHandler(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newPaidRequest("POST"))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if settleCalled {
+		t.Error("Settlement should NOT be called when handler returns >= 400")
+	}
+	if w.Header().Get("PAYMENT-RESPONSE") != "" {
+		t.Error("Expected no PAYMENT-RESPONSE header")
+	}
+}
+
+func TestMiddleware_Returns402WhenSettlementFails(t *testing.T) {
+	client := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{Success: false, ErrorReason: "Insufficient funds"}, nil
+		},
+	}
+
+	handler := createTestHandler(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("protected-data"))
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newPaidRequest("POST"))
+
+	if w.Code != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402, got %d", w.Code)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response["error"] != "Settlement failed" {
+		t.Errorf("Expected error 'Settlement failed', got '%v'", response["error"])
+	}
+	if response["details"] != "Insufficient funds" {
+		t.Errorf("Expected details 'Insufficient funds', got '%v'", response["details"])
+	}
+}
+
+func TestMiddleware_SettlesBeforeResponse(t *testing.T) {
+	routes := testRoutes()
+	route := routes["POST /api"]
+	route.SettlementTiming = x402http.SettlementTimingBeforeResponse
+	routes["POST /api"] = route
+
+	tests := []struct {
+		name          string
+		settleSuccess bool
+		wantStatus    int
+		wantHandler   bool
+	}{
+		{name: "settlement succeeds", settleSuccess: true, wantStatus: http.StatusOK, wantHandler: true},
+		{name: "settlement fails", settleSuccess: false, wantStatus: http.StatusPaymentRequired, wantHandler: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settled := false
+			client := &mockFacilitatorClient{
+				settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+					settled = true
+					if !tt.settleSuccess {
+						return &x402.SettleResponse{Success: false, ErrorReason: "Insufficient funds"}, nil
+					}
+					return &x402.SettleResponse{Success: true, Transaction: "0xtx", Network: "eip155:1", Payer: "0xpayer"}, nil
+				},
+			}
+
+			server := x402.Newx402ResourceServer(x402.WithFacilitatorClient(client))
+			server.Register("eip155:1", &mockSchemeServer{scheme: "exact"})
+
+			handlerCalled := false
+			settledBeforeHandler := false
+			handler := Middleware(routes, server, WithTimeout(5*time.Second))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerCalled = true
+				settledBeforeHandler = settled && w.Header().Get("PAYMENT-RESPONSE") != ""
+				_, _ = w.Write([]byte("protected-data"))
+			}))
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, newPaidRequest("POST"))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if handlerCalled != tt.wantHandler {
+				t.Errorf("Expected handler called = %v, got %v", tt.wantHandler, handlerCalled)
+			}
+			if tt.wantHandler && !settledBeforeHandler {
+				t.Error("Expected payment to be settled before the handler ran")
+			}
+		})
+	}
+}
+
+func TestMiddleware_CustomErrorHandler(t *testing.T) {
+	client := &mockFacilitatorClient{
+		settleFunc: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.SettleResponse, error) {
+			return &x402.SettleResponse{Success: false, ErrorReason: "Insufficient funds"}, nil
+		},
+	}
+
+	var handlerErr error
+	handler := createTestHandler(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("protected-data"))
+	}), WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+		handlerErr = err
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newPaidRequest("POST"))
+
+	if handlerErr == nil || !strings.Contains(handlerErr.Error(), "Insufficient funds") {
+		t.Errorf("Expected custom error handler to receive settlement error, got %v", handlerErr)
+	}
+	if w.Code != http.StatusTeapot {
+		t.Errorf("Expected status 418, got %d", w.Code)
+	}
+}
+
+func TestMiddleware_CustomSettlementHandler(t *testing.T) {
+	var settled *x402.SettleResponse
+	handler := createTestHandler(&mockFacilitatorClient{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("protected-data"))
+	}), WithSettlementHandler(func(w http.ResponseWriter, r *http.Request, response *x402.SettleResponse) {
+		settled = response
+		w.Header().Set("X-Settled-Tx", response.Transaction)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newPaidRequest("POST"))
+
+	if settled == nil {
+		t.Fatal("Expected settlement handler to be called")
+	}
+	if settled.Transaction != "0xtx" || settled.Payer != "0xpayer" {
+		t.Errorf("Unexpected settle response: %+v", settled)
+	}
+	if w.Header().Get("X-Settled-Tx") != "0xtx" {
+		t.Error("Expected settlement handler headers on the response")
+	}
+}
@@ -1,17 +1,22 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
+	"html/template"
+	"math"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 
 	x402 "x402-go"
+	exttypes "x402-go/extensions/types"
 	"x402-go/types"
 )
 
@@ -42,6 +47,52 @@ type PaywallConfig struct {
 	SessionTokenEndpoint string `json:"sessionTokenEndpoint,omitempty"`
 	CurrentURL           string `json:"currentUrl,omitempty"`
 	Testnet              bool   `json:"testnet,omitempty"`
+
+	// Template renders the paywall instead of the built-in page. It is executed with a
+	// *PaywallTemplateData; if it fails, the built-in page is served.
+	Template *template.Template `json:"-"`
+}
+
+// WithTemplate returns a copy of the config (or a new config when c is nil) that renders the
+// paywall with tmpl. A nil tmpl returns c unchanged.
+func (c *PaywallConfig) WithTemplate(tmpl *template.Template) *PaywallConfig {
+	if tmpl == nil {
+		return c
+	}
+
+	config := &PaywallConfig{}
+	if c != nil {
+		*config = *c
+	}
+	config.Template = tmpl
+	return config
+}
+
+// PaywallTemplateData is the data a custom paywall template is executed with
+type PaywallTemplateData struct {
+	// PaymentRequired is the full 402 payload, also sent in the PAYMENT-REQUIRED header
+	PaymentRequired types.PaymentRequired
+
+	// PaymentRequiredJSON is PaymentRequired as JSON, for handing to a wallet script
+	PaymentRequiredJSON string
+
+	// Resource describes the protected resource (may be nil)
+	Resource *types.ResourceInfo
+
+	// Options are the accepted payment options, in the server's order of preference
+	Options []PaywallOption
+
+	// Config is the paywall configuration (app name, logo, ...)
+	Config PaywallConfig
+}
+
+// PaywallOption is one accepted payment option as shown on the paywall
+type PaywallOption struct {
+	types.PaymentRequirements
+
+	// DisplayAmount is Amount in whole tokens, using the decimals advertised in Extra
+	// (6, as for USDC, when none are)
+	DisplayAmount float64
 }
 
 // DynamicPayToFunc is a function that resolves payTo address dynamically based on request context
@@ -77,6 +128,34 @@ type UnpaidResponse struct {
 //	UnpaidResponse with ContentType and Body for the 402 response
 type UnpaidResponseBodyFunc func(ctx context.Context, reqCtx HTTPRequestContext) (*UnpaidResponse, error)
 
+// PaymentRequiredBody controls whether 402 responses to API clients also carry the
+// PaymentRequired structure as their JSON body, for clients that read it from the body.
+// The PAYMENT-REQUIRED header is always set.
+type PaymentRequiredBody string
+
+const (
+	// PaymentRequiredBodyNever sends the payment requirements in the header only (the default)
+	PaymentRequiredBodyNever PaymentRequiredBody = "never"
+	// PaymentRequiredBodyWhenAccepted adds the body when the request's Accept header lists application/json
+	PaymentRequiredBodyWhenAccepted PaymentRequiredBody = "accept"
+	// PaymentRequiredBodyAlways adds the body to every 402 sent to an API client
+	PaymentRequiredBodyAlways PaymentRequiredBody = "always"
+)
+
+// SettlementTiming controls when a route's payment is settled relative to its handler
+type SettlementTiming string
+
+const (
+	// SettlementTimingAfterResponse runs the handler on a verified payment and settles when it
+	// commits a success status (the default). Settlement is optimistic: a payment that fails to
+	// settle has still had its handler run.
+	SettlementTimingAfterResponse SettlementTiming = "after"
+	// SettlementTimingBeforeResponse settles and awaits confirmation before the handler runs, so a
+	// failed settlement prevents the handler from executing. Suited to irreversible actions and
+	// expensive compute, at the cost of settlement latency on every request.
+	SettlementTimingBeforeResponse SettlementTiming = "before"
+)
+
 // PaymentOption represents a single payment option for a route
 // Represents one way a client can pay for access to the resource
 type PaymentOption struct {
@@ -107,16 +186,50 @@ type RouteConfig struct {
 	// For browser requests (Accept: text/html), the paywall HTML takes precedence.
 	// If not provided, defaults to { ContentType: "application/json", Body: nil }.
 	UnpaidResponseBody UnpaidResponseBodyFunc `json:"-"`
+
+	// PaymentRequiredBody sets whether 402 responses to API clients carry the PaymentRequired
+	// structure as their JSON body as well as in the PAYMENT-REQUIRED header. Unset means the
+	// middleware's default (PaymentRequiredBodyNever unless configured otherwise).
+	// Ignored when UnpaidResponseBody is set.
+	PaymentRequiredBody PaymentRequiredBody `json:"paymentRequiredBody,omitempty"`
+
+	// SettlementTiming sets whether the payment is settled after the handler responds (the
+	// default) or before the handler runs
+	SettlementTiming SettlementTiming `json:"settlementTiming,omitempty"`
+
+	// ValidateInput rejects requests whose query parameters or body don't match the input
+	// schema declared in the route's bazaar discovery extension, with 400 instead of 402, so
+	// clients don't pay for a request the resource can't serve. Requires an adapter
+	// implementing RequestInputAdapter; the bundled middlewares all do.
+	ValidateInput bool `json:"validateInput,omitempty"`
 }
 
 // RoutesConfig maps route patterns to configurations
 type RoutesConfig map[string]RouteConfig
 
+// WithPaymentRequiredBody returns a copy of the routes in which every route that doesn't set
+// its own PaymentRequiredBody uses mode
+func (r RoutesConfig) WithPaymentRequiredBody(mode PaymentRequiredBody) RoutesConfig {
+	if mode == "" {
+		return r
+	}
+
+	routes := make(RoutesConfig, len(r))
+	for pattern, config := range r {
+		if config.PaymentRequiredBody == "" {
+			config.PaymentRequiredBody = mode
+		}
+		routes[pattern] = config
+	}
+	return routes
+}
+
 // CompiledRoute is a parsed route ready for matching
 type CompiledRoute struct {
-	Verb   string
-	Regex  *regexp.Regexp
-	Config RouteConfig
+	Pattern string // key in RoutesConfig
+	Verb    string
+	Regex   *regexp.Regexp
+	Config  RouteConfig
 }
 
 // ============================================================================
@@ -145,6 +258,12 @@ type HTTPProcessResult struct {
 	Response            *HTTPResponseInstructions
 	PaymentPayload      *types.PaymentPayload      // V2 only
 	PaymentRequirements *types.PaymentRequirements // V2 only
+	SettlementTiming    SettlementTiming           // Route's settlement timing for verified payments
+}
+
+// SettlesBeforeResponse reports whether a verified payment must be settled before the handler runs
+func (r HTTPProcessResult) SettlesBeforeResponse() bool {
+	return r.SettlementTiming == SettlementTimingBeforeResponse
 }
 
 // Result type constants
@@ -196,9 +315,10 @@ func Wrappedx402HTTPResourceServer(routes RoutesConfig, resourceServer *x402.X40
 	for pattern, config := range normalizedRoutes {
 		verb, regex := parseRoutePattern(pattern)
 		server.compiledRoutes = append(server.compiledRoutes, CompiledRoute{
-			Verb:   verb,
-			Regex:  regex,
-			Config: config,
+			Pattern: pattern,
+			Verb:    verb,
+			Regex:   regex,
+			Config:  config,
 		})
 	}
 
@@ -247,7 +367,7 @@ func (s *x402HTTPResourceServer) BuildPaymentRequirementsFromOptions(ctx context
 			}
 			resolvedPrice = price
 		} else {
-			// It's a static value (string, number, or AssetAmount)
+			// It's a static value (string, number, AssetAmount, or UnitPrice)
 			resolvedPrice = option.Price
 		}
 
@@ -288,13 +408,32 @@ func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx
 
 	// Check for payment header (V2 only)
 	typedPayload, err := s.extractPaymentV2(reqCtx.Adapter)
+
+	// Trace the payment check, joining the client's trace carried in the payload
+	tracer := s.Tracer()
+	if typedPayload != nil {
+		ctx = x402.ExtractTraceContext(ctx, tracer, typedPayload.Extensions)
+	}
+	ctx, span := x402.StartSpan(ctx, tracer, "x402.server.ProcessHTTPRequest")
+	defer span.End()
+
 	if err != nil {
+		span.SetFailure(spanReasonInvalidPaymentHeader, err)
 		return HTTPProcessResult{
 			Type:     ResultPaymentError,
 			Response: &HTTPResponseInstructions{Status: 400, Body: map[string]string{"error": "Invalid payment"}},
 		}
 	}
 
+	extensions := s.EnrichExtensions(routeConfig.Extensions, reqCtx)
+
+	if routeConfig.ValidateInput {
+		if response := validateInput(reqCtx, extensions); response != nil {
+			span.SetFailure(spanReasonInvalidInput, nil)
+			return HTTPProcessResult{Type: ResultPaymentError, Response: response}
+		}
+	}
+
 	// Build requirements from all payment options (resolves dynamic values inline)
 	requirements, err := s.BuildPaymentRequirementsFromOptions(ctx, paymentOptions, reqCtx)
 	if err != nil {
@@ -322,18 +461,13 @@ func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx
 		requirements[i].Extra["resourceUrl"] = resourceInfo.URL
 	}
 
-	extensions := routeConfig.Extensions
-	// TODO: Add EnrichExtensions method if needed
-	// if extensions != nil && len(extensions) > 0 {
-	// 	extensions = s.EnrichExtensions(extensions, reqCtx)
-	// }
-
 	if typedPayload == nil {
+		// Let the client continue this trace when it pays
 		paymentRequired := s.CreatePaymentRequiredResponse(
 			requirements,
 			resourceInfo,
 			"Payment required",
-			extensions,
+			x402.InjectTraceContext(ctx, tracer, extensions),
 		)
 
 		// Call the UnpaidResponseBody callback if provided
@@ -351,6 +485,8 @@ func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx
 				}
 			}
 			unpaidResponse = unpaidResp
+		} else if wantsPaymentRequiredBody(routeConfig.PaymentRequiredBody, reqCtx.Adapter) {
+			unpaidResponse = &UnpaidResponse{ContentType: "application/json", Body: paymentRequired}
 		}
 
 		return HTTPProcessResult{
@@ -365,9 +501,14 @@ func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx
 		}
 	}
 
+	span.SetAttribute(x402.AttrScheme, typedPayload.Accepted.Scheme)
+	span.SetAttribute(x402.AttrNetwork, typedPayload.Accepted.Network)
+	span.SetAttribute(x402.AttrAmount, typedPayload.Accepted.Amount)
+
 	// Find matching requirements (type-safe)
 	matchingReqs := s.FindMatchingRequirements(requirements, *typedPayload)
 	if matchingReqs == nil {
+		span.SetFailure(spanReasonNoMatchingRequirements, nil)
 		paymentRequired := s.CreatePaymentRequiredResponse(
 			requirements,
 			resourceInfo,
@@ -382,8 +523,12 @@ func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx
 	}
 
 	// Verify payment (type-safe)
-	_, verifyErr := s.VerifyPayment(ctx, *typedPayload, *matchingReqs)
+	verifyResult, verifyErr := s.VerifyPayment(ctx, *typedPayload, *matchingReqs)
+	if verifyResult != nil && verifyResult.Payer != "" {
+		span.SetAttribute(x402.AttrPayer, verifyResult.Payer)
+	}
 	if verifyErr != nil {
+		span.SetFailure(errorReason(verifyErr), verifyErr)
 		err = verifyErr
 		errorMsg := err.Error()
 
@@ -405,6 +550,7 @@ func (s *x402HTTPResourceServer) ProcessHTTPRequest(ctx context.Context, reqCtx
 		Type:                ResultPaymentVerified,
 		PaymentPayload:      typedPayload,
 		PaymentRequirements: matchingReqs,
+		SettlementTiming:    routeConfig.SettlementTiming,
 	}
 }
 
@@ -416,16 +562,32 @@ func (s *x402HTTPResourceServer) RequiresPayment(reqCtx HTTPRequestContext) bool
 
 // ProcessSettlement handles settlement after successful response
 func (s *x402HTTPResourceServer) ProcessSettlement(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) *ProcessSettleResult {
+	tracer := s.Tracer()
+	ctx = x402.ExtractTraceContext(ctx, tracer, payload.Extensions)
+	ctx, span := x402.StartSpan(ctx, tracer, "x402.server.ProcessSettlement")
+	defer span.End()
+	span.SetAttribute(x402.AttrScheme, requirements.Scheme)
+	span.SetAttribute(x402.AttrNetwork, requirements.Network)
+	span.SetAttribute(x402.AttrAmount, requirements.Amount)
+
+	// Tag the settlement so HTTP facilitators can deduplicate retries of the same payment
+	ctx = WithIdempotencyKey(ctx, IdempotencyKey(payload, requirements))
+
 	// Settle payment (type-safe, no marshal needed)
 	settleResult, err := s.SettlePayment(ctx, payload, requirements)
 	if err != nil {
+		span.SetFailure(errorReason(err), err)
 		return &ProcessSettleResult{
 			Success:     false,
 			ErrorReason: err.Error(),
 		}
 	}
 
+	if settleResult.Payer != "" {
+		span.SetAttribute(x402.AttrPayer, settleResult.Payer)
+	}
 	if !settleResult.Success {
+		span.SetFailure(settleResult.ErrorReason, nil)
 		return &ProcessSettleResult{
 			Success:     false,
 			ErrorReason: settleResult.ErrorReason,
@@ -445,7 +607,28 @@ func (s *x402HTTPResourceServer) ProcessSettlement(ctx context.Context, payload
 // Helper Methods
 // ============================================================================
 
-// getRouteConfig finds matching route configuration
+// Span failure reasons for requests rejected before reaching the facilitator
+const (
+	spanReasonInvalidPaymentHeader   = "invalid_payment_header"
+	spanReasonNoMatchingRequirements = "no_matching_requirements"
+	spanReasonInvalidInput           = "invalid_input"
+)
+
+// errorReason returns the reason of a VerifyError or SettleError, or the error text otherwise
+func errorReason(err error) string {
+	var ve *x402.VerifyError
+	if errors.As(err, &ve) {
+		return ve.Reason
+	}
+	var se *x402.SettleError
+	if errors.As(err, &se) {
+		return se.Reason
+	}
+	return err.Error()
+}
+
+// getRouteConfig finds matching route configuration, with the route's discovery
+// declaration (see DeclareDiscovery) added to its extensions
 func (s *x402HTTPResourceServer) getRouteConfig(path, method string) *RouteConfig {
 	normalizedPath := normalizePath(path)
 	upperMethod := strings.ToUpper(method)
@@ -454,6 +637,14 @@ func (s *x402HTTPResourceServer) getRouteConfig(path, method string) *RouteConfi
 		if route.Regex.MatchString(normalizedPath) &&
 			(route.Verb == "*" || route.Verb == upperMethod) {
 			config := route.Config // Make a copy
+			if declaration, ok := s.DiscoveryDeclaration(route.Pattern); ok {
+				extensions := make(map[string]interface{}, len(config.Extensions)+1)
+				for key, value := range config.Extensions {
+					extensions[key] = value
+				}
+				extensions[exttypes.BAZAAR] = declaration
+				config.Extensions = extensions
+			}
 			return &config
 		}
 	}
@@ -464,7 +655,7 @@ func (s *x402HTTPResourceServer) getRouteConfig(path, method string) *RouteConfi
 // extractPaymentV2 extracts V2 payment from headers (V2 only)
 func (s *x402HTTPResourceServer) extractPaymentV2(adapter HTTPAdapter) (*types.PaymentPayload, error) {
 	// Check v2 header
-	header := adapter.GetHeader("PAYMENT-SIGNATURE")
+	header := adapter.GetHeader(PaymentSignatureHeader)
 	if header == "" {
 		header = adapter.GetHeader("payment-signature")
 	}
@@ -530,6 +721,23 @@ func (s *x402HTTPResourceServer) isWebBrowser(adapter HTTPAdapter) bool {
 	return strings.Contains(accept, "text/html") && strings.Contains(userAgent, "Mozilla")
 }
 
+// wantsPaymentRequiredBody reports whether a 402 should carry the PaymentRequired structure
+// as its body under mode
+func wantsPaymentRequiredBody(mode PaymentRequiredBody, adapter HTTPAdapter) bool {
+	switch mode {
+	case PaymentRequiredBodyAlways:
+		return true
+	case PaymentRequiredBodyWhenAccepted:
+		for _, mediaRange := range strings.Split(adapter.GetAcceptHeader(), ",") {
+			mediaType, _, _ := strings.Cut(mediaRange, ";")
+			if strings.EqualFold(strings.TrimSpace(mediaType), "application/json") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // createHTTPResponseV2 creates response instructions for V2 PaymentRequired
 //
 // Args:
@@ -598,6 +806,14 @@ func (s *x402HTTPResourceServer) generatePaywallHTMLV2(paymentRequired types.Pay
 		return customHTML
 	}
 
+	if config != nil && config.Template != nil {
+		page, err := renderPaywallTemplate(paymentRequired, config)
+		if err == nil {
+			return page
+		}
+		s.Logger().Warn("paywall template failed, serving the default paywall", "error", err)
+	}
+
 	// Convert V2 to generic format to reuse existing HTML generation
 	genericRequired := x402.PaymentRequired{
 		X402Version: paymentRequired.X402Version,
@@ -632,13 +848,40 @@ func (s *x402HTTPResourceServer) generatePaywallHTMLV2(paymentRequired types.Pay
 	return s.generatePaywallHTML(genericRequired, config, customHTML)
 }
 
+// renderPaywallTemplate executes the configured paywall template
+func renderPaywallTemplate(paymentRequired types.PaymentRequired, config *PaywallConfig) (string, error) {
+	requiredJSON, err := json.Marshal(paymentRequired)
+	if err != nil {
+		return "", err
+	}
+
+	data := &PaywallTemplateData{
+		PaymentRequired:     paymentRequired,
+		PaymentRequiredJSON: string(requiredJSON),
+		Resource:            paymentRequired.Resource,
+		Config:              *config,
+	}
+	for _, requirements := range paymentRequired.Accepts {
+		data.Options = append(data.Options, PaywallOption{
+			PaymentRequirements: requirements,
+			DisplayAmount:       displayAmount(requirements.Amount, requirements.Extra),
+		})
+	}
+
+	var page bytes.Buffer
+	if err := config.Template.Execute(&page, data); err != nil {
+		return "", err
+	}
+	return page.String(), nil
+}
+
 // generatePaywallHTML generates HTML paywall for browsers
 func (s *x402HTTPResourceServer) generatePaywallHTML(paymentRequired x402.PaymentRequired, config *PaywallConfig, customHTML string) string {
 	if customHTML != "" {
 		return customHTML
 	}
 
-	// Calculate display amount (assuming USDC with 6 decimals)
+	// Calculate display amount in whole tokens
 	displayAmount := s.getDisplayAmount(paymentRequired)
 
 	resourceDesc := ""
@@ -743,34 +986,55 @@ func (s *x402HTTPResourceServer) generatePaywallHTML(paymentRequired x402.Paymen
 func (s *x402HTTPResourceServer) getDisplayAmount(paymentRequired x402.PaymentRequired) float64 {
 	if len(paymentRequired.Accepts) > 0 {
 		firstReq := paymentRequired.Accepts[0]
-		// Check if amount field exists
-		if firstReq.Amount != "" {
-			// V2 format - parse amount
-			amount, err := strconv.ParseFloat(firstReq.Amount, 64)
-			if err == nil {
-				// Assuming USDC with 6 decimals
-				return amount / 1000000
-			}
-		}
+		return displayAmount(firstReq.Amount, firstReq.Extra)
 	}
 	return 0.0
 }
 
+// displayAmount converts an amount in atomic units to whole tokens
+func displayAmount(amount string, extra map[string]interface{}) float64 {
+	value, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return 0.0
+	}
+
+	// Use the decimals advertised by the mechanism, assuming USDC with 6 decimals otherwise
+	decimals := 6.0
+	switch d := extra[x402.ExtraDecimals].(type) {
+	case int:
+		decimals = float64(d)
+	case float64:
+		decimals = d
+	}
+	return value / math.Pow(10, decimals)
+}
+
 // ============================================================================
 // Utility Functions
 // ============================================================================
 
-// parseRoutePattern parses a route pattern like "GET /api/*"
+// routeMethods are the HTTP methods a route pattern can be prefixed with
+var routeMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"OPTIONS": true,
+}
+
+// parseRoutePattern parses a route pattern like "GET /api/*" or "DELETE /items/:id".
+// The method prefix is case-insensitive; a bare path (or "*" as the method) matches any method.
 func parseRoutePattern(pattern string) (string, *regexp.Regexp) {
 	parts := strings.Fields(pattern)
 
-	var verb, path string
+	verb, path := "*", strings.TrimSpace(pattern)
 	if len(parts) == 2 {
-		verb = strings.ToUpper(parts[0])
-		path = parts[1]
-	} else {
-		verb = "*"
-		path = pattern
+		if method := strings.ToUpper(parts[0]); method == "*" || routeMethods[method] {
+			verb = method
+			path = parts[1]
+		}
 	}
 
 	// Convert pattern to regex
@@ -779,6 +1043,9 @@ func parseRoutePattern(pattern string) (string, *regexp.Regexp) {
 	// Handle parameters like [id]
 	paramRegex := regexp.MustCompile(`\\\[([^\]]+)\\\]`)
 	regexPattern = paramRegex.ReplaceAllString(regexPattern, `[^/]+`)
+	// Handle parameters like :id
+	namedParamRegex := regexp.MustCompile(`/:[A-Za-z_][A-Za-z0-9_]*`)
+	regexPattern = namedParamRegex.ReplaceAllString(regexPattern, `/[^/]+`)
 	regexPattern += "$"
 
 	regex := regexp.MustCompile(regexPattern)
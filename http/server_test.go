@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"html/template"
 	"strings"
 	"testing"
 
@@ -263,6 +264,114 @@ func TestProcessHTTPRequestWithBrowser(t *testing.T) {
 	}
 }
 
+func TestProcessHTTPRequestPaymentRequiredBody(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		mode     PaymentRequiredBody
+		accept   string
+		agent    string
+		wantBody bool
+	}{
+		{name: "default header only", accept: "application/json"},
+		{name: "never", mode: PaymentRequiredBodyNever, accept: "application/json"},
+		{name: "accept json", mode: PaymentRequiredBodyWhenAccepted, accept: "text/plain, application/json;q=0.9", wantBody: true},
+		{name: "accept anything", mode: PaymentRequiredBodyWhenAccepted, accept: "*/*"},
+		{name: "always", mode: PaymentRequiredBodyAlways, accept: "*/*", wantBody: true},
+		{name: "browser gets paywall", mode: PaymentRequiredBodyAlways, accept: "text/html", agent: "Mozilla/5.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			routes := RoutesConfig{
+				"GET /api": {
+					Accepts:             PaymentOptions{{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"}},
+					PaymentRequiredBody: tt.mode,
+				},
+			}
+			server := Newx402HTTPResourceServer(
+				routes,
+				x402.WithFacilitatorClient(&mockFacilitatorClient{}),
+				x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+			)
+			server.Initialize(ctx)
+
+			adapter := &mockHTTPAdapter{method: "GET", path: "/api", url: "http://example.com/api", accept: tt.accept, agent: tt.agent}
+			result := server.ProcessHTTPRequest(ctx, HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil)
+			if result.Response == nil || result.Response.Status != 402 {
+				t.Fatalf("Expected 402 response, got %+v", result.Response)
+			}
+			if result.Response.IsHTML {
+				if tt.wantBody {
+					t.Fatal("Expected JSON body, got paywall HTML")
+				}
+				return
+			}
+			if result.Response.Headers["PAYMENT-REQUIRED"] == "" {
+				t.Error("Expected PAYMENT-REQUIRED header")
+			}
+
+			body, ok := result.Response.Body.(types.PaymentRequired)
+			if ok != tt.wantBody {
+				t.Fatalf("Expected PaymentRequired body: %v, got %#v", tt.wantBody, result.Response.Body)
+			}
+			if ok && (body.X402Version != 2 || len(body.Accepts) != 1) {
+				t.Errorf("Unexpected PaymentRequired body: %+v", body)
+			}
+		})
+	}
+}
+
+func TestRoutesConfigWithPaymentRequiredBody(t *testing.T) {
+	routes := RoutesConfig{
+		"GET /a": {},
+		"GET /b": {PaymentRequiredBody: PaymentRequiredBodyNever},
+	}
+
+	withBody := routes.WithPaymentRequiredBody(PaymentRequiredBodyAlways)
+	if withBody["GET /a"].PaymentRequiredBody != PaymentRequiredBodyAlways {
+		t.Errorf("Expected default to apply to unset route, got %q", withBody["GET /a"].PaymentRequiredBody)
+	}
+	if withBody["GET /b"].PaymentRequiredBody != PaymentRequiredBodyNever {
+		t.Errorf("Expected route setting to win, got %q", withBody["GET /b"].PaymentRequiredBody)
+	}
+	if routes["GET /a"].PaymentRequiredBody != "" {
+		t.Error("Expected original routes to be left unchanged")
+	}
+}
+
+func TestGeneratePaywallHTMLV2Template(t *testing.T) {
+	server := Newx402HTTPResourceServer(RoutesConfig{})
+	paymentRequired := types.PaymentRequired{
+		X402Version: 2,
+		Resource:    &types.ResourceInfo{URL: "https://example.com/report", Description: "Report"},
+		Accepts: []types.PaymentRequirements{
+			{Scheme: "exact", Network: "eip155:8453", Asset: "0xusdc", Amount: "1500000", PayTo: "0xrecipient"},
+			{Scheme: "exact", Network: "eip155:1", Asset: "0xtoken", Amount: "2500", PayTo: "0xother", Extra: map[string]interface{}{x402.ExtraDecimals: float64(3)}},
+		},
+	}
+
+	tmpl := template.Must(template.New("paywall").Parse(
+		`{{.Resource.Description}}|{{range .Options}}{{.PayTo}}:{{printf "%.2f" .DisplayAmount}};{{end}}|{{.Config.AppName}}`))
+	page := server.generatePaywallHTMLV2(paymentRequired, (&PaywallConfig{AppName: "<Shop>"}).WithTemplate(tmpl), "")
+	if want := "Report|0xrecipient:1.50;0xother:2.50;|&lt;Shop&gt;"; page != want {
+		t.Errorf("Expected %q, got %q", want, page)
+	}
+
+	// A failing template falls back to the built-in page
+	broken := template.Must(template.New("paywall").Parse(`{{.Missing}}`))
+	page = server.generatePaywallHTMLV2(paymentRequired, (*PaywallConfig)(nil).WithTemplate(broken), "")
+	if !strings.Contains(page, "Payment Required") {
+		t.Errorf("Expected default paywall when the template fails, got %q", page)
+	}
+
+	// Per-route custom HTML still wins
+	if page := server.generatePaywallHTMLV2(paymentRequired, (&PaywallConfig{}).WithTemplate(tmpl), "<p>custom</p>"); page != "<p>custom</p>" {
+		t.Errorf("Expected custom route HTML, got %q", page)
+	}
+}
+
 func TestProcessHTTPRequestWithPaymentVerified(t *testing.T) {
 	ctx := context.Background()
 
@@ -368,6 +477,20 @@ func TestProcessHTTPRequestWithPaymentVerified(t *testing.T) {
 	if result.PaymentRequirements == nil {
 		t.Error("Expected payment requirements")
 	}
+	if result.SettlesBeforeResponse() {
+		t.Error("Expected settlement after the response by default")
+	}
+
+	// Routes opting into settle-before-respond carry the timing on the result
+	server.compiledRoutes[0].Config.SettlementTiming = SettlementTimingBeforeResponse
+
+	result = server.ProcessHTTPRequest(ctx, reqCtx, nil)
+	if result.Type != ResultPaymentVerified {
+		t.Fatalf("Expected payment verified, got %s", result.Type)
+	}
+	if !result.SettlesBeforeResponse() {
+		t.Error("Expected settlement before the response")
+	}
 }
 
 func TestProcessSettlement(t *testing.T) {
@@ -454,6 +577,36 @@ func TestParseRoutePattern(t *testing.T) {
 			testPath:    "/api/123",
 			shouldMatch: true,
 		},
+		{
+			pattern:     "DELETE /resource/:id",
+			expectVerb:  "DELETE",
+			testPath:    "/resource/42",
+			shouldMatch: true,
+		},
+		{
+			pattern:     "DELETE /resource/:id",
+			expectVerb:  "DELETE",
+			testPath:    "/resource/42/owner",
+			shouldMatch: false,
+		},
+		{
+			pattern:     "HEAD /api",
+			expectVerb:  "HEAD",
+			testPath:    "/api",
+			shouldMatch: true,
+		},
+		{
+			pattern:     "patch /api/:id/name",
+			expectVerb:  "PATCH",
+			testPath:    "/api/7/name",
+			shouldMatch: true,
+		},
+		{
+			pattern:     "PUT  /api",
+			expectVerb:  "PUT",
+			testPath:    "/api",
+			shouldMatch: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -472,6 +625,45 @@ func TestParseRoutePattern(t *testing.T) {
 	}
 }
 
+func TestGetRouteConfigMethods(t *testing.T) {
+	server := Newx402HTTPResourceServer(RoutesConfig{
+		"DELETE /resource/:id": {Description: "delete"},
+		"HEAD /status":         {Description: "head"},
+		"/open":                {Description: "any"},
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		expect string
+	}{
+		{"DELETE", "/resource/42", "delete"},
+		{"delete", "/resource/42", "delete"},
+		{"GET", "/resource/42", ""},
+		{"DELETE", "/resource", ""},
+		{"HEAD", "/status", "head"},
+		{"GET", "/status", ""},
+		{"GET", "/open", "any"},
+		{"DELETE", "/open", "any"},
+		{"PATCH", "/open", "any"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			config := server.getRouteConfig(tt.path, tt.method)
+			if tt.expect == "" {
+				if config != nil {
+					t.Errorf("Expected no route, got %q", config.Description)
+				}
+				return
+			}
+			if config == nil || config.Description != tt.expect {
+				t.Errorf("Expected route %q, got %+v", tt.expect, config)
+			}
+		})
+	}
+}
+
 func TestNormalizePath(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -522,6 +714,15 @@ func TestGetDisplayAmount(t *testing.T) {
 			},
 			expected: 0.1,
 		},
+		{
+			name: "Advertised 18 decimals",
+			required: x402.PaymentRequired{
+				Accepts: []x402.PaymentRequirements{
+					{Amount: "2500000000000000000", Extra: map[string]interface{}{"decimals": float64(18)}},
+				},
+			},
+			expected: 2.5,
+		},
 		{
 			name: "Invalid amount",
 			required: x402.PaymentRequired{
@@ -605,3 +806,107 @@ func (m *mockFacilitatorClient) GetSupported(ctx context.Context) (x402.Supporte
 func (m *mockFacilitatorClient) Identifier() string {
 	return "mock"
 }
+
+// stubTracer propagates span names as "traceparent" and records span parents
+type stubTracer struct {
+	parents map[string]string // span name -> parent span
+}
+
+type stubSpanKey struct{}
+
+type stubSpan struct{}
+
+func (stubSpan) SetAttribute(string, string) {}
+func (stubSpan) SetFailure(string, error)    {}
+func (stubSpan) End()                        {}
+
+func (t *stubTracer) Start(ctx context.Context, name string) (context.Context, x402.Span) {
+	parent, _ := ctx.Value(stubSpanKey{}).(string)
+	t.parents[name] = parent
+	return context.WithValue(ctx, stubSpanKey{}, name), stubSpan{}
+}
+
+func (t *stubTracer) Inject(ctx context.Context, carrier map[string]string) {
+	if name, ok := ctx.Value(stubSpanKey{}).(string); ok {
+		carrier["traceparent"] = name
+	}
+}
+
+func (t *stubTracer) Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if _, ok := ctx.Value(stubSpanKey{}).(string); ok || carrier["traceparent"] == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, stubSpanKey{}, carrier["traceparent"])
+}
+
+func TestProcessHTTPRequestTraceContext(t *testing.T) {
+	ctx := context.Background()
+	tracer := &stubTracer{parents: make(map[string]string)}
+
+	routes := RoutesConfig{
+		"GET /api": {
+			Accepts: PaymentOptions{
+				{Scheme: "exact", PayTo: "0xtest", Price: "$1.00", Network: "eip155:1"},
+			},
+		},
+	}
+
+	var forwarded map[string]interface{}
+	mockClient := &mockFacilitatorClient{
+		verify: func(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*x402.VerifyResponse, error) {
+			var payload types.PaymentPayload
+			_ = json.Unmarshal(payloadBytes, &payload)
+			forwarded = payload.Extensions
+			return &x402.VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+	}
+
+	server := Newx402HTTPResourceServer(
+		routes,
+		x402.WithFacilitatorClient(mockClient),
+		x402.WithSchemeServer("eip155:1", &mockSchemeServer{scheme: "exact"}),
+		x402.WithServerTracer(tracer),
+	)
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	// The 402 response carries the server's trace context
+	adapter := &mockHTTPAdapter{method: "GET", path: "/api", url: "http://example.com/api", accept: "application/json"}
+	result := server.ProcessHTTPRequest(ctx, HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil)
+	if result.Response == nil || result.Response.Status != 402 {
+		t.Fatalf("Expected 402, got %+v", result.Response)
+	}
+	required, err := decodePaymentRequiredHeader(result.Response.Headers["PAYMENT-REQUIRED"])
+	if err != nil {
+		t.Fatalf("Failed to decode PAYMENT-REQUIRED: %v", err)
+	}
+	traceContext, _ := required.Extensions[x402.TraceContextExtension].(map[string]interface{})
+	if traceContext["traceparent"] != "x402.server.ProcessHTTPRequest" {
+		t.Errorf("Expected trace context in PAYMENT-REQUIRED, got %v", required.Extensions)
+	}
+
+	// A paid request joins the client's trace and hands the server's span on to the facilitator
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted:    required.Accepts[0],
+		Payload:     map[string]interface{}{"signature": "0x"},
+		Extensions: map[string]interface{}{
+			x402.TraceContextExtension: map[string]interface{}{"traceparent": "client-span"},
+		},
+	}
+	payloadBytes, _ := json.Marshal(payload)
+	adapter.headers = map[string]string{"PAYMENT-SIGNATURE": base64.StdEncoding.EncodeToString(payloadBytes)}
+
+	result = server.ProcessHTTPRequest(ctx, HTTPRequestContext{Adapter: adapter, Path: "/api", Method: "GET"}, nil)
+	if result.Type != ResultPaymentVerified {
+		t.Fatalf("Expected payment verified, got %s", result.Type)
+	}
+	if parent := tracer.parents["x402.server.ProcessHTTPRequest"]; parent != "client-span" {
+		t.Errorf("Expected the server span to join the client trace, got parent %q", parent)
+	}
+	forwardedContext, _ := forwarded[x402.TraceContextExtension].(map[string]interface{})
+	if forwardedContext["traceparent"] != "x402.server.ProcessHTTPRequest" {
+		t.Errorf("Expected the facilitator to receive the server span, got %v", forwarded)
+	}
+}
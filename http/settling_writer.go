@@ -0,0 +1,132 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// ============================================================================
+// Settling Writer
+// ============================================================================
+
+// SettlingWriter defers the response status until settlement completes.
+// When the handler commits a success status, settle runs first so the
+// PAYMENT-RESPONSE header can still be added; the body is then streamed
+// through unbuffered. Error statuses (>= 400) pass through unsettled. If
+// settlement fails, settle writes the failure response and the handler's
+// body is discarded.
+//
+// The framework middlewares wrap the protected handler's writer in one.
+type SettlingWriter struct {
+	http.ResponseWriter
+	settle      func(http.ResponseWriter) bool
+	wroteHeader bool
+	discard     bool
+	mu          sync.Mutex
+}
+
+// NewSettlingWriter wraps w so that settle runs before the first success status.
+// settle receives w and returns false after writing a settlement failure response to it.
+func NewSettlingWriter(w http.ResponseWriter, settle func(http.ResponseWriter) bool) *SettlingWriter {
+	return &SettlingWriter{
+		ResponseWriter: w,
+		settle:         settle,
+	}
+}
+
+// WriteHeader settles the payment before committing a success status
+func (w *SettlingWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writeHeaderLocked(code)
+}
+
+// writeHeaderLocked commits the status (must be called with lock held)
+func (w *SettlingWriter) writeHeaderLocked(code int) {
+	if w.wroteHeader {
+		return
+	}
+
+	// Informational responses don't commit the final status
+	if code >= 100 && code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.wroteHeader = true
+
+	if code >= 400 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	if !w.settle(w.ResponseWriter) {
+		w.discard = true
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Commit commits an implicit 200 if no status was set yet. It reports whether the
+// handler's response is passed through, i.e. false once settlement has failed.
+func (w *SettlingWriter) Commit() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.wroteHeader {
+		w.writeHeaderLocked(http.StatusOK)
+	}
+	return !w.discard
+}
+
+// Write commits an implicit 200 on first use, then passes the body through
+func (w *SettlingWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.wroteHeader {
+		w.writeHeaderLocked(http.StatusOK)
+	}
+	if w.discard {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString commits an implicit 200 on first use, then passes the string through
+func (w *SettlingWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.wroteHeader {
+		w.writeHeaderLocked(http.StatusOK)
+	}
+	if w.discard {
+		return len(s), nil
+	}
+	return io.WriteString(w.ResponseWriter, s)
+}
+
+// Flush implements http.Flusher, committing the status first if needed
+func (w *SettlingWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.wroteHeader {
+		w.writeHeaderLocked(http.StatusOK)
+	}
+	if w.discard {
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController
+func (w *SettlingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSettlingWriter(t *testing.T) {
+	t.Run("settles before committing success status", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		settleCalls := 0
+		writer := NewSettlingWriter(recorder, func(w http.ResponseWriter) bool {
+			settleCalls++
+			w.Header().Set("PAYMENT-RESPONSE", "settled")
+			return true
+		})
+
+		writer.WriteHeader(http.StatusCreated)
+		writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = writer.Write([]byte("body"))
+
+		if settleCalls != 1 {
+			t.Errorf("Expected settle to be called once, got %d", settleCalls)
+		}
+		if recorder.Code != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d", recorder.Code)
+		}
+		if recorder.Header().Get("PAYMENT-RESPONSE") != "settled" {
+			t.Error("Expected PAYMENT-RESPONSE header to be set before the status")
+		}
+		if recorder.Body.String() != "body" {
+			t.Errorf("Expected body 'body', got '%s'", recorder.Body.String())
+		}
+	})
+
+	t.Run("settles once on first write", func(t *testing.T) {
+		calls := 0
+		recorder := httptest.NewRecorder()
+		writer := NewSettlingWriter(recorder, func(w http.ResponseWriter) bool {
+			calls++
+			w.Header().Set("PAYMENT-RESPONSE", "settled")
+			return true
+		})
+
+		_, _ = writer.Write([]byte("a"))
+		_, _ = writer.WriteString("b")
+		writer.WriteHeader(http.StatusInternalServerError)
+
+		if calls != 1 {
+			t.Errorf("Expected settle to run once, ran %d times", calls)
+		}
+		if recorder.Code != http.StatusOK || recorder.Body.String() != "ab" {
+			t.Errorf("Expected 200 'ab', got %d '%s'", recorder.Code, recorder.Body.String())
+		}
+		if recorder.Header().Get("PAYMENT-RESPONSE") != "settled" {
+			t.Error("Expected header injected before status")
+		}
+	})
+
+	t.Run("passes error status through unsettled", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		settleCalled := false
+		writer := NewSettlingWriter(recorder, func(w http.ResponseWriter) bool {
+			settleCalled = true
+			return true
+		})
+
+		writer.WriteHeader(http.StatusBadGateway)
+
+		if settleCalled {
+			t.Error("Settlement should NOT be called for error statuses")
+		}
+		if recorder.Code != http.StatusBadGateway {
+			t.Errorf("Expected status 502, got %d", recorder.Code)
+		}
+	})
+
+	t.Run("passes informational status through", func(t *testing.T) {
+		calls := 0
+		writer := NewSettlingWriter(httptest.NewRecorder(), func(w http.ResponseWriter) bool {
+			calls++
+			return true
+		})
+
+		writer.WriteHeader(http.StatusEarlyHints)
+		if calls != 0 || writer.wroteHeader {
+			t.Error("Informational status must not trigger settlement")
+		}
+		writer.WriteHeader(http.StatusOK)
+		if calls != 1 {
+			t.Errorf("Expected settle to run once, ran %d times", calls)
+		}
+	})
+
+	t.Run("discards body after failed settlement", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		writer := NewSettlingWriter(recorder, func(w http.ResponseWriter) bool {
+			w.WriteHeader(http.StatusPaymentRequired)
+			_, _ = w.Write([]byte("failed"))
+			return false
+		})
+
+		n, err := writer.Write([]byte("protected"))
+		if err != nil || n != len("protected") {
+			t.Errorf("Expected discarded write to report success, got %d, %v", n, err)
+		}
+		if recorder.Code != http.StatusPaymentRequired || recorder.Body.String() != "failed" {
+			t.Errorf("Expected 402 'failed', got %d '%s'", recorder.Code, recorder.Body.String())
+		}
+	})
+
+	t.Run("commit reports whether the response passes through", func(t *testing.T) {
+		settled := NewSettlingWriter(httptest.NewRecorder(), func(w http.ResponseWriter) bool {
+			return true
+		})
+		if !settled.Commit() {
+			t.Error("Expected the response to pass through after settlement")
+		}
+
+		recorder := httptest.NewRecorder()
+		failed := NewSettlingWriter(recorder, func(w http.ResponseWriter) bool {
+			w.WriteHeader(http.StatusPaymentRequired)
+			return false
+		})
+		if failed.Commit() {
+			t.Error("Expected the response to be discarded after failed settlement")
+		}
+		if recorder.Code != http.StatusPaymentRequired {
+			t.Errorf("Expected status 402, got %d", recorder.Code)
+		}
+	})
+}
@@ -0,0 +1,419 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	x402 "x402-go"
+)
+
+// ============================================================================
+// Settlement Webhooks
+// ============================================================================
+
+// Headers sent with every webhook delivery
+const (
+	// WebhookIDHeader carries the event ID, the same for every attempt, so receivers can
+	// drop duplicates
+	WebhookIDHeader = "X-X402-Webhook-Id"
+
+	// WebhookTimestampHeader carries the Unix time the attempt was signed at
+	WebhookTimestampHeader = "X-X402-Webhook-Timestamp"
+
+	// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the timestamp,
+	// a ".", and the body (see VerifyWebhookSignature)
+	WebhookSignatureHeader = "X-X402-Webhook-Signature"
+)
+
+// WebhookEventSettled is the type of the event sent when a payment settles
+const WebhookEventSettled = "payment.settled"
+
+const (
+	// DefaultWebhookTimeout bounds each delivery attempt
+	DefaultWebhookTimeout = 10 * time.Second
+
+	// DefaultWebhookQueueSize is how many events can wait for delivery
+	DefaultWebhookQueueSize = 1000
+
+	// DefaultWebhookLogSize is how many finished deliveries Deliveries returns
+	DefaultWebhookLogSize = 100
+
+	// DefaultWebhookMaxAttempts is the default total number of attempts per event
+	DefaultWebhookMaxAttempts = 5
+
+	// DefaultWebhookBaseDelay is the default delay before the first retry
+	DefaultWebhookBaseDelay = time.Second
+
+	// DefaultWebhookMaxDelay is the default cap on the backoff delay
+	DefaultWebhookMaxDelay = time.Minute
+)
+
+// ErrWebhookQueueFull is returned by OnAfterSettle when the event can't be queued
+var ErrWebhookQueueFull = errors.New("webhook queue full")
+
+// WebhookEvent is the JSON body of a webhook delivery
+type WebhookEvent struct {
+	ID          string       `json:"id"`
+	Type        string       `json:"type"`
+	Payer       string       `json:"payer"`
+	Transaction string       `json:"transaction"`
+	Network     x402.Network `json:"network"`
+	Scheme      string       `json:"scheme"`
+	Asset       string       `json:"asset"`
+	Amount      string       `json:"amount"`
+	PayTo       string       `json:"payTo"`
+	Timestamp   int64        `json:"timestamp"` // Unix time of the settlement
+}
+
+// WebhookDelivery records how an event's delivery ended
+type WebhookDelivery struct {
+	Event      WebhookEvent
+	Delivered  bool
+	Attempts   int
+	StatusCode int    // Status of the last response, 0 when none was received
+	Error      string // Why the last attempt failed, empty when delivered
+	Time       time.Time
+}
+
+// WebhookConfig configures a WebhookDispatcher
+type WebhookConfig struct {
+	// URL receives the webhook POSTs
+	URL string
+
+	// Secret signs every delivery
+	Secret []byte
+
+	// HTTPClient is the HTTP client to use (optional)
+	HTTPClient *http.Client
+
+	// Timeout for each attempt (optional, defaults to DefaultWebhookTimeout)
+	Timeout time.Duration
+
+	// RetryPolicy configures retries (optional, defaults to DefaultWebhookMaxAttempts attempts
+	// from DefaultWebhookBaseDelay up to DefaultWebhookMaxDelay). Connection errors, 5xx and 429
+	// responses are retried; other responses end the delivery.
+	RetryPolicy *RetryPolicy
+
+	// QueueSize is how many events can wait for delivery (optional, defaults to DefaultWebhookQueueSize)
+	QueueSize int
+
+	// LogSize is how many finished deliveries are kept (optional, defaults to DefaultWebhookLogSize)
+	LogSize int
+
+	// Logger logs failed deliveries at Warn (optional)
+	Logger x402.Logger
+}
+
+// WebhookDispatcher POSTs a signed WebhookEvent to a URL for every successful settlement.
+// Register its OnAfterSettle as a facilitator hook:
+//
+//	dispatcher, err := x402http.NewWebhookDispatcher(&x402http.WebhookConfig{URL: url, Secret: secret})
+//	facilitator.OnAfterSettle(dispatcher.OnAfterSettle)
+//	defer dispatcher.Close(ctx)
+//
+// Events are delivered one at a time, in settlement order, by a background goroutine, so
+// settlements never wait on the receiver.
+type WebhookDispatcher struct {
+	url         string
+	secret      []byte
+	httpClient  *http.Client
+	retryPolicy *RetryPolicy
+	logger      x402.Logger
+
+	queue chan WebhookEvent
+
+	mu      sync.Mutex
+	log     []WebhookDelivery
+	logSize int
+	closed  bool
+
+	// ctx is canceled when Close gives up waiting for queued deliveries
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewWebhookDispatcher creates a webhook dispatcher and starts delivering
+func NewWebhookDispatcher(config *WebhookConfig) (*WebhookDispatcher, error) {
+	if config == nil || config.URL == "" {
+		return nil, errors.New("webhook URL is required")
+	}
+	if len(config.Secret) == 0 {
+		return nil, errors.New("webhook secret is required")
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		timeout := config.Timeout
+		if timeout == 0 {
+			timeout = DefaultWebhookTimeout
+		}
+		httpClient = &http.Client{
+			Timeout: timeout,
+		}
+	}
+
+	policy := RetryPolicy{
+		MaxAttempts: DefaultWebhookMaxAttempts,
+		BaseDelay:   DefaultWebhookBaseDelay,
+		MaxDelay:    DefaultWebhookMaxDelay,
+	}
+	if config.RetryPolicy != nil {
+		if config.RetryPolicy.MaxAttempts > 0 {
+			policy.MaxAttempts = config.RetryPolicy.MaxAttempts
+		}
+		if config.RetryPolicy.BaseDelay > 0 {
+			policy.BaseDelay = config.RetryPolicy.BaseDelay
+		}
+		if config.RetryPolicy.MaxDelay > 0 {
+			policy.MaxDelay = config.RetryPolicy.MaxDelay
+		}
+	}
+
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultWebhookQueueSize
+	}
+	logSize := config.LogSize
+	if logSize <= 0 {
+		logSize = DefaultWebhookLogSize
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = x402.NoopLogger()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &WebhookDispatcher{
+		url:         config.URL,
+		secret:      config.Secret,
+		httpClient:  httpClient,
+		retryPolicy: &policy,
+		logger:      logger,
+		queue:       make(chan WebhookEvent, queueSize),
+		logSize:     logSize,
+		ctx:         ctx,
+		cancel:      cancel,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go d.run()
+	return d, nil
+}
+
+// OnAfterSettle queues a webhook for a successful settlement. It matches
+// x402.FacilitatorAfterSettleHook and never blocks; when the queue is full the event is
+// dropped, recorded as undelivered, and ErrWebhookQueueFull is returned.
+func (d *WebhookDispatcher) OnAfterSettle(ctx x402.FacilitatorSettleResultContext) error {
+	if ctx.Result == nil || !ctx.Result.Success {
+		return nil
+	}
+
+	event := WebhookEvent{
+		ID:          newWebhookEventID(),
+		Type:        WebhookEventSettled,
+		Payer:       ctx.Result.Payer,
+		Transaction: ctx.Result.Transaction,
+		Network:     ctx.Result.Network,
+		Timestamp:   time.Now().Unix(),
+	}
+	if ctx.Requirements != nil {
+		event.Scheme = ctx.Requirements.GetScheme()
+		event.Asset = ctx.Requirements.GetAsset()
+		event.Amount = ctx.Requirements.GetAmount()
+		event.PayTo = ctx.Requirements.GetPayTo()
+		if event.Network == "" {
+			event.Network = x402.Network(ctx.Requirements.GetNetwork())
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return errors.New("webhook dispatcher closed")
+	}
+	select {
+	case d.queue <- event:
+		return nil
+	default:
+		d.recordLocked(WebhookDelivery{Event: event, Error: ErrWebhookQueueFull.Error(), Time: time.Now()})
+		d.logger.Warn("webhook dropped", "event", event.ID, "transaction", event.Transaction, "error", ErrWebhookQueueFull)
+		return ErrWebhookQueueFull
+	}
+}
+
+// Deliveries returns the most recent finished deliveries, oldest first
+func (d *WebhookDispatcher) Deliveries() []WebhookDelivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]WebhookDelivery(nil), d.log...)
+}
+
+// Close stops accepting events and waits for queued ones to be delivered. If ctx ends
+// first, deliveries still in progress are abandoned and ctx's error is returned.
+func (d *WebhookDispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.stop)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-d.done
+		return ctx.Err()
+	}
+}
+
+// run delivers queued events until Close, then delivers what is left
+func (d *WebhookDispatcher) run() {
+	defer close(d.done)
+	defer d.cancel()
+
+	for {
+		select {
+		case event := <-d.queue:
+			d.deliver(event)
+		case <-d.stop:
+			for {
+				select {
+				case event := <-d.queue:
+					d.deliver(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver POSTs event, retrying per the retry policy, and records the outcome
+func (d *WebhookDispatcher) deliver(event WebhookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		d.record(WebhookDelivery{Event: event, Error: err.Error(), Time: time.Now()})
+		return
+	}
+
+	delivery := WebhookDelivery{Event: event}
+	for attempt := 1; ; attempt++ {
+		delivery.Attempts = attempt
+
+		status, retryAfter, err := d.post(event.ID, body)
+		delivery.StatusCode = status
+		if err == nil {
+			delivery.Delivered = true
+			delivery.Error = ""
+			break
+		}
+		delivery.Error = err.Error()
+
+		retryable := status == 0 || isRetryableStatus(status, true)
+		if !retryable || attempt >= d.retryPolicy.MaxAttempts || d.ctx.Err() != nil {
+			break
+		}
+
+		delay := d.retryPolicy.backoff(attempt)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		select {
+		case <-d.ctx.Done():
+		case <-time.After(delay):
+		}
+		if d.ctx.Err() != nil {
+			break
+		}
+	}
+	delivery.Time = time.Now()
+
+	if !delivery.Delivered {
+		d.logger.Warn("webhook delivery failed",
+			"event", event.ID, "transaction", event.Transaction, "attempts", delivery.Attempts, "error", delivery.Error)
+	}
+	d.record(delivery)
+}
+
+// post makes one delivery attempt, returning the response status (0 when none was received)
+// and any Retry-After delay it asked for
+func (d *WebhookDispatcher) post(id string, body []byte) (int, time.Duration, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, id)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(d.secret, timestamp, body))
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, 0, nil
+}
+
+// record adds a finished delivery to the log
+func (d *WebhookDispatcher) record(delivery WebhookDelivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.recordLocked(delivery)
+}
+
+// recordLocked adds a finished delivery to the log, dropping the oldest beyond logSize.
+// Callers must hold d.mu.
+func (d *WebhookDispatcher) recordLocked(delivery WebhookDelivery) {
+	d.log = append(d.log, delivery)
+	if len(d.log) > d.logSize {
+		d.log = append(d.log[:0], d.log[len(d.log)-d.logSize:]...)
+	}
+}
+
+// SignWebhook returns the WebhookSignatureHeader value for a delivery signed at timestamp
+func SignWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature, the WebhookSignatureHeader of a
+// delivery, matches its timestamp and body. Receivers should also reject timestamps too far
+// from their own clock.
+func VerifyWebhookSignature(secret []byte, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, timestamp, body)), []byte(signature))
+}
+
+// newWebhookEventID returns a random event ID
+func newWebhookEventID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
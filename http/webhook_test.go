@@ -0,0 +1,175 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	x402 "x402-go"
+	"x402-go/types"
+)
+
+// settledContext is the after-settle hook context of a successful settlement
+func settledContext() x402.FacilitatorSettleResultContext {
+	requirements := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:8453",
+		Asset:   "0xUSDC",
+		Amount:  "1000000",
+		PayTo:   "0xrecipient",
+	}
+	return x402.FacilitatorSettleResultContext{
+		FacilitatorSettleContext: x402.FacilitatorSettleContext{
+			Ctx:          context.Background(),
+			Requirements: requirements,
+		},
+		Result: &x402.SettleResponse{Success: true, Payer: "0xpayer", Transaction: "0xabc", Network: "eip155:8453"},
+	}
+}
+
+// newTestDispatcher creates a dispatcher posting to url with fast retries
+func newTestDispatcher(t *testing.T, url string) *WebhookDispatcher {
+	t.Helper()
+	dispatcher, err := NewWebhookDispatcher(&WebhookConfig{
+		URL:         url,
+		Secret:      []byte("secret"),
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("NewWebhookDispatcher failed: %v", err)
+	}
+	return dispatcher
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	t.Run("delivers a signed event", func(t *testing.T) {
+		var event WebhookEvent
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if !VerifyWebhookSignature([]byte("secret"), r.Header.Get(WebhookTimestampHeader), body, r.Header.Get(WebhookSignatureHeader)) {
+				t.Error("Expected a valid signature")
+			}
+			_ = json.Unmarshal(body, &event)
+			if r.Header.Get(WebhookIDHeader) != event.ID {
+				t.Errorf("Expected the event ID header, got %q", r.Header.Get(WebhookIDHeader))
+			}
+		}))
+		defer server.Close()
+
+		dispatcher := newTestDispatcher(t, server.URL)
+		if err := dispatcher.OnAfterSettle(settledContext()); err != nil {
+			t.Fatalf("OnAfterSettle failed: %v", err)
+		}
+		if err := dispatcher.Close(context.Background()); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		if event.Type != WebhookEventSettled || event.Payer != "0xpayer" || event.Transaction != "0xabc" ||
+			event.Network != "eip155:8453" || event.Amount != "1000000" || event.Asset != "0xUSDC" || event.Timestamp == 0 {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+
+		deliveries := dispatcher.Deliveries()
+		if len(deliveries) != 1 || !deliveries[0].Delivered || deliveries[0].Attempts != 1 || deliveries[0].StatusCode != http.StatusOK {
+			t.Errorf("Unexpected deliveries: %+v", deliveries)
+		}
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		dispatcher := newTestDispatcher(t, server.URL)
+		_ = dispatcher.OnAfterSettle(settledContext())
+		_ = dispatcher.Close(context.Background())
+
+		deliveries := dispatcher.Deliveries()
+		if len(deliveries) != 1 || !deliveries[0].Delivered || deliveries[0].Attempts != 3 {
+			t.Errorf("Unexpected deliveries: %+v", deliveries)
+		}
+	})
+
+	t.Run("gives up on client errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		dispatcher := newTestDispatcher(t, server.URL)
+		_ = dispatcher.OnAfterSettle(settledContext())
+		_ = dispatcher.Close(context.Background())
+
+		deliveries := dispatcher.Deliveries()
+		if calls.Load() != 1 || len(deliveries) != 1 || deliveries[0].Delivered || deliveries[0].StatusCode != http.StatusBadRequest {
+			t.Errorf("Unexpected deliveries after %d calls: %+v", calls.Load(), deliveries)
+		}
+	})
+
+	t.Run("ignores failed settlements", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+		}))
+		defer server.Close()
+
+		dispatcher := newTestDispatcher(t, server.URL)
+		failed := settledContext()
+		failed.Result = &x402.SettleResponse{Success: false, ErrorReason: "transaction_failed"}
+		_ = dispatcher.OnAfterSettle(failed)
+		_ = dispatcher.Close(context.Background())
+
+		if calls.Load() != 0 || len(dispatcher.Deliveries()) != 0 {
+			t.Errorf("Expected no delivery, got %d calls", calls.Load())
+		}
+	})
+
+	t.Run("close abandons deliveries when its context ends", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		dispatcher := newTestDispatcher(t, server.URL)
+		_ = dispatcher.OnAfterSettle(settledContext())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := dispatcher.Close(ctx); err != context.DeadlineExceeded {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+		if err := dispatcher.OnAfterSettle(settledContext()); err == nil {
+			t.Error("Expected an error after Close")
+		}
+
+		deliveries := dispatcher.Deliveries()
+		if len(deliveries) != 1 || deliveries[0].Delivered {
+			t.Errorf("Expected one abandoned delivery, got %+v", deliveries)
+		}
+	})
+}
+
+func TestNewWebhookDispatcherValidation(t *testing.T) {
+	if _, err := NewWebhookDispatcher(&WebhookConfig{Secret: []byte("secret")}); err == nil {
+		t.Error("Expected an error without a URL")
+	}
+	if _, err := NewWebhookDispatcher(&WebhookConfig{URL: "https://example.com/webhook"}); err == nil {
+		t.Error("Expected an error without a secret")
+	}
+}
@@ -2,6 +2,7 @@ package x402
 
 import (
 	"context"
+	"time"
 
 	"x402-go/types"
 )
@@ -89,6 +90,34 @@ type SchemeNetworkClient interface {
 	CreatePaymentPayload(ctx context.Context, requirements types.PaymentRequirements) (types.PaymentPayload, error)
 }
 
+// PaymentProbe is what a PaymentProber found out about paying one set of requirements
+type PaymentProbe struct {
+	// NeedsApproval reports whether paying sends an on-chain approval first, costing the
+	// payer gas (e.g. an ERC-20 token without EIP-3009 or permit support)
+	NeedsApproval bool
+}
+
+// PaymentProber is an optional interface for client mechanisms (V2) that can check whether
+// a payment can be made without signing or sending anything. PrepareBest probes every
+// accepted option at once, so implementations must be safe for concurrent use; they
+// typically share their signer's RPC connection.
+//
+// ProbePayment returns an error when the payment can't be made (e.g. an insufficient_balance
+// PaymentError) and should return promptly once ctx is canceled.
+type PaymentProber interface {
+	ProbePayment(ctx context.Context, requirements types.PaymentRequirements) (PaymentProbe, error)
+}
+
+// PayloadExpirer is an optional interface for client mechanisms (V2) whose payloads stop
+// being accepted at a known time, e.g. an EIP-3009 authorization's validBefore. The HTTP
+// client uses it to regenerate a payment that would expire before the server can settle it.
+//
+// PayloadExpiry returns false when the payload carries no expiry or isn't one of the
+// mechanism's payloads.
+type PayloadExpirer interface {
+	PayloadExpiry(payload types.PaymentPayload) (time.Time, bool)
+}
+
 // SchemeNetworkServer is implemented by server-side payment mechanisms (V2)
 type SchemeNetworkServer interface {
 	Scheme() string
@@ -101,6 +130,13 @@ type SchemeNetworkServer interface {
 	) (types.PaymentRequirements, error)
 }
 
+// ContextPriceParser is implemented by scheme servers whose price parsing needs the request
+// context, e.g. to read an exchange rate on-chain. The resource server uses ParsePriceContext
+// instead of ParsePrice when a scheme server implements it.
+type ContextPriceParser interface {
+	ParsePriceContext(ctx context.Context, price Price, network Network) (AssetAmount, error)
+}
+
 // SchemeNetworkFacilitator is implemented by facilitator-side payment mechanisms (V2)
 type SchemeNetworkFacilitator interface {
 	Scheme() string
@@ -147,6 +183,38 @@ type SchemeNetworkFacilitator interface {
 	Settle(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error)
 }
 
+// BatchSettler is an optional interface for facilitator mechanisms (V2) that can
+// settle several payments in a single transaction.
+//
+// SettleBatch returns one result per payment, in order. Payments that fail carry
+// Success=false with their own ErrorReason, so one bad payment doesn't fail the rest.
+// A returned error means nothing was settled and the caller may retry the payments
+// one by one.
+type BatchSettler interface {
+	SettleBatch(ctx context.Context, payloads []types.PaymentPayload, requirements []types.PaymentRequirements) ([]*SettleResponse, error)
+}
+
+// SettlementSimulator is an optional interface for facilitator mechanisms (V2) that can
+// check whether a settlement would succeed without submitting anything.
+//
+// SimulateSettle runs the checks Settle runs, then simulates the settlement against the
+// current chain state. A settlement that would fail returns the SettleError Settle would,
+// or ReasonTransactionSimulationFailed when the simulated transaction fails. A successful
+// simulation returns Success=true with no Transaction.
+type SettlementSimulator interface {
+	SimulateSettle(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error)
+}
+
+// HealthChecker is an optional interface for facilitator mechanisms (V2) that can report
+// whether they are able to settle on a network, e.g. that their RPC endpoint answers and
+// their signers can pay for gas.
+//
+// HealthCheck should make fresh calls rather than rely on cached results, and return
+// Healthy=false with the reasons in Errors instead of failing.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context, network Network) NetworkHealth
+}
+
 // ============================================================================
 // FacilitatorClient Interfaces (Network Boundary - uses bytes)
 // ============================================================================
@@ -154,6 +222,17 @@ type SchemeNetworkFacilitator interface {
 // FacilitatorClient interface for facilitators that support V1 and/or V2.
 // Uses bytes at network boundary - SDK internal routing unmarshals and routes to typed mechanisms.
 // Both modern facilitators (supporting V1+V2) and legacy facilitators (V1 only) implement this interface.
+//
+// It is the resource server's only dependency on a facilitator (see WithFacilitatorClient), so any
+// transport can implement it: x402http.HTTPFacilitatorClient speaks the JSON-over-HTTP protocol and
+// the x402-go/facilitator/grpc module speaks gRPC. NewLocalFacilitatorClient calls a facilitator
+// running in the same process.
+//
+// payloadBytes and requirementsBytes are the JSON-encoded payment payload and requirements, V1 or
+// V2; implementations forward them as-is. A payment the facilitator rejects is reported either as a
+// response (IsValid or Success false, with the reason) or as a *VerifyError or *SettleError
+// carrying the facilitator's reason, so hooks, logs and metrics see it. Other errors mean the
+// facilitator couldn't be reached or didn't answer.
 type FacilitatorClient interface {
 	// Verify a payment (detects version from bytes, routes internally)
 	Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*VerifyResponse, error)
@@ -164,3 +243,11 @@ type FacilitatorClient interface {
 	// GetSupported returns supported payment kinds in flat array format with x402Version in each element (backward compatible)
 	GetSupported(ctx context.Context) (SupportedResponse, error)
 }
+
+// SimulatingFacilitatorClient is an optional interface for facilitator clients that can dry-run
+// a settlement. With WithSettlementSimulation, the resource server simulates each settlement
+// before submitting it. NewLocalFacilitatorClient implements it.
+type SimulatingFacilitatorClient interface {
+	// SimulateSettle reports whether Settle would succeed, without submitting anything
+	SimulateSettle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*SettleResponse, error)
+}
@@ -0,0 +1,43 @@
+package x402
+
+import (
+	"context"
+)
+
+// localFacilitatorClient is a FacilitatorClient calling a facilitator in the same process
+type localFacilitatorClient struct {
+	facilitator *x402Facilitator
+}
+
+// NewLocalFacilitatorClient returns a FacilitatorClient that calls facilitator directly, for
+// resource servers embedding their own facilitator. It skips the network hop and the request
+// encoding of x402http.HTTPFacilitatorClient. Verification and settlement errors are the
+// facilitator's own *VerifyError and *SettleError.
+func NewLocalFacilitatorClient(facilitator *X402Facilitator) FacilitatorClient {
+	return &localFacilitatorClient{facilitator: facilitator}
+}
+
+// Verify verifies a payment with the local facilitator
+func (c *localFacilitatorClient) Verify(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*VerifyResponse, error) {
+	return c.facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+}
+
+// Settle settles a payment with the local facilitator
+func (c *localFacilitatorClient) Settle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*SettleResponse, error) {
+	return c.facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+}
+
+// SimulateSettle dry-runs a settlement with the local facilitator
+func (c *localFacilitatorClient) SimulateSettle(ctx context.Context, payloadBytes []byte, requirementsBytes []byte) (*SettleResponse, error) {
+	return c.facilitator.SimulateSettle(ctx, payloadBytes, requirementsBytes)
+}
+
+// GetSupported returns the payment kinds registered with the local facilitator
+func (c *localFacilitatorClient) GetSupported(ctx context.Context) (SupportedResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return SupportedResponse{}, err
+	}
+	return c.facilitator.GetSupported(), nil
+}
+
+var _ SimulatingFacilitatorClient = (*localFacilitatorClient)(nil)
@@ -0,0 +1,161 @@
+package x402
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"x402-go/types"
+)
+
+func TestLocalFacilitatorClient(t *testing.T) {
+	ctx := context.Background()
+
+	facilitator := Newx402Facilitator()
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		verifyFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*VerifyResponse, error) {
+			if requirements.Amount != "1000000" {
+				return nil, NewVerifyError(ReasonInsufficientAmount, "0xmockpayer", Network(requirements.Network), nil)
+			}
+			return &VerifyResponse{IsValid: true, Payer: "0xmockpayer"}, nil
+		},
+	})
+
+	server := Newx402ResourceServer(
+		WithFacilitatorClient(NewLocalFacilitatorClient(facilitator)),
+		WithSchemeServer("eip155:1", &mockSchemeNetworkServer{scheme: "exact"}),
+	)
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+
+	requirements := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000000",
+		PayTo:   "0xrecipient",
+	}
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload:     map[string]interface{}{"signature": "test"},
+	}
+
+	verifyResp, err := server.VerifyPayment(ctx, payload, requirements)
+	if err != nil {
+		t.Fatalf("VerifyPayment failed: %v", err)
+	}
+	if !verifyResp.IsValid || verifyResp.Payer != "0xmockpayer" {
+		t.Errorf("Unexpected verify response: %+v", verifyResp)
+	}
+
+	settleResp, err := server.SettlePayment(ctx, payload, requirements)
+	if err != nil {
+		t.Fatalf("SettlePayment failed: %v", err)
+	}
+	if !settleResp.Success {
+		t.Errorf("Expected successful settlement, got %+v", settleResp)
+	}
+
+	// Rejections surface as the facilitator's own error
+	underpaid := requirements
+	underpaid.Amount = "1"
+	payload.Accepted = underpaid
+	_, err = server.VerifyPayment(ctx, payload, underpaid)
+	var ve *VerifyError
+	if !errors.As(err, &ve) || ve.Reason != ReasonInsufficientAmount {
+		t.Errorf("Expected an insufficient_amount VerifyError, got %v", err)
+	}
+}
+
+func TestServerSettlementSimulation(t *testing.T) {
+	ctx := context.Background()
+
+	requirements := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000000",
+		PayTo:   "0xrecipient",
+	}
+	payload := types.PaymentPayload{
+		X402Version: 2,
+		Accepted:    requirements,
+		Payload:     map[string]interface{}{"signature": "test"},
+	}
+
+	newServer := func(mechanism SchemeNetworkFacilitator, settled *int, opts ...ResourceServerOption) *x402ResourceServer {
+		facilitator := Newx402Facilitator()
+		facilitator.Register([]Network{"eip155:1"}, mechanism)
+		facilitator.OnAfterSettle(func(FacilitatorSettleResultContext) error {
+			*settled++
+			return nil
+		})
+
+		opts = append(opts, WithFacilitatorClient(NewLocalFacilitatorClient(facilitator)))
+		server := Newx402ResourceServer(opts...)
+		if err := server.Initialize(ctx); err != nil {
+			t.Fatalf("Initialize failed: %v", err)
+		}
+		return server
+	}
+
+	t.Run("failed simulation is not settled", func(t *testing.T) {
+		settled := 0
+		mechanism := &mockSimulatingSchemeNetworkFacilitator{
+			mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"},
+			simulateErr:                  NewSettleError(ReasonTransactionSimulationFailed, "0xmockpayer", "eip155:1", "", errors.New("execution reverted")),
+		}
+		server := newServer(mechanism, &settled, WithSettlementSimulation())
+
+		_, err := server.SettlePayment(ctx, payload, requirements)
+		var se *SettleError
+		if !errors.As(err, &se) || se.Reason != ReasonTransactionSimulationFailed {
+			t.Fatalf("Expected transaction_simulation_failed, got %v", err)
+		}
+		if settled != 0 {
+			t.Error("Expected the settlement not to be submitted")
+		}
+	})
+
+	t.Run("successful simulation is settled", func(t *testing.T) {
+		settled := 0
+		mechanism := &mockSimulatingSchemeNetworkFacilitator{mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"}}
+		server := newServer(mechanism, &settled, WithSettlementSimulation())
+
+		result, err := server.SettlePayment(ctx, payload, requirements)
+		if err != nil || !result.Success {
+			t.Fatalf("Expected settlement, got %+v, %v", result, err)
+		}
+		if mechanism.simulated != 1 || settled != 1 {
+			t.Errorf("Expected one simulation and one settlement, got %d and %d", mechanism.simulated, settled)
+		}
+	})
+
+	t.Run("unsupported simulation is settled", func(t *testing.T) {
+		settled := 0
+		server := newServer(&mockSchemeNetworkFacilitator{scheme: "exact"}, &settled, WithSettlementSimulation())
+
+		if _, err := server.SettlePayment(ctx, payload, requirements); err != nil {
+			t.Fatalf("Expected settlement, got %v", err)
+		}
+		if settled != 1 {
+			t.Error("Expected the settlement to be submitted")
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		settled := 0
+		mechanism := &mockSimulatingSchemeNetworkFacilitator{mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"}}
+		server := newServer(mechanism, &settled)
+
+		if _, err := server.SettlePayment(ctx, payload, requirements); err != nil {
+			t.Fatalf("Expected settlement, got %v", err)
+		}
+		if mechanism.simulated != 0 {
+			t.Error("Expected no simulation without WithSettlementSimulation")
+		}
+	})
+}
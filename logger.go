@@ -0,0 +1,115 @@
+package x402
+
+import (
+	"log/slog"
+)
+
+// ============================================================================
+// Logging
+// ============================================================================
+
+// Logger receives structured log messages from the client, resource server, facilitator and
+// mechanisms. keysAndValues are alternating keys and values, e.g.
+//
+//	logger.Info("payment settled", "network", network, "transaction", tx)
+//
+// Nothing is logged unless a Logger is configured. *slog.Logger satisfies Logger directly;
+// see NewSlogLogger.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// noopLogger discards everything. It is the default Logger.
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, ...interface{}) {}
+func (noopLogger) Info(string, ...interface{})  {}
+func (noopLogger) Warn(string, ...interface{})  {}
+func (noopLogger) Error(string, ...interface{}) {}
+
+// NoopLogger returns a Logger that discards all messages
+func NoopLogger() Logger {
+	return noopLogger{}
+}
+
+// NewSlogLogger adapts a log/slog logger. A nil logger uses slog.Default().
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return logger
+}
+
+// loggerOrNoop returns logger, or the no-op Logger when logger is nil
+func loggerOrNoop(logger Logger) Logger {
+	if logger == nil {
+		return noopLogger{}
+	}
+	return logger
+}
+
+// isNoopLogger reports whether logging is off
+func isNoopLogger(logger Logger) bool {
+	_, ok := logger.(noopLogger)
+	return ok
+}
+
+// WithLogger logs verify and settle outcomes: failures at Warn, successful settlements at
+// Info and successful verifications at Debug. A nil logger turns logging off again.
+func (f *x402Facilitator) WithLogger(logger Logger) *x402Facilitator {
+	f.logger = loggerOrNoop(logger)
+	return f
+}
+
+// logVerify logs the outcome of a verification
+func (f *x402Facilitator) logVerify(scheme, network, payer, reason string, err error) {
+	if reason != "" {
+		f.logger.Warn("payment verification failed", logFields(scheme, network, payer, reason, err)...)
+		return
+	}
+	f.logger.Debug("payment verified", logFields(scheme, network, payer, "", nil)...)
+}
+
+// logSettle logs the outcome of a settlement
+func (f *x402Facilitator) logSettle(scheme, network string, result *SettleResponse, reason string, err error) {
+	payer := errorPayer(err)
+	if result != nil {
+		payer = result.Payer
+	}
+	if reason != "" {
+		f.logger.Warn("payment settlement failed", logFields(scheme, network, payer, reason, err)...)
+		return
+	}
+	f.logger.Info("payment settled", append(logFields(scheme, network, payer, "", nil), "transaction", result.Transaction)...)
+}
+
+// logFields builds the key-value pairs shared by payment log messages, skipping empty values
+func logFields(scheme, network, payer, reason string, err error) []interface{} {
+	fields := make([]interface{}, 0, 10)
+	for _, field := range [][2]string{
+		{"scheme", scheme},
+		{"network", network},
+		{"payer", payer},
+		{"reason", reason},
+	} {
+		if field[1] != "" {
+			fields = append(fields, field[0], field[1])
+		}
+	}
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+	return fields
+}
+
+// logPayload logs the outcome of payment payload creation
+func (c *x402Client) logPayload(scheme, network, reason string, err error) {
+	if reason != "" {
+		c.logger.Warn("payment payload creation failed", logFields(scheme, network, "", reason, err)...)
+		return
+	}
+	c.logger.Debug("payment payload created", logFields(scheme, network, "", "", nil)...)
+}
@@ -0,0 +1,100 @@
+package x402
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"x402-go/types"
+)
+
+// logEntry is a message captured by recordingLogger
+type logEntry struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+// recordingLogger is a Logger that records every message
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []logEntry
+}
+
+func (l *recordingLogger) record(level, msg string, keysAndValues []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fields := make(map[string]interface{})
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, fields: fields})
+}
+
+func (l *recordingLogger) Debug(msg string, kv ...interface{}) { l.record("debug", msg, kv) }
+func (l *recordingLogger) Info(msg string, kv ...interface{})  { l.record("info", msg, kv) }
+func (l *recordingLogger) Warn(msg string, kv ...interface{})  { l.record("warn", msg, kv) }
+func (l *recordingLogger) Error(msg string, kv ...interface{}) { l.record("error", msg, kv) }
+
+func TestFacilitatorLogging(t *testing.T) {
+	ctx := context.Background()
+	logger := &recordingLogger{}
+
+	facilitator := Newx402Facilitator().WithLogger(logger)
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		settleFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+			return nil, NewSettleError(ReasonTransactionFailed, "0xpayer", "eip155:1", "0xtx", nil)
+		},
+	})
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "5", PayTo: "0xrecipient"}
+	payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}}
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	if _, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if _, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes); err == nil {
+		t.Fatal("Expected settle error")
+	}
+
+	if len(logger.entries) != 2 {
+		t.Fatalf("Expected 2 log entries, got %d: %+v", len(logger.entries), logger.entries)
+	}
+
+	verified := logger.entries[0]
+	if verified.level != "debug" || verified.msg != "payment verified" || verified.fields["network"] != "eip155:1" {
+		t.Errorf("Unexpected verify entry: %+v", verified)
+	}
+
+	failed := logger.entries[1]
+	if failed.level != "warn" || failed.msg != "payment settlement failed" {
+		t.Errorf("Unexpected settle entry: %+v", failed)
+	}
+	if failed.fields["reason"] != ReasonTransactionFailed || failed.fields["payer"] != "0xpayer" || failed.fields["error"] == nil {
+		t.Errorf("Unexpected settle fields: %v", failed.fields)
+	}
+}
+
+func TestNewSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	logger.Info("ignored", "network", "eip155:1")
+	logger.Warn("payment settlement failed", "network", "eip155:1", "reason", ReasonTransactionFailed)
+
+	out := buf.String()
+	if strings.Contains(out, "ignored") {
+		t.Errorf("Expected messages below the handler level to be dropped, got %q", out)
+	}
+	if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "network=eip155:1") || !strings.Contains(out, "reason="+ReasonTransactionFailed) {
+		t.Errorf("Unexpected slog output: %q", out)
+	}
+}
@@ -3,6 +3,9 @@ package evm
 import (
 	"math/big"
 	"os"
+	"time"
+
+	x402 "x402-go"
 )
 
 const (
@@ -16,14 +19,83 @@ const (
 	FunctionTransferWithAuthorization = "transferWithAuthorization"
 	FunctionReceiveWithAuthorization  = "receiveWithAuthorization"
 	FunctionAuthorizationState        = "authorizationState"
+	FunctionCancelAuthorization       = "cancelAuthorization"
+
+	// EIP-2612 function names
+	FunctionPermit          = "permit"
+	FunctionNonces          = "nonces"
+	FunctionDomainSeparator = "DOMAIN_SEPARATOR"
+
+	// Facilitator contract function names
+	FunctionSettlePayment      = "settlePayment"
+	FunctionSettlePaymentBatch = "settlePaymentBatch"
+
+	// ERC-20 function names
+	FunctionAllowance = "allowance"
+	FunctionApprove   = "approve"
+	FunctionBalanceOf = "balanceOf"
+	FunctionTransfer  = "transfer"
+
+	// ERC-20 metadata function names
+	FunctionName     = "name"
+	FunctionDecimals = "decimals"
+	FunctionVersion  = "version"
+
+	// Chainlink aggregator function names
+	FunctionLatestRoundData = "latestRoundData"
+
+	// AssetPrefixERC20 prefixes fully-qualified ERC-20 asset identifiers (e.g. "erc20:0x...")
+	AssetPrefixERC20 = "erc20:"
+
+	// AssetNamespaceERC20 is the CAIP-19 asset namespace of ERC-20 tokens (e.g. "eip155:8453/erc20:0x...")
+	AssetNamespaceERC20 = "erc20"
+
+	// Default EIP-712 domain of the facilitator contract's tokenTransferWithAuthorization,
+	// signed by clients paying with generic ERC-20 tokens
+	DefaultERC20AuthorizationDomainName    = "Facilitator"
+	DefaultERC20AuthorizationDomainVersion = "1"
+
+	// Payment payload types (the "type" field of an exact EVM payload)
+	PayloadTypeEIP3009        = "authorizationEip3009"
+	PayloadTypeEIP3009Receive = "receiveAuthorizationEip3009"
+	PayloadTypeAuthorization  = "authorization"
+	PayloadTypePermit         = "permit"
+
+	// EIP-712 primary types of EIP-3009 authorizations
+	PrimaryTypeTransferWithAuthorization = "TransferWithAuthorization"
+	PrimaryTypeReceiveWithAuthorization  = "ReceiveWithAuthorization"
+	PrimaryTypeCancelAuthorization       = "CancelAuthorization"
+
+	// PrimaryTypeTokenTransferWithAuthorization is the EIP-712 primary type of the facilitator
+	// contract's generic ERC-20 authorizations
+	PrimaryTypeTokenTransferWithAuthorization = "tokenTransferWithAuthorization"
+
+	// ExtraAuthorizationFunction is the requirements extra key a facilitator uses to ask
+	// for authorizations it can only settle through FunctionReceiveWithAuthorization
+	ExtraAuthorizationFunction = "authorizationFunction"
 
 	// Transaction status
 	TxStatusSuccess = 1
 	TxStatusFailed  = 0
 
+	// ERC20TransferEventTopic is the topic of the ERC-20 Transfer(address,address,uint256) event
+	ERC20TransferEventTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
 	// Default validity period (1 hour)
 	DefaultValidityPeriod = 3600 // seconds
 
+	// Default clock skew tolerance applied to validity windows (see ClockSkewTolerance)
+	DefaultClockSkewTolerance = 30 // seconds
+
+	// Default maximum number of payments settled in one settlePaymentBatch transaction
+	DefaultMaxBatchSize = 50
+
+	// Default lifetime of cached on-chain capability probes (EIP-3009 and batch settlement support)
+	DefaultSupportCacheTTL = time.Hour
+
+	// Default maximum age of a price feed answer before it is considered stale
+	DefaultPriceFeedMaxAge = time.Hour
+
 	// ERC-6492 magic value (last 32 bytes of wrapped signature)
 	// This is bytes32(uint256(keccak256("erc6492.invalid.signature")) - 1)
 	ERC6492MagicValue = "0x6492649264926492649264926492649264926492649264926492649264926492"
@@ -32,18 +104,26 @@ const (
 	EIP1271MagicValue = "0x1626ba7e"
 
 	// Error codes matching TypeScript implementation
-	ErrInvalidSignature            = "invalid_exact_evm_payload_signature"
-	ErrUndeployedSmartWallet       = "invalid_exact_evm_payload_undeployed_smart_wallet"
-	ErrSmartWalletDeploymentFailed = "smart_wallet_deployment_failed"
+	ErrInvalidSignature            = x402.ReasonInvalidExactEVMPayloadSignature
+	ErrUndeployedSmartWallet       = x402.ReasonInvalidExactEVMPayloadUndeployedSmartWallet
+	ErrSmartWalletDeploymentFailed = x402.ReasonSmartWalletDeploymentFailed
 )
 
 var (
 	// Network chain IDs
-	ChainIDMainnet     = big.NewInt(1)
-	ChainIDBase        = big.NewInt(8453)
-	ChainIDBaseSepolia = big.NewInt(84532)
+	ChainIDMainnet         = big.NewInt(1)
+	ChainIDBase            = big.NewInt(8453)
+	ChainIDBaseSepolia     = big.NewInt(84532)
+	ChainIDArbitrum        = big.NewInt(42161)
+	ChainIDArbitrumSepolia = big.NewInt(421614)
+	ChainIDOptimism        = big.NewInt(10)
+	ChainIDOptimismSepolia = big.NewInt(11155420)
+	ChainIDPolygon         = big.NewInt(137)
+	ChainIDPolygonAmoy     = big.NewInt(80002)
 
-	// Network configurations
+	// NetworkConfigs holds the configuration of every known network. It is guarded by a lock:
+	// read it through GetNetworkConfig, GetConfiguredNetworks and the other lookups, and change
+	// it through RegisterAsset or LoadNetworkConfigs rather than touching the map directly.
 	NetworkConfigs = map[string]NetworkConfig{
 		"eip155:1": {
 			ChainID: ChainIDMainnet,
@@ -62,6 +142,20 @@ var (
 					Decimals:        DefaultDecimals,
 					SupportsEIP3009: true,
 				},
+				"USDT": {
+					Address:         "0xdAC17F958D2ee523a2206206994597C13D831ec7",
+					Name:            "Tether USD",
+					Version:         "1",
+					Decimals:        6,
+					SupportsEIP3009: false,
+				},
+				"DAI": {
+					Address:         "0x6B175474E89094C44Da98b954EedeAC495271d0F",
+					Name:            "Dai Stablecoin",
+					Version:         "1",
+					Decimals:        18,
+					SupportsEIP3009: false,
+				},
 			},
 		},
 		"eip155:8453": {
@@ -81,6 +175,20 @@ var (
 					Decimals:        DefaultDecimals,
 					SupportsEIP3009: true,
 				},
+				"USDT": {
+					Address:         "0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2",
+					Name:            "Tether USD",
+					Version:         "1",
+					Decimals:        6,
+					SupportsEIP3009: false,
+				},
+				"DAI": {
+					Address:         "0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb",
+					Name:            "Dai Stablecoin",
+					Version:         "1",
+					Decimals:        18,
+					SupportsEIP3009: false,
+				},
 			},
 		},
 		"base": {
@@ -100,6 +208,20 @@ var (
 					Decimals:        DefaultDecimals,
 					SupportsEIP3009: true,
 				},
+				"USDT": {
+					Address:         "0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2",
+					Name:            "Tether USD",
+					Version:         "1",
+					Decimals:        6,
+					SupportsEIP3009: false,
+				},
+				"DAI": {
+					Address:         "0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb",
+					Name:            "Dai Stablecoin",
+					Version:         "1",
+					Decimals:        18,
+					SupportsEIP3009: false,
+				},
 			},
 		},
 		"base-mainnet": {
@@ -119,6 +241,20 @@ var (
 					Decimals:        DefaultDecimals,
 					SupportsEIP3009: true,
 				},
+				"USDT": {
+					Address:         "0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2",
+					Name:            "Tether USD",
+					Version:         "1",
+					Decimals:        6,
+					SupportsEIP3009: false,
+				},
+				"DAI": {
+					Address:         "0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb",
+					Name:            "Dai Stablecoin",
+					Version:         "1",
+					Decimals:        18,
+					SupportsEIP3009: false,
+				},
 			},
 		},
 		"eip155:84532": {
@@ -159,6 +295,128 @@ var (
 				},
 			},
 		},
+		"eip155:42161": {
+			ChainID: ChainIDArbitrum,
+			DefaultAsset: AssetInfo{
+				Address:         "0xaf88d065e77c8cC2239327C5EDb3A432268e5831", // USDC on Arbitrum One
+				Name:            "USD Coin",
+				Version:         "2",
+				Decimals:        DefaultDecimals,
+				SupportsEIP3009: true,
+			},
+			SupportedAssets: map[string]AssetInfo{
+				"USDC": {
+					Address:         "0xaf88d065e77c8cC2239327C5EDb3A432268e5831",
+					Name:            "USD Coin",
+					Version:         "2",
+					Decimals:        DefaultDecimals,
+					SupportsEIP3009: true,
+				},
+			},
+		},
+		"eip155:421614": {
+			ChainID: ChainIDArbitrumSepolia,
+			DefaultAsset: AssetInfo{
+				Address:         "0x75faf114eafb1BDbe2F0316DF893fd58CE46AA4d", // USDC on Arbitrum Sepolia
+				Name:            "USD Coin",
+				Version:         "2",
+				Decimals:        DefaultDecimals,
+				SupportsEIP3009: true,
+			},
+			SupportedAssets: map[string]AssetInfo{
+				"USDC": {
+					Address:         "0x75faf114eafb1BDbe2F0316DF893fd58CE46AA4d",
+					Name:            "USD Coin",
+					Version:         "2",
+					Decimals:        DefaultDecimals,
+					SupportsEIP3009: true,
+				},
+			},
+		},
+		"eip155:10": {
+			ChainID: ChainIDOptimism,
+			DefaultAsset: AssetInfo{
+				Address:         "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85", // USDC on OP Mainnet
+				Name:            "USD Coin",
+				Version:         "2",
+				Decimals:        DefaultDecimals,
+				SupportsEIP3009: true,
+			},
+			SupportedAssets: map[string]AssetInfo{
+				"USDC": {
+					Address:         "0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85",
+					Name:            "USD Coin",
+					Version:         "2",
+					Decimals:        DefaultDecimals,
+					SupportsEIP3009: true,
+				},
+			},
+		},
+		"eip155:11155420": {
+			ChainID: ChainIDOptimismSepolia,
+			DefaultAsset: AssetInfo{
+				Address:         "0x5fd84259d66Cd46123540766Be93DFE6D43130D7", // USDC on OP Sepolia
+				Name:            "USDC",
+				Version:         "2",
+				Decimals:        DefaultDecimals,
+				SupportsEIP3009: true,
+			},
+			SupportedAssets: map[string]AssetInfo{
+				"USDC": {
+					Address:         "0x5fd84259d66Cd46123540766Be93DFE6D43130D7",
+					Name:            "USDC",
+					Version:         "2",
+					Decimals:        DefaultDecimals,
+					SupportsEIP3009: true,
+				},
+			},
+		},
+		"eip155:137": {
+			ChainID: ChainIDPolygon,
+			DefaultAsset: AssetInfo{
+				Address:         "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359", // Native USDC on Polygon PoS
+				Name:            "USD Coin",
+				Version:         "2",
+				Decimals:        DefaultDecimals,
+				SupportsEIP3009: true,
+			},
+			SupportedAssets: map[string]AssetInfo{
+				"USDC": {
+					Address:         "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359",
+					Name:            "USD Coin",
+					Version:         "2",
+					Decimals:        DefaultDecimals,
+					SupportsEIP3009: true,
+				},
+				// Bridged USDC.e (PoS bridge) uses a different contract and EIP-712 domain
+				"USDCE": {
+					Address:         "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174",
+					Name:            "USD Coin (PoS)",
+					Version:         "1",
+					Decimals:        DefaultDecimals,
+					SupportsEIP3009: false,
+				},
+			},
+		},
+		"eip155:80002": {
+			ChainID: ChainIDPolygonAmoy,
+			DefaultAsset: AssetInfo{
+				Address:         "0x41E94Eb019C0762f9Bfcf9Fb1E58725BfB0e7582", // USDC on Polygon Amoy
+				Name:            "USDC",
+				Version:         "2",
+				Decimals:        DefaultDecimals,
+				SupportsEIP3009: true,
+			},
+			SupportedAssets: map[string]AssetInfo{
+				"USDC": {
+					Address:         "0x41E94Eb019C0762f9Bfcf9Fb1E58725BfB0e7582",
+					Name:            "USDC",
+					Version:         "2",
+					Decimals:        DefaultDecimals,
+					SupportsEIP3009: true,
+				},
+			},
+		},
 	}
 
 	// EIP-3009 ABI for transferWithAuthorization with v,r,s (EOA signatures)
@@ -201,6 +459,78 @@ var (
 		}
 	]`)
 
+	// EIP-3009 ABI for receiveWithAuthorization with v,r,s (EOA signatures)
+	ReceiveWithAuthorizationVRSABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "from", "type": "address"},
+				{"name": "to", "type": "address"},
+				{"name": "value", "type": "uint256"},
+				{"name": "validAfter", "type": "uint256"},
+				{"name": "validBefore", "type": "uint256"},
+				{"name": "nonce", "type": "bytes32"},
+				{"name": "v", "type": "uint8"},
+				{"name": "r", "type": "bytes32"},
+				{"name": "s", "type": "bytes32"}
+			],
+			"name": "receiveWithAuthorization",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// EIP-3009 ABI for receiveWithAuthorization with bytes signature (smart wallets)
+	ReceiveWithAuthorizationBytesABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "from", "type": "address"},
+				{"name": "to", "type": "address"},
+				{"name": "value", "type": "uint256"},
+				{"name": "validAfter", "type": "uint256"},
+				{"name": "validBefore", "type": "uint256"},
+				{"name": "nonce", "type": "bytes32"},
+				{"name": "signature", "type": "bytes"}
+			],
+			"name": "receiveWithAuthorization",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// EIP-3009 ABI for cancelAuthorization with v,r,s (EOA signatures)
+	CancelAuthorizationVRSABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "authorizer", "type": "address"},
+				{"name": "nonce", "type": "bytes32"},
+				{"name": "v", "type": "uint8"},
+				{"name": "r", "type": "bytes32"},
+				{"name": "s", "type": "bytes32"}
+			],
+			"name": "cancelAuthorization",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// EIP-3009 ABI for cancelAuthorization with bytes signature (smart wallets)
+	CancelAuthorizationBytesABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "authorizer", "type": "address"},
+				{"name": "nonce", "type": "bytes32"},
+				{"name": "signature", "type": "bytes"}
+			],
+			"name": "cancelAuthorization",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
 	// Legacy: Combined ABI (deprecated, use specific ABIs above)
 	TransferWithAuthorizationABI = TransferWithAuthorizationVRSABI
 
@@ -258,10 +588,71 @@ var (
 		}
 	]`)
 
-	// FacilitatorContractAddress is the address of the facilitator contract on all supported networks
+	// SettlePaymentBatchABI matches the settlePaymentBatch function in the facilitator contract.
+	// All payments in a batch use the same token; the call reverts as a whole if any transfer fails.
+	SettlePaymentBatchABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "token", "type": "address"},
+				{"name": "from", "type": "address[]"},
+				{"name": "to", "type": "address[]"},
+				{"name": "value", "type": "uint256[]"},
+				{"name": "validAfter", "type": "uint256[]"},
+				{"name": "validBefore", "type": "uint256[]"},
+				{"name": "nonce", "type": "bytes32[]"},
+				{"name": "signature", "type": "bytes[]"}
+			],
+			"name": "settlePaymentBatch",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// PermitABI covers the EIP-2612 permit function and the views used to detect support
+	PermitABI = []byte(`[
+		{
+			"inputs": [
+				{"name": "owner", "type": "address"},
+				{"name": "spender", "type": "address"},
+				{"name": "value", "type": "uint256"},
+				{"name": "deadline", "type": "uint256"},
+				{"name": "v", "type": "uint8"},
+				{"name": "r", "type": "bytes32"},
+				{"name": "s", "type": "bytes32"}
+			],
+			"name": "permit",
+			"outputs": [],
+			"stateMutability": "nonpayable",
+			"type": "function"
+		},
+		{
+			"inputs": [{"name": "owner", "type": "address"}],
+			"name": "nonces",
+			"outputs": [{"name": "", "type": "uint256"}],
+			"stateMutability": "view",
+			"type": "function"
+		},
+		{
+			"inputs": [],
+			"name": "DOMAIN_SEPARATOR",
+			"outputs": [{"name": "", "type": "bytes32"}],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// ClockSkewTolerance is how far client and facilitator clocks may drift apart. CreateValidityWindow
+	// backdates validAfter by it, and facilitators accept windows off by up to it unless their config
+	// sets its own. Clients and facilitators should use the same value. Overridden by
+	// EVM_CLOCK_SKEW_TOLERANCE (a duration such as "45s").
+	ClockSkewTolerance = DefaultClockSkewTolerance * time.Second
+
+	// FacilitatorContractAddress is the default facilitator contract address, used by networks
+	// whose NetworkConfig doesn't set FacilitatorContract. Overridden by EVM_FACILITATOR_CONTRACT_ADDRESS.
 	FacilitatorContractAddress = "0x555e3311a9893c9B17444C1Ff0d88192a57Ef13e"
 
-	// ERC20ABI for allowance and approve
+	// ERC20ABI for allowance, approve, balanceOf and transfer
 	ERC20ABI = []byte(`[
 		{
 			"constant": true,
@@ -286,6 +677,76 @@ var (
 			"payable": false,
 			"stateMutability": "nonpayable",
 			"type": "function"
+		},
+		{
+			"constant": true,
+			"inputs": [{"name": "account", "type": "address"}],
+			"name": "balanceOf",
+			"outputs": [{"name": "", "type": "uint256"}],
+			"payable": false,
+			"stateMutability": "view",
+			"type": "function"
+		},
+		{
+			"constant": false,
+			"inputs": [
+				{"name": "to", "type": "address"},
+				{"name": "value", "type": "uint256"}
+			],
+			"name": "transfer",
+			"outputs": [{"name": "", "type": "bool"}],
+			"payable": false,
+			"stateMutability": "nonpayable",
+			"type": "function"
+		}
+	]`)
+
+	// ERC20MetadataABI covers the ERC-20 metadata views plus the EIP-712 version() used by EIP-3009 tokens
+	ERC20MetadataABI = []byte(`[
+		{
+			"inputs": [],
+			"name": "name",
+			"outputs": [{"name": "", "type": "string"}],
+			"stateMutability": "view",
+			"type": "function"
+		},
+		{
+			"inputs": [],
+			"name": "decimals",
+			"outputs": [{"name": "", "type": "uint8"}],
+			"stateMutability": "view",
+			"type": "function"
+		},
+		{
+			"inputs": [],
+			"name": "version",
+			"outputs": [{"name": "", "type": "string"}],
+			"stateMutability": "view",
+			"type": "function"
+		}
+	]`)
+
+	// ChainlinkAggregatorABI covers the AggregatorV3Interface views used to read USD prices
+	ChainlinkAggregatorABI = []byte(`[
+		{
+			"inputs": [],
+			"name": "decimals",
+			"outputs": [{"name": "", "type": "uint8"}],
+			"stateMutability": "view",
+			"type": "function"
+		},
+		{
+			"inputs": [],
+			"name": "latestRoundData",
+			"outputs": [
+				{"name": "roundId", "type": "uint80"},
+				{"name": "answer", "type": "int256"},
+				{"name": "startedAt", "type": "uint256"},
+				{"name": "updatedAt", "type": "uint256"},
+				{"name": "answeredInRound", "type": "uint80"}
+			],
+			"stateMutability": "view",
+			"type": "function"
 		}
 	]`)
 )
@@ -295,24 +756,15 @@ func init() {
 		FacilitatorContractAddress = envAddr
 	}
 
-	if usdcAddr := os.Getenv("EVM_USDC_ADDRESS"); usdcAddr != "" {
-		// Override for eip155:84532
-		if config, ok := NetworkConfigs["eip155:84532"]; ok {
-			config.DefaultAsset.Address = usdcAddr
-			if asset, ok := config.SupportedAssets["USDC"]; ok {
-				asset.Address = usdcAddr
-				config.SupportedAssets["USDC"] = asset
-			}
-			NetworkConfigs["eip155:84532"] = config
-		}
-		// Override for base-sepolia alias
-		if config, ok := NetworkConfigs["base-sepolia"]; ok {
-			config.DefaultAsset.Address = usdcAddr
-			if asset, ok := config.SupportedAssets["USDC"]; ok {
-				asset.Address = usdcAddr
-				config.SupportedAssets["USDC"] = asset
-			}
-			NetworkConfigs["base-sepolia"] = config
+	if skew := os.Getenv("EVM_CLOCK_SKEW_TOLERANCE"); skew != "" {
+		if tolerance, err := time.ParseDuration(skew); err == nil && tolerance >= 0 {
+			ClockSkewTolerance = tolerance
 		}
 	}
+
+	if usdcAddr := os.Getenv("EVM_USDC_ADDRESS"); usdcAddr != "" {
+		// Override for eip155:84532 and its base-sepolia alias
+		setAssetAddress("eip155:84532", "USDC", usdcAddr)
+		setAssetAddress("base-sepolia", "USDC", usdcAddr)
+	}
 }
@@ -111,6 +111,30 @@ func HashEIP3009Authorization(
 	verifyingContract string,
 	tokenName string,
 	tokenVersion string,
+) ([]byte, error) {
+	return hashEIP3009(PrimaryTypeTransferWithAuthorization, authorization, chainID, verifyingContract, tokenName, tokenVersion)
+}
+
+// HashEIP3009ReceiveAuthorization hashes a ReceiveWithAuthorization message for EIP-3009.
+// It takes the same arguments as HashEIP3009Authorization.
+func HashEIP3009ReceiveAuthorization(
+	authorization ExactEIP3009Authorization,
+	chainID *big.Int,
+	verifyingContract string,
+	tokenName string,
+	tokenVersion string,
+) ([]byte, error) {
+	return hashEIP3009(PrimaryTypeReceiveWithAuthorization, authorization, chainID, verifyingContract, tokenName, tokenVersion)
+}
+
+// hashEIP3009 hashes an EIP-3009 authorization under the given primary type
+func hashEIP3009(
+	primaryType string,
+	authorization ExactEIP3009Authorization,
+	chainID *big.Int,
+	verifyingContract string,
+	tokenName string,
+	tokenVersion string,
 ) ([]byte, error) {
 	// Create EIP-712 domain
 	domain := TypedDataDomain{
@@ -128,7 +152,7 @@ func HashEIP3009Authorization(
 			{Name: "chainId", Type: "uint256"},
 			{Name: "verifyingContract", Type: "address"},
 		},
-		"TransferWithAuthorization": {
+		primaryType: {
 			{Name: "from", Type: "address"},
 			{Name: "to", Type: "address"},
 			{Name: "value", Type: "uint256"},
@@ -158,47 +182,128 @@ func HashEIP3009Authorization(
 		"nonce":       nonceBytes,
 	}
 
-	return HashTypedData(domain, types, "TransferWithAuthorization", message)
+	return HashTypedData(domain, types, primaryType, message)
 }
 
-// HashERC20Authorization hashes a tokenTransferWithAuthorization message for ERC-20 tokens
+// CancelAuthorizationTypes returns the EIP-712 type definitions for an EIP-3009 CancelAuthorization
+func CancelAuthorizationTypes() map[string][]TypedDataField {
+	return map[string][]TypedDataField{
+		"EIP712Domain": {
+			{Name: "name", Type: "string"},
+			{Name: "version", Type: "string"},
+			{Name: "chainId", Type: "uint256"},
+			{Name: "verifyingContract", Type: "address"},
+		},
+		PrimaryTypeCancelAuthorization: {
+			{Name: "authorizer", Type: "address"},
+			{Name: "nonce", Type: "bytes32"},
+		},
+	}
+}
+
+// CancelAuthorizationMessage returns the EIP-712 message cancelling authorizer's authorization with nonce
+func CancelAuthorizationMessage(authorizer string, nonce string) (map[string]interface{}, error) {
+	nonceBytes, err := HexToBytes(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	if len(nonceBytes) != 32 {
+		return nil, fmt.Errorf("invalid nonce length: expected 32 bytes, got %d", len(nonceBytes))
+	}
+
+	return map[string]interface{}{
+		"authorizer": common.HexToAddress(authorizer).Hex(),
+		"nonce":      nonceBytes,
+	}, nil
+}
+
+// HashEIP3009CancelAuthorization hashes a CancelAuthorization message for EIP-3009
 //
-// This function wraps HashTypedData with the specific types and structure used by
-// the facilitator's tokenTransferWithAuthorization.
+// The domain is the token's own EIP-712 domain, the same one its authorizations are signed in.
 //
 // Args:
 //
-//	authorization: The ERC-20 authorization data
+//	authorizer: The address that signed the authorization being cancelled
+//	nonce: The 32-byte nonce of that authorization as hex
 //	chainID: The chain ID for the EIP-712 domain
-//	verifyingContract: The facilitator contract address
+//	verifyingContract: The token contract address
+//	tokenName: The token name (e.g., "USD Coin")
+//	tokenVersion: The token version (e.g., "2")
 //
 // Returns:
 //
 //	32-byte hash suitable for signing or verification
 //	error if hashing fails
-func HashERC20Authorization(
-	authorization ExactERC20Authorization,
+func HashEIP3009CancelAuthorization(
+	authorizer string,
+	nonce string,
 	chainID *big.Int,
 	verifyingContract string,
+	tokenName string,
+	tokenVersion string,
 ) ([]byte, error) {
-	// Create EIP-712 domain
-	// Note: The facilitator uses name "Facilitator" and version "1"
+	message, err := CancelAuthorizationMessage(authorizer, nonce)
+	if err != nil {
+		return nil, err
+	}
+
 	domain := TypedDataDomain{
-		Name:              "Facilitator",
-		Version:           "1",
+		Name:              tokenName,
+		Version:           tokenVersion,
 		ChainID:           chainID,
 		VerifyingContract: verifyingContract,
 	}
+	return HashTypedData(domain, CancelAuthorizationTypes(), PrimaryTypeCancelAuthorization, message)
+}
 
-	// Define EIP-712 types
-	types := map[string][]TypedDataField{
+// CancelAuthorizationCall returns the ABI and arguments of the token's cancelAuthorization call
+// for a signed cancellation: the v,r,s overload for EOA signatures and the bytes overload for
+// smart wallets
+func CancelAuthorizationCall(cancellation ExactEIP3009Cancellation) ([]byte, []interface{}, error) {
+	nonceBytes, err := HexToBytes(cancellation.Nonce)
+	if err != nil || len(nonceBytes) != 32 {
+		return nil, nil, fmt.Errorf("invalid nonce: %s", cancellation.Nonce)
+	}
+	signature, err := HexToBytes(cancellation.Signature)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	// The token checks signatures itself and doesn't unwrap ERC-6492
+	if IsERC6492Signature(signature) {
+		sigData, err := ParseERC6492Signature(signature)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid ERC-6492 signature: %w", err)
+		}
+		signature = sigData.InnerSignature
+	}
+
+	authorizer := common.HexToAddress(cancellation.Authorizer)
+	if len(signature) == EOASignatureLength {
+		components, err := SplitSignature(signature)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid signature: %w", err)
+		}
+		return CancelAuthorizationVRSABI, []interface{}{
+			authorizer, [32]byte(nonceBytes), components.V, components.R, components.S,
+		}, nil
+	}
+	return CancelAuthorizationBytesABI, []interface{}{
+		authorizer, [32]byte(nonceBytes), signature,
+	}, nil
+}
+
+// ERC20AuthorizationTypes returns the EIP-712 type definitions for the facilitator contract's
+// tokenTransferWithAuthorization. Clients sign and facilitators hash against these same types.
+func ERC20AuthorizationTypes() map[string][]TypedDataField {
+	return map[string][]TypedDataField{
 		"EIP712Domain": {
 			{Name: "name", Type: "string"},
 			{Name: "version", Type: "string"},
 			{Name: "chainId", Type: "uint256"},
 			{Name: "verifyingContract", Type: "address"},
 		},
-		"tokenTransferWithAuthorization": {
+		PrimaryTypeTokenTransferWithAuthorization: {
 			{Name: "token", Type: "address"},
 			{Name: "from", Type: "address"},
 			{Name: "to", Type: "address"},
@@ -209,7 +314,28 @@ func HashERC20Authorization(
 			{Name: "needApprove", Type: "bool"},
 		},
 	}
+}
+
+// ERC20AuthorizationDomain returns the EIP-712 domain of the facilitator contract at
+// verifyingContract. Empty domainName and domainVersion fall back to
+// DefaultERC20AuthorizationDomainName and DefaultERC20AuthorizationDomainVersion.
+func ERC20AuthorizationDomain(chainID *big.Int, verifyingContract, domainName, domainVersion string) TypedDataDomain {
+	if domainName == "" {
+		domainName = DefaultERC20AuthorizationDomainName
+	}
+	if domainVersion == "" {
+		domainVersion = DefaultERC20AuthorizationDomainVersion
+	}
+	return TypedDataDomain{
+		Name:              domainName,
+		Version:           domainVersion,
+		ChainID:           chainID,
+		VerifyingContract: verifyingContract,
+	}
+}
 
+// ERC20AuthorizationMessage returns the EIP-712 message of an ERC-20 authorization
+func ERC20AuthorizationMessage(authorization ExactERC20Authorization) map[string]interface{} {
 	// Parse values for message
 	value, _ := new(big.Int).SetString(authorization.Value, 10)
 	validAfter, _ := new(big.Int).SetString(authorization.ValidAfter, 10)
@@ -217,21 +343,120 @@ func HashERC20Authorization(
 	nonceBytes, _ := HexToBytes(authorization.Nonce)
 
 	// Ensure addresses are checksummed
-	token := common.HexToAddress(authorization.Token).Hex()
-	from := common.HexToAddress(authorization.From).Hex()
-	to := common.HexToAddress(authorization.To).Hex()
-
-	// Create message
-	message := map[string]interface{}{
-		"token":       token,
-		"from":        from,
-		"to":          to,
+	return map[string]interface{}{
+		"token":       common.HexToAddress(authorization.Token).Hex(),
+		"from":        common.HexToAddress(authorization.From).Hex(),
+		"to":          common.HexToAddress(authorization.To).Hex(),
 		"value":       value,
 		"validAfter":  validAfter,
 		"validBefore": validBefore,
 		"nonce":       nonceBytes,
 		"needApprove": authorization.NeedApprove,
 	}
+}
+
+// HashERC20Authorization hashes a tokenTransferWithAuthorization message for ERC-20 tokens
+//
+// This function wraps HashTypedData with ERC20AuthorizationTypes, ERC20AuthorizationDomain
+// and ERC20AuthorizationMessage, the definitions clients sign with.
+//
+// Args:
+//
+//	authorization: The ERC-20 authorization data
+//	chainID: The chain ID for the EIP-712 domain
+//	verifyingContract: The facilitator contract address
+//	domainName: The facilitator contract's EIP-712 domain name (empty for DefaultERC20AuthorizationDomainName)
+//	domainVersion: The facilitator contract's EIP-712 domain version (empty for DefaultERC20AuthorizationDomainVersion)
+//
+// Returns:
+//
+//	32-byte hash suitable for signing or verification
+//	error if hashing fails
+func HashERC20Authorization(
+	authorization ExactERC20Authorization,
+	chainID *big.Int,
+	verifyingContract string,
+	domainName string,
+	domainVersion string,
+) ([]byte, error) {
+	return HashTypedData(
+		ERC20AuthorizationDomain(chainID, verifyingContract, domainName, domainVersion),
+		ERC20AuthorizationTypes(),
+		PrimaryTypeTokenTransferWithAuthorization,
+		ERC20AuthorizationMessage(authorization),
+	)
+}
+
+// PermitTypes returns the EIP-712 type definitions for an EIP-2612 Permit
+func PermitTypes() map[string][]TypedDataField {
+	return map[string][]TypedDataField{
+		"EIP712Domain": {
+			{Name: "name", Type: "string"},
+			{Name: "version", Type: "string"},
+			{Name: "chainId", Type: "uint256"},
+			{Name: "verifyingContract", Type: "address"},
+		},
+		"Permit": {
+			{Name: "owner", Type: "address"},
+			{Name: "spender", Type: "address"},
+			{Name: "value", Type: "uint256"},
+			{Name: "nonce", Type: "uint256"},
+			{Name: "deadline", Type: "uint256"},
+		},
+	}
+}
+
+// HashPermit hashes an EIP-2612 Permit message
+//
+// The domain is the token's own EIP-712 domain, so tokenName and tokenVersion
+// must match the values the token uses for its DOMAIN_SEPARATOR.
+//
+// Args:
+//
+//	permit: The permit data
+//	chainID: The chain ID for the EIP-712 domain
+//	tokenAddress: The token contract address (verifying contract)
+//	tokenName: The token name (e.g., "Dai Stablecoin")
+//	tokenVersion: The token version (e.g., "1")
+//
+// Returns:
+//
+//	32-byte hash suitable for signing or verification
+//	error if hashing fails
+func HashPermit(
+	permit ExactPermit,
+	chainID *big.Int,
+	tokenAddress string,
+	tokenName string,
+	tokenVersion string,
+) ([]byte, error) {
+	domain := TypedDataDomain{
+		Name:              tokenName,
+		Version:           tokenVersion,
+		ChainID:           chainID,
+		VerifyingContract: tokenAddress,
+	}
+
+	value, ok := new(big.Int).SetString(permit.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit value: %s", permit.Value)
+	}
+	nonce, ok := new(big.Int).SetString(permit.Nonce, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit nonce: %s", permit.Nonce)
+	}
+	deadline, ok := new(big.Int).SetString(permit.Deadline, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid permit deadline: %s", permit.Deadline)
+	}
+
+	message := map[string]interface{}{
+		"owner":    common.HexToAddress(permit.Owner).Hex(),
+		"spender":  common.HexToAddress(permit.Spender).Hex(),
+		"value":    value,
+		"nonce":    nonce,
+		"deadline": deadline,
+	}
 
-	return HashTypedData(domain, types, "tokenTransferWithAuthorization", message)
+	return HashTypedData(domain, PermitTypes(), "Permit", message)
 }
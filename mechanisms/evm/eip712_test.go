@@ -0,0 +1,75 @@
+package evm
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestPermitTypeHash(t *testing.T) {
+	// PERMIT_TYPEHASH from EIP-2612
+	const expected = "6e71edae12b1b97f4d1f60370fef10105fa2faae0126114a169c64845d6126c9"
+
+	var encoded string
+	for _, field := range PermitTypes()["Permit"] {
+		if encoded != "" {
+			encoded += ","
+		}
+		encoded += field.Type + " " + field.Name
+	}
+	typeHash := hex.EncodeToString(crypto.Keccak256([]byte("Permit(" + encoded + ")")))
+	if typeHash != expected {
+		t.Errorf("Expected type hash %s, got %s", expected, typeHash)
+	}
+}
+
+func TestHashPermit(t *testing.T) {
+	permit := ExactPermit{
+		Owner:    "0x14791697260E4c9A71f18484C9f997B308e59325",
+		Spender:  "0x2222222222222222222222222222222222222222",
+		Value:    "1000000",
+		Nonce:    "7",
+		Deadline: "1700000000",
+	}
+	token := "0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb" // DAI on Base
+
+	t.Run("known vector", func(t *testing.T) {
+		// keccak256(0x1901 || domainSeparator || hashStruct(permit)), encoded by hand
+		const expected = "55542e145c2e7c161356eaacbca9ba335ddfb2058535c6bdac2ba727f8840735"
+
+		hash, err := HashPermit(permit, big.NewInt(8453), token, "Dai Stablecoin", "1")
+		if err != nil {
+			t.Fatalf("HashPermit failed: %v", err)
+		}
+		if got := hex.EncodeToString(hash); got != expected {
+			t.Errorf("Expected %s, got %s", expected, got)
+		}
+	})
+
+	t.Run("domain is part of the hash", func(t *testing.T) {
+		base, _ := HashPermit(permit, big.NewInt(8453), token, "Dai Stablecoin", "1")
+		other, err := HashPermit(permit, big.NewInt(8453), token, "Dai Stablecoin", "2")
+		if err != nil {
+			t.Fatalf("HashPermit failed: %v", err)
+		}
+		if hex.EncodeToString(base) == hex.EncodeToString(other) {
+			t.Error("Expected a different token version to change the hash")
+		}
+	})
+
+	t.Run("invalid numbers", func(t *testing.T) {
+		for name, tamper := range map[string]func(p *ExactPermit){
+			"value":    func(p *ExactPermit) { p.Value = "1.5" },
+			"nonce":    func(p *ExactPermit) { p.Nonce = "" },
+			"deadline": func(p *ExactPermit) { p.Deadline = "0x10" },
+		} {
+			invalid := permit
+			tamper(&invalid)
+			if _, err := HashPermit(invalid, big.NewInt(8453), token, "Dai Stablecoin", "1"); err == nil {
+				t.Errorf("Expected an invalid %s to fail", name)
+			}
+		}
+	})
+}
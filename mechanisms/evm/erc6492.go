@@ -14,6 +14,23 @@ var erc6492MagicBytes = common.Hex2Bytes(
 	"6492649264926492649264926492649264926492649264926492649264926492",
 )
 
+// erc6492Arguments is the tuple an ERC-6492 signature ABI-encodes:
+// (address factory, bytes factoryCalldata, bytes signature)
+var erc6492Arguments = abi.Arguments{
+	{Type: mustNewABIType("address")}, // factory
+	{Type: mustNewABIType("bytes")},   // factoryCalldata
+	{Type: mustNewABIType("bytes")},   // originalSignature
+}
+
+// mustNewABIType creates an elementary ABI type, panicking on an invalid type name
+func mustNewABIType(name string) abi.Type {
+	ty, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(fmt.Sprintf("invalid ABI type %q: %v", name, err))
+	}
+	return ty
+}
+
 // IsERC6492Signature checks if a signature has the ERC-6492 magic suffix
 //
 // ERC-6492 signatures are wrapped signatures for counterfactual smart contract accounts.
@@ -61,24 +78,8 @@ func ParseERC6492Signature(sig []byte) (*ERC6492SignatureData, error) {
 	// Strip magic value
 	payload := sig[:len(sig)-32]
 
-	// Define ABI types for (address, bytes, bytes)
-	addressTy, err := abi.NewType("address", "", nil)
-	if err != nil {
-		return nil, err
-	}
-	bytesTy, err := abi.NewType("bytes", "", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	arguments := abi.Arguments{
-		{Type: addressTy}, // factory
-		{Type: bytesTy},   // factoryCalldata
-		{Type: bytesTy},   // originalSignature
-	}
-
 	// Unpack the ABI-encoded data
-	unpacked, err := arguments.Unpack(payload)
+	unpacked, err := erc6492Arguments.Unpack(payload)
 	if err != nil {
 		return nil, err
 	}
@@ -112,3 +113,36 @@ func ParseERC6492Signature(sig []byte) (*ERC6492SignatureData, error) {
 		InnerSignature:  innerSignature,
 	}, nil
 }
+
+// WrapERC6492Signature wraps a signature from a counterfactual (not yet deployed) smart contract
+// account in ERC-6492 format, so verifiers can deploy the account before checking it
+//
+// ERC-6492 Format:
+//
+//	abi.encode((address factory, bytes factoryCalldata, bytes signature)) + magicBytes
+//
+// Args:
+//
+//	factory: The factory contract deploying the account
+//	factoryCalldata: The calldata the factory is called with to deploy the account
+//	innerSig: The signature the deployed account accepts through EIP-1271
+//
+// Returns:
+//
+//	The wrapped signature, which ParseERC6492Signature unwraps
+func WrapERC6492Signature(factory common.Address, factoryCalldata []byte, innerSig []byte) []byte {
+	if factoryCalldata == nil {
+		factoryCalldata = []byte{}
+	}
+	if innerSig == nil {
+		innerSig = []byte{}
+	}
+
+	// Packing only fails on Go values not matching the ABI types, which the parameters rule out
+	packed, err := erc6492Arguments.Pack(factory, factoryCalldata, innerSig)
+	if err != nil {
+		panic(fmt.Sprintf("failed to pack ERC-6492 signature: %v", err))
+	}
+
+	return append(packed, erc6492MagicBytes...)
+}
@@ -3,7 +3,6 @@ package evm
 import (
 	"testing"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -51,29 +50,40 @@ func TestIsERC6492Signature(t *testing.T) {
 	}
 }
 
-// createERC6492Signature creates a valid ERC-6492 wrapped signature for testing
-func createERC6492Signature(t *testing.T, factory common.Address, factoryData []byte, originalSig []byte) []byte {
-	addressTy, err := abi.NewType("address", "", nil)
-	if err != nil {
-		t.Fatalf("failed to create address type: %v", err)
+// TestWrapERC6492Signature tests that wrapped signatures round-trip through ParseERC6492Signature
+func TestWrapERC6492Signature(t *testing.T) {
+	factory := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	factoryCalldata := []byte("factory calldata")
+	innerSig := make([]byte, 65)
+	innerSig[64] = 27
+
+	wrapped := WrapERC6492Signature(factory, factoryCalldata, innerSig)
+	if !IsERC6492Signature(wrapped) {
+		t.Fatal("Expected the wrapped signature to end with the ERC-6492 magic value")
 	}
-	bytesTy, err := abi.NewType("bytes", "", nil)
+
+	result, err := ParseERC6492Signature(wrapped)
 	if err != nil {
-		t.Fatalf("failed to create bytes type: %v", err)
+		t.Fatalf("ParseERC6492Signature() error = %v", err)
 	}
-
-	arguments := abi.Arguments{
-		{Type: addressTy},
-		{Type: bytesTy},
-		{Type: bytesTy},
+	if common.BytesToAddress(result.Factory[:]) != factory {
+		t.Errorf("Factory = %v, want %v", common.BytesToAddress(result.Factory[:]), factory)
+	}
+	if !bytesEqual(result.FactoryCalldata, factoryCalldata) {
+		t.Errorf("FactoryCalldata = %v, want %v", result.FactoryCalldata, factoryCalldata)
+	}
+	if !bytesEqual(result.InnerSignature, innerSig) {
+		t.Errorf("InnerSignature = %v, want %v", result.InnerSignature, innerSig)
 	}
 
-	packed, err := arguments.Pack(factory, factoryData, originalSig)
+	// nil calldata and signature encode as empty bytes
+	result, err = ParseERC6492Signature(WrapERC6492Signature(factory, nil, nil))
 	if err != nil {
-		t.Fatalf("failed to pack ERC-6492 data: %v", err)
+		t.Fatalf("ParseERC6492Signature() error = %v", err)
+	}
+	if len(result.FactoryCalldata) != 0 || len(result.InnerSignature) != 0 {
+		t.Errorf("Expected empty calldata and signature, got %v and %v", result.FactoryCalldata, result.InnerSignature)
 	}
-
-	return append(packed, erc6492MagicBytes...)
 }
 
 // TestParseERC6492Signature tests ERC-6492 signature parsing
@@ -94,7 +104,7 @@ func TestParseERC6492Signature(t *testing.T) {
 		{
 			name: "valid ERC-6492 signature",
 			sig: func() []byte {
-				return createERC6492Signature(t, factory, factoryCalldata, originalSig)
+				return WrapERC6492Signature(factory, factoryCalldata, originalSig)
 			},
 			wantErr: false,
 			check: func(t *testing.T, result *ERC6492SignatureData) {
@@ -137,7 +147,7 @@ func TestParseERC6492Signature(t *testing.T) {
 		{
 			name: "ERC-6492 with empty factory data",
 			sig: func() []byte {
-				return createERC6492Signature(t, factory, []byte{}, originalSig)
+				return WrapERC6492Signature(factory, []byte{}, originalSig)
 			},
 			wantErr: false,
 			check: func(t *testing.T, result *ERC6492SignatureData) {
@@ -150,7 +160,7 @@ func TestParseERC6492Signature(t *testing.T) {
 			name: "ERC-6492 with large signature",
 			sig: func() []byte {
 				largeSig := make([]byte, 200)
-				return createERC6492Signature(t, factory, factoryCalldata, largeSig)
+				return WrapERC6492Signature(factory, factoryCalldata, largeSig)
 			},
 			wantErr: false,
 			check: func(t *testing.T, result *ERC6492SignatureData) {
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	x402 "x402-go"
+	"x402-go/mechanisms/evm"
+)
+
+// SignCancelAuthorization signs an EIP-3009 CancelAuthorization for the payer's authorization
+// with nonce on token, without submitting it. Hand the result to a facilitator's
+// CancelAuthorization to have it relayed, or use CancelAuthorization to submit it directly.
+// token is an asset symbol or address, as in payment requirements.
+func (c *ExactEvmScheme) SignCancelAuthorization(ctx context.Context, network x402.Network, token string, nonce string) (*evm.ExactEIP3009Cancellation, error) {
+	networkStr := string(network)
+	config, err := evm.ResolveNetworkConfig(ctx, c.signer, networkStr, token)
+	if err != nil {
+		return nil, err
+	}
+	assetInfo, err := evm.ResolveAssetInfo(ctx, c.signer, networkStr, token)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := evm.CancelAuthorizationMessage(c.payer(), nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cancellations are signed in the token's own domain, like the authorization they cancel
+	domain := evm.TypedDataDomain{
+		Name:              assetInfo.Name,
+		Version:           assetInfo.Version,
+		ChainID:           config.ChainID,
+		VerifyingContract: assetInfo.Address,
+	}
+	signature, err := c.signTypedData(ctx, domain, evm.CancelAuthorizationTypes(), evm.PrimaryTypeCancelAuthorization, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign cancellation: %w", err)
+	}
+
+	return &evm.ExactEIP3009Cancellation{
+		Network:    networkStr,
+		Token:      assetInfo.Address,
+		Authorizer: c.payer(),
+		Nonce:      nonce,
+		Signature:  "0x" + hex.EncodeToString(signature),
+	}, nil
+}
+
+// CancelAuthorization signs and submits an EIP-3009 CancelAuthorization for the payer's
+// authorization with nonce on token, so a payment whose request never completed can't be
+// settled later. It sends a transaction from the signer, which pays its gas, and returns the
+// transaction hash once it is confirmed. The cancellation reverts if the authorization was
+// already settled or cancelled.
+func (c *ExactEvmScheme) CancelAuthorization(ctx context.Context, network x402.Network, token string, nonce string) (string, error) {
+	cancellation, err := c.SignCancelAuthorization(ctx, network, token, nonce)
+	if err != nil {
+		return "", err
+	}
+
+	abi, args, err := evm.CancelAuthorizationCall(*cancellation)
+	if err != nil {
+		return "", err
+	}
+
+	c.logger.Info("cancelling authorization", "network", cancellation.Network, "token", cancellation.Token, "nonce", nonce)
+	txHash, err := c.signer.WriteContract(ctx, cancellation.Token, abi, evm.FunctionCancelAuthorization, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to send cancelAuthorization transaction: %w", err)
+	}
+
+	receipt, err := c.signer.WaitForTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to wait for cancelAuthorization receipt: %w", err)
+	}
+	if receipt.Status == 0 {
+		return "", fmt.Errorf("cancelAuthorization transaction failed")
+	}
+	c.logger.Info("authorization cancelled", "network", cancellation.Network, "transaction", txHash)
+
+	return txHash, nil
+}
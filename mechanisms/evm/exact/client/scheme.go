@@ -3,10 +3,13 @@ package client
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
+	"strconv"
 	"time"
 
+	x402 "x402-go"
 	"x402-go/mechanisms/evm"
 	"x402-go/types"
 
@@ -15,14 +18,124 @@ import (
 
 // ExactEvmScheme implements the SchemeNetworkClient interface for EVM exact payments (V2)
 type ExactEvmScheme struct {
-	signer evm.ClientEvmSigner
+	signer         evm.ClientEvmSigner
+	validityWindow time.Duration
+	checkBalance   bool
+	logger         x402.Logger
+	domainName     string
+	domainVersion  string
+	approval       ApprovalStrategy
+}
+
+// ErrEIP3009SupportUnknown is returned when the asset isn't configured as EIP-3009 capable and
+// the signer can't probe the token on-chain (e.g. it has no RPC connection)
+var ErrEIP3009SupportUnknown = errors.New("cannot determine EIP-3009 support")
+
+// ApprovalStrategy returns the allowance to grant the facilitator contract when its current
+// allowance doesn't cover a payment of value. It applies to tokens without EIP-3009, whether
+// the allowance is granted by an on-chain approve or by an EIP-2612 permit.
+type ApprovalStrategy func(value *big.Int) *big.Int
+
+// maxUint256 is the largest allowance an ERC-20 approve can grant
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// ApproveExact approves exactly the payment amount, so every payment needs its own approval (the default)
+func ApproveExact() ApprovalStrategy {
+	return func(value *big.Int) *big.Int {
+		return value
+	}
+}
+
+// ApproveUnlimited approves the maximum uint256 once, so later payments skip the approve
+// transaction entirely. The facilitator contract can then transfer any amount of the token
+// from the payer, within the payments the payer signs.
+func ApproveUnlimited() ApprovalStrategy {
+	return func(value *big.Int) *big.Int {
+		return new(big.Int).Set(maxUint256)
+	}
+}
+
+// ApproveTopUp approves target, or the payment amount when it is larger, so later payments
+// skip the approval until the buffer is spent
+func ApproveTopUp(target *big.Int) ApprovalStrategy {
+	return func(value *big.Int) *big.Int {
+		if target == nil || target.Cmp(value) < 0 {
+			return value
+		}
+		return new(big.Int).Set(target)
+	}
+}
+
+// ExactEvmSchemeOption configures an ExactEvmScheme
+type ExactEvmSchemeOption func(*ExactEvmScheme)
+
+// WithValidityWindow sets how long signed authorizations remain valid (defaults to 1 hour)
+func WithValidityWindow(window time.Duration) ExactEvmSchemeOption {
+	return func(c *ExactEvmScheme) {
+		if window > 0 {
+			c.validityWindow = window
+		}
+	}
+}
+
+// WithoutBalanceCheck skips reading the payer's token balance before signing.
+// Use it for offline signers, which cannot read the chain.
+func WithoutBalanceCheck() ExactEvmSchemeOption {
+	return func(c *ExactEvmScheme) {
+		c.checkBalance = false
+	}
+}
+
+// WithLogger logs on-chain approvals sent on the payer's behalf (defaults to no logging)
+func WithLogger(logger x402.Logger) ExactEvmSchemeOption {
+	return func(c *ExactEvmScheme) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// WithERC20AuthorizationDomain sets the EIP-712 domain name and version signed for generic
+// ERC-20 payments, for facilitator contracts deployed with a domain other than the default
+// ("Facilitator", "1"). The facilitator must be configured with the same pair.
+func WithERC20AuthorizationDomain(name, version string) ExactEvmSchemeOption {
+	return func(c *ExactEvmScheme) {
+		if name != "" {
+			c.domainName = name
+		}
+		if version != "" {
+			c.domainVersion = version
+		}
+	}
+}
+
+// WithApprovalStrategy sets how much allowance is granted to the facilitator contract when a
+// payment with a token lacking EIP-3009 finds it too low (defaults to ApproveExact)
+func WithApprovalStrategy(strategy ApprovalStrategy) ExactEvmSchemeOption {
+	return func(c *ExactEvmScheme) {
+		if strategy != nil {
+			c.approval = strategy
+		}
+	}
 }
 
 // NewExactEvmScheme creates a new ExactEvmScheme
-func NewExactEvmScheme(signer evm.ClientEvmSigner) *ExactEvmScheme {
-	return &ExactEvmScheme{
-		signer: signer,
+func NewExactEvmScheme(signer evm.ClientEvmSigner, opts ...ExactEvmSchemeOption) *ExactEvmScheme {
+	c := &ExactEvmScheme{
+		signer:         signer,
+		validityWindow: evm.DefaultValidityPeriod * time.Second,
+		checkBalance:   true,
+		logger:         x402.NoopLogger(),
+		domainName:     evm.DefaultERC20AuthorizationDomainName,
+		domainVersion:  evm.DefaultERC20AuthorizationDomainVersion,
+		approval:       ApproveExact(),
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	return c
 }
 
 // Scheme returns the scheme identifier
@@ -35,20 +148,15 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 	ctx context.Context,
 	requirements types.PaymentRequirements,
 ) (types.PaymentPayload, error) {
-	// Validate network
+	// Get network configuration (synthesized on-chain for unlisted eip155 chains with an erc20:0x... asset)
 	networkStr := string(requirements.Network)
-	if !evm.IsValidNetwork(networkStr) {
-		return types.PaymentPayload{}, fmt.Errorf("unsupported network: %s", requirements.Network)
-	}
-
-	// Get network configuration
-	config, err := evm.GetNetworkConfig(networkStr)
+	config, err := evm.ResolveNetworkConfig(ctx, c.signer, networkStr, requirements.Asset)
 	if err != nil {
 		return types.PaymentPayload{}, err
 	}
 
 	// Get asset info
-	assetInfo, err := evm.GetAssetInfo(networkStr, requirements.Asset)
+	assetInfo, err := evm.ResolveAssetInfo(ctx, c.signer, networkStr, requirements.Asset)
 	if err != nil {
 		return types.PaymentPayload{}, err
 	}
@@ -59,14 +167,21 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 		return types.PaymentPayload{}, fmt.Errorf("invalid amount: %s", requirements.Amount)
 	}
 
+	// Fail fast rather than signing an authorization that can't settle
+	if c.checkBalance {
+		if err := c.checkSufficientBalance(ctx, assetInfo.Address, value); err != nil {
+			return types.PaymentPayload{}, err
+		}
+	}
+
 	// Create nonce
 	nonce, err := evm.CreateNonce()
 	if err != nil {
 		return types.PaymentPayload{}, err
 	}
 
-	// V2 specific: No buffer on validAfter (can use immediately)
-	validAfter, validBefore := evm.CreateValidityWindow(time.Hour)
+	// validAfter is backdated by evm.ClockSkewTolerance so it can be used immediately
+	validAfter, validBefore := evm.CreateValidityWindow(c.validityWindow)
 
 	// Extract extra fields for EIP-3009
 	tokenName := assetInfo.Name
@@ -80,31 +195,15 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 		}
 	}
 
-	// Create authorization
-	// Create authorization
 	// Determine flow: EIP-3009 (gasless) or ERC-20 (approve + facilitator method)
-	// We want to prefer EIP-3009 if supported.
-
-	// Check standard support flag first (static config)
-	supportsEIP3009 := assetInfo.SupportsEIP3009
-
-	// If static config says false (or we want to double check), try dynamic check
-	if !supportsEIP3009 {
-		// Use dynamic check
-		// Note: This requires network access. If the signer doesn't support it (e.g. offline keys),
-		// this will fail. We should handle that gracefully or assume false.
-		// For now, we log/ignore error and assume false if check failed.
-		supported, err := evm.VerifyEIP3009Support(ctx, c.signer, config.ChainID, c.signer.Address(), assetInfo.Address)
-		if err == nil && supported {
-			supportsEIP3009 = true
-		}
-		// If verification failed (e.g. no RPC), we default to standard ERC-20 flow which works universally
-		// (though costs gas for verify).
+	supportsEIP3009, err := c.supportsEIP3009(ctx, config, assetInfo, networkStr)
+	if err != nil {
+		return types.PaymentPayload{}, err
 	}
 
 	if supportsEIP3009 {
 		authorization := evm.ExactEIP3009Authorization{
-			From:        c.signer.Address(),
+			From:        c.payer(),
 			To:          requirements.PayTo,
 			Value:       value.String(),
 			ValidAfter:  validAfter.String(),
@@ -112,8 +211,14 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 			Nonce:       nonce,
 		}
 
+		// Facilitators that settle through receiveWithAuthorization need it signed as such
+		primaryType, payloadType := evm.PrimaryTypeTransferWithAuthorization, evm.PayloadTypeEIP3009
+		if function, _ := requirements.Extra[evm.ExtraAuthorizationFunction].(string); function == evm.FunctionReceiveWithAuthorization {
+			primaryType, payloadType = evm.PrimaryTypeReceiveWithAuthorization, evm.PayloadTypeEIP3009Receive
+		}
+
 		// Sign the authorization
-		signature, err := c.signAuthorizationEIP3009(ctx, authorization, config.ChainID, assetInfo.Address, tokenName, tokenVersion)
+		signature, err := c.signAuthorizationEIP3009(ctx, authorization, primaryType, config.ChainID, assetInfo.Address, tokenName, tokenVersion)
 		if err != nil {
 			return types.PaymentPayload{}, fmt.Errorf("failed to sign authorization: %w", err)
 		}
@@ -125,7 +230,7 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 		}
 
 		payloadMap := evmPayload.ToMap()
-		payloadMap["type"] = "authorizationEip3009"
+		payloadMap["type"] = payloadType
 
 		return types.PaymentPayload{
 			X402Version: 2,
@@ -134,53 +239,54 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 	} else {
 		// ERC-20 Authorization (Approvals + Facilitator)
 
+		// The facilitator contract can be deployed at a different address on each network
+		facilitatorContract := config.FacilitatorAddress()
+
 		// 1. Check Allowance
-		allowanceRes, err := c.signer.ReadContract(
-			ctx,
-			assetInfo.Address,
-			evm.ERC20ABI,
-			"allowance",
-			common.HexToAddress(c.signer.Address()),
-			common.HexToAddress(evm.FacilitatorContractAddress),
-		)
+		allowance, err := c.allowance(ctx, assetInfo.Address, facilitatorContract)
 		if err != nil {
-			return types.PaymentPayload{}, fmt.Errorf("failed to check allowance: %w", err)
-		}
-
-		allowance, ok := allowanceRes.(*big.Int)
-		if !ok {
-			return types.PaymentPayload{}, fmt.Errorf("invalid allowance type returned: %T", allowanceRes)
+			return types.PaymentPayload{}, err
 		}
 
-		// 2. Approve if necessary
+		// 2. Approve if necessary, preferring a gasless EIP-2612 permit when the token supports it.
+		// Smart accounts always approve on-chain: most tokens check permits with ecrecover only.
+		var permit *evm.ExactPermit
 		if allowance.Cmp(value) < 0 {
-			fmt.Printf("Approving %s for facilitator...\n", value.String())
-			txHash, err := c.signer.WriteContract(
-				ctx,
-				assetInfo.Address,
-				evm.ERC20ABI,
-				"approve",
-				common.HexToAddress(evm.FacilitatorContractAddress),
-				value,
-			)
-			if err != nil {
-				return types.PaymentPayload{}, fmt.Errorf("failed to send approve transaction: %w", err)
+			amount := c.approvalAmount(value)
+			if permitNonce, ok := evm.GetPermitNonce(ctx, c.signer, assetInfo.Address, c.payer()); ok && !c.isSmartAccount() {
+				permit, err = c.signPermit(ctx, amount, permitNonce, validBefore, config.ChainID, facilitatorContract, assetInfo.Address, tokenName, tokenVersion)
+				if err != nil {
+					return types.PaymentPayload{}, fmt.Errorf("failed to sign permit: %w", err)
+				}
+			} else {
+				c.logger.Info("approving token for facilitator", "network", networkStr, "token", assetInfo.Address, "spender", facilitatorContract, "amount", amount.String())
+				txHash, err := c.signer.WriteContract(
+					ctx,
+					assetInfo.Address,
+					evm.ERC20ABI,
+					evm.FunctionApprove,
+					common.HexToAddress(facilitatorContract),
+					amount,
+				)
+				if err != nil {
+					return types.PaymentPayload{}, fmt.Errorf("failed to send approve transaction: %w", err)
+				}
+
+				// Wait for confirmation
+				receipt, err := c.signer.WaitForTransactionReceipt(ctx, txHash)
+				if err != nil {
+					return types.PaymentPayload{}, fmt.Errorf("failed to wait for approve receipt: %w", err)
+				}
+				if receipt.Status == 0 {
+					return types.PaymentPayload{}, fmt.Errorf("approve transaction failed")
+				}
+				c.logger.Info("approve transaction confirmed", "network", networkStr, "transaction", txHash)
 			}
-
-			// Wait for confirmation
-			receipt, err := c.signer.WaitForTransactionReceipt(ctx, txHash)
-			if err != nil {
-				return types.PaymentPayload{}, fmt.Errorf("failed to wait for approve receipt: %w", err)
-			}
-			if receipt.Status == 0 {
-				return types.PaymentPayload{}, fmt.Errorf("approve transaction failed")
-			}
-			fmt.Println("Approve transaction confirmed.")
 		}
 
 		authorization := evm.ExactERC20Authorization{
 			Token:       assetInfo.Address,
-			From:        c.signer.Address(),
+			From:        c.payer(),
 			To:          requirements.PayTo,
 			Value:       value.String(),
 			ValidAfter:  validAfter.String(),
@@ -189,10 +295,8 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 			NeedApprove: true, // Signal that approval corresponds to this payment
 		}
 
-		// Sign the authorization
-		// Note: The reference implementation uses "Facilitator" domain name and version "1"
-		// which are hardcoded in signAuthorizationERC20
-		signature, err := c.signAuthorizationERC20(ctx, authorization, config.ChainID, evm.FacilitatorContractAddress)
+		// Sign the authorization against the facilitator contract's domain
+		signature, err := c.signAuthorizationERC20(ctx, authorization, config.ChainID, facilitatorContract)
 		if err != nil {
 			return types.PaymentPayload{}, fmt.Errorf("failed to sign authorization: %w", err)
 		}
@@ -201,10 +305,14 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 		evmPayload := &evm.ExactERC20Payload{
 			Signature:     "0x" + hex.EncodeToString(signature),
 			Authorization: authorization,
+			Permit:        permit,
 		}
 
 		payloadMap := evmPayload.ToMap()
-		payloadMap["type"] = "authorization"
+		payloadMap["type"] = evm.PayloadTypeAuthorization
+		if permit != nil {
+			payloadMap["type"] = evm.PayloadTypePermit
+		}
 
 		return types.PaymentPayload{
 			X402Version: 2,
@@ -213,10 +321,124 @@ func (c *ExactEvmScheme) CreatePaymentPayload(
 	}
 }
 
-// signAuthorizationEIP3009 signs the EIP-3009 authorization using EIP-712
+// ProbePayment checks, without signing or sending anything, that the payer can pay
+// requirements: the balance covers the amount (unless WithoutBalanceCheck is set) and the
+// token's payment flow is known. NeedsApproval is set when paying sends an approve
+// transaction first, i.e. the token supports neither EIP-3009 nor a usable permit and the
+// facilitator's allowance is too low.
+func (c *ExactEvmScheme) ProbePayment(ctx context.Context, requirements types.PaymentRequirements) (x402.PaymentProbe, error) {
+	networkStr := string(requirements.Network)
+	config, err := evm.ResolveNetworkConfig(ctx, c.signer, networkStr, requirements.Asset)
+	if err != nil {
+		return x402.PaymentProbe{}, err
+	}
+
+	assetInfo, err := evm.ResolveAssetInfo(ctx, c.signer, networkStr, requirements.Asset)
+	if err != nil {
+		return x402.PaymentProbe{}, err
+	}
+
+	value, ok := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok {
+		return x402.PaymentProbe{}, fmt.Errorf("invalid amount: %s", requirements.Amount)
+	}
+
+	if c.checkBalance {
+		if err := c.checkSufficientBalance(ctx, assetInfo.Address, value); err != nil {
+			return x402.PaymentProbe{}, err
+		}
+	}
+
+	supportsEIP3009, err := c.supportsEIP3009(ctx, config, assetInfo, networkStr)
+	if err != nil || supportsEIP3009 {
+		return x402.PaymentProbe{}, err
+	}
+
+	allowance, err := c.allowance(ctx, assetInfo.Address, config.FacilitatorAddress())
+	if err != nil {
+		return x402.PaymentProbe{}, err
+	}
+	if allowance.Cmp(value) >= 0 {
+		return x402.PaymentProbe{}, nil
+	}
+
+	_, permit := evm.GetPermitNonce(ctx, c.signer, assetInfo.Address, c.payer())
+	return x402.PaymentProbe{NeedsApproval: !permit || c.isSmartAccount()}, nil
+}
+
+// PayloadExpiry returns when a payment payload created by this scheme stops being accepted,
+// its authorization's validBefore
+func (c *ExactEvmScheme) PayloadExpiry(payload types.PaymentPayload) (time.Time, bool) {
+	envelope, err := evm.UnmarshalPayload(payload)
+	if err != nil {
+		return time.Time{}, false
+	}
+	validBefore, err := strconv.ParseInt(envelope.Authorization().ValidBefore, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(validBefore, 0), true
+}
+
+// supportsEIP3009 reports whether the asset is paid through EIP-3009. Static config is trusted
+// when it marks the token as EIP-3009 capable; otherwise the token is probed on-chain, since
+// unlisted tokens may support it too.
+func (c *ExactEvmScheme) supportsEIP3009(ctx context.Context, config *evm.NetworkConfig, assetInfo *evm.AssetInfo, network string) (bool, error) {
+	if assetInfo.SupportsEIP3009 {
+		return true, nil
+	}
+
+	supported, err := evm.VerifyEIP3009Support(ctx, c.signer, config.ChainID, c.payer(), assetInfo.Address)
+	if err != nil {
+		// Falling back to the ERC-20 flow here would cost gas for a token that may not need it
+		return false, fmt.Errorf(
+			"%w: %s on %s: connect the signer to an RPC endpoint, or register the asset with SupportsEIP3009 set (evm.RegisterAsset): %w",
+			ErrEIP3009SupportUnknown, assetInfo.Address, network, err,
+		)
+	}
+	return supported, nil
+}
+
+// approvalAmount is the allowance to grant for a payment of value under the approval strategy,
+// never less than value
+func (c *ExactEvmScheme) approvalAmount(value *big.Int) *big.Int {
+	amount := c.approval(value)
+	if amount == nil || amount.Cmp(value) < 0 {
+		return value
+	}
+	if amount.Cmp(maxUint256) > 0 {
+		return new(big.Int).Set(maxUint256)
+	}
+	return amount
+}
+
+// allowance reads how much of the token spender may transfer from the payer
+func (c *ExactEvmScheme) allowance(ctx context.Context, tokenAddress string, spender string) (*big.Int, error) {
+	result, err := c.signer.ReadContract(
+		ctx,
+		tokenAddress,
+		evm.ERC20ABI,
+		evm.FunctionAllowance,
+		common.HexToAddress(c.payer()),
+		common.HexToAddress(spender),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check allowance: %w", err)
+	}
+
+	allowance, ok := result.(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("invalid allowance type returned: %T", result)
+	}
+	return allowance, nil
+}
+
+// signAuthorizationEIP3009 signs the EIP-3009 authorization using EIP-712 under primaryType
+// (TransferWithAuthorization or ReceiveWithAuthorization)
 func (c *ExactEvmScheme) signAuthorizationEIP3009(
 	ctx context.Context,
 	authorization evm.ExactEIP3009Authorization,
+	primaryType string,
 	chainID *big.Int,
 	verifyingContract string,
 	tokenName string,
@@ -238,7 +460,7 @@ func (c *ExactEvmScheme) signAuthorizationEIP3009(
 			{Name: "chainId", Type: "uint256"},
 			{Name: "verifyingContract", Type: "address"},
 		},
-		"TransferWithAuthorization": {
+		primaryType: {
 			{Name: "from", Type: "address"},
 			{Name: "to", Type: "address"},
 			{Name: "value", Type: "uint256"},
@@ -265,7 +487,7 @@ func (c *ExactEvmScheme) signAuthorizationEIP3009(
 	}
 
 	// Sign the typed data
-	return c.signer.SignTypedData(ctx, domain, types, "TransferWithAuthorization", message)
+	return c.signTypedData(ctx, domain, types, primaryType, message)
 }
 
 // signAuthorizationERC20 signs the ERC-20 authorization using EIP-712
@@ -275,53 +497,120 @@ func (c *ExactEvmScheme) signAuthorizationERC20(
 	chainID *big.Int,
 	verifyingContract string,
 ) ([]byte, error) {
-	// Create EIP-712 domain
-	// Note: The reference implementation hardcodes name "Facilitator" and version "1" for the domain
+	return c.signTypedData(
+		ctx,
+		evm.ERC20AuthorizationDomain(chainID, verifyingContract, c.domainName, c.domainVersion),
+		evm.ERC20AuthorizationTypes(),
+		evm.PrimaryTypeTokenTransferWithAuthorization,
+		evm.ERC20AuthorizationMessage(authorization),
+	)
+}
+
+// signPermit signs an EIP-2612 permit granting the facilitator contract (spender) an allowance of value
+func (c *ExactEvmScheme) signPermit(
+	ctx context.Context,
+	value *big.Int,
+	nonce *big.Int,
+	deadline *big.Int,
+	chainID *big.Int,
+	spender string,
+	tokenAddress string,
+	tokenName string,
+	tokenVersion string,
+) (*evm.ExactPermit, error) {
 	domain := evm.TypedDataDomain{
-		Name:              "Facilitator",
-		Version:           "1",
+		Name:              tokenName,
+		Version:           tokenVersion,
 		ChainID:           chainID,
-		VerifyingContract: verifyingContract,
+		VerifyingContract: tokenAddress,
 	}
 
-	// Define EIP-712 types
-	types := map[string][]evm.TypedDataField{
-		"EIP712Domain": {
-			{Name: "name", Type: "string"},
-			{Name: "version", Type: "string"},
-			{Name: "chainId", Type: "uint256"},
-			{Name: "verifyingContract", Type: "address"},
-		},
-		"tokenTransferWithAuthorization": {
-			{Name: "token", Type: "address"},
-			{Name: "from", Type: "address"},
-			{Name: "to", Type: "address"},
-			{Name: "value", Type: "uint256"},
-			{Name: "validAfter", Type: "uint256"},
-			{Name: "validBefore", Type: "uint256"},
-			{Name: "nonce", Type: "bytes32"},
-			{Name: "needApprove", Type: "bool"},
-		},
+	message := map[string]interface{}{
+		"owner":    c.payer(),
+		"spender":  spender,
+		"value":    value,
+		"nonce":    nonce,
+		"deadline": deadline,
 	}
 
-	// Parse values for message
-	value, _ := new(big.Int).SetString(authorization.Value, 10)
-	validAfter, _ := new(big.Int).SetString(authorization.ValidAfter, 10)
-	validBefore, _ := new(big.Int).SetString(authorization.ValidBefore, 10)
-	nonceBytes, _ := evm.HexToBytes(authorization.Nonce)
+	signature, err := c.signTypedData(ctx, domain, evm.PermitTypes(), "Permit", message)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create message
-	message := map[string]interface{}{
-		"token":       authorization.Token,
-		"from":        authorization.From,
-		"to":          authorization.To,
-		"value":       value,
-		"validAfter":  validAfter,
-		"validBefore": validBefore,
-		"nonce":       nonceBytes,
-		"needApprove": authorization.NeedApprove,
+	return &evm.ExactPermit{
+		Owner:     c.payer(),
+		Spender:   spender,
+		Value:     value.String(),
+		Nonce:     nonce.String(),
+		Deadline:  deadline.String(),
+		Signature: "0x" + hex.EncodeToString(signature),
+	}, nil
+}
+
+// payer returns the address paying: the smart account for a SmartAccountClientSigner, otherwise
+// the signer's own address
+func (c *ExactEvmScheme) payer() string {
+	if account, ok := c.signer.(evm.SmartAccountClientSigner); ok {
+		return account.AccountAddress()
 	}
+	return c.signer.Address()
+}
 
-	// Sign the typed data
-	return c.signer.SignTypedData(ctx, domain, types, "tokenTransferWithAuthorization", message)
+// isSmartAccount reports whether the client pays from a smart contract account
+func (c *ExactEvmScheme) isSmartAccount() bool {
+	_, ok := c.signer.(evm.SmartAccountClientSigner)
+	return ok
+}
+
+// signTypedData signs with the client's signer and normalizes EOA signatures to low-S, since
+// KMS and remote signers may return either form and some verifiers reject high-S signatures.
+// Smart account signatures (EIP-1271 or ERC-6492 wrapped) are passed through untouched.
+func (c *ExactEvmScheme) signTypedData(
+	ctx context.Context,
+	domain evm.TypedDataDomain,
+	types map[string][]evm.TypedDataField,
+	primaryType string,
+	message map[string]interface{},
+) ([]byte, error) {
+	signature, err := c.signer.SignTypedData(ctx, domain, types, primaryType, message)
+	if err != nil {
+		return nil, err
+	}
+	if c.isSmartAccount() {
+		return signature, nil
+	}
+	return evm.NormalizeSignatureLowS(signature), nil
+}
+
+// checkSufficientBalance returns an insufficient_balance PaymentError when the signer holds less
+// than required of the token. A balance that cannot be read (e.g. the signer has no RPC access)
+// does not block the payment; the facilitator still checks it at settlement.
+func (c *ExactEvmScheme) checkSufficientBalance(ctx context.Context, tokenAddress string, required *big.Int) error {
+	result, err := c.signer.ReadContract(
+		ctx,
+		tokenAddress,
+		evm.ERC20ABI,
+		evm.FunctionBalanceOf,
+		common.HexToAddress(c.payer()),
+	)
+	if err != nil {
+		return nil
+	}
+
+	balance, ok := result.(*big.Int)
+	if !ok || balance.Cmp(required) >= 0 {
+		return nil
+	}
+
+	return x402.NewPaymentError(
+		x402.ReasonInsufficientBalance,
+		fmt.Sprintf("balance %s is below the required %s", balance, required),
+		map[string]interface{}{
+			"payer":    c.payer(),
+			"asset":    tokenAddress,
+			"balance":  balance.String(),
+			"required": required.String(),
+		},
+	)
 }
@@ -0,0 +1,399 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	x402 "x402-go"
+	"x402-go/mechanisms/evm"
+	"x402-go/types"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// offlineSigner signs locally but has no RPC connection
+type offlineSigner struct {
+	reads int
+}
+
+func (s *offlineSigner) Address() string {
+	return "0x1111111111111111111111111111111111111111"
+}
+
+func (s *offlineSigner) SignTypedData(ctx context.Context, domain evm.TypedDataDomain, types map[string][]evm.TypedDataField, primaryType string, message map[string]interface{}) ([]byte, error) {
+	signature := make([]byte, 65)
+	signature[64] = 27
+	return signature, nil
+}
+
+func (s *offlineSigner) ReadContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (interface{}, error) {
+	s.reads++
+	return nil, errors.New("RPC client not configured")
+}
+
+func (s *offlineSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	return "", errors.New("RPC client not configured")
+}
+
+func (s *offlineSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error) {
+	return nil, errors.New("RPC client not configured")
+}
+
+// smartAccountSigner pays from a counterfactual smart account, returning ERC-6492 wrapped signatures
+type smartAccountSigner struct {
+	offlineSigner
+}
+
+func (s *smartAccountSigner) AccountAddress() string {
+	return "0x3333333333333333333333333333333333333333"
+}
+
+func (s *smartAccountSigner) SignTypedData(ctx context.Context, domain evm.TypedDataDomain, types map[string][]evm.TypedDataField, primaryType string, message map[string]interface{}) ([]byte, error) {
+	wrapped := make([]byte, 96)
+	magic, _ := evm.HexToBytes(evm.ERC6492MagicValue)
+	return append(wrapped, magic...), nil
+}
+
+func TestCreatePaymentPayloadOfflineSigner(t *testing.T) {
+	requirements := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:1",
+		Amount:  "1000",
+		PayTo:   "0x2222222222222222222222222222222222222222",
+	}
+
+	t.Run("static EIP-3009 support skips the probe", func(t *testing.T) {
+		signer := &offlineSigner{}
+		scheme := NewExactEvmScheme(signer, WithoutBalanceCheck())
+
+		req := requirements
+		req.Asset = "USDC"
+		payload, err := scheme.CreatePaymentPayload(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if payload.Payload["type"] != evm.PayloadTypeEIP3009 {
+			t.Errorf("Expected an EIP-3009 payload, got %v", payload.Payload["type"])
+		}
+		if signer.reads != 0 {
+			t.Errorf("Expected no on-chain reads, got %d", signer.reads)
+		}
+	})
+
+	t.Run("unknown support without RPC is an error", func(t *testing.T) {
+		signer := &offlineSigner{}
+		scheme := NewExactEvmScheme(signer, WithoutBalanceCheck())
+
+		req := requirements
+		req.Asset = "DAI"
+		_, err := scheme.CreatePaymentPayload(context.Background(), req)
+		if !errors.Is(err, ErrEIP3009SupportUnknown) {
+			t.Fatalf("Expected ErrEIP3009SupportUnknown, got %v", err)
+		}
+	})
+}
+
+func TestPayloadExpiry(t *testing.T) {
+	scheme := NewExactEvmScheme(&offlineSigner{}, WithoutBalanceCheck(), WithValidityWindow(time.Minute))
+	payload, err := scheme.CreatePaymentPayload(context.Background(), types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000",
+		PayTo:   "0x2222222222222222222222222222222222222222",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expiry, ok := scheme.PayloadExpiry(payload)
+	if !ok {
+		t.Fatal("Expected the payload to report its expiry")
+	}
+	if remaining := time.Until(expiry); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("Expected expiry within the validity window, got %v", remaining)
+	}
+
+	if _, ok := scheme.PayloadExpiry(types.PaymentPayload{Payload: map[string]interface{}{"type": "unknown"}}); ok {
+		t.Error("Expected no expiry for a foreign payload")
+	}
+}
+
+func TestCreatePaymentPayloadSmartAccount(t *testing.T) {
+	signer := &smartAccountSigner{}
+	scheme := NewExactEvmScheme(signer, WithoutBalanceCheck())
+
+	payload, err := scheme.CreatePaymentPayload(context.Background(), types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000",
+		PayTo:   "0x2222222222222222222222222222222222222222",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	authorization, _ := payload.Payload["authorization"].(map[string]interface{})
+	if authorization["from"] != signer.AccountAddress() {
+		t.Errorf("Expected payment from the smart account %s, got %v", signer.AccountAddress(), authorization["from"])
+	}
+
+	signature, _ := payload.Payload["signature"].(string)
+	if !strings.HasSuffix(signature, strings.TrimPrefix(evm.ERC6492MagicValue, "0x")) {
+		t.Errorf("Expected the ERC-6492 signature to be passed through, got %s", signature)
+	}
+}
+
+// balanceSigner reports balance for every balanceOf read
+type balanceSigner struct {
+	offlineSigner
+	balance *big.Int
+}
+
+func (s *balanceSigner) ReadContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (interface{}, error) {
+	if functionName == evm.FunctionBalanceOf {
+		return s.balance, nil
+	}
+	return s.offlineSigner.ReadContract(ctx, address, abi, functionName, args...)
+}
+
+func TestProbePayment(t *testing.T) {
+	requirements := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000",
+		PayTo:   "0x2222222222222222222222222222222222222222",
+	}
+
+	t.Run("EIP-3009 needs no approval", func(t *testing.T) {
+		scheme := NewExactEvmScheme(&balanceSigner{balance: big.NewInt(1000)})
+
+		probe, err := scheme.ProbePayment(context.Background(), requirements)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if probe.NeedsApproval {
+			t.Error("Expected no approval for an EIP-3009 token")
+		}
+	})
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		scheme := NewExactEvmScheme(&balanceSigner{balance: big.NewInt(999)})
+
+		_, err := scheme.ProbePayment(context.Background(), requirements)
+		var paymentErr *x402.PaymentError
+		if !errors.As(err, &paymentErr) || paymentErr.Code != x402.ReasonInsufficientBalance {
+			t.Errorf("Expected insufficient_balance, got %v", err)
+		}
+	})
+
+	t.Run("unknown support without RPC is an error", func(t *testing.T) {
+		scheme := NewExactEvmScheme(&offlineSigner{}, WithoutBalanceCheck())
+
+		req := requirements
+		req.Asset = "DAI"
+		if _, err := scheme.ProbePayment(context.Background(), req); !errors.Is(err, ErrEIP3009SupportUnknown) {
+			t.Errorf("Expected ErrEIP3009SupportUnknown, got %v", err)
+		}
+	})
+}
+
+// domainSigner records the EIP-712 domain it signs
+type domainSigner struct {
+	offlineSigner
+	domain evm.TypedDataDomain
+}
+
+func (s *domainSigner) SignTypedData(ctx context.Context, domain evm.TypedDataDomain, types map[string][]evm.TypedDataField, primaryType string, message map[string]interface{}) ([]byte, error) {
+	s.domain = domain
+	return s.offlineSigner.SignTypedData(ctx, domain, types, primaryType, message)
+}
+
+func TestERC20AuthorizationDomain(t *testing.T) {
+	authorization := evm.ExactERC20Authorization{
+		Token:       "0x4444444444444444444444444444444444444444",
+		From:        "0x1111111111111111111111111111111111111111",
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "1000",
+		ValidAfter:  "0",
+		ValidBefore: "9999999999",
+		Nonce:       "0x" + strings.Repeat("00", 32),
+	}
+
+	tests := []struct {
+		name        string
+		opts        []ExactEvmSchemeOption
+		wantName    string
+		wantVersion string
+	}{
+		{"default", nil, evm.DefaultERC20AuthorizationDomainName, evm.DefaultERC20AuthorizationDomainVersion},
+		{"custom", []ExactEvmSchemeOption{WithERC20AuthorizationDomain("MyFacilitator", "2")}, "MyFacilitator", "2"},
+		{"empty keeps default", []ExactEvmSchemeOption{WithERC20AuthorizationDomain("", "")}, evm.DefaultERC20AuthorizationDomainName, evm.DefaultERC20AuthorizationDomainVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := &domainSigner{}
+			scheme := NewExactEvmScheme(signer, tt.opts...)
+			if _, err := scheme.signAuthorizationERC20(context.Background(), authorization, big.NewInt(8453), "0x5555555555555555555555555555555555555555"); err != nil {
+				t.Fatalf("signAuthorizationERC20() failed: %v", err)
+			}
+			if signer.domain.Name != tt.wantName || signer.domain.Version != tt.wantVersion {
+				t.Errorf("domain = (%q, %q), want (%q, %q)", signer.domain.Name, signer.domain.Version, tt.wantName, tt.wantVersion)
+			}
+		})
+	}
+}
+
+// keySigner signs EIP-712 hashes with a private key
+type keySigner struct {
+	offlineSigner
+	key *ecdsa.PrivateKey
+}
+
+func (s *keySigner) Address() string {
+	return crypto.PubkeyToAddress(s.key.PublicKey).Hex()
+}
+
+func (s *keySigner) SignTypedData(ctx context.Context, domain evm.TypedDataDomain, types map[string][]evm.TypedDataField, primaryType string, message map[string]interface{}) ([]byte, error) {
+	hash, err := evm.HashTypedData(domain, types, primaryType, message)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(hash, s.key)
+}
+
+func TestERC20AuthorizationSignatureMatchesHash(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	signer := &keySigner{key: key}
+	chainID := big.NewInt(8453)
+	facilitatorContract := "0x5555555555555555555555555555555555555555"
+
+	authorization := evm.ExactERC20Authorization{
+		Token:       "0x4444444444444444444444444444444444444444",
+		From:        strings.ToLower(signer.Address()),
+		To:          "0x2222222222222222222222222222222222222222",
+		Value:       "1000",
+		ValidAfter:  "0",
+		ValidBefore: "9999999999",
+		Nonce:       "0x" + strings.Repeat("ab", 32),
+		NeedApprove: true,
+	}
+
+	tests := []struct {
+		name          string
+		opts          []ExactEvmSchemeOption
+		domainName    string
+		domainVersion string
+	}{
+		{"default domain", nil, "", ""},
+		{"custom domain", []ExactEvmSchemeOption{WithERC20AuthorizationDomain("MyFacilitator", "2")}, "MyFacilitator", "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := NewExactEvmScheme(signer, tt.opts...)
+			signature, err := scheme.signAuthorizationERC20(context.Background(), authorization, chainID, facilitatorContract)
+			if err != nil {
+				t.Fatalf("signAuthorizationERC20() failed: %v", err)
+			}
+
+			hash, err := evm.HashERC20Authorization(authorization, chainID, facilitatorContract, tt.domainName, tt.domainVersion)
+			if err != nil {
+				t.Fatalf("HashERC20Authorization() failed: %v", err)
+			}
+			pubKey, err := crypto.SigToPub(hash, signature)
+			if err != nil {
+				t.Fatalf("SigToPub() failed: %v", err)
+			}
+			if recovered := crypto.PubkeyToAddress(*pubKey).Hex(); recovered != signer.Address() {
+				t.Errorf("Signature recovers %s against HashERC20Authorization, want %s", recovered, signer.Address())
+			}
+		})
+	}
+}
+
+func TestApprovalStrategy(t *testing.T) {
+	value := big.NewInt(1000)
+
+	tests := []struct {
+		name     string
+		strategy ApprovalStrategy
+		want     *big.Int
+	}{
+		{name: "default approves the exact amount", want: value},
+		{name: "exact", strategy: ApproveExact(), want: value},
+		{name: "unlimited", strategy: ApproveUnlimited(), want: maxUint256},
+		{name: "top up to a larger target", strategy: ApproveTopUp(big.NewInt(50000)), want: big.NewInt(50000)},
+		{name: "top up below the payment covers the payment", strategy: ApproveTopUp(big.NewInt(10)), want: value},
+		{name: "strategy below the payment covers the payment", strategy: func(*big.Int) *big.Int { return big.NewInt(1) }, want: value},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := NewExactEvmScheme(&offlineSigner{}, WithApprovalStrategy(tt.strategy))
+			if got := scheme.approvalAmount(value); got.Cmp(tt.want) != 0 {
+				t.Errorf("Expected approval of %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSignCancelAuthorizationMatchesHash(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	signer := &keySigner{key: key}
+	scheme := NewExactEvmScheme(signer)
+	nonce := "0x" + strings.Repeat("cd", 32)
+
+	cancellation, err := scheme.SignCancelAuthorization(context.Background(), "eip155:1", "USDC", nonce)
+	if err != nil {
+		t.Fatalf("SignCancelAuthorization() failed: %v", err)
+	}
+	if cancellation.Authorizer != signer.Address() || cancellation.Nonce != nonce {
+		t.Errorf("Unexpected cancellation %+v", cancellation)
+	}
+
+	assetInfo, err := evm.GetAssetInfo("eip155:1", "USDC")
+	if err != nil {
+		t.Fatalf("GetAssetInfo() failed: %v", err)
+	}
+	if cancellation.Token != assetInfo.Address {
+		t.Errorf("Expected token %s, got %s", assetInfo.Address, cancellation.Token)
+	}
+
+	hash, err := evm.HashEIP3009CancelAuthorization(signer.Address(), nonce, big.NewInt(1), assetInfo.Address, assetInfo.Name, assetInfo.Version)
+	if err != nil {
+		t.Fatalf("HashEIP3009CancelAuthorization() failed: %v", err)
+	}
+	signature, err := evm.HexToBytes(cancellation.Signature)
+	if err != nil {
+		t.Fatalf("HexToBytes() failed: %v", err)
+	}
+	pubKey, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		t.Fatalf("SigToPub() failed: %v", err)
+	}
+	if recovered := crypto.PubkeyToAddress(*pubKey).Hex(); recovered != signer.Address() {
+		t.Errorf("Signature recovers %s against HashEIP3009CancelAuthorization, want %s", recovered, signer.Address())
+	}
+
+	// EOA cancellations are submitted through the v,r,s overload
+	abi, args, err := evm.CancelAuthorizationCall(*cancellation)
+	if err != nil {
+		t.Fatalf("CancelAuthorizationCall() failed: %v", err)
+	}
+	if string(abi) != string(evm.CancelAuthorizationVRSABI) || len(args) != 5 {
+		t.Errorf("Expected the v,r,s overload with 5 arguments, got %d arguments", len(args))
+	}
+}
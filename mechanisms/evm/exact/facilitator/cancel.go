@@ -0,0 +1,182 @@
+package facilitator
+
+import (
+	"context"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	x402 "x402-go"
+	"x402-go/mechanisms/evm"
+)
+
+// maxPendingAuthorizationAge bounds how long a verified authorization stays cancellable through
+// the facilitator, whatever its validBefore
+const maxPendingAuthorizationAge = time.Hour
+
+// pendingAuthorizations records the EIP-3009 authorizations this scheme has verified and not
+// settled. Only those can be cancelled through CancelAuthorization, so the facilitator never
+// spends gas on nonces it has never seen. It is kept in memory.
+type pendingAuthorizations struct {
+	mu      sync.Mutex
+	entries map[string]pendingAuthorization
+}
+
+// pendingAuthorization is a verified authorization. network and asset are the requirements'
+// own strings, which settlement reserves the NonceGuard with.
+type pendingAuthorization struct {
+	network string
+	asset   string
+	expires time.Time
+}
+
+// pendingAuthorizationKey identifies an authorization by its resolved token address;
+// addresses and nonces compare case-insensitively
+func pendingAuthorizationKey(network, token, from, nonce string) string {
+	return strings.ToLower(strings.Join([]string{evm.NormalizeNetwork(network), token, from, nonce}, "|"))
+}
+
+// add records an authorization verified against network and asset until its validBefore,
+// or maxPendingAuthorizationAge from now if that is sooner
+func (p *pendingAuthorizations) add(key string, network, asset string, validBefore string) {
+	now := time.Now()
+	expires := now.Add(maxPendingAuthorizationAge)
+	if unix, err := strconv.ParseInt(validBefore, 10, 64); err == nil && time.Unix(unix, 0).Before(expires) {
+		expires = time.Unix(unix, 0)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.entries == nil {
+		p.entries = make(map[string]pendingAuthorization)
+	}
+	for k, entry := range p.entries {
+		if !now.Before(entry.expires) {
+			delete(p.entries, k)
+		}
+	}
+	p.entries[key] = pendingAuthorization{network: network, asset: asset, expires: expires}
+}
+
+// remove forgets an authorization once it was settled or cancelled
+func (p *pendingAuthorizations) remove(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.entries, key)
+}
+
+// get returns an authorization that was verified, and neither settled nor expired
+func (p *pendingAuthorizations) get(key string) (pendingAuthorization, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entry, ok := p.entries[key]
+	return entry, ok && time.Now().Before(entry.expires)
+}
+
+// CancelAuthorization relays a signed EIP-3009 CancelAuthorization to the token, invalidating
+// the authorizer's authorization with that nonce so it can no longer be settled. A client whose
+// payment request never completed can use it to revoke the outstanding authorization without
+// holding gas itself.
+//
+// Since the facilitator pays the gas, only authorizations this scheme instance has verified and
+// not yet settled can be cancelled (within maxPendingAuthorizationAge of verification); anything
+// else fails with authorization_not_verified. The cancellation is reserved with the NonceGuard
+// so it can't race a settlement of the same authorization, its signature is checked against the
+// token's EIP-712 domain, the RateLimiter is applied to the authorizer, and the nonce must still
+// be unused. Cancellation only applies to EIP-3009 tokens, since the nonces of generic ERC-20
+// authorizations are tracked by the facilitator contract.
+func (f *ExactEvmScheme) CancelAuthorization(ctx context.Context, cancellation evm.ExactEIP3009Cancellation) (*x402.SettleResponse, error) {
+	network := x402.Network(cancellation.Network)
+	authorizer := cancellation.Authorizer
+
+	config, err := evm.ResolveNetworkConfig(ctx, f.signer, cancellation.Network, cancellation.Token)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonFailedToGetNetworkConfig, authorizer, network, "", err)
+	}
+	assetInfo, err := evm.ResolveAssetInfo(ctx, f.signer, cancellation.Network, cancellation.Token)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonFailedToGetAssetInfo, authorizer, network, "", err)
+	}
+
+	// Only relay cancellations of payments this facilitator is holding
+	pendingKey := pendingAuthorizationKey(cancellation.Network, assetInfo.Address, authorizer, cancellation.Nonce)
+	pending, ok := f.pending.get(pendingKey)
+	if !ok {
+		return nil, x402.NewSettleError(x402.ReasonAuthorizationNotVerified, authorizer, network, "", nil)
+	}
+
+	// A settlement of the same authorization may be in flight
+	release, ok := f.config.NonceGuard.Reserve(pending.network, pending.asset, authorizer, cancellation.Nonce)
+	if !ok {
+		return nil, x402.NewSettleError(x402.ReasonNonceInFlight, authorizer, network, "", nil)
+	}
+	defer release()
+
+	// Check the signature before spending gas on it
+	hash, err := evm.HashEIP3009CancelAuthorization(authorizer, cancellation.Nonce, config.ChainID, assetInfo.Address, assetInfo.Name, assetInfo.Version)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonFailedToHashAuthorization, authorizer, network, "", err)
+	}
+	signature, err := evm.HexToBytes(cancellation.Signature)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonInvalidSignatureFormat, authorizer, network, "", err)
+	}
+	signature, err = evm.ExpandCompactEOASignature(ctx, f.signer, authorizer, signature)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonFailedToCheckDeployment, authorizer, network, "", err)
+	}
+	valid, _, err := evm.VerifyUniversalSignature(ctx, f.signer, authorizer, [32]byte(hash), signature, false, f.signatureOptions()...)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonFailedToVerifySignature, authorizer, network, "", err)
+	}
+	if !valid {
+		return nil, x402.NewSettleError(x402.ReasonInvalidSignature, authorizer, network, "", nil)
+	}
+
+	// Only requests signed by the authorizer count against its quota
+	if f.config.RateLimiter != nil && !f.config.RateLimiter.Allow(authorizer) {
+		return nil, x402.NewSettleError(x402.ReasonRateLimited, authorizer, network, "", nil)
+	}
+
+	// A used or already cancelled nonce would only make the transaction revert
+	used, err := f.checkNonceUsed(ctx, authorizer, cancellation.Nonce, assetInfo.Address)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonFailedToCheckNonce, authorizer, network, "", err)
+	}
+	if used {
+		f.pending.remove(pendingKey)
+		return nil, x402.NewSettleError(x402.ReasonNonceAlreadyUsed, authorizer, network, "", nil)
+	}
+
+	cancellation.Signature = "0x" + hex.EncodeToString(signature)
+	abi, args, err := evm.CancelAuthorizationCall(cancellation)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonInvalidSignatureFormat, authorizer, network, "", err)
+	}
+
+	txHash, err := f.signer.WriteContract(ctx, assetInfo.Address, abi, evm.FunctionCancelAuthorization, args...)
+	if err != nil {
+		return nil, x402.NewSettleError(evm.SendErrorReason(err), authorizer, network, "", err)
+	}
+
+	// The cancellation may be mined even if waiting for it fails, so it can't be relayed again
+	f.pending.remove(pendingKey)
+
+	receipt, err := f.signer.WaitForTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, x402.NewSettleError(evm.ReceiptErrorReason(err), authorizer, network, txHash, err)
+	}
+	if receipt.Status != evm.TxStatusSuccess {
+		return nil, x402.NewSettleError(x402.ReasonTransactionFailed, authorizer, network, txHash, nil)
+	}
+
+	return &x402.SettleResponse{
+		Success:     true,
+		Transaction: txHash,
+		Network:     network,
+		Payer:       authorizer,
+	}, nil
+}
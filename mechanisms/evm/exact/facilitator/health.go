@@ -0,0 +1,99 @@
+package facilitator
+
+import (
+	"context"
+	"fmt"
+
+	x402 "x402-go"
+	"x402-go/mechanisms/evm"
+)
+
+// HealthCheck reports whether the scheme can settle on network: that the signer's RPC endpoint
+// answers on the network's chain, that the facilitator contract has code where settlements use
+// it, and that every signer holds enough native token for gas (see MinSignerBalance).
+//
+// A missing contract only makes the network unhealthy with SettlementModeFacilitatorContract;
+// by default EIP-3009 payments settle through the token instead.
+func (f *ExactEvmScheme) HealthCheck(ctx context.Context, network x402.Network) x402.NetworkHealth {
+	health := x402.NetworkHealth{Network: network, Scheme: evm.SchemeExact}
+	for _, address := range f.signer.GetAddresses() {
+		health.Signers = append(health.Signers, x402.SignerHealth{Address: address})
+	}
+	fail := func(format string, args ...interface{}) {
+		health.Errors = append(health.Errors, fmt.Sprintf(format, args...))
+	}
+
+	chainID, err := f.signer.GetChainID(ctx)
+	if err != nil {
+		fail("RPC unreachable: %v", err)
+		return health
+	}
+	health.RPCReachable = true
+	if config, err := evm.GetNetworkConfig(string(network)); err == nil && config.ChainID.Cmp(chainID) != 0 {
+		fail("RPC serves chain %s, want %s", chainID, config.ChainID)
+	}
+
+	if f.config.SettlementMode != SettlementModeDirectToken {
+		contract := evm.GetFacilitatorContractAddress(string(network))
+		code, err := f.signer.GetCode(ctx, contract)
+		if err != nil {
+			fail("failed to check facilitator contract %s: %v", contract, err)
+		} else {
+			deployed := len(code) > 0
+			health.ContractDeployed = &deployed
+			if !deployed && f.config.SettlementMode == SettlementModeFacilitatorContract {
+				fail("no facilitator contract deployed at %s", contract)
+			}
+		}
+	}
+
+	for i := range health.Signers {
+		signer := &health.Signers[i]
+		balance, err := f.signer.GetBalance(ctx, signer.Address, "")
+		if err != nil {
+			fail("failed to get balance of %s: %v", signer.Address, err)
+			continue
+		}
+		signer.Balance = balance.String()
+		if f.config.MinSignerBalance != nil && balance.Cmp(f.config.MinSignerBalance) < 0 {
+			signer.LowBalance = true
+			fail("signer %s balance %s is below %s", signer.Address, balance, f.config.MinSignerBalance)
+		}
+	}
+
+	health.Healthy = len(health.Errors) == 0
+	return health
+}
+
+// checkSignerGas reads the native balance of every signer before a settlement when
+// MinSignerBalance is set, and logs a warning for each one below it. With RefuseSettleOnLowGas,
+// it fails with ReasonFacilitatorLowGas when no signer is above the threshold. A balance that
+// can't be read counts as funded, so a flaky read doesn't block settlements.
+func (f *ExactEvmScheme) checkSignerGas(ctx context.Context, network x402.Network) error {
+	threshold := f.config.MinSignerBalance
+	if threshold == nil {
+		return nil
+	}
+
+	funded := false
+	for _, address := range f.signer.GetAddresses() {
+		balance, err := f.signer.GetBalance(ctx, address, "")
+		if err != nil {
+			f.config.Logger.Warn("failed to read facilitator signer balance", "network", network, "signer", address, "error", err)
+			funded = true
+			continue
+		}
+		if balance.Cmp(threshold) >= 0 {
+			funded = true
+			continue
+		}
+		f.config.Logger.Warn("facilitator signer low on gas",
+			"network", network, "signer", address, "balance", balance.String(), "threshold", threshold.String())
+	}
+
+	if !funded && f.config.RefuseSettleOnLowGas {
+		err := fmt.Errorf("%w: every signer holds less than %s wei", evm.ErrSignerLowGas, threshold)
+		return x402.NewSettleError(x402.ReasonFacilitatorLowGas, "", network, "", err)
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package facilitator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	x402 "x402-go"
+	"x402-go/mechanisms/evm"
+)
+
+// RefundRecord describes a refund made by ExactEvmScheme.Refund
+type RefundRecord struct {
+	Settlement  string // Transaction of the refunded settlement
+	Transaction string // Refund transaction
+	Network     x402.Network
+	Payer       string
+	Token       string
+	Amount      *big.Int
+	Reason      string
+	Time        time.Time
+}
+
+// DefaultRefundWindow is how long a MemoryRefundLedger keeps a settlement refundable
+const DefaultRefundWindow = 24 * time.Hour
+
+// RefundLedger records the settlements a facilitator sent and the refunds Refund paid out, so
+// only the facilitator's own settlements are refunded, each transfer at most once.
+//
+// MemoryRefundLedger covers a single facilitator instance and is lost on restart. Facilitators
+// running several instances, or refunding settlements from before a restart, should share a
+// persistent implementation.
+type RefundLedger interface {
+	// RecordSettlement records a settlement transaction the facilitator sent on network
+	RecordSettlement(network x402.Network, txHash string)
+
+	// IsSettlement reports whether txHash was recorded as a settlement on network
+	IsSettlement(network x402.Network, txHash string) bool
+
+	// Reserve marks the settlement transfer identified by key as being refunded. It reports
+	// false when the transfer was refunded, or is being refunded, already.
+	Reserve(key string) bool
+
+	// Release undoes Reserve for a refund that was never sent
+	Release(key string)
+
+	// Complete records the refund sent for a reserved transfer
+	Complete(key string, record RefundRecord)
+
+	// Refunds returns the refunds recorded by Complete
+	Refunds() []RefundRecord
+}
+
+// MemoryRefundLedger is an in-process RefundLedger. Settlements stay refundable for a window;
+// refunds are kept for the ledger's lifetime.
+type MemoryRefundLedger struct {
+	mu          sync.Mutex
+	window      time.Duration
+	settlements map[string]time.Time     // keyed by network and transaction; when they stop being refundable
+	records     map[string]*RefundRecord // keyed by transfer; nil while the refund is in flight
+	now         func() time.Time         // overridable in tests
+}
+
+// NewMemoryRefundLedger creates an in-memory RefundLedger. A window <= 0 uses
+// DefaultRefundWindow.
+func NewMemoryRefundLedger(window time.Duration) *MemoryRefundLedger {
+	if window <= 0 {
+		window = DefaultRefundWindow
+	}
+	return &MemoryRefundLedger{
+		window:      window,
+		settlements: make(map[string]time.Time),
+		records:     make(map[string]*RefundRecord),
+		now:         time.Now,
+	}
+}
+
+// settlementKey identifies a settlement transaction on a network
+func settlementKey(network x402.Network, txHash string) string {
+	return evm.NormalizeNetwork(string(network)) + "|" + strings.ToLower(txHash)
+}
+
+// RecordSettlement records a settlement, dropping those whose refund window has passed
+func (l *MemoryRefundLedger) RecordSettlement(network x402.Network, txHash string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, expires := range l.settlements {
+		if !now.Before(expires) {
+			delete(l.settlements, key)
+		}
+	}
+	l.settlements[settlementKey(network, txHash)] = now.Add(l.window)
+}
+
+// IsSettlement reports whether txHash was recorded on network within the refund window
+func (l *MemoryRefundLedger) IsSettlement(network x402.Network, txHash string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expires, ok := l.settlements[settlementKey(network, txHash)]
+	return ok && l.now().Before(expires)
+}
+
+// Reserve marks a transfer as being refunded unless it already is
+func (l *MemoryRefundLedger) Reserve(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, taken := l.records[key]; taken {
+		return false
+	}
+	l.records[key] = nil
+	return true
+}
+
+// Release drops the reservation of a refund that wasn't sent
+func (l *MemoryRefundLedger) Release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.records, key)
+}
+
+// Complete records the refund sent for a reserved transfer
+func (l *MemoryRefundLedger) Complete(key string, record RefundRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records[key] = &record
+}
+
+// Refunds returns the completed refunds, oldest first
+func (l *MemoryRefundLedger) Refunds() []RefundRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	records := make([]RefundRecord, 0, len(l.records))
+	for _, record := range l.records {
+		if record != nil {
+			records = append(records, *record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records
+}
+
+// refundKey identifies one transfer of a settlement transaction; a batch settlement carries
+// several
+func refundKey(txHash string, logIndex uint) string {
+	return fmt.Sprintf("%s:%d", strings.ToLower(txHash), logIndex)
+}
+
+// Refund sends the payer of a settled payment back the amount and token it paid, e.g. when the
+// resource server fails to deliver after settling.
+//
+// The settlement must be one this facilitator sent, as recorded in ExactEvmSchemeConfig.RefundLedger,
+// and is checked on-chain: its transaction must have succeeded and contain an ERC-20 Transfer
+// of one of the network's configured assets from originalSettle.Payer. The refund repays that
+// transfer in full. It is paid by ExactEvmSchemeConfig.RefundSigner when set, which must be the
+// transfer's recipient (the payee), and otherwise by the facilitator's own balance. Each
+// settlement transfer is refunded at most once per ledger. reason is kept in the RefundRecord
+// returned by Refunds.
+//
+// Refund pays out real funds and doesn't check that the payee agreed to the refund: only expose
+// it to callers trusted to spend them (see "Refunds" in the EVM mechanism README).
+func (f *ExactEvmScheme) Refund(ctx context.Context, originalSettle *x402.SettleResponse, reason string) (*x402.SettleResponse, error) {
+	if originalSettle == nil || !originalSettle.Success || originalSettle.Transaction == "" || originalSettle.Payer == "" {
+		return nil, x402.NewSettleError(x402.ReasonSettlementNotFound, "", "", "", errors.New("not a successful settlement"))
+	}
+	payer := originalSettle.Payer
+	network := originalSettle.Network
+	settlement := originalSettle.Transaction
+
+	if _, err := evm.GetNetworkConfig(string(network)); err != nil {
+		return nil, x402.NewSettleError(x402.ReasonFailedToGetNetworkConfig, payer, network, "", err)
+	}
+
+	// Only settlements this facilitator paid for can be refunded
+	if !f.config.RefundLedger.IsSettlement(network, settlement) {
+		return nil, x402.NewSettleError(x402.ReasonSettlementNotFound, payer, network, "", fmt.Errorf("settlement %s was not sent by this facilitator", settlement))
+	}
+
+	// Validate the settlement on-chain
+	receipt, err := f.signer.WaitForTransactionReceipt(ctx, settlement)
+	if err != nil {
+		return nil, x402.NewSettleError(evm.ReceiptErrorReason(err), payer, network, "", err)
+	}
+	if receipt.Status != evm.TxStatusSuccess {
+		return nil, x402.NewSettleError(x402.ReasonSettlementNotFound, payer, network, "", fmt.Errorf("settlement %s failed", settlement))
+	}
+
+	var transfers []evm.ERC20Transfer
+	for _, transfer := range evm.ERC20Transfers(receipt) {
+		if strings.EqualFold(transfer.From, payer) && transfer.Value.Sign() > 0 && evm.IsConfiguredAsset(string(network), transfer.Token) {
+			transfers = append(transfers, transfer)
+		}
+	}
+	if len(transfers) == 0 {
+		return nil, x402.NewSettleError(x402.ReasonSettlementNotFound, payer, network, "", fmt.Errorf("settlement %s has no transfer of a configured asset from %s", settlement, payer))
+	}
+
+	// Reserve the first transfer not refunded yet
+	transfer, key, ok := f.reserveRefund(settlement, transfers)
+	if !ok {
+		return nil, x402.NewSettleError(x402.ReasonAlreadyRefunded, payer, network, "", fmt.Errorf("settlement %s was already refunded", settlement))
+	}
+	sent := false
+	defer func() {
+		if !sent {
+			f.config.RefundLedger.Release(key)
+		}
+	}()
+
+	// The refunding account must hold the amount
+	write := f.signer.WriteContract
+	wait := f.signer.WaitForTransactionReceipt
+	if refundSigner := f.config.RefundSigner; refundSigner != nil {
+		if !strings.EqualFold(refundSigner.Address(), transfer.To) {
+			return nil, x402.NewSettleError(x402.ReasonRecipientMismatch, payer, network, "",
+				fmt.Errorf("refund signer %s is not the payee %s", refundSigner.Address(), transfer.To))
+		}
+		balanceOf := func(ctx context.Context, address, token string) (*big.Int, error) {
+			result, err := refundSigner.ReadContract(ctx, token, evm.ERC20ABI, evm.FunctionBalanceOf, common.HexToAddress(address))
+			if err != nil {
+				return nil, err
+			}
+			balance, ok := result.(*big.Int)
+			if !ok {
+				return nil, fmt.Errorf("unexpected balanceOf result %T", result)
+			}
+			return balance, nil
+		}
+		if err := checkRefundBalance(ctx, balanceOf, []string{refundSigner.Address()}, transfer); err != nil {
+			return nil, refundBalanceError(err, payer, network)
+		}
+		write = refundSigner.WriteContract
+		wait = refundSigner.WaitForTransactionReceipt
+	} else if err := checkRefundBalance(ctx, f.signer.GetBalance, f.signer.GetAddresses(), transfer); err != nil {
+		// The signer picks which address sends the refund, so every one must be able to pay it
+		return nil, refundBalanceError(err, payer, network)
+	}
+
+	txHash, err := write(ctx, transfer.Token, evm.ERC20ABI, evm.FunctionTransfer, common.HexToAddress(payer), transfer.Value)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonFailedToExecuteTransfer, payer, network, "", err)
+	}
+
+	// The refund may be mined even if waiting for it fails, so it stays reserved from here on
+	sent = true
+	f.config.RefundLedger.Complete(key, RefundRecord{
+		Settlement:  settlement,
+		Transaction: txHash,
+		Network:     network,
+		Payer:       payer,
+		Token:       transfer.Token,
+		Amount:      transfer.Value,
+		Reason:      reason,
+		Time:        time.Now(),
+	})
+
+	refundReceipt, err := wait(ctx, txHash)
+	if err != nil {
+		return nil, x402.NewSettleError(evm.ReceiptErrorReason(err), payer, network, txHash, err)
+	}
+	if refundReceipt.Status != evm.TxStatusSuccess {
+		return nil, x402.NewSettleError(x402.ReasonTransactionFailed, payer, network, txHash, nil)
+	}
+
+	return &x402.SettleResponse{
+		Success:     true,
+		Transaction: txHash,
+		Network:     network,
+		Payer:       payer,
+	}, nil
+}
+
+// Refunds returns the refunds sent by Refund, as recorded in the RefundLedger. A refund whose
+// transaction reverted or wasn't confirmed is included too, since it may still be mined.
+func (f *ExactEvmScheme) Refunds() []RefundRecord {
+	return f.config.RefundLedger.Refunds()
+}
+
+// reserveRefund reserves the first of transfers without a refund in the ledger, returning it
+// with its key
+func (f *ExactEvmScheme) reserveRefund(settlement string, transfers []evm.ERC20Transfer) (evm.ERC20Transfer, string, bool) {
+	for _, transfer := range transfers {
+		key := refundKey(settlement, transfer.LogIndex)
+		if f.config.RefundLedger.Reserve(key) {
+			return transfer, key, true
+		}
+	}
+	return evm.ERC20Transfer{}, "", false
+}
+
+// errInsufficientRefundBalance is wrapped by checkRefundBalance when an account can't pay
+var errInsufficientRefundBalance = errors.New("insufficient balance for refund")
+
+// checkRefundBalance checks that every address holds the transfer's value of its token
+func checkRefundBalance(
+	ctx context.Context,
+	balanceOf func(ctx context.Context, address, token string) (*big.Int, error),
+	addresses []string,
+	transfer evm.ERC20Transfer,
+) error {
+	if len(addresses) == 0 {
+		return errors.New("no refund address")
+	}
+	for _, address := range addresses {
+		balance, err := balanceOf(ctx, address, transfer.Token)
+		if err != nil {
+			return err
+		}
+		if balance.Cmp(transfer.Value) < 0 {
+			return fmt.Errorf("%w: %s holds %s of %s, refund is %s", errInsufficientRefundBalance, address, balance, transfer.Token, transfer.Value)
+		}
+	}
+	return nil
+}
+
+// refundBalanceError maps a checkRefundBalance failure to a settle error
+func refundBalanceError(err error, payer string, network x402.Network) error {
+	if errors.Is(err, errInsufficientRefundBalance) {
+		return x402.NewSettleError(x402.ReasonInsufficientRefundBalance, payer, network, "", err)
+	}
+	return x402.NewSettleError(x402.ReasonFailedToGetBalance, payer, network, "", err)
+}
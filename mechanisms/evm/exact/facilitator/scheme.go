@@ -2,10 +2,12 @@ package facilitator
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/big"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -14,17 +16,129 @@ import (
 	"x402-go/types"
 )
 
+// SettlementMode selects how the facilitator settles EIP-3009 payments
+type SettlementMode string
+
+const (
+	// SettlementModeAuto uses the facilitator contract where it is deployed, and calls the
+	// token directly on networks without it
+	SettlementModeAuto SettlementMode = ""
+
+	// SettlementModeFacilitatorContract settles every payment through the facilitator
+	// contract's settlePayment
+	SettlementModeFacilitatorContract SettlementMode = "facilitatorContract"
+
+	// SettlementModeDirectToken settles EIP-3009 payments by calling the token's
+	// transferWithAuthorization. Generic ERC-20 payments need the facilitator contract, so
+	// they fail with ReasonFacilitatorContractUnavailable.
+	SettlementModeDirectToken SettlementMode = "directToken"
+)
+
 // ExactEvmSchemeConfig holds configuration for the ExactEvmScheme facilitator
 type ExactEvmSchemeConfig struct {
 	// DeployERC4337WithEIP6492 enables automatic deployment of ERC-4337 smart wallets
 	// via EIP-6492 when encountering undeployed contract signatures during settlement
 	DeployERC4337WithEIP6492 bool
+
+	// ClockSkewTolerance is the allowance applied to validAfter/validBefore when
+	// checking the authorization validity window (defaults to evm.ClockSkewTolerance,
+	// the same value clients use to backdate validAfter)
+	ClockSkewTolerance time.Duration
+
+	// CheckBalanceBeforeSettle queries the payer's token balance before submitting
+	// the settlement transaction, failing fast instead of paying gas for a revert
+	CheckBalanceBeforeSettle bool
+
+	// CheckBalanceOnVerify applies the same balance check during Verify so resource
+	// servers can reject underfunded payers before serving the request
+	CheckBalanceOnVerify bool
+
+	// MaxBatchSize caps how many payments SettleBatch submits in one settlePaymentBatch
+	// transaction (defaults to 50)
+	MaxBatchSize int
+
+	// UseReceiveWithAuthorization asks clients for EIP-3009 receiveWithAuthorization
+	// signatures and settles them by calling the token directly. The token only accepts
+	// them from the recipient, so nobody can front-run the settlement; payTo must be the
+	// facilitator's (single) signing address.
+	UseReceiveWithAuthorization bool
+
+	// NonceGuard rejects a settlement while another settlement of the same authorization is
+	// in flight (defaults to an in-memory guard; share one across facilitator instances)
+	NonceGuard x402.NonceGuard
+
+	// SettlementCache returns the original result when the same payment is settled again,
+	// instead of broadcasting a second transaction (defaults to an in-memory cache; share one
+	// across facilitator instances)
+	SettlementCache x402.SettlementCache
+
+	// RateLimiter limits verify and settle requests per payer, rejecting the excess with
+	// rate_limited. Only requests carrying a valid payer signature are counted (nil disables
+	// rate limiting; share one across facilitator instances)
+	RateLimiter x402.PayerRateLimiter
+
+	// RequireLowS rejects EOA signatures whose s is above half the curve order with
+	// malleable_signature, for tokens and contracts that only accept low-S signatures
+	RequireLowS bool
+
+	// AllowOverpayment accepts authorizations for more than the required amount. Settlement
+	// always transfers the full signed value, so by default such payments are rejected with
+	// amount_exceeds_required rather than charging the payer more than the price.
+	AllowOverpayment bool
+
+	// AllowZeroAmount accepts payments of zero. By default zero-value requirements and
+	// authorizations are rejected with zero_amount, since they verify without paying anything.
+	AllowZeroAmount bool
+
+	// MinAmounts rejects payments below a minimum per network and asset with
+	// amount_below_minimum, so dust payments don't cost more gas to settle than they are worth
+	MinAmounts []evm.MinAmount
+
+	// SettlementMode selects whether EIP-3009 payments settle through the facilitator contract
+	// or the token itself. The default picks the contract where it is deployed and the token
+	// elsewhere.
+	SettlementMode SettlementMode
+
+	// SettleEOADirectly settles EIP-3009 payments signed by EOAs by calling the token's v,r,s
+	// transferWithAuthorization directly rather than the facilitator contract's settlePayment,
+	// which costs less gas. Smart wallet, ERC-6492 and generic ERC-20 payments still go
+	// through the contract.
+	SettleEOADirectly bool
+
+	// RefundSigner is the payee's signer, used by Refund to pay refunds from the payee's
+	// balance (nil pays them from the facilitator's)
+	RefundSigner evm.ClientEvmSigner
+
+	// RefundLedger records the settlements sent and the refunds paid, so Refund only repays
+	// this facilitator's settlements, once each (defaults to an in-memory ledger; share a
+	// persistent one across facilitator instances and restarts)
+	RefundLedger RefundLedger
+
+	// MinSignerBalance is the native token balance (in wei) below which a signer is low on gas.
+	// Settle then logs a warning for it, and HealthCheck reports the network as unhealthy. Nil
+	// turns the check off, and HealthCheck only reports the balances.
+	MinSignerBalance *big.Int
+
+	// RefuseSettleOnLowGas fails settlements with facilitator_low_gas, before anything is sent,
+	// when every signer is below MinSignerBalance, so the payment can be retried on another
+	// facilitator instead of failing on-chain
+	RefuseSettleOnLowGas bool
+
+	// Logger receives warnings about signers low on gas (defaults to no logging)
+	Logger x402.Logger
+
+	// ERC20AuthorizationDomainName and ERC20AuthorizationDomainVersion are the EIP-712 domain
+	// of the facilitator contract, against which generic ERC-20 authorizations are verified
+	// (default "Facilitator" and "1"). Clients must sign with the same pair.
+	ERC20AuthorizationDomainName    string
+	ERC20AuthorizationDomainVersion string
 }
 
 // ExactEvmScheme implements the SchemeNetworkFacilitator interface for EVM exact payments (V2)
 type ExactEvmScheme struct {
-	signer evm.FacilitatorEvmSigner
-	config ExactEvmSchemeConfig
+	signer  evm.FacilitatorEvmSigner
+	config  ExactEvmSchemeConfig
+	pending pendingAuthorizations
 }
 
 // NewExactEvmScheme creates a new ExactEvmScheme
@@ -41,12 +155,62 @@ func NewExactEvmScheme(signer evm.FacilitatorEvmSigner, config *ExactEvmSchemeCo
 	if config != nil {
 		cfg = *config
 	}
+	if cfg.ClockSkewTolerance <= 0 {
+		cfg.ClockSkewTolerance = evm.ClockSkewTolerance
+	}
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = evm.DefaultMaxBatchSize
+	}
+	if cfg.NonceGuard == nil {
+		cfg.NonceGuard = x402.NewMemoryNonceGuard(0)
+	}
+	if cfg.SettlementCache == nil {
+		cfg.SettlementCache = x402.NewMemorySettlementCache(0)
+	}
+	if cfg.RefundLedger == nil {
+		cfg.RefundLedger = NewMemoryRefundLedger(0)
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = x402.NoopLogger()
+	}
+	if cfg.ERC20AuthorizationDomainName == "" {
+		cfg.ERC20AuthorizationDomainName = evm.DefaultERC20AuthorizationDomainName
+	}
+	if cfg.ERC20AuthorizationDomainVersion == "" {
+		cfg.ERC20AuthorizationDomainVersion = evm.DefaultERC20AuthorizationDomainVersion
+	}
 	return &ExactEvmScheme{
 		signer: signer,
 		config: cfg,
 	}
 }
 
+// checkAmount enforces the amount invariant shared by Verify and Settle: neither value may be
+// zero unless zero amounts are allowed, and the signed value must equal the required amount, or
+// exceed it when overpayment is allowed. It returns the reason the value is rejected, or "" when
+// it is acceptable.
+func (f *ExactEvmScheme) checkAmount(authValue, requiredValue *big.Int) string {
+	if (authValue.Sign() == 0 || requiredValue.Sign() == 0) && !f.config.AllowZeroAmount {
+		return x402.ReasonZeroAmount
+	}
+	switch cmp := authValue.Cmp(requiredValue); {
+	case cmp < 0:
+		return x402.ReasonInsufficientAmount
+	case cmp > 0 && !f.config.AllowOverpayment:
+		// The signature covers the value, so settlement can't transfer less than was authorized
+		return x402.ReasonAmountExceedsRequired
+	}
+	return ""
+}
+
+// signatureOptions returns the EOA verification options of the config
+func (f *ExactEvmScheme) signatureOptions() []evm.SignatureOption {
+	if f.config.RequireLowS {
+		return []evm.SignatureOption{evm.RequireLowS()}
+	}
+	return nil
+}
+
 // Scheme returns the scheme identifier
 func (f *ExactEvmScheme) Scheme() string {
 	return evm.SchemeExact
@@ -58,8 +222,13 @@ func (f *ExactEvmScheme) CaipFamily() string {
 }
 
 // GetExtra returns mechanism-specific extra data for the supported kinds endpoint.
-// For EVM, no extra data is needed.
+// For EVM, it only asks for receiveWithAuthorization signatures when those are enabled.
 func (f *ExactEvmScheme) GetExtra(_ x402.Network) map[string]interface{} {
+	if f.config.UseReceiveWithAuthorization {
+		return map[string]interface{}{
+			evm.ExtraAuthorizationFunction: evm.FunctionReceiveWithAuthorization,
+		}
+	}
 	return nil
 }
 
@@ -79,58 +248,106 @@ func (f *ExactEvmScheme) Verify(
 
 	// Validate scheme (v2 has scheme in Accepted field)
 	if payload.Accepted.Scheme != evm.SchemeExact {
-		return nil, x402.NewVerifyError("invalid_scheme", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidScheme, "", network, nil)
 	}
 
 	// Validate network (v2 has network in Accepted field)
 	if payload.Accepted.Network != requirements.Network {
-		return nil, x402.NewVerifyError("network_mismatch", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonNetworkMismatch, "", network, nil)
+	}
+
+	// The payload must commit to exactly the terms being verified
+	if !types.AcceptedMatches(payload.Accepted, requirements) {
+		return nil, x402.NewVerifyError(x402.ReasonAcceptedMismatch, "", network, errors.New("accepted terms differ from the requirements"))
+	}
+
+	// Reject mistyped (bad checksum) addresses before any funds can move
+	if err := evm.ValidateRequirementAddresses(requirements.PayTo, requirements.Asset); err != nil {
+		return nil, x402.NewVerifyError(x402.ReasonInvalidAddressChecksum, "", network, err)
 	}
 
+	// A CAIP-19 asset must name a token on the requirements' chain
+	if _, err := evm.NormalizeAssetIdentifier(requirements.Network, requirements.Asset); errors.Is(err, evm.ErrAssetNetworkMismatch) {
+		return nil, x402.NewVerifyError(x402.ReasonAssetNetworkMismatch, "", network, err)
+	}
+
+	// Decode the EVM payload according to its type
+	envelope, err := evm.UnmarshalPayload(payload)
+	if err != nil {
+		if errors.Is(err, evm.ErrUnknownPayloadType) {
+			return nil, x402.NewVerifyError(x402.ReasonInvalidPayloadType, "", network, err)
+		}
+		return nil, x402.NewVerifyError(x402.ReasonInvalidPayload, "", network, err)
+	}
+	authorization := envelope.Authorization()
+
 	// Get network configuration
 	networkStr := string(requirements.Network)
-	config, err := evm.GetNetworkConfig(networkStr)
+	config, err := evm.ResolveNetworkConfig(ctx, f.signer, networkStr, requirements.Asset)
 	if err != nil {
-		return nil, x402.NewVerifyError("failed_to_get_network_config", "", network, err)
+		return nil, x402.NewVerifyError(x402.ReasonFailedToGetNetworkConfig, "", network, err)
 	}
 
 	// Get asset info
-	assetInfo, err := evm.GetAssetInfo(networkStr, requirements.Asset)
+	assetInfo, err := evm.ResolveAssetInfo(ctx, f.signer, networkStr, requirements.Asset)
 	if err != nil {
-		return nil, x402.NewVerifyError("failed_to_get_asset_info", "", network, err)
+		return nil, x402.NewVerifyError(x402.ReasonFailedToGetAssetInfo, "", network, err)
 	}
 
-	// Parse EVM payload - use generic parser that handles standard EIP-3009 structure
-	// We use ExactEIP3009Payload structure for both flows as they share key fields
-	evmPayload, err := evm.PayloadFromMap(payload.Payload)
+	// A token that only exists on another chain would only make the settlement revert
+	onNetwork, err := evm.VerifyAssetOnNetwork(ctx, f.signer, networkStr, config.ChainID, assetInfo.Address)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_payload", "", network, err)
+		return nil, x402.NewVerifyError(x402.ReasonFailedToGetAssetInfo, "", network, err)
+	}
+	if !onNetwork {
+		return nil, x402.NewVerifyError(x402.ReasonAssetNetworkMismatch, "", network, fmt.Errorf("no token contract at %s on %s", assetInfo.Address, network))
 	}
 
 	// Validate signature exists
-	if evmPayload.Signature == "" {
-		return nil, x402.NewVerifyError("missing_signature", "", network, nil)
+	if envelope.Signature() == "" {
+		return nil, x402.NewVerifyError(x402.ReasonMissingSignature, "", network, nil)
 	}
 
 	// Validate authorization matches requirements
-	if !strings.EqualFold(evmPayload.Authorization.To, requirements.PayTo) {
-		return nil, x402.NewVerifyError("recipient_mismatch", "", network, nil)
+	if !strings.EqualFold(authorization.To, requirements.PayTo) {
+		return nil, x402.NewVerifyError(x402.ReasonRecipientMismatch, "", network, nil)
+	}
+
+	// receiveWithAuthorization can only be submitted by its recipient
+	isReceive := envelope.Type == evm.PayloadTypeEIP3009Receive
+	if isReceive {
+		if !f.config.UseReceiveWithAuthorization {
+			return nil, x402.NewVerifyError(x402.ReasonInvalidPayloadType, authorization.From, network, errors.New("receiveWithAuthorization is not enabled"))
+		}
+		if !f.isSoleSigner(requirements.PayTo) {
+			return nil, x402.NewVerifyError(x402.ReasonReceiverNotFacilitator, authorization.From, network, nil)
+		}
 	}
 
 	// Parse and validate amount
-	authValue, ok := new(big.Int).SetString(evmPayload.Authorization.Value, 10)
+	authValue, ok := new(big.Int).SetString(authorization.Value, 10)
 	if !ok {
-		return nil, x402.NewVerifyError("invalid_authorization_value", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidAuthorizationValue, "", network, nil)
 	}
 
 	// Requirements.Amount is already in the smallest unit
 	requiredValue, ok := new(big.Int).SetString(requirements.Amount, 10)
 	if !ok {
-		return nil, x402.NewVerifyError("invalid_required_amount", "", network, fmt.Errorf("invalid amount: %s", requirements.Amount))
+		return nil, x402.NewVerifyError(x402.ReasonInvalidRequiredAmount, "", network, fmt.Errorf("invalid amount: %s", requirements.Amount))
+	}
+
+	if reason := f.checkAmount(authValue, requiredValue); reason != "" {
+		return nil, x402.NewVerifyError(reason, authorization.From, network, nil)
 	}
 
-	if authValue.Cmp(requiredValue) < 0 {
-		return nil, x402.NewVerifyError("insufficient_amount", evmPayload.Authorization.From, network, nil)
+	// Dust payments cost more gas to settle than they are worth
+	if minimum := evm.FindMinAmount(f.config.MinAmounts, networkStr, assetInfo.Address); minimum != nil && authValue.Cmp(minimum) < 0 {
+		return nil, x402.NewVerifyError(x402.ReasonAmountBelowMinimum, authorization.From, network, fmt.Errorf("amount %s is below the minimum of %s", authValue, minimum))
+	}
+
+	// Reject authorizations outside their validity window before touching the chain
+	if err := f.checkValidityWindow(ctx, authorization, network); err != nil {
+		return nil, err
 	}
 
 	// Extract token info from requirements
@@ -152,22 +369,21 @@ func (f *ExactEvmScheme) Verify(
 
 	// Determine verification strategy based on payload type
 	// If type is present, use it. Otherwise fall back to detection (backward compatibility)
-	var isEIP3009 bool
-	if typeStr, ok := payload.Payload["type"].(string); ok {
-		if typeStr == "authorizationEip3009" {
-			isEIP3009 = true
-		} else if typeStr == "authorization" {
-			isEIP3009 = false
-		} else {
-			return nil, x402.NewVerifyError("invalid_payload_type", "", network, fmt.Errorf("unknown payload type: %s", typeStr))
-		}
-	} else {
-		// Fallback: Determine verification strategy based on token capabilities (old method)
+	var isEIP3009, isPermit bool
+	switch envelope.Type {
+	case evm.PayloadTypeEIP3009, evm.PayloadTypeEIP3009Receive:
+		isEIP3009 = true
+	case evm.PayloadTypeAuthorization:
+		isEIP3009 = false
+	case evm.PayloadTypePermit:
+		isPermit = true
+	default:
+		// Untyped payload from an older client: decide based on the token's capabilities
 		supported, err := evm.VerifyEIP3009Support(
 			ctx,
 			f.signer,
 			config.ChainID,
-			evmPayload.Authorization.From,
+			authorization.From,
 			assetInfo.Address,
 		)
 		if err != nil {
@@ -178,17 +394,22 @@ func (f *ExactEvmScheme) Verify(
 		}
 	}
 
-	signatureBytes, err := evm.HexToBytes(evmPayload.Signature)
+	signatureBytes, err := evm.HexToBytes(envelope.Signature())
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_signature_format", evmPayload.Authorization.From, network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidSignatureFormat, authorization.From, network, err)
 	}
 
 	var valid bool
 	if isEIP3009 {
 		// Verify signature against Token contract (EIP-3009)
+		primaryType := evm.PrimaryTypeTransferWithAuthorization
+		if isReceive {
+			primaryType = evm.PrimaryTypeReceiveWithAuthorization
+		}
 		valid, err = f.verifySignature(
 			ctx,
-			evmPayload.Authorization,
+			authorization,
+			primaryType,
 			signatureBytes,
 			config.ChainID,
 			assetInfo.Address,
@@ -196,23 +417,22 @@ func (f *ExactEvmScheme) Verify(
 			tokenVersion,
 		)
 		if err != nil {
-			return nil, x402.NewVerifyError("failed_to_verify_signature", evmPayload.Authorization.From, network, err)
+			return nil, x402.NewVerifyError(evm.SignatureErrorReason(err), authorization.From, network, err)
 		}
 	} else {
 		// Verify signature against Facilitator contract (ERC-20 Auth)
-		evmPayloadERC20, err := evm.PayloadERC20FromMap(payload.Payload)
-		if err != nil {
-			return nil, x402.NewVerifyError("invalid_payload", "", network, err)
-		}
+		evmPayloadERC20 := envelope.ERC20
 
 		// Hash ERC-20 Auth
 		hash, err := evm.HashERC20Authorization(
 			evmPayloadERC20.Authorization,
 			config.ChainID,
-			evm.FacilitatorContractAddress,
+			config.FacilitatorAddress(),
+			f.config.ERC20AuthorizationDomainName,
+			f.config.ERC20AuthorizationDomainVersion,
 		)
 		if err != nil {
-			return nil, x402.NewVerifyError("failed_to_hash_authorization", evmPayload.Authorization.From, network, err)
+			return nil, x402.NewVerifyError(x402.ReasonFailedToHashAuthorization, authorization.From, network, err)
 		}
 		var hash32 [32]byte
 		copy(hash32[:], hash)
@@ -220,27 +440,70 @@ func (f *ExactEvmScheme) Verify(
 		valid, _, err = evm.VerifyUniversalSignature(
 			ctx,
 			f.signer,
-			evmPayload.Authorization.From,
+			authorization.From,
 			hash32,
 			signatureBytes,
 			true,
+			f.signatureOptions()...,
 		)
 		if err != nil {
-			return nil, x402.NewVerifyError("failed_to_verify_signature", evmPayload.Authorization.From, network, err)
+			return nil, x402.NewVerifyError(evm.SignatureErrorReason(err), authorization.From, network, err)
+		}
+
+		// The permit flow carries an EIP-2612 permit that grants the facilitator contract its allowance
+		if valid && isPermit {
+			if err := f.verifyPermit(ctx, evmPayloadERC20, authValue, config.ChainID, config.FacilitatorAddress(), assetInfo.Address, tokenName, tokenVersion, network); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	if !valid {
-		return nil, x402.NewVerifyError("invalid_signature", evmPayload.Authorization.From, network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidSignature, authorization.From, network, nil)
+	}
+
+	// Throttle the payer once the signature proves the request is theirs, so nobody can
+	// exhaust another address's allowance with payloads naming it as from
+	if f.config.RateLimiter != nil && !f.config.RateLimiter.Allow(authorization.From) {
+		return nil, x402.NewVerifyError(x402.ReasonRateLimited, authorization.From, network, nil)
+	}
+
+	if f.config.CheckBalanceOnVerify {
+		sufficient, err := f.hasSufficientBalance(ctx, authorization.From, assetInfo.Address, authValue)
+		if err != nil {
+			return nil, x402.NewVerifyError(x402.ReasonFailedToGetBalance, authorization.From, network, err)
+		}
+		if !sufficient {
+			return nil, x402.NewVerifyError(x402.ReasonInsufficientBalance, authorization.From, network, nil)
+		}
+	}
+
+	// Reject replayed authorizations. EIP-3009 nonces are tracked by the token itself,
+	// while generic ERC-20 authorizations are tracked by the facilitator contract.
+	nonceContract := config.FacilitatorAddress()
+	if isEIP3009 {
+		nonceContract = assetInfo.Address
+	}
+	used, err := f.checkNonceUsed(ctx, authorization.From, authorization.Nonce, nonceContract)
+	if err != nil {
+		return nil, x402.NewVerifyError(x402.ReasonFailedToCheckNonce, authorization.From, network, err)
+	}
+	if used {
+		return nil, x402.NewVerifyError(x402.ReasonNonceAlreadyUsed, authorization.From, network, nil)
 	}
 
 	// Unlike TS implementation which is lighter on pre-checks, we perform robust
 	// off-chain validation here to ensure the signature is valid before settlement.
 	// This prevents failed transactions and wasted gas.
 
+	// Verified EIP-3009 authorizations become cancellable through CancelAuthorization
+	if isEIP3009 {
+		f.pending.add(pendingAuthorizationKey(networkStr, assetInfo.Address, authorization.From, authorization.Nonce), networkStr, requirements.Asset, authorization.ValidBefore)
+	}
+
 	return &x402.VerifyResponse{
 		IsValid: true,
-		Payer:   evmPayload.Authorization.From,
+		Payer:   authorization.From,
 	}, nil
 }
 
@@ -250,6 +513,171 @@ func (f *ExactEvmScheme) Settle(
 	payload types.PaymentPayload,
 	requirements types.PaymentRequirements,
 ) (*x402.SettleResponse, error) {
+	// A payment settled before gets its original result
+	cacheKey := settlementCacheKey(payload, requirements)
+	if cacheKey != "" {
+		if cached, ok := f.config.SettlementCache.Get(cacheKey); ok {
+			return cached, nil
+		}
+	}
+
+	if err := f.checkSignerGas(ctx, x402.Network(requirements.Network)); err != nil {
+		return nil, err
+	}
+
+	release, err := f.reserveNonce(payload, requirements)
+	if err != nil {
+		return nil, err
+	}
+
+	call, err := f.prepareSettlement(ctx, payload, requirements, nil)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	result, err := f.executeSettlement(ctx, call)
+	if err == nil && cacheKey != "" {
+		// Cached before the reservation is released, so a retry finds one or the other
+		f.config.SettlementCache.Put(cacheKey, result)
+	}
+	// A settlement that timed out may still be mined, so its reservation is left to expire
+	if !errors.Is(err, evm.ErrReceiptTimeout) {
+		release()
+	}
+	return result, err
+}
+
+// SimulateSettle reports whether Settle would succeed without sending anything. The payment is
+// verified and prepared as for settlement, then the settlement call runs as an eth_call against
+// the latest block, so a revert comes back decoded as ReasonTransactionSimulationFailed. The
+// signer must implement evm.ContractSimulator.
+//
+// An undeployed ERC-6492 wallet is not deployed first; the call relies on the contract deploying
+// it. When a permit payment's permit isn't on-chain yet, only the permit is simulated, since the
+// settlement depends on the allowance it grants.
+func (f *ExactEvmScheme) SimulateSettle(
+	ctx context.Context,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+) (*x402.SettleResponse, error) {
+	simulator, ok := f.signer.(evm.ContractSimulator)
+	if !ok {
+		return nil, x402.NewSettleError(x402.ReasonSimulationNotSupported, "", x402.Network(requirements.Network), "", errors.New("signer cannot simulate contract calls"))
+	}
+
+	call, err := f.prepareSettlement(ctx, payload, requirements, simulator)
+	if err != nil {
+		return nil, err
+	}
+
+	if !call.permitPending {
+		contract, abiJSON, function, args := call.contractCall()
+		if err := simulator.SimulateContract(ctx, contract, abiJSON, function, args...); err != nil {
+			return nil, x402.NewSettleError(x402.ReasonTransactionSimulationFailed, call.payer, call.network, "", err)
+		}
+	}
+
+	return &x402.SettleResponse{
+		Success: true,
+		Network: call.network,
+		Payer:   call.payer,
+	}, nil
+}
+
+// settlementCacheKey returns the settlement cache key of a payment, or "" when its payload
+// can't be decoded
+func settlementCacheKey(payload types.PaymentPayload, requirements types.PaymentRequirements) string {
+	envelope, err := evm.UnmarshalPayload(payload)
+	if err != nil {
+		return ""
+	}
+	authorization := envelope.Authorization()
+	return evm.SettlementCacheKey(requirements.Network, requirements.Asset, authorization.From, authorization.Nonce, payload, requirements)
+}
+
+// reserveNonce reserves the payment's authorization with the nonce guard, failing with
+// ReasonNonceInFlight while another settlement of it is in progress
+func (f *ExactEvmScheme) reserveNonce(payload types.PaymentPayload, requirements types.PaymentRequirements) (func(), error) {
+	envelope, err := evm.UnmarshalPayload(payload)
+	if err != nil {
+		// Reported by verification
+		return func() {}, nil
+	}
+
+	network := x402.Network(requirements.Network)
+	authorization := envelope.Authorization()
+	release, ok := f.config.NonceGuard.Reserve(string(network), requirements.Asset, authorization.From, authorization.Nonce)
+	if !ok {
+		return nil, x402.NewSettleError(x402.ReasonNonceInFlight, authorization.From, network, "", nil)
+	}
+	return release, nil
+}
+
+// settlementCall holds the checked arguments of a settlePayment call
+type settlementCall struct {
+	network     x402.Network
+	contract    string // Facilitator contract for the network
+	payer       string
+	token       common.Address
+	from        common.Address
+	to          common.Address
+	value       *big.Int
+	validAfter  *big.Int
+	validBefore *big.Int
+	nonce       [32]byte
+	signature   []byte
+	receive     bool // Settle through the token's receiveWithAuthorization instead
+	direct      bool // Settle through the token's transferWithAuthorization instead
+
+	// Set for EOA signatures settled through the token, which take its v,r,s overloads
+	vrs *evm.SignatureComponents
+
+	// Set by a simulation when the permit the settlement relies on isn't on-chain yet
+	permitPending bool
+}
+
+// throughToken reports whether call settles by calling the token rather than the facilitator contract
+func (call *settlementCall) throughToken() bool {
+	return call.receive || call.direct
+}
+
+// contractCall returns the contract, ABI, function and arguments that settle call
+func (call *settlementCall) contractCall() (string, []byte, string, []interface{}) {
+	if !call.throughToken() {
+		// settlePayment on the Facilitator contract handles both EIP-3009 and generic
+		// transferWithAuthorization (ERC-20 style)
+		return call.contract, evm.SettlePaymentABI, evm.FunctionSettlePayment, []interface{}{
+			call.token, call.from, call.to, call.value, call.validAfter, call.validBefore, call.nonce, call.signature,
+		}
+	}
+
+	// The token's transferWithAuthorization or receiveWithAuthorization: the v,r,s overload
+	// for EOA signatures and the bytes overload for smart wallets
+	function, vrsABI, bytesABI := evm.FunctionTransferWithAuthorization, evm.TransferWithAuthorizationVRSABI, evm.TransferWithAuthorizationBytesABI
+	if call.receive {
+		function, vrsABI, bytesABI = evm.FunctionReceiveWithAuthorization, evm.ReceiveWithAuthorizationVRSABI, evm.ReceiveWithAuthorizationBytesABI
+	}
+	if call.vrs != nil {
+		return call.token.Hex(), vrsABI, function, []interface{}{
+			call.from, call.to, call.value, call.validAfter, call.validBefore, call.nonce,
+			call.vrs.V, call.vrs.R, call.vrs.S,
+		}
+	}
+	return call.token.Hex(), bytesABI, function, []interface{}{
+		call.from, call.to, call.value, call.validAfter, call.validBefore, call.nonce, call.signature,
+	}
+}
+
+// prepareSettlement verifies a payment and performs any on-chain preparation it needs
+// (smart wallet deployment, permit submission), returning the settlePayment arguments.
+// With a simulator nothing is sent: the wallet is left undeployed and the permit is simulated.
+func (f *ExactEvmScheme) prepareSettlement(
+	ctx context.Context,
+	payload types.PaymentPayload,
+	requirements types.PaymentRequirements,
+	simulator evm.ContractSimulator,
+) (*settlementCall, error) {
 	network := x402.Network(payload.Accepted.Network)
 
 	// First verify the payment
@@ -260,49 +688,65 @@ func (f *ExactEvmScheme) Settle(
 		if errors.As(err, &ve) {
 			return nil, x402.NewSettleError(ve.Reason, ve.Payer, ve.Network, "", ve.Err)
 		}
-		return nil, x402.NewSettleError("verification_failed", "", network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonVerificationFailed, "", network, "", err)
 	}
 
-	// Get asset info
+	// Get network configuration
 	networkStr := string(requirements.Network)
-	assetInfo, err := evm.GetAssetInfo(networkStr, requirements.Asset)
+	config, err := evm.ResolveNetworkConfig(ctx, f.signer, networkStr, requirements.Asset)
 	if err != nil {
-		return nil, x402.NewSettleError("failed_to_get_asset_info", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonFailedToGetNetworkConfig, verifyResp.Payer, network, "", err)
 	}
 
-	// Parse EVM payload (Standard EIP-3009 structure works for extraction)
-	evmPayload, err := evm.PayloadFromMap(payload.Payload)
+	// Get asset info
+	assetInfo, err := evm.ResolveAssetInfo(ctx, f.signer, networkStr, requirements.Asset)
 	if err != nil {
-		return nil, x402.NewSettleError("invalid_payload", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonFailedToGetAssetInfo, verifyResp.Payer, network, "", err)
+	}
+
+	// Decode the EVM payload (already checked by Verify)
+	envelope, err := evm.UnmarshalPayload(payload)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonInvalidPayload, verifyResp.Payer, network, "", err)
+	}
+	authorization := envelope.Authorization()
+
+	// Settle exactly the signed value, and only if it is the one verified against the requirements
+	value, ok := new(big.Int).SetString(authorization.Value, 10)
+	requiredValue, requiredOk := new(big.Int).SetString(requirements.Amount, 10)
+	if !ok || !requiredOk || f.checkAmount(value, requiredValue) != "" {
+		return nil, x402.NewSettleError(x402.ReasonAmountMismatch, verifyResp.Payer, network, "", nil)
 	}
 
 	// Parse signature
-	signatureBytes, err := evm.HexToBytes(evmPayload.Signature)
+	signatureBytes, err := evm.HexToBytes(envelope.Signature())
 	if err != nil {
-		return nil, x402.NewSettleError("invalid_signature_format", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonInvalidSignatureFormat, verifyResp.Payer, network, "", err)
 	}
 
 	// Parse ERC-6492 signature to extract inner signature if needed
 	sigData, err := evm.ParseERC6492Signature(signatureBytes)
 	if err != nil {
-		return nil, x402.NewSettleError("failed_to_parse_signature", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonFailedToParseSignature, verifyResp.Payer, network, "", err)
 	}
 
 	// Check if wallet needs deployment (undeployed smart wallet with ERC-6492)
 	zeroFactory := [20]byte{}
 	if sigData.Factory != zeroFactory && len(sigData.FactoryCalldata) > 0 {
-		code, err := f.signer.GetCode(ctx, evmPayload.Authorization.From)
+		code, err := f.signer.GetCode(ctx, authorization.From)
 		if err != nil {
-			return nil, x402.NewSettleError("failed_to_check_deployment", verifyResp.Payer, network, "", err)
+			return nil, x402.NewSettleError(x402.ReasonFailedToCheckDeployment, verifyResp.Payer, network, "", err)
 		}
 
 		if len(code) == 0 {
 			// Wallet not deployed
 			if f.config.DeployERC4337WithEIP6492 {
-				// Deploy wallet
-				err := f.deploySmartWallet(ctx, sigData)
-				if err != nil {
-					return nil, x402.NewSettleError(evm.ErrSmartWalletDeploymentFailed, verifyResp.Payer, network, "", err)
+				// Deploy wallet (a simulation leaves it to the contract's ERC-6492 check)
+				if simulator == nil {
+					err := f.deploySmartWallet(ctx, sigData)
+					if err != nil {
+						return nil, x402.NewSettleError(evm.ErrSmartWalletDeploymentFailed, verifyResp.Payer, network, "", err)
+					}
 				}
 			} else {
 				// Deployment not enabled - fail settlement
@@ -311,54 +755,428 @@ func (f *ExactEvmScheme) Settle(
 		}
 	}
 
+	// Pick where the payment settles: the facilitator contract or the token itself
+	receive := envelope.Type == evm.PayloadTypeEIP3009Receive
+	direct := false
+	if !receive {
+		direct, err = f.settlesDirectly(ctx, envelope, network, config, verifyResp.Payer, authorization.From, assetInfo.Address, signatureBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The token checks signatures itself and doesn't unwrap ERC-6492
+	if receive || direct {
+		signatureBytes = sigData.InnerSignature
+	}
+
+	// Expand EIP-2098 compact signatures from EOAs to the 65-byte form the contract expects
+	signatureBytes, err = evm.ExpandCompactEOASignature(ctx, f.signer, authorization.From, signatureBytes)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonFailedToCheckDeployment, verifyResp.Payer, network, "", err)
+	}
+
+	// EOA signatures go to the token's v,r,s overloads
+	var vrs *evm.SignatureComponents
+	if (receive || direct) && len(signatureBytes) == evm.EOASignatureLength {
+		components, err := evm.SplitSignature(signatureBytes)
+		if err != nil {
+			return nil, x402.NewSettleError(x402.ReasonInvalidSignatureFormat, verifyResp.Payer, network, "", err)
+		}
+		vrs = &components
+	}
+
+	// Submit the EIP-2612 permit first so the facilitator contract holds the allowance it needs
+	permitPending := false
+	if envelope.Type == evm.PayloadTypePermit {
+		if simulator != nil {
+			permitPending, err = f.simulatePermit(ctx, simulator, envelope.ERC20.Permit, assetInfo.Address)
+			if err != nil {
+				return nil, x402.NewSettleError(x402.ReasonTransactionSimulationFailed, verifyResp.Payer, network, "", err)
+			}
+		} else if err := f.submitPermit(ctx, envelope.ERC20.Permit, assetInfo.Address); err != nil {
+			return nil, x402.NewSettleError(x402.ReasonPermitFailed, verifyResp.Payer, network, "", err)
+		}
+	}
+
 	// Use original signature for settlement (Facilitator handles unpacking 6492 if needed, or we pass inner?
 	// TS implementation passes `payload.signature`. If 6492 is used, it should be passed as is to the contract
 	// if the contract supports it. Our Facilitator contract uses Solady SignatureChecker which supports 6492.
 	// So we pass the FULL signature.
 
 	// Parse values
-	value, _ := new(big.Int).SetString(evmPayload.Authorization.Value, 10)
-	validAfter, _ := new(big.Int).SetString(evmPayload.Authorization.ValidAfter, 10)
-	validBefore, _ := new(big.Int).SetString(evmPayload.Authorization.ValidBefore, 10)
-	nonceBytes, _ := evm.HexToBytes(evmPayload.Authorization.Nonce)
+	validAfter, _ := new(big.Int).SetString(authorization.ValidAfter, 10)
+	validBefore, _ := new(big.Int).SetString(authorization.ValidBefore, 10)
+	nonceBytes, _ := evm.HexToBytes(authorization.Nonce)
 
-	// Execute settlePayment on the Facilitator contract
-	// This unified function handles both EIP-3009 and generic transferWithAuthorization (ERC-20 style)
-	txHash, err := f.signer.WriteContract(
-		ctx,
-		evm.FacilitatorContractAddress,
-		evm.SettlePaymentABI,
-		"settlePayment",
-		common.HexToAddress(assetInfo.Address), // Token address
-		common.HexToAddress(evmPayload.Authorization.From),
-		common.HexToAddress(requirements.PayTo), // PayTo from requirements (safer)
-		value,
-		validAfter,
-		validBefore,
-		[32]byte(nonceBytes),
-		signatureBytes,
-	)
+	// Optionally make sure the payer can still cover the transfer before spending gas
+	if f.config.CheckBalanceBeforeSettle {
+		sufficient, err := f.hasSufficientBalance(ctx, authorization.From, assetInfo.Address, value)
+		if err != nil {
+			return nil, x402.NewSettleError(x402.ReasonFailedToGetBalance, verifyResp.Payer, network, "", err)
+		}
+		if !sufficient {
+			return nil, x402.NewSettleError(x402.ReasonInsufficientBalance, verifyResp.Payer, network, "", nil)
+		}
+	}
+
+	return &settlementCall{
+		network:     network,
+		contract:    config.FacilitatorAddress(),
+		payer:       verifyResp.Payer,
+		token:       common.HexToAddress(assetInfo.Address),
+		from:        common.HexToAddress(authorization.From),
+		to:          common.HexToAddress(requirements.PayTo), // PayTo from requirements (safer)
+		value:       value,
+		validAfter:  validAfter,
+		validBefore: validBefore,
+		nonce:       [32]byte(nonceBytes),
+		signature:   signatureBytes,
+		receive:     receive,
+		direct:      direct,
+		vrs:         vrs,
+
+		permitPending: permitPending,
+	}, nil
+}
+
+// settlesDirectly reports whether a payment skips the facilitator contract and settles through
+// the token's transferWithAuthorization, per SettlementMode and SettleEOADirectly. Payments
+// that need the contract where it isn't used fail with ReasonFacilitatorContractUnavailable.
+func (f *ExactEvmScheme) settlesDirectly(
+	ctx context.Context,
+	envelope *evm.ExactEvmPayloadEnvelope,
+	network x402.Network,
+	config *evm.NetworkConfig,
+	payer string,
+	from string,
+	token string,
+	signature []byte,
+) (bool, error) {
+	contract := config.FacilitatorAddress()
+
+	mode := f.config.SettlementMode
+	if mode == SettlementModeAuto {
+		deployed, err := evm.VerifyFacilitatorContractDeployed(ctx, f.signer, config.ChainID, contract)
+		if err != nil {
+			return false, x402.NewSettleError(x402.ReasonFailedToCheckDeployment, payer, network, "", err)
+		}
+		mode = SettlementModeFacilitatorContract
+		if !deployed {
+			mode = SettlementModeDirectToken
+		}
+	}
+
+	isEIP3009 := envelope.Type == evm.PayloadTypeEIP3009
+	if envelope.Type == "" {
+		// Untyped payloads are EIP-3009 authorizations when the token supports them (see Verify)
+		supported, err := evm.VerifyEIP3009Support(ctx, f.signer, config.ChainID, from, token)
+		isEIP3009 = err == nil && supported
+	}
 
+	if mode == SettlementModeDirectToken {
+		if !isEIP3009 {
+			var err error
+			if f.config.SettlementMode == SettlementModeDirectToken {
+				err = errors.New("direct token settlement only supports EIP-3009 payments")
+			} else {
+				err = fmt.Errorf("no facilitator contract deployed at %s for ERC-20 payments", contract)
+			}
+			return false, x402.NewSettleError(x402.ReasonFacilitatorContractUnavailable, payer, network, "", err)
+		}
+		return true, nil
+	}
+
+	// Through the contract, except EIP-3009 payments signed by EOAs with SettleEOADirectly.
+	// A 65-byte (or compact) signature rules out ERC-6492 wrapping, but a smart wallet can
+	// produce one for ERC-1271 too.
+	if !f.config.SettleEOADirectly || !isEIP3009 ||
+		(len(signature) != evm.EOASignatureLength && len(signature) != evm.CompactSignatureLength) {
+		return false, nil
+	}
+	code, err := f.signer.GetCode(ctx, from)
 	if err != nil {
-		return nil, x402.NewSettleError("failed_to_execute_transfer", verifyResp.Payer, network, "", err)
+		return false, x402.NewSettleError(x402.ReasonFailedToCheckDeployment, payer, network, "", err)
 	}
+	return len(code) == 0, nil
+}
+
+// executeSettlement submits a prepared settlePayment call and waits for it to be mined
+func (f *ExactEvmScheme) executeSettlement(ctx context.Context, call *settlementCall) (*x402.SettleResponse, error) {
+	contract, abiJSON, function, args := call.contractCall()
+	txHash, err := f.signer.WriteContract(ctx, contract, abiJSON, function, args...)
+	if err != nil {
+		return nil, x402.NewSettleError(evm.SendErrorReason(err), call.payer, call.network, "", err)
+	}
+	f.submitted(call, txHash)
 
 	// Wait for transaction confirmation
 	receipt, err := f.signer.WaitForTransactionReceipt(ctx, txHash)
 	if err != nil {
-		return nil, x402.NewSettleError("failed_to_get_receipt", verifyResp.Payer, network, txHash, err)
+		return nil, x402.NewSettleError(evm.ReceiptErrorReason(err), call.payer, call.network, txHash, err)
 	}
 
 	if receipt.Status != evm.TxStatusSuccess {
-		return nil, x402.NewSettleError("transaction_failed", verifyResp.Payer, network, txHash, nil)
+		return nil, x402.NewSettleError(x402.ReasonTransactionFailed, call.payer, call.network, txHash, nil)
 	}
 
-	return &x402.SettleResponse{
+	// Confirm the token actually moved the value to payTo
+	transfer, err := evm.FindSettlementTransfer(receipt, call.token.Hex(), call.to.Hex(), call.value, nil)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonSettlementEventMismatch, call.payer, call.network, txHash, err)
+	}
+
+	return settledResponse(call, txHash, receipt, transfer), nil
+}
+
+// submitted records a sent settlement transaction, which may be mined even if the settlement
+// later fails: CancelAuthorization no longer relays its authorization, and Refund accepts it
+func (f *ExactEvmScheme) submitted(call *settlementCall, txHash string) {
+	f.pending.remove(pendingAuthorizationKey(string(call.network), call.token.Hex(), call.from.Hex(), "0x"+hex.EncodeToString(call.nonce[:])))
+	f.config.RefundLedger.RecordSettlement(call.network, txHash)
+}
+
+// settledResponse is the SettleResponse of call, mined in receipt with transfer as its transfer
+// event (nil when the signer doesn't report logs)
+func settledResponse(call *settlementCall, txHash string, receipt *evm.TransactionReceipt, transfer *evm.ERC20Transfer) *x402.SettleResponse {
+	result := &x402.SettleResponse{
 		Success:     true,
 		Transaction: txHash,
-		Network:     network,
-		Payer:       verifyResp.Payer,
-	}, nil
+		Network:     call.network,
+		Payer:       call.payer,
+		BlockNumber: receipt.BlockNumber,
+	}
+	if transfer != nil {
+		logIndex := transfer.LogIndex
+		result.LogIndex = &logIndex
+	}
+	return result
+}
+
+// isSoleSigner reports whether address is the facilitator's only signing address, so every
+// transaction it sends comes from address
+func (f *ExactEvmScheme) isSoleSigner(address string) bool {
+	addresses := f.signer.GetAddresses()
+	return len(addresses) == 1 && strings.EqualFold(addresses[0], address)
+}
+
+// SettleBatch settles several V2 payments, combining payments of the same token on the
+// same network into settlePaymentBatch transactions on the facilitator contract.
+//
+// Each payment is verified and prepared on its own first, so invalid payments fail
+// individually. Payments in a batch share its transaction hash. When the contract on a
+// network doesn't implement settlePaymentBatch, or a batch is rejected as a whole, its
+// payments are settled one by one instead. Transactions are sent one at a time, so the
+// signer never has two of them pending with the same nonce.
+func (f *ExactEvmScheme) SettleBatch(
+	ctx context.Context,
+	payloads []types.PaymentPayload,
+	requirements []types.PaymentRequirements,
+) ([]*x402.SettleResponse, error) {
+	if len(payloads) != len(requirements) {
+		return nil, fmt.Errorf("got %d payloads for %d requirements", len(payloads), len(requirements))
+	}
+
+	results := make([]*x402.SettleResponse, len(payloads))
+
+	// Group prepared payments by network and token
+	type pendingSettlement struct {
+		index   int
+		call    *settlementCall
+		release func()
+	}
+	groups := make(map[string][]pendingSettlement)
+	var groupOrder []string
+	cacheKeys := make([]string, len(payloads))
+	var gasChecked bool
+	var gasErr error
+	for i := range payloads {
+		cacheKeys[i] = settlementCacheKey(payloads[i], requirements[i])
+		if cacheKeys[i] != "" {
+			if cached, ok := f.config.SettlementCache.Get(cacheKeys[i]); ok {
+				results[i] = cached
+				continue
+			}
+		}
+
+		// Balances are read once per batch, before the first payment that needs settling
+		if !gasChecked {
+			gasErr = f.checkSignerGas(ctx, x402.Network(requirements[i].Network))
+			gasChecked = true
+		}
+		if gasErr != nil {
+			results[i] = failedSettleResponse(gasErr, x402.Network(requirements[i].Network))
+			continue
+		}
+
+		release, err := f.reserveNonce(payloads[i], requirements[i])
+		if err != nil {
+			results[i] = failedSettleResponse(err, x402.Network(requirements[i].Network))
+			continue
+		}
+		call, err := f.prepareSettlement(ctx, payloads[i], requirements[i], nil)
+		if err != nil {
+			release()
+			results[i] = failedSettleResponse(err, x402.Network(requirements[i].Network))
+			continue
+		}
+		key := string(call.network) + "|" + call.token.Hex()
+		if call.throughToken() {
+			// Settled through the token rather than the facilitator contract
+			key += "|token"
+		}
+		if _, exists := groups[key]; !exists {
+			groupOrder = append(groupOrder, key)
+		}
+		groups[key] = append(groups[key], pendingSettlement{index: i, call: call, release: release})
+	}
+
+	for _, key := range groupOrder {
+		pending := groups[key]
+		for start := 0; start < len(pending); start += f.config.MaxBatchSize {
+			chunk := pending[start:min(start+f.config.MaxBatchSize, len(pending))]
+
+			calls := make([]*settlementCall, len(chunk))
+			for j, p := range chunk {
+				calls[j] = p.call
+			}
+
+			if len(calls) > 1 && !calls[0].throughToken() && f.supportsBatchSettlement(ctx, calls[0].network, calls[0].contract) {
+				if batchResults, ok := f.executeBatchSettlement(ctx, calls); ok {
+					for j, p := range chunk {
+						results[p.index] = batchResults[j]
+					}
+					continue
+				}
+			}
+
+			// Settle sequentially
+			for _, p := range chunk {
+				result, err := f.executeSettlement(ctx, p.call)
+				if err != nil {
+					result = failedSettleResponse(err, p.call.network)
+				}
+				results[p.index] = result
+			}
+		}
+
+		for _, p := range pending {
+			if results[p.index].Success && cacheKeys[p.index] != "" {
+				f.config.SettlementCache.Put(cacheKeys[p.index], results[p.index])
+			}
+			// Settlements that timed out may still be mined, so their reservations are left to expire
+			if results[p.index].ErrorReason != x402.ReasonSettlementTimeout {
+				p.release()
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// supportsBatchSettlement reports whether the facilitator contract on network implements settlePaymentBatch
+func (f *ExactEvmScheme) supportsBatchSettlement(ctx context.Context, network x402.Network, contract string) bool {
+	chainID, err := evm.GetEvmChainId(string(network))
+	if err != nil {
+		return false
+	}
+	supported, err := evm.VerifyBatchSettlementSupport(ctx, f.signer, chainID, contract)
+	return err == nil && supported
+}
+
+// executeBatchSettlement submits calls (all for the same token) as one settlePaymentBatch transaction.
+// Returns ok=false when nothing was settled and the calls can safely be retried one by one.
+func (f *ExactEvmScheme) executeBatchSettlement(ctx context.Context, calls []*settlementCall) ([]*x402.SettleResponse, bool) {
+	froms := make([]common.Address, len(calls))
+	tos := make([]common.Address, len(calls))
+	values := make([]*big.Int, len(calls))
+	validAfters := make([]*big.Int, len(calls))
+	validBefores := make([]*big.Int, len(calls))
+	nonces := make([][32]byte, len(calls))
+	signatures := make([][]byte, len(calls))
+	for i, call := range calls {
+		froms[i] = call.from
+		tos[i] = call.to
+		values[i] = call.value
+		validAfters[i] = call.validAfter
+		validBefores[i] = call.validBefore
+		nonces[i] = call.nonce
+		signatures[i] = call.signature
+	}
+
+	txHash, err := f.signer.WriteContract(
+		ctx,
+		calls[0].contract,
+		evm.SettlePaymentBatchABI,
+		evm.FunctionSettlePaymentBatch,
+		calls[0].token,
+		froms,
+		tos,
+		values,
+		validAfters,
+		validBefores,
+		nonces,
+		signatures,
+	)
+	if err != nil {
+		// Typically a failed gas estimation because one payment would revert
+		return nil, false
+	}
+	for _, call := range calls {
+		f.submitted(call, txHash)
+	}
+
+	results := make([]*x402.SettleResponse, len(calls))
+
+	receipt, err := f.signer.WaitForTransactionReceipt(ctx, txHash)
+	if err != nil {
+		// The batch may still be mined, so the payments must not be resubmitted
+		for i, call := range calls {
+			results[i] = failedSettleResponse(
+				x402.NewSettleError(evm.ReceiptErrorReason(err), call.payer, call.network, txHash, err),
+				call.network,
+			)
+		}
+		return results, true
+	}
+
+	if receipt.Status != evm.TxStatusSuccess {
+		// The batch reverts as a whole, so no authorization was used
+		return nil, false
+	}
+
+	// Match each payment to its own transfer event, since identical payments emit identical events
+	matched := make(map[uint]bool)
+	for i, call := range calls {
+		transfer, err := evm.FindSettlementTransfer(receipt, call.token.Hex(), call.to.Hex(), call.value, matched)
+		if err != nil {
+			results[i] = failedSettleResponse(
+				x402.NewSettleError(x402.ReasonSettlementEventMismatch, call.payer, call.network, txHash, err),
+				call.network,
+			)
+			continue
+		}
+		if transfer != nil {
+			matched[transfer.LogIndex] = true
+		}
+		results[i] = settledResponse(call, txHash, receipt, transfer)
+	}
+	return results, true
+}
+
+// failedSettleResponse converts a settlement error into a failed SettleResponse
+func failedSettleResponse(err error, network x402.Network) *x402.SettleResponse {
+	se := &x402.SettleError{}
+	if errors.As(err, &se) {
+		return &x402.SettleResponse{
+			Success:     false,
+			ErrorReason: se.Reason,
+			Payer:       se.Payer,
+			Transaction: se.Transaction,
+			Network:     network,
+		}
+	}
+	return &x402.SettleResponse{Success: false, ErrorReason: err.Error(), Network: network}
 }
 
 // deploySmartWallet deploys an ERC-4337 smart wallet using the ERC-6492 factory
@@ -405,16 +1223,223 @@ func (f *ExactEvmScheme) deploySmartWallet(
 	return nil
 }
 
-// checkNonceUsed checks if a nonce has already been used
-func (f *ExactEvmScheme) checkNonceUsed(ctx context.Context, from string, nonce string, tokenAddress string) (bool, error) {
+// checkValidityWindow verifies that the current time falls within the authorization's
+// validAfter/validBefore window, allowing for the configured clock skew tolerance
+func (f *ExactEvmScheme) checkValidityWindow(
+	ctx context.Context,
+	authorization evm.ExactEIP3009Authorization,
+	network x402.Network,
+) error {
+	validAfter, ok := new(big.Int).SetString(authorization.ValidAfter, 10)
+	if !ok {
+		return x402.NewVerifyError(x402.ReasonInvalidAuthorizationValidAfter, authorization.From, network, fmt.Errorf("invalid validAfter: %s", authorization.ValidAfter))
+	}
+	validBefore, ok := new(big.Int).SetString(authorization.ValidBefore, 10)
+	if !ok {
+		return x402.NewVerifyError(x402.ReasonInvalidAuthorizationValidBefore, authorization.From, network, fmt.Errorf("invalid validBefore: %s", authorization.ValidBefore))
+	}
+
+	switch err := evm.CheckValidityWindow(validAfter, validBefore, f.currentTime(ctx), f.config.ClockSkewTolerance); err {
+	case evm.ErrAuthorizationExpired:
+		return x402.NewVerifyError(x402.ReasonAuthorizationExpired, authorization.From, network, nil)
+	case evm.ErrAuthorizationNotYetValid:
+		return x402.NewVerifyError(x402.ReasonAuthorizationNotYetValid, authorization.From, network, nil)
+	}
+
+	return nil
+}
+
+// currentTime returns the latest block timestamp when the signer can provide it,
+// falling back to the local clock otherwise
+func (f *ExactEvmScheme) currentTime(ctx context.Context) int64 {
+	if reader, ok := f.signer.(evm.BlockTimeReader); ok {
+		if ts, err := reader.GetBlockTimestamp(ctx); err == nil {
+			return int64(ts)
+		}
+	}
+	return time.Now().Unix()
+}
+
+// verifyPermit validates the EIP-2612 permit embedded in a permit-type payload
+func (f *ExactEvmScheme) verifyPermit(
+	ctx context.Context,
+	payload *evm.ExactERC20Payload,
+	authValue *big.Int,
+	chainID *big.Int,
+	facilitatorContract string,
+	tokenAddress string,
+	tokenName string,
+	tokenVersion string,
+	network x402.Network,
+) error {
+	payer := payload.Authorization.From
+	permit := payload.Permit
+	if permit == nil {
+		return x402.NewVerifyError(x402.ReasonMissingPermit, payer, network, nil)
+	}
+
+	if !strings.EqualFold(permit.Owner, payer) {
+		return x402.NewVerifyError(x402.ReasonPermitOwnerMismatch, payer, network, nil)
+	}
+	if !strings.EqualFold(permit.Spender, facilitatorContract) {
+		return x402.NewVerifyError(x402.ReasonPermitSpenderMismatch, payer, network, nil)
+	}
+
+	permitValue, ok := new(big.Int).SetString(permit.Value, 10)
+	if !ok {
+		return x402.NewVerifyError(x402.ReasonInvalidPermitValue, payer, network, fmt.Errorf("invalid value: %s", permit.Value))
+	}
+	if permitValue.Cmp(authValue) < 0 {
+		return x402.NewVerifyError(x402.ReasonInsufficientPermitValue, payer, network, nil)
+	}
+
+	deadline, ok := new(big.Int).SetString(permit.Deadline, 10)
+	if !ok {
+		return x402.NewVerifyError(x402.ReasonInvalidPermitDeadline, payer, network, fmt.Errorf("invalid deadline: %s", permit.Deadline))
+	}
+	if deadline.Cmp(big.NewInt(f.currentTime(ctx))) <= 0 {
+		return x402.NewVerifyError(x402.ReasonPermitExpired, payer, network, nil)
+	}
+
+	hash, err := evm.HashPermit(*permit, chainID, tokenAddress, tokenName, tokenVersion)
+	if err != nil {
+		return x402.NewVerifyError(x402.ReasonFailedToHashPermit, payer, network, err)
+	}
+	var hash32 [32]byte
+	copy(hash32[:], hash)
+
+	signature, err := evm.HexToBytes(permit.Signature)
+	if err != nil {
+		return x402.NewVerifyError(x402.ReasonInvalidPermitSignatureFormat, payer, network, err)
+	}
+
+	valid, _, err := evm.VerifyUniversalSignature(ctx, f.signer, payer, hash32, signature, false, f.signatureOptions()...)
+	if err != nil {
+		reason := x402.ReasonFailedToVerifyPermit
+		if errors.Is(err, evm.ErrMalleableSignature) {
+			reason = x402.ReasonMalleableSignature
+		}
+		return x402.NewVerifyError(reason, payer, network, err)
+	}
+	if !valid {
+		return x402.NewVerifyError(x402.ReasonInvalidPermitSignature, payer, network, nil)
+	}
+
+	return nil
+}
+
+// submitPermit submits an EIP-2612 permit to the token contract unless the facilitator
+// contract already holds a sufficient allowance (e.g. the permit was relayed by someone else)
+func (f *ExactEvmScheme) submitPermit(ctx context.Context, permit *evm.ExactPermit, tokenAddress string) error {
+	args, err := f.permitCall(ctx, permit, tokenAddress)
+	if err != nil || args == nil {
+		return err
+	}
+
+	txHash, err := f.signer.WriteContract(ctx, tokenAddress, evm.PermitABI, evm.FunctionPermit, args...)
+	if err != nil {
+		return fmt.Errorf("permit transaction failed: %w", err)
+	}
+
+	receipt, err := f.signer.WaitForTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return fmt.Errorf("failed to wait for permit: %w", err)
+	}
+	if receipt.Status != evm.TxStatusSuccess {
+		return fmt.Errorf("permit transaction reverted")
+	}
+
+	return nil
+}
+
+// simulatePermit simulates the permit submitPermit would send, reporting whether it is still
+// pending (so a settlement relying on its allowance can't be simulated yet)
+func (f *ExactEvmScheme) simulatePermit(ctx context.Context, simulator evm.ContractSimulator, permit *evm.ExactPermit, tokenAddress string) (bool, error) {
+	args, err := f.permitCall(ctx, permit, tokenAddress)
+	if err != nil || args == nil {
+		return false, err
+	}
+
+	if err := simulator.SimulateContract(ctx, tokenAddress, evm.PermitABI, evm.FunctionPermit, args...); err != nil {
+		return false, fmt.Errorf("permit simulation failed: %w", err)
+	}
+	return true, nil
+}
+
+// permitCall returns the arguments of the permit call for permit, or nil when the facilitator
+// contract already holds a sufficient allowance
+func (f *ExactEvmScheme) permitCall(ctx context.Context, permit *evm.ExactPermit, tokenAddress string) ([]interface{}, error) {
+	if permit == nil {
+		return nil, fmt.Errorf("missing permit")
+	}
+
+	value, _ := new(big.Int).SetString(permit.Value, 10)
+	deadline, _ := new(big.Int).SetString(permit.Deadline, 10)
+	if value == nil || deadline == nil {
+		return nil, fmt.Errorf("invalid permit value or deadline")
+	}
+
+	allowanceRes, err := f.signer.ReadContract(
+		ctx,
+		tokenAddress,
+		evm.ERC20ABI,
+		evm.FunctionAllowance,
+		common.HexToAddress(permit.Owner),
+		common.HexToAddress(permit.Spender),
+	)
+	if err == nil {
+		if allowance, ok := allowanceRes.(*big.Int); ok && allowance.Cmp(value) >= 0 {
+			return nil, nil
+		}
+	}
+
+	signature, err := evm.HexToBytes(permit.Signature)
+	if err != nil {
+		return nil, err
+	}
+	// EIP-2612 permits are checked with ecrecover, so the owner signs as an EOA
+	signature, err = evm.ExpandSignature(signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid permit signature: %w", err)
+	}
+
+	return []interface{}{
+		common.HexToAddress(permit.Owner),
+		common.HexToAddress(permit.Spender),
+		value,
+		deadline,
+		signature[64],
+		[32]byte(signature[0:32]),
+		[32]byte(signature[32:64]),
+	}, nil
+}
+
+// hasSufficientBalance reports whether the payer holds at least the required amount of the token
+func (f *ExactEvmScheme) hasSufficientBalance(ctx context.Context, payer string, tokenAddress string, required *big.Int) (bool, error) {
+	balance, err := f.signer.GetBalance(ctx, payer, tokenAddress)
+	if err != nil {
+		return false, err
+	}
+	if balance == nil {
+		return false, fmt.Errorf("no balance returned for %s", payer)
+	}
+	return balance.Cmp(required) >= 0, nil
+}
+
+// checkNonceUsed checks if a nonce has already been used by querying authorizationState
+// on the contract that tracks it (the token for EIP-3009, the facilitator contract otherwise)
+func (f *ExactEvmScheme) checkNonceUsed(ctx context.Context, from string, nonce string, contractAddress string) (bool, error) {
 	nonceBytes, err := evm.HexToBytes(nonce)
 	if err != nil {
 		return false, err
 	}
+	if len(nonceBytes) != 32 {
+		return false, fmt.Errorf("invalid nonce length: expected 32 bytes, got %d", len(nonceBytes))
+	}
 
 	result, err := f.signer.ReadContract(
 		ctx,
-		tokenAddress,
+		contractAddress,
 		evm.AuthorizationStateABI,
 		evm.FunctionAuthorizationState,
 		common.HexToAddress(from),
@@ -432,18 +1457,24 @@ func (f *ExactEvmScheme) checkNonceUsed(ctx context.Context, from string, nonce
 	return used, nil
 }
 
-// verifySignature verifies the EIP-712 signature
+// verifySignature verifies the EIP-712 signature of an EIP-3009 authorization signed under primaryType
 func (f *ExactEvmScheme) verifySignature(
 	ctx context.Context,
 	authorization evm.ExactEIP3009Authorization,
+	primaryType string,
 	signature []byte,
 	chainID *big.Int,
 	verifyingContract string,
 	tokenName string,
 	tokenVersion string,
 ) (bool, error) {
+	hashAuthorization := evm.HashEIP3009Authorization
+	if primaryType == evm.PrimaryTypeReceiveWithAuthorization {
+		hashAuthorization = evm.HashEIP3009ReceiveAuthorization
+	}
+
 	// Hash the EIP-712 typed data
-	hash, err := evm.HashEIP3009Authorization(
+	hash, err := hashAuthorization(
 		authorization,
 		chainID,
 		verifyingContract,
@@ -466,6 +1497,7 @@ func (f *ExactEvmScheme) verifySignature(
 		hash32,
 		signature,
 		true, // allowUndeployed in verify()
+		f.signatureOptions()...,
 	)
 
 	if err != nil {
@@ -14,7 +14,8 @@ import (
 
 // ExactEvmScheme implements the SchemeNetworkServer interface for EVM exact payments (V2)
 type ExactEvmScheme struct {
-	moneyParsers []x402.MoneyParser
+	moneyParsers   []x402.MoneyParser
+	priceConverter evm.PriceConverter
 }
 
 // NewExactEvmScheme creates a new ExactEvmScheme
@@ -61,20 +62,37 @@ func (s *ExactEvmScheme) RegisterMoneyParser(parser x402.MoneyParser) *ExactEvmS
 	return s
 }
 
+// SetPriceConverter converts dollar prices into the default asset through converter instead of 1:1,
+// for networks whose default asset isn't a dollar stablecoin. Custom money parsers still run first.
+func (s *ExactEvmScheme) SetPriceConverter(converter evm.PriceConverter) *ExactEvmScheme {
+	s.priceConverter = converter
+	return s
+}
+
 // ParsePrice parses a price string and converts it to an asset amount (V2)
-// If price is already an AssetAmount, returns it directly.
+// If price is already an AssetAmount or a UnitPrice, returns it without conversion.
 // If price is Money (string | number), parses to decimal and tries custom parsers.
 // Falls back to default conversion if all custom parsers return nil.
 //
 // Args:
 //
-//	price: The price to parse (can be string, number, or AssetAmount map)
+//	price: The price to parse (can be string, number, AssetAmount map, or UnitPrice)
 //	network: The network identifier
 //
 // Returns:
 //
 //	AssetAmount with amount, asset, and optional extra fields
 func (s *ExactEvmScheme) ParsePrice(price x402.Price, network x402.Network) (x402.AssetAmount, error) {
+	return s.ParsePriceContext(context.Background(), price, network)
+}
+
+// ParsePriceContext is ParsePrice with a context for the price converter
+func (s *ExactEvmScheme) ParsePriceContext(ctx context.Context, price x402.Price, network x402.Network) (x402.AssetAmount, error) {
+	// A UnitPrice is already in the asset's smallest unit
+	if unitPrice, ok := x402.AsUnitPrice(price); ok {
+		return s.parseUnitPrice(unitPrice, network)
+	}
+
 	// If already an AssetAmount (map with "amount" and "asset"), return it directly
 	if priceMap, ok := price.(map[string]interface{}); ok {
 		if amountVal, hasAmount := priceMap["amount"]; hasAmount {
@@ -130,7 +148,47 @@ func (s *ExactEvmScheme) ParsePrice(price x402.Price, network x402.Network) (x40
 	}
 
 	// All custom parsers returned nil, use default conversion
-	return s.defaultMoneyConversion(decimalAmount, network)
+	return s.defaultMoneyConversion(ctx, decimalAmount, network)
+}
+
+// parseUnitPrice resolves the asset of a UnitPrice, leaving its amount as is
+func (s *ExactEvmScheme) parseUnitPrice(price x402.UnitPrice, network x402.Network) (x402.AssetAmount, error) {
+	if price.Amount == nil || price.Amount.Sign() <= 0 {
+		return x402.AssetAmount{}, fmt.Errorf("unit price amount must be positive")
+	}
+
+	config, err := evm.GetNetworkConfig(string(network))
+	if err != nil {
+		return x402.AssetAmount{}, err
+	}
+
+	// Resolve symbols strictly: falling back to the default asset would charge the amount in the wrong token
+	asset, err := evm.NormalizeAssetIdentifier(string(network), price.Asset)
+	if err != nil {
+		return x402.AssetAmount{}, err
+	}
+	if asset == "" {
+		asset = config.DefaultAsset.Address
+	} else if address, ok := evm.ParseERC20Asset(asset); ok {
+		asset = address
+	} else if !evm.IsValidAddress(asset) {
+		info, ok := config.SupportedAssets[strings.ToUpper(asset)]
+		if !ok {
+			return x402.AssetAmount{}, fmt.Errorf("unknown asset %s on %s", price.Asset, network)
+		}
+		asset = info.Address
+	}
+
+	extra := price.Extra
+	if extra == nil {
+		extra = make(map[string]interface{})
+	}
+
+	return x402.AssetAmount{
+		Asset:  asset,
+		Amount: price.Amount.String(),
+		Extra:  extra,
+	}, nil
 }
 
 // parseMoneyToDecimal converts Money (string | number) to decimal amount
@@ -165,8 +223,8 @@ func (s *ExactEvmScheme) parseMoneyToDecimal(price x402.Price) (float64, error)
 	}
 }
 
-// defaultMoneyConversion converts decimal amount to USDC AssetAmount
-func (s *ExactEvmScheme) defaultMoneyConversion(amount float64, network x402.Network) (x402.AssetAmount, error) {
+// defaultMoneyConversion converts decimal amount to an AssetAmount of the network's default asset
+func (s *ExactEvmScheme) defaultMoneyConversion(ctx context.Context, amount float64, network x402.Network) (x402.AssetAmount, error) {
 	networkStr := string(network)
 
 	// Get network config to determine the asset
@@ -191,6 +249,19 @@ func (s *ExactEvmScheme) defaultMoneyConversion(amount float64, network x402.Net
 		}, nil
 	}
 
+	// Price the default asset at its exchange rate when it isn't pegged to the dollar
+	if s.priceConverter != nil {
+		converted, err := s.priceConverter.Convert(ctx, amount, config.DefaultAsset, networkStr)
+		if err != nil {
+			return x402.AssetAmount{}, fmt.Errorf("failed to convert price: %w", err)
+		}
+		return x402.AssetAmount{
+			Asset:  config.DefaultAsset.Address,
+			Amount: converted.String(),
+			Extra:  make(map[string]interface{}),
+		}, nil
+	}
+
 	// Convert decimal to smallest unit (e.g., $1.50 -> 1500000 for USDC with 6 decimals)
 	amountStr := fmt.Sprintf("%.6f", amount)
 	parsedAmount, err := evm.ParseAmount(amountStr, config.DefaultAsset.Decimals)
@@ -256,8 +327,26 @@ func (s *ExactEvmScheme) EnhancePaymentRequirements(
 		requirements.Extra["version"] = assetInfo.Version
 	}
 
+	// Describe the asset so clients can compare requirements across assets
+	if _, ok := requirements.Extra[x402.ExtraDecimals]; !ok {
+		requirements.Extra[x402.ExtraDecimals] = assetInfo.Decimals
+	}
+	if _, ok := requirements.Extra[x402.ExtraSymbol]; !ok {
+		for symbol, info := range config.SupportedAssets {
+			if strings.EqualFold(info.Address, requirements.Asset) {
+				requirements.Extra[x402.ExtraSymbol] = symbol
+				break
+			}
+		}
+	}
+
 	// Copy extensions from supportedKind if provided
 	if supportedKind.Extra != nil {
+		// Facilitators settling through receiveWithAuthorization need clients to sign for it
+		if function, ok := supportedKind.Extra[evm.ExtraAuthorizationFunction]; ok {
+			requirements.Extra[evm.ExtraAuthorizationFunction] = function
+		}
+
 		for _, key := range extensionKeys {
 			if val, ok := supportedKind.Extra[key]; ok {
 				requirements.Extra[key] = val
@@ -356,11 +445,7 @@ func (s *ExactEvmScheme) ConvertFromTokenAmount(tokenAmount string, network stri
 
 // GetSupportedNetworks returns the list of supported networks
 func (s *ExactEvmScheme) GetSupportedNetworks() []string {
-	networks := make([]string, 0, len(evm.NetworkConfigs))
-	for network := range evm.NetworkConfigs {
-		networks = append(networks, network)
-	}
-	return networks
+	return evm.GetConfiguredNetworks()
 }
 
 // GetSupportedAssets returns the list of supported assets for a network
@@ -1,10 +1,14 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"math/big"
 	"testing"
 
 	x402 "x402-go"
+	"x402-go/mechanisms/evm"
 )
 
 // TestRegisterMoneyParser_SingleCustomParser tests a single custom money parser
@@ -284,3 +288,116 @@ func TestRegisterMoneyParser_NoCustomParsers(t *testing.T) {
 		t.Errorf("Expected amount %s, got %s", expectedAmount, result.Amount)
 	}
 }
+
+// TestParsePrice_UnitPrice tests that unit prices skip money parsers and the dollar conversion
+func TestParsePrice_UnitPrice(t *testing.T) {
+	server := NewExactEvmScheme()
+	server.RegisterMoneyParser(func(amount float64, network x402.Network) (*x402.AssetAmount, error) {
+		t.Error("Money parsers should not be called for unit prices")
+		return nil, nil
+	})
+
+	oneDAI, _ := new(big.Int).SetString("1000000000000000000", 10)
+
+	tests := []struct {
+		name       string
+		price      x402.Price
+		wantAsset  string
+		wantAmount string
+		wantErr    bool
+	}{
+		{
+			name:       "default asset",
+			price:      x402.UnitPrice{Amount: big.NewInt(1500)},
+			wantAsset:  "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
+			wantAmount: "1500",
+		},
+		{
+			name:       "asset symbol",
+			price:      x402.UnitPrice{Amount: oneDAI, Asset: "dai"},
+			wantAsset:  "0x6B175474E89094C44Da98b954EedeAC495271d0F",
+			wantAmount: "1000000000000000000",
+		},
+		{
+			name:       "asset address by pointer",
+			price:      &x402.UnitPrice{Amount: big.NewInt(42), Asset: "0x6B175474E89094C44Da98b954EedeAC495271d0F"},
+			wantAsset:  "0x6B175474E89094C44Da98b954EedeAC495271d0F",
+			wantAmount: "42",
+		},
+		{
+			name:    "unknown symbol",
+			price:   x402.UnitPrice{Amount: big.NewInt(1), Asset: "NOPE"},
+			wantErr: true,
+		},
+		{
+			name:    "missing amount",
+			price:   x402.UnitPrice{Asset: "USDC"},
+			wantErr: true,
+		},
+		{
+			name:    "zero amount",
+			price:   x402.UnitPrice{Amount: big.NewInt(0)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := server.ParsePrice(tt.price, "eip155:1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePrice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if result.Asset != tt.wantAsset {
+				t.Errorf("Expected asset %s, got %s", tt.wantAsset, result.Asset)
+			}
+			if result.Amount != tt.wantAmount {
+				t.Errorf("Expected amount %s, got %s", tt.wantAmount, result.Amount)
+			}
+		})
+	}
+}
+
+// fixedRateConverter prices the asset at a fixed number of cents per whole token
+type fixedRateConverter struct {
+	centsPerToken int64
+	gotAsset      evm.AssetInfo
+}
+
+func (c *fixedRateConverter) Convert(ctx context.Context, usd float64, asset evm.AssetInfo, network string) (*big.Int, error) {
+	c.gotAsset = asset
+	units := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(asset.Decimals)), nil)
+	units.Mul(units, big.NewInt(int64(math.Round(usd*100))))
+	return units.Div(units, big.NewInt(c.centsPerToken)), nil
+}
+
+// TestParsePrice_PriceConverter tests that money prices of the default asset go through the converter
+func TestParsePrice_PriceConverter(t *testing.T) {
+	converter := &fixedRateConverter{centsPerToken: 200}
+	server := NewExactEvmScheme().SetPriceConverter(converter)
+
+	result, err := server.ParsePriceContext(context.Background(), "$0.10", "eip155:1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Amount != "50000" { // $0.10 at $2 per token, 6 decimals
+		t.Errorf("Expected amount 50000, got %s", result.Amount)
+	}
+	if converter.gotAsset.Address != "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48" {
+		t.Errorf("Expected the default asset to be converted, got %s", converter.gotAsset.Address)
+	}
+
+	// Custom money parsers still take precedence
+	server.RegisterMoneyParser(func(amount float64, network x402.Network) (*x402.AssetAmount, error) {
+		return &x402.AssetAmount{Asset: "0x6B175474E89094C44Da98b954EedeAC495271d0F", Amount: "1"}, nil
+	})
+	result, err = server.ParsePrice("$0.10", "eip155:1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Amount != "1" {
+		t.Errorf("Expected the money parser result, got %s", result.Amount)
+	}
+}
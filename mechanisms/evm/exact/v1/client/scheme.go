@@ -172,6 +172,10 @@ func (c *ExactEvmSchemeV1) signAuthorization(
 		"nonce":       nonceBytes,
 	}
 
-	// Sign the typed data
-	return c.signer.SignTypedData(ctx, domain, types, "TransferWithAuthorization", message)
+	// Sign the typed data, normalizing to low-S as KMS and remote signers may not
+	signature, err := c.signer.SignTypedData(ctx, domain, types, "TransferWithAuthorization", message)
+	if err != nil {
+		return nil, err
+	}
+	return evm.NormalizeSignatureLowS(signature), nil
 }
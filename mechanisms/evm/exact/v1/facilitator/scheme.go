@@ -21,6 +21,37 @@ type ExactEvmSchemeV1Config struct {
 	// DeployERC4337WithEIP6492 enables automatic deployment of ERC-4337 smart wallets
 	// via EIP-6492 when encountering undeployed contract signatures during settlement
 	DeployERC4337WithEIP6492 bool
+
+	// NonceGuard rejects a settlement while another settlement of the same authorization is
+	// in flight (defaults to an in-memory guard; share one across facilitator instances)
+	NonceGuard x402.NonceGuard
+
+	// SettlementCache returns the original result when the same payment is settled again,
+	// instead of broadcasting a second transaction (defaults to an in-memory cache; share one
+	// across facilitator instances)
+	SettlementCache x402.SettlementCache
+
+	// RateLimiter limits verify and settle requests per payer, rejecting the excess with
+	// rate_limited. Only requests carrying a valid payer signature are counted (nil disables
+	// rate limiting; share one across facilitator instances)
+	RateLimiter x402.PayerRateLimiter
+
+	// RequireLowS rejects EOA signatures whose s is above half the curve order with
+	// malleable_signature, for tokens and contracts that only accept low-S signatures
+	RequireLowS bool
+
+	// AllowOverpayment accepts authorizations for more than maxAmountRequired. Settlement
+	// always transfers the full signed value, so by default such payments are rejected with
+	// amount_exceeds_required rather than charging the payer more than the price.
+	AllowOverpayment bool
+
+	// AllowZeroAmount accepts payments of zero. By default zero-value requirements and
+	// authorizations are rejected with zero_amount, since they verify without paying anything.
+	AllowZeroAmount bool
+
+	// MinAmounts rejects payments below a minimum per network and asset with
+	// amount_below_minimum, so dust payments don't cost more gas to settle than they are worth
+	MinAmounts []evm.MinAmount
 }
 
 // ExactEvmSchemeV1 implements the SchemeNetworkFacilitatorV1 interface for EVM exact payments (V1)
@@ -43,12 +74,44 @@ func NewExactEvmSchemeV1(signer evm.FacilitatorEvmSigner, config *ExactEvmScheme
 	if config != nil {
 		cfg = *config
 	}
+	if cfg.NonceGuard == nil {
+		cfg.NonceGuard = x402.NewMemoryNonceGuard(0)
+	}
+	if cfg.SettlementCache == nil {
+		cfg.SettlementCache = x402.NewMemorySettlementCache(0)
+	}
 	return &ExactEvmSchemeV1{
 		signer: signer,
 		config: cfg,
 	}
 }
 
+// checkAmount enforces the amount invariant shared by Verify and Settle: neither value may be
+// zero unless zero amounts are allowed, and the signed value must equal maxAmountRequired, or
+// exceed it when overpayment is allowed. It returns the reason the value is rejected, or "" when
+// it is acceptable.
+func (f *ExactEvmSchemeV1) checkAmount(authValue, requiredValue *big.Int) string {
+	if (authValue.Sign() == 0 || requiredValue.Sign() == 0) && !f.config.AllowZeroAmount {
+		return x402.ReasonZeroAmount
+	}
+	switch cmp := authValue.Cmp(requiredValue); {
+	case cmp < 0:
+		return x402.ReasonInvalidExactEVMPayloadAuthorizationValue
+	case cmp > 0 && !f.config.AllowOverpayment:
+		// The signature covers the value, so settlement can't transfer less than was authorized
+		return x402.ReasonAmountExceedsRequired
+	}
+	return ""
+}
+
+// signatureOptions returns the EOA verification options of the config
+func (f *ExactEvmSchemeV1) signatureOptions() []evm.SignatureOption {
+	if f.config.RequireLowS {
+		return []evm.SignatureOption{evm.RequireLowS()}
+	}
+	return nil
+}
+
 // Scheme returns the scheme identifier
 func (f *ExactEvmSchemeV1) Scheme() string {
 	return evm.SchemeExact
@@ -81,59 +144,64 @@ func (f *ExactEvmSchemeV1) Verify(
 
 	// Validate scheme (v1 has scheme at top level)
 	if payload.Scheme != evm.SchemeExact || requirements.Scheme != evm.SchemeExact {
-		return nil, x402.NewVerifyError("unsupported_scheme", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonUnsupportedScheme, "", network, nil)
 	}
 
 	// Validate network (v1 has network at top level)
 	if payload.Network != requirements.Network {
-		return nil, x402.NewVerifyError("network_mismatch", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonNetworkMismatch, "", network, nil)
+	}
+
+	// Reject mistyped (bad checksum) addresses before any funds can move
+	if err := evm.ValidateRequirementAddresses(requirements.PayTo, requirements.Asset); err != nil {
+		return nil, x402.NewVerifyError(x402.ReasonInvalidAddressChecksum, "", network, err)
 	}
 
 	// Parse EVM payload
 	evmPayload, err := evm.PayloadFromMap(payload.Payload)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_payload", "", network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidPayload, "", network, err)
 	}
 
 	// Validate signature exists
 	if evmPayload.Signature == "" {
-		return nil, x402.NewVerifyError("missing_signature", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonMissingSignature, "", network, nil)
 	}
 
 	// Get network configuration
 	networkStr := string(requirements.Network)
 	config, err := evm.GetNetworkConfig(networkStr)
 	if err != nil {
-		return nil, x402.NewVerifyError("failed_to_get_network_config", "", network, err)
+		return nil, x402.NewVerifyError(x402.ReasonFailedToGetNetworkConfig, "", network, err)
 	}
 
 	// Get asset info
 	assetInfo, err := evm.GetAssetInfo(networkStr, requirements.Asset)
 	if err != nil {
-		return nil, x402.NewVerifyError("failed_to_get_asset_info", "", network, err)
+		return nil, x402.NewVerifyError(x402.ReasonFailedToGetAssetInfo, "", network, err)
 	}
 
 	// Check EIP-712 domain parameters
 	var extraMap map[string]interface{}
 	if requirements.Extra != nil {
 		if err := json.Unmarshal(*requirements.Extra, &extraMap); err != nil {
-			return nil, x402.NewVerifyError("invalid_extra_field", evmPayload.Authorization.From, network, err)
+			return nil, x402.NewVerifyError(x402.ReasonInvalidExtraField, evmPayload.Authorization.From, network, err)
 		}
 	}
 
 	if extraMap == nil || extraMap["name"] == nil || extraMap["version"] == nil {
-		return nil, x402.NewVerifyError("missing_eip712_domain", evmPayload.Authorization.From, network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonMissingEIP712Domain, evmPayload.Authorization.From, network, nil)
 	}
 
 	// Validate authorization matches requirements
 	if !strings.EqualFold(evmPayload.Authorization.To, requirements.PayTo) {
-		return nil, x402.NewVerifyError("invalid_exact_evm_payload_recipient_mismatch", evmPayload.Authorization.From, network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactEVMPayloadRecipientMismatch, evmPayload.Authorization.From, network, nil)
 	}
 
 	// Parse and validate amount
 	authValue, ok := new(big.Int).SetString(evmPayload.Authorization.Value, 10)
 	if !ok || evmPayload.Authorization.Value == "" {
-		return nil, x402.NewVerifyError("invalid_authorization_value", evmPayload.Authorization.From, network, fmt.Errorf("invalid value: %s", evmPayload.Authorization.Value))
+		return nil, x402.NewVerifyError(x402.ReasonInvalidAuthorizationValue, evmPayload.Authorization.From, network, fmt.Errorf("invalid value: %s", evmPayload.Authorization.Value))
 	}
 
 	// V1: Use MaxAmountRequired field
@@ -141,30 +209,35 @@ func (f *ExactEvmSchemeV1) Verify(
 
 	requiredValue, ok := new(big.Int).SetString(amountStr, 10)
 	if !ok {
-		return nil, x402.NewVerifyError("invalid_required_amount", evmPayload.Authorization.From, network, fmt.Errorf("invalid amount: %s", amountStr))
+		return nil, x402.NewVerifyError(x402.ReasonInvalidRequiredAmount, evmPayload.Authorization.From, network, fmt.Errorf("invalid amount: %s", amountStr))
+	}
+
+	if reason := f.checkAmount(authValue, requiredValue); reason != "" {
+		return nil, x402.NewVerifyError(reason, evmPayload.Authorization.From, network, nil)
 	}
 
-	if authValue.Cmp(requiredValue) < 0 {
-		return nil, x402.NewVerifyError("invalid_exact_evm_payload_authorization_value", evmPayload.Authorization.From, network, nil)
+	// Dust payments cost more gas to settle than they are worth
+	if minimum := evm.FindMinAmount(f.config.MinAmounts, networkStr, assetInfo.Address); minimum != nil && authValue.Cmp(minimum) < 0 {
+		return nil, x402.NewVerifyError(x402.ReasonAmountBelowMinimum, evmPayload.Authorization.From, network, fmt.Errorf("amount %s is below the minimum of %s", authValue, minimum))
 	}
 
 	// V1 specific: Check validBefore is in the future (with 6 second buffer for block time)
 	now := time.Now().Unix()
 	validBefore, _ := new(big.Int).SetString(evmPayload.Authorization.ValidBefore, 10)
 	if validBefore.Cmp(big.NewInt(now+6)) < 0 {
-		return nil, x402.NewVerifyError("invalid_exact_evm_payload_authorization_valid_before", evmPayload.Authorization.From, network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactEVMPayloadAuthorizationValidBefore, evmPayload.Authorization.From, network, nil)
 	}
 
-	// V1 specific: Check validAfter is not in the future
+	// V1 specific: Check validAfter is not in the future, allowing for clock skew with the client
 	validAfter, _ := new(big.Int).SetString(evmPayload.Authorization.ValidAfter, 10)
-	if validAfter.Cmp(big.NewInt(now)) > 0 {
-		return nil, x402.NewVerifyError("invalid_exact_evm_payload_authorization_valid_after", evmPayload.Authorization.From, network, nil)
+	if validAfter.Cmp(big.NewInt(now+int64(evm.ClockSkewTolerance.Seconds()))) > 0 {
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactEVMPayloadAuthorizationValidAfter, evmPayload.Authorization.From, network, nil)
 	}
 
 	// Check balance
 	balance, err := f.signer.GetBalance(ctx, evmPayload.Authorization.From, assetInfo.Address)
 	if err == nil && balance.Cmp(requiredValue) < 0 {
-		return nil, x402.NewVerifyError("insufficient_funds", evmPayload.Authorization.From, network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInsufficientFunds, evmPayload.Authorization.From, network, nil)
 	}
 
 	// Extract token info from requirements (already unmarshaled earlier)
@@ -174,7 +247,7 @@ func (f *ExactEvmSchemeV1) Verify(
 	// Verify signature
 	signatureBytes, err := evm.HexToBytes(evmPayload.Signature)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_signature_format", evmPayload.Authorization.From, network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidSignatureFormat, evmPayload.Authorization.From, network, err)
 	}
 
 	valid, err := f.verifySignature(
@@ -187,11 +260,16 @@ func (f *ExactEvmSchemeV1) Verify(
 		tokenVersion,
 	)
 	if err != nil {
-		return nil, x402.NewVerifyError("failed_to_verify_signature", evmPayload.Authorization.From, network, err)
+		return nil, x402.NewVerifyError(evm.SignatureErrorReason(err), evmPayload.Authorization.From, network, err)
 	}
 
 	if !valid {
-		return nil, x402.NewVerifyError("invalid_exact_evm_payload_signature", evmPayload.Authorization.From, network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactEVMPayloadSignature, evmPayload.Authorization.From, network, nil)
+	}
+
+	// Throttle the payer once the signature proves the request is theirs
+	if f.config.RateLimiter != nil && !f.config.RateLimiter.Allow(evmPayload.Authorization.From) {
+		return nil, x402.NewVerifyError(x402.ReasonRateLimited, evmPayload.Authorization.From, network, nil)
 	}
 
 	return &x402.VerifyResponse{
@@ -200,11 +278,49 @@ func (f *ExactEvmSchemeV1) Verify(
 	}, nil
 }
 
-// Settle settles a V1 payment on-chain
+// Settle settles a V1 payment on-chain, rejecting it with ReasonNonceInFlight while another
+// settlement of the same authorization is in progress
 func (f *ExactEvmSchemeV1) Settle(
 	ctx context.Context,
 	payload types.PaymentPayloadV1,
 	requirements types.PaymentRequirementsV1,
+) (*x402.SettleResponse, error) {
+	evmPayload, err := evm.PayloadFromMap(payload.Payload)
+	if err != nil {
+		// Reported by verification
+		return f.settle(ctx, payload, requirements)
+	}
+
+	authorization := evmPayload.Authorization
+
+	// A payment settled before gets its original result
+	cacheKey := evm.SettlementCacheKey(payload.Network, requirements.Asset, authorization.From, authorization.Nonce, payload, requirements)
+	if cached, ok := f.config.SettlementCache.Get(cacheKey); ok {
+		return cached, nil
+	}
+
+	release, ok := f.config.NonceGuard.Reserve(payload.Network, requirements.Asset, authorization.From, authorization.Nonce)
+	if !ok {
+		return nil, x402.NewSettleError(x402.ReasonNonceInFlight, authorization.From, x402.Network(payload.Network), "", nil)
+	}
+
+	result, err := f.settle(ctx, payload, requirements)
+	if err == nil {
+		// Cached before the reservation is released, so a retry finds one or the other
+		f.config.SettlementCache.Put(cacheKey, result)
+	}
+	// A settlement that timed out may still be mined, so its reservation is left to expire
+	if !errors.Is(err, evm.ErrReceiptTimeout) {
+		release()
+	}
+	return result, err
+}
+
+// settle verifies and settles a V1 payment on-chain
+func (f *ExactEvmSchemeV1) settle(
+	ctx context.Context,
+	payload types.PaymentPayloadV1,
+	requirements types.PaymentRequirementsV1,
 ) (*x402.SettleResponse, error) {
 	network := x402.Network(payload.Network)
 
@@ -216,32 +332,39 @@ func (f *ExactEvmSchemeV1) Settle(
 		if errors.As(err, &ve) {
 			return nil, x402.NewSettleError(ve.Reason, ve.Payer, ve.Network, "", ve.Err)
 		}
-		return nil, x402.NewSettleError("verification_failed", "", network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonVerificationFailed, "", network, "", err)
 	}
 
 	// Parse EVM payload
 	evmPayload, err := evm.PayloadFromMap(payload.Payload)
 	if err != nil {
-		return nil, x402.NewSettleError("invalid_payload", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonInvalidPayload, verifyResp.Payer, network, "", err)
+	}
+
+	// Settle exactly the signed value, and only if it is the one verified against the requirements
+	value, ok := new(big.Int).SetString(evmPayload.Authorization.Value, 10)
+	requiredValue, requiredOk := new(big.Int).SetString(requirements.MaxAmountRequired, 10)
+	if !ok || !requiredOk || f.checkAmount(value, requiredValue) != "" {
+		return nil, x402.NewSettleError(x402.ReasonAmountMismatch, verifyResp.Payer, network, "", nil)
 	}
 
 	// Get asset info
 	networkStr := string(requirements.Network)
 	assetInfo, err := evm.GetAssetInfo(networkStr, requirements.Asset)
 	if err != nil {
-		return nil, x402.NewSettleError("failed_to_get_asset_info", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonFailedToGetAssetInfo, verifyResp.Payer, network, "", err)
 	}
 
 	// Parse signature
 	signatureBytes, err := evm.HexToBytes(evmPayload.Signature)
 	if err != nil {
-		return nil, x402.NewSettleError("invalid_signature_format", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonInvalidSignatureFormat, verifyResp.Payer, network, "", err)
 	}
 
 	// Parse ERC-6492 signature to extract inner signature if needed
 	sigData, err := evm.ParseERC6492Signature(signatureBytes)
 	if err != nil {
-		return nil, x402.NewSettleError("failed_to_parse_signature", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonFailedToParseSignature, verifyResp.Payer, network, "", err)
 	}
 
 	// Check if wallet needs deployment (undeployed smart wallet with ERC-6492)
@@ -249,7 +372,7 @@ func (f *ExactEvmSchemeV1) Settle(
 	if sigData.Factory != zeroFactory && len(sigData.FactoryCalldata) > 0 {
 		code, err := f.signer.GetCode(ctx, evmPayload.Authorization.From)
 		if err != nil {
-			return nil, x402.NewSettleError("failed_to_check_deployment", verifyResp.Payer, network, "", err)
+			return nil, x402.NewSettleError(x402.ReasonFailedToCheckDeployment, verifyResp.Payer, network, "", err)
 		}
 
 		if len(code) == 0 {
@@ -267,11 +390,14 @@ func (f *ExactEvmSchemeV1) Settle(
 		}
 	}
 
-	// Use inner signature for settlement
-	signatureBytes = sigData.InnerSignature
+	// Use inner signature for settlement, expanding EIP-2098 compact signatures from EOAs
+	// so they take the v,r,s path below
+	signatureBytes, err = evm.ExpandCompactEOASignature(ctx, f.signer, evmPayload.Authorization.From, sigData.InnerSignature)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonFailedToCheckDeployment, verifyResp.Payer, network, "", err)
+	}
 
 	// Parse values
-	value, _ := new(big.Int).SetString(evmPayload.Authorization.Value, 10)
 	validAfter, _ := new(big.Int).SetString(evmPayload.Authorization.ValidAfter, 10)
 	validBefore, _ := new(big.Int).SetString(evmPayload.Authorization.ValidBefore, 10)
 	nonceBytes, _ := evm.HexToBytes(evmPayload.Authorization.Nonce)
@@ -319,25 +445,37 @@ func (f *ExactEvmSchemeV1) Settle(
 	}
 
 	if err != nil {
-		return nil, x402.NewSettleError("transaction_failed", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonTransactionFailed, verifyResp.Payer, network, "", err)
 	}
 
 	// Wait for transaction confirmation
 	receipt, err := f.signer.WaitForTransactionReceipt(ctx, txHash)
 	if err != nil {
-		return nil, x402.NewSettleError("failed_to_get_receipt", verifyResp.Payer, network, txHash, err)
+		return nil, x402.NewSettleError(evm.ReceiptErrorReason(err), verifyResp.Payer, network, txHash, err)
 	}
 
 	if receipt.Status != evm.TxStatusSuccess {
-		return nil, x402.NewSettleError("invalid_transaction_state", verifyResp.Payer, network, txHash, nil)
+		return nil, x402.NewSettleError(x402.ReasonInvalidTransactionState, verifyResp.Payer, network, txHash, nil)
 	}
 
-	return &x402.SettleResponse{
+	// Confirm the token actually moved the value to payTo
+	transfer, err := evm.FindSettlementTransfer(receipt, assetInfo.Address, evmPayload.Authorization.To, value, nil)
+	if err != nil {
+		return nil, x402.NewSettleError(x402.ReasonSettlementEventMismatch, verifyResp.Payer, network, txHash, err)
+	}
+
+	result := &x402.SettleResponse{
 		Success:     true,
 		Transaction: txHash,
 		Network:     network,
 		Payer:       verifyResp.Payer,
-	}, nil
+		BlockNumber: receipt.BlockNumber,
+	}
+	if transfer != nil {
+		logIndex := transfer.LogIndex
+		result.LogIndex = &logIndex
+	}
+	return result, nil
 }
 
 // verifySignature verifies the EIP-712 signature
@@ -374,6 +512,7 @@ func (f *ExactEvmSchemeV1) verifySignature(
 		hash32,
 		signature,
 		true, // allowUndeployed in verify()
+		f.signatureOptions()...,
 	)
 
 	if err != nil {
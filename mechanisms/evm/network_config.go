@@ -0,0 +1,201 @@
+package evm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+)
+
+// networkConfigsFile is the JSON document accepted by LoadNetworkConfigs
+//
+//	{
+//	  "networks": {
+//	    "eip155:43114": {
+//	      "chainId": 43114,
+//	      "facilitatorContract": "0x...",
+//	      "defaultAsset": "USDC",
+//	      "assets": {
+//	        "USDC": {"address": "0x...", "name": "USD Coin", "version": "2", "decimals": 6, "supportsEip3009": true}
+//	      }
+//	    }
+//	  }
+//	}
+type networkConfigsFile struct {
+	Networks map[string]networkConfigEntry `json:"networks"`
+}
+
+type networkConfigEntry struct {
+	ChainID             *int64                    `json:"chainId,omitempty"`
+	FacilitatorContract string                    `json:"facilitatorContract,omitempty"`
+	DefaultAsset        string                    `json:"defaultAsset,omitempty"`
+	Assets              map[string]assetInfoEntry `json:"assets,omitempty"`
+}
+
+type assetInfoEntry struct {
+	Address         string `json:"address"`
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	Decimals        *int   `json:"decimals"`
+	SupportsEIP3009 bool   `json:"supportsEip3009"`
+}
+
+// LoadNetworkConfigsFromFile loads a JSON network configuration file and merges it into NetworkConfigs
+func LoadNetworkConfigsFromFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open network config file: %w", err)
+	}
+	defer f.Close()
+
+	if err := LoadNetworkConfigs(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadNetworkConfigs parses a JSON network configuration document and merges it into NetworkConfigs.
+//
+// Networks not yet configured are added and require a chainId and at least one asset. Networks that
+// already exist keep their chain ID and built-in assets; assets in the document are added or replace
+// the entry with the same symbol, and defaultAsset (a symbol) switches the default. facilitatorContract
+// sets the network's facilitator contract address, overriding FacilitatorContractAddress. The whole document
+// is validated before anything is applied, so a bad entry leaves NetworkConfigs untouched.
+func LoadNetworkConfigs(r io.Reader) error {
+	var file networkConfigsFile
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return fmt.Errorf("failed to parse network configs: %w", err)
+	}
+
+	// Process networks in a stable order so errors are deterministic
+	networks := make([]string, 0, len(file.Networks))
+	for network := range file.Networks {
+		networks = append(networks, network)
+	}
+	sort.Strings(networks)
+
+	networkConfigsMu.Lock()
+	defer networkConfigsMu.Unlock()
+
+	merged := make(map[string]NetworkConfig, len(networks))
+	for _, network := range networks {
+		networkStr := NormalizeNetwork(network)
+		existing, exists := NetworkConfigs[networkStr]
+		if prev, ok := merged[networkStr]; ok {
+			existing, exists = prev, true
+		}
+
+		config, err := mergeNetworkConfig(networkStr, file.Networks[network], existing, exists)
+		if err != nil {
+			return fmt.Errorf("network %q: %w", network, err)
+		}
+		merged[networkStr] = config
+	}
+
+	for network, config := range merged {
+		NetworkConfigs[network] = config
+	}
+
+	return nil
+}
+
+// mergeNetworkConfig validates a single document entry and merges it over the existing config (if any)
+func mergeNetworkConfig(network string, entry networkConfigEntry, existing NetworkConfig, exists bool) (NetworkConfig, error) {
+	if !strings.HasPrefix(network, "eip155:") {
+		return NetworkConfig{}, fmt.Errorf("network must be a CAIP-2 eip155:<chainId> identifier")
+	}
+	keyChainID, ok := new(big.Int).SetString(strings.TrimPrefix(network, "eip155:"), 10)
+	if !ok || keyChainID.Sign() <= 0 {
+		return NetworkConfig{}, fmt.Errorf("invalid chain ID in network identifier")
+	}
+
+	if entry.ChainID != nil {
+		if *entry.ChainID <= 0 {
+			return NetworkConfig{}, fmt.Errorf("invalid chainId %d", *entry.ChainID)
+		}
+		if big.NewInt(*entry.ChainID).Cmp(keyChainID) != 0 {
+			return NetworkConfig{}, fmt.Errorf("chainId %d does not match network identifier", *entry.ChainID)
+		}
+	} else if !exists {
+		return NetworkConfig{}, fmt.Errorf("chainId is required for new networks")
+	}
+
+	config := NetworkConfig{
+		ChainID:             keyChainID,
+		DefaultAsset:        existing.DefaultAsset,
+		SupportedAssets:     make(map[string]AssetInfo, len(existing.SupportedAssets)+len(entry.Assets)),
+		FacilitatorContract: existing.FacilitatorContract,
+	}
+	if exists {
+		config.ChainID = existing.ChainID
+	}
+	for symbol, asset := range existing.SupportedAssets {
+		config.SupportedAssets[symbol] = asset
+	}
+
+	if entry.FacilitatorContract != "" {
+		if !IsValidAddress(entry.FacilitatorContract) {
+			return NetworkConfig{}, fmt.Errorf("invalid facilitatorContract %q", entry.FacilitatorContract)
+		}
+		config.FacilitatorContract = entry.FacilitatorContract
+	}
+
+	for symbol, asset := range entry.Assets {
+		info, err := asset.toAssetInfo()
+		if err != nil {
+			return NetworkConfig{}, fmt.Errorf("asset %q: %w", symbol, err)
+		}
+		config.SupportedAssets[strings.ToUpper(symbol)] = info
+	}
+
+	switch {
+	case entry.DefaultAsset != "":
+		asset, ok := config.SupportedAssets[strings.ToUpper(entry.DefaultAsset)]
+		if !ok {
+			return NetworkConfig{}, fmt.Errorf("defaultAsset %q is not a configured asset", entry.DefaultAsset)
+		}
+		config.DefaultAsset = asset
+	case !exists:
+		if len(entry.Assets) != 1 {
+			return NetworkConfig{}, fmt.Errorf("defaultAsset is required for new networks with %d assets", len(entry.Assets))
+		}
+		for _, asset := range config.SupportedAssets {
+			config.DefaultAsset = asset
+		}
+	}
+
+	return config, nil
+}
+
+func (a assetInfoEntry) toAssetInfo() (AssetInfo, error) {
+	if !IsValidAddress(a.Address) {
+		return AssetInfo{}, fmt.Errorf("invalid address %q", a.Address)
+	}
+	if a.Name == "" {
+		return AssetInfo{}, fmt.Errorf("name is required")
+	}
+	if a.Decimals == nil {
+		return AssetInfo{}, fmt.Errorf("decimals is required")
+	}
+	if *a.Decimals < 0 || *a.Decimals > 77 {
+		return AssetInfo{}, fmt.Errorf("invalid decimals %d", *a.Decimals)
+	}
+
+	version := a.Version
+	if version == "" {
+		version = "1"
+	}
+
+	return AssetInfo{
+		Address:         a.Address,
+		Name:            a.Name,
+		Version:         version,
+		Decimals:        *a.Decimals,
+		SupportsEIP3009: a.SupportsEIP3009,
+	}, nil
+}
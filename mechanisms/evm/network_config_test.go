@@ -0,0 +1,177 @@
+package evm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// restoreNetworkConfigs snapshots NetworkConfigs and restores it when the test ends
+func restoreNetworkConfigs(t *testing.T) {
+	t.Helper()
+
+	networkConfigsMu.RLock()
+	snapshot := make(map[string]NetworkConfig, len(NetworkConfigs))
+	for k, v := range NetworkConfigs {
+		snapshot[k] = v
+	}
+	networkConfigsMu.RUnlock()
+
+	t.Cleanup(func() {
+		networkConfigsMu.Lock()
+		NetworkConfigs = snapshot
+		networkConfigsMu.Unlock()
+	})
+}
+
+// TestLoadNetworkConfigs tests adding a new chain and merging assets into a built-in one
+func TestLoadNetworkConfigs(t *testing.T) {
+	restoreNetworkConfigs(t)
+
+	doc := `{
+		"networks": {
+			"eip155:43114": {
+				"chainId": 43114,
+				"facilitatorContract": "0x1111111111111111111111111111111111111111",
+				"assets": {
+					"usdc": {"address": "0xB97EF9Ef8734C71904D8002F8b6Bc66Dd9c48a6E", "name": "USD Coin", "version": "2", "decimals": 6, "supportsEip3009": true}
+				}
+			},
+			"base": {
+				"assets": {
+					"EURC": {"address": "0x60a3E35Cc302bFA44Cb288Bc5a4F316Fdb1adb42", "name": "EURC", "version": "2", "decimals": 6}
+				}
+			}
+		}
+	}`
+
+	if err := LoadNetworkConfigs(strings.NewReader(doc)); err != nil {
+		t.Fatalf("LoadNetworkConfigs error: %v", err)
+	}
+
+	if !IsValidNetwork("eip155:43114") {
+		t.Error("Expected loaded network to be valid")
+	}
+	config, err := GetNetworkConfig("eip155:43114")
+	if err != nil {
+		t.Fatalf("GetNetworkConfig error: %v", err)
+	}
+	if config.ChainID.Int64() != 43114 {
+		t.Errorf("Expected chain ID 43114, got %d", config.ChainID.Int64())
+	}
+	if config.DefaultAsset.Name != "USD Coin" || !config.DefaultAsset.SupportsEIP3009 {
+		t.Errorf("Unexpected default asset: %+v", config.DefaultAsset)
+	}
+	if got := GetFacilitatorContractAddress("eip155:43114"); got != "0x1111111111111111111111111111111111111111" {
+		t.Errorf("Expected the configured facilitator contract, got %s", got)
+	}
+
+	// Built-in Base assets must survive the merge
+	base, err := GetNetworkConfig("eip155:8453")
+	if err != nil {
+		t.Fatalf("GetNetworkConfig error: %v", err)
+	}
+	if _, ok := base.SupportedAssets["EURC"]; !ok {
+		t.Error("Expected EURC to be merged into Base")
+	}
+	if _, ok := base.SupportedAssets["USDC"]; !ok {
+		t.Error("Expected built-in USDC to be preserved on Base")
+	}
+	if base.DefaultAsset.Address != "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913" {
+		t.Errorf("Expected Base default asset to be unchanged, got %s", base.DefaultAsset.Address)
+	}
+	if got := GetFacilitatorContractAddress("base"); got != FacilitatorContractAddress {
+		t.Errorf("Expected Base to fall back to the default facilitator contract, got %s", got)
+	}
+}
+
+// TestLoadNetworkConfigsValidation tests that invalid entries are rejected with the offending entry named
+func TestLoadNetworkConfigsValidation(t *testing.T) {
+	restoreNetworkConfigs(t)
+
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr string
+	}{
+		{
+			name:    "missing chainId",
+			doc:     `{"networks": {"eip155:43114": {"assets": {"USDC": {"address": "0xB97EF9Ef8734C71904D8002F8b6Bc66Dd9c48a6E", "name": "USD Coin", "decimals": 6}}}}}`,
+			wantErr: `network "eip155:43114": chainId is required`,
+		},
+		{
+			name:    "chainId mismatch",
+			doc:     `{"networks": {"eip155:43114": {"chainId": 1, "assets": {"USDC": {"address": "0xB97EF9Ef8734C71904D8002F8b6Bc66Dd9c48a6E", "name": "USD Coin", "decimals": 6}}}}}`,
+			wantErr: "chainId 1 does not match",
+		},
+		{
+			name:    "invalid address",
+			doc:     `{"networks": {"eip155:43114": {"chainId": 43114, "assets": {"USDC": {"address": "0x1234", "name": "USD Coin", "decimals": 6}}}}}`,
+			wantErr: `asset "USDC": invalid address "0x1234"`,
+		},
+		{
+			name:    "missing decimals",
+			doc:     `{"networks": {"eip155:43114": {"chainId": 43114, "assets": {"USDC": {"address": "0xB97EF9Ef8734C71904D8002F8b6Bc66Dd9c48a6E", "name": "USD Coin"}}}}}`,
+			wantErr: "decimals is required",
+		},
+		{
+			name:    "invalid facilitator contract",
+			doc:     `{"networks": {"eip155:8453": {"facilitatorContract": "0x1234"}}}`,
+			wantErr: `invalid facilitatorContract "0x1234"`,
+		},
+		{
+			name:    "unknown default asset",
+			doc:     `{"networks": {"eip155:8453": {"defaultAsset": "EURC"}}}`,
+			wantErr: `defaultAsset "EURC" is not a configured asset`,
+		},
+		{
+			name:    "non-EVM network",
+			doc:     `{"networks": {"solana:mainnet": {"chainId": 1}}}`,
+			wantErr: "eip155",
+		},
+		{
+			name:    "unknown field",
+			doc:     `{"networks": {"eip155:43114": {"chain": 43114}}}`,
+			wantErr: "failed to parse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadNetworkConfigs(strings.NewReader(tt.doc))
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantErr, err.Error())
+			}
+		})
+	}
+
+	if _, err := GetNetworkConfig("eip155:43114"); err == nil {
+		t.Error("Expected failed loads to leave NetworkConfigs untouched")
+	}
+}
+
+// TestLoadNetworkConfigsFromFile tests loading from disk
+func TestLoadNetworkConfigsFromFile(t *testing.T) {
+	restoreNetworkConfigs(t)
+
+	path := filepath.Join(t.TempDir(), "networks.json")
+	doc := `{"networks": {"eip155:43113": {"chainId": 43113, "assets": {"USDC": {"address": "0x5425890298aed601595a70AB815c96711a31Bc65", "name": "USD Coin", "version": "2", "decimals": 6}}}}}`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatalf("WriteFile error: %v", err)
+	}
+
+	if err := LoadNetworkConfigsFromFile(path); err != nil {
+		t.Fatalf("LoadNetworkConfigsFromFile error: %v", err)
+	}
+	if _, err := GetAssetInfo("eip155:43113", "0x5425890298aed601595a70AB815c96711a31Bc65"); err != nil {
+		t.Errorf("GetAssetInfo error: %v", err)
+	}
+
+	if err := LoadNetworkConfigsFromFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
@@ -0,0 +1,93 @@
+package evm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"x402-go/types"
+)
+
+// ErrUnknownPayloadType is returned by UnmarshalPayload for a "type" it doesn't know
+var ErrUnknownPayloadType = errors.New("unknown payload type")
+
+// ExactEvmPayloadEnvelope is an exact EVM payment payload decoded according to its "type".
+//
+// EIP3009 is set for PayloadTypeEIP3009 and PayloadTypeEIP3009Receive payloads and ERC20 for PayloadTypeAuthorization and
+// PayloadTypePermit payloads. Payloads from clients that don't send a type leave Type empty
+// and set both, so the caller can pick the flow (e.g. from the token's EIP-3009 support).
+type ExactEvmPayloadEnvelope struct {
+	Type    string
+	EIP3009 *ExactEIP3009Payload
+	ERC20   *ExactERC20Payload
+}
+
+// UnmarshalPayload decodes the exact EVM payload carried by a V2 payment payload
+func UnmarshalPayload(payload types.PaymentPayload) (*ExactEvmPayloadEnvelope, error) {
+	var discriminator struct {
+		Type string `json:"type"`
+	}
+	if err := decodePayloadMap(payload.Payload, &discriminator); err != nil {
+		return nil, err
+	}
+
+	envelope := &ExactEvmPayloadEnvelope{Type: discriminator.Type}
+	var err error
+	switch discriminator.Type {
+	case PayloadTypeEIP3009, PayloadTypeEIP3009Receive:
+		envelope.EIP3009, err = PayloadFromMap(payload.Payload)
+	case PayloadTypeAuthorization, PayloadTypePermit:
+		envelope.ERC20, err = PayloadERC20FromMap(payload.Payload)
+		if err == nil && discriminator.Type == PayloadTypePermit && envelope.ERC20.Permit == nil {
+			err = errors.New("permit payload is missing its permit")
+		}
+	case "":
+		if envelope.EIP3009, err = PayloadFromMap(payload.Payload); err == nil {
+			envelope.ERC20, err = PayloadERC20FromMap(payload.Payload)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPayloadType, discriminator.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return envelope, nil
+}
+
+// Signature returns the authorization signature
+func (e *ExactEvmPayloadEnvelope) Signature() string {
+	if e.EIP3009 != nil {
+		return e.EIP3009.Signature
+	}
+	return e.ERC20.Signature
+}
+
+// Authorization returns the transfer fields every payload type carries
+func (e *ExactEvmPayloadEnvelope) Authorization() ExactEIP3009Authorization {
+	if e.EIP3009 != nil {
+		return e.EIP3009.Authorization
+	}
+	auth := e.ERC20.Authorization
+	return ExactEIP3009Authorization{
+		From:        auth.From,
+		To:          auth.To,
+		Value:       auth.Value,
+		ValidAfter:  auth.ValidAfter,
+		ValidBefore: auth.ValidBefore,
+		Nonce:       auth.Nonce,
+	}
+}
+
+// decodePayloadMap decodes a payload map into v through its JSON form, so fields of the
+// wrong type are reported instead of silently dropped
+func decodePayloadMap(data map[string]interface{}, v interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	return nil
+}
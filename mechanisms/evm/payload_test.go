@@ -0,0 +1,121 @@
+package evm
+
+import (
+	"errors"
+	"testing"
+
+	"x402-go/types"
+)
+
+// TestUnmarshalPayload tests that payloads decode according to their type
+func TestUnmarshalPayload(t *testing.T) {
+	authorization := map[string]interface{}{
+		"token":       "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+		"from":        "0x1111111111111111111111111111111111111111",
+		"to":          "0x2222222222222222222222222222222222222222",
+		"value":       "1000",
+		"validAfter":  "0",
+		"validBefore": "9999999999",
+		"nonce":       "0x01",
+	}
+	permit := map[string]interface{}{
+		"owner":     "0x1111111111111111111111111111111111111111",
+		"spender":   FacilitatorContractAddress,
+		"value":     "1000",
+		"nonce":     "0",
+		"deadline":  "9999999999",
+		"signature": "0xpermit",
+	}
+
+	tests := []struct {
+		name        string
+		payload     map[string]interface{}
+		wantType    string
+		wantEIP3009 bool
+		wantERC20   bool
+		wantErr     bool
+		wantUnknown bool
+	}{
+		{
+			name:        "eip3009",
+			payload:     map[string]interface{}{"type": PayloadTypeEIP3009, "signature": "0xsig", "authorization": authorization},
+			wantType:    PayloadTypeEIP3009,
+			wantEIP3009: true,
+		},
+		{
+			name:        "eip3009 receive",
+			payload:     map[string]interface{}{"type": PayloadTypeEIP3009Receive, "signature": "0xsig", "authorization": authorization},
+			wantType:    PayloadTypeEIP3009Receive,
+			wantEIP3009: true,
+		},
+		{
+			name:      "erc20 authorization",
+			payload:   map[string]interface{}{"type": PayloadTypeAuthorization, "signature": "0xsig", "authorization": authorization},
+			wantType:  PayloadTypeAuthorization,
+			wantERC20: true,
+		},
+		{
+			name:      "permit",
+			payload:   map[string]interface{}{"type": PayloadTypePermit, "signature": "0xsig", "authorization": authorization, "permit": permit},
+			wantType:  PayloadTypePermit,
+			wantERC20: true,
+		},
+		{
+			name:        "untyped legacy payload",
+			payload:     map[string]interface{}{"signature": "0xsig", "authorization": authorization},
+			wantEIP3009: true,
+			wantERC20:   true,
+		},
+		{
+			name:    "permit without permit",
+			payload: map[string]interface{}{"type": PayloadTypePermit, "signature": "0xsig", "authorization": authorization},
+			wantErr: true,
+		},
+		{
+			name:        "unknown type",
+			payload:     map[string]interface{}{"type": "somethingElse", "signature": "0xsig", "authorization": authorization},
+			wantErr:     true,
+			wantUnknown: true,
+		},
+		{
+			name:    "mistyped field",
+			payload: map[string]interface{}{"type": PayloadTypeEIP3009, "signature": "0xsig", "authorization": map[string]interface{}{"value": 1000}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope, err := UnmarshalPayload(types.PaymentPayload{X402Version: 2, Payload: tt.payload})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				if errors.Is(err, ErrUnknownPayloadType) != tt.wantUnknown {
+					t.Errorf("errors.Is(err, ErrUnknownPayloadType) = %v, want %v", !tt.wantUnknown, tt.wantUnknown)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if envelope.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", envelope.Type, tt.wantType)
+			}
+			if (envelope.EIP3009 != nil) != tt.wantEIP3009 || (envelope.ERC20 != nil) != tt.wantERC20 {
+				t.Errorf("EIP3009 set = %v, ERC20 set = %v", envelope.EIP3009 != nil, envelope.ERC20 != nil)
+			}
+			if envelope.Signature() != "0xsig" {
+				t.Errorf("Signature() = %q", envelope.Signature())
+			}
+			auth := envelope.Authorization()
+			if auth.From != authorization["from"] || auth.Value != "1000" || auth.Nonce != "0x01" {
+				t.Errorf("Unexpected authorization: %+v", auth)
+			}
+			if tt.wantType == PayloadTypePermit && envelope.ERC20.Permit.Signature != "0xpermit" {
+				t.Errorf("Unexpected permit: %+v", envelope.ERC20.Permit)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PriceConverter converts a dollar price into an amount of an asset's smallest unit.
+//
+// Servers use it to price assets that aren't pegged to the dollar. Without one, a dollar is
+// converted 1:1 into the asset (e.g. $0.10 becomes 100000 of a 6-decimal stablecoin).
+type PriceConverter interface {
+	Convert(ctx context.Context, usd float64, asset AssetInfo, network string) (*big.Int, error)
+}
+
+var (
+	// ErrNoPriceFeed is returned when no price feed is configured for an asset
+	ErrNoPriceFeed = errors.New("no price feed configured for asset")
+
+	// ErrStalePrice is returned when a price feed answer is older than the allowed age
+	ErrStalePrice = errors.New("price feed answer is stale")
+
+	// ErrInvalidPrice is returned when a price feed answer isn't a positive price
+	ErrInvalidPrice = errors.New("price feed answer is not a positive price")
+)
+
+// ChainlinkPriceConverter is a PriceConverter reading USD prices from Chainlink aggregators
+// (AggregatorV3Interface), one per asset. Register feeds with AddFeed before use.
+type ChainlinkPriceConverter struct {
+	reader ContractReader
+	feeds  map[string]string // network|asset -> aggregator address
+	maxAge time.Duration
+	now    func() time.Time // overridable in tests
+}
+
+// NewChainlinkPriceConverter creates a converter that reads aggregators through reader
+// (any signer with RPC access). Answers older than DefaultPriceFeedMaxAge are rejected.
+func NewChainlinkPriceConverter(reader ContractReader) *ChainlinkPriceConverter {
+	return &ChainlinkPriceConverter{
+		reader: reader,
+		feeds:  make(map[string]string),
+		maxAge: DefaultPriceFeedMaxAge,
+		now:    time.Now,
+	}
+}
+
+// AddFeed sets the aggregator reporting the USD price of asset (its address) on network
+func (c *ChainlinkPriceConverter) AddFeed(network, asset, aggregator string) *ChainlinkPriceConverter {
+	c.feeds[priceFeedKey(network, asset)] = aggregator
+	return c
+}
+
+// SetMaxAge sets how old an aggregator answer may be. A maxAge <= 0 accepts any age.
+func (c *ChainlinkPriceConverter) SetMaxAge(maxAge time.Duration) *ChainlinkPriceConverter {
+	c.maxAge = maxAge
+	return c
+}
+
+// Convert returns the amount of asset worth usd at the aggregator's latest answer, rounded up
+// so the payment never falls short of the price
+func (c *ChainlinkPriceConverter) Convert(ctx context.Context, usd float64, asset AssetInfo, network string) (*big.Int, error) {
+	aggregator, ok := c.feeds[priceFeedKey(network, asset.Address)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s on %s", ErrNoPriceFeed, asset.Address, network)
+	}
+
+	usdAmount, ok := new(big.Rat).SetString(strconv.FormatFloat(usd, 'f', -1, 64))
+	if !ok || usdAmount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid usd amount: %v", usd)
+	}
+
+	answer, feedDecimals, err := c.latestAnswer(ctx, aggregator)
+	if err != nil {
+		return nil, err
+	}
+
+	// units = usd * 10^feedDecimals * 10^assetDecimals / answer
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(feedDecimals+asset.Decimals)), nil)
+	units := new(big.Rat).Mul(usdAmount, new(big.Rat).SetInt(scale))
+	units.Quo(units, new(big.Rat).SetInt(answer))
+
+	amount, remainder := new(big.Int).QuoRem(units.Num(), units.Denom(), new(big.Int))
+	if remainder.Sign() > 0 {
+		amount.Add(amount, big.NewInt(1))
+	}
+	return amount, nil
+}
+
+// latestAnswer reads the aggregator's latest price and its decimals
+func (c *ChainlinkPriceConverter) latestAnswer(ctx context.Context, aggregator string) (*big.Int, int, error) {
+	decimalsResult, err := c.reader.ReadContract(ctx, aggregator, ChainlinkAggregatorABI, FunctionDecimals)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read price feed decimals: %w", err)
+	}
+	feedDecimals, ok := decimalsResult.(uint8)
+	if !ok {
+		return nil, 0, fmt.Errorf("unexpected price feed decimals type: %T", decimalsResult)
+	}
+
+	roundResult, err := c.reader.ReadContract(ctx, aggregator, ChainlinkAggregatorABI, FunctionLatestRoundData)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read price feed: %w", err)
+	}
+	round, ok := roundResult.([]interface{})
+	if !ok || len(round) != 5 {
+		return nil, 0, fmt.Errorf("unexpected price feed round type: %T", roundResult)
+	}
+	answer, ok := round[1].(*big.Int)
+	if !ok {
+		return nil, 0, fmt.Errorf("unexpected price feed answer type: %T", round[1])
+	}
+	updatedAt, ok := round[3].(*big.Int)
+	if !ok {
+		return nil, 0, fmt.Errorf("unexpected price feed timestamp type: %T", round[3])
+	}
+
+	if answer.Sign() <= 0 {
+		return nil, 0, fmt.Errorf("%w: %s", ErrInvalidPrice, answer)
+	}
+	if c.maxAge > 0 && c.now().Sub(time.Unix(updatedAt.Int64(), 0)) > c.maxAge {
+		return nil, 0, fmt.Errorf("%w: updated at %s", ErrStalePrice, time.Unix(updatedAt.Int64(), 0).UTC().Format(time.RFC3339))
+	}
+
+	return answer, int(feedDecimals), nil
+}
+
+// priceFeedKey identifies an asset's feed; networks and addresses compare case-insensitively
+func priceFeedKey(network, asset string) string {
+	return strings.ToLower(network + "|" + asset)
+}
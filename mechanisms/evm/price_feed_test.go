@@ -0,0 +1,98 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// fakeAggregator answers Chainlink aggregator reads with a fixed round
+type fakeAggregator struct {
+	decimals  uint8
+	answer    *big.Int
+	updatedAt time.Time
+}
+
+func (a *fakeAggregator) ReadContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (interface{}, error) {
+	switch functionName {
+	case FunctionDecimals:
+		return a.decimals, nil
+	case FunctionLatestRoundData:
+		return []interface{}{big.NewInt(1), a.answer, big.NewInt(a.updatedAt.Unix()), big.NewInt(a.updatedAt.Unix()), big.NewInt(1)}, nil
+	}
+	return nil, errors.New("unexpected function " + functionName)
+}
+
+func TestChainlinkPriceConverter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	weth := AssetInfo{Address: "0x4200000000000000000000000000000000000006", Decimals: 18}
+
+	tests := []struct {
+		name       string
+		usd        float64
+		aggregator *fakeAggregator
+		asset      AssetInfo
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "volatile token",
+			usd:        0.10,
+			aggregator: &fakeAggregator{decimals: 8, answer: big.NewInt(2000_00000000), updatedAt: now},
+			asset:      weth,
+			want:       "50000000000000", // $0.10 / $2000 = 0.00005 WETH
+		},
+		{
+			name:       "rounds up",
+			usd:        1,
+			aggregator: &fakeAggregator{decimals: 8, answer: big.NewInt(3_00000000), updatedAt: now},
+			asset:      AssetInfo{Address: weth.Address, Decimals: 6},
+			want:       "333334",
+		},
+		{
+			name:       "stale answer",
+			usd:        1,
+			aggregator: &fakeAggregator{decimals: 8, answer: big.NewInt(2000_00000000), updatedAt: now.Add(-2 * DefaultPriceFeedMaxAge)},
+			asset:      weth,
+			wantErr:    ErrStalePrice,
+		},
+		{
+			name:       "non-positive answer",
+			usd:        1,
+			aggregator: &fakeAggregator{decimals: 8, answer: big.NewInt(0), updatedAt: now},
+			asset:      weth,
+			wantErr:    ErrInvalidPrice,
+		},
+		{
+			name:       "no feed",
+			usd:        1,
+			aggregator: &fakeAggregator{decimals: 8, answer: big.NewInt(1), updatedAt: now},
+			asset:      AssetInfo{Address: "0x0000000000000000000000000000000000000001", Decimals: 18},
+			wantErr:    ErrNoPriceFeed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := NewChainlinkPriceConverter(tt.aggregator).
+				AddFeed("eip155:8453", weth.Address, "0x71041dddad3595F9CEd3DcCFBe3D1F4b0a16Bb70")
+			converter.now = func() time.Time { return now }
+
+			amount, err := converter.Convert(context.Background(), tt.usd, tt.asset, "eip155:8453")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if amount.String() != tt.want {
+				t.Errorf("Convert() = %s, want %s", amount, tt.want)
+			}
+		})
+	}
+}
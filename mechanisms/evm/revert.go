@@ -0,0 +1,106 @@
+package evm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// RevertError is a contract call or gas estimation that reverted, with its revert data decoded
+type RevertError struct {
+	// Reason is the Error(string) message, the Panic(uint256) description, or a custom error
+	// matched against the call's ABI as "Name(arg1, arg2)". Empty when the data isn't recognized.
+	Reason string
+
+	// Data is the raw revert data (empty when the node didn't return any)
+	Data []byte
+
+	err error
+}
+
+func (e *RevertError) Error() string {
+	switch {
+	case e.Reason != "":
+		return "execution reverted: " + e.Reason
+	case len(e.Data) > 0:
+		return fmt.Sprintf("execution reverted: 0x%x", e.Data)
+	default:
+		return "execution reverted"
+	}
+}
+
+// Unwrap returns the underlying RPC error
+func (e *RevertError) Unwrap() error {
+	return e.err
+}
+
+// DecodeRevertError converts a revert returned by a contract call into a *RevertError, matching
+// custom errors against abiJSON (which may be nil). Errors that aren't reverts are returned unchanged.
+func DecodeRevertError(err error, abiJSON []byte) error {
+	if err == nil || !isRevertError(err) {
+		return err
+	}
+
+	var revertErr *RevertError
+	if errors.As(err, &revertErr) {
+		if revertErr.Reason != "" || len(abiJSON) == 0 {
+			return err
+		}
+		// Decoded without an ABI so far; try the custom errors of this one
+		return &RevertError{Reason: DecodeRevertReason(revertErr.Data, abiJSON), Data: revertErr.Data, err: revertErr.err}
+	}
+
+	data := revertData(err)
+	return &RevertError{Reason: DecodeRevertReason(data, abiJSON), Data: data, err: err}
+}
+
+// DecodeRevertReason decodes revert data: Error(string) and Panic(uint256) reverts, and custom
+// errors declared in abiJSON (which may be nil). Returns "" when the data isn't recognized.
+func DecodeRevertReason(data []byte, abiJSON []byte) string {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	if len(data) < 4 || len(abiJSON) == 0 {
+		return ""
+	}
+
+	parsedABI, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return ""
+	}
+	for _, abiErr := range parsedABI.Errors {
+		if !bytes.Equal(abiErr.ID[:4], data[:4]) {
+			continue
+		}
+		values, err := abiErr.Inputs.Unpack(data[4:])
+		if err != nil {
+			return abiErr.Name
+		}
+		args := make([]string, len(values))
+		for i, value := range values {
+			args[i] = fmt.Sprint(value)
+		}
+		return abiErr.Name + "(" + strings.Join(args, ", ") + ")"
+	}
+	return ""
+}
+
+// revertData extracts the revert data carried by a go-ethereum RPC error
+func revertData(err error) []byte {
+	var dataErr revertDataError
+	if !errors.As(err, &dataErr) {
+		return nil
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil
+	}
+	data, err := HexToBytes(hexData)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,116 @@
+package evm
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// rpcDataError mimics a go-ethereum RPC error carrying revert data
+type rpcDataError struct {
+	data string
+}
+
+func (e *rpcDataError) Error() string          { return "execution reverted" }
+func (e *rpcDataError) ErrorData() interface{} { return e.data }
+
+var customErrorABI = []byte(`[
+	{
+		"type": "error",
+		"name": "InsufficientBalance",
+		"inputs": [
+			{"name": "available", "type": "uint256"},
+			{"name": "required", "type": "uint256"}
+		]
+	}
+]`)
+
+// errorStringData encodes Error("nope")
+const errorStringData = "0x08c379a0" +
+	"0000000000000000000000000000000000000000000000000000000000000020" +
+	"0000000000000000000000000000000000000000000000000000000000000004" +
+	"6e6f706500000000000000000000000000000000000000000000000000000000"
+
+func customErrorData() string {
+	selector := crypto.Keccak256([]byte("InsufficientBalance(uint256,uint256)"))[:4]
+	available := common.LeftPadBytes(big.NewInt(5).Bytes(), 32)
+	required := common.LeftPadBytes(big.NewInt(10).Bytes(), 32)
+	return "0x" + hex.EncodeToString(append(append(selector, available...), required...))
+}
+
+func TestDecodeRevertError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		abi        []byte
+		wantReason string
+		wantData   bool
+	}{
+		{
+			name:       "error string",
+			err:        &rpcDataError{data: errorStringData},
+			wantReason: "nope",
+			wantData:   true,
+		},
+		{
+			name:       "custom error",
+			err:        &rpcDataError{data: customErrorData()},
+			abi:        customErrorABI,
+			wantReason: "InsufficientBalance(5, 10)",
+			wantData:   true,
+		},
+		{
+			name:     "custom error without its abi",
+			err:      &rpcDataError{data: customErrorData()},
+			wantData: true,
+		},
+		{
+			name: "bare revert",
+			err:  errors.New("execution reverted"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeRevertError(tt.err, tt.abi)
+
+			var revertErr *RevertError
+			if !errors.As(err, &revertErr) {
+				t.Fatalf("Expected a RevertError, got %T: %v", err, err)
+			}
+			if revertErr.Reason != tt.wantReason {
+				t.Errorf("Reason = %q, want %q", revertErr.Reason, tt.wantReason)
+			}
+			if (len(revertErr.Data) > 0) != tt.wantData {
+				t.Errorf("Data = %x", revertErr.Data)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Error("Expected the RPC error to stay in the chain")
+			}
+		})
+	}
+}
+
+func TestDecodeRevertErrorPassesThroughOtherErrors(t *testing.T) {
+	rpcErr := errors.New("connection refused")
+	if err := DecodeRevertError(rpcErr, nil); err != rpcErr {
+		t.Errorf("Expected the error unchanged, got %v", err)
+	}
+	if DecodeRevertError(nil, nil) != nil {
+		t.Error("Expected nil for a nil error")
+	}
+}
+
+func TestDecodeRevertErrorRedecodesWithABI(t *testing.T) {
+	err := DecodeRevertError(&rpcDataError{data: customErrorData()}, nil)
+	err = DecodeRevertError(err, customErrorABI)
+
+	var revertErr *RevertError
+	if !errors.As(err, &revertErr) || revertErr.Reason != "InsufficientBalance(5, 10)" {
+		t.Errorf("Expected the custom error to be decoded, got %v", err)
+	}
+}
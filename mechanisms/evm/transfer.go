@@ -0,0 +1,67 @@
+package evm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC20Transfer is an ERC-20 Transfer event emitted by a mined transaction
+type ERC20Transfer struct {
+	Token    string // Token contract that emitted the event
+	From     string
+	To       string
+	Value    *big.Int
+	LogIndex uint
+}
+
+// ERC20Transfers decodes the ERC-20 Transfer events in receipt's logs, in log order.
+// Logs with the Transfer topic but another layout (e.g. ERC-721 transfers, whose token ID
+// is indexed) are skipped.
+func ERC20Transfers(receipt *TransactionReceipt) []ERC20Transfer {
+	if receipt == nil {
+		return nil
+	}
+
+	var transfers []ERC20Transfer
+	for _, log := range receipt.Logs {
+		if len(log.Topics) != 3 || !strings.EqualFold(log.Topics[0], ERC20TransferEventTopic) || len(log.Data) != 32 {
+			continue
+		}
+		transfers = append(transfers, ERC20Transfer{
+			Token:    common.HexToAddress(log.Address).Hex(),
+			From:     common.BytesToAddress(common.HexToHash(log.Topics[1]).Bytes()).Hex(),
+			To:       common.BytesToAddress(common.HexToHash(log.Topics[2]).Bytes()).Hex(),
+			Value:    new(big.Int).SetBytes(log.Data),
+			LogIndex: log.Index,
+		})
+	}
+	return transfers
+}
+
+// ErrSettlementEventMismatch is returned by FindSettlementTransfer when the receipt has no
+// matching transfer
+var ErrSettlementEventMismatch = errors.New("settlement emitted no matching transfer event")
+
+// FindSettlementTransfer returns the first ERC-20 Transfer in receipt that moved value of token
+// to recipient, skipping the log indexes in exclude (transfers already matched to other
+// payments of a batch). A receipt with nil Logs comes from a signer that doesn't report logs,
+// so nothing can be checked: it returns nil, nil.
+func FindSettlementTransfer(receipt *TransactionReceipt, token, recipient string, value *big.Int, exclude map[uint]bool) (*ERC20Transfer, error) {
+	if receipt == nil || receipt.Logs == nil {
+		return nil, nil
+	}
+
+	for _, transfer := range ERC20Transfers(receipt) {
+		if exclude[transfer.LogIndex] {
+			continue
+		}
+		if strings.EqualFold(transfer.Token, token) && strings.EqualFold(transfer.To, recipient) && transfer.Value.Cmp(value) == 0 {
+			return &transfer, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: expected %s of %s to %s", ErrSettlementEventMismatch, value, token, recipient)
+}
@@ -0,0 +1,105 @@
+package evm
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestERC20TransferEventTopic(t *testing.T) {
+	topic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")).Hex()
+	if topic != ERC20TransferEventTopic {
+		t.Errorf("Expected %s, got %s", topic, ERC20TransferEventTopic)
+	}
+}
+
+func TestERC20Transfers(t *testing.T) {
+	token := common.HexToAddress("0x036CbD53842c5426634e7929541eC2318f3dCF7e")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	topic := func(address common.Address) string {
+		return common.BytesToHash(address.Bytes()).Hex()
+	}
+
+	receipt := &TransactionReceipt{
+		Status: TxStatusSuccess,
+		Logs: []ReceiptLog{
+			// ERC-20 transfer
+			{
+				Address: token.Hex(),
+				Topics:  []string{ERC20TransferEventTopic, topic(from), topic(to)},
+				Data:    common.LeftPadBytes(big.NewInt(1000000).Bytes(), 32),
+				Index:   3,
+			},
+			// ERC-721 transfer: the token ID is indexed
+			{
+				Address: token.Hex(),
+				Topics:  []string{ERC20TransferEventTopic, topic(from), topic(to), common.BigToHash(big.NewInt(7)).Hex()},
+				Index:   4,
+			},
+			// Another event
+			{
+				Address: token.Hex(),
+				Topics:  []string{common.BytesToHash([]byte("other")).Hex()},
+				Data:    make([]byte, 32),
+				Index:   5,
+			},
+		},
+	}
+
+	transfers := ERC20Transfers(receipt)
+	if len(transfers) != 1 {
+		t.Fatalf("Expected 1 transfer, got %d", len(transfers))
+	}
+	transfer := transfers[0]
+	if transfer.Token != token.Hex() || transfer.From != from.Hex() || transfer.To != to.Hex() {
+		t.Errorf("Unexpected addresses: %+v", transfer)
+	}
+	if transfer.Value.Cmp(big.NewInt(1000000)) != 0 || transfer.LogIndex != 3 {
+		t.Errorf("Unexpected value or index: %+v", transfer)
+	}
+
+	if ERC20Transfers(nil) != nil {
+		t.Error("Expected no transfers for a nil receipt")
+	}
+}
+
+func TestFindSettlementTransfer(t *testing.T) {
+	token := "0x036CbD53842c5426634e7929541eC2318f3dCF7e"
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	log := func(index uint) ReceiptLog {
+		return ReceiptLog{
+			Address: token,
+			Topics:  []string{ERC20TransferEventTopic, common.Hash{}.Hex(), common.BytesToHash(to.Bytes()).Hex()},
+			Data:    common.LeftPadBytes(big.NewInt(1000000).Bytes(), 32),
+			Index:   index,
+		}
+	}
+	receipt := &TransactionReceipt{Status: TxStatusSuccess, Logs: []ReceiptLog{log(1), log(2)}}
+
+	// Lowercase addresses match too
+	transfer, err := FindSettlementTransfer(receipt, strings.ToLower(token), strings.ToLower(to.Hex()), big.NewInt(1000000), nil)
+	if err != nil || transfer == nil || transfer.LogIndex != 1 {
+		t.Fatalf("Expected the first transfer, got %+v, %v", transfer, err)
+	}
+
+	// Identical payments of a batch match separate events
+	transfer, err = FindSettlementTransfer(receipt, token, to.Hex(), big.NewInt(1000000), map[uint]bool{1: true})
+	if err != nil || transfer == nil || transfer.LogIndex != 2 {
+		t.Fatalf("Expected the second transfer, got %+v, %v", transfer, err)
+	}
+
+	if _, err := FindSettlementTransfer(receipt, token, to.Hex(), big.NewInt(999999), nil); !errors.Is(err, ErrSettlementEventMismatch) {
+		t.Errorf("Expected ErrSettlementEventMismatch, got %v", err)
+	}
+
+	// Signers that don't report logs can't be checked
+	transfer, err = FindSettlementTransfer(&TransactionReceipt{Status: TxStatusSuccess}, token, to.Hex(), big.NewInt(1), nil)
+	if transfer != nil || err != nil {
+		t.Errorf("Expected nothing to check, got %+v, %v", transfer, err)
+	}
+}
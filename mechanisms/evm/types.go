@@ -2,7 +2,10 @@ package evm
 
 import (
 	"context"
+	"errors"
 	"math/big"
+
+	x402 "x402-go"
 )
 
 // ExactEIP3009Authorization represents the EIP-3009 TransferWithAuthorization data
@@ -43,6 +46,28 @@ type ExactERC20Authorization struct {
 type ExactERC20Payload struct {
 	Signature     string                  `json:"signature,omitempty"`
 	Authorization ExactERC20Authorization `json:"authorization"`
+	Permit        *ExactPermit            `json:"permit,omitempty"` // Set for the EIP-2612 permit flow
+}
+
+// ExactPermit represents an EIP-2612 permit granting the facilitator contract an allowance
+type ExactPermit struct {
+	Owner     string `json:"owner"`     // Token holder address (hex)
+	Spender   string `json:"spender"`   // Facilitator contract address (hex)
+	Value     string `json:"value"`     // Allowance in wei as string
+	Nonce     string `json:"nonce"`     // Token permit nonce as decimal string
+	Deadline  string `json:"deadline"`  // Unix timestamp as string
+	Signature string `json:"signature"` // 65-byte permit signature as hex
+}
+
+// ExactEIP3009Cancellation is a signed EIP-3009 CancelAuthorization. Submitted to the token's
+// cancelAuthorization, by the authorizer or relayed by a facilitator, it invalidates the
+// authorization with the same nonce so it can no longer be settled.
+type ExactEIP3009Cancellation struct {
+	Network    string `json:"network"`    // CAIP-2 network of the token
+	Token      string `json:"token"`      // Token address (hex)
+	Authorizer string `json:"authorizer"` // Address that signed the authorization (hex)
+	Nonce      string `json:"nonce"`      // 32-byte nonce of the authorization as hex string
+	Signature  string `json:"signature"`  // CancelAuthorization signature as hex
 }
 
 // ToMap converts an ExactERC20Payload to a map for JSON marshaling
@@ -62,6 +87,16 @@ func (p *ExactERC20Payload) ToMap() map[string]interface{} {
 	if p.Signature != "" {
 		result["signature"] = p.Signature
 	}
+	if p.Permit != nil {
+		result["permit"] = map[string]interface{}{
+			"owner":     p.Permit.Owner,
+			"spender":   p.Permit.Spender,
+			"value":     p.Permit.Value,
+			"nonce":     p.Permit.Nonce,
+			"deadline":  p.Permit.Deadline,
+			"signature": p.Permit.Signature,
+		}
+	}
 	return result
 }
 
@@ -91,6 +126,21 @@ type ClientEvmSigner interface {
 	WaitForTransactionReceipt(ctx context.Context, txHash string) (*TransactionReceipt, error)
 }
 
+// SmartAccountClientSigner is a ClientEvmSigner paying from a smart contract account (e.g. Safe,
+// Coinbase Smart Wallet) rather than from the key that signs for it.
+//
+// The client scheme uses AccountAddress as the payer. SignTypedData must return a signature the
+// account's EIP-1271 isValidSignature accepts. While the account is counterfactual (not yet
+// deployed), the signature must be wrapped in ERC-6492 with the factory and calldata that deploy
+// it, so the facilitator can deploy the account before settling. WriteContract must execute
+// calls from the account, since token approvals are made by the payer.
+type SmartAccountClientSigner interface {
+	ClientEvmSigner
+
+	// AccountAddress returns the smart account's address
+	AccountAddress() string
+}
+
 // FacilitatorEvmSigner defines the interface for facilitator EVM operations
 // Supports multiple addresses for load balancing, key rotation, and high availability
 type FacilitatorEvmSigner interface {
@@ -125,6 +175,37 @@ type FacilitatorEvmSigner interface {
 	GetCode(ctx context.Context, address string) ([]byte, error)
 }
 
+// BlockTimeReader is an optional interface for facilitator signers that can report chain time.
+// When implemented, validity windows are checked against the latest block timestamp
+// instead of the facilitator's local clock.
+type BlockTimeReader interface {
+	// GetBlockTimestamp returns the timestamp (unix seconds) of the latest block
+	GetBlockTimestamp(ctx context.Context) (uint64, error)
+}
+
+// ChainIDReader is an optional interface for contract readers that can report their chain.
+// When implemented, token metadata for networks missing from NetworkConfigs is only read
+// (and cached) if the reader is connected to the requested chain.
+type ChainIDReader interface {
+	// GetChainID returns the chain ID of the connected network
+	GetChainID(ctx context.Context) (*big.Int, error)
+}
+
+// ContractSimulator is an optional interface for facilitator signers that can dry-run a
+// contract call. The exact scheme's SimulateSettle requires it.
+type ContractSimulator interface {
+	// SimulateContract runs the call WriteContract would send as an eth_call, without
+	// broadcasting anything. A call that would revert returns a *RevertError.
+	SimulateContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) error
+}
+
+// NonceResetter is an optional interface for signers that track transaction nonces locally.
+// ResetNonce drops the cached nonces so the next transaction resyncs from the chain,
+// e.g. after a transaction is known to have been dropped from the mempool.
+type NonceResetter interface {
+	ResetNonce()
+}
+
 // TypedDataDomain represents the EIP-712 domain separator
 type TypedDataDomain struct {
 	Name              string   `json:"name"`
@@ -141,9 +222,42 @@ type TypedDataField struct {
 
 // TransactionReceipt represents the receipt of a mined transaction
 type TransactionReceipt struct {
-	Status      uint64 `json:"status"`
-	BlockNumber uint64 `json:"blockNumber"`
-	TxHash      string `json:"transactionHash"`
+	Status      uint64       `json:"status"`
+	BlockNumber uint64       `json:"blockNumber"`
+	TxHash      string       `json:"transactionHash"`
+	Logs        []ReceiptLog `json:"logs,omitempty"` // nil when the signer doesn't report logs
+}
+
+// ReceiptLog is an event log emitted by a mined transaction
+type ReceiptLog struct {
+	Address string   `json:"address"` // Emitting contract
+	Topics  []string `json:"topics"`  // 32-byte topics as hex
+	Data    []byte   `json:"data"`
+	Index   uint     `json:"logIndex"` // Position of the log in its block
+}
+
+// ErrReceiptTimeout is wrapped by WaitForTransactionReceipt errors when a transaction isn't
+// mined with the required confirmations before the signer's timeout
+var ErrReceiptTimeout = errors.New("timed out waiting for transaction confirmation")
+
+// ErrSignerLowGas is wrapped by errors refusing to send a transaction because no signing key
+// holds enough native token to pay for its gas
+var ErrSignerLowGas = errors.New("facilitator signer low on gas")
+
+// SendErrorReason maps a WriteContract error to a settle reason
+func SendErrorReason(err error) string {
+	if errors.Is(err, ErrSignerLowGas) {
+		return x402.ReasonFacilitatorLowGas
+	}
+	return x402.ReasonFailedToExecuteTransfer
+}
+
+// ReceiptErrorReason maps a WaitForTransactionReceipt error to a settle reason
+func ReceiptErrorReason(err error) string {
+	if errors.Is(err, ErrReceiptTimeout) {
+		return x402.ReasonSettlementTimeout
+	}
+	return x402.ReasonFailedToGetReceipt
 }
 
 // AssetInfo contains information about an ERC20 token
@@ -155,11 +269,29 @@ type AssetInfo struct {
 	SupportsEIP3009 bool
 }
 
+// MinAmount is the smallest payment a facilitator accepts on a network, for one asset or for
+// every asset on it
+type MinAmount struct {
+	Network string   // CAIP-2 network or alias
+	Asset   string   // Token address, symbol or CAIP-19 identifier; empty covers every asset on the network
+	Amount  *big.Int // In the token's smallest unit
+}
+
 // NetworkConfig contains network-specific configuration
 type NetworkConfig struct {
-	ChainID         *big.Int
-	DefaultAsset    AssetInfo
-	SupportedAssets map[string]AssetInfo // symbol -> AssetInfo
+	ChainID             *big.Int
+	DefaultAsset        AssetInfo
+	SupportedAssets     map[string]AssetInfo // symbol -> AssetInfo
+	FacilitatorContract string               // Facilitator contract address; empty uses FacilitatorContractAddress
+}
+
+// FacilitatorAddress returns the facilitator contract address for the network,
+// falling back to the FacilitatorContractAddress default when none is configured
+func (c *NetworkConfig) FacilitatorAddress() string {
+	if c != nil && c.FacilitatorContract != "" {
+		return c.FacilitatorContract
+	}
+	return FacilitatorContractAddress
 }
 
 // PayloadToMap converts an ExactEIP3009Payload to a map for JSON marshaling
@@ -180,107 +312,36 @@ func (p *ExactEIP3009Payload) ToMap() map[string]interface{} {
 	return result
 }
 
-// PayloadFromMap creates an ExactEIP3009Payload from a map
+// PayloadFromMap decodes an ExactEIP3009Payload from a payload map.
+// V2 payloads should go through UnmarshalPayload, which honours the payload type.
 func PayloadFromMap(data map[string]interface{}) (*ExactEIP3009Payload, error) {
 	payload := &ExactEIP3009Payload{}
-
-	if sig, ok := data["signature"].(string); ok {
-		payload.Signature = sig
-	}
-
-	if auth, ok := data["authorization"].(map[string]interface{}); ok {
-		if from, ok := auth["from"].(string); ok {
-			payload.Authorization.From = from
-		}
-		if to, ok := auth["to"].(string); ok {
-			payload.Authorization.To = to
-		}
-		if value, ok := auth["value"].(string); ok {
-			payload.Authorization.Value = value
-		}
-		if validAfter, ok := auth["validAfter"].(string); ok {
-			payload.Authorization.ValidAfter = validAfter
-		}
-		if validBefore, ok := auth["validBefore"].(string); ok {
-			payload.Authorization.ValidBefore = validBefore
-		}
-		if nonce, ok := auth["nonce"].(string); ok {
-			payload.Authorization.Nonce = nonce
-		}
+	if err := decodePayloadMap(data, payload); err != nil {
+		return nil, err
 	}
-
 	return payload, nil
 }
 
-// PayloadFromMap creates an ExactEIP3009Payload or ExactERC20Payload from a map.
-// Note: Since both payloads share the same underlying struct for Authorization (mostly),
-// and ExactEIP3009Payload is the legacy/standard return type, we currently map everything
-// to that structure where possible or handle the extension fields.
-//
-// Ideally, this should return a wrapper or interface, but for now we will assume the caller
-// knows what to expect or checks the fields.
-// However, to support the new ExactERC20Payload, we might need a more flexible return type or
-// just reuse the existing one if it fits.
-//
-// Actually, looking at types.go, ExactEIP3009Authorization is missing 'Token' and 'NeedApprove'.
-// We need to update ExactEIP3009Payload or use a Union type.
-// Given strict typing, we might need a separate parser or expand ExactEIP3009Authorization?
-// Expanding ExactEIP3009Authorization involves changing the spec.
-//
-// Instead, let's allow returning a pointer to ExactEIP3009Payload OR ExactERC20Payload.
-// But Go doesn't support sum types easily.
-//
-// Let's modify PayloadFromMap to return interface{} or check input.
-// Alternatively, since ExactEvmScheme uses this, and it needs to handle both...
-//
-// Let's UPDATE ExactEIP3009Authorization to include optional Token and NeedApprove?
-// No, that mixes EIP-3009 and ERC-20 auth.
-//
-// Let's add a specialized function PayloadERC20FromMap.
+// PayloadERC20FromMap decodes an ExactERC20Payload (generic ERC-20 authorization, optionally
+// with an EIP-2612 permit) from a payload map
 func PayloadERC20FromMap(data map[string]interface{}) (*ExactERC20Payload, error) {
 	payload := &ExactERC20Payload{}
-
-	if sig, ok := data["signature"].(string); ok {
-		payload.Signature = sig
+	if err := decodePayloadMap(data, payload); err != nil {
+		return nil, err
 	}
-
-	if auth, ok := data["authorization"].(map[string]interface{}); ok {
-		if token, ok := auth["token"].(string); ok {
-			payload.Authorization.Token = token
-		}
-		if from, ok := auth["from"].(string); ok {
-			payload.Authorization.From = from
-		}
-		if to, ok := auth["to"].(string); ok {
-			payload.Authorization.To = to
-		}
-		if value, ok := auth["value"].(string); ok {
-			payload.Authorization.Value = value
-		}
-		if validAfter, ok := auth["validAfter"].(string); ok {
-			payload.Authorization.ValidAfter = validAfter
-		}
-		if validBefore, ok := auth["validBefore"].(string); ok {
-			payload.Authorization.ValidBefore = validBefore
-		}
-		if nonce, ok := auth["nonce"].(string); ok {
-			payload.Authorization.Nonce = nonce
-		}
-		if needApprove, ok := auth["needApprove"].(bool); ok {
-			payload.Authorization.NeedApprove = needApprove
-		}
-	}
-
 	return payload, nil
 }
 
 // IsValidNetwork checks if the network is supported for EVM
 func IsValidNetwork(network string) bool {
-	switch network {
-	case "eip155:1", "eip155:8453", "eip155:84532", "base", "base-sepolia", "base-mainnet":
+	switch NormalizeNetwork(network) {
+	case "eip155:1", "eip155:8453", "eip155:84532", "eip155:42161", "eip155:421614",
+		"eip155:10", "eip155:11155420", "eip155:137", "eip155:80002":
 		return true
 	default:
-		return false
+		// Networks registered at runtime (e.g. via LoadNetworkConfigs)
+		_, err := GetNetworkConfig(network)
+		return err == nil
 	}
 }
 
@@ -3,7 +3,10 @@ package evm
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
@@ -11,21 +14,42 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-)
 
-// GetEvmChainId returns the chain ID for a given network
-func GetEvmChainId(network string) (*big.Int, error) {
-	networkStr := network
+	x402 "x402-go"
+)
 
-	// Normalize network name
-	switch networkStr {
+// NormalizeNetwork maps friendly network aliases (e.g. "base", "arbitrum") to their CAIP-2 identifiers.
+// Unknown values are returned unchanged.
+func NormalizeNetwork(network string) string {
+	switch network {
 	case "base", "base-mainnet":
-		networkStr = "eip155:8453"
+		return "eip155:8453"
 	case "base-sepolia":
-		networkStr = "eip155:84532"
+		return "eip155:84532"
+	case "arbitrum", "arbitrum-one":
+		return "eip155:42161"
+	case "arbitrum-sepolia":
+		return "eip155:421614"
+	case "optimism", "optimism-mainnet":
+		return "eip155:10"
+	case "optimism-sepolia":
+		return "eip155:11155420"
+	case "polygon", "polygon-mainnet":
+		return "eip155:137"
+	case "polygon-amoy":
+		return "eip155:80002"
 	}
+	return network
+}
 
-	if config, ok := NetworkConfigs[networkStr]; ok {
+// GetEvmChainId returns the chain ID for a given network
+func GetEvmChainId(network string) (*big.Int, error) {
+	networkStr := NormalizeNetwork(network)
+
+	networkConfigsMu.RLock()
+	config, ok := NetworkConfigs[networkStr]
+	networkConfigsMu.RUnlock()
+	if ok {
 		return config.ChainID, nil
 	}
 
@@ -75,8 +99,70 @@ func IsValidAddress(address string) bool {
 	return err == nil
 }
 
-// ParseAmount converts a decimal string amount to wei based on token decimals
+// ToChecksumAddress returns the EIP-55 mixed-case checksum form of an address
+func ToChecksumAddress(address string) (string, error) {
+	if !IsValidAddress(address) {
+		return "", fmt.Errorf("invalid address: %s", address)
+	}
+	return common.HexToAddress(address).Hex(), nil
+}
+
+// ValidateAddressChecksum checks a mixed-case address against its EIP-55 checksum.
+// All-lowercase and all-uppercase addresses carry no checksum and are accepted.
+func ValidateAddressChecksum(address string) error {
+	checksummed, err := ToChecksumAddress(address)
+	if err != nil {
+		return err
+	}
+
+	addr := strings.TrimPrefix(address, "0x")
+	if addr == strings.ToLower(addr) || addr == strings.ToUpper(addr) {
+		return nil
+	}
+	if addr != strings.TrimPrefix(checksummed, "0x") {
+		return fmt.Errorf("address %s fails EIP-55 checksum, expected %s", address, checksummed)
+	}
+	return nil
+}
+
+// ValidateRequirementAddresses checks the EIP-55 checksum of the payTo address and, when the
+// asset is given as an address (plain, "erc20:0x..." or CAIP-19), of the asset. A mixed-case address
+// with a bad checksum is almost always a typo, so it is rejected rather than normalized.
+// Values that are not addresses (e.g. asset symbols) are left to the regular lookups.
+func ValidateRequirementAddresses(payTo string, asset string) error {
+	if IsValidAddress(payTo) {
+		if err := ValidateAddressChecksum(payTo); err != nil {
+			return fmt.Errorf("payTo: %w", err)
+		}
+	}
+
+	if _, namespace, reference, err := x402.ParseCAIP19(asset); err == nil && namespace == AssetNamespaceERC20 {
+		asset = reference
+	}
+	if len(asset) >= len(AssetPrefixERC20) && strings.EqualFold(asset[:len(AssetPrefixERC20)], AssetPrefixERC20) {
+		asset = asset[len(AssetPrefixERC20):]
+	}
+	if IsValidAddress(asset) {
+		if err := ValidateAddressChecksum(asset); err != nil {
+			return fmt.Errorf("asset: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ParseAmount converts a decimal string amount to wei based on token decimals.
+// The amount must be an unsigned decimal number with digits before any decimal point and
+// after it ("1", "0.5", "1.50"). Negative or signed amounts, ".5", "1." and amounts with
+// more significant decimals than the token supports are rejected rather than rounded.
 func ParseAmount(amount string, decimals int) (*big.Int, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("invalid decimals: %d", decimals)
+	}
+	if strings.HasPrefix(amount, "-") {
+		return nil, fmt.Errorf("negative amount: %s", amount)
+	}
+
 	// Parse the decimal amount
 	parts := strings.Split(amount, ".")
 	if len(parts) > 2 {
@@ -84,25 +170,30 @@ func ParseAmount(amount string, decimals int) (*big.Int, error) {
 	}
 
 	// Parse integer part
-	intPart, ok := new(big.Int).SetString(parts[0], 10)
-	if !ok {
-		return nil, fmt.Errorf("invalid integer part: %s", parts[0])
+	if !isDecimalDigits(parts[0]) {
+		return nil, fmt.Errorf("invalid integer part: %q", parts[0])
 	}
+	intPart, _ := new(big.Int).SetString(parts[0], 10)
 
 	// Handle decimal part
 	decPart := new(big.Int)
-	if len(parts) == 2 && parts[1] != "" {
-		// Pad or truncate decimal part to match token decimals
+	if len(parts) == 2 {
 		decStr := parts[1]
+		if !isDecimalDigits(decStr) {
+			return nil, fmt.Errorf("invalid decimal part: %q", decStr)
+		}
+
+		// Digits beyond the token's precision may only be trailing zeros
 		if len(decStr) > decimals {
+			if strings.TrimRight(decStr[decimals:], "0") != "" {
+				return nil, fmt.Errorf("amount %s has more than %d decimals", amount, decimals)
+			}
 			decStr = decStr[:decimals]
 		} else {
 			decStr += strings.Repeat("0", decimals-len(decStr))
 		}
-
-		decPart, ok = new(big.Int).SetString(decStr, 10)
-		if !ok {
-			return nil, fmt.Errorf("invalid decimal part: %s", parts[1])
+		if decStr != "" {
+			decPart.SetString(decStr, 10)
 		}
 	}
 
@@ -114,11 +205,30 @@ func ParseAmount(amount string, decimals int) (*big.Int, error) {
 	return result, nil
 }
 
+// isDecimalDigits reports whether s is a non-empty string of ASCII digits
+func isDecimalDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // FormatAmount converts an amount in wei to a decimal string
 func FormatAmount(amount *big.Int, decimals int) string {
 	if amount == nil {
 		return "0"
 	}
+	if amount.Sign() < 0 {
+		return "-" + FormatAmount(new(big.Int).Neg(amount), decimals)
+	}
+	if decimals <= 0 {
+		return amount.String()
+	}
 
 	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
 	quotient, remainder := new(big.Int).DivMod(amount, divisor, new(big.Int))
@@ -139,42 +249,350 @@ func FormatAmount(amount *big.Int, decimals int) string {
 	return quotient.String() + "." + decStr
 }
 
+// NormalizedAmount converts an amount in a token's smallest unit to whole tokens, so amounts
+// of tokens with different decimals can be compared: NormalizedAmount("1000000", 6) and
+// NormalizedAmount("1000000000000000000", 18) are both 1
+func NormalizedAmount(amount string, decimals int) (*big.Rat, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("invalid decimals: %d", decimals)
+	}
+	units, ok := new(big.Int).SetString(amount, 10)
+	if !ok || units.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Rat).SetFrac(units, scale), nil
+}
+
 // GetNetworkConfig returns the configuration for a network
 func GetNetworkConfig(network string) (*NetworkConfig, error) {
-	networkStr := network
+	networkStr := NormalizeNetwork(network)
 
-	// Normalize network name
-	switch networkStr {
-	case "base", "base-mainnet":
-		networkStr = "eip155:8453"
-	case "base-sepolia":
-		networkStr = "eip155:84532"
+	networkConfigsMu.RLock()
+	config, ok := NetworkConfigs[networkStr]
+	networkConfigsMu.RUnlock()
+	if ok {
+		return &config, nil
+	}
+
+	return nil, fmt.Errorf("unsupported network: %s", network)
+}
+
+// GetConfiguredNetworks returns the networks in NetworkConfigs, including aliases and networks
+// registered at runtime. Safe to call concurrently with registrations.
+func GetConfiguredNetworks() []string {
+	networkConfigsMu.RLock()
+	defer networkConfigsMu.RUnlock()
+
+	networks := make([]string, 0, len(NetworkConfigs))
+	for network := range NetworkConfigs {
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// GetFacilitatorContractAddress returns the facilitator contract address for a network.
+// Networks without their own FacilitatorContract (including unknown ones) use FacilitatorContractAddress.
+func GetFacilitatorContractAddress(network string) string {
+	config, err := GetNetworkConfig(network)
+	if err != nil {
+		return FacilitatorContractAddress
+	}
+	return config.FacilitatorAddress()
+}
+
+// networkConfigsMu guards NetworkConfigs against registrations racing with lookups
+var networkConfigsMu sync.RWMutex
+
+// RegisterAsset adds (or replaces) an asset in a network's SupportedAssets so it can be resolved
+// by symbol or address. The network must already be configured. Safe to call concurrently with lookups.
+func RegisterAsset(network string, symbol string, info AssetInfo) error {
+	return registerAsset(network, symbol, info, false)
+}
+
+// RegisterDefaultAsset registers an asset like RegisterAsset and also makes it the network's DefaultAsset
+func RegisterDefaultAsset(network string, symbol string, info AssetInfo) error {
+	return registerAsset(network, symbol, info, true)
+}
+
+func registerAsset(network string, symbol string, info AssetInfo, setDefault bool) error {
+	if symbol == "" {
+		return fmt.Errorf("asset symbol is required")
+	}
+	if !IsValidAddress(info.Address) {
+		return fmt.Errorf("invalid asset address: %s", info.Address)
+	}
+
+	networkStr := NormalizeNetwork(network)
+
+	networkConfigsMu.Lock()
+	defer networkConfigsMu.Unlock()
+
+	config, ok := NetworkConfigs[networkStr]
+	if !ok {
+		return fmt.Errorf("unsupported network: %s", network)
+	}
+
+	assets := cloneAssets(config.SupportedAssets)
+	assets[strings.ToUpper(symbol)] = info
+
+	config.SupportedAssets = assets
+	if setDefault {
+		config.DefaultAsset = info
 	}
+	NetworkConfigs[networkStr] = config
 
-	if config, ok := NetworkConfigs[networkStr]; ok {
+	return nil
+}
+
+// setAssetAddress points a network's default asset, and its supported asset registered under
+// symbol, at address. network is a NetworkConfigs key and isn't normalized, so alias entries
+// such as "base-sepolia" can be updated too. Unknown networks are ignored.
+func setAssetAddress(network string, symbol string, address string) {
+	networkConfigsMu.Lock()
+	defer networkConfigsMu.Unlock()
+
+	config, ok := NetworkConfigs[network]
+	if !ok {
+		return
+	}
+
+	config.DefaultAsset.Address = address
+	if asset, ok := config.SupportedAssets[symbol]; ok {
+		asset.Address = address
+		config.SupportedAssets = cloneAssets(config.SupportedAssets)
+		config.SupportedAssets[symbol] = asset
+	}
+	NetworkConfigs[network] = config
+}
+
+// cloneAssets copies a SupportedAssets map before a change. Configs are copied on write so
+// readers holding the previous map never observe a concurrent write.
+func cloneAssets(assets map[string]AssetInfo) map[string]AssetInfo {
+	clone := make(map[string]AssetInfo, len(assets)+1)
+	for k, v := range assets {
+		clone[k] = v
+	}
+	return clone
+}
+
+// SynthesizedNetworkConfigCache caches network configs synthesized on-chain for networks missing from NetworkConfigs
+// Key format: "chainID:tokenAddress"
+var SynthesizedNetworkConfigCache sync.Map
+
+// ParseERC20Asset extracts the token address from a fully-qualified "erc20:0x..." asset identifier
+func ParseERC20Asset(asset string) (string, bool) {
+	if len(asset) < len(AssetPrefixERC20) || !strings.EqualFold(asset[:len(AssetPrefixERC20)], AssetPrefixERC20) {
+		return "", false
+	}
+
+	address := asset[len(AssetPrefixERC20):]
+	if !IsValidAddress(address) {
+		return "", false
+	}
+
+	return address, true
+}
+
+// ErrAssetNetworkMismatch is wrapped by errors for assets that don't exist on the network
+// they are requested on
+var ErrAssetNetworkMismatch = errors.New("asset network mismatch")
+
+// ErrChainIDMismatch is wrapped by errors for on-chain reads through a reader connected to a
+// different chain than the network being resolved
+var ErrChainIDMismatch = errors.New("chain ID mismatch")
+
+// NormalizeAssetIdentifier rewrites a CAIP-19 ERC-20 asset identifier ("eip155:8453/erc20:0x...")
+// to the "erc20:0x..." form the other asset lookups take, after checking that its chain is
+// network (the error wraps ErrAssetNetworkMismatch when it isn't). Symbols, addresses and
+// "erc20:0x..." identifiers are returned unchanged.
+func NormalizeAssetIdentifier(network string, asset string) (string, error) {
+	if !strings.Contains(asset, "/") {
+		return asset, nil
+	}
+
+	assetNetwork, namespace, reference, err := x402.ParseCAIP19(asset)
+	if err != nil {
+		return "", err
+	}
+	if NormalizeNetwork(assetNetwork) != NormalizeNetwork(network) {
+		return "", fmt.Errorf("%w: %s is on %s, not %s", ErrAssetNetworkMismatch, asset, assetNetwork, network)
+	}
+	if namespace != AssetNamespaceERC20 || !IsValidAddress(reference) {
+		return "", fmt.Errorf("asset %s is not an ERC-20 token", asset)
+	}
+	return AssetPrefixERC20 + reference, nil
+}
+
+// ResolveNetworkConfig returns the configuration for a network, synthesizing one for unknown EVM chains.
+// Networks in NetworkConfigs are returned as-is. For any other eip155:<chainId> network, if the asset is a
+// fully-qualified "erc20:0x..." address (or its CAIP-19 form), a minimal config is built from the token's
+// on-chain name, decimals and EIP-712 version read through reader. If reader implements ChainIDReader and
+// is connected to another chain, an error wrapping ErrChainIDMismatch is returned instead. Synthesized
+// configs are cached per chain and token.
+func ResolveNetworkConfig(ctx context.Context, reader ContractReader, network string, asset string) (*NetworkConfig, error) {
+	if config, err := GetNetworkConfig(network); err == nil {
+		return config, nil
+	}
+
+	asset, err := NormalizeAssetIdentifier(network, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	networkStr := NormalizeNetwork(network)
+	tokenAddress, ok := ParseERC20Asset(asset)
+	if !strings.HasPrefix(networkStr, "eip155:") || !ok || reader == nil {
+		return nil, fmt.Errorf("unsupported network: %s", network)
+	}
+
+	chainID, err := GetEvmChainId(networkStr)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := synthesizeNetworkConfig(ctx, reader, chainID, tokenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported network %s: %w", network, err)
+	}
+
+	return config, nil
+}
+
+// synthesizeNetworkConfig builds (or loads from cache) a minimal config for a token read on-chain
+func synthesizeNetworkConfig(ctx context.Context, reader ContractReader, chainID *big.Int, tokenAddress string) (*NetworkConfig, error) {
+	cacheKey := fmt.Sprintf("%s:%s", chainID.String(), NormalizeAddress(tokenAddress))
+	if val, ok := SynthesizedNetworkConfigCache.Load(cacheKey); ok {
+		config := val.(NetworkConfig)
 		return &config, nil
 	}
 
-	return nil, fmt.Errorf("unsupported network: %s", network)
+	// A reader on another chain would cache that chain's token metadata under this one
+	if chainReader, ok := reader.(ChainIDReader); ok {
+		readerChainID, err := chainReader.GetChainID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chain ID: %w", err)
+		}
+		if readerChainID.Cmp(chainID) != 0 {
+			return nil, fmt.Errorf("%w: reader is connected to chain %s, not %s", ErrChainIDMismatch, readerChainID, chainID)
+		}
+	}
+
+	assetInfo, err := readTokenMetadata(ctx, reader, tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	config := NetworkConfig{
+		ChainID:      chainID,
+		DefaultAsset: *assetInfo,
+	}
+	SynthesizedNetworkConfigCache.Store(cacheKey, config)
+
+	return &config, nil
 }
 
-// GetAssetInfo returns information about an asset on a network
+// ResolveAssetInfo returns information about an asset on a network, using the same on-chain
+// fallback as ResolveNetworkConfig for networks missing from NetworkConfigs
+func ResolveAssetInfo(ctx context.Context, reader ContractReader, network string, asset string) (*AssetInfo, error) {
+	asset, err := NormalizeAssetIdentifier(network, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	if config, err := GetNetworkConfig(network); err == nil {
+		tokenAddress, ok := ParseERC20Asset(asset)
+		if !ok && IsValidAddress(asset) {
+			tokenAddress, ok = asset, true
+		}
+		if !ok || reader == nil {
+			return GetAssetInfo(network, asset)
+		}
+		if assetInfo, found := findAssetByAddress(config, tokenAddress); found {
+			return assetInfo, nil
+		}
+
+		// Address not configured for this network: read its metadata on-chain
+		synthesized, err := synthesizeNetworkConfig(ctx, reader, config.ChainID, tokenAddress)
+		if err != nil {
+			return GetAssetInfo(network, asset)
+		}
+		return &synthesized.DefaultAsset, nil
+	}
+
+	config, err := ResolveNetworkConfig(ctx, reader, network, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config.DefaultAsset, nil
+}
+
+// readTokenMetadata reads an ERC-20 token's name, decimals and EIP-712 version on-chain.
+// Tokens without a version() view fall back to "1", the EIP-712 default used by most implementations.
+func readTokenMetadata(ctx context.Context, reader ContractReader, tokenAddress string) (*AssetInfo, error) {
+	nameResult, err := reader.ReadContract(ctx, tokenAddress, ERC20MetadataABI, FunctionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token name: %w", err)
+	}
+	name, ok := nameResult.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected token name type: %T", nameResult)
+	}
+
+	decimalsResult, err := reader.ReadContract(ctx, tokenAddress, ERC20MetadataABI, FunctionDecimals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token decimals: %w", err)
+	}
+	var decimals int
+	switch d := decimalsResult.(type) {
+	case uint8:
+		decimals = int(d)
+	case *big.Int:
+		decimals = int(d.Int64())
+	default:
+		return nil, fmt.Errorf("unexpected token decimals type: %T", decimalsResult)
+	}
+
+	version := "1"
+	if versionResult, err := reader.ReadContract(ctx, tokenAddress, ERC20MetadataABI, FunctionVersion); err == nil {
+		if v, ok := versionResult.(string); ok && v != "" {
+			version = v
+		}
+	}
+
+	return &AssetInfo{
+		Address:  tokenAddress,
+		Name:     name,
+		Version:  version,
+		Decimals: decimals,
+	}, nil
+}
+
+// GetAssetInfo returns information about an asset on a network. The asset is a symbol, an address,
+// an "erc20:0x..." identifier or a CAIP-19 identifier, which must name a token on network.
 func GetAssetInfo(network string, assetSymbolOrAddress string) (*AssetInfo, error) {
 	config, err := GetNetworkConfig(network)
 	if err != nil {
 		return nil, err
 	}
 
+	assetSymbolOrAddress, err = NormalizeAssetIdentifier(network, assetSymbolOrAddress)
+	if err != nil {
+		return nil, err
+	}
+	if address, ok := ParseERC20Asset(assetSymbolOrAddress); ok {
+		assetSymbolOrAddress = address
+	}
+
 	// Check if it's an address
 	if IsValidAddress(assetSymbolOrAddress) {
-		// For now, assume it's USDC if the address matches
-		normalizedAddr := NormalizeAddress(assetSymbolOrAddress)
-		if normalizedAddr == NormalizeAddress(config.DefaultAsset.Address) {
-			return &config.DefaultAsset, nil
+		if asset, ok := findAssetByAddress(config, assetSymbolOrAddress); ok {
+			return asset, nil
 		}
-		// Could extend this to support more tokens
+		// Unknown token; callers with RPC access should prefer ResolveAssetInfo
 		return &AssetInfo{
-			Address:  normalizedAddr,
+			Address:  NormalizeAddress(assetSymbolOrAddress),
 			Name:     "Unknown Token",
 			Version:  "1",
 			Decimals: 18, // Default to 18 decimals for unknown tokens
@@ -190,15 +608,76 @@ func GetAssetInfo(network string, assetSymbolOrAddress string) (*AssetInfo, erro
 	return &config.DefaultAsset, nil
 }
 
-// CreateValidityWindow creates valid after/before timestamps
+// IsConfiguredAsset reports whether address is the default or a supported asset of network
+func IsConfiguredAsset(network string, address string) bool {
+	config, err := GetNetworkConfig(network)
+	if err != nil {
+		return false
+	}
+	_, ok := findAssetByAddress(config, address)
+	return ok
+}
+
+// findAssetByAddress searches the network's default and supported assets for a token address
+func findAssetByAddress(config *NetworkConfig, address string) (*AssetInfo, bool) {
+	normalizedAddr := NormalizeAddress(address)
+	if normalizedAddr == NormalizeAddress(config.DefaultAsset.Address) {
+		return &config.DefaultAsset, true
+	}
+
+	for _, asset := range config.SupportedAssets {
+		if normalizedAddr == NormalizeAddress(asset.Address) {
+			return &asset, true
+		}
+	}
+
+	return nil, false
+}
+
+// CreateValidityWindow creates valid after/before timestamps. validAfter is backdated by
+// ClockSkewTolerance so a facilitator whose clock lags the client's accepts it immediately.
 func CreateValidityWindow(duration time.Duration) (validAfter, validBefore *big.Int) {
 	now := time.Now().Unix()
-	// Add 30 second buffer to account for clock skew and block time
-	validAfter = big.NewInt(now - 30)
+	validAfter = big.NewInt(now - int64(ClockSkewTolerance.Seconds()))
 	validBefore = big.NewInt(now + int64(duration.Seconds()))
 	return validAfter, validBefore
 }
 
+var (
+	// ErrAuthorizationExpired is returned by CheckValidityWindow when validBefore has passed
+	ErrAuthorizationExpired = errors.New("authorization expired")
+
+	// ErrAuthorizationNotYetValid is returned by CheckValidityWindow when validAfter is in the future
+	ErrAuthorizationNotYetValid = errors.New("authorization not yet valid")
+)
+
+// CheckValidityWindow checks that now (unix seconds) falls within validAfter/validBefore,
+// allowing either bound to be off by tolerance
+func CheckValidityWindow(validAfter, validBefore *big.Int, now int64, tolerance time.Duration) error {
+	skew := int64(tolerance.Seconds())
+	if validBefore.Cmp(big.NewInt(now-skew)) <= 0 {
+		return ErrAuthorizationExpired
+	}
+	if validAfter.Cmp(big.NewInt(now+skew)) > 0 {
+		return ErrAuthorizationNotYetValid
+	}
+	return nil
+}
+
+// SettlementCacheKey derives the x402.SettlementCache key of a payment: its authorization
+// (network, token, payer and nonce) plus a digest of the payload and requirements, so a
+// request reusing the authorization with other content never gets the cached result
+func SettlementCacheKey(network, token, from, nonce string, payload, requirements interface{}) string {
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	hash := sha256.New()
+	hash.Write(payloadBytes)
+	hash.Write([]byte{0})
+	hash.Write(requirementsBytes)
+	return strings.ToLower(strings.Join([]string{network, token, from, nonce}, "|")) + "|" + hex.EncodeToString(hash.Sum(nil))
+}
+
 // HexToBytes converts a hex string to bytes
 func HexToBytes(hexStr string) ([]byte, error) {
 	// Remove 0x prefix if present
@@ -206,101 +685,361 @@ func HexToBytes(hexStr string) ([]byte, error) {
 	return hex.DecodeString(cleaned)
 }
 
-// VerifyEIP3009Support checks if a token contract supports EIP-3009 transferWithAuthorization.
-// It simulates a call with a random valid-looking signature.
-// If the call reverts with "invalid signature" (or similar), it means the function exists.
-// If it reverts because function selector not found (fallback), it means not supported.
+// SupportCache caches the results of on-chain capability probes. Entries expire after a TTL
+// so a contract upgrade, or a wrong answer caused by a transient RPC failure, is eventually re-probed.
+// The zero value is ready to use and expires entries after DefaultSupportCacheTTL.
+type SupportCache struct {
+	mu      sync.RWMutex
+	ttl     time.Duration
+	entries map[string]supportCacheEntry
+	now     func() time.Time // overridable in tests
+}
+
+type supportCacheEntry struct {
+	supported bool
+	expiresAt time.Time
+}
+
+// SetTTL sets how long new entries stay valid. A non-positive ttl restores DefaultSupportCacheTTL.
+// Entries already cached keep their original expiry.
+func (c *SupportCache) SetTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+}
+
+// Load returns the cached support status for key, if present and not expired
+func (c *SupportCache) Load(key string) (supported bool, ok bool) {
+	c.mu.RLock()
+	entry, found := c.entries[key]
+	now := c.clock()
+	c.mu.RUnlock()
+
+	if !found || !now.Before(entry.expiresAt) {
+		return false, false
+	}
+	return entry.supported, true
+}
+
+// Store caches the support status for key
+func (c *SupportCache) Store(key string, supported bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := c.ttl
+	if ttl <= 0 {
+		ttl = DefaultSupportCacheTTL
+	}
+	if c.entries == nil {
+		c.entries = make(map[string]supportCacheEntry)
+	}
+	c.entries[key] = supportCacheEntry{supported: supported, expiresAt: c.clock().Add(ttl)}
+}
+
+// Delete removes the cached support status for key
+func (c *SupportCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Clear removes every cached entry
+func (c *SupportCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+func (c *SupportCache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
 // EIP3009SupportCache caches the support status (supported, unsupported) for tokens on chains
 // Key format: "chainID:tokenAddress"
-var EIP3009SupportCache sync.Map
+var EIP3009SupportCache SupportCache
+
+// eip3009SupportCacheKey builds the EIP3009SupportCache key for a token on a chain
+func eip3009SupportCacheKey(chainID *big.Int, tokenAddress string) string {
+	return fmt.Sprintf("%s:%s", chainID.String(), strings.ToLower(tokenAddress))
+}
+
+// InvalidateEIP3009Support drops the cached EIP-3009 support status for a token,
+// so the next VerifyEIP3009Support call probes the chain again
+func InvalidateEIP3009Support(chainID *big.Int, tokenAddress string) {
+	EIP3009SupportCache.Delete(eip3009SupportCacheKey(chainID, tokenAddress))
+}
 
 // VerifyEIP3009Support checks if a token contract supports EIP-3009 transferWithAuthorization.
-// It simulates a call with a random valid-looking signature.
-// If the call reverts with "invalid signature" (or similar), it means the function exists.
-// If it reverts because function selector not found (fallback), it means not supported.
+//
+// It first calls the authorizationState(address,bytes32) view, which every EIP-3009 token exposes:
+// a successful call means the token is supported. If that reverts, it simulates transferWithAuthorization
+// with a dummy signature. A revert carrying an EIP-3009 reason or a custom error means the function
+// exists but rejected the arguments; a bare revert or an empty result means the selector fell through
+// to a fallback, so the token is not supported.
+//
+// An error is returned only when the RPC call itself fails. Such results are not cached.
 func VerifyEIP3009Support(ctx context.Context, reader ContractReader, chainID *big.Int, fromAddress string, tokenAddress string) (bool, error) {
 	// Check cache first
-	cacheKey := fmt.Sprintf("%s:%s", chainID.String(), strings.ToLower(tokenAddress))
-	if val, ok := EIP3009SupportCache.Load(cacheKey); ok {
-		return val.(bool), nil
+	cacheKey := eip3009SupportCacheKey(chainID, tokenAddress)
+	if supported, ok := EIP3009SupportCache.Load(cacheKey); ok {
+		return supported, nil
 	}
 
-	// EIP-3009 transferWithAuthorization selector: e3ee160e
-	// transferWithAuthorization(address,address,uint256,uint256,uint256,bytes32,uint8,bytes32,bytes32)
-	// We use the VRS version as it's the standard for EOAs.
+	supported, err := probeEIP3009Support(ctx, reader, fromAddress, tokenAddress)
+	if err != nil {
+		return false, err
+	}
 
-	// Construct a dummy call
+	// Update cache
+	EIP3009SupportCache.Store(cacheKey, supported)
+
+	return supported, nil
+}
+
+// probeEIP3009Support runs the on-chain checks behind VerifyEIP3009Support
+func probeEIP3009Support(ctx context.Context, reader ContractReader, fromAddress string, tokenAddress string) (bool, error) {
 	from := common.HexToAddress(fromAddress)
-	to := common.HexToAddress(fromAddress) // Self for safety
-	value := big.NewInt(0)
-	validAfter := big.NewInt(0)
-	validBefore := big.NewInt(0)
 	var nonce [32]byte
 
-	// Dummy signature parts
-	var v uint8 = 27
-	var r [32]byte
-	var s [32]byte
-
-	// Create minimal ABI for just this function
-	minimalABI := []byte(`[
-		{
-			"inputs": [
-				{"name": "from", "type": "address"},
-				{"name": "to", "type": "address"},
-				{"name": "value", "type": "uint256"},
-				{"name": "validAfter", "type": "uint256"},
-				{"name": "validBefore", "type": "uint256"},
-				{"name": "nonce", "type": "bytes32"},
-				{"name": "v", "type": "uint8"},
-				{"name": "r", "type": "bytes32"},
-				{"name": "s", "type": "bytes32"}
-			],
-			"name": "transferWithAuthorization",
-			"outputs": [],
-			"stateMutability": "nonpayable",
-			"type": "function"
-		}
-	]`)
+	// authorizationState is the most reliable indicator
+	_, err := reader.ReadContract(ctx, tokenAddress, AuthorizationStateABI, FunctionAuthorizationState, from, nonce)
+	if err == nil {
+		return true, nil
+	}
+	if !isRevertError(err) && !isEmptyResultError(err) {
+		return false, fmt.Errorf("failed to probe %s: %w", FunctionAuthorizationState, err)
+	}
 
-	// Perform read (simulation)
+	// Fall back to simulating transferWithAuthorization with a dummy VRS signature.
 	// We expect this to fail, but HOW it fails tells us if supported.
-	_, err := reader.ReadContract(
+	var v uint8 = 27
+	var r, s [32]byte
+	_, err = reader.ReadContract(
 		ctx,
 		tokenAddress,
-		minimalABI,
-		"transferWithAuthorization",
+		TransferWithAuthorizationVRSABI,
+		FunctionTransferWithAuthorization,
 		from,
-		to,
-		value,
-		validAfter,
-		validBefore,
+		from, // Self for safety
+		big.NewInt(0),
+		big.NewInt(0),
+		big.NewInt(0),
 		nonce,
 		v,
 		r,
 		s,
 	)
+	switch {
+	case err == nil:
+		// Surprising success (maybe it accepts anything?)
+		return true, nil
+	case isRevertError(err):
+		return hasEIP3009RevertReason(err) || hasCustomErrorData(err), nil
+	case isEmptyResultError(err):
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to probe %s: %w", FunctionTransferWithAuthorization, err)
+	}
+}
 
-	var supported bool
-	if err == nil {
-		// Verify surprising success (maybe it accepts anything?)
-		supported = true
-	} else {
-		// Check error message
-		errStr := err.Error()
-
-		// If revert contains "signature" or specific EIP-3009 errors, it's supported
-		if strings.Contains(strings.ToLower(errStr), "signature") ||
-			strings.Contains(strings.ToLower(errStr), "authorization") ||
-			strings.Contains(strings.ToLower(errStr), "nonce") {
-			supported = true
-		} else {
-			supported = false
-		}
+// revertDataError is implemented by go-ethereum RPC errors that carry revert data
+type revertDataError interface {
+	ErrorData() interface{}
+}
+
+// isRevertError reports whether err is the contract reverting, as opposed to the RPC call failing
+func isRevertError(err error) bool {
+	var dataErr revertDataError
+	if errors.As(err, &dataErr) {
+		return true
 	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "revert") || strings.Contains(msg, "invalid opcode")
+}
 
-	// Update cache
-	EIP3009SupportCache.Store(cacheKey, supported)
+// isEmptyResultError reports whether err comes from decoding an empty call result,
+// which is what a fallback function (or an address without code) returns for an unknown selector
+func isEmptyResultError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "failed to unpack") ||
+		strings.Contains(msg, "empty string") ||
+		strings.Contains(msg, "no data")
+}
+
+// hasEIP3009RevertReason reports whether a revert reason mentions what EIP-3009 tokens check
+func hasEIP3009RevertReason(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "signature") ||
+		strings.Contains(msg, "authorization") ||
+		strings.Contains(msg, "nonce")
+}
+
+// hasCustomErrorData reports whether a revert carries a custom error selector.
+// Error(string) and Panic(uint256) are excluded since fallbacks and proxies revert with those too.
+func hasCustomErrorData(err error) bool {
+	var dataErr revertDataError
+	if !errors.As(err, &dataErr) {
+		return false
+	}
+	data, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return false
+	}
+	data = strings.ToLower(strings.TrimPrefix(data, "0x"))
+	if len(data) < 8 {
+		return false
+	}
+	selector := data[:8]
+	return selector != "08c379a0" && selector != "4e487b71"
+}
+
+// BatchSettlementSupportCache caches whether a facilitator contract supports settlePaymentBatch
+// Key format: "chainID:contractAddress"
+var BatchSettlementSupportCache SupportCache
+
+// VerifyBatchSettlementSupport checks if the facilitator contract at contractAddress on a chain implements
+// settlePaymentBatch. It simulates an empty batch: a contract with the function accepts it, while one without it
+// reverts because the selector is unknown. Only those definitive results are cached; other failures, such as an
+// unreachable RPC endpoint, are returned so the next call probes again.
+func VerifyBatchSettlementSupport(ctx context.Context, reader ContractReader, chainID *big.Int, contractAddress string) (bool, error) {
+	cacheKey := fmt.Sprintf("%s:%s", chainID.String(), strings.ToLower(contractAddress))
+	if supported, ok := BatchSettlementSupportCache.Load(cacheKey); ok {
+		return supported, nil
+	}
+
+	_, err := reader.ReadContract(
+		ctx,
+		contractAddress,
+		SettlePaymentBatchABI,
+		FunctionSettlePaymentBatch,
+		common.Address{},
+		[]common.Address{},
+		[]common.Address{},
+		[]*big.Int{},
+		[]*big.Int{},
+		[]*big.Int{},
+		[][32]byte{},
+		[][]byte{},
+	)
+	if err != nil && ctx.Err() != nil {
+		// Don't cache a probe that was cut short
+		return false, ctx.Err()
+	}
+	if err != nil && !isRevertError(err) && !isEmptyResultError(err) {
+		return false, fmt.Errorf("failed to probe %s: %w", FunctionSettlePaymentBatch, err)
+	}
+
+	supported := err == nil
+	BatchSettlementSupportCache.Store(cacheKey, supported)
 
 	return supported, nil
 }
+
+// FacilitatorContractCache caches whether the facilitator contract is deployed on a chain
+// Key format: "chainID:contractAddress"
+var FacilitatorContractCache SupportCache
+
+// VerifyFacilitatorContractDeployed checks whether the facilitator contract at contractAddress
+// has code on a chain. An empty address counts as not deployed. RPC failures are returned
+// and not cached.
+func VerifyFacilitatorContractDeployed(ctx context.Context, signer FacilitatorEvmSigner, chainID *big.Int, contractAddress string) (bool, error) {
+	if contractAddress == "" {
+		return false, nil
+	}
+
+	cacheKey := fmt.Sprintf("%s:%s", chainID.String(), strings.ToLower(contractAddress))
+	if deployed, ok := FacilitatorContractCache.Load(cacheKey); ok {
+		return deployed, nil
+	}
+
+	code, err := signer.GetCode(ctx, contractAddress)
+	if err != nil {
+		return false, err
+	}
+
+	deployed := len(code) > 0
+	FacilitatorContractCache.Store(cacheKey, deployed)
+
+	return deployed, nil
+}
+
+// AssetDeploymentCache caches the tokens found to have code on a chain. Tokens without code
+// aren't cached, so one deployed later is accepted on the next check.
+// Key format: "chainID:tokenAddress"
+var AssetDeploymentCache SupportCache
+
+// VerifyAssetOnNetwork checks that tokenAddress is a token on network. Tokens configured for
+// the network are trusted; any other address must hold code on the chain signer is connected
+// to. A false result means the token only exists on another chain (or nowhere), so payments
+// in it can't settle on network. RPC failures are returned.
+func VerifyAssetOnNetwork(ctx context.Context, signer FacilitatorEvmSigner, network string, chainID *big.Int, tokenAddress string) (bool, error) {
+	if config, err := GetNetworkConfig(network); err == nil {
+		if _, ok := findAssetByAddress(config, tokenAddress); ok {
+			return true, nil
+		}
+	}
+
+	cacheKey := fmt.Sprintf("%s:%s", chainID.String(), strings.ToLower(tokenAddress))
+	if _, ok := AssetDeploymentCache.Load(cacheKey); ok {
+		return true, nil
+	}
+
+	code, err := signer.GetCode(ctx, tokenAddress)
+	if err != nil {
+		return false, err
+	}
+	if len(code) == 0 {
+		return false, nil
+	}
+	AssetDeploymentCache.Store(cacheKey, true)
+
+	return true, nil
+}
+
+// FindMinAmount returns the minimum payment in the token at tokenAddress on network: the
+// amount of the entry naming that token, else of the entry covering the whole network, else
+// nil when there is no minimum. Entries naming an asset the network doesn't know are ignored.
+func FindMinAmount(minimums []MinAmount, network string, tokenAddress string) *big.Int {
+	network = NormalizeNetwork(network)
+
+	var networkWide *big.Int
+	for _, minimum := range minimums {
+		if minimum.Amount == nil || NormalizeNetwork(minimum.Network) != network {
+			continue
+		}
+		if minimum.Asset == "" {
+			networkWide = minimum.Amount
+			continue
+		}
+		asset, err := GetAssetInfo(network, minimum.Asset)
+		if err == nil && NormalizeAddress(asset.Address) == NormalizeAddress(tokenAddress) {
+			return minimum.Amount
+		}
+	}
+
+	return networkWide
+}
+
+// GetPermitNonce checks whether a token implements EIP-2612 permit and returns the owner's current permit nonce.
+// Support is detected by probing the DOMAIN_SEPARATOR() and nonces(address) views, which every
+// EIP-2612 token exposes. Returns supported=false (and no error) when either probe fails.
+func GetPermitNonce(ctx context.Context, reader ContractReader, tokenAddress string, owner string) (*big.Int, bool) {
+	if _, err := reader.ReadContract(ctx, tokenAddress, PermitABI, FunctionDomainSeparator); err != nil {
+		return nil, false
+	}
+
+	result, err := reader.ReadContract(ctx, tokenAddress, PermitABI, FunctionNonces, common.HexToAddress(owner))
+	if err != nil {
+		return nil, false
+	}
+
+	nonce, ok := result.(*big.Int)
+	if !ok {
+		return nil, false
+	}
+
+	return nonce, true
+}
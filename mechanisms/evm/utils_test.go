@@ -0,0 +1,805 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestNetworkAliases tests that friendly network aliases resolve to their CAIP-2 configuration
+func TestNetworkAliases(t *testing.T) {
+	tests := []struct {
+		name        string
+		network     string
+		wantNetwork string
+		wantChainID int64
+	}{
+		{name: "base alias", network: "base", wantNetwork: "eip155:8453", wantChainID: 8453},
+		{name: "base-sepolia alias", network: "base-sepolia", wantNetwork: "eip155:84532", wantChainID: 84532},
+		{name: "arbitrum alias", network: "arbitrum", wantNetwork: "eip155:42161", wantChainID: 42161},
+		{name: "arbitrum-sepolia alias", network: "arbitrum-sepolia", wantNetwork: "eip155:421614", wantChainID: 421614},
+		{name: "arbitrum CAIP-2", network: "eip155:42161", wantNetwork: "eip155:42161", wantChainID: 42161},
+		{name: "optimism alias", network: "optimism", wantNetwork: "eip155:10", wantChainID: 10},
+		{name: "optimism-sepolia alias", network: "optimism-sepolia", wantNetwork: "eip155:11155420", wantChainID: 11155420},
+		{name: "optimism CAIP-2", network: "eip155:10", wantNetwork: "eip155:10", wantChainID: 10},
+		{name: "polygon alias", network: "polygon", wantNetwork: "eip155:137", wantChainID: 137},
+		{name: "polygon-amoy alias", network: "polygon-amoy", wantNetwork: "eip155:80002", wantChainID: 80002},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeNetwork(tt.network); got != tt.wantNetwork {
+				t.Errorf("NormalizeNetwork(%q) = %q, want %q", tt.network, got, tt.wantNetwork)
+			}
+
+			if !IsValidNetwork(tt.network) {
+				t.Errorf("IsValidNetwork(%q) = false, want true", tt.network)
+			}
+
+			chainID, err := GetEvmChainId(tt.network)
+			if err != nil {
+				t.Fatalf("GetEvmChainId(%q) error: %v", tt.network, err)
+			}
+			if chainID.Int64() != tt.wantChainID {
+				t.Errorf("GetEvmChainId(%q) = %d, want %d", tt.network, chainID.Int64(), tt.wantChainID)
+			}
+
+			config, err := GetNetworkConfig(tt.network)
+			if err != nil {
+				t.Fatalf("GetNetworkConfig(%q) error: %v", tt.network, err)
+			}
+			if config.ChainID.Int64() != tt.wantChainID {
+				t.Errorf("GetNetworkConfig(%q).ChainID = %d, want %d", tt.network, config.ChainID.Int64(), tt.wantChainID)
+			}
+		})
+	}
+}
+
+// TestArbitrumUSDC tests the Arbitrum USDC asset configuration
+func TestArbitrumUSDC(t *testing.T) {
+	for _, network := range []string{"eip155:42161", "eip155:421614"} {
+		config, err := GetNetworkConfig(network)
+		if err != nil {
+			t.Fatalf("GetNetworkConfig(%q) error: %v", network, err)
+		}
+
+		asset := config.DefaultAsset
+		if asset.Name != "USD Coin" || asset.Version != "2" {
+			t.Errorf("%s: unexpected EIP-712 domain %q/%q", network, asset.Name, asset.Version)
+		}
+		if asset.Decimals != 6 {
+			t.Errorf("%s: expected 6 decimals, got %d", network, asset.Decimals)
+		}
+		if !asset.SupportsEIP3009 {
+			t.Errorf("%s: expected EIP-3009 support", network)
+		}
+		if !IsValidAddress(asset.Address) {
+			t.Errorf("%s: invalid USDC address %q", network, asset.Address)
+		}
+	}
+}
+
+// TestPolygonUSDCVariants tests that native and bridged USDC resolve separately on Polygon
+func TestPolygonUSDCVariants(t *testing.T) {
+	native, err := GetAssetInfo("eip155:137", "USDC")
+	if err != nil {
+		t.Fatalf("GetAssetInfo(USDC) error: %v", err)
+	}
+	bridged, err := GetAssetInfo("eip155:137", "USDCE")
+	if err != nil {
+		t.Fatalf("GetAssetInfo(USDCE) error: %v", err)
+	}
+
+	if native.Address == bridged.Address {
+		t.Fatal("Expected native and bridged USDC to have different addresses")
+	}
+	if native.Address != "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359" {
+		t.Errorf("Unexpected native USDC address: %s", native.Address)
+	}
+	if bridged.Address != "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174" {
+		t.Errorf("Unexpected bridged USDC.e address: %s", bridged.Address)
+	}
+	if native.Decimals != 6 || bridged.Decimals != 6 {
+		t.Errorf("Expected 6 decimals for both, got %d and %d", native.Decimals, bridged.Decimals)
+	}
+}
+
+// metadataReader is a ContractReader that serves ERC-20 metadata views and counts calls
+type metadataReader struct {
+	name     string
+	decimals uint8
+	version  string
+	calls    int
+}
+
+func (r *metadataReader) ReadContract(_ context.Context, _ string, _ []byte, functionName string, _ ...interface{}) (interface{}, error) {
+	r.calls++
+	switch functionName {
+	case FunctionName:
+		return r.name, nil
+	case FunctionDecimals:
+		return r.decimals, nil
+	case FunctionVersion:
+		if r.version == "" {
+			return nil, fmt.Errorf("execution reverted")
+		}
+		return r.version, nil
+	}
+	return nil, fmt.Errorf("unexpected function %s", functionName)
+}
+
+// TestResolveNetworkConfigSynthesized tests that unlisted eip155 chains get a config built from on-chain metadata
+func TestResolveNetworkConfigSynthesized(t *testing.T) {
+	ctx := context.Background()
+	reader := &metadataReader{name: "Test Dollar", decimals: 18}
+	network := "eip155:999999"
+	asset := "erc20:0x1111111111111111111111111111111111111111"
+
+	if _, err := GetNetworkConfig(network); err == nil {
+		t.Fatalf("Expected %s to be missing from NetworkConfigs", network)
+	}
+
+	config, err := ResolveNetworkConfig(ctx, reader, network, asset)
+	if err != nil {
+		t.Fatalf("ResolveNetworkConfig error: %v", err)
+	}
+	if config.ChainID.Int64() != 999999 {
+		t.Errorf("Expected chain ID 999999, got %d", config.ChainID.Int64())
+	}
+	if config.DefaultAsset.Name != "Test Dollar" || config.DefaultAsset.Decimals != 18 {
+		t.Errorf("Unexpected asset metadata: %+v", config.DefaultAsset)
+	}
+	if config.DefaultAsset.Version != "1" {
+		t.Errorf("Expected version fallback \"1\", got %q", config.DefaultAsset.Version)
+	}
+
+	// Second lookup must be served from the cache
+	calls := reader.calls
+	assetInfo, err := ResolveAssetInfo(ctx, reader, network, asset)
+	if err != nil {
+		t.Fatalf("ResolveAssetInfo error: %v", err)
+	}
+	if reader.calls != calls {
+		t.Errorf("Expected cached config, got %d extra reads", reader.calls-calls)
+	}
+	if assetInfo.Decimals != 18 {
+		t.Errorf("Expected 18 decimals, got %d", assetInfo.Decimals)
+	}
+
+	// Symbol assets cannot be synthesized
+	if _, err := ResolveNetworkConfig(ctx, reader, "eip155:999998", "USDC"); err == nil {
+		t.Error("Expected error for symbol asset on unlisted network")
+	}
+}
+
+// chainMetadataReader is a metadataReader that also reports the chain it is connected to
+type chainMetadataReader struct {
+	metadataReader
+	chainID int64
+}
+
+func (r *chainMetadataReader) GetChainID(_ context.Context) (*big.Int, error) {
+	return big.NewInt(r.chainID), nil
+}
+
+// TestResolveNetworkConfigChainIDMismatch tests that metadata is not read or cached through a reader on another chain
+func TestResolveNetworkConfigChainIDMismatch(t *testing.T) {
+	ctx := context.Background()
+	network := "eip155:999997"
+	asset := "erc20:0x3333333333333333333333333333333333333333"
+
+	wrongChain := &chainMetadataReader{metadataReader: metadataReader{name: "Other Dollar", decimals: 6}, chainID: 1}
+	_, err := ResolveNetworkConfig(ctx, wrongChain, network, asset)
+	if !errors.Is(err, ErrChainIDMismatch) {
+		t.Fatalf("Expected ErrChainIDMismatch, got %v", err)
+	}
+	if _, err := ResolveAssetInfo(ctx, wrongChain, network, asset); !errors.Is(err, ErrChainIDMismatch) {
+		t.Errorf("Expected ErrChainIDMismatch from ResolveAssetInfo, got %v", err)
+	}
+	if wrongChain.calls != 0 {
+		t.Errorf("Expected no metadata reads, got %d", wrongChain.calls)
+	}
+
+	// Nothing was cached, so a reader on the right chain reads its own metadata
+	rightChain := &chainMetadataReader{metadataReader: metadataReader{name: "Test Dollar", decimals: 18}, chainID: 999997}
+	config, err := ResolveNetworkConfig(ctx, rightChain, network, asset)
+	if err != nil {
+		t.Fatalf("ResolveNetworkConfig error: %v", err)
+	}
+	if config.DefaultAsset.Name != "Test Dollar" {
+		t.Errorf("Expected metadata from the matching chain, got %q", config.DefaultAsset.Name)
+	}
+}
+
+// TestGetAssetInfoByAddress tests that any configured asset resolves by address with its own decimals
+func TestGetAssetInfoByAddress(t *testing.T) {
+	tests := []struct {
+		name         string
+		network      string
+		asset        string
+		wantName     string
+		wantDecimals int
+	}{
+		{name: "mainnet USDC", network: "eip155:1", asset: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", wantName: "USD Coin", wantDecimals: 6},
+		{name: "mainnet USDT", network: "eip155:1", asset: "0xdAC17F958D2ee523a2206206994597C13D831ec7", wantName: "Tether USD", wantDecimals: 6},
+		{name: "mainnet DAI", network: "eip155:1", asset: "0x6b175474e89094c44da98b954eedeac495271d0f", wantName: "Dai Stablecoin", wantDecimals: 18},
+		{name: "base DAI erc20 prefix", network: "eip155:8453", asset: "erc20:0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb", wantName: "Dai Stablecoin", wantDecimals: 18},
+		{name: "base USDT", network: "base", asset: "0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2", wantName: "Tether USD", wantDecimals: 6},
+		{name: "base DAI CAIP-19", network: "eip155:8453", asset: "eip155:8453/erc20:0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb", wantName: "Dai Stablecoin", wantDecimals: 18},
+		{name: "base USDT CAIP-19 with alias", network: "base", asset: "eip155:8453/erc20:0xfde4C96c8593536E31F229EA8f37b2ADa2699bb2", wantName: "Tether USD", wantDecimals: 6},
+		{name: "unknown token", network: "eip155:1", asset: "0x1111111111111111111111111111111111111111", wantName: "Unknown Token", wantDecimals: 18},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset, err := GetAssetInfo(tt.network, tt.asset)
+			if err != nil {
+				t.Fatalf("GetAssetInfo error: %v", err)
+			}
+			if asset.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", asset.Name, tt.wantName)
+			}
+			if asset.Decimals != tt.wantDecimals {
+				t.Errorf("Decimals = %d, want %d", asset.Decimals, tt.wantDecimals)
+			}
+		})
+	}
+}
+
+// TestRegisterAsset tests registering custom assets at runtime
+func TestGetAssetInfoCAIP19Mismatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		asset string
+	}{
+		{name: "other chain", asset: "eip155:1/erc20:0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb"},
+		{name: "not an ERC-20", asset: "eip155:8453/slip44:60"},
+		{name: "malformed", asset: "eip155:8453/erc20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GetAssetInfo("eip155:8453", tt.asset); err == nil {
+				t.Errorf("GetAssetInfo(%q) expected an error", tt.asset)
+			}
+		})
+	}
+}
+
+func TestRegisterAsset(t *testing.T) {
+	restoreNetworkConfigs(t)
+	original, _ := GetNetworkConfig("eip155:84532")
+
+	info := AssetInfo{
+		Address:  "0x2222222222222222222222222222222222222222",
+		Name:     "Example Dollar",
+		Version:  "1",
+		Decimals: 18,
+	}
+
+	if err := RegisterAsset("base-sepolia", "exd", info); err != nil {
+		t.Fatalf("RegisterAsset error: %v", err)
+	}
+
+	bySymbol, err := GetAssetInfo("eip155:84532", "EXD")
+	if err != nil || bySymbol.Address != info.Address {
+		t.Fatalf("Expected registered asset by symbol, got %+v (err %v)", bySymbol, err)
+	}
+	byAddress, err := GetAssetInfo("eip155:84532", info.Address)
+	if err != nil || byAddress.Decimals != 18 {
+		t.Fatalf("Expected registered asset by address, got %+v (err %v)", byAddress, err)
+	}
+	if _, ok := original.SupportedAssets["EXD"]; ok {
+		t.Error("Expected registration not to mutate the previous SupportedAssets map")
+	}
+
+	if err := RegisterDefaultAsset("eip155:84532", "EXD", info); err != nil {
+		t.Fatalf("RegisterDefaultAsset error: %v", err)
+	}
+	config, _ := GetNetworkConfig("eip155:84532")
+	if config.DefaultAsset.Address != info.Address {
+		t.Errorf("Expected default asset %s, got %s", info.Address, config.DefaultAsset.Address)
+	}
+
+	if err := RegisterAsset("eip155:999999", "EXD", info); err == nil {
+		t.Error("Expected error for unknown network")
+	}
+	info.Address = "not-an-address"
+	if err := RegisterAsset("eip155:84532", "BAD", info); err == nil {
+		t.Error("Expected error for invalid address")
+	}
+}
+
+// TestNetworkConfigsConcurrentAccess resolves configs on two networks while assets and
+// facilitator contracts are registered on them, as a multi-network facilitator does; run
+// with -race to catch unguarded access to NetworkConfigs
+func TestNetworkConfigsConcurrentAccess(t *testing.T) {
+	restoreNetworkConfigs(t)
+
+	const rounds = 50
+	networks := []string{"eip155:8453", "eip155:84532"}
+	contract := "0x1111111111111111111111111111111111111111"
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4*rounds*len(networks))
+	for _, network := range networks {
+		for i := 0; i < rounds; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				if _, err := GetAssetInfo(network, "USDC"); err != nil {
+					errs <- err
+				}
+				if _, err := GetEvmChainId(network); err != nil {
+					errs <- err
+				}
+				GetFacilitatorContractAddress(network)
+				GetConfiguredNetworks()
+			}()
+			go func() {
+				defer wg.Done()
+				info := AssetInfo{Address: fmt.Sprintf("0x%040x", i+1), Name: "Test", Version: "1", Decimals: 6}
+				if err := RegisterAsset(network, fmt.Sprintf("T%d", i), info); err != nil {
+					errs <- err
+				}
+				doc := fmt.Sprintf(`{"networks": {%q: {"facilitatorContract": %q}}}`, network, contract)
+				if err := LoadNetworkConfigs(strings.NewReader(doc)); err != nil {
+					errs <- err
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for _, network := range networks {
+		config, err := GetNetworkConfig(network)
+		if err != nil {
+			t.Fatalf("GetNetworkConfig error: %v", err)
+		}
+		if len(config.SupportedAssets) < rounds || config.FacilitatorAddress() != contract {
+			t.Errorf("Expected every registration on %s to apply, got %d assets and contract %s", network, len(config.SupportedAssets), config.FacilitatorAddress())
+		}
+	}
+}
+
+// TestSetAssetAddress tests the EVM_USDC_ADDRESS override, which must update alias entries
+// and leave the previous SupportedAssets map untouched for readers still holding it
+func TestSetAssetAddress(t *testing.T) {
+	restoreNetworkConfigs(t)
+	original, _ := GetNetworkConfig("eip155:84532")
+	originalUSDC := original.SupportedAssets["USDC"].Address
+	address := "0x4444444444444444444444444444444444444444"
+
+	setAssetAddress("eip155:84532", "USDC", address)
+	setAssetAddress("base-sepolia", "USDC", address)
+	setAssetAddress("eip155:999999", "USDC", address)
+
+	for _, network := range []string{"eip155:84532", "base-sepolia"} {
+		networkConfigsMu.RLock()
+		config := NetworkConfigs[network]
+		networkConfigsMu.RUnlock()
+		if config.DefaultAsset.Address != address || config.SupportedAssets["USDC"].Address != address {
+			t.Errorf("Expected USDC on %s at %s, got default %s and supported %s",
+				network, address, config.DefaultAsset.Address, config.SupportedAssets["USDC"].Address)
+		}
+	}
+	if original.SupportedAssets["USDC"].Address != originalUSDC {
+		t.Error("Expected the override not to mutate the previous SupportedAssets map")
+	}
+	if _, err := GetNetworkConfig("eip155:999999"); err == nil {
+		t.Error("Expected unknown networks to be left out")
+	}
+}
+
+// probeReader is a ContractReader that answers the EIP-3009 probes with fixed errors and counts calls
+type probeReader struct {
+	authorizationStateErr error
+	transferErr           error
+	batchErr              error
+	calls                 int
+}
+
+func (r *probeReader) ReadContract(_ context.Context, _ string, _ []byte, functionName string, _ ...interface{}) (interface{}, error) {
+	r.calls++
+	switch functionName {
+	case FunctionAuthorizationState:
+		if r.authorizationStateErr != nil {
+			return nil, r.authorizationStateErr
+		}
+		return false, nil
+	case FunctionTransferWithAuthorization:
+		return nil, r.transferErr
+	case FunctionSettlePaymentBatch:
+		return nil, r.batchErr
+	}
+	return nil, fmt.Errorf("unexpected function %s", functionName)
+}
+
+// revertError mimics a go-ethereum RPC revert error carrying revert data
+type revertError struct {
+	data string
+}
+
+func (e *revertError) Error() string          { return "execution reverted" }
+func (e *revertError) ErrorData() interface{} { return e.data }
+
+// TestSupportCacheTTL tests that cached probe results expire after the configured TTL
+func TestSupportCacheTTL(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cache := &SupportCache{now: func() time.Time { return now }}
+
+	cache.Store("a", true)
+	now = now.Add(DefaultSupportCacheTTL - time.Second)
+	if supported, ok := cache.Load("a"); !ok || !supported {
+		t.Fatalf("Expected cached entry before default TTL, got %v %v", supported, ok)
+	}
+	now = now.Add(time.Second)
+	if _, ok := cache.Load("a"); ok {
+		t.Error("Expected entry to expire after default TTL")
+	}
+
+	cache.SetTTL(time.Minute)
+	cache.Store("b", false)
+	now = now.Add(time.Minute)
+	if _, ok := cache.Load("b"); ok {
+		t.Error("Expected entry to expire after custom TTL")
+	}
+
+	cache.Store("c", true)
+	cache.Delete("c")
+	if _, ok := cache.Load("c"); ok {
+		t.Error("Expected deleted entry to be gone")
+	}
+}
+
+// TestVerifyEIP3009Support tests how probe results are classified
+func TestVerifyEIP3009Support(t *testing.T) {
+	reverted := fmt.Errorf("execution reverted")
+	tests := []struct {
+		name      string
+		reader    *probeReader
+		supported bool
+		wantErr   bool
+	}{
+		{"authorizationState succeeds", &probeReader{}, true, false},
+		{"EIP-3009 revert reason", &probeReader{authorizationStateErr: reverted, transferErr: fmt.Errorf("execution reverted: FiatTokenV2: invalid signature")}, true, false},
+		{"custom error", &probeReader{authorizationStateErr: reverted, transferErr: &revertError{data: "0x8baa579f"}}, true, false},
+		{"bare revert", &probeReader{authorizationStateErr: reverted, transferErr: reverted}, false, false},
+		{"Error(string) from fallback", &probeReader{authorizationStateErr: reverted, transferErr: &revertError{data: "0x08c379a0"}}, false, false},
+		{"empty result", &probeReader{authorizationStateErr: fmt.Errorf("failed to unpack result: abi: attempting to unmarshall an empty string while arguments are expected"), transferErr: fmt.Errorf("failed to unpack result")}, false, false},
+		{"RPC failure", &probeReader{authorizationStateErr: fmt.Errorf("dial tcp: connection refused")}, false, true},
+		{"RPC failure on fallback probe", &probeReader{authorizationStateErr: reverted, transferErr: fmt.Errorf("429 Too Many Requests")}, false, true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chainID := big.NewInt(int64(999900 + i))
+			token := "0x4444444444444444444444444444444444444444"
+			defer InvalidateEIP3009Support(chainID, token)
+
+			supported, err := VerifyEIP3009Support(context.Background(), tt.reader, chainID, token, token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyEIP3009Support() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if supported != tt.supported {
+				t.Errorf("VerifyEIP3009Support() = %v, want %v", supported, tt.supported)
+			}
+			if _, cached := EIP3009SupportCache.Load(eip3009SupportCacheKey(chainID, token)); cached == tt.wantErr {
+				t.Errorf("Expected cached = %v", !tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestInvalidateEIP3009Support tests that invalidation forces VerifyEIP3009Support to probe again
+func TestInvalidateEIP3009Support(t *testing.T) {
+	ctx := context.Background()
+	chainID := big.NewInt(999997)
+	token := "0x2222222222222222222222222222222222222222"
+	from := "0x3333333333333333333333333333333333333333"
+	defer InvalidateEIP3009Support(chainID, token)
+
+	// Before a proxy upgrade the token has no EIP-3009 functions
+	reverted := fmt.Errorf("execution reverted")
+	reader := &probeReader{authorizationStateErr: reverted, transferErr: reverted}
+	supported, err := VerifyEIP3009Support(ctx, reader, chainID, from, token)
+	if err != nil || supported {
+		t.Fatalf("Expected unsupported, got %v %v", supported, err)
+	}
+
+	reader.authorizationStateErr = nil
+	calls := reader.calls
+	if supported, _ := VerifyEIP3009Support(ctx, reader, chainID, from, token); supported || reader.calls != calls {
+		t.Fatalf("Expected cached result without a new probe, got %v after %d calls", supported, reader.calls-calls)
+	}
+
+	// Invalidation is case-insensitive on the token address
+	InvalidateEIP3009Support(chainID, "0X2222222222222222222222222222222222222222")
+	if supported, _ := VerifyEIP3009Support(ctx, reader, chainID, from, token); !supported || reader.calls == calls {
+		t.Errorf("Expected fresh probe to report support, got %v", supported)
+	}
+}
+
+// TestVerifyBatchSettlementSupport tests that only definitive probe results are cached
+func TestVerifyBatchSettlementSupport(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchErr  error
+		supported bool
+		wantErr   bool
+	}{
+		{"empty batch accepted", nil, true, false},
+		{"unknown selector", fmt.Errorf("execution reverted"), false, false},
+		{"empty result", fmt.Errorf("failed to unpack result"), false, false},
+		{"RPC failure", fmt.Errorf("dial tcp: connection refused"), false, true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chainID := big.NewInt(int64(999800 + i))
+			contract := "0x5555555555555555555555555555555555555555"
+			cacheKey := fmt.Sprintf("%s:%s", chainID, contract)
+			defer BatchSettlementSupportCache.Delete(cacheKey)
+
+			supported, err := VerifyBatchSettlementSupport(context.Background(), &probeReader{batchErr: tt.batchErr}, chainID, contract)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyBatchSettlementSupport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if supported != tt.supported {
+				t.Errorf("VerifyBatchSettlementSupport() = %v, want %v", supported, tt.supported)
+			}
+			if _, cached := BatchSettlementSupportCache.Load(cacheKey); cached == tt.wantErr {
+				t.Errorf("Expected cached = %v", !tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateRequirementAddresses tests EIP-55 checksum validation of payTo and asset addresses
+func TestValidateRequirementAddresses(t *testing.T) {
+	const checksummed = "0x036CbD53842c5426634e7929541eC2318f3dCF7e"
+	const badChecksum = "0x036cbD53842c5426634e7929541eC2318f3dCF7e"
+
+	if got, err := ToChecksumAddress("0x036cbd53842c5426634e7929541ec2318f3dcf7e"); err != nil || got != checksummed {
+		t.Errorf("ToChecksumAddress() = %s, %v, want %s", got, err, checksummed)
+	}
+	if _, err := ToChecksumAddress("0x1234"); err == nil {
+		t.Error("Expected error for short address")
+	}
+
+	tests := []struct {
+		name    string
+		payTo   string
+		asset   string
+		wantErr bool
+	}{
+		{"checksummed", checksummed, checksummed, false},
+		{"lowercase", "0x036cbd53842c5426634e7929541ec2318f3dcf7e", "USDC", false},
+		{"uppercase", "0x036CBD53842C5426634E7929541EC2318F3DCF7E", "", false},
+		{"bad payTo checksum", badChecksum, "USDC", true},
+		{"bad asset checksum", checksummed, badChecksum, true},
+		{"bad erc20 asset checksum", checksummed, "erc20:" + badChecksum, true},
+		{"CAIP-19 asset", checksummed, "eip155:84532/erc20:" + checksummed, false},
+		{"bad CAIP-19 asset checksum", checksummed, "eip155:84532/erc20:" + badChecksum, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRequirementAddresses(tt.payTo, tt.asset)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateRequirementAddresses() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizedAmount(t *testing.T) {
+	oneUSDC, err := NormalizedAmount("1000000", 6)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	oneDAI, err := NormalizedAmount("1000000000000000000", 18)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if oneUSDC.Cmp(oneDAI) != 0 {
+		t.Errorf("Expected 1 USDC and 1 DAI to compare equal, got %s and %s", oneUSDC, oneDAI)
+	}
+
+	tests := []struct {
+		name     string
+		amount   string
+		decimals int
+		want     string
+		wantErr  bool
+	}{
+		{"usdc cents", "10000", 6, "1/100", false},
+		{"dai cents", "10000000000000000", 18, "1/100", false},
+		{"fractional dai", "1500000000000000000", 18, "3/2", false},
+		{"zero decimals", "42", 0, "42", false},
+		{"zero", "0", 18, "0", false},
+		{"decimal string", "1.5", 6, "", true},
+		{"negative", "-1", 6, "", true},
+		{"negative decimals", "1", -1, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizedAmount(tt.amount, tt.decimals)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizedAmount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.RatString() != tt.want {
+				t.Errorf("NormalizedAmount() = %s, want %s", got.RatString(), tt.want)
+			}
+		})
+	}
+
+	// A smaller raw amount of an 18-decimal token can still be worth more
+	usdc, _ := NormalizedAmount("2000000", 6)
+	dai, _ := NormalizedAmount("1999999999999999999", 18)
+	if dai.Cmp(usdc) >= 0 {
+		t.Errorf("Expected %s DAI to be less than %s USDC", dai, usdc)
+	}
+}
+
+func TestFindMinAmount(t *testing.T) {
+	usdc := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	minimums := []MinAmount{
+		{Network: "base", Amount: big.NewInt(100)},
+		{Network: "eip155:8453", Asset: "USDC", Amount: big.NewInt(1000)},
+		{Network: "eip155:8453", Asset: "not-a-token", Amount: nil},
+	}
+
+	if got := FindMinAmount(minimums, "eip155:8453", strings.ToLower(usdc)); got == nil || got.Int64() != 1000 {
+		t.Errorf("Expected the USDC minimum of 1000, got %v", got)
+	}
+	if got := FindMinAmount(minimums, "base", "0x1111111111111111111111111111111111111111"); got == nil || got.Int64() != 100 {
+		t.Errorf("Expected the network-wide minimum of 100, got %v", got)
+	}
+	if got := FindMinAmount(minimums, "eip155:1", usdc); got != nil {
+		t.Errorf("Expected no minimum on another network, got %v", got)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		decimals int
+		want     string
+		wantErr  bool
+	}{
+		{"whole", "1", 6, "1000000", false},
+		{"fractional", "1.5", 6, "1500000", false},
+		{"leading zero", "0.000001", 6, "1", false},
+		{"full precision", "1.123456789012345678", 18, "1123456789012345678", false},
+		{"trailing zeros past precision", "1.500000000", 6, "1500000", false},
+		{"zero decimals", "42", 0, "42", false},
+		{"zero decimals with zero fraction", "42.0", 0, "42", false},
+		{"too many decimals", "1.0000001", 6, "", true},
+		{"trailing dot", "1.", 6, "", true},
+		{"missing integer part", ".5", 6, "", true},
+		{"negative", "-5", 6, "", true},
+		{"leading plus", "+5", 6, "", true},
+		{"empty", "", 6, "", true},
+		{"two dots", "1.2.3", 6, "", true},
+		{"non-numeric", "1e6", 6, "", true},
+		{"signed fraction", "1.-5", 6, "", true},
+		{"negative decimals", "1", -1, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAmount(tt.amount, tt.decimals)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAmount(%q) error = %v, wantErr %v", tt.amount, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseAmount(%q) = %s, want %s", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   *big.Int
+		decimals int
+		want     string
+	}{
+		{big.NewInt(1500000), 6, "1.5"},
+		{big.NewInt(1), 6, "0.000001"},
+		{big.NewInt(1000000), 6, "1"},
+		{big.NewInt(42), 0, "42"},
+		{big.NewInt(-1500000), 6, "-1.5"},
+		{nil, 6, "0"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatAmount(tt.amount, tt.decimals); got != tt.want {
+			t.Errorf("FormatAmount(%v, %d) = %s, want %s", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+// FuzzParseAmountRoundTrip checks that every amount ParseAmount accepts formats back to a
+// string that parses to the same value
+func FuzzParseAmountRoundTrip(f *testing.F) {
+	for _, seed := range []string{"0", "1", "1.5", "0.000001", "1.", ".5", "-5", "+5", "1.0000001", "1e6", "00.10"} {
+		f.Add(seed, uint8(6))
+	}
+	f.Add("1.123456789012345678", uint8(18))
+	f.Add("42", uint8(0))
+
+	f.Fuzz(func(t *testing.T, amount string, decimals uint8) {
+		d := int(decimals % 40)
+		parsed, err := ParseAmount(amount, d)
+		if err != nil {
+			return
+		}
+		if parsed.Sign() < 0 {
+			t.Fatalf("ParseAmount(%q, %d) = %s, want a non-negative amount", amount, d, parsed)
+		}
+
+		formatted := FormatAmount(parsed, d)
+		reparsed, err := ParseAmount(formatted, d)
+		if err != nil {
+			t.Fatalf("ParseAmount(FormatAmount(%s)) = %q failed: %v", parsed, formatted, err)
+		}
+		if reparsed.Cmp(parsed) != 0 {
+			t.Fatalf("round trip of %q with %d decimals: %s -> %q -> %s", amount, d, parsed, formatted, reparsed)
+		}
+	})
+}
+
+func TestCreateValidityWindowVerifiesImmediately(t *testing.T) {
+	validAfter, validBefore := CreateValidityWindow(time.Minute)
+	now := time.Now().Unix()
+
+	if err := CheckValidityWindow(validAfter, validBefore, now, ClockSkewTolerance); err != nil {
+		t.Errorf("Expected a freshly created window to be valid at t=0, got %v", err)
+	}
+
+	// A facilitator whose clock lags the client's by up to the tolerance still accepts it
+	lagging := now - int64(ClockSkewTolerance.Seconds())
+	if err := CheckValidityWindow(validAfter, validBefore, lagging, ClockSkewTolerance); err != nil {
+		t.Errorf("Expected the window to be valid on a lagging clock, got %v", err)
+	}
+}
+
+func TestCheckValidityWindow(t *testing.T) {
+	const now = 1700000000
+	tolerance := 30 * time.Second
+
+	tests := []struct {
+		name        string
+		validAfter  int64
+		validBefore int64
+		wantErr     error
+	}{
+		{"inside", now - 60, now + 60, nil},
+		{"validAfter within tolerance", now + 30, now + 60, nil},
+		{"validAfter beyond tolerance", now + 31, now + 60, ErrAuthorizationNotYetValid},
+		{"validBefore within tolerance", now - 60, now - 29, nil},
+		{"validBefore beyond tolerance", now - 60, now - 30, ErrAuthorizationExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckValidityWindow(big.NewInt(tt.validAfter), big.NewInt(tt.validBefore), now, tolerance)
+			if err != tt.wantErr {
+				t.Errorf("CheckValidityWindow() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
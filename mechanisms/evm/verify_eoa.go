@@ -1,23 +1,76 @@
 package evm
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"math/big"
+
+	x402 "x402-go"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// Signature lengths accepted from externally owned accounts
+const (
+	EOASignatureLength     = 65 // r (32) || s (32) || v (1)
+	CompactSignatureLength = 64 // EIP-2098: r (32) || yParity+s (32)
+)
+
+var (
+	// ErrInvalidSignatureLength is returned for EOA signatures that are neither 64 nor 65 bytes
+	ErrInvalidSignatureLength = errors.New("invalid EOA signature length: expected 64 (EIP-2098) or 65 bytes")
+
+	// ErrInvalidSignatureV is returned for 65-byte signatures whose v is not 0, 1, 27 or 28
+	ErrInvalidSignatureV = errors.New("invalid EOA signature v value: expected 0, 1, 27 or 28")
+
+	// ErrMalleableSignature is returned by verification requiring low-S for signatures whose s
+	// is above half the curve order
+	ErrMalleableSignature = errors.New("malleable EOA signature: s is above half the curve order")
+
+	// secp256k1 curve order and half of it; s values above the half are malleable (EIP-2)
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// SignatureOption configures EOA signature verification
+type SignatureOption func(*signatureOptions)
+
+type signatureOptions struct {
+	requireLowS bool
+}
+
+// RequireLowS rejects EOA signatures whose s is above half the curve order with ErrMalleableSignature.
+// For every valid (r, s) signature, (r, n-s) with the other v is valid too; some on-chain verifiers
+// only accept the low-S form.
+func RequireLowS() SignatureOption {
+	return func(o *signatureOptions) {
+		o.requireLowS = true
+	}
+}
+
+func newSignatureOptions(opts []SignatureOption) signatureOptions {
+	var o signatureOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
 // VerifyEOASignature verifies an ECDSA signature from an externally owned account (EOA)
 //
 // This function uses secp256k1 public key recovery to verify that the signature
-// was created by the expected address. It handles the Ethereum-specific v value
-// adjustment (27/28 → 0/1 for recovery).
+// was created by the expected address. It accepts EIP-2098 compact signatures and
+// handles the Ethereum-specific v value adjustment (27/28 → 0/1 for recovery).
 //
 // Args:
 //
 //	hash: The 32-byte message hash that was signed
 //	signature: The 65-byte ECDSA signature (r: 32 bytes, s: 32 bytes, v: 1 byte)
+//	           or a 64-byte EIP-2098 compact signature
 //	expectedAddress: The Ethereum address that should have signed the message
+//	opts: Verification options (e.g. RequireLowS)
 //
 // Returns:
 //
@@ -27,21 +80,20 @@ func VerifyEOASignature(
 	hash []byte,
 	signature []byte,
 	expectedAddress common.Address,
+	opts ...SignatureOption,
 ) (bool, error) {
-	if len(signature) != 65 {
-		return false, errors.New("invalid EOA signature length: expected 65 bytes")
+	sig, err := ExpandSignature(signature)
+	if err != nil {
+		return false, err
 	}
 
-	// Create a copy to avoid modifying the original signature
-	sig := make([]byte, 65)
-	copy(sig, signature)
+	if newSignatureOptions(opts).requireLowS && !IsLowS(sig) {
+		return false, ErrMalleableSignature
+	}
 
 	// Adjust v value for recovery
 	// Ethereum uses v = 27 or 28, but crypto.SigToPub expects v = 0 or 1
-	v := sig[64]
-	if v >= 27 {
-		sig[64] = v - 27
-	}
+	sig[64] -= 27
 
 	// Recover the public key from the signature
 	pubKey, err := crypto.SigToPub(hash, sig)
@@ -55,3 +107,113 @@ func VerifyEOASignature(
 	// Compare the recovered address with the expected address
 	return recoveredAddress == expectedAddress, nil
 }
+
+// ExpandSignature returns a copy of an EOA signature in the 65-byte r || s || v form with
+// v = 27 or 28. EIP-2098 compact signatures are expanded, with v taken from the top bit of
+// their second word; 65-byte signatures with v = 0 or 1 are shifted to 27 or 28.
+func ExpandSignature(signature []byte) ([]byte, error) {
+	sig := make([]byte, EOASignatureLength)
+
+	switch len(signature) {
+	case CompactSignatureLength:
+		copy(sig, signature)
+		sig[64] = 27 + sig[32]>>7
+		sig[32] &= 0x7f
+	case EOASignatureLength:
+		copy(sig, signature)
+		switch v := sig[64]; v {
+		case 0, 1:
+			sig[64] = v + 27
+		case 27, 28:
+		default:
+			return nil, fmt.Errorf("%w, got %d", ErrInvalidSignatureV, v)
+		}
+	default:
+		return nil, fmt.Errorf("%w, got %d", ErrInvalidSignatureLength, len(signature))
+	}
+
+	return sig, nil
+}
+
+// SignatureComponents is an EOA signature split into the arguments of the v,r,s overloads of
+// transferWithAuthorization and receiveWithAuthorization
+type SignatureComponents struct {
+	V uint8 // 27 or 28
+	R [32]byte
+	S [32]byte
+}
+
+// SplitSignature splits an EOA signature into v, r and s. EIP-2098 compact signatures and
+// v = 0 or 1 are handled as by ExpandSignature.
+func SplitSignature(signature []byte) (SignatureComponents, error) {
+	sig, err := ExpandSignature(signature)
+	if err != nil {
+		return SignatureComponents{}, err
+	}
+	return SignatureComponents{
+		V: sig[64],
+		R: [32]byte(sig[0:32]),
+		S: [32]byte(sig[32:64]),
+	}, nil
+}
+
+// IsLowS reports whether an expanded 65-byte EOA signature has s in the lower half of the curve order
+func IsLowS(signature []byte) bool {
+	if len(signature) != EOASignatureLength {
+		return false
+	}
+	return new(big.Int).SetBytes(signature[32:64]).Cmp(secp256k1HalfN) <= 0
+}
+
+// NormalizeSignatureLowS returns a 65-byte EOA signature in its low-S form: a high s is replaced
+// by n-s and v flipped, which recovers the same signer. Other signatures, including smart wallet
+// and EIP-2098 compact ones, are returned unchanged.
+func NormalizeSignatureLowS(signature []byte) []byte {
+	if len(signature) != EOASignatureLength || IsLowS(signature) {
+		return signature
+	}
+
+	normalized := make([]byte, EOASignatureLength)
+	copy(normalized, signature)
+	s := new(big.Int).SetBytes(signature[32:64])
+	new(big.Int).Sub(secp256k1N, s).FillBytes(normalized[32:64])
+	// v is 0/1 or 27/28; swap the parity within whichever form the signature uses
+	if normalized[64] >= 27 {
+		normalized[64] = 55 - normalized[64]
+	} else {
+		normalized[64] ^= 1
+	}
+	return normalized
+}
+
+// ExpandCompactEOASignature expands an EIP-2098 compact signature from an EOA to the 65-byte
+// form that tokens and the facilitator contract expect. Signatures of any other length, and
+// 64-byte signatures from smart contract wallets (signer has code), are returned unchanged.
+func ExpandCompactEOASignature(ctx context.Context, reader FacilitatorEvmSigner, signer string, signature []byte) ([]byte, error) {
+	if len(signature) != CompactSignatureLength {
+		return signature, nil
+	}
+
+	code, err := reader.GetCode(ctx, signer)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) > 0 {
+		return signature, nil
+	}
+
+	return ExpandSignature(signature)
+}
+
+// SignatureErrorReason returns the verify failure reason for a signature verification error:
+// ReasonInvalidSignatureFormat for malformed EOA signatures, ReasonMalleableSignature for high-S
+// signatures rejected by RequireLowS, ReasonFailedToVerifySignature otherwise
+func SignatureErrorReason(err error) string {
+	if errors.Is(err, ErrInvalidSignatureLength) || errors.Is(err, ErrInvalidSignatureV) {
+		return x402.ReasonInvalidSignatureFormat
+	}
+	if errors.Is(err, ErrMalleableSignature) {
+		return x402.ReasonMalleableSignature
+	}
+	return x402.ReasonFailedToVerifySignature
+}
@@ -1,8 +1,13 @@
 package evm
 
 import (
+	"bytes"
+	"errors"
+	"math/big"
 	"testing"
 
+	x402 "x402-go"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
@@ -43,7 +48,7 @@ func TestVerifyEOASignature(t *testing.T) {
 			wantErr:         false,
 		},
 		{
-			name:            "invalid signature length (64 bytes)",
+			name:            "invalid compact signature (64 zero bytes)",
 			hash:            testHash,
 			signature:       func() []byte { return make([]byte, 64) },
 			expectedAddress: address,
@@ -141,3 +146,157 @@ func TestVerifyEOASignature_VValueAdjustment(t *testing.T) {
 		}
 	})
 }
+
+// compactSignature converts a 65-byte signature with v = 0 or 1 to EIP-2098 compact form
+func compactSignature(sig []byte) []byte {
+	compact := make([]byte, 64)
+	copy(compact, sig[:64])
+	compact[32] |= sig[64] << 7
+	return compact
+}
+
+// TestVerifyEOASignature_Compact tests EIP-2098 compact signatures for both y parities
+func TestVerifyEOASignature_Compact(t *testing.T) {
+	privateKey, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	seen := map[byte]bool{}
+	for i := 0; len(seen) < 2 && i < 64; i++ {
+		hash := crypto.Keccak256([]byte{byte(i)})
+		sig, _ := crypto.Sign(hash, privateKey)
+		seen[sig[64]] = true
+
+		compact := compactSignature(sig)
+		got, err := VerifyEOASignature(hash, compact, address)
+		if err != nil || !got {
+			t.Fatalf("VerifyEOASignature(compact, v=%d) = %v, %v; want true", sig[64], got, err)
+		}
+
+		expanded, err := ExpandSignature(compact)
+		if err != nil {
+			t.Fatalf("ExpandSignature() error = %v", err)
+		}
+		if !bytes.Equal(expanded[:64], sig[:64]) || expanded[64] != sig[64]+27 {
+			t.Errorf("ExpandSignature() = %x, want %x with v=%d", expanded, sig[:64], sig[64]+27)
+		}
+	}
+	if len(seen) < 2 {
+		t.Fatal("expected signatures with both y parities")
+	}
+}
+
+// TestExpandSignature_Errors tests that malformed signatures are rejected with dedicated errors
+func TestExpandSignature_Errors(t *testing.T) {
+	invalidV := make([]byte, 65)
+	invalidV[64] = 29
+
+	tests := []struct {
+		name      string
+		signature []byte
+		wantErr   error
+	}{
+		{name: "v = 29", signature: invalidV, wantErr: ErrInvalidSignatureV},
+		{name: "63 bytes", signature: make([]byte, 63), wantErr: ErrInvalidSignatureLength},
+		{name: "66 bytes", signature: make([]byte, 66), wantErr: ErrInvalidSignatureLength},
+		{name: "empty", signature: nil, wantErr: ErrInvalidSignatureLength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExpandSignature(tt.signature)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ExpandSignature() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := VerifyEOASignature(make([]byte, 32), tt.signature, common.Address{}); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyEOASignature() error = %v, want %v", err, tt.wantErr)
+			}
+			if reason := SignatureErrorReason(err); reason != x402.ReasonInvalidSignatureFormat {
+				t.Errorf("SignatureErrorReason() = %q, want %q", reason, x402.ReasonInvalidSignatureFormat)
+			}
+		})
+	}
+}
+
+// TestSplitSignature tests that signatures are split into r, s and a v of 27 or 28
+func TestSplitSignature(t *testing.T) {
+	signature := make([]byte, 65)
+	for i := range 64 {
+		signature[i] = byte(i + 1)
+	}
+	signature[64] = 1
+
+	components, err := SplitSignature(signature)
+	if err != nil {
+		t.Fatalf("SplitSignature() failed: %v", err)
+	}
+	if components.V != 28 {
+		t.Errorf("V = %d, want 28", components.V)
+	}
+	if !bytes.Equal(components.R[:], signature[0:32]) || !bytes.Equal(components.S[:], signature[32:64]) {
+		t.Errorf("Unexpected r, s: %x, %x", components.R, components.S)
+	}
+
+	// The compact form of the same signature splits the same way
+	compact := make([]byte, 64)
+	copy(compact, signature[:64])
+	compact[32] |= 0x80
+	if compactComponents, err := SplitSignature(compact); err != nil || compactComponents != components {
+		t.Errorf("SplitSignature(compact) = %+v, %v, want %+v", compactComponents, err, components)
+	}
+
+	if _, err := SplitSignature(make([]byte, 66)); !errors.Is(err, ErrInvalidSignatureLength) {
+		t.Errorf("Expected ErrInvalidSignatureLength, got %v", err)
+	}
+}
+
+// TestVerifyEOASignature_LowS tests that RequireLowS rejects the malleated form of a signature
+// and that NormalizeSignatureLowS restores the canonical one
+func TestVerifyEOASignature_LowS(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	hash := crypto.Keccak256([]byte("low-s"))
+
+	sig, err := crypto.Sign(hash, privateKey)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	sig[64] += 27
+
+	// Malleate: s -> n - s with the other recovery id
+	highS := make([]byte, 65)
+	copy(highS, sig)
+	s := new(big.Int).SetBytes(sig[32:64])
+	new(big.Int).Sub(secp256k1N, s).FillBytes(highS[32:64])
+	highS[64] = 55 - highS[64] // 27 <-> 28
+
+	if !IsLowS(sig) || IsLowS(highS) {
+		t.Fatal("Expected crypto.Sign to produce low-S and the malleated form to be high-S")
+	}
+
+	valid, err := VerifyEOASignature(hash, sig, address, RequireLowS())
+	if err != nil || !valid {
+		t.Errorf("Expected the low-S signature to verify, got %v, %v", valid, err)
+	}
+
+	_, err = VerifyEOASignature(hash, highS, address, RequireLowS())
+	if !errors.Is(err, ErrMalleableSignature) {
+		t.Fatalf("Expected ErrMalleableSignature, got %v", err)
+	}
+	if reason := SignatureErrorReason(err); reason != x402.ReasonMalleableSignature {
+		t.Errorf("SignatureErrorReason() = %q, want %q", reason, x402.ReasonMalleableSignature)
+	}
+
+	normalized := NormalizeSignatureLowS(highS)
+	if !bytes.Equal(normalized, sig) {
+		t.Errorf("NormalizeSignatureLowS() = %x, want %x", normalized, sig)
+	}
+	if !bytes.Equal(NormalizeSignatureLowS(sig), sig) {
+		t.Error("Expected a low-S signature to be returned unchanged")
+	}
+	if compact := make([]byte, 64); !bytes.Equal(NormalizeSignatureLowS(compact), compact) {
+		t.Error("Expected a compact signature to be returned unchanged")
+	}
+}
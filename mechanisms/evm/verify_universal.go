@@ -1,6 +1,7 @@
 package evm
 
 import (
+	"bytes"
 	"context"
 	"errors"
 
@@ -17,11 +18,11 @@ import (
 //
 // The verification flow:
 // 1. Parse ERC-6492 wrapper if present to extract inner signature
-// 2. If inner signature is exactly 65 bytes AND no factory: EOA path (optimization - skips GetCode)
-// 3. Otherwise: check if contract is deployed (GetCode)
+// 2. Check the signer's code (GetCode)
+// 3. If inner signature is exactly 65 bytes AND no factory AND no EIP-7702 delegation: EOA path
 // 4. If undeployed + has deployment info + allowUndeployed: accept (deploy in settle)
 // 5. If undeployed without deployment info: fallback to EOA verification
-// 6. If deployed: use EIP-1271 verification
+// 6. If deployed (including EIP-7702 delegated EOAs): use EIP-1271 verification
 //
 // Args:
 //
@@ -31,6 +32,7 @@ import (
 //	hash: The 32-byte message hash that was signed
 //	signature: The signature bytes (may be wrapped in ERC-6492 format)
 //	allowUndeployed: Whether to accept ERC-6492 signatures from undeployed wallets
+//	opts: EOA verification options (e.g. RequireLowS)
 //
 // Returns:
 //
@@ -44,6 +46,7 @@ func VerifyUniversalSignature(
 	hash [32]byte,
 	signature []byte,
 	allowUndeployed bool,
+	opts ...SignatureOption,
 ) (bool, *ERC6492SignatureData, error) {
 	// Step 1: Parse ERC-6492 wrapper if present
 	sigData, err := ParseERC6492Signature(signature)
@@ -51,26 +54,26 @@ func VerifyUniversalSignature(
 		return false, nil, err
 	}
 
-	// Step 2: Detect if this is likely a smart wallet signature
+	// Step 2: Check for code at the signer. An EOA that delegated its code via EIP-7702 signs
+	// 65-byte signatures like any EOA, but tokens verify them through EIP-1271.
+	code, err := facilitatorSigner.GetCode(ctx, signerAddress)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// Step 3: Detect if this is likely a smart wallet signature
 	// EOA signatures are exactly 65 bytes
 	// Smart wallet signatures can be any other length or have ERC-6492 deployment info
 	zeroFactory := [20]byte{}
 	isEOASignature := len(sigData.InnerSignature) == 65 && sigData.Factory == zeroFactory
 
-	// Step 3: If clearly an EOA signature, skip to EOA verification (optimization)
-	if isEOASignature {
-		// EOA signature - use ECDSA recovery directly (avoids GetCode call)
+	if isEOASignature && !IsEIP7702Delegated(code) {
+		// EOA signature - use ECDSA recovery directly
 		signerAddr := common.HexToAddress(signerAddress)
-		valid, err := VerifyEOASignature(hash[:], sigData.InnerSignature, signerAddr)
+		valid, err := VerifyEOASignature(hash[:], sigData.InnerSignature, signerAddr, opts...)
 		return valid, sigData, err
 	}
 
-	// Step 4: Potential smart wallet signature - check if contract is deployed
-	code, err := facilitatorSigner.GetCode(ctx, signerAddress)
-	if err != nil {
-		return false, nil, err
-	}
-
 	isDeployed := len(code) > 0
 
 	// Step 5: Handle undeployed address
@@ -92,7 +95,7 @@ func VerifyUniversalSignature(
 		// No deployment info - try EOA verification as fallback
 		// This handles the case where someone sends a non-65-byte signature from an EOA
 		signerAddr := common.HexToAddress(signerAddress)
-		valid, err := VerifyEOASignature(hash[:], sigData.InnerSignature, signerAddr)
+		valid, err := VerifyEOASignature(hash[:], sigData.InnerSignature, signerAddr, opts...)
 		return valid, sigData, err
 	}
 
@@ -106,3 +109,13 @@ func VerifyUniversalSignature(
 	)
 	return valid, sigData, err
 }
+
+// IsEIP7702Delegated reports whether code is an EIP-7702 delegation designator: the code of an
+// EOA that delegated to a smart account implementation, 0xef0100 followed by its address
+func IsEIP7702Delegated(code []byte) bool {
+	return len(code) == len(eip7702DelegationPrefix)+common.AddressLength &&
+		bytes.HasPrefix(code, eip7702DelegationPrefix)
+}
+
+// eip7702DelegationPrefix prefixes the code of EIP-7702 delegated EOAs
+var eip7702DelegationPrefix = []byte{0xef, 0x01, 0x00}
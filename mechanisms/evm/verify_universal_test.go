@@ -5,7 +5,6 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
@@ -141,6 +140,70 @@ func TestVerifyUniversalSignature_EIP1271(t *testing.T) {
 	})
 }
 
+func TestVerifyUniversalSignature_EIP7702(t *testing.T) {
+	ctx := context.Background()
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	testHash := crypto.Keccak256([]byte("test message"))
+	sig, err := crypto.Sign(testHash, privateKey)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	sig[64] += 27
+
+	var hash32 [32]byte
+	copy(hash32[:], testHash)
+
+	// Delegation designator: 0xef0100 followed by the implementation address
+	delegatedCode := append([]byte{0xef, 0x01, 0x00}, common.HexToAddress("0x63c0c19a282a1B52b07dD5a65b58948A07DAE32B").Bytes()...)
+
+	t.Run("65-byte signature is checked through EIP-1271", func(t *testing.T) {
+		mock := &mockFacilitatorSigner{
+			getCodeResult:      delegatedCode,
+			readContractResult: []byte{0x00, 0x00, 0x00, 0x00}, // The delegate rejects it
+		}
+
+		valid, _, err := VerifyUniversalSignature(ctx, mock, address.Hex(), hash32, sig, true)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if valid {
+			t.Error("expected the delegate's EIP-1271 answer, not ECDSA recovery")
+		}
+	})
+
+	t.Run("delegate accepts the signature", func(t *testing.T) {
+		mock := &mockFacilitatorSigner{
+			getCodeResult:      delegatedCode,
+			readContractResult: []byte{0x16, 0x26, 0xba, 0x7e},
+		}
+
+		valid, _, err := VerifyUniversalSignature(ctx, mock, address.Hex(), hash32, make([]byte, 65), true)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !valid {
+			t.Error("expected valid signature")
+		}
+	})
+
+	t.Run("designator detection", func(t *testing.T) {
+		if !IsEIP7702Delegated(delegatedCode) {
+			t.Error("expected delegation designator to be detected")
+		}
+		if IsEIP7702Delegated([]byte{0xef, 0x01, 0x00}) {
+			t.Error("expected a bare prefix not to be a designator")
+		}
+		if IsEIP7702Delegated(append([]byte{0x60, 0x80, 0x60}, make([]byte, 20)...)) {
+			t.Error("expected contract bytecode not to be a designator")
+		}
+	})
+}
+
 func TestVerifyUniversalSignature_ERC6492(t *testing.T) {
 	ctx := context.Background()
 	wallet := "0x1234567890123456789012345678901234567890"
@@ -151,7 +214,7 @@ func TestVerifyUniversalSignature_ERC6492(t *testing.T) {
 	innerSig := make([]byte, 65)
 
 	// Create valid ERC-6492 signature
-	erc6492Sig := createERC6492SignatureForTest(t, factory, factoryCalldata, innerSig)
+	erc6492Sig := WrapERC6492Signature(factory, factoryCalldata, innerSig)
 
 	t.Run("undeployed wallet with ERC-6492 and allowUndeployed=true", func(t *testing.T) {
 		// Mock signer that returns no code (undeployed)
@@ -279,28 +342,3 @@ func TestVerifyUniversalSignature_EdgeCases(t *testing.T) {
 		}
 	})
 }
-
-// Helper to create ERC-6492 signatures for testing
-func createERC6492SignatureForTest(t *testing.T, factory common.Address, factoryData []byte, originalSig []byte) []byte {
-	addressTy, err := abi.NewType("address", "", nil)
-	if err != nil {
-		t.Fatalf("failed to create address type: %v", err)
-	}
-	bytesTy, err := abi.NewType("bytes", "", nil)
-	if err != nil {
-		t.Fatalf("failed to create bytes type: %v", err)
-	}
-
-	arguments := abi.Arguments{
-		{Type: addressTy},
-		{Type: bytesTy},
-		{Type: bytesTy},
-	}
-
-	packed, err := arguments.Pack(factory, factoryData, originalSig)
-	if err != nil {
-		t.Fatalf("failed to pack: %v", err)
-	}
-
-	return append(packed, erc6492MagicBytes...)
-}
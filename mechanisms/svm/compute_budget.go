@@ -0,0 +1,126 @@
+package svm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	solana "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ComputeUnitPriceFunc returns the compute unit price, in microlamports, to ask clients for on a network
+type ComputeUnitPriceFunc func(ctx context.Context, network string) (uint64, error)
+
+// ComputeBudgetConfig sets the compute budget a facilitator asks clients to put in payment transactions.
+// The values are advertised through the supported kinds extra, copied into payment requirements by
+// the server, and used by the client when it builds the transaction. Zero values leave the client
+// defaults (DefaultComputeUnitLimit and DefaultComputeUnitPriceMicrolamports) in place.
+//
+// The client defaults are fine on devnet and testnet. On mainnet, transactions without a priority fee
+// can fail to land during congestion; use UnitPriceFunc with RecentPrioritizationFee (e.g. the 75th
+// percentile) so the price tracks current demand.
+type ComputeBudgetConfig struct {
+	// UnitLimit is the compute unit limit clients should set
+	UnitLimit uint32
+
+	// UnitPrice is a static compute unit price in microlamports
+	UnitPrice uint64
+
+	// UnitPriceFunc returns a dynamic compute unit price and takes precedence over UnitPrice.
+	// If it fails, UnitPrice is advertised instead.
+	UnitPriceFunc ComputeUnitPriceFunc
+
+	// MaxUnitPrice is the highest compute unit price Verify accepts, and caps the advertised price
+	// (defaults to MaxComputeUnitPriceMicrolamports)
+	MaxUnitPrice uint64
+}
+
+// MaxPrice returns the highest compute unit price the facilitator accepts
+func (c ComputeBudgetConfig) MaxPrice() uint64 {
+	if c.MaxUnitPrice == 0 {
+		return MaxComputeUnitPriceMicrolamports
+	}
+	return c.MaxUnitPrice
+}
+
+// AddToExtra writes the configured compute budget into a supported kind's extra
+func (c ComputeBudgetConfig) AddToExtra(ctx context.Context, network string, extra map[string]interface{}) {
+	if c.UnitLimit > 0 {
+		extra[ExtraComputeUnitLimit] = c.UnitLimit
+	}
+
+	price := c.UnitPrice
+	if c.UnitPriceFunc != nil {
+		ctx, cancel := context.WithTimeout(ctx, PriorityFeeQueryTimeout)
+		defer cancel()
+		if dynamic, err := c.UnitPriceFunc(ctx, network); err == nil {
+			price = dynamic
+		}
+	}
+	if price > 0 {
+		extra[ExtraComputeUnitPrice] = min(price, c.MaxPrice())
+	}
+}
+
+// ComputeBudgetFromExtra returns the compute unit limit and price requested in payment requirements extra,
+// falling back to DefaultComputeUnitLimit and DefaultComputeUnitPriceMicrolamports
+func ComputeBudgetFromExtra(extra map[string]interface{}) (limit uint32, price uint64) {
+	limit = DefaultComputeUnitLimit
+	price = DefaultComputeUnitPriceMicrolamports
+
+	if v, ok := extraUint(extra[ExtraComputeUnitLimit]); ok && v > 0 && v <= math.MaxUint32 {
+		limit = uint32(v)
+	}
+	if v, ok := extraUint(extra[ExtraComputeUnitPrice]); ok {
+		price = v
+	}
+	return limit, price
+}
+
+// extraUint reads a non-negative integer from an extra value, which is a float64 after a JSON round trip
+func extraUint(value interface{}) (uint64, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v < 0 || v != math.Trunc(v) || v >= math.MaxUint64 {
+			return 0, false
+		}
+		return uint64(v), true
+	case int:
+		return uint64(v), v >= 0
+	case int64:
+		return uint64(v), v >= 0
+	case uint32:
+		return uint64(v), true
+	case uint64:
+		return v, true
+	case json.Number:
+		n, err := v.Int64()
+		return uint64(n), err == nil && n >= 0
+	}
+	return 0, false
+}
+
+// RecentPrioritizationFee returns the given percentile (0-100) of the prioritization fees paid in recent
+// slots, in microlamports per compute unit. Passing the accounts a payment writes (such as the mint)
+// narrows the sample to transactions competing for the same locks. Returns 0 when no fees were paid.
+func RecentPrioritizationFee(ctx context.Context, rpcClient *rpc.Client, accounts []solana.PublicKey, percentile int) (uint64, error) {
+	results, err := rpcClient.GetRecentPrioritizationFees(ctx, accounts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get recent prioritization fees: %w", err)
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+
+	fees := make([]uint64, len(results))
+	for i, result := range results {
+		fees[i] = result.PrioritizationFee
+	}
+	sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+
+	percentile = max(0, min(percentile, 100))
+	return fees[(len(fees)-1)*percentile/100], nil
+}
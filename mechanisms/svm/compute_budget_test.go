@@ -0,0 +1,66 @@
+package svm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestComputeBudgetAddToExtra(t *testing.T) {
+	ctx := context.Background()
+
+	// Unconfigured budgets advertise nothing, leaving client defaults in place
+	extra := map[string]interface{}{}
+	ComputeBudgetConfig{}.AddToExtra(ctx, SolanaMainnetCAIP2, extra)
+	if len(extra) != 0 {
+		t.Errorf("Expected no compute budget in extra, got %v", extra)
+	}
+
+	// Dynamic price wins over the static one and is capped at the maximum
+	config := ComputeBudgetConfig{
+		UnitLimit: 20000,
+		UnitPrice: 1000,
+		UnitPriceFunc: func(ctx context.Context, network string) (uint64, error) {
+			return 9_000_000, nil
+		},
+	}
+	config.AddToExtra(ctx, SolanaMainnetCAIP2, extra)
+	if extra[ExtraComputeUnitLimit] != uint32(20000) {
+		t.Errorf("Expected compute unit limit 20000, got %v", extra[ExtraComputeUnitLimit])
+	}
+	if extra[ExtraComputeUnitPrice] != uint64(MaxComputeUnitPriceMicrolamports) {
+		t.Errorf("Expected capped compute unit price, got %v", extra[ExtraComputeUnitPrice])
+	}
+
+	// A failing lookup falls back to the static price
+	config.UnitPriceFunc = func(ctx context.Context, network string) (uint64, error) {
+		return 0, errors.New("rpc unavailable")
+	}
+	config.AddToExtra(ctx, SolanaMainnetCAIP2, extra)
+	if extra[ExtraComputeUnitPrice] != uint64(1000) {
+		t.Errorf("Expected static compute unit price, got %v", extra[ExtraComputeUnitPrice])
+	}
+}
+
+func TestComputeBudgetFromExtra(t *testing.T) {
+	tests := []struct {
+		name      string
+		extra     map[string]interface{}
+		wantLimit uint32
+		wantPrice uint64
+	}{
+		{"defaults", nil, DefaultComputeUnitLimit, DefaultComputeUnitPriceMicrolamports},
+		{"json numbers", map[string]interface{}{ExtraComputeUnitLimit: float64(20000), ExtraComputeUnitPrice: float64(50000)}, 20000, 50000},
+		{"in-process values", map[string]interface{}{ExtraComputeUnitLimit: uint32(15000), ExtraComputeUnitPrice: uint64(7)}, 15000, 7},
+		{"invalid values", map[string]interface{}{ExtraComputeUnitLimit: float64(-1), ExtraComputeUnitPrice: "high"}, DefaultComputeUnitLimit, DefaultComputeUnitPriceMicrolamports},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, price := ComputeBudgetFromExtra(tt.extra)
+			if limit != tt.wantLimit || price != tt.wantPrice {
+				t.Errorf("ComputeBudgetFromExtra() = %d, %d, want %d, %d", limit, price, tt.wantLimit, tt.wantPrice)
+			}
+		})
+	}
+}
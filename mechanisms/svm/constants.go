@@ -13,6 +13,9 @@ const (
 	// DefaultDecimals is the default token decimals for USDC
 	DefaultDecimals = 6
 
+	// UnknownAssetSymbol is the placeholder symbol of tokens that aren't in a network's supported assets
+	UnknownAssetSymbol = "UNKNOWN"
+
 	// DefaultComputeUnitPriceMicrolamports is the default compute unit price in microlamports
 	DefaultComputeUnitPriceMicrolamports = 1
 
@@ -23,6 +26,18 @@ const (
 	// DefaultComputeUnitLimit is the default compute unit limit for transactions
 	DefaultComputeUnitLimit uint32 = 6500
 
+	// PriorityFeeQueryTimeout bounds a dynamic compute unit price lookup made for the supported kinds endpoint
+	PriorityFeeQueryTimeout = 5 * time.Second
+
+	// ExtraComputeUnitLimit and ExtraComputeUnitPrice are the paymentRequirements.extra keys through
+	// which a facilitator asks clients for a specific compute budget
+	ExtraComputeUnitLimit = "computeUnitLimit"
+	ExtraComputeUnitPrice = "computeUnitPrice"
+
+	// ExtraNonceAccount is the paymentRequirements.extra key through which a facilitator asks
+	// clients to build the transaction against a durable nonce account
+	ExtraNonceAccount = "nonceAccount"
+
 	// DefaultCommitment is the default commitment level for transactions
 	DefaultCommitment = rpc.CommitmentConfirmed
 
@@ -5,10 +5,8 @@ import (
 	"fmt"
 	"strconv"
 
-	bin "github.com/gagliardetto/binary"
 	solana "github.com/gagliardetto/solana-go"
 	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
-	"github.com/gagliardetto/solana-go/programs/token"
 	"github.com/gagliardetto/solana-go/rpc"
 
 	"x402-go/mechanisms/svm"
@@ -71,16 +69,10 @@ func (c *ExactSvmScheme) CreatePaymentPayload(
 		return types.PaymentPayload{}, fmt.Errorf("invalid asset address: %w", err)
 	}
 
-	// Get mint account to determine token program
-	mintAccount, err := rpcClient.GetAccountInfo(ctx, mintPubkey)
+	// Get mint account to determine token program (Token or Token-2022) and transfer fee
+	mint, err := svm.GetMintInfo(ctx, rpcClient, mintPubkey)
 	if err != nil {
-		return types.PaymentPayload{}, fmt.Errorf("failed to get mint account: %w", err)
-	}
-
-	// Determine token program (Token or Token-2022)
-	tokenProgramID := mintAccount.Value.Owner
-	if tokenProgramID != solana.TokenProgramID && tokenProgramID != solana.Token2022ProgramID {
-		return types.PaymentPayload{}, fmt.Errorf("asset was not created by a known token program")
+		return types.PaymentPayload{}, err
 	}
 
 	// Parse payTo address
@@ -89,14 +81,14 @@ func (c *ExactSvmScheme) CreatePaymentPayload(
 		return types.PaymentPayload{}, fmt.Errorf("invalid payTo address: %w", err)
 	}
 
-	// Find source ATA (client's token account)
-	sourceATA, _, err := solana.FindAssociatedTokenAddress(c.signer.Address(), mintPubkey)
+	// Find source ATA (client's token account) under the mint's token program
+	sourceATA, err := svm.FindAssociatedTokenAddress(c.signer.Address(), mintPubkey, mint.ProgramID)
 	if err != nil {
 		return types.PaymentPayload{}, fmt.Errorf("failed to derive source ATA: %w", err)
 	}
 
 	// Find destination ATA (recipient's token account)
-	destinationATA, _, err := solana.FindAssociatedTokenAddress(payToPubkey, mintPubkey)
+	destinationATA, err := svm.FindAssociatedTokenAddress(payToPubkey, mintPubkey, mint.ProgramID)
 	if err != nil {
 		return types.PaymentPayload{}, fmt.Errorf("failed to derive destination ATA: %w", err)
 	}
@@ -118,53 +110,77 @@ func (c *ExactSvmScheme) CreatePaymentPayload(
 		return types.PaymentPayload{}, fmt.Errorf("invalid feePayer address: %w", err)
 	}
 
-	// Get mint account data to get decimals
-	var mintData token.Mint
-	err = bin.NewBinDecoder(mintAccount.Value.Data.GetBinary()).Decode(&mintData)
-	if err != nil {
-		return types.PaymentPayload{}, fmt.Errorf("failed to decode mint data: %w", err)
-	}
-
-	// Get latest blockhash
-	latestBlockhash, err := rpcClient.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
-	if err != nil {
-		return types.PaymentPayload{}, fmt.Errorf("failed to get latest blockhash: %w", err)
-	}
-	recentBlockhash := latestBlockhash.Value.Blockhash
-
-	// Build compute budget instructions
+	// Token-2022 mints may withhold a transfer fee from the recipient; send enough to cover it
+	if mint.TransferFee != nil {
+		epoch, err := svm.GetCurrentEpoch(ctx, rpcClient)
+		if err != nil {
+			return types.PaymentPayload{}, err
+		}
+		amount, err = mint.AmountBeforeFee(amount, epoch)
+		if err != nil {
+			return types.PaymentPayload{}, err
+		}
+	}
+
+	// Build against the facilitator's durable nonce when it asks for one, so the payment stays
+	// valid until settled; otherwise use the latest blockhash, which expires after about two minutes
+	var instructions []solana.Instruction
+	var recentBlockhash solana.Hash
+	if nonceAccount, ok := svm.NonceAccountFromExtra(requirements.Extra); ok {
+		nonce, err := svm.GetNonceAccount(ctx, rpcClient, nonceAccount)
+		if err != nil {
+			return types.PaymentPayload{}, err
+		}
+		if nonce.Authority != feePayer {
+			return types.PaymentPayload{}, fmt.Errorf("nonce account %s is not controlled by fee payer %s", nonceAccount, feePayer)
+		}
+		instructions = append(instructions, svm.NewAdvanceNonceInstruction(nonceAccount, feePayer))
+		recentBlockhash = nonce.Nonce
+	} else {
+		latestBlockhash, err := rpcClient.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
+		if err != nil {
+			return types.PaymentPayload{}, fmt.Errorf("failed to get latest blockhash: %w", err)
+		}
+		recentBlockhash = latestBlockhash.Value.Blockhash
+	}
+
+	// Build compute budget instructions, using the facilitator's requested budget when present
+	unitLimit, unitPrice := svm.ComputeBudgetFromExtra(requirements.Extra)
 	cuLimit, err := computebudget.NewSetComputeUnitLimitInstructionBuilder().
-		SetUnits(svm.DefaultComputeUnitLimit).
+		SetUnits(unitLimit).
 		ValidateAndBuild()
 	if err != nil {
 		return types.PaymentPayload{}, fmt.Errorf("failed to build compute limit instruction: %w", err)
 	}
 
 	cuPrice, err := computebudget.NewSetComputeUnitPriceInstructionBuilder().
-		SetMicroLamports(svm.DefaultComputeUnitPriceMicrolamports).
+		SetMicroLamports(unitPrice).
 		ValidateAndBuild()
 	if err != nil {
 		return types.PaymentPayload{}, fmt.Errorf("failed to build compute price instruction: %w", err)
 	}
 
 	// Build final transfer instruction
-	transferIx, err := token.NewTransferCheckedInstructionBuilder().
-		SetAmount(amount).
-		SetDecimals(mintData.Decimals).
-		SetSourceAccount(sourceATA).
-		SetMintAccount(mintPubkey).
-		SetDestinationAccount(destinationATA).
-		SetOwnerAccount(c.signer.Address()).
-		ValidateAndBuild()
+	transferIx, err := svm.NewTransferCheckedInstruction(
+		mint.ProgramID,
+		amount,
+		mint.Decimals,
+		sourceATA,
+		mintPubkey,
+		destinationATA,
+		c.signer.Address(),
+	)
 	if err != nil {
 		return types.PaymentPayload{}, fmt.Errorf("failed to build transfer instruction: %w", err)
 	}
 
 	// Create final transaction
-	tx, err := solana.NewTransactionBuilder().
-		AddInstruction(cuLimit).
-		AddInstruction(cuPrice).
-		AddInstruction(transferIx).
+	instructions = append(instructions, cuLimit, cuPrice, transferIx)
+	builder := solana.NewTransactionBuilder()
+	for _, ix := range instructions {
+		builder.AddInstruction(ix)
+	}
+	tx, err := builder.
 		SetRecentBlockHash(recentBlockhash).
 		SetFeePayer(feePayer).
 		Build()
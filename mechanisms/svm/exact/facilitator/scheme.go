@@ -16,15 +16,36 @@ import (
 	"x402-go/types"
 )
 
+// ExactSvmSchemeConfig holds configuration for the ExactSvmScheme facilitator
+type ExactSvmSchemeConfig struct {
+	// ComputeBudget sets the compute unit limit and price (priority fee) clients are asked to use,
+	// and the highest price Verify accepts. Zero values keep the client defaults.
+	ComputeBudget svm.ComputeBudgetConfig
+
+	// DurableNonces are durable nonce accounts by CAIP-2 network. Clients build payment
+	// transactions against them instead of a recent blockhash, so payments can wait in a
+	// settlement queue without expiring; settling advances the nonce. The nonce's authority is
+	// advertised as the fee payer. A nonce account holds one pending payment at a time: settling
+	// any payment invalidates the others built against the same nonce.
+	DurableNonces map[string]svm.DurableNonce
+}
+
 // ExactSvmScheme implements the SchemeNetworkFacilitator interface for SVM (Solana) exact payments (V2)
 type ExactSvmScheme struct {
 	signer svm.FacilitatorSvmSigner
+	config ExactSvmSchemeConfig
 }
 
 // NewExactSvmScheme creates a new ExactSvmScheme
-func NewExactSvmScheme(signer svm.FacilitatorSvmSigner) *ExactSvmScheme {
+// Config is optional - if not provided, uses defaults
+func NewExactSvmScheme(signer svm.FacilitatorSvmSigner, config ...*ExactSvmSchemeConfig) *ExactSvmScheme {
+	cfg := ExactSvmSchemeConfig{}
+	if len(config) > 0 && config[0] != nil {
+		cfg = *config[0]
+	}
 	return &ExactSvmScheme{
 		signer: signer,
+		config: cfg,
 	}
 }
 
@@ -39,7 +60,7 @@ func (f *ExactSvmScheme) CaipFamily() string {
 }
 
 // GetExtra returns mechanism-specific extra data for the supported kinds endpoint.
-// For SVM, this includes a randomly selected fee payer address.
+// For SVM, this includes a randomly selected fee payer address and any configured compute budget.
 // Random selection distributes load across multiple signers.
 func (f *ExactSvmScheme) GetExtra(network x402.Network) map[string]interface{} {
 	addresses := f.signer.GetAddresses(context.Background(), string(network))
@@ -47,9 +68,18 @@ func (f *ExactSvmScheme) GetExtra(network x402.Network) map[string]interface{} {
 	// Randomly select from available addresses to distribute load
 	randomIndex := rand.Intn(len(addresses))
 
-	return map[string]interface{}{
+	extra := map[string]interface{}{
 		"feePayer": addresses[randomIndex].String(),
 	}
+
+	// The nonce's authority pays the fee, since it must sign the advance nonce instruction
+	if nonce, ok := f.config.DurableNonces[string(network)]; ok {
+		extra["feePayer"] = nonce.Authority.String()
+		extra[svm.ExtraNonceAccount] = nonce.Account.String()
+	}
+	f.config.ComputeBudget.AddToExtra(context.Background(), string(network), extra)
+
+	return extra
 }
 
 // GetSigners returns signer addresses used by this facilitator.
@@ -73,21 +103,21 @@ func (f *ExactSvmScheme) Verify(
 
 	// Step 1: Validate Payment Requirements
 	if payload.Accepted.Scheme != svm.SchemeExact || requirements.Scheme != svm.SchemeExact {
-		return nil, x402.NewVerifyError("unsupported_scheme", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonUnsupportedScheme, "", network, nil)
 	}
 
 	// V2: Network matching - validate payload network matches requirements
 	if string(payload.Accepted.Network) != string(requirements.Network) {
-		return nil, x402.NewVerifyError("network_mismatch", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonNetworkMismatch, "", network, nil)
 	}
 
 	if requirements.Extra == nil || requirements.Extra["feePayer"] == nil {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_missing_fee_payer", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadMissingFeePayer, "", network, nil)
 	}
 
 	feePayerStr, ok := requirements.Extra["feePayer"].(string)
 	if !ok {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_missing_fee_payer", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadMissingFeePayer, "", network, nil)
 	}
 
 	// Verify that the requested feePayer is managed by this facilitator
@@ -105,39 +135,47 @@ func (f *ExactSvmScheme) Verify(
 		}
 	}
 	if !feePayerManaged {
-		return nil, x402.NewVerifyError("fee_payer_not_managed_by_facilitator", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonFeePayerNotManagedByFacilitator, "", network, nil)
 	}
 
 	// Parse payload
 	solanaPayload, err := svm.PayloadFromMap(payload.Payload)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_transaction", "", network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadTransaction, "", network, err)
 	}
 
 	// Step 2: Parse and Validate Transaction Structure
 	tx, err := svm.DecodeTransaction(solanaPayload.Transaction)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_transaction_could_not_be_decoded", "", network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadTransactionCouldNotBeDecoded, "", network, err)
 	}
 
-	// 3 instructions: ComputeLimit + ComputePrice + TransferChecked
-	if len(tx.Message.Instructions) != 3 {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_transaction_instructions_length", "", network, nil)
+	// 3 instructions: ComputeLimit + ComputePrice + TransferChecked, preceded by AdvanceNonceAccount
+	// when the transaction is built against a durable nonce
+	instructions := tx.Message.Instructions
+	if len(instructions) == 4 {
+		if err := f.verifyNonceInstruction(tx, instructions[0], feePayerStr, string(network)); err != nil {
+			return nil, x402.NewVerifyError(err.Error(), "", network, err)
+		}
+		instructions = instructions[1:]
+	}
+	if len(instructions) != 3 {
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsLength, "", network, nil)
 	}
 
 	// Step 3: Verify Compute Budget Instructions
-	if err := f.verifyComputeLimitInstruction(tx, tx.Message.Instructions[0]); err != nil {
+	if err := f.verifyComputeLimitInstruction(tx, instructions[0]); err != nil {
 		return nil, x402.NewVerifyError(err.Error(), "", network, err)
 	}
 
-	if err := f.verifyComputePriceInstruction(tx, tx.Message.Instructions[1]); err != nil {
+	if err := f.verifyComputePriceInstruction(tx, instructions[1]); err != nil {
 		return nil, x402.NewVerifyError(err.Error(), "", network, err)
 	}
 
 	// Extract payer from transaction
 	payer, err := svm.GetTokenPayerFromTransaction(tx)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_no_transfer_instruction", payer, network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction, payer, network, err)
 	}
 
 	// V2: payload.Accepted.Network is already validated by scheme lookup
@@ -154,27 +192,32 @@ func (f *ExactSvmScheme) Verify(
 	}
 
 	// Step 4: Verify Transfer Instruction
-	if err := f.verifyTransferInstruction(tx, tx.Message.Instructions[2], reqStruct, signerAddressStrs); err != nil {
+	if err := f.verifyTransferInstruction(ctx, tx, instructions[2], reqStruct, signerAddressStrs); err != nil {
 		return nil, x402.NewVerifyError(err.Error(), payer, network, err)
 	}
 
+	// Reject underfunded payers with a clear reason rather than a failed simulation
+	if err := f.checkPayerBalance(ctx, tx, instructions[2], payer, network); err != nil {
+		return nil, err
+	}
+
 	// Step 5: Sign and Simulate Transaction
 	// CRITICAL: Simulation proves transaction will succeed (catches insufficient balance, invalid accounts, etc)
 
 	// feePayer already validated in Step 1
 	feePayer, err := solana.PublicKeyFromBase58(feePayerStr)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_fee_payer", payer, network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidFeePayer, payer, network, err)
 	}
 
 	// Sign transaction with the feePayer's signer
 	if err := f.signer.SignTransaction(ctx, tx, feePayer, string(requirements.Network)); err != nil {
-		return nil, x402.NewVerifyError("transaction_signing_failed", payer, network, err)
+		return nil, x402.NewVerifyError(x402.ReasonTransactionSigningFailed, payer, network, err)
 	}
 
 	// Simulate transaction to verify it would succeed
 	if err := f.signer.SimulateTransaction(ctx, tx, string(requirements.Network)); err != nil {
-		return nil, x402.NewVerifyError("transaction_simulation_failed", payer, network, err)
+		return nil, x402.NewVerifyError(x402.ReasonTransactionSimulationFailed, payer, network, err)
 	}
 
 	return &x402.VerifyResponse{
@@ -200,53 +243,53 @@ func (f *ExactSvmScheme) Settle(
 		if errors.As(err, &ve) {
 			return nil, x402.NewSettleError(ve.Reason, ve.Payer, ve.Network, "", ve.Err)
 		}
-		return nil, x402.NewSettleError("verification_failed", "", network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonVerificationFailed, "", network, "", err)
 	}
 
 	// Parse payload
 	solanaPayload, err := svm.PayloadFromMap(payload.Payload)
 	if err != nil {
-		return nil, x402.NewSettleError("invalid_exact_solana_payload_transaction", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonInvalidExactSolanaPayloadTransaction, verifyResp.Payer, network, "", err)
 	}
 
 	// Decode transaction
 	tx, err := svm.DecodeTransaction(solanaPayload.Transaction)
 	if err != nil {
-		return nil, x402.NewSettleError("invalid_exact_solana_payload_transaction", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonInvalidExactSolanaPayloadTransaction, verifyResp.Payer, network, "", err)
 	}
 
 	// Extract and validate feePayer from requirements matches transaction
 	feePayerStr, ok := requirements.Extra["feePayer"].(string)
 	if !ok {
-		return nil, x402.NewSettleError("missing_fee_payer", verifyResp.Payer, network, "", nil)
+		return nil, x402.NewSettleError(x402.ReasonMissingFeePayer, verifyResp.Payer, network, "", nil)
 	}
 
 	expectedFeePayer, err := solana.PublicKeyFromBase58(feePayerStr)
 	if err != nil {
-		return nil, x402.NewSettleError("invalid_fee_payer", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonInvalidFeePayer, verifyResp.Payer, network, "", err)
 	}
 
 	// Verify transaction feePayer matches requirements
 	actualFeePayer := tx.Message.AccountKeys[0] // First account is fee payer
 	if actualFeePayer != expectedFeePayer {
-		return nil, x402.NewSettleError("fee_payer_mismatch", verifyResp.Payer, network, "",
+		return nil, x402.NewSettleError(x402.ReasonFeePayerMismatch, verifyResp.Payer, network, "",
 			fmt.Errorf("expected %s, got %s", expectedFeePayer, actualFeePayer))
 	}
 
 	// Sign with the feePayer's signer
 	if err := f.signer.SignTransaction(ctx, tx, expectedFeePayer, string(requirements.Network)); err != nil {
-		return nil, x402.NewSettleError("transaction_failed", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonTransactionFailed, verifyResp.Payer, network, "", err)
 	}
 
 	// Send transaction to network
 	signature, err := f.signer.SendTransaction(ctx, tx, string(requirements.Network))
 	if err != nil {
-		return nil, x402.NewSettleError("transaction_failed", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonTransactionFailed, verifyResp.Payer, network, "", err)
 	}
 
 	// Wait for confirmation
 	if err := f.signer.ConfirmTransaction(ctx, signature, string(requirements.Network)); err != nil {
-		return nil, x402.NewSettleError("transaction_confirmation_failed", verifyResp.Payer, network, signature.String(), err)
+		return nil, x402.NewSettleError(x402.ReasonTransactionConfirmationFailed, verifyResp.Payer, network, signature.String(), err)
 	}
 
 	return &x402.SettleResponse{
@@ -257,28 +300,96 @@ func (f *ExactSvmScheme) Settle(
 	}, nil
 }
 
+// checkPayerBalance rejects a transfer out of the payer's associated token account when that
+// account holds less than the transfer amount; an account that doesn't exist yet holds nothing.
+// Balances are read through the signer's svm.TokenBalanceReader, and the check is skipped when
+// the signer doesn't implement it or the transfer comes from another token account.
+func (f *ExactSvmScheme) checkPayerBalance(
+	ctx context.Context,
+	tx *solana.Transaction,
+	inst solana.CompiledInstruction,
+	payer string,
+	network x402.Network,
+) error {
+	reader, ok := f.signer.(svm.TokenBalanceReader)
+	if !ok {
+		return nil
+	}
+
+	// The instruction was validated by verifyTransferInstruction
+	progID := tx.Message.AccountKeys[inst.ProgramIDIndex]
+	accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
+	if err != nil || len(accounts) < 4 {
+		return nil
+	}
+	decoded, err := token.DecodeInstruction(accounts, inst.Data)
+	if err != nil {
+		return nil
+	}
+	transferChecked, ok := decoded.Impl.(*token.TransferChecked)
+	if !ok {
+		return nil
+	}
+
+	// TransferChecked: [source, mint, destination, authority, ...]
+	source := accounts[0].PublicKey
+	mint := accounts[1].PublicKey
+	owner := accounts[3].PublicKey
+	ata, err := svm.FindAssociatedTokenAddress(owner, mint, progID)
+	if err != nil || source != ata {
+		return nil
+	}
+
+	balance, err := reader.GetTokenBalance(ctx, owner, mint, progID, string(network))
+	if err != nil {
+		return x402.NewVerifyError(x402.ReasonFailedToGetBalance, payer, network, err)
+	}
+	if balance < *transferChecked.Amount {
+		return x402.NewVerifyError(x402.ReasonInsufficientBalance, payer, network,
+			fmt.Errorf("balance %d is below the transfer amount %d", balance, *transferChecked.Amount))
+	}
+
+	return nil
+}
+
+// verifyNonceInstruction verifies that an AdvanceNonceAccount instruction advances the durable
+// nonce configured for network, under the fee payer's authority
+func (f *ExactSvmScheme) verifyNonceInstruction(tx *solana.Transaction, inst solana.CompiledInstruction, feePayer string, network string) error {
+	nonceAccount, authority, ok := svm.DecodeAdvanceNonceInstruction(tx, inst)
+	if !ok {
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsNonceInstruction)
+	}
+
+	nonce, configured := f.config.DurableNonces[network]
+	if !configured || nonceAccount != nonce.Account || authority != nonce.Authority || authority.String() != feePayer {
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsNonceInstruction)
+	}
+
+	return nil
+}
+
 // verifyComputeLimitInstruction verifies the compute unit limit instruction
 func (f *ExactSvmScheme) verifyComputeLimitInstruction(tx *solana.Transaction, inst solana.CompiledInstruction) error {
 	progID := tx.Message.AccountKeys[inst.ProgramIDIndex]
 
 	if !progID.Equals(solana.ComputeBudget) {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_limit_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputeLimitInstruction)
 	}
 
 	// Check discriminator (should be 2 for SetComputeUnitLimit)
 	if len(inst.Data) < 1 || inst.Data[0] != 2 {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_limit_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputeLimitInstruction)
 	}
 
 	// Decode to validate format
 	accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_limit_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputeLimitInstruction)
 	}
 
 	_, err = computebudget.DecodeInstruction(accounts, inst.Data)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_limit_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputeLimitInstruction)
 	}
 
 	return nil
@@ -289,33 +400,33 @@ func (f *ExactSvmScheme) verifyComputePriceInstruction(tx *solana.Transaction, i
 	progID := tx.Message.AccountKeys[inst.ProgramIDIndex]
 
 	if !progID.Equals(solana.ComputeBudget) {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction)
 	}
 
 	// Check discriminator (should be 3 for SetComputeUnitPrice)
 	if len(inst.Data) < 1 || inst.Data[0] != 3 {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction)
 	}
 
 	// Decode to get microLamports
 	accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction)
 	}
 
 	decoded, err := computebudget.DecodeInstruction(accounts, inst.Data)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction)
 	}
 
 	// Check if it's SetComputeUnitPrice and validate the price
 	if priceInst, ok := decoded.Impl.(*computebudget.SetComputeUnitPrice); ok {
-		// Check if price exceeds maximum (5 lamports per compute unit = 5,000,000 microlamports)
-		if priceInst.MicroLamports > uint64(svm.MaxComputeUnitPriceMicrolamports) {
-			return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction_too_high")
+		// Check if price exceeds the configured maximum (default 5 lamports per compute unit = 5,000,000 microlamports)
+		if priceInst.MicroLamports > f.config.ComputeBudget.MaxPrice() {
+			return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstructionTooHigh)
 		}
 	} else {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction)
 	}
 
 	return nil
@@ -323,6 +434,7 @@ func (f *ExactSvmScheme) verifyComputePriceInstruction(tx *solana.Transaction, i
 
 // verifyTransferInstruction verifies the transfer instruction
 func (f *ExactSvmScheme) verifyTransferInstruction(
+	ctx context.Context,
 	tx *solana.Transaction,
 	inst solana.CompiledInstruction,
 	requirements x402.PaymentRequirements,
@@ -331,27 +443,27 @@ func (f *ExactSvmScheme) verifyTransferInstruction(
 	progID := tx.Message.AccountKeys[inst.ProgramIDIndex]
 
 	// Must be Token Program or Token-2022 Program
-	if progID != solana.TokenProgramID && progID != solana.Token2022ProgramID {
-		return fmt.Errorf("invalid_exact_solana_payload_no_transfer_instruction")
+	if !svm.IsTokenProgram(progID) {
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction)
 	}
 
 	accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_no_transfer_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction)
 	}
 
 	if len(accounts) < 4 {
-		return fmt.Errorf("invalid_exact_solana_payload_no_transfer_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction)
 	}
 
 	decoded, err := token.DecodeInstruction(accounts, inst.Data)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_no_transfer_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction)
 	}
 
 	transferChecked, ok := decoded.Impl.(*token.TransferChecked)
 	if !ok {
-		return fmt.Errorf("invalid_exact_solana_payload_no_transfer_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction)
 	}
 
 	// SECURITY: Verify that the facilitator's signers are not transferring their own funds
@@ -359,45 +471,52 @@ func (f *ExactSvmScheme) verifyTransferInstruction(
 	authorityAddr := accounts[3].PublicKey.String() // TransferChecked: [source, mint, destination, authority, ...]
 	for _, signerAddr := range signerAddresses {
 		if authorityAddr == signerAddr {
-			return fmt.Errorf("invalid_exact_solana_payload_transaction_fee_payer_transferring_funds")
+			return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionFeePayerTransferringFunds)
 		}
 	}
 
 	// Verify mint address
 	mintAddr := accounts[1].PublicKey.String()
 	if mintAddr != requirements.Asset {
-		return fmt.Errorf("invalid_exact_solana_payload_mint_mismatch")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadMintMismatch)
 	}
 
 	// Verify destination ATA
 	payToPubkey, err := solana.PublicKeyFromBase58(requirements.PayTo)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_recipient_mismatch")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadRecipientMismatch)
 	}
 
 	mintPubkey, err := solana.PublicKeyFromBase58(requirements.Asset)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_mint_mismatch")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadMintMismatch)
 	}
 
-	expectedDestATA, _, err := solana.FindAssociatedTokenAddress(payToPubkey, mintPubkey)
+	// Token-2022 associated token accounts are derived with the Token-2022 program ID
+	expectedDestATA, err := svm.FindAssociatedTokenAddress(payToPubkey, mintPubkey, progID)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_recipient_mismatch")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadRecipientMismatch)
 	}
 
 	destATA := transferChecked.GetDestinationAccount().PublicKey
 	if destATA.String() != expectedDestATA.String() {
-		return fmt.Errorf("invalid_exact_solana_payload_recipient_mismatch")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadRecipientMismatch)
 	}
 
 	// Verify amount
 	requiredAmount, err := strconv.ParseUint(requirements.Amount, 10, 64)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_amount_insufficient")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadAmountInsufficient)
+	}
+
+	// Token-2022 transfer fees are withheld from the recipient, so the net amount must cover the requirement
+	received, err := svm.AmountReceived(ctx, f.signer, progID, mintPubkey, *transferChecked.Amount, string(requirements.Network))
+	if err != nil {
+		return errors.New(x402.ReasonFailedToGetMintInfo)
 	}
 
-	if *transferChecked.Amount < requiredAmount {
-		return fmt.Errorf("invalid_exact_solana_payload_amount_insufficient")
+	if received < requiredAmount {
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadAmountInsufficient)
 	}
 
 	return nil
@@ -61,13 +61,13 @@ func (s *ExactSvmScheme) RegisterMoneyParser(parser x402.MoneyParser) *ExactSvmS
 }
 
 // ParsePrice parses a price and converts it to an asset amount (V2)
-// If price is already an AssetAmount, returns it directly.
+// If price is already an AssetAmount or a UnitPrice, returns it without conversion.
 // If price is Money (string | number), parses to decimal and tries custom parsers.
 // Falls back to default conversion if all custom parsers return nil.
 //
 // Args:
 //
-//	price: The price to parse (can be string, number, or AssetAmount map)
+//	price: The price to parse (can be string, number, AssetAmount map, or UnitPrice)
 //	network: The network identifier
 //
 // Returns:
@@ -82,6 +82,11 @@ func (s *ExactSvmScheme) ParsePrice(price x402.Price, network x402.Network) (x40
 		return x402.AssetAmount{}, err
 	}
 
+	// A UnitPrice is already in the asset's smallest unit
+	if unitPrice, ok := x402.AsUnitPrice(price); ok {
+		return s.parseUnitPrice(unitPrice, config)
+	}
+
 	// Handle pre-parsed price object (with amount and asset)
 	if priceMap, ok := price.(map[string]interface{}); ok {
 		if amountVal, hasAmount := priceMap["amount"]; hasAmount {
@@ -170,6 +175,36 @@ func (s *ExactSvmScheme) parseMoneyToDecimal(price x402.Price) (float64, error)
 	return 0, fmt.Errorf("invalid price format: %v", price)
 }
 
+// parseUnitPrice resolves the asset of a UnitPrice, leaving its amount as is
+func (s *ExactSvmScheme) parseUnitPrice(price x402.UnitPrice, config *svm.NetworkConfig) (x402.AssetAmount, error) {
+	if price.Amount == nil || price.Amount.Sign() <= 0 || !price.Amount.IsUint64() {
+		return x402.AssetAmount{}, fmt.Errorf("unit price amount must be a positive uint64")
+	}
+
+	// Resolve symbols strictly: falling back to the default asset would charge the amount in the wrong token
+	asset := price.Asset
+	if asset == "" {
+		asset = config.DefaultAsset.Address
+	} else if !svm.ValidateSolanaAddress(asset) {
+		info, ok := config.SupportedAssets[strings.ToUpper(asset)]
+		if !ok {
+			return x402.AssetAmount{}, fmt.Errorf("unknown asset %s", price.Asset)
+		}
+		asset = info.Address
+	}
+
+	extra := price.Extra
+	if extra == nil {
+		extra = make(map[string]interface{})
+	}
+
+	return x402.AssetAmount{
+		Amount: price.Amount.String(),
+		Asset:  asset,
+		Extra:  extra,
+	}, nil
+}
+
 // defaultMoneyConversion converts decimal amount to USDC AssetAmount
 func (s *ExactSvmScheme) defaultMoneyConversion(amount float64, config *svm.NetworkConfig) (x402.AssetAmount, error) {
 	// Convert decimal to smallest unit (e.g., $1.50 -> 1500000 for USDC with 6 decimals)
@@ -231,12 +266,27 @@ func (s *ExactSvmScheme) EnhancePaymentRequirements(
 		requirements.Extra = make(map[string]interface{})
 	}
 
+	// Describe the asset so clients can compare requirements across assets
+	if _, ok := requirements.Extra[x402.ExtraDecimals]; !ok {
+		requirements.Extra[x402.ExtraDecimals] = assetInfo.Decimals
+	}
+	if _, ok := requirements.Extra[x402.ExtraSymbol]; !ok && assetInfo.Symbol != "" && assetInfo.Symbol != svm.UnknownAssetSymbol {
+		requirements.Extra[x402.ExtraSymbol] = assetInfo.Symbol
+	}
+
 	// Add feePayer from supportedKind.extra to payment requirements
 	// The facilitator provides its address as the fee payer for transaction fees
 	if supportedKind.Extra != nil {
 		if feePayer, ok := supportedKind.Extra["feePayer"]; ok {
 			requirements.Extra["feePayer"] = feePayer
 		}
+
+		// Pass through the compute budget (priority fee) the facilitator asks clients to use
+		for _, key := range []string{svm.ExtraComputeUnitLimit, svm.ExtraComputeUnitPrice} {
+			if val, ok := supportedKind.Extra[key]; ok {
+				requirements.Extra[key] = val
+			}
+		}
 	}
 
 	// Copy extensions from supportedKind if provided
@@ -2,6 +2,7 @@ package server
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 
 	x402 "x402-go"
@@ -194,3 +195,31 @@ func TestRegisterMoneyParser_NoCustomParsers(t *testing.T) {
 		t.Errorf("Expected amount %s, got %s", expectedAmount, result.Amount)
 	}
 }
+
+// TestParsePrice_UnitPrice tests that unit prices skip money parsers and the dollar conversion
+func TestParsePrice_UnitPrice(t *testing.T) {
+	server := NewExactSvmScheme()
+	server.RegisterMoneyParser(func(amount float64, network x402.Network) (*x402.AssetAmount, error) {
+		t.Error("Money parsers should not be called for unit prices")
+		return nil, nil
+	})
+
+	result, err := server.ParsePrice(x402.UnitPrice{Amount: big.NewInt(1500), Asset: "USDC"}, "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Asset != "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v" {
+		t.Errorf("Expected USDC, got %s", result.Asset)
+	}
+	if result.Amount != "1500" {
+		t.Errorf("Expected amount 1500, got %s", result.Amount)
+	}
+
+	tooLarge := new(big.Int).Lsh(big.NewInt(1), 64)
+	if _, err := server.ParsePrice(x402.UnitPrice{Amount: tooLarge}, "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp"); err == nil {
+		t.Error("Expected error for an amount above uint64")
+	}
+	if _, err := server.ParsePrice(x402.UnitPrice{Amount: big.NewInt(1), Asset: "NOPE"}, "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp"); err == nil {
+		t.Error("Expected error for an unknown asset symbol")
+	}
+}
@@ -17,15 +17,29 @@ import (
 	"x402-go/types"
 )
 
+// ExactSvmSchemeV1Config holds configuration for the ExactSvmSchemeV1 facilitator
+type ExactSvmSchemeV1Config struct {
+	// ComputeBudget sets the compute unit limit and price (priority fee) clients are asked to use,
+	// and the highest price Verify accepts. Zero values keep the client defaults.
+	ComputeBudget svm.ComputeBudgetConfig
+}
+
 // ExactSvmSchemeV1 implements the SchemeNetworkFacilitator interface for SVM (Solana) exact payments (V1)
 type ExactSvmSchemeV1 struct {
 	signer svm.FacilitatorSvmSigner
+	config ExactSvmSchemeV1Config
 }
 
 // NewExactSvmSchemeV1 creates a new ExactSvmSchemeV1
-func NewExactSvmSchemeV1(signer svm.FacilitatorSvmSigner) *ExactSvmSchemeV1 {
+// Config is optional - if not provided, uses defaults
+func NewExactSvmSchemeV1(signer svm.FacilitatorSvmSigner, config ...*ExactSvmSchemeV1Config) *ExactSvmSchemeV1 {
+	cfg := ExactSvmSchemeV1Config{}
+	if len(config) > 0 && config[0] != nil {
+		cfg = *config[0]
+	}
 	return &ExactSvmSchemeV1{
 		signer: signer,
+		config: cfg,
 	}
 }
 
@@ -40,7 +54,7 @@ func (f *ExactSvmSchemeV1) CaipFamily() string {
 }
 
 // GetExtra returns mechanism-specific extra data for the supported kinds endpoint.
-// For SVM, this includes a randomly selected fee payer address.
+// For SVM, this includes a randomly selected fee payer address and any configured compute budget.
 // Random selection distributes load across multiple signers.
 func (f *ExactSvmSchemeV1) GetExtra(network x402.Network) map[string]interface{} {
 	addresses := f.signer.GetAddresses(context.Background(), string(network))
@@ -48,9 +62,12 @@ func (f *ExactSvmSchemeV1) GetExtra(network x402.Network) map[string]interface{}
 	// Randomly select from available addresses to distribute load
 	randomIndex := rand.Intn(len(addresses))
 
-	return map[string]interface{}{
+	extra := map[string]interface{}{
 		"feePayer": addresses[randomIndex].String(),
 	}
+	f.config.ComputeBudget.AddToExtra(context.Background(), string(network), extra)
+
+	return extra
 }
 
 // GetSigners returns signer addresses used by this facilitator.
@@ -75,29 +92,29 @@ func (f *ExactSvmSchemeV1) Verify(
 	// Step 1: Validate Payment Requirements
 	// V1: Check scheme from top level (not in Accepted)
 	if payload.Scheme != svm.SchemeExact || requirements.Scheme != svm.SchemeExact {
-		return nil, x402.NewVerifyError("unsupported_scheme", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonUnsupportedScheme, "", network, nil)
 	}
 
 	// V1: Use payload.Network for validation (top level, not in Accepted)
 	if payload.Network != requirements.Network {
-		return nil, x402.NewVerifyError("network_mismatch", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonNetworkMismatch, "", network, nil)
 	}
 
 	// Parse extra field for feePayer
 	var reqExtraMap map[string]interface{}
 	if requirements.Extra != nil {
 		if err := json.Unmarshal(*requirements.Extra, &reqExtraMap); err != nil {
-			return nil, x402.NewVerifyError("invalid_extra_field", "", network, err)
+			return nil, x402.NewVerifyError(x402.ReasonInvalidExtraField, "", network, err)
 		}
 	}
 
 	if reqExtraMap == nil || reqExtraMap["feePayer"] == nil {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_missing_fee_payer", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadMissingFeePayer, "", network, nil)
 	}
 
 	feePayerStr, ok := reqExtraMap["feePayer"].(string)
 	if !ok {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_missing_fee_payer", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadMissingFeePayer, "", network, nil)
 	}
 
 	// Verify that the requested feePayer is managed by this facilitator
@@ -115,24 +132,24 @@ func (f *ExactSvmSchemeV1) Verify(
 		}
 	}
 	if !feePayerManaged {
-		return nil, x402.NewVerifyError("fee_payer_not_managed_by_facilitator", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonFeePayerNotManagedByFacilitator, "", network, nil)
 	}
 
 	// Parse payload
 	svmPayload, err := svm.PayloadFromMap(payload.Payload)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_transaction", "", network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadTransaction, "", network, err)
 	}
 
 	// Step 2: Parse and Validate Transaction Structure
 	tx, err := svm.DecodeTransaction(svmPayload.Transaction)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_transaction_could_not_be_decoded", "", network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadTransactionCouldNotBeDecoded, "", network, err)
 	}
 
 	// 3 instructions: ComputeLimit + ComputePrice + TransferChecked
 	if len(tx.Message.Instructions) != 3 {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_transaction_instructions_length", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsLength, "", network, nil)
 	}
 
 	// Step 3: Verify Compute Budget Instructions
@@ -147,11 +164,11 @@ func (f *ExactSvmSchemeV1) Verify(
 	// Extract payer from transaction
 	payer, err := svm.GetTokenPayerFromTransaction(tx)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_exact_solana_payload_no_transfer_instruction", payer, network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction, payer, network, err)
 	}
 
 	// Step 4: Verify Transfer Instruction
-	if err := f.verifyTransferInstruction(tx, tx.Message.Instructions[2], requirements, signerAddressStrs); err != nil {
+	if err := f.verifyTransferInstruction(ctx, tx, tx.Message.Instructions[2], requirements, signerAddressStrs); err != nil {
 		return nil, x402.NewVerifyError(err.Error(), payer, network, err)
 	}
 
@@ -161,17 +178,17 @@ func (f *ExactSvmSchemeV1) Verify(
 	// feePayer already validated in Step 1
 	feePayer, err := solana.PublicKeyFromBase58(feePayerStr)
 	if err != nil {
-		return nil, x402.NewVerifyError("invalid_fee_payer", payer, network, err)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidFeePayer, payer, network, err)
 	}
 
 	// Sign transaction with the feePayer's signer
 	if err := f.signer.SignTransaction(ctx, tx, feePayer, string(requirements.Network)); err != nil {
-		return nil, x402.NewVerifyError("transaction_signing_failed", payer, network, err)
+		return nil, x402.NewVerifyError(x402.ReasonTransactionSigningFailed, payer, network, err)
 	}
 
 	// Simulate transaction to verify it would succeed
 	if err := f.signer.SimulateTransaction(ctx, tx, string(requirements.Network)); err != nil {
-		return nil, x402.NewVerifyError("transaction_simulation_failed", payer, network, err)
+		return nil, x402.NewVerifyError(x402.ReasonTransactionSimulationFailed, payer, network, err)
 	}
 
 	return &x402.VerifyResponse{
@@ -197,61 +214,61 @@ func (f *ExactSvmSchemeV1) Settle(
 		if errors.As(err, &ve) {
 			return nil, x402.NewSettleError(ve.Reason, ve.Payer, ve.Network, "", ve.Err)
 		}
-		return nil, x402.NewSettleError("verification_failed", "", network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonVerificationFailed, "", network, "", err)
 	}
 
 	// Parse payload
 	svmPayload, err := svm.PayloadFromMap(payload.Payload)
 	if err != nil {
-		return nil, x402.NewSettleError("invalid_exact_solana_payload_transaction", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonInvalidExactSolanaPayloadTransaction, verifyResp.Payer, network, "", err)
 	}
 
 	// Decode transaction
 	tx, err := svm.DecodeTransaction(svmPayload.Transaction)
 	if err != nil {
-		return nil, x402.NewSettleError("invalid_exact_solana_payload_transaction", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonInvalidExactSolanaPayloadTransaction, verifyResp.Payer, network, "", err)
 	}
 
 	// Parse extra field for feePayer (V1 uses *json.RawMessage)
 	var reqExtraMap map[string]interface{}
 	if requirements.Extra != nil {
 		if err := json.Unmarshal(*requirements.Extra, &reqExtraMap); err != nil {
-			return nil, x402.NewSettleError("invalid_extra_field", verifyResp.Payer, network, "", err)
+			return nil, x402.NewSettleError(x402.ReasonInvalidExtraField, verifyResp.Payer, network, "", err)
 		}
 	}
 
 	// Extract and validate feePayer from requirements matches transaction
 	feePayerStr, ok := reqExtraMap["feePayer"].(string)
 	if !ok {
-		return nil, x402.NewSettleError("missing_fee_payer", verifyResp.Payer, network, "", nil)
+		return nil, x402.NewSettleError(x402.ReasonMissingFeePayer, verifyResp.Payer, network, "", nil)
 	}
 
 	expectedFeePayer, err := solana.PublicKeyFromBase58(feePayerStr)
 	if err != nil {
-		return nil, x402.NewSettleError("invalid_fee_payer", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonInvalidFeePayer, verifyResp.Payer, network, "", err)
 	}
 
 	// Verify transaction feePayer matches requirements
 	actualFeePayer := tx.Message.AccountKeys[0] // First account is fee payer
 	if actualFeePayer != expectedFeePayer {
-		return nil, x402.NewSettleError("fee_payer_mismatch", verifyResp.Payer, network, "",
+		return nil, x402.NewSettleError(x402.ReasonFeePayerMismatch, verifyResp.Payer, network, "",
 			fmt.Errorf("expected %s, got %s", expectedFeePayer, actualFeePayer))
 	}
 
 	// Sign with the feePayer's signer
 	if err := f.signer.SignTransaction(ctx, tx, expectedFeePayer, string(requirements.Network)); err != nil {
-		return nil, x402.NewSettleError("transaction_failed", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonTransactionFailed, verifyResp.Payer, network, "", err)
 	}
 
 	// Send transaction to network
 	signature, err := f.signer.SendTransaction(ctx, tx, string(requirements.Network))
 	if err != nil {
-		return nil, x402.NewSettleError("transaction_failed", verifyResp.Payer, network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonTransactionFailed, verifyResp.Payer, network, "", err)
 	}
 
 	// Wait for confirmation
 	if err := f.signer.ConfirmTransaction(ctx, signature, string(requirements.Network)); err != nil {
-		return nil, x402.NewSettleError("transaction_confirmation_failed", verifyResp.Payer, network, signature.String(), err)
+		return nil, x402.NewSettleError(x402.ReasonTransactionConfirmationFailed, verifyResp.Payer, network, signature.String(), err)
 	}
 
 	return &x402.SettleResponse{
@@ -267,23 +284,23 @@ func (f *ExactSvmSchemeV1) verifyComputeLimitInstruction(tx *solana.Transaction,
 	progID := tx.Message.AccountKeys[inst.ProgramIDIndex]
 
 	if !progID.Equals(solana.ComputeBudget) {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_limit_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputeLimitInstruction)
 	}
 
 	// Check discriminator (should be 2 for SetComputeUnitLimit)
 	if len(inst.Data) < 1 || inst.Data[0] != 2 {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_limit_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputeLimitInstruction)
 	}
 
 	// Decode to validate format
 	accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_limit_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputeLimitInstruction)
 	}
 
 	_, err = computebudget.DecodeInstruction(accounts, inst.Data)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_limit_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputeLimitInstruction)
 	}
 
 	return nil
@@ -294,33 +311,33 @@ func (f *ExactSvmSchemeV1) verifyComputePriceInstruction(tx *solana.Transaction,
 	progID := tx.Message.AccountKeys[inst.ProgramIDIndex]
 
 	if !progID.Equals(solana.ComputeBudget) {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction)
 	}
 
 	// Check discriminator (should be 3 for SetComputeUnitPrice)
 	if len(inst.Data) < 1 || inst.Data[0] != 3 {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction)
 	}
 
 	// Decode to get microLamports
 	accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction)
 	}
 
 	decoded, err := computebudget.DecodeInstruction(accounts, inst.Data)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction)
 	}
 
 	// Check if it's SetComputeUnitPrice and validate the price
 	if priceInst, ok := decoded.Impl.(*computebudget.SetComputeUnitPrice); ok {
-		// Check if price exceeds maximum (5 lamports per compute unit = 5,000,000 microlamports)
-		if priceInst.MicroLamports > uint64(svm.MaxComputeUnitPriceMicrolamports) {
-			return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction_too_high")
+		// Check if price exceeds the configured maximum (default 5 lamports per compute unit = 5,000,000 microlamports)
+		if priceInst.MicroLamports > f.config.ComputeBudget.MaxPrice() {
+			return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstructionTooHigh)
 		}
 	} else {
-		return fmt.Errorf("invalid_exact_solana_payload_transaction_instructions_compute_price_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction)
 	}
 
 	return nil
@@ -328,6 +345,7 @@ func (f *ExactSvmSchemeV1) verifyComputePriceInstruction(tx *solana.Transaction,
 
 // verifyTransferInstruction verifies the transfer instruction
 func (f *ExactSvmSchemeV1) verifyTransferInstruction(
+	ctx context.Context,
 	tx *solana.Transaction,
 	inst solana.CompiledInstruction,
 	requirements types.PaymentRequirementsV1,
@@ -336,27 +354,27 @@ func (f *ExactSvmSchemeV1) verifyTransferInstruction(
 	progID := tx.Message.AccountKeys[inst.ProgramIDIndex]
 
 	// Must be Token Program or Token-2022 Program
-	if progID != solana.TokenProgramID && progID != solana.Token2022ProgramID {
-		return fmt.Errorf("invalid_exact_solana_payload_no_transfer_instruction")
+	if !svm.IsTokenProgram(progID) {
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction)
 	}
 
 	accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_no_transfer_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction)
 	}
 
 	if len(accounts) < 4 {
-		return fmt.Errorf("invalid_exact_solana_payload_no_transfer_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction)
 	}
 
 	decoded, err := token.DecodeInstruction(accounts, inst.Data)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_no_transfer_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction)
 	}
 
 	transferChecked, ok := decoded.Impl.(*token.TransferChecked)
 	if !ok {
-		return fmt.Errorf("invalid_exact_solana_payload_no_transfer_instruction")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadNoTransferInstruction)
 	}
 
 	// SECURITY: Verify that the facilitator's signers are not transferring their own funds
@@ -364,35 +382,36 @@ func (f *ExactSvmSchemeV1) verifyTransferInstruction(
 	authorityAddr := accounts[3].PublicKey.String() // TransferChecked: [source, mint, destination, authority, ...]
 	for _, signerAddr := range signerAddresses {
 		if authorityAddr == signerAddr {
-			return fmt.Errorf("invalid_exact_solana_payload_transaction_fee_payer_transferring_funds")
+			return errors.New(x402.ReasonInvalidExactSolanaPayloadTransactionFeePayerTransferringFunds)
 		}
 	}
 
 	// Verify mint address
 	mintAddr := accounts[1].PublicKey.String()
 	if mintAddr != requirements.Asset {
-		return fmt.Errorf("invalid_exact_solana_payload_mint_mismatch")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadMintMismatch)
 	}
 
 	// Verify destination ATA
 	payToPubkey, err := solana.PublicKeyFromBase58(requirements.PayTo)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_recipient_mismatch")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadRecipientMismatch)
 	}
 
 	mintPubkey, err := solana.PublicKeyFromBase58(requirements.Asset)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_mint_mismatch")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadMintMismatch)
 	}
 
-	expectedDestATA, _, err := solana.FindAssociatedTokenAddress(payToPubkey, mintPubkey)
+	// Token-2022 associated token accounts are derived with the Token-2022 program ID
+	expectedDestATA, err := svm.FindAssociatedTokenAddress(payToPubkey, mintPubkey, progID)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_recipient_mismatch")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadRecipientMismatch)
 	}
 
 	destATA := transferChecked.GetDestinationAccount().PublicKey
 	if destATA.String() != expectedDestATA.String() {
-		return fmt.Errorf("invalid_exact_solana_payload_recipient_mismatch")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadRecipientMismatch)
 	}
 
 	// Verify amount - V1: Use MaxAmountRequired
@@ -400,11 +419,17 @@ func (f *ExactSvmSchemeV1) verifyTransferInstruction(
 
 	requiredAmount, err := strconv.ParseUint(amountStr, 10, 64)
 	if err != nil {
-		return fmt.Errorf("invalid_exact_solana_payload_amount_insufficient")
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadAmountInsufficient)
+	}
+
+	// Token-2022 transfer fees are withheld from the recipient, so the net amount must cover the requirement
+	received, err := svm.AmountReceived(ctx, f.signer, progID, mintPubkey, *transferChecked.Amount, string(requirements.Network))
+	if err != nil {
+		return errors.New(x402.ReasonFailedToGetMintInfo)
 	}
 
-	if *transferChecked.Amount < requiredAmount {
-		return fmt.Errorf("invalid_exact_solana_payload_amount_insufficient")
+	if received < requiredAmount {
+		return errors.New(x402.ReasonInvalidExactSolanaPayloadAmountInsufficient)
 	}
 
 	return nil
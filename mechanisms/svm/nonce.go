@@ -0,0 +1,118 @@
+package svm
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	solana "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	// nonceAccountSize is the size of an initialized durable nonce account:
+	// version (4), state (4), authority (32), nonce (32), lamports per signature (8)
+	nonceAccountSize = 80
+
+	// nonceStateInitialized marks a nonce account that holds a nonce
+	nonceStateInitialized = 1
+
+	// systemInstructionAdvanceNonceAccount is the System program's AdvanceNonceAccount instruction index
+	systemInstructionAdvanceNonceAccount = 4
+)
+
+// SysVarRecentBlockhashesPubkey is the RecentBlockhashes sysvar read by AdvanceNonceAccount
+var SysVarRecentBlockhashesPubkey = solana.MustPublicKeyFromBase58("SysvarRecentB1ockHashes11111111111111111111")
+
+// DurableNonce is a durable nonce account payment transactions are built against instead of a
+// recent blockhash, so they remain valid until settled rather than expiring after about two
+// minutes. Authority is the nonce account's authority, which must be one of the facilitator's
+// fee payers: it signs the AdvanceNonceAccount instruction along with the fee.
+type DurableNonce struct {
+	Account   solana.PublicKey
+	Authority solana.PublicKey
+}
+
+// NonceAccountInfo is the state of an initialized durable nonce account
+type NonceAccountInfo struct {
+	Authority solana.PublicKey // Account allowed to advance the nonce
+	Nonce     solana.Hash      // Value to use as the transaction's recent blockhash
+}
+
+// ParseNonceAccount decodes an initialized durable nonce account
+func ParseNonceAccount(data []byte) (*NonceAccountInfo, error) {
+	if len(data) < nonceAccountSize {
+		return nil, fmt.Errorf("nonce account too short: %d bytes", len(data))
+	}
+	if binary.LittleEndian.Uint32(data[4:8]) != nonceStateInitialized {
+		return nil, fmt.Errorf("nonce account is not initialized")
+	}
+
+	info := &NonceAccountInfo{}
+	copy(info.Authority[:], data[8:40])
+	copy(info.Nonce[:], data[40:72])
+	return info, nil
+}
+
+// GetNonceAccount reads and decodes a durable nonce account through rpcClient
+func GetNonceAccount(ctx context.Context, rpcClient *rpc.Client, account solana.PublicKey) (*NonceAccountInfo, error) {
+	result, err := rpcClient.GetAccountInfoWithOpts(ctx, account, &rpc.GetAccountInfoOpts{Commitment: DefaultCommitment})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce account: %w", err)
+	}
+	if result == nil || result.Value == nil {
+		return nil, fmt.Errorf("nonce account not found: %s", account)
+	}
+	if result.Value.Owner != solana.SystemProgramID {
+		return nil, fmt.Errorf("nonce account %s is not owned by the system program", account)
+	}
+
+	return ParseNonceAccount(result.Value.Data.GetBinary())
+}
+
+// NewAdvanceNonceInstruction builds the System program instruction that advances nonceAccount.
+// A transaction built against a durable nonce must start with it.
+func NewAdvanceNonceInstruction(nonceAccount solana.PublicKey, authority solana.PublicKey) solana.Instruction {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, systemInstructionAdvanceNonceAccount)
+
+	return solana.NewInstruction(
+		solana.SystemProgramID,
+		solana.AccountMetaSlice{
+			{PublicKey: nonceAccount, IsWritable: true},
+			{PublicKey: SysVarRecentBlockhashesPubkey},
+			{PublicKey: authority, IsSigner: true},
+		},
+		data,
+	)
+}
+
+// DecodeAdvanceNonceInstruction returns the nonce account and authority of an AdvanceNonceAccount
+// instruction; ok is false for any other instruction
+func DecodeAdvanceNonceInstruction(tx *solana.Transaction, inst solana.CompiledInstruction) (nonceAccount solana.PublicKey, authority solana.PublicKey, ok bool) {
+	if tx.Message.AccountKeys[inst.ProgramIDIndex] != solana.SystemProgramID {
+		return solana.PublicKey{}, solana.PublicKey{}, false
+	}
+	if len(inst.Data) != 4 || binary.LittleEndian.Uint32(inst.Data) != systemInstructionAdvanceNonceAccount {
+		return solana.PublicKey{}, solana.PublicKey{}, false
+	}
+
+	accounts, err := inst.ResolveInstructionAccounts(&tx.Message)
+	if err != nil || len(accounts) != 3 || accounts[1].PublicKey != SysVarRecentBlockhashesPubkey {
+		return solana.PublicKey{}, solana.PublicKey{}, false
+	}
+	return accounts[0].PublicKey, accounts[2].PublicKey, true
+}
+
+// NonceAccountFromExtra returns the durable nonce account requested in payment requirements extra
+func NonceAccountFromExtra(extra map[string]interface{}) (solana.PublicKey, bool) {
+	value, ok := extra[ExtraNonceAccount].(string)
+	if !ok || value == "" {
+		return solana.PublicKey{}, false
+	}
+	account, err := solana.PublicKeyFromBase58(value)
+	if err != nil {
+		return solana.PublicKey{}, false
+	}
+	return account, true
+}
@@ -0,0 +1,79 @@
+package svm
+
+import (
+	"encoding/binary"
+	"testing"
+
+	solana "github.com/gagliardetto/solana-go"
+)
+
+func TestParseNonceAccount(t *testing.T) {
+	authority := solana.NewWallet().PublicKey()
+	nonce := solana.Hash(solana.NewWallet().PublicKey())
+
+	data := make([]byte, nonceAccountSize)
+	binary.LittleEndian.PutUint32(data[0:4], 1) // current version
+	binary.LittleEndian.PutUint32(data[4:8], nonceStateInitialized)
+	copy(data[8:40], authority[:])
+	copy(data[40:72], nonce[:])
+
+	info, err := ParseNonceAccount(data)
+	if err != nil {
+		t.Fatalf("ParseNonceAccount() failed: %v", err)
+	}
+	if info.Authority != authority || info.Nonce != nonce {
+		t.Errorf("ParseNonceAccount() = (%s, %s), want (%s, %s)", info.Authority, info.Nonce, authority, nonce)
+	}
+
+	binary.LittleEndian.PutUint32(data[4:8], 0)
+	if _, err := ParseNonceAccount(data); err == nil {
+		t.Error("Expected an error for an uninitialized nonce account")
+	}
+	if _, err := ParseNonceAccount(data[:40]); err == nil {
+		t.Error("Expected an error for a truncated nonce account")
+	}
+}
+
+func TestAdvanceNonceInstruction(t *testing.T) {
+	nonceAccount := solana.NewWallet().PublicKey()
+	authority := solana.NewWallet().PublicKey()
+
+	tx, err := solana.NewTransactionBuilder().
+		AddInstruction(NewAdvanceNonceInstruction(nonceAccount, authority)).
+		SetRecentBlockHash(solana.Hash{1}).
+		SetFeePayer(authority).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+
+	gotAccount, gotAuthority, ok := DecodeAdvanceNonceInstruction(tx, tx.Message.Instructions[0])
+	if !ok {
+		t.Fatal("Expected the advance nonce instruction to decode")
+	}
+	if gotAccount != nonceAccount || gotAuthority != authority {
+		t.Errorf("DecodeAdvanceNonceInstruction() = (%s, %s), want (%s, %s)", gotAccount, gotAuthority, nonceAccount, authority)
+	}
+
+	// Any other system instruction is not an advance
+	other := tx.Message.Instructions[0]
+	other.Data = []byte{2, 0, 0, 0}
+	if _, _, ok := DecodeAdvanceNonceInstruction(tx, other); ok {
+		t.Error("Expected a transfer instruction not to decode as an advance")
+	}
+}
+
+func TestNonceAccountFromExtra(t *testing.T) {
+	account := solana.NewWallet().PublicKey()
+
+	got, ok := NonceAccountFromExtra(map[string]interface{}{ExtraNonceAccount: account.String()})
+	if !ok || got != account {
+		t.Errorf("NonceAccountFromExtra() = (%s, %v), want (%s, true)", got, ok, account)
+	}
+	if _, ok := NonceAccountFromExtra(map[string]interface{}{}); ok {
+		t.Error("Expected no nonce account without the extra key")
+	}
+	if _, ok := NonceAccountFromExtra(map[string]interface{}{ExtraNonceAccount: "not-base58!"}); ok {
+		t.Error("Expected no nonce account for an invalid address")
+	}
+}
@@ -0,0 +1,341 @@
+package svm
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	bin "github.com/gagliardetto/binary"
+	solana "github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	// mintBaseSize is the size of a classic SPL Token mint account
+	mintBaseSize = 82
+
+	// token2022AccountTypeOffset is where Token-2022 stores the account type byte.
+	// Mints are padded to the token account size so both kinds share the same extension layout.
+	token2022AccountTypeOffset = 165
+
+	// token2022AccountTypeMint marks a Token-2022 account as a mint
+	token2022AccountTypeMint = 1
+
+	// extensionTypeTransferFeeConfig is the Token-2022 TLV type of the TransferFeeConfig extension
+	extensionTypeTransferFeeConfig = 1
+
+	// transferFeeConfigSize is the length of the TransferFeeConfig extension value
+	transferFeeConfigSize = 108
+
+	// tokenAccountAmountOffset is where a token account stores its amount, after the mint and owner
+	tokenAccountAmountOffset = 64
+
+	// MaxTransferFeeBasisPoints is the Token-2022 upper bound for a transfer fee (100%)
+	MaxTransferFeeBasisPoints = 10_000
+)
+
+// TransferFee is one epoch's fee schedule from a Token-2022 TransferFeeConfig extension
+type TransferFee struct {
+	Epoch       uint64 // First epoch the fee applies to
+	MaximumFee  uint64 // Fee cap in token base units
+	BasisPoints uint16 // Fee rate in basis points of the transferred amount
+}
+
+// TransferFeeConfig holds the older and newer fee schedules of a Token-2022 mint.
+// A fee change only takes effect from the newer schedule's epoch on.
+type TransferFeeConfig struct {
+	Older TransferFee
+	Newer TransferFee
+}
+
+// MintInfo describes a token mint and the program that owns it
+type MintInfo struct {
+	ProgramID   solana.PublicKey   // TokenProgramID or Token2022ProgramID
+	Decimals    uint8              // Token decimals
+	TransferFee *TransferFeeConfig // Token-2022 transfer fee, nil when the mint charges none
+}
+
+// IsTokenProgram reports whether programID is the SPL Token or Token-2022 program
+func IsTokenProgram(programID solana.PublicKey) bool {
+	return programID == solana.TokenProgramID || programID == solana.Token2022ProgramID
+}
+
+// FindAssociatedTokenAddress derives the associated token account of wallet for mint under tokenProgram.
+// Token-2022 accounts live at a different address than classic SPL Token accounts for the same wallet and mint.
+func FindAssociatedTokenAddress(wallet solana.PublicKey, mint solana.PublicKey, tokenProgram solana.PublicKey) (solana.PublicKey, error) {
+	address, _, err := solana.FindProgramAddress(
+		[][]byte{wallet[:], tokenProgram[:], mint[:]},
+		solana.SPLAssociatedTokenAccountProgramID,
+	)
+	return address, err
+}
+
+// ParseMintAccount decodes a mint account owned by owner, including the
+// Token-2022 TransferFeeConfig extension when present
+func ParseMintAccount(owner solana.PublicKey, data []byte) (*MintInfo, error) {
+	if !IsTokenProgram(owner) {
+		return nil, fmt.Errorf("asset was not created by a known token program")
+	}
+	if len(data) < mintBaseSize {
+		return nil, fmt.Errorf("mint account too short: %d bytes", len(data))
+	}
+
+	var mint token.Mint
+	if err := bin.NewBinDecoder(data[:mintBaseSize]).Decode(&mint); err != nil {
+		return nil, fmt.Errorf("failed to decode mint data: %w", err)
+	}
+
+	info := &MintInfo{
+		ProgramID: owner,
+		Decimals:  mint.Decimals,
+	}
+
+	if owner == solana.Token2022ProgramID && len(data) > token2022AccountTypeOffset {
+		if data[token2022AccountTypeOffset] != token2022AccountTypeMint {
+			return nil, fmt.Errorf("token-2022 account is not a mint")
+		}
+		fee, err := parseTransferFeeConfig(data[token2022AccountTypeOffset+1:])
+		if err != nil {
+			return nil, err
+		}
+		info.TransferFee = fee
+	}
+
+	return info, nil
+}
+
+// parseTransferFeeConfig walks Token-2022 TLV extension data looking for TransferFeeConfig
+func parseTransferFeeConfig(tlv []byte) (*TransferFeeConfig, error) {
+	for len(tlv) >= 4 {
+		extType := binary.LittleEndian.Uint16(tlv[0:2])
+		length := int(binary.LittleEndian.Uint16(tlv[2:4]))
+		if extType == 0 {
+			// Uninitialized space after the last extension
+			break
+		}
+		if len(tlv) < 4+length {
+			return nil, fmt.Errorf("malformed token-2022 extension data")
+		}
+
+		value := tlv[4 : 4+length]
+		if extType == extensionTypeTransferFeeConfig {
+			if length != transferFeeConfigSize {
+				return nil, fmt.Errorf("invalid transfer fee config length: %d", length)
+			}
+			// Skip the two authorities (32 bytes each) and the withheld amount (8 bytes)
+			return &TransferFeeConfig{
+				Older: parseTransferFee(value[72:90]),
+				Newer: parseTransferFee(value[90:108]),
+			}, nil
+		}
+
+		tlv = tlv[4+length:]
+	}
+
+	return nil, nil
+}
+
+func parseTransferFee(data []byte) TransferFee {
+	return TransferFee{
+		Epoch:       binary.LittleEndian.Uint64(data[0:8]),
+		MaximumFee:  binary.LittleEndian.Uint64(data[8:16]),
+		BasisPoints: binary.LittleEndian.Uint16(data[16:18]),
+	}
+}
+
+// ForEpoch returns the fee schedule in effect at epoch
+func (c *TransferFeeConfig) ForEpoch(epoch uint64) TransferFee {
+	if epoch >= c.Newer.Epoch {
+		return c.Newer
+	}
+	return c.Older
+}
+
+// Fee returns the fee withheld when amount is transferred, rounded up and capped at MaximumFee
+func (f TransferFee) Fee(amount uint64) uint64 {
+	if f.BasisPoints == 0 || amount == 0 {
+		return 0
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(amount), big.NewInt(int64(f.BasisPoints)))
+	fee.Add(fee, big.NewInt(MaxTransferFeeBasisPoints-1))
+	fee.Div(fee, big.NewInt(MaxTransferFeeBasisPoints))
+	if !fee.IsUint64() || fee.Uint64() > f.MaximumFee {
+		return f.MaximumFee
+	}
+	return fee.Uint64()
+}
+
+// AmountBeforeFee returns the smallest amount to transfer so that at least net arrives after the fee
+func (f TransferFee) AmountBeforeFee(net uint64) (uint64, error) {
+	if f.BasisPoints == 0 || net == 0 {
+		return net, nil
+	}
+
+	var gross uint64
+	if f.BasisPoints >= MaxTransferFeeBasisPoints {
+		gross = net + f.MaximumFee
+	} else {
+		// ceil(net * 10000 / (10000 - bps))
+		numerator := new(big.Int).Mul(new(big.Int).SetUint64(net), big.NewInt(MaxTransferFeeBasisPoints))
+		denominator := big.NewInt(MaxTransferFeeBasisPoints - int64(f.BasisPoints))
+		numerator.Add(numerator, new(big.Int).Sub(denominator, big.NewInt(1)))
+		raw := numerator.Div(numerator, denominator)
+
+		if !raw.IsUint64() || raw.Uint64()-net >= f.MaximumFee {
+			gross = net + f.MaximumFee
+		} else {
+			gross = raw.Uint64()
+		}
+	}
+
+	if gross < net {
+		return 0, fmt.Errorf("amount %d plus transfer fee overflows", net)
+	}
+	return gross, nil
+}
+
+// AmountAfterFee returns what the recipient receives when amount is transferred at epoch
+func (m *MintInfo) AmountAfterFee(amount uint64, epoch uint64) uint64 {
+	if m.TransferFee == nil {
+		return amount
+	}
+	return amount - m.TransferFee.ForEpoch(epoch).Fee(amount)
+}
+
+// AmountBeforeFee returns the amount to transfer at epoch so the recipient receives at least net
+func (m *MintInfo) AmountBeforeFee(net uint64, epoch uint64) (uint64, error) {
+	if m.TransferFee == nil {
+		return net, nil
+	}
+	return m.TransferFee.ForEpoch(epoch).AmountBeforeFee(net)
+}
+
+// GetMintInfo reads and decodes a mint account through rpcClient
+func GetMintInfo(ctx context.Context, rpcClient *rpc.Client, mint solana.PublicKey) (*MintInfo, error) {
+	account, err := rpcClient.GetAccountInfo(ctx, mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mint account: %w", err)
+	}
+	if account == nil || account.Value == nil {
+		return nil, fmt.Errorf("mint account not found: %s", mint)
+	}
+
+	return ParseMintAccount(account.Value.Owner, account.Value.Data.GetBinary())
+}
+
+// ParseTokenAccountBalance decodes the amount held by a token account. Classic SPL Token and
+// Token-2022 accounts share the same base layout.
+func ParseTokenAccountBalance(data []byte) (uint64, error) {
+	if len(data) < tokenAccountAmountOffset+8 {
+		return 0, fmt.Errorf("token account too short: %d bytes", len(data))
+	}
+	return binary.LittleEndian.Uint64(data[tokenAccountAmountOffset:]), nil
+}
+
+// GetTokenBalance reads the balance of owner's associated token account for mint under
+// tokenProgram through rpcClient. An account that doesn't exist yet holds nothing.
+func GetTokenBalance(ctx context.Context, rpcClient *rpc.Client, owner solana.PublicKey, mint solana.PublicKey, tokenProgram solana.PublicKey) (uint64, error) {
+	ata, err := FindAssociatedTokenAddress(owner, mint, tokenProgram)
+	if err != nil {
+		return 0, fmt.Errorf("failed to derive associated token account: %w", err)
+	}
+
+	account, err := rpcClient.GetAccountInfoWithOpts(ctx, ata, &rpc.GetAccountInfoOpts{Commitment: DefaultCommitment})
+	if errors.Is(err, rpc.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get token account: %w", err)
+	}
+	if account == nil || account.Value == nil {
+		return 0, nil
+	}
+
+	return ParseTokenAccountBalance(account.Value.Data.GetBinary())
+}
+
+// GetCurrentEpoch returns the cluster's current epoch, which selects the active transfer fee
+func GetCurrentEpoch(ctx context.Context, rpcClient *rpc.Client) (uint64, error) {
+	epochInfo, err := rpcClient.GetEpochInfo(ctx, DefaultCommitment)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get epoch info: %w", err)
+	}
+	return epochInfo.Epoch, nil
+}
+
+// NewTransferCheckedInstruction builds a TransferChecked instruction for tokenProgram.
+// The solana-go token builder always targets the classic SPL Token program, so the
+// instruction is rebuilt against Token-2022 when the mint requires it; both programs
+// share the TransferChecked encoding.
+func NewTransferCheckedInstruction(
+	tokenProgram solana.PublicKey,
+	amount uint64,
+	decimals uint8,
+	source solana.PublicKey,
+	mint solana.PublicKey,
+	destination solana.PublicKey,
+	owner solana.PublicKey,
+) (solana.Instruction, error) {
+	ix, err := token.NewTransferCheckedInstructionBuilder().
+		SetAmount(amount).
+		SetDecimals(decimals).
+		SetSourceAccount(source).
+		SetMintAccount(mint).
+		SetDestinationAccount(destination).
+		SetOwnerAccount(owner).
+		ValidateAndBuild()
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenProgram == solana.TokenProgramID {
+		return ix, nil
+	}
+
+	data, err := ix.Data()
+	if err != nil {
+		return nil, err
+	}
+	return solana.NewInstruction(tokenProgram, ix.Accounts(), data), nil
+}
+
+// AmountReceived returns what the recipient gets from a TransferChecked of amount under tokenProgram.
+// Only Token-2022 mints can withhold a transfer fee. The fee is read through signer when it
+// implements MintReader; otherwise amount is returned unchanged.
+func AmountReceived(
+	ctx context.Context,
+	signer FacilitatorSvmSigner,
+	tokenProgram solana.PublicKey,
+	mint solana.PublicKey,
+	amount uint64,
+	network string,
+) (uint64, error) {
+	if tokenProgram != solana.Token2022ProgramID {
+		return amount, nil
+	}
+	reader, ok := signer.(MintReader)
+	if !ok {
+		return amount, nil
+	}
+
+	mintInfo, err := reader.GetMintInfo(ctx, mint, network)
+	if err != nil {
+		return 0, err
+	}
+	if mintInfo.ProgramID != tokenProgram {
+		return 0, fmt.Errorf("mint %s is owned by %s, not %s", mint, mintInfo.ProgramID, tokenProgram)
+	}
+	if mintInfo.TransferFee == nil {
+		return amount, nil
+	}
+
+	epoch, err := reader.GetCurrentEpoch(ctx, network)
+	if err != nil {
+		return 0, err
+	}
+	return mintInfo.AmountAfterFee(amount, epoch), nil
+}
@@ -0,0 +1,122 @@
+package svm
+
+import (
+	"encoding/binary"
+	"testing"
+
+	solana "github.com/gagliardetto/solana-go"
+)
+
+// token2022MintData builds Token-2022 mint account data with a TransferFeeConfig extension
+func token2022MintData(decimals uint8, older, newer TransferFee) []byte {
+	data := make([]byte, token2022AccountTypeOffset+1+4+transferFeeConfigSize)
+	data[44] = decimals // mint authority option (4) + authority (32) + supply (8)
+	data[45] = 1        // is_initialized
+	data[token2022AccountTypeOffset] = token2022AccountTypeMint
+
+	tlv := data[token2022AccountTypeOffset+1:]
+	binary.LittleEndian.PutUint16(tlv[0:2], extensionTypeTransferFeeConfig)
+	binary.LittleEndian.PutUint16(tlv[2:4], transferFeeConfigSize)
+	for i, fee := range []TransferFee{older, newer} {
+		offset := 4 + 72 + i*18
+		binary.LittleEndian.PutUint64(tlv[offset:], fee.Epoch)
+		binary.LittleEndian.PutUint64(tlv[offset+8:], fee.MaximumFee)
+		binary.LittleEndian.PutUint16(tlv[offset+16:], fee.BasisPoints)
+	}
+	return data
+}
+
+func TestParseMintAccount(t *testing.T) {
+	older := TransferFee{Epoch: 0, MaximumFee: 5000, BasisPoints: 50}
+	newer := TransferFee{Epoch: 600, MaximumFee: 10000, BasisPoints: 100}
+
+	info, err := ParseMintAccount(solana.Token2022ProgramID, token2022MintData(6, older, newer))
+	if err != nil {
+		t.Fatalf("ParseMintAccount() failed: %v", err)
+	}
+	if info.ProgramID != solana.Token2022ProgramID || info.Decimals != 6 {
+		t.Errorf("Unexpected mint info: %+v", info)
+	}
+	if info.TransferFee == nil || info.TransferFee.Older != older || info.TransferFee.Newer != newer {
+		t.Fatalf("Unexpected transfer fee config: %+v", info.TransferFee)
+	}
+	if fee := info.TransferFee.ForEpoch(599); fee != older {
+		t.Errorf("Expected older fee before epoch 600, got %+v", fee)
+	}
+	if fee := info.TransferFee.ForEpoch(600); fee != newer {
+		t.Errorf("Expected newer fee from epoch 600, got %+v", fee)
+	}
+
+	// Classic mints have no extensions
+	classic := make([]byte, mintBaseSize)
+	classic[44] = 9
+	info, err = ParseMintAccount(solana.TokenProgramID, classic)
+	if err != nil {
+		t.Fatalf("ParseMintAccount() failed: %v", err)
+	}
+	if info.Decimals != 9 || info.TransferFee != nil {
+		t.Errorf("Unexpected classic mint info: %+v", info)
+	}
+
+	if _, err := ParseMintAccount(solana.SystemProgramID, classic); err == nil {
+		t.Error("Expected error for a mint not owned by a token program")
+	}
+}
+
+func TestTransferFeeAmounts(t *testing.T) {
+	tests := []struct {
+		name      string
+		fee       TransferFee
+		net       uint64
+		wantGross uint64
+	}{
+		{"no fee", TransferFee{}, 1000000, 1000000},
+		{"one percent", TransferFee{MaximumFee: 1 << 62, BasisPoints: 100}, 100, 102},
+		{"capped", TransferFee{MaximumFee: 500, BasisPoints: 100}, 1000000, 1000500},
+		{"full rate", TransferFee{MaximumFee: 7, BasisPoints: MaxTransferFeeBasisPoints}, 100, 107},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gross, err := tt.fee.AmountBeforeFee(tt.net)
+			if err != nil {
+				t.Fatalf("AmountBeforeFee() failed: %v", err)
+			}
+			if gross != tt.wantGross {
+				t.Errorf("AmountBeforeFee(%d) = %d, want %d", tt.net, gross, tt.wantGross)
+			}
+			if received := gross - tt.fee.Fee(gross); received < tt.net {
+				t.Errorf("Recipient receives %d, want at least %d", received, tt.net)
+			}
+		})
+	}
+
+	// The recipient always gets at least the net amount, without overpaying by more than one unit
+	fee := TransferFee{MaximumFee: 1 << 62, BasisPoints: 333}
+	for net := uint64(1); net < 5000; net++ {
+		gross, _ := fee.AmountBeforeFee(net)
+		if gross-fee.Fee(gross) < net {
+			t.Fatalf("AmountBeforeFee(%d) = %d leaves %d", net, gross, gross-fee.Fee(gross))
+		}
+		if prev := gross - 1; prev-fee.Fee(prev) >= net {
+			t.Fatalf("AmountBeforeFee(%d) = %d is not minimal", net, gross)
+		}
+	}
+}
+
+func TestParseTokenAccountBalance(t *testing.T) {
+	data := make([]byte, 165)
+	binary.LittleEndian.PutUint64(data[tokenAccountAmountOffset:], 1_500_000)
+
+	balance, err := ParseTokenAccountBalance(data)
+	if err != nil {
+		t.Fatalf("ParseTokenAccountBalance() failed: %v", err)
+	}
+	if balance != 1_500_000 {
+		t.Errorf("balance = %d, want 1500000", balance)
+	}
+
+	if _, err := ParseTokenAccountBalance(data[:70]); err == nil {
+		t.Error("Expected an error for a truncated token account")
+	}
+}
@@ -54,6 +54,26 @@ type FacilitatorSvmSigner interface {
 	ConfirmTransaction(ctx context.Context, signature solana.Signature, network string) error
 }
 
+// MintReader is an optional FacilitatorSvmSigner capability for reading token mints.
+// When the signer implements it, the exact facilitator accounts for Token-2022 transfer
+// fees so the recipient is guaranteed the required amount after the fee is withheld.
+type MintReader interface {
+	// GetMintInfo reads a mint account, including its owning program and transfer fee config
+	GetMintInfo(ctx context.Context, mint solana.PublicKey, network string) (*MintInfo, error)
+
+	// GetCurrentEpoch returns the cluster's current epoch
+	GetCurrentEpoch(ctx context.Context, network string) (uint64, error)
+}
+
+// TokenBalanceReader is an optional FacilitatorSvmSigner capability for reading token balances.
+// When the signer implements it, the exact facilitator rejects payers whose associated token
+// account can't cover the transfer with insufficient_balance, before simulating the transaction.
+type TokenBalanceReader interface {
+	// GetTokenBalance returns the balance of owner's associated token account for mint under
+	// tokenProgram, or zero when the account doesn't exist yet
+	GetTokenBalance(ctx context.Context, owner solana.PublicKey, mint solana.PublicKey, tokenProgram solana.PublicKey, network string) (uint64, error)
+}
+
 // AssetInfo contains information about a SPL token
 type AssetInfo struct {
 	Address  string // Mint address
@@ -77,7 +77,7 @@ func GetAssetInfo(network string, assetSymbolOrAddress string) (*AssetInfo, erro
 		// Unknown token - return basic info with default decimals
 		return &AssetInfo{
 			Address:  assetSymbolOrAddress,
-			Symbol:   "UNKNOWN",
+			Symbol:   UnknownAssetSymbol,
 			Decimals: 9, // Solana default decimals
 		}, nil
 	}
@@ -0,0 +1,81 @@
+package x402
+
+import (
+	"errors"
+	"time"
+)
+
+// ============================================================================
+// Facilitator Metrics
+// ============================================================================
+
+// MetricsCollector receives the outcome and latency of every facilitator verify and settle call.
+// See metrics/prometheus for a Prometheus implementation.
+//
+// reason is empty when the payment verified or settled successfully and holds the failure
+// reason otherwise (one of the Reason constants, or a mechanism-specific value). scheme and
+// network are empty when the request could not be decoded.
+// Implementations must be safe for concurrent use and should not block.
+type MetricsCollector interface {
+	// ObserveVerify records a completed Verify call
+	ObserveVerify(scheme, network, reason string, d time.Duration)
+
+	// ObserveSettle records a completed settlement
+	ObserveSettle(scheme, network, reason string, d time.Duration)
+}
+
+const (
+	// metricsReasonInvalid labels invalid verify/settle responses that carry no reason
+	metricsReasonInvalid = "invalid"
+	// metricsReasonError labels failures that are not a VerifyError or SettleError (e.g. hook errors)
+	metricsReasonError = "error"
+)
+
+// WithMetrics reports verify and settle outcomes to collector.
+// Metrics are off by default, and a nil collector turns them off again.
+func (f *x402Facilitator) WithMetrics(collector MetricsCollector) *x402Facilitator {
+	f.metrics = collector
+	return f
+}
+
+// verifyMetricReason returns the metrics reason label for a verify outcome
+func verifyMetricReason(result *VerifyResponse, err error) string {
+	if err != nil {
+		return errorMetricReason(err)
+	}
+	if result == nil || !result.IsValid {
+		if result != nil && result.InvalidReason != "" {
+			return result.InvalidReason
+		}
+		return metricsReasonInvalid
+	}
+	return ""
+}
+
+// settleMetricReason returns the metrics reason label for a settle outcome
+func settleMetricReason(result *SettleResponse, err error) string {
+	if err != nil {
+		return errorMetricReason(err)
+	}
+	if result == nil || !result.Success {
+		if result != nil && result.ErrorReason != "" {
+			return result.ErrorReason
+		}
+		return metricsReasonInvalid
+	}
+	return ""
+}
+
+// errorMetricReason extracts the reason from a VerifyError or SettleError.
+// Other errors get a fixed label so arbitrary messages can't blow up label cardinality.
+func errorMetricReason(err error) string {
+	var ve *VerifyError
+	if errors.As(err, &ve) && ve.Reason != "" {
+		return ve.Reason
+	}
+	var se *SettleError
+	if errors.As(err, &se) && se.Reason != "" {
+		return se.Reason
+	}
+	return metricsReasonError
+}
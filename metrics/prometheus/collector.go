@@ -0,0 +1,144 @@
+// Package prometheus exports x402 facilitator verify and settle metrics to Prometheus.
+//
+//	collector := prometheus.NewCollector()
+//	registry.MustRegister(collector)
+//	facilitator := x402.Newx402Facilitator().WithMetrics(collector)
+package prometheus
+
+import (
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	x402 "x402-go"
+)
+
+const (
+	// DefaultNamespace prefixes every metric name
+	DefaultNamespace = "x402"
+
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+// DefaultBuckets are the latency histogram buckets in seconds. Settlement waits for the
+// transaction to be mined, so the buckets reach well past typical block times.
+var DefaultBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Config customizes the collector
+type Config struct {
+	Namespace   string      // Metric name prefix (default DefaultNamespace)
+	Buckets     []float64   // Latency histogram buckets in seconds (default DefaultBuckets)
+	ConstLabels prom.Labels // Labels added to every metric (e.g. instance or region)
+}
+
+// Collector implements x402.MetricsCollector with Prometheus counters and histograms.
+// It is also a prometheus.Collector, so it can be registered with any registry.
+//
+// Exported metrics (with the default namespace):
+//
+//	x402_facilitator_verify_total{scheme,network,result,reason}
+//	x402_facilitator_verify_duration_seconds{scheme,network,result}
+//	x402_facilitator_settle_total{scheme,network,result,reason}
+//	x402_facilitator_settle_duration_seconds{scheme,network,result}
+//
+// result is "success" or "failure"; reason is the failure reason and empty on success.
+type Collector struct {
+	verifyTotal    *prom.CounterVec
+	verifyDuration *prom.HistogramVec
+	settleTotal    *prom.CounterVec
+	settleDuration *prom.HistogramVec
+}
+
+var _ x402.MetricsCollector = (*Collector)(nil)
+var _ prom.Collector = (*Collector)(nil)
+
+// NewCollector creates a collector. Register it with a Prometheus registry before use.
+func NewCollector(config ...*Config) *Collector {
+	cfg := &Config{}
+	if len(config) > 0 && config[0] != nil {
+		cfg = config[0]
+	}
+
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	buckets := cfg.Buckets
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	countLabels := []string{"scheme", "network", "result", "reason"}
+	durationLabels := []string{"scheme", "network", "result"}
+
+	return &Collector{
+		verifyTotal: prom.NewCounterVec(prom.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "facilitator",
+			Name:        "verify_total",
+			Help:        "Payment verifications by scheme, network, result and failure reason.",
+			ConstLabels: cfg.ConstLabels,
+		}, countLabels),
+		verifyDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   "facilitator",
+			Name:        "verify_duration_seconds",
+			Help:        "Payment verification latency in seconds.",
+			Buckets:     buckets,
+			ConstLabels: cfg.ConstLabels,
+		}, durationLabels),
+		settleTotal: prom.NewCounterVec(prom.CounterOpts{
+			Namespace:   namespace,
+			Subsystem:   "facilitator",
+			Name:        "settle_total",
+			Help:        "Payment settlements by scheme, network, result and failure reason.",
+			ConstLabels: cfg.ConstLabels,
+		}, countLabels),
+		settleDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace:   namespace,
+			Subsystem:   "facilitator",
+			Name:        "settle_duration_seconds",
+			Help:        "Payment settlement latency in seconds, including waiting for the transaction receipt.",
+			Buckets:     buckets,
+			ConstLabels: cfg.ConstLabels,
+		}, durationLabels),
+	}
+}
+
+// ObserveVerify implements x402.MetricsCollector
+func (c *Collector) ObserveVerify(scheme, network, reason string, d time.Duration) {
+	result := resultLabel(reason)
+	c.verifyTotal.WithLabelValues(scheme, network, result, reason).Inc()
+	c.verifyDuration.WithLabelValues(scheme, network, result).Observe(d.Seconds())
+}
+
+// ObserveSettle implements x402.MetricsCollector
+func (c *Collector) ObserveSettle(scheme, network, reason string, d time.Duration) {
+	result := resultLabel(reason)
+	c.settleTotal.WithLabelValues(scheme, network, result, reason).Inc()
+	c.settleDuration.WithLabelValues(scheme, network, result).Observe(d.Seconds())
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	c.verifyTotal.Describe(ch)
+	c.verifyDuration.Describe(ch)
+	c.settleTotal.Describe(ch)
+	c.settleDuration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	c.verifyTotal.Collect(ch)
+	c.verifyDuration.Collect(ch)
+	c.settleTotal.Collect(ch)
+	c.settleDuration.Collect(ch)
+}
+
+func resultLabel(reason string) string {
+	if reason == "" {
+		return resultSuccess
+	}
+	return resultFailure
+}
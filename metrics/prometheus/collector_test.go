@@ -0,0 +1,46 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	x402 "x402-go"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector()
+	registry := prom.NewRegistry()
+	registry.MustRegister(collector)
+
+	collector.ObserveVerify("exact", "eip155:8453", "", 20*time.Millisecond)
+	collector.ObserveVerify("exact", "eip155:8453", "", 30*time.Millisecond)
+	collector.ObserveVerify("exact", "eip155:8453", x402.ReasonInvalidSignature, 10*time.Millisecond)
+	collector.ObserveSettle("exact", "eip155:8453", "", 2*time.Second)
+
+	if got := testutil.ToFloat64(collector.verifyTotal.WithLabelValues("exact", "eip155:8453", resultSuccess, "")); got != 2 {
+		t.Errorf("Expected 2 successful verifications, got %v", got)
+	}
+	if got := testutil.ToFloat64(collector.verifyTotal.WithLabelValues("exact", "eip155:8453", resultFailure, x402.ReasonInvalidSignature)); got != 1 {
+		t.Errorf("Expected 1 failed verification, got %v", got)
+	}
+	if got := testutil.ToFloat64(collector.settleTotal.WithLabelValues("exact", "eip155:8453", resultSuccess, "")); got != 1 {
+		t.Errorf("Expected 1 settlement, got %v", got)
+	}
+
+	// Two verify counters, two verify histograms, one settle counter and one settle histogram
+	if count := testutil.CollectAndCount(collector); count != 6 {
+		t.Errorf("Expected 6 series, got %d", count)
+	}
+}
+
+func TestCollectorConfig(t *testing.T) {
+	collector := NewCollector(&Config{Namespace: "payments", ConstLabels: prom.Labels{"region": "eu"}})
+	collector.ObserveSettle("exact", "solana:mainnet", x402.ReasonTransactionFailed, time.Second)
+
+	if count := testutil.CollectAndCount(collector, "payments_facilitator_settle_total"); count != 1 {
+		t.Errorf("Expected the custom namespace to be used, got %d series", count)
+	}
+}
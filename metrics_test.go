@@ -0,0 +1,154 @@
+package x402
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"x402-go/types"
+)
+
+// metricsObservation is a single call recorded by recordingMetrics
+type metricsObservation struct {
+	op      string
+	scheme  string
+	network string
+	reason  string
+}
+
+// recordingMetrics is a MetricsCollector that records every observation
+type recordingMetrics struct {
+	mu           sync.Mutex
+	observations []metricsObservation
+}
+
+func (m *recordingMetrics) ObserveVerify(scheme, network, reason string, d time.Duration) {
+	m.record("verify", scheme, network, reason)
+}
+
+func (m *recordingMetrics) ObserveSettle(scheme, network, reason string, d time.Duration) {
+	m.record("settle", scheme, network, reason)
+}
+
+func (m *recordingMetrics) record(op, scheme, network, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observations = append(m.observations, metricsObservation{op, scheme, network, reason})
+}
+
+func TestFacilitatorMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	mock := &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		verifyFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*VerifyResponse, error) {
+			if payload.Payload["signature"] == "bad" {
+				return nil, NewVerifyError(ReasonInvalidSignature, "0xpayer", Network(requirements.Network), nil)
+			}
+			return &VerifyResponse{IsValid: true, Payer: "0xpayer"}, nil
+		},
+	}
+
+	metrics := &recordingMetrics{}
+	facilitator := Newx402Facilitator().WithMetrics(metrics)
+	facilitator.Register([]Network{"eip155:1"}, mock)
+
+	marshal := func(network, signature string) ([]byte, []byte) {
+		requirements := types.PaymentRequirements{
+			Scheme:  "exact",
+			Network: network,
+			Asset:   "USDC",
+			Amount:  "1000000",
+			PayTo:   "0xrecipient",
+		}
+		payload := types.PaymentPayload{
+			X402Version: 2,
+			Accepted:    requirements,
+			Payload:     map[string]interface{}{"signature": signature},
+		}
+		payloadBytes, _ := json.Marshal(payload)
+		requirementsBytes, _ := json.Marshal(requirements)
+		return payloadBytes, requirementsBytes
+	}
+
+	payloadBytes, requirementsBytes := marshal("eip155:1", "good")
+	_, _ = facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+	_, _ = facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+
+	payloadBytes, requirementsBytes = marshal("eip155:1", "bad")
+	_, _ = facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+
+	payloadBytes, requirementsBytes = marshal("solana:mainnet", "good")
+	_, _ = facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+
+	_, _ = facilitator.Verify(ctx, []byte("not json"), []byte("not json"))
+
+	expected := []metricsObservation{
+		{"verify", "exact", "eip155:1", ""},
+		{"settle", "exact", "eip155:1", ""},
+		{"verify", "exact", "eip155:1", ReasonInvalidSignature},
+		{"verify", "exact", "solana:mainnet", ReasonNoFacilitatorForNetwork},
+		{"verify", "", "", ReasonInvalidVersion},
+	}
+	if len(metrics.observations) != len(expected) {
+		t.Fatalf("Expected %d observations, got %d: %+v", len(expected), len(metrics.observations), metrics.observations)
+	}
+	for i, want := range expected {
+		if got := metrics.observations[i]; got != want {
+			t.Errorf("Observation %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	// Removing the collector stops reporting
+	facilitator.WithMetrics(nil)
+	payloadBytes, requirementsBytes = marshal("eip155:1", "good")
+	_, _ = facilitator.Verify(ctx, payloadBytes, requirementsBytes)
+	if len(metrics.observations) != len(expected) {
+		t.Errorf("Expected no observations after WithMetrics(nil), got %d", len(metrics.observations))
+	}
+}
+
+func TestFacilitatorMetricsSettleBatch(t *testing.T) {
+	ctx := context.Background()
+
+	mock := &mockBatchSchemeNetworkFacilitator{
+		mockSchemeNetworkFacilitator: mockSchemeNetworkFacilitator{scheme: "exact"},
+		batchFunc: func(ctx context.Context, payloads []types.PaymentPayload, requirements []types.PaymentRequirements) ([]*SettleResponse, error) {
+			results := make([]*SettleResponse, len(payloads))
+			results[0] = &SettleResponse{Success: true, Transaction: "0xbatch", Network: Network(requirements[0].Network)}
+			for i := 1; i < len(payloads); i++ {
+				results[i] = &SettleResponse{Success: false, ErrorReason: ReasonInsufficientBalance, Network: Network(requirements[i].Network)}
+			}
+			return results, nil
+		},
+	}
+
+	metrics := &recordingMetrics{}
+	facilitator := Newx402Facilitator().WithMetrics(metrics)
+	facilitator.Register([]Network{"eip155:1"}, mock)
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "1", PayTo: "0xrecipient"}
+	payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}}
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+	request := SettlementRequest{PayloadBytes: payloadBytes, RequirementsBytes: requirementsBytes}
+
+	if _, err := facilitator.SettleBatch(ctx, []SettlementRequest{request, request}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []metricsObservation{
+		{"settle", "exact", "eip155:1", ""},
+		{"settle", "exact", "eip155:1", ReasonInsufficientBalance},
+	}
+	if len(metrics.observations) != len(expected) {
+		t.Fatalf("Expected %d observations, got %+v", len(expected), metrics.observations)
+	}
+	for i, want := range expected {
+		if got := metrics.observations[i]; got != want {
+			t.Errorf("Observation %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+}
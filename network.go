@@ -0,0 +1,107 @@
+package x402
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// caip2Pattern matches a CAIP-2 chain ID (namespace ":" reference), with "*" accepted as a
+// wildcard reference
+var caip2Pattern = regexp.MustCompile(`^([-a-z0-9]{3,8}):([-_a-zA-Z0-9]{1,32}|\*)$`)
+
+// v1NetworkPattern matches a legacy V1 network name such as "base-sepolia" or "solana-devnet"
+var v1NetworkPattern = regexp.MustCompile(`^[a-z0-9][-a-z0-9]*$`)
+
+// eip155ReferencePattern matches an EVM chain ID
+var eip155ReferencePattern = regexp.MustCompile(`^[1-9][0-9]*$`)
+
+// knownNamespaces are the CAIP-2 namespaces implemented by this module's mechanisms.
+// Custom namespaces are accepted, but one that is a single edit away from a known
+// namespace (e.g. "eip156") is treated as a typo.
+var knownNamespaces = []string{"eip155", "solana"}
+
+// ValidateNetworkPattern checks that a V2 network or network pattern is a well-formed CAIP-2
+// identifier such as "eip155:8453" or "eip155:*", so a typo fails at registration instead of
+// registering a mechanism that never matches. Errors are of type *NetworkPatternError.
+func ValidateNetworkPattern(network Network) error {
+	match := caip2Pattern.FindStringSubmatch(string(network))
+	if match == nil {
+		if strings.Contains(string(network), "*") {
+			return &NetworkPatternError{Network: network, Reason: `a wildcard must be the whole reference, e.g. "eip155:*"`}
+		}
+		return &NetworkPatternError{Network: network, Reason: `expected CAIP-2 "namespace:reference", e.g. "eip155:8453"`}
+	}
+
+	namespace, reference := match[1], match[2]
+	if err := validateNamespace(network, namespace); err != nil {
+		return err
+	}
+	if namespace == "eip155" && reference != "*" && !eip155ReferencePattern.MatchString(reference) {
+		return &NetworkPatternError{Network: network, Reason: "eip155 reference must be a decimal chain ID"}
+	}
+	return nil
+}
+
+// ValidateNetworkPatternV1 checks a V1 network, which is either a legacy network name such as
+// "base-sepolia" or a CAIP-2 identifier accepted by ValidateNetworkPattern
+func ValidateNetworkPatternV1(network Network) error {
+	if strings.Contains(string(network), ":") {
+		return ValidateNetworkPattern(network)
+	}
+	if !v1NetworkPattern.MatchString(string(network)) {
+		return &NetworkPatternError{Network: network, Reason: `expected a network name such as "base-sepolia" or a CAIP-2 identifier`}
+	}
+	return nil
+}
+
+// validateNamespace rejects namespaces that look like a misspelling of a known namespace
+func validateNamespace(network Network, namespace string) error {
+	for _, known := range knownNamespaces {
+		if namespace == known {
+			return nil
+		}
+	}
+	for _, known := range knownNamespaces {
+		if editDistanceAtMostOne(namespace, known) {
+			return &NetworkPatternError{Network: network, Reason: fmt.Sprintf("unknown namespace %q, did you mean %q?", namespace, known)}
+		}
+	}
+	return nil
+}
+
+// editDistanceAtMostOne reports whether a can be turned into b by a single insertion,
+// deletion, substitution or transposition of adjacent characters
+func editDistanceAtMostOne(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b)-len(a) > 1 {
+		return false
+	}
+
+	i := 0
+	for i < len(a) && a[i] == b[i] {
+		i++
+	}
+	if i == len(a) {
+		return true
+	}
+	if len(a) < len(b) {
+		return a[i:] == b[i+1:]
+	}
+	if a[i+1:] == b[i+1:] {
+		return true
+	}
+	return i+1 < len(a) && a[i] == b[i+1] && a[i+1] == b[i] && a[i+2:] == b[i+2:]
+}
+
+// mustValidateNetworks panics with a *NetworkPatternError if any network fails validate.
+// Used by the fluent Register methods, which have no error return.
+func mustValidateNetworks(validate func(Network) error, networks ...Network) {
+	for _, network := range networks {
+		if err := validate(network); err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package x402
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateNetworkPattern(t *testing.T) {
+	tests := []struct {
+		network Network
+		valid   bool
+	}{
+		{"eip155:8453", true},
+		{"eip155:*", true},
+		{"solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp", true},
+		{"solana:*", true},
+		{"x402:cash", true},
+		{"test:1", true},
+		{"eip156:*", false},
+		{"eip15:8453", false},
+		{"epi155:1", false},
+		{"solanna:*", false},
+		{"sloana:*", false},
+		{"eip155:base", false},
+		{"eip155:08453", false},
+		{"eip155:8*", false},
+		{"*:1", false},
+		{"eip155", false},
+		{"base-sepolia", false},
+		{"eip155:1:2", false},
+		{"EIP155:1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.network), func(t *testing.T) {
+			err := ValidateNetworkPattern(tt.network)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("expected %q to be valid, got %v", tt.network, err)
+				}
+				return
+			}
+			var patternErr *NetworkPatternError
+			if !errors.As(err, &patternErr) {
+				t.Fatalf("expected *NetworkPatternError for %q, got %v", tt.network, err)
+			}
+			if patternErr.Network != tt.network {
+				t.Fatalf("expected error for %q, got %q", tt.network, patternErr.Network)
+			}
+		})
+	}
+}
+
+func TestValidateNetworkPatternV1(t *testing.T) {
+	for _, network := range []Network{"base", "base-sepolia", "solana-devnet", "eip155:84532", "test:1"} {
+		if err := ValidateNetworkPatternV1(network); err != nil {
+			t.Errorf("expected %q to be valid, got %v", network, err)
+		}
+	}
+	for _, network := range []Network{"", "Base", "base sepolia", "-base", "eip156:1"} {
+		if err := ValidateNetworkPatternV1(network); err == nil {
+			t.Errorf("expected %q to be rejected", network)
+		}
+	}
+}
+
+func TestRegisterPanicsOnInvalidNetwork(t *testing.T) {
+	expectPanic := func(t *testing.T, register func()) {
+		t.Helper()
+		defer func() {
+			r := recover()
+			err, ok := r.(error)
+			var patternErr *NetworkPatternError
+			if !ok || !errors.As(err, &patternErr) {
+				t.Fatalf("expected panic with *NetworkPatternError, got %v", r)
+			}
+		}()
+		register()
+	}
+
+	t.Run("client", func(t *testing.T) {
+		expectPanic(t, func() {
+			Newx402Client().Register("eip156:*", &mockSchemeNetworkClientV2{scheme: "exact"})
+		})
+	})
+	t.Run("client v1", func(t *testing.T) {
+		expectPanic(t, func() {
+			Newx402Client().RegisterV1("base sepolia", &mockSchemeNetworkClientV1{scheme: "exact"})
+		})
+	})
+	t.Run("facilitator", func(t *testing.T) {
+		expectPanic(t, func() {
+			Newx402Facilitator().Register([]Network{"eip155:8453", "solanna:*"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+		})
+	})
+	t.Run("server", func(t *testing.T) {
+		expectPanic(t, func() {
+			Newx402ResourceServer().Register("eip155", &mockSchemeNetworkServer{scheme: "exact"})
+		})
+	})
+}
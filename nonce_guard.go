@@ -0,0 +1,90 @@
+package x402
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Nonce Guard
+// ============================================================================
+
+// DefaultNonceGuardTTL is how long a MemoryNonceGuard reservation lasts when it isn't released
+const DefaultNonceGuardTTL = 5 * time.Minute
+
+// NonceGuard stops a facilitator from settling the same authorization twice at once.
+//
+// On-chain nonce checks only catch a replay once the first settlement has landed, so two
+// concurrent requests carrying the same payment could both pass them. Mechanisms reserve
+// the authorization at the start of settlement: ok is false when it is already reserved,
+// otherwise release frees it once the settlement is over. Reservations should expire on
+// their own so a crashed settlement doesn't block the payment forever.
+//
+// MemoryNonceGuard covers a single facilitator instance. Facilitators running several
+// instances can plug in a shared implementation (e.g. Redis SET NX with an expiry).
+type NonceGuard interface {
+	Reserve(network, token, from, nonce string) (release func(), ok bool)
+}
+
+// MemoryNonceGuard is an in-process NonceGuard whose reservations expire after a TTL
+type MemoryNonceGuard struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	next     uint64
+	reserved map[string]nonceReservation
+	now      func() time.Time // overridable in tests
+}
+
+// nonceReservation is a held reservation. id tells a late release apart from a newer
+// reservation of the same key after the first one expired.
+type nonceReservation struct {
+	id      uint64
+	expires time.Time
+}
+
+// NewMemoryNonceGuard creates an in-memory NonceGuard. A ttl <= 0 uses DefaultNonceGuardTTL.
+func NewMemoryNonceGuard(ttl time.Duration) *MemoryNonceGuard {
+	if ttl <= 0 {
+		ttl = DefaultNonceGuardTTL
+	}
+	return &MemoryNonceGuard{
+		ttl:      ttl,
+		reserved: make(map[string]nonceReservation),
+		now:      time.Now,
+	}
+}
+
+// Reserve reserves the authorization unless an unexpired reservation holds it.
+// Addresses and nonces are compared case-insensitively.
+func (g *MemoryNonceGuard) Reserve(network, token, from, nonce string) (func(), bool) {
+	key := strings.ToLower(strings.Join([]string{network, token, from, nonce}, "|"))
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	for k, r := range g.reserved {
+		if !now.Before(r.expires) {
+			delete(g.reserved, k)
+		}
+	}
+	if _, held := g.reserved[key]; held {
+		return nil, false
+	}
+
+	g.next++
+	id := g.next
+	g.reserved[key] = nonceReservation{id: id, expires: now.Add(g.ttl)}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			if r, held := g.reserved[key]; held && r.id == id {
+				delete(g.reserved, key)
+			}
+		})
+	}, true
+}
@@ -0,0 +1,74 @@
+package x402
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryNonceGuard(t *testing.T) {
+	guard := NewMemoryNonceGuard(time.Minute)
+
+	release, ok := guard.Reserve("eip155:8453", "0xToken", "0xPayer", "0x01")
+	if !ok {
+		t.Fatal("Expected the first reservation to succeed")
+	}
+	if _, ok := guard.Reserve("eip155:8453", "0xtoken", "0xpayer", "0x01"); ok {
+		t.Fatal("Expected a second reservation of the same authorization to fail")
+	}
+	if _, ok := guard.Reserve("eip155:8453", "0xToken", "0xPayer", "0x02"); !ok {
+		t.Error("Expected a different nonce to be reservable")
+	}
+
+	release()
+	if _, ok := guard.Reserve("eip155:8453", "0xToken", "0xPayer", "0x01"); !ok {
+		t.Error("Expected the authorization to be reservable after release")
+	}
+}
+
+func TestMemoryNonceGuardExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	guard := NewMemoryNonceGuard(time.Minute)
+	guard.now = func() time.Time { return now }
+
+	staleRelease, ok := guard.Reserve("eip155:1", "0xToken", "0xPayer", "0x01")
+	if !ok {
+		t.Fatal("Expected the first reservation to succeed")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := guard.Reserve("eip155:1", "0xToken", "0xPayer", "0x01"); !ok {
+		t.Fatal("Expected an expired reservation to be replaced")
+	}
+
+	// Releasing the expired reservation must not free the newer one
+	staleRelease()
+	if _, ok := guard.Reserve("eip155:1", "0xToken", "0xPayer", "0x01"); ok {
+		t.Error("Expected the newer reservation to still be held")
+	}
+}
+
+func TestMemoryNonceGuardConcurrentReserve(t *testing.T) {
+	guard := NewMemoryNonceGuard(0)
+
+	const workers = 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	reserved := 0
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := guard.Reserve("eip155:1", "0xToken", "0xPayer", "0x01"); ok {
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != 1 {
+		t.Errorf("Expected exactly one reservation, got %d", reserved)
+	}
+}
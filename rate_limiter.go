@@ -0,0 +1,93 @@
+package x402
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Payer Rate Limiter
+// ============================================================================
+
+// PayerRateLimiter limits how often a facilitator accepts verify and settle requests from
+// one payer.
+//
+// Mechanisms call Allow once per request, after the payer's signature on the payload has
+// been verified, and reject the request with ReasonRateLimited when it returns false.
+// Counting only signed requests keeps anyone from exhausting a payer's allowance with
+// payloads that merely name it.
+//
+// MemoryRateLimiter covers a single facilitator instance. Facilitators running several
+// instances can plug in a shared implementation (e.g. a Redis-backed token bucket).
+type PayerRateLimiter interface {
+	Allow(payer string) bool
+}
+
+// MemoryRateLimiter is an in-process PayerRateLimiter with a token bucket per payer. Each
+// bucket holds up to requests tokens and refills at requests per window, so a payer can
+// burst up to requests at once and sustain requests per window.
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	requests  float64
+	window    time.Duration
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time // overridable in tests
+}
+
+// tokenBucket is a payer's remaining tokens as of updated
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewMemoryRateLimiter creates an in-memory PayerRateLimiter allowing each payer requests
+// per window. requests must be positive and window non-zero.
+func NewMemoryRateLimiter(requests int, window time.Duration) *MemoryRateLimiter {
+	if requests <= 0 || window <= 0 {
+		panic("x402: NewMemoryRateLimiter needs a positive request count and window")
+	}
+	return &MemoryRateLimiter{
+		requests: float64(requests),
+		window:   window,
+		buckets:  make(map[string]*tokenBucket),
+		now:      time.Now,
+	}
+}
+
+// Allow takes a token from payer's bucket, reporting false when it is empty.
+// Addresses are compared case-insensitively.
+func (l *MemoryRateLimiter) Allow(payer string) bool {
+	key := strings.ToLower(payer)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	// Buckets idle for a whole window have refilled, so they can be dropped
+	if now.Sub(l.lastSweep) >= l.window {
+		for k, b := range l.buckets {
+			if now.Sub(b.updated) >= l.window {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.requests, updated: now}
+		l.buckets[key] = bucket
+	} else if elapsed := now.Sub(bucket.updated); elapsed > 0 {
+		bucket.tokens = min(l.requests, bucket.tokens+l.requests*float64(elapsed)/float64(l.window))
+		bucket.updated = now
+	}
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
@@ -0,0 +1,58 @@
+package x402
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewMemoryRateLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	// The bucket starts full
+	if !limiter.Allow("0xPayer") || !limiter.Allow("0xpayer") {
+		t.Fatal("Expected a burst of 2 to be allowed")
+	}
+	if limiter.Allow("0xPAYER") {
+		t.Fatal("Expected the third request to be rate limited")
+	}
+	if !limiter.Allow("0xOther") {
+		t.Error("Expected other payers to have their own bucket")
+	}
+
+	// Half a window refills one token
+	now = now.Add(30 * time.Second)
+	if !limiter.Allow("0xPayer") {
+		t.Error("Expected a refilled token to be allowed")
+	}
+	if limiter.Allow("0xPayer") {
+		t.Error("Expected only one token to have refilled")
+	}
+
+	// A long idle period refills no more than a full bucket
+	now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if !limiter.Allow("0xPayer") {
+			t.Fatalf("Expected request %d after idling to be allowed", i+1)
+		}
+	}
+	if limiter.Allow("0xPayer") {
+		t.Error("Expected the bucket to be capped at 2 tokens")
+	}
+}
+
+func TestMemoryRateLimiterDropsIdleBuckets(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewMemoryRateLimiter(1, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("0xA")
+	limiter.Allow("0xB")
+
+	now = now.Add(time.Minute)
+	limiter.Allow("0xC")
+	if len(limiter.buckets) != 1 {
+		t.Errorf("Expected idle buckets to be dropped, have %d", len(limiter.buckets))
+	}
+}
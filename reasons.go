@@ -0,0 +1,244 @@
+package x402
+
+// ============================================================================
+// Verify/Settle Reasons
+// ============================================================================
+//
+// Reasons are the machine-readable values carried in VerifyError.Reason,
+// SettleError.Reason, VerifyResponse.InvalidReason and SettleResponse.ErrorReason.
+// They are part of the wire format, so the values must never change.
+// Unsupported protocol versions are reported as "unsupported_version_<n>".
+
+// Core reasons, raised by the facilitator and resource server routing logic
+const (
+	// ReasonInvalidVersion is returned when the payload and requirements versions disagree
+	ReasonInvalidVersion = "invalid_version"
+	// ReasonInvalidV1Payload is returned when a v1 payload cannot be decoded
+	ReasonInvalidV1Payload = "invalid_v1_payload"
+	// ReasonInvalidV1Requirements is returned when v1 requirements cannot be decoded
+	ReasonInvalidV1Requirements = "invalid_v1_requirements"
+	// ReasonInvalidV2Payload is returned when a v2 payload cannot be decoded
+	ReasonInvalidV2Payload = "invalid_v2_payload"
+	// ReasonInvalidV2Requirements is returned when v2 requirements cannot be decoded
+	ReasonInvalidV2Requirements = "invalid_v2_requirements"
+	// ReasonFailedToMarshalPayload is returned when a payload cannot be serialized for the facilitator
+	ReasonFailedToMarshalPayload = "failed_to_marshal_payload"
+	// ReasonFailedToMarshalRequirements is returned when requirements cannot be serialized for the facilitator
+	ReasonFailedToMarshalRequirements = "failed_to_marshal_requirements"
+	// ReasonNoFacilitator is returned when no facilitator client is configured
+	ReasonNoFacilitator = "no_facilitator"
+	// ReasonNoFacilitatorForNetwork is returned when no facilitator supports the scheme and network
+	ReasonNoFacilitatorForNetwork = "no_facilitator_for_network"
+	// ReasonSimulationNotSupported is returned when a settlement cannot be simulated for the scheme and network
+	ReasonSimulationNotSupported = "simulation_not_supported"
+)
+
+// Reasons shared by all mechanisms
+const (
+	// ReasonUnsupportedScheme is returned when the payload scheme is not the one requested
+	ReasonUnsupportedScheme = "unsupported_scheme"
+	// ReasonNetworkMismatch is returned when the payload network is not the one requested
+	ReasonNetworkMismatch = "network_mismatch"
+	// ReasonInvalidExtraField is returned when the requirements' extra field is malformed
+	ReasonInvalidExtraField = "invalid_extra_field"
+	// ReasonVerificationFailed is returned by settle when re-verification fails
+	ReasonVerificationFailed = "verification_failed"
+	// ReasonTransactionFailed is returned when the settlement transaction reverts or fails
+	ReasonTransactionFailed = "transaction_failed"
+	// ReasonTransactionSimulationFailed is returned when simulating the transaction fails
+	ReasonTransactionSimulationFailed = "transaction_simulation_failed"
+	// ReasonRateLimited is returned when the payer exceeded the facilitator's PayerRateLimiter
+	ReasonRateLimited = "rate_limited"
+)
+
+// EVM reasons, raised by the exact EVM mechanism
+const (
+	// ReasonInvalidScheme is returned when the requirements scheme is not "exact"
+	ReasonInvalidScheme = "invalid_scheme"
+	// ReasonInvalidPayload is returned when the scheme payload cannot be decoded
+	ReasonInvalidPayload = "invalid_payload"
+	// ReasonInvalidPayloadType is returned for an unknown payload type
+	ReasonInvalidPayloadType = "invalid_payload_type"
+	// ReasonFailedToGetNetworkConfig is returned when the network is not configured
+	ReasonFailedToGetNetworkConfig = "failed_to_get_network_config"
+	// ReasonFailedToGetAssetInfo is returned when the asset is not known for the network
+	ReasonFailedToGetAssetInfo = "failed_to_get_asset_info"
+	// ReasonAssetNetworkMismatch is returned when the asset is a token on another chain than the requirements' network
+	ReasonAssetNetworkMismatch = "asset_network_mismatch"
+	// ReasonMissingEIP712Domain is returned when the requirements lack the token's EIP-712 name or version
+	ReasonMissingEIP712Domain = "missing_eip712_domain"
+	// ReasonAcceptedMismatch is returned when the terms a v2 payload accepted differ from the requirements
+	ReasonAcceptedMismatch = "accepted_mismatch"
+	// ReasonRecipientMismatch is returned when the authorization pays someone other than payTo
+	ReasonRecipientMismatch = "recipient_mismatch"
+	// ReasonReceiverNotFacilitator is returned when a receiveWithAuthorization payment is not to the facilitator's sole signing address
+	ReasonReceiverNotFacilitator = "receiver_not_facilitator"
+	// ReasonInvalidAddressChecksum is returned when a mixed-case payTo or asset address fails its EIP-55 checksum
+	ReasonInvalidAddressChecksum = "invalid_address_checksum"
+	// ReasonInvalidRequiredAmount is returned when the required amount is not a valid integer
+	ReasonInvalidRequiredAmount = "invalid_required_amount"
+	// ReasonInsufficientAmount is returned when the authorized value is below the required amount
+	ReasonInsufficientAmount = "insufficient_amount"
+	// ReasonAmountExceedsRequired is returned when the authorized value is above the required amount
+	// and the facilitator doesn't allow overpayment
+	ReasonAmountExceedsRequired = "amount_exceeds_required"
+	// ReasonZeroAmount is returned when a payment of zero is verified without being explicitly allowed
+	ReasonZeroAmount = "zero_amount"
+	// ReasonAmountBelowMinimum is returned when the payment is below the facilitator's minimum for the asset
+	ReasonAmountBelowMinimum = "amount_below_minimum"
+	// ReasonAmountMismatch is returned when the value about to be settled is not the one verified
+	// against the requirements
+	ReasonAmountMismatch = "amount_mismatch"
+	// ReasonInsufficientBalance is returned when the payer's token balance is below the authorized value
+	ReasonInsufficientBalance = "insufficient_balance"
+	// ReasonInsufficientFunds is the v1 equivalent of ReasonInsufficientBalance
+	ReasonInsufficientFunds = "insufficient_funds"
+	// ReasonFailedToGetBalance is returned when the payer's balance cannot be read
+	ReasonFailedToGetBalance = "failed_to_get_balance"
+
+	// ReasonInvalidAuthorizationValue is returned when the authorization value is not a valid integer
+	ReasonInvalidAuthorizationValue = "invalid_authorization_value"
+	// ReasonInvalidAuthorizationValidAfter is returned when validAfter is not a valid integer
+	ReasonInvalidAuthorizationValidAfter = "invalid_authorization_valid_after"
+	// ReasonInvalidAuthorizationValidBefore is returned when validBefore is not a valid integer
+	ReasonInvalidAuthorizationValidBefore = "invalid_authorization_valid_before"
+	// ReasonAuthorizationExpired is returned when validBefore has passed
+	ReasonAuthorizationExpired = "authorization_expired"
+	// ReasonAuthorizationNotYetValid is returned when validAfter is still in the future
+	ReasonAuthorizationNotYetValid = "authorization_not_yet_valid"
+	// ReasonFailedToHashAuthorization is returned when the EIP-712 authorization hash cannot be built
+	ReasonFailedToHashAuthorization = "failed_to_hash_authorization"
+	// ReasonFailedToCheckNonce is returned when the authorization nonce state cannot be read
+	ReasonFailedToCheckNonce = "failed_to_check_nonce"
+	// ReasonNonceAlreadyUsed is returned when the authorization nonce has already been used
+	ReasonNonceAlreadyUsed = "nonce_already_used"
+	// ReasonNonceInFlight is returned when another settlement of the same authorization is in progress
+	ReasonNonceInFlight = "nonce_in_flight"
+	// ReasonAuthorizationNotVerified is returned when a cancellation is for an authorization the facilitator hasn't verified, or has already settled
+	ReasonAuthorizationNotVerified = "authorization_not_verified"
+
+	// ReasonMissingSignature is returned when the payload has no signature
+	ReasonMissingSignature = "missing_signature"
+	// ReasonInvalidSignature is returned when the signature does not match the payer
+	ReasonInvalidSignature = "invalid_signature"
+	// ReasonInvalidSignatureFormat is returned when the signature is not valid hex
+	ReasonInvalidSignatureFormat = "invalid_signature_format"
+	// ReasonMalleableSignature is returned when low-S is required and the signature's s is high
+	ReasonMalleableSignature = "malleable_signature"
+	// ReasonFailedToParseSignature is returned when the signature cannot be split into v, r, s
+	ReasonFailedToParseSignature = "failed_to_parse_signature"
+	// ReasonFailedToVerifySignature is returned when signature verification itself errors
+	ReasonFailedToVerifySignature = "failed_to_verify_signature"
+	// ReasonFailedToCheckDeployment is returned when the payer's contract code cannot be read
+	ReasonFailedToCheckDeployment = "failed_to_check_deployment"
+	// ReasonSmartWalletDeploymentFailed is returned when an ERC-6492 wallet cannot be deployed
+	ReasonSmartWalletDeploymentFailed = "smart_wallet_deployment_failed"
+
+	// ReasonMissingPermit is returned when a permit payload has no permit
+	ReasonMissingPermit = "missing_permit"
+	// ReasonPermitOwnerMismatch is returned when the permit owner is not the payer
+	ReasonPermitOwnerMismatch = "permit_owner_mismatch"
+	// ReasonPermitSpenderMismatch is returned when the permit spender is not the facilitator
+	ReasonPermitSpenderMismatch = "permit_spender_mismatch"
+	// ReasonInvalidPermitValue is returned when the permit value is not a valid integer
+	ReasonInvalidPermitValue = "invalid_permit_value"
+	// ReasonInsufficientPermitValue is returned when the permit value is below the authorized value
+	ReasonInsufficientPermitValue = "insufficient_permit_value"
+	// ReasonInvalidPermitDeadline is returned when the permit deadline is not a valid integer
+	ReasonInvalidPermitDeadline = "invalid_permit_deadline"
+	// ReasonPermitExpired is returned when the permit deadline has passed
+	ReasonPermitExpired = "permit_expired"
+	// ReasonFailedToHashPermit is returned when the EIP-2612 permit hash cannot be built
+	ReasonFailedToHashPermit = "failed_to_hash_permit"
+	// ReasonInvalidPermitSignatureFormat is returned when the permit signature is not valid hex
+	ReasonInvalidPermitSignatureFormat = "invalid_permit_signature_format"
+	// ReasonFailedToVerifyPermit is returned when permit signature verification itself errors
+	ReasonFailedToVerifyPermit = "failed_to_verify_permit"
+	// ReasonInvalidPermitSignature is returned when the permit signature does not match the owner
+	ReasonInvalidPermitSignature = "invalid_permit_signature"
+	// ReasonPermitFailed is returned when submitting the permit on-chain fails
+	ReasonPermitFailed = "permit_failed"
+
+	// ReasonFacilitatorContractUnavailable is returned when a payment needs the facilitator contract
+	// and it isn't deployed on the network, or the scheme is configured to settle without it
+	ReasonFacilitatorContractUnavailable = "facilitator_contract_unavailable"
+	// ReasonFacilitatorLowGas is returned when none of the facilitator's signers holds enough native token to pay for gas
+	ReasonFacilitatorLowGas = "facilitator_low_gas"
+	// ReasonFailedToExecuteTransfer is returned when the transfer transaction cannot be sent
+	ReasonFailedToExecuteTransfer = "failed_to_execute_transfer"
+	// ReasonFailedToGetReceipt is returned when the transaction receipt cannot be fetched
+	ReasonFailedToGetReceipt = "failed_to_get_receipt"
+	// ReasonSettlementTimeout is returned when the settlement transaction isn't confirmed in time
+	ReasonSettlementTimeout = "settlement_timeout"
+	// ReasonInvalidTransactionState is returned when the v1 transaction receipt reports failure
+	ReasonInvalidTransactionState = "invalid_transaction_state"
+	// ReasonSettlementEventMismatch is returned when a mined settlement emitted no transfer of the paid value to payTo
+	ReasonSettlementEventMismatch = "settlement_event_mismatch"
+
+	// ReasonSettlementNotFound is returned when a refunded settlement wasn't sent by the facilitator, or isn't a successful on-chain transfer of a configured asset from the payer
+	ReasonSettlementNotFound = "settlement_not_found"
+	// ReasonAlreadyRefunded is returned when a settlement has already been refunded
+	ReasonAlreadyRefunded = "already_refunded"
+	// ReasonInsufficientRefundBalance is returned when the refunding account's token balance is below the refund
+	ReasonInsufficientRefundBalance = "insufficient_refund_balance"
+
+	// ReasonInvalidExactEVMPayloadRecipientMismatch is the v1 equivalent of ReasonRecipientMismatch
+	ReasonInvalidExactEVMPayloadRecipientMismatch = "invalid_exact_evm_payload_recipient_mismatch"
+	// ReasonInvalidExactEVMPayloadAuthorizationValue is the v1 equivalent of ReasonInsufficientAmount
+	ReasonInvalidExactEVMPayloadAuthorizationValue = "invalid_exact_evm_payload_authorization_value"
+	// ReasonInvalidExactEVMPayloadAuthorizationValidBefore is the v1 equivalent of ReasonAuthorizationExpired
+	ReasonInvalidExactEVMPayloadAuthorizationValidBefore = "invalid_exact_evm_payload_authorization_valid_before"
+	// ReasonInvalidExactEVMPayloadAuthorizationValidAfter is the v1 equivalent of ReasonAuthorizationNotYetValid
+	ReasonInvalidExactEVMPayloadAuthorizationValidAfter = "invalid_exact_evm_payload_authorization_valid_after"
+	// ReasonInvalidExactEVMPayloadSignature is the v1 equivalent of ReasonInvalidSignature
+	ReasonInvalidExactEVMPayloadSignature = "invalid_exact_evm_payload_signature"
+	// ReasonInvalidExactEVMPayloadUndeployedSmartWallet is returned for an undeployed wallet without ERC-6492 deployment data
+	ReasonInvalidExactEVMPayloadUndeployedSmartWallet = "invalid_exact_evm_payload_undeployed_smart_wallet"
+)
+
+// Solana reasons, raised by the exact SVM mechanism
+const (
+	// ReasonMissingFeePayer is returned by settle when the requirements don't name a fee payer
+	ReasonMissingFeePayer = "missing_fee_payer"
+	// ReasonInvalidFeePayer is returned when the fee payer is not a valid address
+	ReasonInvalidFeePayer = "invalid_fee_payer"
+	// ReasonFeePayerMismatch is returned when the transaction fee payer is not the requested one
+	ReasonFeePayerMismatch = "fee_payer_mismatch"
+	// ReasonFeePayerNotManagedByFacilitator is returned when the facilitator holds no key for the fee payer
+	ReasonFeePayerNotManagedByFacilitator = "fee_payer_not_managed_by_facilitator"
+
+	// ReasonInvalidExactSolanaPayloadMissingFeePayer is returned by verify when the requirements don't name a fee payer
+	ReasonInvalidExactSolanaPayloadMissingFeePayer = "invalid_exact_solana_payload_missing_fee_payer"
+	// ReasonInvalidExactSolanaPayloadTransaction is returned when the payload transaction is missing or malformed
+	ReasonInvalidExactSolanaPayloadTransaction = "invalid_exact_solana_payload_transaction"
+	// ReasonInvalidExactSolanaPayloadTransactionCouldNotBeDecoded is returned when the transaction cannot be decoded
+	ReasonInvalidExactSolanaPayloadTransactionCouldNotBeDecoded = "invalid_exact_solana_payload_transaction_could_not_be_decoded"
+	// ReasonInvalidExactSolanaPayloadTransactionInstructionsLength is returned for an unexpected instruction count
+	ReasonInvalidExactSolanaPayloadTransactionInstructionsLength = "invalid_exact_solana_payload_transaction_instructions_length"
+	// ReasonInvalidExactSolanaPayloadTransactionInstructionsComputeLimitInstruction is returned for a bad compute limit instruction
+	ReasonInvalidExactSolanaPayloadTransactionInstructionsComputeLimitInstruction = "invalid_exact_solana_payload_transaction_instructions_compute_limit_instruction"
+	// ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction is returned for a bad compute price instruction
+	ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstruction = "invalid_exact_solana_payload_transaction_instructions_compute_price_instruction"
+	// ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstructionTooHigh is returned when the compute unit price exceeds the limit
+	ReasonInvalidExactSolanaPayloadTransactionInstructionsComputePriceInstructionTooHigh = "invalid_exact_solana_payload_transaction_instructions_compute_price_instruction_too_high"
+	// ReasonInvalidExactSolanaPayloadTransactionInstructionsNonceInstruction is returned for an advance nonce
+	// instruction that doesn't advance the facilitator's durable nonce account
+	ReasonInvalidExactSolanaPayloadTransactionInstructionsNonceInstruction = "invalid_exact_solana_payload_transaction_instructions_nonce_instruction"
+	// ReasonInvalidExactSolanaPayloadNoTransferInstruction is returned when no TransferChecked instruction is found
+	ReasonInvalidExactSolanaPayloadNoTransferInstruction = "invalid_exact_solana_payload_no_transfer_instruction"
+	// ReasonInvalidExactSolanaPayloadTransactionFeePayerTransferringFunds is returned when the fee payer is the transfer authority
+	ReasonInvalidExactSolanaPayloadTransactionFeePayerTransferringFunds = "invalid_exact_solana_payload_transaction_fee_payer_transferring_funds"
+	// ReasonInvalidExactSolanaPayloadMintMismatch is returned when the transfer mint is not the requested asset
+	ReasonInvalidExactSolanaPayloadMintMismatch = "invalid_exact_solana_payload_mint_mismatch"
+	// ReasonInvalidExactSolanaPayloadRecipientMismatch is returned when the transfer destination is not payTo's token account
+	ReasonInvalidExactSolanaPayloadRecipientMismatch = "invalid_exact_solana_payload_recipient_mismatch"
+	// ReasonInvalidExactSolanaPayloadAmountInsufficient is returned when the transfer amount is below the required amount
+	ReasonInvalidExactSolanaPayloadAmountInsufficient = "invalid_exact_solana_payload_amount_insufficient"
+	// ReasonFailedToGetMintInfo is returned when the mint cannot be read to account for a Token-2022 transfer fee
+	ReasonFailedToGetMintInfo = "failed_to_get_mint_info"
+
+	// ReasonTransactionSigningFailed is returned when the facilitator cannot sign the transaction
+	ReasonTransactionSigningFailed = "transaction_signing_failed"
+	// ReasonTransactionConfirmationFailed is returned when the transaction is not confirmed
+	ReasonTransactionConfirmationFailed = "transaction_confirmation_failed"
+)
@@ -0,0 +1,150 @@
+package x402
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"x402-go/types"
+)
+
+// ============================================================================
+// Selection Strategies
+// ============================================================================
+
+// Requirement Extra keys describing the asset, set by mechanisms that know them
+const (
+	ExtraDecimals = "decimals"
+	ExtraSymbol   = "symbol"
+)
+
+// SelectionStrategy picks one of the supported payment requirements for a single
+// SelectPaymentRequirementsWith call. It receives the requirements left after
+// filtering by registered schemes and policies, in the server's order, and never an empty slice.
+type SelectionStrategy func(requirements []types.PaymentRequirements) (types.PaymentRequirements, error)
+
+// ErrNoComparableAmount is returned by CheapestByAmount when no requirement has an amount it can compare
+var ErrNoComparableAmount = errors.New("no payment requirement has a comparable amount")
+
+// PreferNetwork selects the first requirement on network (wildcards such as "eip155:*"
+// are allowed), falling back to the first requirement when none matches
+func PreferNetwork(network Network) SelectionStrategy {
+	return func(requirements []types.PaymentRequirements) (types.PaymentRequirements, error) {
+		return preferFirst(requirements, func(req types.PaymentRequirements) bool {
+			return Network(req.Network).Match(network)
+		})
+	}
+}
+
+// PreferAsset selects the first requirement paying in asset, falling back to the first
+// requirement when none matches. asset is compared case-insensitively against both the
+// requirement's asset address and the symbol advertised in its Extra.
+func PreferAsset(asset string) SelectionStrategy {
+	return func(requirements []types.PaymentRequirements) (types.PaymentRequirements, error) {
+		return preferFirst(requirements, func(req types.PaymentRequirements) bool {
+			if strings.EqualFold(req.Asset, asset) {
+				return true
+			}
+			symbol, _ := req.Extra[ExtraSymbol].(string)
+			return symbol != "" && strings.EqualFold(symbol, asset)
+		})
+	}
+}
+
+// CheapestByAmount selects the requirement with the lowest amount, ties going to the
+// server's order.
+//
+// Amounts are in each asset's smallest unit, so they are scaled by the decimals advertised
+// in the requirement's Extra before comparing: 1000000 of a 6-decimal token costs the same
+// as 1000000000000000000 of an 18-decimal one. Without decimals, amounts are only comparable
+// when every requirement pays in the same asset; otherwise requirements missing them are skipped.
+func CheapestByAmount() SelectionStrategy {
+	return func(requirements []types.PaymentRequirements) (types.PaymentRequirements, error) {
+		sameAsset := true
+		for _, req := range requirements[1:] {
+			if !strings.EqualFold(req.Asset, requirements[0].Asset) {
+				sameAsset = false
+				break
+			}
+		}
+
+		var cheapest *types.PaymentRequirements
+		var lowest *big.Rat
+		for i, req := range requirements {
+			decimals, ok := requirementDecimals(req)
+			if !ok {
+				if !sameAsset {
+					continue
+				}
+				decimals = 0
+			}
+			amount, err := normalizeAmount(req.Amount, decimals)
+			if err != nil {
+				continue
+			}
+			if lowest == nil || amount.Cmp(lowest) < 0 {
+				cheapest = &requirements[i]
+				lowest = amount
+			}
+		}
+
+		if cheapest == nil {
+			return types.PaymentRequirements{}, ErrNoComparableAmount
+		}
+		return *cheapest, nil
+	}
+}
+
+// preferFirst returns the first requirement matching, or the first requirement when none does
+func preferFirst(requirements []types.PaymentRequirements, match func(types.PaymentRequirements) bool) (types.PaymentRequirements, error) {
+	for _, req := range requirements {
+		if match(req) {
+			return req, nil
+		}
+	}
+	return requirements[0], nil
+}
+
+// requirementDecimals reads the asset decimals from the requirement's Extra. JSON-decoded
+// requirements carry them as float64, locally built ones as int or a numeric string.
+func requirementDecimals(req types.PaymentRequirements) (int, bool) {
+	var decimals int
+	switch v := req.Extra[ExtraDecimals].(type) {
+	case int:
+		decimals = v
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		decimals = int(v)
+	case string:
+		d, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, false
+		}
+		decimals = d
+	default:
+		return 0, false
+	}
+	// A uint256 amount has at most 78 digits, so larger values are not real decimals
+	if decimals < 0 || decimals > 77 {
+		return 0, false
+	}
+	return decimals, true
+}
+
+// normalizeAmount converts an amount in the asset's smallest unit to whole tokens. It matches
+// evm.NormalizedAmount, which this package can't import since mechanisms depend on it.
+func normalizeAmount(amount string, decimals int) (*big.Rat, error) {
+	if decimals < 0 {
+		return nil, fmt.Errorf("invalid decimals: %d", decimals)
+	}
+	units, ok := new(big.Int).SetString(amount, 10)
+	if !ok || units.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Rat).SetFrac(units, scale), nil
+}
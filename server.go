@@ -3,10 +3,14 @@ package x402
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"strings"
 	"sync"
 	"time"
 
+	exttypes "x402-go/extensions/types"
 	"x402-go/types"
 )
 
@@ -25,6 +29,9 @@ type x402ResourceServer struct {
 	registeredExtensions map[string]types.ResourceServerExtension
 	supportedCache       *SupportedCache
 
+	// Discovery declarations by route key (see DeclareDiscovery)
+	discovery map[string]interface{}
+
 	// Lifecycle hooks
 	beforeVerifyHooks    []BeforeVerifyHook
 	afterVerifyHooks     []AfterVerifyHook
@@ -32,8 +39,27 @@ type x402ResourceServer struct {
 	beforeSettleHooks    []BeforeSettleHook
 	afterSettleHooks     []AfterSettleHook
 	onSettleFailureHooks []OnSettleFailureHook
+
+	// Optional tracing (nil = disabled)
+	tracer Tracer
+
+	// Logger for facilitator failures (no-op by default)
+	logger Logger
+
+	// Dry-run settlements before submitting them (see WithSettlementSimulation)
+	simulateSettlement bool
+
+	// Background refresh of supported kinds (off when refreshInterval is 0)
+	refreshInterval   time.Duration
+	refreshRetryDelay time.Duration
+	refreshCancel     context.CancelFunc
+	refreshDone       chan struct{}
 }
 
+// DefaultSupportedRefreshRetryDelay is the delay before retrying a failed background refresh,
+// doubled for each consecutive failure up to the refresh interval
+const DefaultSupportedRefreshRetryDelay = time.Second
+
 // SupportedCache caches facilitator capabilities
 type SupportedCache struct {
 	mu     sync.RWMutex
@@ -68,6 +94,18 @@ func (c *SupportedCache) Get(key string) (SupportedResponse, bool) {
 	return response, true
 }
 
+// replace swaps the cached responses for responses
+func (c *SupportedCache) replace(responses map[string]SupportedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expiry := time.Now().Add(c.ttl)
+	c.data = responses
+	c.expiry = make(map[string]time.Time, len(responses))
+	for key := range responses {
+		c.expiry[key] = expiry
+	}
+}
+
 // ResourceServerOption configures the server
 type ResourceServerOption func(*x402ResourceServer)
 
@@ -96,16 +134,67 @@ func WithCacheTTL(ttl time.Duration) ResourceServerOption {
 	}
 }
 
+// WithSupportedRefresh re-fetches supported kinds from every facilitator about every interval
+// once Initialize has run, so kinds a facilitator adds or drops are picked up without a restart.
+// Each wait is jittered by up to 10% so servers sharing a facilitator spread their requests.
+// A failed refresh keeps the last good kinds and is retried with exponential backoff starting
+// at DefaultSupportedRefreshRetryDelay. Call Close to stop refreshing.
+func WithSupportedRefresh(interval time.Duration) ResourceServerOption {
+	return func(s *x402ResourceServer) {
+		s.refreshInterval = interval
+	}
+}
+
+// WithSettlementSimulation simulates each settlement with the facilitator before submitting it,
+// so a payment that would fail on-chain is rejected without costing the facilitator gas. It
+// applies to facilitator clients implementing SimulatingFacilitatorClient; settlements the
+// facilitator can't simulate (ReasonSimulationNotSupported) are submitted as usual. A failed
+// simulation is reported like a failed settlement, to the same hooks.
+func WithSettlementSimulation() ResourceServerOption {
+	return func(s *x402ResourceServer) {
+		s.simulateSettlement = true
+	}
+}
+
+// WithServerTracer traces payment processing. The trace context is passed to clients in
+// PaymentRequired and to facilitators in the payment payload, so each payment is one trace.
+func WithServerTracer(tracer Tracer) ResourceServerOption {
+	return func(s *x402ResourceServer) {
+		s.tracer = tracer
+	}
+}
+
+// Tracer returns the configured tracer, or nil when tracing is off
+func (s *x402ResourceServer) Tracer() Tracer {
+	return s.tracer
+}
+
+// WithServerLogger logs facilitator verify and settle failures. The HTTP middleware uses the
+// same logger.
+func WithServerLogger(logger Logger) ResourceServerOption {
+	return func(s *x402ResourceServer) {
+		s.logger = loggerOrNoop(logger)
+	}
+}
+
+// Logger returns the configured logger (a no-op Logger when none is set)
+func (s *x402ResourceServer) Logger() Logger {
+	return s.logger
+}
+
 func Newx402ResourceServer(opts ...ResourceServerOption) *x402ResourceServer {
 	s := &x402ResourceServer{
 		schemes:              make(map[Network]map[string]SchemeNetworkServer),
 		facilitatorClients:   make(map[Network]map[string]FacilitatorClient),
 		registeredExtensions: make(map[string]types.ResourceServerExtension),
+		discovery:            make(map[string]interface{}),
 		supportedCache: &SupportedCache{
 			data:   make(map[string]SupportedResponse),
 			expiry: make(map[string]time.Time),
 			ttl:    5 * time.Minute,
 		},
+		logger:            noopLogger{},
+		refreshRetryDelay: DefaultSupportedRefreshRetryDelay,
 	}
 
 	for _, opt := range opts {
@@ -115,50 +204,152 @@ func Newx402ResourceServer(opts ...ResourceServerOption) *x402ResourceServer {
 	return s
 }
 
-// Initialize populates facilitator clients by querying GetSupported
+// Initialize populates facilitator clients by querying GetSupported, then starts the
+// background refresh when WithSupportedRefresh is set (even if this first query failed)
 func (s *x402ResourceServer) Initialize(ctx context.Context) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	err := s.RefreshSupported(ctx)
+	s.startSupportedRefresh(err != nil)
+	return err
+}
+
+// RefreshSupported re-queries GetSupported on every facilitator client and replaces the
+// network/scheme routing and cached kinds with the result. If any facilitator fails, its error
+// is returned and the previous kinds are kept.
+func (s *x402ResourceServer) RefreshSupported(ctx context.Context) error {
+	s.mu.RLock()
+	clients := s.tempFacilitatorClients
+	s.mu.RUnlock()
 
-	for _, client := range s.tempFacilitatorClients {
-		// Get supported kinds
+	// Query outside the lock so payments keep flowing while facilitators answer
+	responses := make([]SupportedResponse, len(clients))
+	for i, client := range clients {
 		supported, err := client.GetSupported(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get supported from facilitator: %w", err)
 		}
+		responses[i] = supported
+	}
 
+	facilitatorClients := make(map[Network]map[string]FacilitatorClient)
+	cached := make(map[string]SupportedResponse, len(clients))
+	for i, client := range clients {
 		// Populate facilitatorClients map from kinds (now flat array with version in each element)
-		for _, kind := range supported.Kinds {
+		for _, kind := range responses[i].Kinds {
 			network := Network(kind.Network)
 			scheme := kind.Scheme
 
-			if s.facilitatorClients[network] == nil {
-				s.facilitatorClients[network] = make(map[string]FacilitatorClient)
+			if facilitatorClients[network] == nil {
+				facilitatorClients[network] = make(map[string]FacilitatorClient)
 			}
 
 			// Only set if not already present (precedence to earlier clients)
-			if s.facilitatorClients[network][scheme] == nil {
-				s.facilitatorClients[network][scheme] = client
+			if facilitatorClients[network][scheme] == nil {
+				facilitatorClients[network][scheme] = client
 			}
 		}
 
-		// Cache the supported response
-		s.supportedCache.Set(fmt.Sprintf("facilitator_%p", client), supported)
+		cached[fmt.Sprintf("facilitator_%p", client)] = responses[i]
 	}
 
+	s.mu.Lock()
+	s.facilitatorClients = facilitatorClients
+	s.mu.Unlock()
+	s.supportedCache.replace(cached)
+
 	return nil
 }
 
-// Register registers a payment mechanism (V2, default)
-func (s *x402ResourceServer) Register(network Network, schemeServer SchemeNetworkServer) *x402ResourceServer {
+// Close stops the background refresh started by WithSupportedRefresh, waiting for a refresh in
+// flight to finish. It is a no-op when no refresh is running.
+func (s *x402ResourceServer) Close() {
+	s.mu.Lock()
+	cancel, done := s.refreshCancel, s.refreshDone
+	s.refreshCancel, s.refreshDone = nil, nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// startSupportedRefresh starts the background refresh once, retrying sooner when the initial
+// query failed
+func (s *x402ResourceServer) startSupportedRefresh(failed bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if s.schemes[network] == nil {
-		s.schemes[network] = make(map[string]SchemeNetworkServer)
+	if s.refreshInterval <= 0 || s.refreshCancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.refreshCancel, s.refreshDone = cancel, make(chan struct{})
+
+	failures := 0
+	if failed {
+		failures = 1
+	}
+	go s.refreshSupportedLoop(ctx, s.refreshDone, failures)
+}
+
+// refreshSupportedLoop refreshes supported kinds until ctx is cancelled
+func (s *x402ResourceServer) refreshSupportedLoop(ctx context.Context, done chan struct{}, failures int) {
+	defer close(done)
+
+	for {
+		timer := time.NewTimer(s.nextRefreshDelay(failures))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		refreshCtx, cancel := context.WithTimeout(ctx, s.refreshInterval)
+		err := s.RefreshSupported(refreshCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			failures++
+			s.logger.Warn("failed to refresh supported kinds", "error", err, "failures", failures)
+			continue
+		}
+		failures = 0
+	}
+}
+
+// nextRefreshDelay returns the jittered wait before the next background refresh: the refresh
+// interval, or after failures an exponential backoff capped at the interval
+func (s *x402ResourceServer) nextRefreshDelay(failures int) time.Duration {
+	delay := s.refreshInterval
+	if failures > 0 {
+		delay = s.refreshRetryDelay
+		for i := 1; i < failures && delay < s.refreshInterval; i++ {
+			delay *= 2
+		}
+		if delay > s.refreshInterval {
+			delay = s.refreshInterval
+		}
 	}
 
-	s.schemes[network][schemeServer.Scheme()] = schemeServer
+	// Jitter by up to 10% either way
+	jitter := delay / 10
+	return delay - jitter + time.Duration(rand.Int64N(int64(2*jitter)+1))
+}
+
+// Register registers a payment mechanism (V2, default).
+// Panics with a *NetworkPatternError if network is malformed (see ValidateNetworkPattern).
+func (s *x402ResourceServer) Register(network Network, schemeServer SchemeNetworkServer) *x402ResourceServer {
+	mustValidateNetworks(ValidateNetworkPattern, network)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	registerScheme(s.schemes, network, schemeServer.Scheme(), schemeServer, s.logger)
 	return s
 }
 
@@ -170,6 +361,99 @@ func (s *x402ResourceServer) RegisterExtension(extension types.ResourceServerExt
 	return s
 }
 
+// EnrichExtensions returns a copy of extensions in which each declaration with a registered
+// extension has been passed through its EnrichDeclaration, e.g. to fill in details from the
+// request in transportContext. Declarations without a registered extension are kept as is.
+func (s *x402ResourceServer) EnrichExtensions(extensions map[string]interface{}, transportContext interface{}) map[string]interface{} {
+	if len(extensions) == 0 {
+		return extensions
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	enriched := make(map[string]interface{}, len(extensions))
+	for key, declaration := range extensions {
+		if extension, ok := s.registeredExtensions[key]; ok {
+			declaration = extension.EnrichDeclaration(declaration, transportContext)
+		}
+		enriched[key] = declaration
+	}
+	return enriched
+}
+
+// DeclareDiscovery declares how to call the route with key routeKey (its key in the HTTP
+// RoutesConfig, e.g. "GET /weather"), so Bazaar catalogs can learn its input and output.
+// The declaration is added to the route's PAYMENT-REQUIRED extensions, where the registered
+// Bazaar extension (the HTTP middlewares register it) builds the discovery extension from it.
+//
+// config is an exttypes.DeclareQueryDiscoveryConfig for GET, HEAD and DELETE routes or an
+// exttypes.DeclareBodyDiscoveryConfig for POST, PUT and PATCH routes. Its Method defaults to
+// the route key's method prefix; one of them must be set, and they must agree.
+func (s *x402ResourceServer) DeclareDiscovery(routeKey string, config interface{}) error {
+	routeMethod := ""
+	if parts := strings.Fields(routeKey); len(parts) == 2 && parts[0] != "*" {
+		routeMethod = strings.ToUpper(parts[0])
+	}
+
+	methodFor := func(method string) (string, error) {
+		method = strings.ToUpper(method)
+		switch {
+		case method == "":
+			method = routeMethod
+		case routeMethod != "" && method != routeMethod:
+			return "", fmt.Errorf("discovery method %s does not match route %q", method, routeKey)
+		}
+		if method == "" {
+			return "", fmt.Errorf("route %q has no method: set the discovery config's Method", routeKey)
+		}
+		return method, nil
+	}
+
+	switch c := config.(type) {
+	case exttypes.DeclareQueryDiscoveryConfig:
+		method, err := methodFor(string(c.Method))
+		if err != nil {
+			return err
+		}
+		if !exttypes.IsQueryMethod(method) {
+			return fmt.Errorf("query discovery config used for %s route %q: use DeclareBodyDiscoveryConfig", method, routeKey)
+		}
+		c.Method = exttypes.QueryParamMethods(method)
+		config = c
+	case exttypes.DeclareBodyDiscoveryConfig:
+		method, err := methodFor(string(c.Method))
+		if err != nil {
+			return err
+		}
+		if !exttypes.IsBodyMethod(method) {
+			return fmt.Errorf("body discovery config used for %s route %q: use DeclareQueryDiscoveryConfig", method, routeKey)
+		}
+		c.Method = exttypes.BodyMethods(method)
+		if c.BodyType == "" {
+			c.BodyType = exttypes.BodyTypeJSON
+		}
+		config = c
+	default:
+		return fmt.Errorf("unsupported discovery config type: %T", config)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.discovery[routeKey] = config
+	return nil
+}
+
+// DiscoveryDeclaration returns the discovery config declared for routeKey with DeclareDiscovery
+func (s *x402ResourceServer) DiscoveryDeclaration(routeKey string) (interface{}, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	config, ok := s.discovery[routeKey]
+	return config, ok
+}
+
 // ============================================================================
 // Hook Registration Methods (Chainable)
 // ============================================================================
@@ -234,7 +518,7 @@ func (s *x402ResourceServer) BuildPaymentRequirements(
 	scheme := config.Scheme
 	network := config.Network
 
-	schemeServer := s.schemes[network][scheme]
+	schemeServer := findByNetworkAndScheme(s.schemes, scheme, network)
 	if schemeServer == nil {
 		return types.PaymentRequirements{}, &PaymentError{
 			Code:    ErrCodeUnsupportedScheme,
@@ -243,7 +527,13 @@ func (s *x402ResourceServer) BuildPaymentRequirements(
 	}
 
 	// Parse price to get asset/amount
-	assetAmount, err := schemeServer.ParsePrice(config.Price, network)
+	var assetAmount AssetAmount
+	var err error
+	if parser, ok := schemeServer.(ContextPriceParser); ok {
+		assetAmount, err = parser.ParsePriceContext(ctx, config.Price, network)
+	} else {
+		assetAmount, err = schemeServer.ParsePrice(config.Price, network)
+	}
 	if err != nil {
 		return types.PaymentRequirements{}, err
 	}
@@ -290,15 +580,18 @@ func (s *x402ResourceServer) FindMatchingRequirements(available []types.PaymentR
 
 // VerifyPayment verifies a V2 payment
 func (s *x402ResourceServer) VerifyPayment(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*VerifyResponse, error) {
+	// Hand our trace context on to the facilitator
+	payload.Extensions = InjectTraceContext(ctx, s.tracer, payload.Extensions)
+
 	// Marshal to bytes early for hooks (escape hatch for extensions)
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return nil, NewVerifyError("failed_to_marshal_payload", "", Network(requirements.Network), err)
+		return nil, NewVerifyError(ReasonFailedToMarshalPayload, "", Network(requirements.Network), err)
 	}
 
 	requirementsBytes, err := json.Marshal(requirements)
 	if err != nil {
-		return nil, NewVerifyError("failed_to_marshal_requirements", "", Network(requirements.Network), err)
+		return nil, NewVerifyError(ReasonFailedToMarshalRequirements, "", Network(requirements.Network), err)
 	}
 
 	// Execute beforeVerify hooks
@@ -328,7 +621,7 @@ func (s *x402ResourceServer) VerifyPayment(ctx context.Context, payload types.Pa
 	s.mu.RUnlock()
 
 	if facilitator == nil {
-		return nil, NewVerifyError("no_facilitator", "", network, fmt.Errorf("no facilitator for %s on %s", scheme, network))
+		return nil, NewVerifyError(ReasonNoFacilitator, "", network, fmt.Errorf("no facilitator for %s on %s", scheme, network))
 	}
 
 	// Use already marshaled bytes for network call
@@ -336,6 +629,7 @@ func (s *x402ResourceServer) VerifyPayment(ctx context.Context, payload types.Pa
 
 	// Handle failure
 	if verifyErr != nil {
+		s.logger.Warn("facilitator verification failed", logFields(scheme, string(network), errorPayer(verifyErr), errorMetricReason(verifyErr), verifyErr)...)
 		failureCtx := VerifyFailureContext{VerifyContext: hookCtx, Error: verifyErr}
 		for _, hook := range s.onVerifyFailureHooks {
 			result, _ := hook(failureCtx)
@@ -357,15 +651,18 @@ func (s *x402ResourceServer) VerifyPayment(ctx context.Context, payload types.Pa
 
 // SettlePayment settles a V2 payment
 func (s *x402ResourceServer) SettlePayment(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+	// Hand our trace context on to the facilitator
+	payload.Extensions = InjectTraceContext(ctx, s.tracer, payload.Extensions)
+
 	// Marshal to bytes early for hooks (escape hatch for extensions)
 	payloadBytes, err := json.Marshal(payload)
 	if err != nil {
-		return nil, NewSettleError("failed_to_marshal_payload", "", Network(requirements.Network), "", err)
+		return nil, NewSettleError(ReasonFailedToMarshalPayload, "", Network(requirements.Network), "", err)
 	}
 
 	requirementsBytes, err := json.Marshal(requirements)
 	if err != nil {
-		return nil, NewSettleError("failed_to_marshal_requirements", "", Network(requirements.Network), "", err)
+		return nil, NewSettleError(ReasonFailedToMarshalRequirements, "", Network(requirements.Network), "", err)
 	}
 
 	// Execute beforeSettle hooks
@@ -395,14 +692,28 @@ func (s *x402ResourceServer) SettlePayment(ctx context.Context, payload types.Pa
 	s.mu.RUnlock()
 
 	if facilitator == nil {
-		return nil, NewSettleError("no_facilitator", "", network, "", fmt.Errorf("no facilitator for %s on %s", scheme, network))
+		return nil, NewSettleError(ReasonNoFacilitator, "", network, "", fmt.Errorf("no facilitator for %s on %s", scheme, network))
+	}
+
+	// Optionally dry-run the settlement first, so one that would fail is never submitted
+	var settleResult *SettleResponse
+	var settleErr error
+	if simulator, ok := facilitator.(SimulatingFacilitatorClient); ok && s.simulateSettlement {
+		_, settleErr = simulator.SimulateSettle(ctx, payloadBytes, requirementsBytes)
+		var se *SettleError
+		if errors.As(settleErr, &se) && se.Reason == ReasonSimulationNotSupported {
+			settleErr = nil
+		}
 	}
 
 	// Use already marshaled bytes for network call
-	settleResult, settleErr := facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+	if settleErr == nil {
+		settleResult, settleErr = facilitator.Settle(ctx, payloadBytes, requirementsBytes)
+	}
 
 	// Handle failure
 	if settleErr != nil {
+		s.logger.Warn("facilitator settlement failed", logFields(scheme, string(network), errorPayer(settleErr), errorMetricReason(settleErr), settleErr)...)
 		failureCtx := SettleFailureContext{SettleContext: hookCtx, Error: settleErr}
 		for _, hook := range s.onSettleFailureHooks {
 			result, _ := hook(failureCtx)
@@ -456,13 +767,13 @@ func (s *x402ResourceServer) BuildPaymentRequirementsFromConfig(ctx context.Cont
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	schemeServer := s.schemes[config.Network][config.Scheme]
+	schemeServer := findByNetworkAndScheme(s.schemes, config.Scheme, config.Network)
 	if schemeServer == nil {
 		return nil, fmt.Errorf("no scheme server for %s on %s", config.Scheme, config.Network)
 	}
 
 	// Look up cached supported kinds from facilitator
-	// This was populated during Initialize() by querying facilitator's /supported endpoint
+	// This was populated during Initialize() (and each refresh) by querying facilitator's /supported endpoint
 	var supportedKind types.SupportedKind
 	foundKind := false
 
@@ -3,9 +3,11 @@ package x402
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
+	exttypes "x402-go/extensions/types"
 	"x402-go/types"
 )
 
@@ -82,6 +84,57 @@ func TestNewx402ResourceServer(t *testing.T) {
 	}
 }
 
+func TestServerDeclareDiscovery(t *testing.T) {
+	tests := []struct {
+		name     string
+		routeKey string
+		config   interface{}
+		method   string // expected declared method, "" for an error
+	}{
+		{"method from route key", "DELETE /resource/:id", exttypes.DeclareQueryDiscoveryConfig{}, "DELETE"},
+		{"lowercase route method", "get /weather", exttypes.DeclareQueryDiscoveryConfig{}, "GET"},
+		{"method from config", "/search", exttypes.DeclareQueryDiscoveryConfig{Method: exttypes.MethodHEAD}, "HEAD"},
+		{"body route", "POST /compute", exttypes.DeclareBodyDiscoveryConfig{}, "POST"},
+		{"no method", "/search", exttypes.DeclareQueryDiscoveryConfig{}, ""},
+		{"conflicting methods", "GET /weather", exttypes.DeclareQueryDiscoveryConfig{Method: exttypes.MethodDELETE}, ""},
+		{"body config on query route", "GET /weather", exttypes.DeclareBodyDiscoveryConfig{}, ""},
+		{"query config on body route", "PUT /items", exttypes.DeclareQueryDiscoveryConfig{}, ""},
+		{"unsupported config", "GET /weather", map[string]interface{}{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := Newx402ResourceServer()
+			err := server.DeclareDiscovery(tt.routeKey, tt.config)
+
+			declaration, ok := server.DiscoveryDeclaration(tt.routeKey)
+			if tt.method == "" {
+				if err == nil || ok {
+					t.Errorf("Expected the declaration to be rejected, got %+v", declaration)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var method string
+			switch c := declaration.(type) {
+			case exttypes.DeclareQueryDiscoveryConfig:
+				method = string(c.Method)
+			case exttypes.DeclareBodyDiscoveryConfig:
+				method = string(c.Method)
+				if c.BodyType != exttypes.BodyTypeJSON {
+					t.Errorf("Expected body type to default to json, got %q", c.BodyType)
+				}
+			}
+			if method != tt.method {
+				t.Errorf("Expected method %s, got %s", tt.method, method)
+			}
+		})
+	}
+}
+
 func TestServerWithOptions(t *testing.T) {
 	mockClient := &mockFacilitatorClient{
 		kinds: []SupportedKind{
@@ -198,6 +251,156 @@ func TestServerInitializeWithMultipleFacilitators(t *testing.T) {
 	}
 }
 
+// refreshingFacilitatorClient serves kinds that can change between calls, or fails while err is set
+type refreshingFacilitatorClient struct {
+	mockServerFacilitatorClient
+	mu    sync.Mutex
+	err   error
+	calls int
+}
+
+func (m *refreshingFacilitatorClient) GetSupported(ctx context.Context) (SupportedResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	if m.err != nil {
+		return SupportedResponse{}, m.err
+	}
+	return m.mockServerFacilitatorClient.GetSupported(ctx)
+}
+
+func (m *refreshingFacilitatorClient) set(kinds []SupportedKind, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.kinds, m.err = kinds, err
+}
+
+func (m *refreshingFacilitatorClient) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// routes reports whether the server has a facilitator for scheme on network
+func routes(server *x402ResourceServer, network Network, scheme string) bool {
+	server.mu.RLock()
+	defer server.mu.RUnlock()
+	return server.facilitatorClients[network][scheme] != nil
+}
+
+// waitFor polls condition until it holds or a second passes
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestServerRefreshSupported(t *testing.T) {
+	ctx := context.Background()
+	mockClient := &refreshingFacilitatorClient{}
+	mockClient.set([]SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}}, nil)
+
+	server := Newx402ResourceServer(WithFacilitatorClient(mockClient))
+	if err := server.Initialize(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mockClient.set([]SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:8453"}}, nil)
+	if err := server.RefreshSupported(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if routes(server, "eip155:1", "exact") {
+		t.Error("Expected the dropped kind to be removed")
+	}
+	if !routes(server, "eip155:8453", "exact") {
+		t.Error("Expected the added kind to be routed")
+	}
+
+	// A failed refresh keeps the last good kinds
+	mockClient.set(nil, errors.New("facilitator unavailable"))
+	if err := server.RefreshSupported(ctx); err == nil {
+		t.Fatal("Expected refresh error")
+	}
+	if !routes(server, "eip155:8453", "exact") {
+		t.Error("Expected the previous kinds to be kept after a failed refresh")
+	}
+	if len(server.supportedCache.data) != 1 {
+		t.Errorf("Expected the cached response to be kept, got %d", len(server.supportedCache.data))
+	}
+}
+
+func TestServerSupportedRefresh(t *testing.T) {
+	mockClient := &refreshingFacilitatorClient{}
+	mockClient.set([]SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}}, nil)
+
+	server := Newx402ResourceServer(
+		WithFacilitatorClient(mockClient),
+		WithSupportedRefresh(20*time.Millisecond),
+	)
+	defer server.Close()
+	if err := server.Initialize(context.Background()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	mockClient.set([]SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:8453"}}, nil)
+	waitFor(t, func() bool { return routes(server, "eip155:8453", "exact") })
+
+	server.Close()
+	calls := mockClient.callCount()
+	time.Sleep(60 * time.Millisecond)
+	if mockClient.callCount() != calls {
+		t.Error("Expected no refreshes after Close")
+	}
+}
+
+func TestServerSupportedRefreshRetriesFailedInitialize(t *testing.T) {
+	mockClient := &refreshingFacilitatorClient{}
+	mockClient.set(nil, errors.New("facilitator unavailable"))
+
+	server := Newx402ResourceServer(
+		WithFacilitatorClient(mockClient),
+		WithSupportedRefresh(time.Hour),
+	)
+	server.refreshRetryDelay = 10 * time.Millisecond
+	defer server.Close()
+
+	if err := server.Initialize(context.Background()); err == nil {
+		t.Fatal("Expected initialize error")
+	}
+
+	// Retries back off from refreshRetryDelay instead of waiting out the hour
+	mockClient.set([]SupportedKind{{X402Version: 2, Scheme: "exact", Network: "eip155:1"}}, nil)
+	waitFor(t, func() bool { return routes(server, "eip155:1", "exact") })
+}
+
+func TestServerNextRefreshDelay(t *testing.T) {
+	server := Newx402ResourceServer(WithSupportedRefresh(time.Minute))
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{10, time.Minute},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			delay := server.nextRefreshDelay(tt.failures)
+			if delay < tt.expected*9/10 || delay > tt.expected*11/10 {
+				t.Errorf("failures=%d: expected within 10%% of %v, got %v", tt.failures, tt.expected, delay)
+			}
+		}
+	}
+}
+
 func TestServerBuildPaymentRequirements(t *testing.T) {
 	ctx := context.Background()
 
@@ -258,6 +461,37 @@ func TestServerBuildPaymentRequirements(t *testing.T) {
 	}
 }
 
+// contextPriceServer parses prices with the request context
+type contextPriceServer struct {
+	mockSchemeNetworkServer
+}
+
+type priceCtxKey struct{}
+
+func (m *contextPriceServer) ParsePriceContext(ctx context.Context, price Price, network Network) (AssetAmount, error) {
+	rate, _ := ctx.Value(priceCtxKey{}).(string)
+	return AssetAmount{Asset: "WETH", Amount: rate}, nil
+}
+
+func TestServerBuildPaymentRequirementsContextPriceParser(t *testing.T) {
+	ctx := context.WithValue(context.Background(), priceCtxKey{}, "50000000000000")
+
+	server := Newx402ResourceServer(
+		WithFacilitatorClient(&mockFacilitatorClient{}),
+		WithSchemeServer("eip155:1", &contextPriceServer{mockSchemeNetworkServer{scheme: "exact"}}),
+	)
+	server.Initialize(ctx)
+
+	config := ResourceConfig{Scheme: "exact", PayTo: "0xrecipient", Price: "$0.10", Network: "eip155:1"}
+	requirements, err := server.BuildPaymentRequirements(ctx, config, types.SupportedKind{Scheme: "exact", Network: "eip155:1"}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requirements.Asset != "WETH" || requirements.Amount != "50000000000000" {
+		t.Errorf("Expected the context-aware parser to be used, got %s %s", requirements.Amount, requirements.Asset)
+	}
+}
+
 func TestServerBuildPaymentRequirementsNoScheme(t *testing.T) {
 	ctx := context.Background()
 	server := Newx402ResourceServer()
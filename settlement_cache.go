@@ -0,0 +1,89 @@
+package x402
+
+import (
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Settlement Cache
+// ============================================================================
+
+// DefaultSettlementCacheTTL is how long a MemorySettlementCache keeps a settlement
+const DefaultSettlementCacheTTL = time.Hour
+
+// SettlementCache remembers successful settlements so settling the same payment again
+// (e.g. a retry after a timeout) returns the original result instead of broadcasting a
+// second transaction.
+//
+// Mechanisms derive the key from the authorization (network, token, payer and nonce) and a
+// digest of the exact payload and requirements, so only an identical request gets the cached
+// result. Anything else goes through the normal checks and fails on the used nonce.
+//
+// MemorySettlementCache covers a single facilitator instance. Facilitators running several
+// instances can plug in a shared implementation (e.g. Redis with an expiry).
+type SettlementCache interface {
+	// Get returns the settlement cached under key, if any
+	Get(key string) (*SettleResponse, bool)
+
+	// Put caches a successful settlement under key
+	Put(key string, response *SettleResponse)
+}
+
+// MemorySettlementCache is an in-process SettlementCache whose entries expire after a TTL
+type MemorySettlementCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedSettlement
+	now     func() time.Time // overridable in tests
+}
+
+// cachedSettlement is a cached result and when it expires
+type cachedSettlement struct {
+	response SettleResponse
+	expires  time.Time
+}
+
+// NewMemorySettlementCache creates an in-memory SettlementCache. A ttl <= 0 uses
+// DefaultSettlementCacheTTL.
+func NewMemorySettlementCache(ttl time.Duration) *MemorySettlementCache {
+	if ttl <= 0 {
+		ttl = DefaultSettlementCacheTTL
+	}
+	return &MemorySettlementCache{
+		ttl:     ttl,
+		entries: make(map[string]cachedSettlement),
+		now:     time.Now,
+	}
+}
+
+// Get returns a copy of the unexpired settlement cached under key
+func (c *MemorySettlementCache) Get(key string) (*SettleResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	response := entry.response
+	return &response, true
+}
+
+// Put caches a copy of response under key, dropping expired entries
+func (c *MemorySettlementCache) Put(key string, response *SettleResponse) {
+	if response == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedSettlement{response: *response, expires: now.Add(c.ttl)}
+}
@@ -0,0 +1,38 @@
+package x402
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemorySettlementCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cache := NewMemorySettlementCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	if _, ok := cache.Get("key"); ok {
+		t.Fatal("Expected an empty cache")
+	}
+
+	response := &SettleResponse{Success: true, Transaction: "0xabc", Network: "eip155:8453"}
+	cache.Put("key", response)
+	response.Transaction = "0xchanged"
+
+	cached, ok := cache.Get("key")
+	if !ok || cached.Transaction != "0xabc" {
+		t.Fatalf("Expected the cached settlement, got %+v", cached)
+	}
+	cached.Transaction = "0xchanged"
+	if again, _ := cache.Get("key"); again.Transaction != "0xabc" {
+		t.Error("Expected callers to get copies")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("key"); ok {
+		t.Error("Expected the settlement to expire")
+	}
+	cache.Put("other", response)
+	if len(cache.entries) != 1 {
+		t.Errorf("Expected expired entries to be dropped, have %d", len(cache.entries))
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
-	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
@@ -18,7 +17,6 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 // ClientSigner implements x402evm.ClientEvmSigner using an ECDSA private key.
@@ -26,7 +24,13 @@ import (
 type ClientSigner struct {
 	privateKey *ecdsa.PrivateKey
 	address    common.Address
-	ethClient  *ethclient.Client
+	pool       *ClientPool
+	rpcURL     string
+	rpcClient  *TimeoutClient
+	nonces     *NonceManager
+	gas        GasConfig
+	receipts   ReceiptConfig
+	rpc        RPCConfig
 }
 
 // NewClientSignerFromPrivateKey creates a client signer from a hex-encoded private key.
@@ -67,16 +71,54 @@ func NewClientSignerFromPrivateKey(privateKeyHex string) (x402evm.ClientEvmSigne
 	}, nil
 }
 
-// Connect connects the signer to an RPC endpoint
+// Connect connects the signer to an RPC endpoint, sharing the connection with other signers
+// using the same URL through the signer's client pool (DefaultClientPool unless SetClientPool
+// was called). Connecting again releases the previous connection.
 func (s *ClientSigner) Connect(rpcURL string) error {
-	client, err := ethclient.Dial(rpcURL)
+	if s.pool == nil {
+		s.pool = DefaultClientPool
+	}
+	client, err := s.pool.Acquire(context.Background(), rpcURL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to RPC: %w", err)
 	}
-	s.ethClient = client
+	s.Close()
+	s.rpcURL = rpcURL
+	s.rpcClient = NewTimeoutClient(client, &s.rpc)
+	s.nonces = NewNonceManager(s.rpcClient)
 	return nil
 }
 
+// Close releases the signer's RPC connection, closing it unless another signer shares it
+func (s *ClientSigner) Close() {
+	if s.rpcURL == "" {
+		return
+	}
+	s.pool.Release(s.rpcURL)
+	s.rpcURL = ""
+}
+
+// SetClientPool sets the pool Connect takes its connection from. Call it before Connect.
+func (s *ClientSigner) SetClientPool(pool *ClientPool) {
+	s.pool = pool
+}
+
+// SetGasConfig bounds the gas price and limit of transactions sent by WriteContract
+func (s *ClientSigner) SetGasConfig(config GasConfig) {
+	s.gas = config
+}
+
+// SetReceiptConfig controls how WaitForTransactionReceipt polls, how many confirmations
+// it waits for and when it gives up
+func (s *ClientSigner) SetReceiptConfig(config ReceiptConfig) {
+	s.receipts = config
+}
+
+// SetRPCConfig bounds each RPC call the signer makes
+func (s *ClientSigner) SetRPCConfig(config RPCConfig) {
+	s.rpc = config
+}
+
 // Address returns the Ethereum address of the signer.
 func (s *ClientSigner) Address() string {
 	return s.address.Hex()
@@ -165,7 +207,8 @@ func (s *ClientSigner) SignTypedData(
 	// Adjust v value for Ethereum (recovery ID 0/1 → 27/28)
 	signature[64] += 27
 
-	return signature, nil
+	// crypto.Sign already returns low-S; normalize anyway so verifiers requiring it never reject ours
+	return x402evm.NormalizeSignatureLowS(signature), nil
 }
 
 // ReadContract reads data from a smart contract
@@ -176,7 +219,7 @@ func (s *ClientSigner) ReadContract(
 	functionName string,
 	args ...interface{},
 ) (interface{}, error) {
-	if s.ethClient == nil {
+	if s.rpcClient == nil {
 		return nil, fmt.Errorf("RPC client not configured")
 	}
 
@@ -199,10 +242,9 @@ func (s *ClientSigner) ReadContract(
 		Data: data,
 	}
 
-	resultBytes, err := s.ethClient.CallContract(ctx, msg, nil)
+	resultBytes, err := s.rpcClient.CallContract(ctx, msg, nil)
 	if err != nil {
-		// Detect revert
-		return nil, err
+		return nil, x402evm.DecodeRevertError(err, abiJSON)
 	}
 
 	// Unpack result
@@ -237,7 +279,7 @@ func (s *ClientSigner) WriteContract(
 	functionName string,
 	args ...interface{},
 ) (string, error) {
-	if s.ethClient == nil {
+	if s.rpcClient == nil {
 		return "", fmt.Errorf("RPC client not configured")
 	}
 
@@ -254,86 +296,65 @@ func (s *ClientSigner) WriteContract(
 	}
 
 	// Get chain ID
-	chainID, err := s.ethClient.ChainID(ctx)
+	chainID, err := s.rpcClient.ChainID(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get chain ID: %w", err)
 	}
 
-	// Get nonce
-	nonce, err := s.ethClient.PendingNonceAt(ctx, s.address)
-	if err != nil {
-		return "", fmt.Errorf("failed to get nonce: %w", err)
-	}
-
-	// Get gas price
-	gasPrice, err := s.ethClient.SuggestGasPrice(ctx)
-	if err != nil {
-		return "", fmt.Errorf("failed to get gas price: %w", err)
-	}
-
-	// Estimate gas
+	// Price the transaction, refusing to send it above the configured caps
 	to := common.HexToAddress(contractAddress)
 	msg := ethereum.CallMsg{
 		From: s.address,
 		To:   &to,
 		Data: data,
 	}
-	gasLimit, err := s.ethClient.EstimateGas(ctx, msg)
+	gasPrice, gasLimit, err := s.gas.Estimate(ctx, s.rpcClient, msg)
 	if err != nil {
-		// Fallback for gas estimation failure (or add buffer)
-		gasLimit = 300000 // Safe default for simple calls
-	} else {
-		gasLimit = uint64(float64(gasLimit) * 1.2) // Add buffer
+		return "", x402evm.DecodeRevertError(err, abiJSON)
 	}
 
-	// Create transaction (Legacy for simplicity, or EIP-1559 if supported)
-	// Using NewTransaction (Legacy) is safest for generic support unless we check feecap
-	tx := types.NewTransaction(nonce, to, big.NewInt(0), gasLimit, gasPrice, data)
-
-	// Sign transaction
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), s.privateKey)
-	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %w", err)
-	}
+	// Allocate a nonce, then sign and send
+	var signedTx *types.Transaction
+	err = s.nonces.Send(ctx, s.address, func(nonce uint64) error {
+		// Create transaction (Legacy for simplicity, or EIP-1559 if supported)
+		// Using NewTransaction (Legacy) is safest for generic support unless we check feecap
+		tx := types.NewTransaction(nonce, to, big.NewInt(0), gasLimit, gasPrice, data)
+
+		// Sign transaction
+		signed, err := types.SignTx(tx, types.NewEIP155Signer(chainID), s.privateKey)
+		if err != nil {
+			return fmt.Errorf("failed to sign transaction: %w", err)
+		}
 
-	// Send transaction
-	err = s.ethClient.SendTransaction(ctx, signedTx)
+		// Send transaction
+		if err := s.rpcClient.SendTransaction(ctx, signed); err != nil {
+			return fmt.Errorf("failed to send transaction: %w", err)
+		}
+		signedTx = signed
+		return nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %w", err)
+		return "", err
 	}
 
 	return signedTx.Hash().Hex(), nil
 }
 
-// WaitForTransactionReceipt waits for a transaction to be mined
-func (s *ClientSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*x402evm.TransactionReceipt, error) {
-	if s.ethClient == nil {
-		return nil, fmt.Errorf("RPC client not configured")
+// ResetNonce drops the locally tracked nonce so the next transaction resyncs from the chain.
+// Call it when a transaction sent by this signer is known to have been dropped.
+func (s *ClientSigner) ResetNonce() {
+	if s.nonces != nil {
+		s.nonces.Reset(s.address)
 	}
+}
 
-	hash := common.HexToHash(txHash)
-
-	// Polling loop
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-ticker.C:
-			receipt, err := s.ethClient.TransactionReceipt(ctx, hash)
-			if err != nil {
-				if err == ethereum.NotFound {
-					continue // Not mined yet
-				}
-				return nil, err
-			}
-			return &x402evm.TransactionReceipt{
-				Status:      receipt.Status,
-				BlockNumber: receipt.BlockNumber.Uint64(),
-				TxHash:      receipt.TxHash.Hex(),
-			}, nil
-		}
+// WaitForTransactionReceipt waits for a transaction to be mined with the configured
+// confirmations, giving up with x402evm.ErrReceiptTimeout after the configured timeout
+func (s *ClientSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*x402evm.TransactionReceipt, error) {
+	if s.rpcClient == nil {
+		return nil, fmt.Errorf("RPC client not configured")
 	}
+	return s.receipts.Wait(ctx, s.rpcClient, txHash)
 }
+
+var _ x402evm.NonceResetter = (*ClientSigner)(nil)
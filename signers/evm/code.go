@@ -0,0 +1,108 @@
+package evm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CodeCacheConfig controls the cache a signer keeps of GetCode results.
+// The zero value disables the cache, so every GetCode call reaches the RPC endpoint.
+type CodeCacheConfig struct {
+	// TTL is how long a GetCode result is reused. Results are also dropped as soon as the
+	// signer sees a new block. Zero disables the cache.
+	//
+	// Keep it short (around the chain's block time): a smart wallet deployed in the
+	// meantime keeps reporting no code until its cached result expires.
+	TTL time.Duration
+}
+
+// CodeCache remembers the bytecode at each address for a short time, so verifying many
+// payments from the same smart wallets doesn't fetch the same code over and over. Whether
+// an address holds code can't change within a block, so entries are dropped when a newer
+// block is observed or after the TTL, whichever comes first.
+//
+// A nil *CodeCache is valid and caches nothing.
+type CodeCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	block   uint64 // highest block observed
+	entries map[common.Address]cachedCode
+	now     func() time.Time // overridable in tests
+}
+
+// cachedCode is the code fetched for an address and when it expires
+type cachedCode struct {
+	code    []byte
+	expires time.Time
+}
+
+// NewCodeCache creates a CodeCache, or returns nil when config disables caching
+func NewCodeCache(config CodeCacheConfig) *CodeCache {
+	if config.TTL <= 0 {
+		return nil
+	}
+	return &CodeCache{
+		ttl:     config.TTL,
+		entries: make(map[common.Address]cachedCode),
+		now:     time.Now,
+	}
+}
+
+// GetCode returns the cached code at address, calling fetch on a miss. Only successful
+// fetches are cached. The returned slice is shared with the cache and must not be modified.
+func (c *CodeCache) GetCode(
+	ctx context.Context,
+	address common.Address,
+	fetch func(ctx context.Context, address common.Address) ([]byte, error),
+) ([]byte, error) {
+	if c == nil {
+		return fetch(ctx, address)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[address]
+	block := c.block
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.code, nil
+	}
+
+	code, err := fetch(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// A block observed during the fetch may already make the result stale
+	if c.block == block {
+		now := c.now()
+		for a, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, a)
+			}
+		}
+		c.entries[address] = cachedCode{code: code, expires: now.Add(c.ttl)}
+	}
+	return code, nil
+}
+
+// ObserveBlock records the chain head, dropping every entry when it is newer than the
+// last block observed
+func (c *CodeCache) ObserveBlock(number uint64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if number > c.block {
+		c.block = number
+		clear(c.entries)
+	}
+}
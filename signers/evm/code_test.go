@@ -0,0 +1,131 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCodeCache(t *testing.T) {
+	ctx := context.Background()
+	wallet := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	other := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	fetches := 0
+	fetch := func(ctx context.Context, address common.Address) ([]byte, error) {
+		fetches++
+		return []byte{0x60, 0x80}, nil
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		if cache := NewCodeCache(CodeCacheConfig{}); cache != nil {
+			t.Fatal("Expected zero TTL to disable the cache")
+		}
+
+		var cache *CodeCache
+		fetches = 0
+		for i := 0; i < 2; i++ {
+			if _, err := cache.GetCode(ctx, wallet, fetch); err != nil {
+				t.Fatalf("GetCode() failed: %v", err)
+			}
+		}
+		cache.ObserveBlock(1)
+		if fetches != 2 {
+			t.Errorf("fetches = %d, want 2", fetches)
+		}
+	})
+
+	t.Run("Reuses Within TTL", func(t *testing.T) {
+		now := time.Unix(1700000000, 0)
+		cache := NewCodeCache(CodeCacheConfig{TTL: 2 * time.Second})
+		cache.now = func() time.Time { return now }
+		fetches = 0
+
+		for i := 0; i < 3; i++ {
+			code, err := cache.GetCode(ctx, wallet, fetch)
+			if err != nil {
+				t.Fatalf("GetCode() failed: %v", err)
+			}
+			if len(code) != 2 {
+				t.Errorf("GetCode() returned %x", code)
+			}
+		}
+		if _, err := cache.GetCode(ctx, other, fetch); err != nil {
+			t.Fatalf("GetCode() failed: %v", err)
+		}
+		if fetches != 2 {
+			t.Errorf("fetches = %d, want 2 (one per address)", fetches)
+		}
+
+		now = now.Add(2 * time.Second)
+		if _, err := cache.GetCode(ctx, wallet, fetch); err != nil {
+			t.Fatalf("GetCode() failed: %v", err)
+		}
+		if fetches != 3 {
+			t.Errorf("fetches = %d, want 3 after the TTL", fetches)
+		}
+	})
+
+	t.Run("New Block Invalidates", func(t *testing.T) {
+		cache := NewCodeCache(CodeCacheConfig{TTL: time.Minute})
+		fetches = 0
+
+		cache.ObserveBlock(10)
+		cache.GetCode(ctx, wallet, fetch)
+		cache.ObserveBlock(10)
+		cache.GetCode(ctx, wallet, fetch)
+		if fetches != 1 {
+			t.Errorf("fetches = %d, want 1 within the same block", fetches)
+		}
+
+		cache.ObserveBlock(11)
+		cache.GetCode(ctx, wallet, fetch)
+		if fetches != 2 {
+			t.Errorf("fetches = %d, want 2 after a new block", fetches)
+		}
+
+		// An older head (e.g. from a lagging RPC node) doesn't drop anything
+		cache.ObserveBlock(9)
+		cache.GetCode(ctx, wallet, fetch)
+		if fetches != 2 {
+			t.Errorf("fetches = %d, want 2 after an older block", fetches)
+		}
+	})
+
+	t.Run("Block During Fetch", func(t *testing.T) {
+		cache := NewCodeCache(CodeCacheConfig{TTL: time.Minute})
+		fetches = 0
+
+		racing := func(ctx context.Context, address common.Address) ([]byte, error) {
+			cache.ObserveBlock(20)
+			return fetch(ctx, address)
+		}
+		cache.GetCode(ctx, wallet, racing)
+		cache.GetCode(ctx, wallet, fetch)
+		if fetches != 2 {
+			t.Errorf("fetches = %d, want 2 (result fetched across blocks isn't cached)", fetches)
+		}
+	})
+
+	t.Run("Errors Not Cached", func(t *testing.T) {
+		cache := NewCodeCache(CodeCacheConfig{TTL: time.Minute})
+		fetches = 0
+
+		failing := func(ctx context.Context, address common.Address) ([]byte, error) {
+			fetches++
+			return nil, errors.New("rpc down")
+		}
+		if _, err := cache.GetCode(ctx, wallet, failing); err == nil {
+			t.Fatal("Expected fetch error")
+		}
+		if _, err := cache.GetCode(ctx, wallet, fetch); err != nil {
+			t.Fatalf("GetCode() failed: %v", err)
+		}
+		if fetches != 2 {
+			t.Errorf("fetches = %d, want 2", fetches)
+		}
+	})
+}
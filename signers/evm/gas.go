@@ -0,0 +1,91 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+
+	x402evm "x402-go/mechanisms/evm"
+)
+
+const (
+	// DefaultGasLimit is used when gas estimation fails
+	DefaultGasLimit = 300000
+
+	// DefaultGasLimitMultiplier is the buffer applied to estimated gas limits
+	DefaultGasLimitMultiplier = 1.2
+)
+
+var (
+	// ErrGasPriceTooHigh is returned instead of sending a transaction when the suggested
+	// gas price exceeds GasConfig.MaxGasPriceWei
+	ErrGasPriceTooHigh = errors.New("gas price exceeds cap")
+
+	// ErrGasLimitTooHigh is returned instead of sending a transaction when its gas limit
+	// exceeds GasConfig.GasLimitCap
+	ErrGasLimitTooHigh = errors.New("gas limit exceeds cap")
+)
+
+// GasConfig bounds what a signer pays for each transaction it sends.
+// The zero value applies no caps and the default 1.2x gas limit buffer.
+type GasConfig struct {
+	// MaxGasPriceWei is the highest gas price (the max fee per gas of the legacy
+	// transactions the signers send) a transaction may pay. Nil means no cap.
+	MaxGasPriceWei *big.Int
+
+	// GasLimitCap is the highest gas limit a transaction may use, after the buffer.
+	// Zero means no cap.
+	GasLimitCap uint64
+
+	// GasLimitMultiplier is applied to estimated gas limits (defaults to 1.2)
+	GasLimitMultiplier float64
+}
+
+// GasEstimator prices transactions. *ethclient.Client implements it.
+type GasEstimator interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+}
+
+// Estimate returns the gas price and limit to send msg with, or an error wrapping
+// ErrGasPriceTooHigh or ErrGasLimitTooHigh when either exceeds its cap. An estimation that
+// reverts returns an error wrapping *x402evm.RevertError, as does one that times out (see
+// ErrRPCTimeout) or outlives ctx; other estimation failures fall back to DefaultGasLimit.
+func (c GasConfig) Estimate(ctx context.Context, estimator GasEstimator, msg ethereum.CallMsg) (*big.Int, uint64, error) {
+	gasPrice, err := estimator.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	if c.MaxGasPriceWei != nil && gasPrice.Cmp(c.MaxGasPriceWei) > 0 {
+		return nil, 0, fmt.Errorf("%w: %s > %s wei", ErrGasPriceTooHigh, gasPrice, c.MaxGasPriceWei)
+	}
+
+	multiplier := c.GasLimitMultiplier
+	if multiplier <= 0 {
+		multiplier = DefaultGasLimitMultiplier
+	}
+
+	gasLimit, err := estimator.EstimateGas(ctx, msg)
+	if err != nil {
+		// Don't pay for a transaction the node already knows will revert
+		var revertErr *x402evm.RevertError
+		if decoded := x402evm.DecodeRevertError(err, nil); errors.As(decoded, &revertErr) {
+			return nil, 0, fmt.Errorf("gas estimation failed: %w", decoded)
+		}
+		// Nor send with a guessed limit to a node that isn't answering
+		if errors.Is(err, ErrRPCTimeout) || ctx.Err() != nil {
+			return nil, 0, fmt.Errorf("gas estimation failed: %w", err)
+		}
+		gasLimit = DefaultGasLimit
+	} else {
+		gasLimit = uint64(float64(gasLimit) * multiplier) // Add buffer
+	}
+	if c.GasLimitCap > 0 && gasLimit > c.GasLimitCap {
+		return nil, 0, fmt.Errorf("%w: %d > %d", ErrGasLimitTooHigh, gasLimit, c.GasLimitCap)
+	}
+
+	return gasPrice, gasLimit, nil
+}
@@ -0,0 +1,112 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+
+	x402evm "x402-go/mechanisms/evm"
+)
+
+// fakeGasEstimator returns a fixed gas price and estimate
+type fakeGasEstimator struct {
+	gasPrice    *big.Int
+	gasLimit    uint64
+	estimateErr error
+}
+
+func (e *fakeGasEstimator) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return e.gasPrice, nil
+}
+
+func (e *fakeGasEstimator) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return e.gasLimit, e.estimateErr
+}
+
+func TestGasConfigEstimate(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       GasConfig
+		estimator    *fakeGasEstimator
+		wantGasLimit uint64
+		wantErr      error
+		wantRevert   bool
+	}{
+		{
+			name:         "default buffer",
+			estimator:    &fakeGasEstimator{gasPrice: big.NewInt(1e9), gasLimit: 100000},
+			wantGasLimit: 120000,
+		},
+		{
+			name:         "custom buffer",
+			config:       GasConfig{GasLimitMultiplier: 1.5},
+			estimator:    &fakeGasEstimator{gasPrice: big.NewInt(1e9), gasLimit: 100000},
+			wantGasLimit: 150000,
+		},
+		{
+			name:         "estimation failure falls back",
+			estimator:    &fakeGasEstimator{gasPrice: big.NewInt(1e9), estimateErr: errors.New("connection reset by peer")},
+			wantGasLimit: DefaultGasLimit,
+		},
+		{
+			name:      "estimation timeout is returned",
+			estimator: &fakeGasEstimator{gasPrice: big.NewInt(1e9), estimateErr: fmt.Errorf("%w: eth_estimateGas after 10s", ErrRPCTimeout)},
+			wantErr:   ErrRPCTimeout,
+		},
+		{
+			name:       "estimation revert is returned",
+			estimator:  &fakeGasEstimator{gasPrice: big.NewInt(1e9), estimateErr: errors.New("execution reverted: FiatToken: authorization is used or canceled")},
+			wantRevert: true,
+		},
+		{
+			name:         "within caps",
+			config:       GasConfig{MaxGasPriceWei: big.NewInt(1e9), GasLimitCap: 120000},
+			estimator:    &fakeGasEstimator{gasPrice: big.NewInt(1e9), gasLimit: 100000},
+			wantGasLimit: 120000,
+		},
+		{
+			name:      "gas price above cap",
+			config:    GasConfig{MaxGasPriceWei: big.NewInt(1e9)},
+			estimator: &fakeGasEstimator{gasPrice: big.NewInt(2e9), gasLimit: 100000},
+			wantErr:   ErrGasPriceTooHigh,
+		},
+		{
+			name:      "buffered gas limit above cap",
+			config:    GasConfig{GasLimitCap: 110000},
+			estimator: &fakeGasEstimator{gasPrice: big.NewInt(1e9), gasLimit: 100000},
+			wantErr:   ErrGasLimitTooHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gasPrice, gasLimit, err := tt.config.Estimate(context.Background(), tt.estimator, ethereum.CallMsg{})
+			if tt.wantRevert {
+				var revertErr *x402evm.RevertError
+				if !errors.As(err, &revertErr) {
+					t.Fatalf("Expected a RevertError, got %v", err)
+				}
+				return
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if gasPrice.Cmp(tt.estimator.gasPrice) != 0 {
+				t.Errorf("gasPrice = %s, want %s", gasPrice, tt.estimator.gasPrice)
+			}
+			if gasLimit != tt.wantGasLimit {
+				t.Errorf("gasLimit = %d, want %d", gasLimit, tt.wantGasLimit)
+			}
+		})
+	}
+}
@@ -0,0 +1,526 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	x402evm "x402-go/mechanisms/evm"
+)
+
+// DigestSigner signs a 32-byte digest with the key behind address, returning a 65-byte
+// [R || S || V] signature with V as the recovery id (0 or 1)
+type DigestSigner func(ctx context.Context, address common.Address, digest []byte) ([]byte, error)
+
+// signerKey is a sending address with its usage bookkeeping
+type signerKey struct {
+	address  common.Address
+	lastUsed uint64    // selection sequence number of the last use
+	inFlight int       // transactions currently being sent
+	lowUntil time.Time // skipped by selection until then, after its balance fell below MinKeyBalance
+}
+
+// lowBalanceRecheck is how long a key found below MinKeyBalance is skipped before its
+// balance is read again
+const lowBalanceRecheck = time.Minute
+
+// KeyedSigner implements x402evm.FacilitatorEvmSigner over a set of sending addresses whose
+// keys are only reached through a DigestSigner. It selects the key for each transaction,
+// tracks nonces per key, bounds gas and RPC calls and serves every chain read, so signers
+// such as MultiKeySigner and the KMS signer only supply how a digest is signed.
+type KeyedSigner struct {
+	keys       []*signerKey
+	sign       DigestSigner
+	selection  KeySelection
+	gas        GasConfig
+	receipts   ReceiptConfig
+	rpc        RPCConfig
+	codes      *CodeCache
+	minBalance *big.Int
+	pool       *ClientPool
+	rpcURL     string
+	rpcClient  *TimeoutClient
+	chainID    *big.Int
+	nonces     *NonceManager
+
+	// Overridable in tests
+	balanceAt func(ctx context.Context, address common.Address) (*big.Int, error)
+	now       func() time.Time
+
+	mu  sync.Mutex
+	seq uint64
+}
+
+// NewKeyedSigner creates a facilitator signer that sends from addresses, signing through sign.
+// The signer reads and sends nothing until Connect is called.
+//
+// Args:
+//
+//	addresses: Sending addresses, one per key
+//	sign: Signs digests with the key behind each address
+//	config: Optional configuration (nil uses round-robin selection)
+//
+// Returns:
+//
+//	*KeyedSigner ready to connect
+//	Error if no address is given, an address repeats or sign is nil
+func NewKeyedSigner(addresses []common.Address, sign DigestSigner, config *MultiKeySignerConfig) (*KeyedSigner, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("at least one address is required")
+	}
+	if sign == nil {
+		return nil, fmt.Errorf("sign function is required")
+	}
+
+	cfg := MultiKeySignerConfig{}
+	if config != nil {
+		cfg = *config
+	}
+	if cfg.ClientPool == nil {
+		cfg.ClientPool = DefaultClientPool
+	}
+
+	keys := make([]*signerKey, 0, len(addresses))
+	seen := make(map[common.Address]bool)
+	for _, address := range addresses {
+		if seen[address] {
+			return nil, fmt.Errorf("duplicate key for %s", address.Hex())
+		}
+		seen[address] = true
+		keys = append(keys, &signerKey{address: address})
+	}
+
+	s := &KeyedSigner{
+		keys:       keys,
+		sign:       sign,
+		selection:  cfg.Selection,
+		gas:        cfg.Gas,
+		receipts:   cfg.Receipt,
+		rpc:        cfg.RPC,
+		codes:      NewCodeCache(cfg.CodeCache),
+		minBalance: cfg.MinKeyBalance,
+		pool:       cfg.ClientPool,
+		now:        time.Now,
+	}
+	s.balanceAt = s.nativeBalance
+	return s, nil
+}
+
+// Connect connects the signer to an RPC endpoint through its client pool and loads the chain ID.
+// Connecting again releases the previous connection.
+func (s *KeyedSigner) Connect(ctx context.Context, rpcURL string) error {
+	ethClient, err := s.pool.Acquire(ctx, rpcURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+
+	rpcClient := NewTimeoutClient(ethClient, &s.rpc)
+	chainID, err := rpcClient.ChainID(ctx)
+	if err != nil {
+		s.pool.Release(rpcURL)
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	s.Close()
+	s.rpcURL = rpcURL
+	s.rpcClient = rpcClient
+	s.chainID = chainID
+	s.nonces = NewNonceManager(s.rpcClient)
+	return nil
+}
+
+// Close releases the signer's RPC connection, closing it unless another signer shares it.
+// The signer can't send transactions or read the chain afterwards.
+func (s *KeyedSigner) Close() {
+	if s.rpcURL == "" {
+		return
+	}
+	s.pool.Release(s.rpcURL)
+	s.rpcURL = ""
+}
+
+// SetGasConfig bounds the gas price and limit of transactions sent by the signer
+func (s *KeyedSigner) SetGasConfig(config GasConfig) {
+	s.gas = config
+}
+
+// SetReceiptConfig controls how WaitForTransactionReceipt polls, how many confirmations
+// it waits for and when it gives up
+func (s *KeyedSigner) SetReceiptConfig(config ReceiptConfig) {
+	s.receipts = config
+}
+
+// SetRPCConfig bounds each RPC call the signer makes
+func (s *KeyedSigner) SetRPCConfig(config RPCConfig) {
+	s.rpc = config
+}
+
+// SetCodeCacheConfig caches GetCode results per block (disabled by default).
+// Call it before the signer is in use.
+func (s *KeyedSigner) SetCodeCacheConfig(config CodeCacheConfig) {
+	s.codes = NewCodeCache(config)
+}
+
+// GetAddresses returns the addresses of every configured key
+func (s *KeyedSigner) GetAddresses() []string {
+	addresses := make([]string, len(s.keys))
+	for i, k := range s.keys {
+		addresses[i] = k.address.Hex()
+	}
+	return addresses
+}
+
+// GetChainID returns the chain ID of the connected network
+func (s *KeyedSigner) GetChainID(ctx context.Context) (*big.Int, error) {
+	if s.chainID == nil {
+		return nil, fmt.Errorf("RPC client not configured")
+	}
+	return s.chainID, nil
+}
+
+// VerifyTypedData verifies an EIP-712 signature against address.
+// Verification doesn't involve the signer's keys, so any address can be checked.
+func (s *KeyedSigner) VerifyTypedData(
+	ctx context.Context,
+	address string,
+	domain x402evm.TypedDataDomain,
+	types map[string][]x402evm.TypedDataField,
+	primaryType string,
+	message map[string]interface{},
+	signature []byte,
+) (bool, error) {
+	digest, err := x402evm.HashTypedData(domain, types, primaryType, message)
+	if err != nil {
+		return false, err
+	}
+
+	return x402evm.VerifyEOASignature(digest, signature, common.HexToAddress(address))
+}
+
+// ReadContract reads data from a smart contract
+func (s *KeyedSigner) ReadContract(
+	ctx context.Context,
+	contractAddress string,
+	abiJSON []byte,
+	functionName string,
+	args ...interface{},
+) (interface{}, error) {
+	if s.rpcClient == nil {
+		return nil, fmt.Errorf("RPC client not configured")
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(string(abiJSON)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	data, err := parsedABI.Pack(functionName, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack data: %w", err)
+	}
+
+	to := common.HexToAddress(contractAddress)
+	result, err := s.rpcClient.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		return nil, x402evm.DecodeRevertError(err, abiJSON)
+	}
+
+	unpacked, err := parsedABI.Unpack(functionName, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack result: %w", err)
+	}
+
+	if len(unpacked) == 0 {
+		return nil, nil
+	}
+	if len(unpacked) == 1 {
+		return unpacked[0], nil
+	}
+	return unpacked, nil
+}
+
+// SimulateContract runs the call WriteContract would send as an eth_call from the first key,
+// without broadcasting it. A call that would revert returns a *x402evm.RevertError.
+func (s *KeyedSigner) SimulateContract(
+	ctx context.Context,
+	contractAddress string,
+	abiJSON []byte,
+	functionName string,
+	args ...interface{},
+) error {
+	if s.rpcClient == nil {
+		return fmt.Errorf("RPC client not configured")
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(string(abiJSON)))
+	if err != nil {
+		return fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	data, err := parsedABI.Pack(functionName, args...)
+	if err != nil {
+		return fmt.Errorf("failed to pack data: %w", err)
+	}
+
+	to := common.HexToAddress(contractAddress)
+	if _, err := s.rpcClient.CallContract(ctx, ethereum.CallMsg{From: s.keys[0].address, To: &to, Data: data}, nil); err != nil {
+		return x402evm.DecodeRevertError(err, abiJSON)
+	}
+	return nil
+}
+
+// WriteContract executes a smart contract transaction from the next selected key
+func (s *KeyedSigner) WriteContract(
+	ctx context.Context,
+	contractAddress string,
+	abiJSON []byte,
+	functionName string,
+	args ...interface{},
+) (string, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(string(abiJSON)))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ABI: %w", err)
+	}
+
+	data, err := parsedABI.Pack(functionName, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to pack data: %w", err)
+	}
+
+	txHash, err := s.SendTransaction(ctx, contractAddress, data)
+	if err != nil {
+		return "", x402evm.DecodeRevertError(err, abiJSON)
+	}
+	return txHash, nil
+}
+
+// SendTransaction sends a transaction with raw calldata from the next selected key
+func (s *KeyedSigner) SendTransaction(ctx context.Context, to string, data []byte) (string, error) {
+	if s.rpcClient == nil {
+		return "", fmt.Errorf("RPC client not configured")
+	}
+
+	k, err := s.acquireFundedKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer s.releaseKey(k)
+
+	toAddr := common.HexToAddress(to)
+	gasPrice, gasLimit, err := s.gas.Estimate(ctx, s.rpcClient, ethereum.CallMsg{From: k.address, To: &toAddr, Data: data})
+	if err != nil {
+		return "", err
+	}
+
+	var signedTx *types.Transaction
+	err = s.nonces.Send(ctx, k.address, func(nonce uint64) error {
+		tx := types.NewTransaction(nonce, toAddr, big.NewInt(0), gasLimit, gasPrice, data)
+
+		signed, err := s.signTx(ctx, k.address, tx)
+		if err != nil {
+			return err
+		}
+
+		if err := s.rpcClient.SendTransaction(ctx, signed); err != nil {
+			return fmt.Errorf("failed to send transaction: %w", err)
+		}
+		signedTx = signed
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return signedTx.Hash().Hex(), nil
+}
+
+// ResetNonce drops the locally tracked nonces of every key so the next transactions resync
+// from the chain. Call it when a transaction sent by this signer is known to have been dropped.
+func (s *KeyedSigner) ResetNonce() {
+	if s.nonces != nil {
+		s.nonces.ResetAll()
+	}
+}
+
+// WaitForTransactionReceipt waits for a transaction to be mined with the configured
+// confirmations, giving up with x402evm.ErrReceiptTimeout after the configured timeout
+func (s *KeyedSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*x402evm.TransactionReceipt, error) {
+	if s.rpcClient == nil {
+		return nil, fmt.Errorf("RPC client not configured")
+	}
+	receipt, err := s.receipts.Wait(ctx, s.rpcClient, txHash)
+	if err != nil {
+		return nil, err
+	}
+	s.codes.ObserveBlock(receipt.BlockNumber)
+	return receipt, nil
+}
+
+// GetBalance gets the balance of an address for a token (native balance for the zero address)
+func (s *KeyedSigner) GetBalance(ctx context.Context, address string, tokenAddress string) (*big.Int, error) {
+	if s.rpcClient == nil {
+		return nil, fmt.Errorf("RPC client not configured")
+	}
+
+	if tokenAddress == "" || tokenAddress == "0x0000000000000000000000000000000000000000" {
+		balance, err := s.rpcClient.BalanceAt(ctx, common.HexToAddress(address), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get balance: %w", err)
+		}
+		return balance, nil
+	}
+
+	const balanceOfABI = `[{"constant":true,"inputs":[{"name":"account","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"}]`
+
+	result, err := s.ReadContract(ctx, tokenAddress, []byte(balanceOfABI), "balanceOf", common.HexToAddress(address))
+	if err != nil {
+		return nil, err
+	}
+
+	if balance, ok := result.(*big.Int); ok {
+		return balance, nil
+	}
+
+	return nil, fmt.Errorf("unexpected balance type: %T", result)
+}
+
+// GetCode returns the bytecode at the given address, from the code cache when enabled
+func (s *KeyedSigner) GetCode(ctx context.Context, address string) ([]byte, error) {
+	if s.rpcClient == nil {
+		return nil, fmt.Errorf("RPC client not configured")
+	}
+
+	code, err := s.codes.GetCode(ctx, common.HexToAddress(address), func(ctx context.Context, address common.Address) ([]byte, error) {
+		return s.rpcClient.CodeAt(ctx, address, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code: %w", err)
+	}
+	return code, nil
+}
+
+// GetBlockTimestamp returns the timestamp of the latest block
+func (s *KeyedSigner) GetBlockTimestamp(ctx context.Context) (uint64, error) {
+	if s.rpcClient == nil {
+		return 0, fmt.Errorf("RPC client not configured")
+	}
+
+	header, err := s.rpcClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block: %w", err)
+	}
+	s.codes.ObserveBlock(header.Number.Uint64())
+	return header.Time, nil
+}
+
+// signTx signs tx with the key behind address for the connected chain
+func (s *KeyedSigner) signTx(ctx context.Context, address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+	txSigner := types.LatestSignerForChainID(s.chainID)
+
+	signature, err := s.sign(ctx, address, txSigner.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx, err := tx.WithSignature(txSigner, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return signedTx, nil
+}
+
+// acquireFundedKey acquires the key for the next transaction, skipping keys below MinKeyBalance.
+// A balance that can't be read doesn't hold up the send.
+func (s *KeyedSigner) acquireFundedKey(ctx context.Context) (*signerKey, error) {
+	for {
+		k := s.acquireKey()
+		if k == nil {
+			return nil, fmt.Errorf("%w: every key holds less than %s wei", x402evm.ErrSignerLowGas, s.minBalance)
+		}
+		if s.minBalance == nil {
+			return k, nil
+		}
+
+		balance, err := s.balanceAt(ctx, k.address)
+		if err != nil || balance.Cmp(s.minBalance) >= 0 {
+			return k, nil
+		}
+		s.skipLowKey(k)
+	}
+}
+
+// nativeBalance reads the native token balance of address
+func (s *KeyedSigner) nativeBalance(ctx context.Context, address common.Address) (*big.Int, error) {
+	return s.rpcClient.BalanceAt(ctx, address, nil)
+}
+
+// acquireKey selects the key for the next transaction and marks it in use. Keys found low on
+// gas are skipped; it returns nil when every key is.
+func (s *KeyedSigner) acquireKey() *signerKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var k *signerKey
+	switch s.selection {
+	case KeySelectionLeastRecentlyUsed:
+		for _, candidate := range s.keys {
+			if now.Before(candidate.lowUntil) {
+				continue
+			}
+			if k == nil ||
+				candidate.inFlight < k.inFlight ||
+				(candidate.inFlight == k.inFlight && candidate.lastUsed < k.lastUsed) {
+				k = candidate
+			}
+		}
+	default:
+		for range s.keys {
+			candidate := s.keys[s.seq%uint64(len(s.keys))]
+			if !now.Before(candidate.lowUntil) {
+				k = candidate
+				break
+			}
+			s.seq++
+		}
+	}
+	if k == nil {
+		return nil
+	}
+
+	s.seq++
+	k.lastUsed = s.seq
+	k.inFlight++
+	return k
+}
+
+// releaseKey marks a transaction from k as finished sending
+func (s *KeyedSigner) releaseKey(k *signerKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k.inFlight--
+}
+
+// skipLowKey releases k, which was found below MinKeyBalance, and leaves it out of selection
+// for lowBalanceRecheck
+func (s *KeyedSigner) skipLowKey(k *signerKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k.inFlight--
+	k.lowUntil = s.now().Add(lowBalanceRecheck)
+}
+
+var _ x402evm.FacilitatorEvmSigner = (*KeyedSigner)(nil)
+var _ x402evm.BlockTimeReader = (*KeyedSigner)(nil)
+var _ x402evm.ContractSimulator = (*KeyedSigner)(nil)
+var _ x402evm.NonceResetter = (*KeyedSigner)(nil)
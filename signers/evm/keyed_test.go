@@ -0,0 +1,83 @@
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestNewKeyedSigner(t *testing.T) {
+	sign := func(ctx context.Context, address common.Address, digest []byte) ([]byte, error) {
+		return nil, nil
+	}
+	address := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+
+	if _, err := NewKeyedSigner(nil, sign, nil); err == nil {
+		t.Error("Expected error for no addresses")
+	}
+	if _, err := NewKeyedSigner([]common.Address{address}, nil, nil); err == nil {
+		t.Error("Expected error for a nil sign function")
+	}
+	if _, err := NewKeyedSigner([]common.Address{address, address}, sign, nil); err == nil {
+		t.Error("Expected error for a duplicate address")
+	}
+}
+
+func TestKeyedSignerSendsThroughSignFunc(t *testing.T) {
+	privateKeys := make(map[common.Address]*ecdsa.PrivateKey)
+	var addresses []common.Address
+	for _, hex := range []string{testPrivateKeyHex, testPrivateKeyHex2} {
+		privateKey, err := crypto.HexToECDSA(hex)
+		if err != nil {
+			t.Fatalf("HexToECDSA() failed: %v", err)
+		}
+		address := crypto.PubkeyToAddress(privateKey.PublicKey)
+		privateKeys[address] = privateKey
+		addresses = append(addresses, address)
+	}
+
+	var signedBy []common.Address
+	var signErr error
+	s, err := NewKeyedSigner(addresses, func(ctx context.Context, address common.Address, digest []byte) ([]byte, error) {
+		if signErr != nil {
+			return nil, signErr
+		}
+		signedBy = append(signedBy, address)
+		return crypto.Sign(digest, privateKeys[address])
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewKeyedSigner() failed: %v", err)
+	}
+	s.rpcClient = NewTimeoutClient(&hangingRPCClient{}, &s.rpc)
+	s.chainID = big.NewInt(8453)
+	s.nonces = NewNonceManager(s.rpcClient)
+
+	ctx := context.Background()
+	to := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	for i := 0; i < 3; i++ {
+		if _, err := s.SendTransaction(ctx, to, []byte{0x01}); err != nil {
+			t.Fatalf("SendTransaction() failed: %v", err)
+		}
+	}
+
+	want := []common.Address{addresses[0], addresses[1], addresses[0]}
+	if len(signedBy) != len(want) {
+		t.Fatalf("sign called %d times, want %d", len(signedBy), len(want))
+	}
+	for i := range want {
+		if signedBy[i] != want[i] {
+			t.Errorf("send %d signed by %s, want %s", i, signedBy[i].Hex(), want[i].Hex())
+		}
+	}
+
+	// Sign failures surface from the send
+	signErr = errors.New("signing unavailable")
+	if _, err := s.SendTransaction(ctx, to, []byte{0x01}); !errors.Is(err, signErr) {
+		t.Errorf("Expected the sign error, got %v", err)
+	}
+}
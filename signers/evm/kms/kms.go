@@ -0,0 +1,118 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Client is the subset of the AWS KMS API used by Signer.
+//
+// Keys must be asymmetric SIGN_VERIFY keys with key spec ECC_SECG_P256K1. The aws-sdk-go-v2
+// *kms.Client is adapted with a few lines (see README.md); tests can substitute an in-memory key.
+type Client interface {
+	// GetPublicKey returns the DER-encoded SubjectPublicKeyInfo for keyID (GetPublicKeyOutput.PublicKey)
+	GetPublicKey(ctx context.Context, keyID string) ([]byte, error)
+
+	// Sign signs a 32-byte digest with MessageType DIGEST and SigningAlgorithm ECDSA_SHA_256,
+	// returning the DER-encoded ECDSA signature (SignOutput.Signature)
+	Sign(ctx context.Context, keyID string, digest []byte) ([]byte, error)
+}
+
+var (
+	// oidECPublicKey is the id-ecPublicKey algorithm identifier
+	oidECPublicKey = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	// oidSecp256k1 is the secp256k1 named curve identifier
+	oidSecp256k1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// subjectPublicKeyInfo mirrors the X.509 SubjectPublicKeyInfo structure returned by KMS.
+// crypto/x509 does not support secp256k1, so the structure is decoded directly.
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// ecdsaSignature mirrors the DER ECDSA-Sig-Value structure returned by KMS
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// parsePublicKey decodes a DER SubjectPublicKeyInfo holding a secp256k1 public key
+func parsePublicKey(der []byte) (*ecdsa.PublicKey, error) {
+	var spki subjectPublicKeyInfo
+	rest, err := asn1.Unmarshal(der, &spki)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("failed to parse public key: trailing data")
+	}
+
+	if !spki.Algorithm.Algorithm.Equal(oidECPublicKey) {
+		return nil, fmt.Errorf("unsupported public key algorithm: %v", spki.Algorithm.Algorithm)
+	}
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(spki.Algorithm.Parameters.FullBytes, &curve); err != nil {
+		return nil, fmt.Errorf("failed to parse curve: %w", err)
+	}
+	if !curve.Equal(oidSecp256k1) {
+		return nil, fmt.Errorf("unsupported curve %v: key spec must be ECC_SECG_P256K1", curve)
+	}
+
+	pubKey, err := crypto.UnmarshalPubkey(spki.PublicKey.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return pubKey, nil
+}
+
+// toEthereumSignature converts a DER ECDSA signature over digest into the 65-byte [R || S || V]
+// form used by go-ethereum, with V as the recovery id (0 or 1).
+//
+// KMS does not return a recovery id and may return a high-S signature, which Ethereum rejects
+// (EIP-2). S is normalized to the lower half of the curve order, then each recovery id is tried
+// until the recovered public key matches the key that produced the signature.
+func toEthereumSignature(digest []byte, der []byte, pubKey *ecdsa.PublicKey) ([]byte, error) {
+	var sig ecdsaSignature
+	rest, err := asn1.Unmarshal(der, &sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signature: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("failed to parse signature: trailing data")
+	}
+	if sig.R == nil || sig.S == nil || sig.R.Sign() <= 0 || sig.S.Sign() <= 0 ||
+		sig.R.Cmp(secp256k1N) >= 0 || sig.S.Cmp(secp256k1N) >= 0 {
+		return nil, fmt.Errorf("invalid signature values")
+	}
+
+	s := new(big.Int).Set(sig.S)
+	if s.Cmp(secp256k1HalfN) > 0 {
+		s.Sub(secp256k1N, s)
+	}
+
+	signature := make([]byte, 65)
+	sig.R.FillBytes(signature[0:32])
+	s.FillBytes(signature[32:64])
+
+	expected := crypto.FromECDSAPub(pubKey)
+	for v := byte(0); v < 2; v++ {
+		signature[64] = v
+		recovered, err := crypto.Ecrecover(digest, signature)
+		if err == nil && bytes.Equal(recovered, expected) {
+			return signature, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to recover signer from KMS signature")
+}
@@ -0,0 +1,256 @@
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	x402evm "x402-go/mechanisms/evm"
+)
+
+// Test private keys (deterministic for testing)
+const (
+	testPrivateKeyHex  = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	testPrivateKeyHex2 = "59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d"
+)
+
+// mockKMS emulates KMS with in-memory secp256k1 keys, returning DER encodings like the real service
+type mockKMS struct {
+	keys map[string]*ecdsa.PrivateKey
+	// highS forces signatures into the upper half of the curve order, as KMS may return
+	highS bool
+}
+
+func newMockKMS(t *testing.T, highS bool, keys map[string]string) *mockKMS {
+	t.Helper()
+
+	m := &mockKMS{keys: make(map[string]*ecdsa.PrivateKey), highS: highS}
+	for id, hexKey := range keys {
+		privateKey, err := crypto.HexToECDSA(hexKey)
+		if err != nil {
+			t.Fatalf("HexToECDSA() failed: %v", err)
+		}
+		m.keys[id] = privateKey
+	}
+	return m
+}
+
+func (m *mockKMS) GetPublicKey(_ context.Context, keyID string) ([]byte, error) {
+	privateKey, ok := m.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("NotFoundException: %s", keyID)
+	}
+
+	params, err := asn1.Marshal(oidSecp256k1)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := crypto.FromECDSAPub(&privateKey.PublicKey)
+
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidECPublicKey,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		PublicKey: asn1.BitString{Bytes: publicKey, BitLength: len(publicKey) * 8},
+	})
+}
+
+func (m *mockKMS) Sign(_ context.Context, keyID string, digest []byte) ([]byte, error) {
+	privateKey, ok := m.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("NotFoundException: %s", keyID)
+	}
+
+	signature, err := crypto.Sign(digest, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(big.Int).SetBytes(signature[0:32])
+	s := new(big.Int).SetBytes(signature[32:64])
+	if m.highS {
+		s.Sub(secp256k1N, s)
+	}
+
+	return asn1.Marshal(ecdsaSignature{R: r, S: s})
+}
+
+func TestSignDigestRecoversSigner(t *testing.T) {
+	for _, highS := range []bool{false, true} {
+		t.Run(fmt.Sprintf("highS=%v", highS), func(t *testing.T) {
+			client := newMockKMS(t, highS, map[string]string{"key-1": testPrivateKeyHex})
+			signer, err := newSigner(context.Background(), client, []string{"key-1"})
+			if err != nil {
+				t.Fatalf("newSigner() failed: %v", err)
+			}
+
+			address := signer.GetAddresses()[0]
+			if address != "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266" {
+				t.Fatalf("GetAddresses()[0] = %s, want 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", address)
+			}
+
+			// Sign several digests so both recovery ids are exercised
+			recoveryIDs := map[byte]bool{}
+			for i := 0; i < 16; i++ {
+				digest := crypto.Keccak256([]byte(fmt.Sprintf("x402 digest %d", i)))
+
+				signature, err := signer.SignDigest(context.Background(), address, digest)
+				if err != nil {
+					t.Fatalf("SignDigest() failed: %v", err)
+				}
+				if len(signature) != 65 {
+					t.Fatalf("signature length = %d, want 65", len(signature))
+				}
+
+				s := new(big.Int).SetBytes(signature[32:64])
+				if s.Cmp(secp256k1HalfN) > 0 {
+					t.Errorf("signature %d has high S", i)
+				}
+				recoveryIDs[signature[64]] = true
+
+				pubKey, err := crypto.SigToPub(digest, signature)
+				if err != nil {
+					t.Fatalf("SigToPub() failed: %v", err)
+				}
+				if recovered := crypto.PubkeyToAddress(*pubKey).Hex(); recovered != address {
+					t.Errorf("recovered %s, want %s", recovered, address)
+				}
+			}
+
+			if !recoveryIDs[0] || !recoveryIDs[1] {
+				t.Errorf("expected both recovery ids across samples, got %v", recoveryIDs)
+			}
+		})
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	client := newMockKMS(t, true, map[string]string{"key-1": testPrivateKeyHex})
+	signer, err := newSigner(context.Background(), client, []string{"key-1"})
+	if err != nil {
+		t.Fatalf("newSigner() failed: %v", err)
+	}
+	address := signer.GetAddresses()[0]
+
+	domain := x402evm.TypedDataDomain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(84532),
+		VerifyingContract: "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+	}
+	types := map[string][]x402evm.TypedDataField{
+		"TransferWithAuthorization": {
+			{Name: "from", Type: "address"},
+			{Name: "to", Type: "address"},
+			{Name: "value", Type: "uint256"},
+			{Name: "validAfter", Type: "uint256"},
+			{Name: "validBefore", Type: "uint256"},
+			{Name: "nonce", Type: "bytes32"},
+		},
+	}
+	message := map[string]interface{}{
+		"from":        address,
+		"to":          "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+		"value":       big.NewInt(1000000),
+		"validAfter":  big.NewInt(0),
+		"validBefore": big.NewInt(9999999999),
+		"nonce":       [32]byte{1, 2, 3},
+	}
+
+	signature, err := signer.SignTypedData(context.Background(), address, domain, types, "TransferWithAuthorization", message)
+	if err != nil {
+		t.Fatalf("SignTypedData() failed: %v", err)
+	}
+	if v := signature[64]; v != 27 && v != 28 {
+		t.Errorf("v = %d, want 27 or 28", v)
+	}
+
+	valid, err := signer.VerifyTypedData(context.Background(), address, domain, types, "TransferWithAuthorization", message, signature)
+	if err != nil {
+		t.Fatalf("VerifyTypedData() failed: %v", err)
+	}
+	if !valid {
+		t.Error("VerifyTypedData() = false, want true")
+	}
+}
+
+func TestMultipleKeys(t *testing.T) {
+	client := newMockKMS(t, false, map[string]string{
+		"key-1": testPrivateKeyHex,
+		"key-2": testPrivateKeyHex2,
+	})
+	signer, err := newSigner(context.Background(), client, []string{"key-1", "key-2"})
+	if err != nil {
+		t.Fatalf("newSigner() failed: %v", err)
+	}
+
+	addresses := signer.GetAddresses()
+	want := []string{"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"}
+	if len(addresses) != len(want) {
+		t.Fatalf("GetAddresses() returned %d addresses, want %d", len(addresses), len(want))
+	}
+	for i := range want {
+		if addresses[i] != want[i] {
+			t.Errorf("GetAddresses()[%d] = %s, want %s", i, addresses[i], want[i])
+		}
+	}
+
+	// Each address signs with its own key
+	digest := crypto.Keccak256([]byte("rotation"))
+	for _, address := range addresses {
+		signature, err := signer.SignDigest(context.Background(), address, digest)
+		if err != nil {
+			t.Fatalf("SignDigest(%s) failed: %v", address, err)
+		}
+		pubKey, err := crypto.SigToPub(digest, signature)
+		if err != nil {
+			t.Fatalf("SigToPub() failed: %v", err)
+		}
+		if recovered := crypto.PubkeyToAddress(*pubKey).Hex(); recovered != address {
+			t.Errorf("recovered %s, want %s", recovered, address)
+		}
+	}
+
+	if _, err := signer.SignDigest(context.Background(), "0x0000000000000000000000000000000000000001", digest); err == nil {
+		t.Error("expected error signing for an unknown address")
+	}
+}
+
+func TestNewSignerErrors(t *testing.T) {
+	client := newMockKMS(t, false, map[string]string{"key-1": testPrivateKeyHex})
+
+	if _, err := newSigner(context.Background(), client, nil); err == nil {
+		t.Error("expected error with no key IDs")
+	}
+	if _, err := newSigner(context.Background(), nil, []string{"key-1"}); err == nil {
+		t.Error("expected error with nil client")
+	}
+	if _, err := newSigner(context.Background(), client, []string{"missing"}); err == nil {
+		t.Error("expected error for unknown key ID")
+	}
+}
+
+func TestParsePublicKeyRejectsOtherCurves(t *testing.T) {
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&p256Key.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey() failed: %v", err)
+	}
+
+	if _, err := parsePublicKey(der); err == nil {
+		t.Error("expected error for a P-256 key")
+	}
+}
@@ -0,0 +1,182 @@
+package kms
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	x402evm "x402-go/mechanisms/evm"
+	evmsigners "x402-go/signers/evm"
+)
+
+// DefaultGasLimit is used when gas estimation fails
+const DefaultGasLimit = evmsigners.DefaultGasLimit
+
+// key is a KMS key and the Ethereum account derived from its public key
+type key struct {
+	id        string
+	publicKey *ecdsa.PublicKey
+	address   common.Address
+}
+
+// Signer implements x402evm.FacilitatorEvmSigner with keys held in AWS KMS.
+// Private keys never leave KMS: transactions and typed data are hashed locally and the
+// digest is signed through Client.Sign. When configured with several key IDs, transactions
+// are sent from each key in turn so keys can be rotated or load balanced.
+//
+// Sending, nonces and chain reads come from the embedded evmsigners.KeyedSigner.
+type Signer struct {
+	*evmsigners.KeyedSigner
+	kms  Client
+	keys []key
+}
+
+// NewSigner creates a KMS-backed facilitator signer.
+//
+// Args:
+//
+//	ctx: Context for the KMS and RPC calls made during construction
+//	client: KMS client used for GetPublicKey and Sign
+//	rpcURL: RPC endpoint URL
+//	keyIDs: One or more KMS key IDs or ARNs (ECC_SECG_P256K1)
+//
+// Returns:
+//
+//	*Signer ready for use with the exact EVM facilitator scheme
+//	Error if a public key cannot be loaded or the RPC endpoint is unreachable
+//
+// The RPC connection comes from evmsigners.DefaultClientPool and is released by Close.
+//
+// Example:
+//
+//	signer, err := kms.NewSigner(ctx, awsClient, "https://mainnet.base.org", "alias/x402-primary", "alias/x402-secondary")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	facilitator.Register([]x402.Network{"eip155:8453"}, evmfacilitator.NewExactEvmScheme(signer, nil))
+func NewSigner(ctx context.Context, client Client, rpcURL string, keyIDs ...string) (*Signer, error) {
+	s, err := newSigner(ctx, client, keyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Connect(ctx, rpcURL); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// newSigner loads the public keys for keyIDs without connecting to an RPC endpoint
+func newSigner(ctx context.Context, client Client, keyIDs []string) (*Signer, error) {
+	if client == nil {
+		return nil, fmt.Errorf("KMS client is required")
+	}
+	if len(keyIDs) == 0 {
+		return nil, fmt.Errorf("at least one KMS key ID is required")
+	}
+
+	s := &Signer{
+		kms:  client,
+		keys: make([]key, 0, len(keyIDs)),
+	}
+	addresses := make([]common.Address, 0, len(keyIDs))
+	for _, keyID := range keyIDs {
+		der, err := client.GetPublicKey(ctx, keyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get public key for %s: %w", keyID, err)
+		}
+
+		publicKey, err := parsePublicKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", keyID, err)
+		}
+
+		address := crypto.PubkeyToAddress(*publicKey)
+		s.keys = append(s.keys, key{
+			id:        keyID,
+			publicKey: publicKey,
+			address:   address,
+		})
+		addresses = append(addresses, address)
+	}
+
+	keyed, err := evmsigners.NewKeyedSigner(addresses, s.signDigest, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.KeyedSigner = keyed
+	return s, nil
+}
+
+// SignDigest signs a 32-byte digest with the KMS key for address.
+// Returns a 65-byte [R || S || V] signature with low S and V as the recovery id (0 or 1).
+func (s *Signer) SignDigest(ctx context.Context, address string, digest []byte) ([]byte, error) {
+	return s.signDigest(ctx, common.HexToAddress(address), digest)
+}
+
+// SignTypedData signs EIP-712 typed data with the KMS key for address.
+// Returns a 65-byte signature with V as 27 or 28.
+func (s *Signer) SignTypedData(
+	ctx context.Context,
+	address string,
+	domain x402evm.TypedDataDomain,
+	types map[string][]x402evm.TypedDataField,
+	primaryType string,
+	message map[string]interface{},
+) ([]byte, error) {
+	digest, err := x402evm.HashTypedData(domain, types, primaryType, message)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := s.SignDigest(ctx, address, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Adjust v value for Ethereum (recovery ID 0/1 → 27/28)
+	signature[64] += 27
+	return signature, nil
+}
+
+// signDigest signs digest with the KMS key for address
+func (s *Signer) signDigest(ctx context.Context, address common.Address, digest []byte) ([]byte, error) {
+	if len(digest) != 32 {
+		return nil, fmt.Errorf("invalid digest length: %d", len(digest))
+	}
+
+	k, err := s.keyFor(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sign(ctx, k, digest)
+}
+
+// sign signs digest through KMS and converts the result to an Ethereum signature
+func (s *Signer) sign(ctx context.Context, k key, digest []byte) ([]byte, error) {
+	der, err := s.kms.Sign(ctx, k.id, digest)
+	if err != nil {
+		return nil, fmt.Errorf("KMS sign failed for %s: %w", k.id, err)
+	}
+
+	return toEthereumSignature(digest, der, k.publicKey)
+}
+
+// keyFor returns the configured key for address
+func (s *Signer) keyFor(address common.Address) (key, error) {
+	for _, k := range s.keys {
+		if k.address == address {
+			return k, nil
+		}
+	}
+	return key{}, fmt.Errorf("no KMS key configured for address %s", address.Hex())
+}
+
+var _ x402evm.FacilitatorEvmSigner = (*Signer)(nil)
+var _ x402evm.BlockTimeReader = (*Signer)(nil)
+var _ x402evm.ContractSimulator = (*Signer)(nil)
+var _ x402evm.NonceResetter = (*Signer)(nil)
@@ -0,0 +1,149 @@
+package evm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	x402evm "x402-go/mechanisms/evm"
+)
+
+// KeySelection controls which key MultiKeySigner sends the next transaction from
+type KeySelection int
+
+const (
+	// KeySelectionRoundRobin sends from each key in turn
+	KeySelectionRoundRobin KeySelection = iota
+
+	// KeySelectionLeastRecentlyUsed sends from the key that has been idle longest,
+	// preferring keys with no transaction currently being sent
+	KeySelectionLeastRecentlyUsed
+)
+
+// MultiKeySignerConfig holds configuration for MultiKeySigner and KeyedSigner
+type MultiKeySignerConfig struct {
+	// Selection picks the sending key for each transaction (defaults to round-robin)
+	Selection KeySelection
+
+	// Gas bounds the gas price and limit of every transaction
+	Gas GasConfig
+
+	// Receipt controls how WaitForTransactionReceipt polls, how many confirmations it
+	// waits for and when it gives up
+	Receipt ReceiptConfig
+
+	// RPC bounds each RPC call the signer makes
+	RPC RPCConfig
+
+	// CodeCache caches GetCode results per block (disabled by default)
+	CodeCache CodeCacheConfig
+
+	// MinKeyBalance leaves keys holding less native token (in wei) out of selection. Each send
+	// reads the selected key's balance first, and a key found below it is skipped for a
+	// minute before being checked again. When every key is below it, sends fail with
+	// x402evm.ErrSignerLowGas (nil sends from every key regardless of balance).
+	MinKeyBalance *big.Int
+
+	// ClientPool shares the RPC connection with other signers using the same URL
+	// (defaults to DefaultClientPool)
+	ClientPool *ClientPool
+}
+
+// MultiKeySigner implements x402evm.FacilitatorEvmSigner over several private keys.
+// Each WriteContract/SendTransaction call is sent from one of the keys, so settlements
+// run in parallel across sender accounts and a nonce gap only stalls the key it belongs to.
+type MultiKeySigner struct {
+	*KeyedSigner
+	privateKeys map[common.Address]*ecdsa.PrivateKey
+}
+
+// NewMultiKeySigner creates a facilitator signer that spreads transactions across privateKeys.
+//
+// Args:
+//
+//	ctx: Context for the RPC calls made during construction
+//	rpcURL: RPC endpoint URL
+//	privateKeys: Hex-encoded private keys (with or without "0x" prefix)
+//	config: Optional configuration (nil uses round-robin selection)
+//
+// Returns:
+//
+//	*MultiKeySigner ready for use with the exact EVM facilitator scheme
+//	Error if a key is invalid or the RPC endpoint is unreachable
+//
+// Every key sends through one pooled connection, released by Close.
+//
+// Example:
+//
+//	signer, err := evmsigners.NewMultiKeySigner(ctx, "https://mainnet.base.org", []string{key1, key2, key3}, nil)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	facilitator.Register([]x402.Network{"eip155:8453"}, evmfacilitator.NewExactEvmScheme(signer, nil))
+func NewMultiKeySigner(ctx context.Context, rpcURL string, privateKeys []string, config *MultiKeySignerConfig) (*MultiKeySigner, error) {
+	s, err := newMultiKeySigner(privateKeys, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Connect(ctx, rpcURL); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// newMultiKeySigner parses privateKeys without connecting to an RPC endpoint
+func newMultiKeySigner(privateKeys []string, config *MultiKeySignerConfig) (*MultiKeySigner, error) {
+	if len(privateKeys) == 0 {
+		return nil, fmt.Errorf("at least one private key is required")
+	}
+
+	s := &MultiKeySigner{
+		privateKeys: make(map[common.Address]*ecdsa.PrivateKey, len(privateKeys)),
+	}
+	addresses := make([]common.Address, 0, len(privateKeys))
+	for i, privateKeyHex := range privateKeys {
+		privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(privateKeyHex, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key at index %d: %w", i, err)
+		}
+
+		address := crypto.PubkeyToAddress(privateKey.PublicKey)
+		if s.privateKeys[address] != nil {
+			return nil, fmt.Errorf("duplicate private key for %s", address.Hex())
+		}
+		s.privateKeys[address] = privateKey
+		addresses = append(addresses, address)
+	}
+
+	keyed, err := NewKeyedSigner(addresses, s.signDigest, config)
+	if err != nil {
+		return nil, err
+	}
+	s.KeyedSigner = keyed
+	return s, nil
+}
+
+// signDigest signs digest with the private key for address
+func (s *MultiKeySigner) signDigest(ctx context.Context, address common.Address, digest []byte) ([]byte, error) {
+	privateKey, ok := s.privateKeys[address]
+	if !ok {
+		return nil, fmt.Errorf("no private key configured for address %s", address.Hex())
+	}
+
+	signature, err := crypto.Sign(digest, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return signature, nil
+}
+
+var _ x402evm.FacilitatorEvmSigner = (*MultiKeySigner)(nil)
+var _ x402evm.BlockTimeReader = (*MultiKeySigner)(nil)
+var _ x402evm.ContractSimulator = (*MultiKeySigner)(nil)
+var _ x402evm.NonceResetter = (*MultiKeySigner)(nil)
@@ -0,0 +1,196 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	x402evm "x402-go/mechanisms/evm"
+)
+
+const testPrivateKeyHex2 = "59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d"
+
+func TestNewMultiKeySigner(t *testing.T) {
+	signer, err := newMultiKeySigner([]string{"0x" + testPrivateKeyHex, testPrivateKeyHex2}, nil)
+	if err != nil {
+		t.Fatalf("newMultiKeySigner() failed: %v", err)
+	}
+
+	addresses := signer.GetAddresses()
+	want := []string{"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"}
+	if len(addresses) != len(want) {
+		t.Fatalf("GetAddresses() returned %d addresses, want %d", len(addresses), len(want))
+	}
+	for i := range want {
+		if addresses[i] != want[i] {
+			t.Errorf("GetAddresses()[%d] = %s, want %s", i, addresses[i], want[i])
+		}
+	}
+
+	if _, err := newMultiKeySigner(nil, nil); err == nil {
+		t.Error("Expected error for no keys")
+	}
+	if _, err := newMultiKeySigner([]string{"invalid"}, nil); err == nil {
+		t.Error("Expected error for invalid key")
+	}
+	if _, err := newMultiKeySigner([]string{testPrivateKeyHex, "0x" + testPrivateKeyHex}, nil); err == nil {
+		t.Error("Expected error for duplicate key")
+	}
+}
+
+func TestMultiKeySignerRoundRobin(t *testing.T) {
+	signer, err := newMultiKeySigner([]string{testPrivateKeyHex, testPrivateKeyHex2}, nil)
+	if err != nil {
+		t.Fatalf("newMultiKeySigner() failed: %v", err)
+	}
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		k := signer.acquireKey()
+		got = append(got, k.address.Hex())
+		signer.releaseKey(k)
+	}
+
+	addresses := signer.GetAddresses()
+	want := []string{addresses[0], addresses[1], addresses[0], addresses[1]}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("key %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMultiKeySignerLeastRecentlyUsed(t *testing.T) {
+	signer, err := newMultiKeySigner(
+		[]string{testPrivateKeyHex, testPrivateKeyHex2},
+		&MultiKeySignerConfig{Selection: KeySelectionLeastRecentlyUsed},
+	)
+	if err != nil {
+		t.Fatalf("newMultiKeySigner() failed: %v", err)
+	}
+
+	// First key is still sending, so the second one is picked
+	first := signer.acquireKey()
+	second := signer.acquireKey()
+	if first == second {
+		t.Fatal("Expected a different key while the first is busy")
+	}
+
+	// Once both are idle, the one used longest ago goes next
+	signer.releaseKey(second)
+	signer.releaseKey(first)
+	if next := signer.acquireKey(); next != first {
+		t.Errorf("Expected least recently used key %s, got %s", first.address.Hex(), next.address.Hex())
+	}
+
+	// A busy key is skipped even if it was used longer ago
+	signer.releaseKey(signer.acquireKey()) // second, now the most recently used
+	if next := signer.acquireKey(); next != second {
+		t.Errorf("Expected idle key %s, got %s", second.address.Hex(), next.address.Hex())
+	}
+}
+
+func TestMultiKeySignerVerifyTypedData(t *testing.T) {
+	signer, err := newMultiKeySigner([]string{testPrivateKeyHex}, nil)
+	if err != nil {
+		t.Fatalf("newMultiKeySigner() failed: %v", err)
+	}
+
+	// Sign with a key the multi-key signer doesn't hold
+	payer, err := NewClientSignerFromPrivateKey(testPrivateKeyHex2)
+	if err != nil {
+		t.Fatalf("NewClientSignerFromPrivateKey() failed: %v", err)
+	}
+
+	domain := x402evm.TypedDataDomain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(84532),
+		VerifyingContract: "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+	}
+	types := map[string][]x402evm.TypedDataField{
+		"TransferWithAuthorization": {
+			{Name: "from", Type: "address"},
+			{Name: "to", Type: "address"},
+			{Name: "value", Type: "uint256"},
+			{Name: "validAfter", Type: "uint256"},
+			{Name: "validBefore", Type: "uint256"},
+			{Name: "nonce", Type: "bytes32"},
+		},
+	}
+	message := map[string]interface{}{
+		"from":        payer.Address(),
+		"to":          "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+		"value":       big.NewInt(1000000),
+		"validAfter":  big.NewInt(0),
+		"validBefore": big.NewInt(9999999999),
+		"nonce":       [32]byte{1, 2, 3},
+	}
+
+	signature, err := payer.SignTypedData(context.Background(), domain, types, "TransferWithAuthorization", message)
+	if err != nil {
+		t.Fatalf("SignTypedData() failed: %v", err)
+	}
+
+	valid, err := signer.VerifyTypedData(context.Background(), payer.Address(), domain, types, "TransferWithAuthorization", message, signature)
+	if err != nil {
+		t.Fatalf("VerifyTypedData() failed: %v", err)
+	}
+	if !valid {
+		t.Error("VerifyTypedData() = false, want true")
+	}
+}
+
+func TestMultiKeySignerSkipsLowKeys(t *testing.T) {
+	ctx := context.Background()
+	signer, err := newMultiKeySigner([]string{testPrivateKeyHex, testPrivateKeyHex2}, &MultiKeySignerConfig{MinKeyBalance: big.NewInt(100)})
+	if err != nil {
+		t.Fatalf("newMultiKeySigner() failed: %v", err)
+	}
+	addresses := signer.GetAddresses()
+
+	now := time.Unix(1_700_000_000, 0)
+	signer.now = func() time.Time { return now }
+	balances := map[string]int64{addresses[0]: 10, addresses[1]: 500}
+	reads := 0
+	signer.balanceAt = func(ctx context.Context, address common.Address) (*big.Int, error) {
+		reads++
+		return big.NewInt(balances[address.Hex()]), nil
+	}
+
+	for i := 0; i < 3; i++ {
+		k, err := signer.acquireFundedKey(ctx)
+		if err != nil {
+			t.Fatalf("acquireFundedKey() failed: %v", err)
+		}
+		if k.address.Hex() != addresses[1] {
+			t.Errorf("send %d from %s, want the funded key %s", i, k.address.Hex(), addresses[1])
+		}
+		signer.releaseKey(k)
+	}
+	if reads != 4 {
+		t.Errorf("balance reads = %d, want 4 (the low key is read once, then skipped)", reads)
+	}
+
+	// Once every key is low, sends are refused
+	balances[addresses[1]] = 0
+	now = now.Add(lowBalanceRecheck)
+	if _, err := signer.acquireFundedKey(ctx); !errors.Is(err, x402evm.ErrSignerLowGas) {
+		t.Fatalf("Expected ErrSignerLowGas, got %v", err)
+	}
+
+	// A topped-up key is used again after the recheck interval
+	balances[addresses[0]] = 1000
+	now = now.Add(lowBalanceRecheck)
+	k, err := signer.acquireFundedKey(ctx)
+	if err != nil {
+		t.Fatalf("acquireFundedKey() failed: %v", err)
+	}
+	if k.address.Hex() != addresses[0] {
+		t.Errorf("send from %s, want the topped-up key %s", k.address.Hex(), addresses[0])
+	}
+}
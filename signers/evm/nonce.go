@@ -0,0 +1,101 @@
+package evm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PendingNonceReader reads an account's next nonce from the chain, including pending transactions.
+// *ethclient.Client implements it.
+type PendingNonceReader interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager allocates transaction nonces locally so concurrent transactions from the
+// same account never reuse a nonce.
+//
+// The first allocation for an account reads the pending nonce from the chain; later
+// allocations increment a local counter under a mutex. When a transaction fails to send,
+// the account's counter is dropped and the next allocation resyncs from the chain,
+// which also fills any gap the failed transaction left behind.
+type NonceManager struct {
+	mu     sync.Mutex
+	reader PendingNonceReader
+	nonces map[common.Address]uint64 // next nonce to allocate per account
+}
+
+// NewNonceManager creates a nonce manager that syncs from reader
+func NewNonceManager(reader PendingNonceReader) *NonceManager {
+	return &NonceManager{
+		reader: reader,
+		nonces: make(map[common.Address]uint64),
+	}
+}
+
+// Next allocates the next nonce for account
+func (m *NonceManager) Next(ctx context.Context, account common.Address) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nonce, ok := m.nonces[account]
+	if !ok {
+		pending, err := m.reader.PendingNonceAt(ctx, account)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get nonce: %w", err)
+		}
+		nonce = pending
+	}
+
+	m.nonces[account] = nonce + 1
+	return nonce, nil
+}
+
+// Reset drops the cached nonce for account so the next allocation resyncs from the chain.
+// Call it when a transaction from account is known to have been dropped.
+func (m *NonceManager) Reset(account common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.nonces, account)
+}
+
+// ResetAll drops the cached nonces for every account
+func (m *NonceManager) ResetAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nonces = make(map[common.Address]uint64)
+}
+
+// Send allocates a nonce for account and passes it to send, which should sign and
+// broadcast the transaction. If send fails the account is resynced from the chain, and
+// a "nonce too low" rejection is retried once with the fresh nonce.
+func (m *NonceManager) Send(ctx context.Context, account common.Address, send func(nonce uint64) error) error {
+	for attempt := 0; ; attempt++ {
+		nonce, err := m.Next(ctx, account)
+		if err != nil {
+			return err
+		}
+
+		err = send(nonce)
+		if err == nil {
+			return nil
+		}
+
+		m.Reset(account)
+		if attempt > 0 || !isNonceTooLow(err) {
+			return err
+		}
+	}
+}
+
+// isNonceTooLow reports whether err is a node rejecting a nonce that is already used,
+// either by a mined transaction or by a different pending one
+func isNonceTooLow(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "replacement transaction underpriced")
+}
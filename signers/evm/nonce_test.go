@@ -0,0 +1,121 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeNonceReader returns a fixed pending nonce and counts chain reads
+type fakeNonceReader struct {
+	mu      sync.Mutex
+	pending uint64
+	reads   int
+}
+
+func (r *fakeNonceReader) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads++
+	return r.pending, nil
+}
+
+func TestNonceManagerConcurrentAllocation(t *testing.T) {
+	reader := &fakeNonceReader{pending: 7}
+	manager := NewNonceManager(reader)
+	account := common.HexToAddress("0x1")
+
+	const workers = 50
+	nonces := make(chan uint64, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := manager.Next(context.Background(), account)
+			if err != nil {
+				t.Errorf("Next failed: %v", err)
+				return
+			}
+			nonces <- nonce
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+
+	seen := make(map[uint64]bool)
+	for nonce := range nonces {
+		if seen[nonce] {
+			t.Fatalf("Nonce %d allocated twice", nonce)
+		}
+		if nonce < 7 || nonce >= 7+workers {
+			t.Errorf("Nonce %d out of range", nonce)
+		}
+		seen[nonce] = true
+	}
+	if reader.reads != 1 {
+		t.Errorf("Expected a single chain read, got %d", reader.reads)
+	}
+
+	// Accounts are tracked separately
+	other, _ := manager.Next(context.Background(), common.HexToAddress("0x2"))
+	if other != 7 {
+		t.Errorf("Expected nonce 7 for a new account, got %d", other)
+	}
+}
+
+func TestNonceManagerReset(t *testing.T) {
+	reader := &fakeNonceReader{pending: 3}
+	manager := NewNonceManager(reader)
+	account := common.HexToAddress("0x1")
+	ctx := context.Background()
+
+	manager.Next(ctx, account)
+	manager.Next(ctx, account)
+
+	// The transaction using nonce 4 was dropped; the chain still reports 4 as next
+	reader.pending = 4
+	manager.Reset(account)
+	if nonce, _ := manager.Next(ctx, account); nonce != 4 {
+		t.Errorf("Expected resynced nonce 4, got %d", nonce)
+	}
+}
+
+func TestNonceManagerSend(t *testing.T) {
+	reader := &fakeNonceReader{pending: 10}
+	manager := NewNonceManager(reader)
+	account := common.HexToAddress("0x1")
+	ctx := context.Background()
+
+	// A failed send resyncs the account
+	sendErr := errors.New("connection refused")
+	if err := manager.Send(ctx, account, func(nonce uint64) error { return sendErr }); !errors.Is(err, sendErr) {
+		t.Fatalf("Expected send error, got %v", err)
+	}
+	if nonce, _ := manager.Next(ctx, account); nonce != 10 {
+		t.Errorf("Expected nonce 10 after failed send, got %d", nonce)
+	}
+
+	// "nonce too low" is retried once with a fresh nonce from the chain
+	reader.pending = 20
+	manager.Reset(account)
+	manager.Next(ctx, account) // local counter now at 21
+	reader.pending = 25
+	var attempts []uint64
+	err := manager.Send(ctx, account, func(nonce uint64) error {
+		attempts = append(attempts, nonce)
+		if len(attempts) == 1 {
+			return errors.New("failed to send transaction: nonce too low")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(attempts) != 2 || attempts[0] != 21 || attempts[1] != 25 {
+		t.Errorf("Expected attempts [21 25], got %v", attempts)
+	}
+}
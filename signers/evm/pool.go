@@ -0,0 +1,79 @@
+package evm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultClientPool is the pool signers connect through unless given another one
+var DefaultClientPool = NewClientPool()
+
+// ClientPool shares one ethclient.Client between every signer connected to the same RPC URL,
+// so a facilitator running many signers against an endpoint keeps a single connection to it.
+// Connections are reference counted: each Acquire must be matched by a Release, and a
+// connection is closed once its last user releases it.
+type ClientPool struct {
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+	dial    func(ctx context.Context, rpcURL string) (*ethclient.Client, error) // overridable in tests
+}
+
+// pooledClient is a shared client and the number of signers using it
+type pooledClient struct {
+	client *ethclient.Client
+	refs   int
+}
+
+// NewClientPool creates an empty ClientPool
+func NewClientPool() *ClientPool {
+	return &ClientPool{
+		clients: make(map[string]*pooledClient),
+		dial:    ethclient.DialContext,
+	}
+}
+
+// Acquire returns the client connected to rpcURL, dialing it if no signer holds one yet.
+// The caller must call Release(rpcURL) once it stops using the client.
+func (p *ClientPool) Acquire(ctx context.Context, rpcURL string) (*ethclient.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pooled, ok := p.clients[rpcURL]; ok {
+		pooled.refs++
+		return pooled.client, nil
+	}
+
+	client, err := p.dial(ctx, rpcURL)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[rpcURL] = &pooledClient{client: client, refs: 1}
+	return client, nil
+}
+
+// Release drops a reference taken by Acquire, closing the connection to rpcURL when it was
+// the last one. Releasing a URL with no references is a no-op.
+func (p *ClientPool) Release(rpcURL string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pooled, ok := p.clients[rpcURL]
+	if !ok {
+		return
+	}
+	pooled.refs--
+	if pooled.refs > 0 {
+		return
+	}
+	delete(p.clients, rpcURL)
+	pooled.client.Close()
+}
+
+// Len returns the number of open connections in the pool
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
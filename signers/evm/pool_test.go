@@ -0,0 +1,84 @@
+package evm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func TestClientPool(t *testing.T) {
+	ctx := context.Background()
+	pool := NewClientPool()
+
+	dials := 0
+	pool.dial = func(ctx context.Context, rpcURL string) (*ethclient.Client, error) {
+		dials++
+		return ethclient.DialContext(ctx, rpcURL)
+	}
+
+	const base = "http://127.0.0.1:8545"
+	const mainnet = "http://127.0.0.1:8546"
+
+	first, err := pool.Acquire(ctx, base)
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	second, err := pool.Acquire(ctx, base)
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	if first != second {
+		t.Error("Expected signers on the same URL to share a client")
+	}
+	other, err := pool.Acquire(ctx, mainnet)
+	if err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	if other == first {
+		t.Error("Expected a separate client for another URL")
+	}
+	if dials != 2 || pool.Len() != 2 {
+		t.Fatalf("dials = %d, open = %d, want 2 and 2", dials, pool.Len())
+	}
+
+	pool.Release(base)
+	if pool.Len() != 2 {
+		t.Errorf("Expected the connection to stay open while referenced, open = %d", pool.Len())
+	}
+	pool.Release(base)
+	if pool.Len() != 1 {
+		t.Errorf("Expected the connection closed after its last release, open = %d", pool.Len())
+	}
+	pool.Release(base) // no references left
+	pool.Release(mainnet)
+	if pool.Len() != 0 {
+		t.Errorf("open = %d, want 0", pool.Len())
+	}
+
+	// A released URL is dialed again on its next use
+	if _, err := pool.Acquire(ctx, base); err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	if dials != 3 {
+		t.Errorf("dials = %d, want 3", dials)
+	}
+}
+
+func TestMultiKeySignerClose(t *testing.T) {
+	pool := NewClientPool()
+	s, err := newMultiKeySigner([]string{testPrivateKeyHex}, &MultiKeySignerConfig{ClientPool: pool})
+	if err != nil {
+		t.Fatalf("newMultiKeySigner() failed: %v", err)
+	}
+	if _, err := pool.Acquire(context.Background(), "http://127.0.0.1:8545"); err != nil {
+		t.Fatalf("Acquire() failed: %v", err)
+	}
+	s.rpcURL = "http://127.0.0.1:8545"
+
+	s.Close()
+	s.Close()
+	if pool.Len() != 0 {
+		t.Errorf("Expected Close to release the connection once, open = %d", pool.Len())
+	}
+}
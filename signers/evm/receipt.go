@@ -0,0 +1,120 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	x402evm "x402-go/mechanisms/evm"
+)
+
+const (
+	// DefaultReceiptPollInterval is how often a pending transaction's receipt is polled
+	DefaultReceiptPollInterval = time.Second
+
+	// DefaultReceiptTimeout bounds how long WaitForTransactionReceipt waits for confirmation
+	DefaultReceiptTimeout = 2 * time.Minute
+)
+
+// ReceiptConfig controls how WaitForTransactionReceipt waits for a transaction.
+// The zero value polls every second, returns on the first confirmation and gives up after
+// two minutes.
+type ReceiptConfig struct {
+	// PollInterval is how often the receipt and chain head are polled (defaults to 1 second)
+	PollInterval time.Duration
+
+	// Confirmations is how many blocks, counting the one the transaction is in, must be
+	// on the chain before the receipt is returned (defaults to 1). Raise it on chains
+	// prone to reorgs.
+	Confirmations uint64
+
+	// Timeout bounds the wait independently of the caller's context (defaults to 2 minutes).
+	// When it passes, the error wraps x402evm.ErrReceiptTimeout.
+	Timeout time.Duration
+}
+
+// ReceiptReader reads transaction receipts and the chain head. *ethclient.Client implements it.
+type ReceiptReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// Wait polls reader until txHash is mined with the configured number of confirmations
+func (c ReceiptConfig) Wait(ctx context.Context, reader ReceiptReader, txHash string) (*x402evm.TransactionReceipt, error) {
+	pollInterval := c.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultReceiptPollInterval
+	}
+	confirmations := c.Confirmations
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReceiptTimeout
+	}
+
+	hash := common.HexToHash(txHash)
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline.C:
+			return nil, fmt.Errorf("%w: %s after %s", x402evm.ErrReceiptTimeout, txHash, timeout)
+		case <-ticker.C:
+			receipt, err := reader.TransactionReceipt(ctx, hash)
+			if err != nil {
+				if errors.Is(err, ethereum.NotFound) {
+					continue // Not mined yet
+				}
+				return nil, err
+			}
+
+			blockNumber := receipt.BlockNumber.Uint64()
+			if confirmations > 1 {
+				head, err := reader.BlockNumber(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get block number: %w", err)
+				}
+				if head < blockNumber || head-blockNumber+1 < confirmations {
+					continue // Not confirmed yet; a reorg may also move the receipt
+				}
+			}
+
+			return &x402evm.TransactionReceipt{
+				Status:      receipt.Status,
+				BlockNumber: blockNumber,
+				TxHash:      receipt.TxHash.Hex(),
+				Logs:        receiptLogs(receipt.Logs),
+			}, nil
+		}
+	}
+}
+
+// receiptLogs converts go-ethereum logs to receipt logs
+func receiptLogs(logs []*types.Log) []x402evm.ReceiptLog {
+	converted := make([]x402evm.ReceiptLog, 0, len(logs))
+	for _, log := range logs {
+		topics := make([]string, len(log.Topics))
+		for i, topic := range log.Topics {
+			topics[i] = topic.Hex()
+		}
+		converted = append(converted, x402evm.ReceiptLog{
+			Address: log.Address.Hex(),
+			Topics:  topics,
+			Data:    log.Data,
+			Index:   log.Index,
+		})
+	}
+	return converted
+}
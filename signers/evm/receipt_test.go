@@ -0,0 +1,88 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	x402evm "x402-go/mechanisms/evm"
+)
+
+// fakeReceiptReader mines a transaction at minedAt and advances the head by one block per poll
+type fakeReceiptReader struct {
+	mu      sync.Mutex
+	head    uint64
+	minedAt uint64 // 0 means never mined
+}
+
+func (r *fakeReceiptReader) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.head++
+	if r.minedAt == 0 || r.head < r.minedAt {
+		return nil, ethereum.NotFound
+	}
+	return &types.Receipt{
+		Status:      1,
+		BlockNumber: new(big.Int).SetUint64(r.minedAt),
+		TxHash:      txHash,
+		Logs:        []*types.Log{{Address: common.HexToAddress("0x01"), Topics: []common.Hash{common.HexToHash("0x02")}, Data: []byte{3}, Index: 4}},
+	}, nil
+}
+
+func (r *fakeReceiptReader) BlockNumber(ctx context.Context) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.head, nil
+}
+
+func TestReceiptConfigWait(t *testing.T) {
+	txHash := "0x0000000000000000000000000000000000000000000000000000000000000001"
+
+	t.Run("waits for confirmations", func(t *testing.T) {
+		reader := &fakeReceiptReader{minedAt: 2}
+		config := ReceiptConfig{PollInterval: time.Millisecond, Confirmations: 3}
+
+		receipt, err := config.Wait(context.Background(), reader, txHash)
+		if err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+		if receipt.BlockNumber != 2 || receipt.Status != x402evm.TxStatusSuccess {
+			t.Errorf("Unexpected receipt: %+v", receipt)
+		}
+		if reader.head < 4 {
+			t.Errorf("Returned at head %d, before 3 confirmations", reader.head)
+		}
+		if len(receipt.Logs) != 1 || receipt.Logs[0].Address != common.HexToAddress("0x01").Hex() ||
+			receipt.Logs[0].Topics[0] != common.HexToHash("0x02").Hex() || receipt.Logs[0].Index != 4 {
+			t.Errorf("Unexpected logs: %+v", receipt.Logs)
+		}
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		reader := &fakeReceiptReader{}
+		config := ReceiptConfig{PollInterval: time.Millisecond, Timeout: 20 * time.Millisecond}
+
+		_, err := config.Wait(context.Background(), reader, txHash)
+		if !errors.Is(err, x402evm.ErrReceiptTimeout) {
+			t.Fatalf("Expected ErrReceiptTimeout, got %v", err)
+		}
+	})
+
+	t.Run("context cancellation is not a timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := ReceiptConfig{PollInterval: time.Millisecond}.Wait(ctx, &fakeReceiptReader{}, txHash)
+		if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, x402evm.ErrReceiptTimeout) {
+			t.Fatalf("Expected the context error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,337 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	x402evm "x402-go/mechanisms/evm"
+)
+
+const (
+	// DefaultSignPath is the endpoint (relative to Config.URL) that signs EIP-712 typed data
+	DefaultSignPath = "/sign"
+
+	// AddressPath is the endpoint (relative to Config.URL) that returns the signer's address
+	AddressPath = "/address"
+
+	// DefaultMaxRetries is the number of retries for 5xx responses and transport errors
+	DefaultMaxRetries = 2
+
+	// DefaultRetryDelay is the initial delay between retries (doubled after each attempt)
+	DefaultRetryDelay = 200 * time.Millisecond
+)
+
+// Config configures the remote signer
+type Config struct {
+	// URL is the base URL of the signing service
+	URL string
+
+	// SignPath overrides the typed-data signing endpoint (optional, defaults to DefaultSignPath)
+	SignPath string
+
+	// Headers are added to every request, e.g. {"Authorization": "Bearer ..."} (optional)
+	Headers map[string]string
+
+	// HTTPClient is the HTTP client to use (optional)
+	HTTPClient *http.Client
+
+	// Timeout for requests when HTTPClient is not set (optional, defaults to 30s).
+	// Per-call deadlines are taken from the context passed to each method.
+	Timeout time.Duration
+
+	// MaxRetries is the number of retries for 5xx responses (optional, defaults to DefaultMaxRetries).
+	// Set to a negative value to disable retries.
+	MaxRetries int
+
+	// RetryDelay is the initial delay between retries (optional, defaults to DefaultRetryDelay)
+	RetryDelay time.Duration
+}
+
+// Signer implements x402evm.ClientEvmSigner by delegating EIP-712 signing to a remote service.
+// No private key is held locally. The service must expose:
+//
+//	GET  {URL}/address  -> {"address": "0x..."}
+//	POST {URL}/sign     <- {"domain": {...}, "types": {...}, "primaryType": "...", "message": {...}}
+//	                    -> {"signature": "0x..."}
+//
+// Message values are JSON-encoded as strings: integers in decimal, byte arrays as 0x-prefixed hex.
+type Signer struct {
+	url        string
+	signPath   string
+	headers    map[string]string
+	httpClient *http.Client
+	maxRetries int
+	retryDelay time.Duration
+	address    string
+}
+
+// signRequest is the body POSTed to the sign endpoint
+type signRequest struct {
+	Domain      x402evm.TypedDataDomain             `json:"domain"`
+	Types       map[string][]x402evm.TypedDataField `json:"types"`
+	PrimaryType string                              `json:"primaryType"`
+	Message     map[string]interface{}              `json:"message"`
+}
+
+type signResponse struct {
+	Signature string `json:"signature"`
+}
+
+type addressResponse struct {
+	Address string `json:"address"`
+}
+
+// NewSigner creates a remote signer, fetching and caching its address from the service.
+//
+// Args:
+//
+//	ctx: Context for the address request
+//	config: Remote signer configuration (URL is required)
+//
+// Returns:
+//
+//	*Signer ready for use with the exact EVM client scheme
+//	Error if the address cannot be fetched
+//
+// Example:
+//
+//	signer, err := remote.NewSigner(ctx, &remote.Config{
+//	    URL:     "https://signer.internal",
+//	    Headers: map[string]string{"Authorization": "Bearer " + token},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	client := x402.Newx402Client().
+//	    Register("eip155:*", evmclient.NewExactEvmScheme(signer))
+func NewSigner(ctx context.Context, config *Config) (*Signer, error) {
+	if config == nil || config.URL == "" {
+		return nil, fmt.Errorf("remote signer URL is required")
+	}
+
+	signPath := config.SignPath
+	if signPath == "" {
+		signPath = DefaultSignPath
+	}
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		timeout := config.Timeout
+		if timeout == 0 {
+			timeout = 30 * time.Second
+		}
+		httpClient = &http.Client{
+			Timeout: timeout,
+		}
+	}
+
+	maxRetries := config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	} else if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	retryDelay := config.RetryDelay
+	if retryDelay == 0 {
+		retryDelay = DefaultRetryDelay
+	}
+
+	s := &Signer{
+		url:        strings.TrimSuffix(config.URL, "/"),
+		signPath:   signPath,
+		headers:    config.Headers,
+		httpClient: httpClient,
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+	}
+
+	var resp addressResponse
+	if err := s.do(ctx, http.MethodGet, AddressPath, nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to fetch signer address: %w", err)
+	}
+	if !x402evm.IsValidAddress(resp.Address) {
+		return nil, fmt.Errorf("remote signer returned invalid address: %q", resp.Address)
+	}
+	s.address = resp.Address
+
+	return s, nil
+}
+
+// Address returns the signer's Ethereum address (fetched once at construction)
+func (s *Signer) Address() string {
+	return s.address
+}
+
+// SignTypedData signs EIP-712 typed data through the remote service.
+// Returns the 65-byte signature with v as 27 or 28. Signatures that don't recover to
+// Address() from the locally computed EIP-712 hash are rejected.
+func (s *Signer) SignTypedData(
+	ctx context.Context,
+	domain x402evm.TypedDataDomain,
+	types map[string][]x402evm.TypedDataField,
+	primaryType string,
+	message map[string]interface{},
+) ([]byte, error) {
+	digest, err := x402evm.HashTypedData(domain, types, primaryType, message)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(signRequest{
+		Domain:      domain,
+		Types:       types,
+		PrimaryType: primaryType,
+		Message:     encodeMessage(message),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sign request: %w", err)
+	}
+
+	var resp signResponse
+	if err := s.do(ctx, http.MethodPost, s.signPath, body, &resp); err != nil {
+		return nil, fmt.Errorf("remote sign failed: %w", err)
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(resp.Signature, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature from remote signer: %w", err)
+	}
+	if len(signature) != 65 {
+		return nil, fmt.Errorf("invalid signature length from remote signer: %d", len(signature))
+	}
+
+	// Normalize recovery id 0/1 → 27/28
+	if signature[64] < 27 {
+		signature[64] += 27
+	}
+
+	// The service may have signed with another key or over different data
+	valid, err := x402evm.VerifyEOASignature(digest, signature, common.HexToAddress(s.address))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature from remote signer: %w", err)
+	}
+	if !valid {
+		return nil, fmt.Errorf("remote signer signature does not recover to %s", s.address)
+	}
+
+	return signature, nil
+}
+
+// ReadContract is not supported: the remote signer has no RPC access
+func (s *Signer) ReadContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (interface{}, error) {
+	return nil, fmt.Errorf("RPC client not configured")
+}
+
+// WriteContract is not supported: the remote service only signs typed data
+func (s *Signer) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	return "", fmt.Errorf("remote signer does not support transactions")
+}
+
+// WaitForTransactionReceipt is not supported: the remote signer has no RPC access
+func (s *Signer) WaitForTransactionReceipt(ctx context.Context, txHash string) (*x402evm.TransactionReceipt, error) {
+	return nil, fmt.Errorf("RPC client not configured")
+}
+
+// do sends a request to the service, retrying 5xx responses and transport errors with exponential backoff
+func (s *Signer) do(ctx context.Context, method string, path string, body []byte, out interface{}) error {
+	delay := s.retryDelay
+	var lastErr error
+
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		retry, err := s.doOnce(ctx, method, path, body, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+// doOnce sends a single request, reporting whether a failure is retryable
+func (s *Signer) doOnce(ctx context.Context, method string, path string, body []byte, out interface{}) (bool, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.url+path, reader)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 500 {
+		return true, fmt.Errorf("remote signer returned %d: %s", resp.StatusCode, string(respBody))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("remote signer returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return false, nil
+}
+
+// encodeMessage converts typed-data message values into JSON-friendly strings
+func encodeMessage(message map[string]interface{}) map[string]interface{} {
+	encoded := make(map[string]interface{}, len(message))
+	for k, v := range message {
+		switch val := v.(type) {
+		case *big.Int:
+			encoded[k] = val.String()
+		case [32]byte:
+			encoded[k] = "0x" + hex.EncodeToString(val[:])
+		case []byte:
+			encoded[k] = "0x" + hex.EncodeToString(val)
+		case fmt.Stringer:
+			encoded[k] = val.String()
+		default:
+			encoded[k] = v
+		}
+	}
+	return encoded
+}
+
+var _ x402evm.ClientEvmSigner = (*Signer)(nil)
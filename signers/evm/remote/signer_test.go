@@ -0,0 +1,278 @@
+package remote
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	x402evm "x402-go/mechanisms/evm"
+)
+
+const testAddress = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+
+// testPrivateKey is the well-known key for testAddress (first Anvil/Hardhat account)
+const testPrivateKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// signTestDigest signs the test authorization's EIP-712 hash with hexKey, leaving v as the
+// 0/1 recovery id
+func signTestDigest(t *testing.T, hexKey string) string {
+	t.Helper()
+
+	key, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		t.Fatalf("HexToECDSA() failed: %v", err)
+	}
+	domain, types, message := testAuthorization()
+	digest, err := x402evm.HashTypedData(domain, types, "TransferWithAuthorization", message)
+	if err != nil {
+		t.Fatalf("HashTypedData() failed: %v", err)
+	}
+	signature, err := crypto.Sign(digest, key)
+	if err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+	return "0x" + hex.EncodeToString(signature)
+}
+
+// newTestServer serves /address and /sign, failing the first signFailures sign requests with signStatus
+func newTestServer(t *testing.T, signFailures int32, signStatus int) (*httptest.Server, *atomic.Int32, *atomic.Int32) {
+	t.Helper()
+
+	testSignature := signTestDigest(t, testPrivateKey)
+	var addressCalls, signCalls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case AddressPath:
+			addressCalls.Add(1)
+			json.NewEncoder(w).Encode(addressResponse{Address: testAddress})
+		case DefaultSignPath:
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if signCalls.Add(1) <= signFailures {
+				w.WriteHeader(signStatus)
+				return
+			}
+
+			var req signRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if req.PrimaryType != "TransferWithAuthorization" || req.Message["value"] != "1000000" ||
+				req.Message["nonce"] != "0x"+strings.Repeat("00", 31)+"07" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			json.NewEncoder(w).Encode(signResponse{Signature: testSignature})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &addressCalls, &signCalls
+}
+
+func newTestSigner(t *testing.T, url string) *Signer {
+	t.Helper()
+
+	signer, err := NewSigner(context.Background(), &Config{
+		URL:        url,
+		Headers:    map[string]string{"Authorization": "Bearer secret"},
+		RetryDelay: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewSigner() failed: %v", err)
+	}
+	return signer
+}
+
+// testAuthorization returns the EIP-712 domain, types and message of a test EIP-3009 transfer
+func testAuthorization() (x402evm.TypedDataDomain, map[string][]x402evm.TypedDataField, map[string]interface{}) {
+	domain := x402evm.TypedDataDomain{
+		Name:              "USD Coin",
+		Version:           "2",
+		ChainID:           big.NewInt(84532),
+		VerifyingContract: "0x036CbD53842c5426634e7929541eC2318f3dCF7e",
+	}
+	types := map[string][]x402evm.TypedDataField{
+		"TransferWithAuthorization": {
+			{Name: "from", Type: "address"},
+			{Name: "to", Type: "address"},
+			{Name: "value", Type: "uint256"},
+			{Name: "validAfter", Type: "uint256"},
+			{Name: "validBefore", Type: "uint256"},
+			{Name: "nonce", Type: "bytes32"},
+		},
+	}
+	message := map[string]interface{}{
+		"from":        testAddress,
+		"to":          "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+		"value":       big.NewInt(1000000),
+		"validAfter":  big.NewInt(0),
+		"validBefore": big.NewInt(9999999999),
+		"nonce":       [32]byte{31: 7},
+	}
+	return domain, types, message
+}
+
+func signTestAuthorization(signer *Signer, ctx context.Context) ([]byte, error) {
+	domain, types, message := testAuthorization()
+	return signer.SignTypedData(ctx, domain, types, "TransferWithAuthorization", message)
+}
+
+func TestNewSignerCachesAddress(t *testing.T) {
+	server, addressCalls, _ := newTestServer(t, 0, 0)
+	signer := newTestSigner(t, server.URL)
+
+	for i := 0; i < 3; i++ {
+		if addr := signer.Address(); addr != testAddress {
+			t.Errorf("Address() = %s, want %s", addr, testAddress)
+		}
+	}
+	if n := addressCalls.Load(); n != 1 {
+		t.Errorf("address endpoint called %d times, want 1", n)
+	}
+}
+
+func TestNewSignerRequiresAuth(t *testing.T) {
+	server, _, _ := newTestServer(t, 0, 0)
+
+	if _, err := NewSigner(context.Background(), &Config{URL: server.URL}); err == nil {
+		t.Error("expected error without auth headers")
+	}
+	if _, err := NewSigner(context.Background(), nil); err == nil {
+		t.Error("expected error with nil config")
+	}
+}
+
+func TestSignTypedData(t *testing.T) {
+	server, _, signCalls := newTestServer(t, 0, 0)
+	signer := newTestSigner(t, server.URL)
+
+	signature, err := signTestAuthorization(signer, context.Background())
+	if err != nil {
+		t.Fatalf("SignTypedData() failed: %v", err)
+	}
+
+	if len(signature) != 65 {
+		t.Fatalf("signature length = %d, want 65", len(signature))
+	}
+	if v := signature[64]; v != 27 && v != 28 {
+		t.Errorf("v = %d, want 27 or 28", v)
+	}
+	domain, types, message := testAuthorization()
+	digest, _ := x402evm.HashTypedData(domain, types, "TransferWithAuthorization", message)
+	if valid, err := x402evm.VerifyEOASignature(digest, signature, common.HexToAddress(testAddress)); err != nil || !valid {
+		t.Errorf("signature does not recover to %s: %v", testAddress, err)
+	}
+	if n := signCalls.Load(); n != 1 {
+		t.Errorf("sign endpoint called %d times, want 1", n)
+	}
+}
+
+func TestSignTypedDataRejectsOtherSigner(t *testing.T) {
+	// Second Anvil/Hardhat account, not the address the service reports
+	otherSignature := signTestDigest(t, "59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == AddressPath {
+			json.NewEncoder(w).Encode(addressResponse{Address: testAddress})
+			return
+		}
+		json.NewEncoder(w).Encode(signResponse{Signature: otherSignature})
+	}))
+	defer server.Close()
+
+	signer, err := NewSigner(context.Background(), &Config{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewSigner() failed: %v", err)
+	}
+
+	_, err = signTestAuthorization(signer, context.Background())
+	if err == nil || !strings.Contains(err.Error(), "does not recover") {
+		t.Fatalf("expected signer mismatch error, got %v", err)
+	}
+}
+
+func TestSignTypedDataRetries(t *testing.T) {
+	t.Run("retries 5xx", func(t *testing.T) {
+		server, _, signCalls := newTestServer(t, 2, http.StatusServiceUnavailable)
+		signer := newTestSigner(t, server.URL)
+
+		if _, err := signTestAuthorization(signer, context.Background()); err != nil {
+			t.Fatalf("SignTypedData() failed: %v", err)
+		}
+		if n := signCalls.Load(); n != 3 {
+			t.Errorf("sign endpoint called %d times, want 3", n)
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		server, _, signCalls := newTestServer(t, 10, http.StatusInternalServerError)
+		signer := newTestSigner(t, server.URL)
+
+		if _, err := signTestAuthorization(signer, context.Background()); err == nil {
+			t.Fatal("expected error")
+		}
+		if n := signCalls.Load(); n != DefaultMaxRetries+1 {
+			t.Errorf("sign endpoint called %d times, want %d", n, DefaultMaxRetries+1)
+		}
+	})
+
+	t.Run("does not retry 4xx", func(t *testing.T) {
+		server, _, signCalls := newTestServer(t, 10, http.StatusForbidden)
+		signer := newTestSigner(t, server.URL)
+
+		if _, err := signTestAuthorization(signer, context.Background()); err == nil {
+			t.Fatal("expected error")
+		}
+		if n := signCalls.Load(); n != 1 {
+			t.Errorf("sign endpoint called %d times, want 1", n)
+		}
+	})
+}
+
+func TestSignTypedDataContextTimeout(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == AddressPath {
+			json.NewEncoder(w).Encode(addressResponse{Address: testAddress})
+			return
+		}
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+
+	signer, err := NewSigner(context.Background(), &Config{URL: server.URL})
+	if err != nil {
+		t.Fatalf("NewSigner() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := signTestAuthorization(signer, ctx); err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("SignTypedData() took %v, expected to honor the context deadline", elapsed)
+	}
+}
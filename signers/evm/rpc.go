@@ -0,0 +1,172 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultRPCCallTimeout bounds each RPC call made to send a transaction or poll its receipt
+const DefaultRPCCallTimeout = 10 * time.Second
+
+// ErrRPCTimeout is returned when a single RPC call outlives RPCConfig.CallTimeout. The error
+// names the JSON-RPC method that timed out.
+var ErrRPCTimeout = errors.New("rpc call timed out")
+
+// RPCConfig bounds the RPC calls a signer makes.
+// The zero value gives each call DefaultRPCCallTimeout.
+type RPCConfig struct {
+	// CallTimeout bounds each call within the caller's context, so one hung call (chain ID,
+	// nonce, gas price, gas estimate, send, receipt poll, contract read, balance, code or
+	// block header) can't use up the caller's whole budget (defaults to 10 seconds)
+	CallTimeout time.Duration
+}
+
+// RPCClient is the RPC surface used to read the chain, send transactions and wait for their
+// receipts. *ethclient.Client implements it.
+type RPCClient interface {
+	GasEstimator
+	PendingNonceReader
+	ReceiptReader
+	ChainID(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// TimeoutClient makes every call on an RPCClient with its own timeout
+type TimeoutClient struct {
+	client RPCClient
+	config *RPCConfig
+}
+
+// NewTimeoutClient bounds each call on client by config.CallTimeout. config is read on every
+// call, so a signer can hand out the client before its configuration is final.
+func NewTimeoutClient(client RPCClient, config *RPCConfig) *TimeoutClient {
+	return &TimeoutClient{client: client, config: config}
+}
+
+// call runs fn with a context bounded by the call timeout. When that timeout, rather than
+// the caller's context, ends the call, the error wraps ErrRPCTimeout and names method.
+func (c *TimeoutClient) call(ctx context.Context, method string, fn func(ctx context.Context) error) error {
+	timeout := c.config.CallTimeout
+	if timeout <= 0 {
+		timeout = DefaultRPCCallTimeout
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := fn(callCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s after %s", ErrRPCTimeout, method, timeout)
+	}
+	return err
+}
+
+// ChainID calls eth_chainId
+func (c *TimeoutClient) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	err = c.call(ctx, "eth_chainId", func(ctx context.Context) error {
+		chainID, err = c.client.ChainID(ctx)
+		return err
+	})
+	return chainID, err
+}
+
+// PendingNonceAt calls eth_getTransactionCount for the pending block
+func (c *TimeoutClient) PendingNonceAt(ctx context.Context, account common.Address) (nonce uint64, err error) {
+	err = c.call(ctx, "eth_getTransactionCount", func(ctx context.Context) error {
+		nonce, err = c.client.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+// SuggestGasPrice calls eth_gasPrice
+func (c *TimeoutClient) SuggestGasPrice(ctx context.Context) (gasPrice *big.Int, err error) {
+	err = c.call(ctx, "eth_gasPrice", func(ctx context.Context) error {
+		gasPrice, err = c.client.SuggestGasPrice(ctx)
+		return err
+	})
+	return gasPrice, err
+}
+
+// EstimateGas calls eth_estimateGas
+func (c *TimeoutClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (gas uint64, err error) {
+	err = c.call(ctx, "eth_estimateGas", func(ctx context.Context) error {
+		gas, err = c.client.EstimateGas(ctx, msg)
+		return err
+	})
+	return gas, err
+}
+
+// SendTransaction calls eth_sendRawTransaction
+func (c *TimeoutClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return c.call(ctx, "eth_sendRawTransaction", func(ctx context.Context) error {
+		return c.client.SendTransaction(ctx, tx)
+	})
+}
+
+// TransactionReceipt calls eth_getTransactionReceipt
+func (c *TimeoutClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (receipt *types.Receipt, err error) {
+	err = c.call(ctx, "eth_getTransactionReceipt", func(ctx context.Context) error {
+		receipt, err = c.client.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+// BlockNumber calls eth_blockNumber
+func (c *TimeoutClient) BlockNumber(ctx context.Context) (blockNumber uint64, err error) {
+	err = c.call(ctx, "eth_blockNumber", func(ctx context.Context) error {
+		blockNumber, err = c.client.BlockNumber(ctx)
+		return err
+	})
+	return blockNumber, err
+}
+
+// CallContract calls eth_call
+func (c *TimeoutClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (result []byte, err error) {
+	err = c.call(ctx, "eth_call", func(ctx context.Context) error {
+		result, err = c.client.CallContract(ctx, msg, blockNumber)
+		return err
+	})
+	return result, err
+}
+
+// BalanceAt calls eth_getBalance
+func (c *TimeoutClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (balance *big.Int, err error) {
+	err = c.call(ctx, "eth_getBalance", func(ctx context.Context) error {
+		balance, err = c.client.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return balance, err
+}
+
+// CodeAt calls eth_getCode
+func (c *TimeoutClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) (code []byte, err error) {
+	err = c.call(ctx, "eth_getCode", func(ctx context.Context) error {
+		code, err = c.client.CodeAt(ctx, account, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+// HeaderByNumber calls eth_getBlockByNumber, for the latest block when number is nil
+func (c *TimeoutClient) HeaderByNumber(ctx context.Context, number *big.Int) (header *types.Header, err error) {
+	err = c.call(ctx, "eth_getBlockByNumber", func(ctx context.Context) error {
+		header, err = c.client.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+var _ RPCClient = (*TimeoutClient)(nil)
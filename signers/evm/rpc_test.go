@@ -0,0 +1,175 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	x402evm "x402-go/mechanisms/evm"
+)
+
+// hangingRPCClient answers every call immediately except hangs, which blocks until its context ends
+type hangingRPCClient struct {
+	hangs string
+}
+
+func (c *hangingRPCClient) wait(ctx context.Context, method string) error {
+	if method != c.hangs {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (c *hangingRPCClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(8453), c.wait(ctx, "ChainID")
+}
+
+func (c *hangingRPCClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 7, c.wait(ctx, "PendingNonceAt")
+}
+
+func (c *hangingRPCClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1e9), c.wait(ctx, "SuggestGasPrice")
+}
+
+func (c *hangingRPCClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return 100000, c.wait(ctx, "EstimateGas")
+}
+
+func (c *hangingRPCClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return c.wait(ctx, "SendTransaction")
+}
+
+func (c *hangingRPCClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return nil, c.wait(ctx, "TransactionReceipt")
+}
+
+func (c *hangingRPCClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return 1, c.wait(ctx, "BlockNumber")
+}
+
+func (c *hangingRPCClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return nil, c.wait(ctx, "CallContract")
+}
+
+func (c *hangingRPCClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return big.NewInt(1e18), c.wait(ctx, "BalanceAt")
+}
+
+func (c *hangingRPCClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return nil, c.wait(ctx, "CodeAt")
+}
+
+func (c *hangingRPCClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1)}, c.wait(ctx, "HeaderByNumber")
+}
+
+func TestTimeoutClient(t *testing.T) {
+	config := &RPCConfig{CallTimeout: 10 * time.Millisecond}
+
+	t.Run("names the call that timed out", func(t *testing.T) {
+		client := NewTimeoutClient(&hangingRPCClient{hangs: "PendingNonceAt"}, config)
+
+		if _, err := client.ChainID(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		_, err := client.PendingNonceAt(context.Background(), common.Address{})
+		if !errors.Is(err, ErrRPCTimeout) {
+			t.Fatalf("Expected ErrRPCTimeout, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "eth_getTransactionCount") {
+			t.Errorf("Expected the error to name the RPC method, got %v", err)
+		}
+	})
+
+	t.Run("caller deadline is not reported as a call timeout", func(t *testing.T) {
+		client := NewTimeoutClient(&hangingRPCClient{hangs: "SendTransaction"}, &RPCConfig{CallTimeout: time.Minute})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err := client.SendTransaction(ctx, types.NewTransaction(0, common.Address{}, big.NewInt(0), 0, big.NewInt(0), nil))
+		if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrRPCTimeout) {
+			t.Fatalf("Expected the caller's deadline error, got %v", err)
+		}
+	})
+
+	t.Run("gas estimation fails instead of guessing", func(t *testing.T) {
+		client := NewTimeoutClient(&hangingRPCClient{hangs: "EstimateGas"}, config)
+
+		_, _, err := GasConfig{}.Estimate(context.Background(), client, ethereum.CallMsg{})
+		if !errors.Is(err, ErrRPCTimeout) || !strings.Contains(err.Error(), "eth_estimateGas") {
+			t.Fatalf("Expected an eth_estimateGas timeout, got %v", err)
+		}
+	})
+
+	t.Run("nonce manager reports the timeout", func(t *testing.T) {
+		nonces := NewNonceManager(NewTimeoutClient(&hangingRPCClient{hangs: "PendingNonceAt"}, config))
+
+		_, err := nonces.Next(context.Background(), common.Address{})
+		if !errors.Is(err, ErrRPCTimeout) {
+			t.Fatalf("Expected ErrRPCTimeout, got %v", err)
+		}
+	})
+
+	t.Run("signer reads are bounded", func(t *testing.T) {
+		s, err := newMultiKeySigner([]string{testPrivateKeyHex}, &MultiKeySignerConfig{RPC: *config})
+		if err != nil {
+			t.Fatalf("Failed to create signer: %v", err)
+		}
+		ctx := context.Background()
+		token := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+
+		reads := map[string]func() error{
+			"eth_call": func() error {
+				_, err := s.ReadContract(ctx, token, x402evm.ERC20ABI, x402evm.FunctionBalanceOf, common.Address{})
+				return err
+			},
+			"eth_getBalance": func() error {
+				_, err := s.GetBalance(ctx, s.keys[0].address.Hex(), "")
+				return err
+			},
+			"eth_getCode": func() error {
+				_, err := s.GetCode(ctx, token)
+				return err
+			},
+			"eth_getBlockByNumber": func() error {
+				_, err := s.GetBlockTimestamp(ctx)
+				return err
+			},
+		}
+		hangs := map[string]string{
+			"eth_call":             "CallContract",
+			"eth_getBalance":       "BalanceAt",
+			"eth_getCode":          "CodeAt",
+			"eth_getBlockByNumber": "HeaderByNumber",
+		}
+		for method, read := range reads {
+			s.rpcClient = NewTimeoutClient(&hangingRPCClient{hangs: hangs[method]}, &s.rpc)
+			if err := read(); !errors.Is(err, ErrRPCTimeout) || !strings.Contains(err.Error(), method) {
+				t.Errorf("Expected a %s timeout, got %v", method, err)
+			}
+		}
+	})
+
+	t.Run("config changes apply to later calls", func(t *testing.T) {
+		config := &RPCConfig{CallTimeout: time.Minute}
+		client := NewTimeoutClient(&hangingRPCClient{hangs: "BlockNumber"}, config)
+
+		config.CallTimeout = 10 * time.Millisecond
+		start := time.Now()
+		if _, err := client.BlockNumber(context.Background()); !errors.Is(err, ErrRPCTimeout) {
+			t.Fatalf("Expected ErrRPCTimeout, got %v", err)
+		}
+		if time.Since(start) > time.Second {
+			t.Error("Expected the updated timeout to apply")
+		}
+	})
+}
@@ -86,7 +86,7 @@ func (f *SchemeNetworkFacilitator) Verify(ctx context.Context, payload types.Pay
 	// Extract payload fields
 	signature, ok := payload.Payload["signature"].(string)
 	if !ok {
-		return nil, x402.NewVerifyError("missing_signature", "", network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonMissingSignature, "", network, nil)
 	}
 
 	name, ok := payload.Payload["name"].(string)
@@ -102,7 +102,7 @@ func (f *SchemeNetworkFacilitator) Verify(ctx context.Context, payload types.Pay
 	// Check signature
 	expectedSig := fmt.Sprintf("~%s", name)
 	if signature != expectedSig {
-		return nil, x402.NewVerifyError("invalid_signature", signature, network, nil)
+		return nil, x402.NewVerifyError(x402.ReasonInvalidSignature, signature, network, nil)
 	}
 
 	// Check expiration
@@ -132,7 +132,7 @@ func (f *SchemeNetworkFacilitator) Settle(ctx context.Context, payload types.Pay
 		if ve, ok := err.(*x402.VerifyError); ok {
 			return nil, x402.NewSettleError(ve.Reason, ve.Payer, ve.Network, "", ve.Err)
 		}
-		return nil, x402.NewSettleError("verification_failed", "", network, "", err)
+		return nil, x402.NewSettleError(x402.ReasonVerificationFailed, "", network, "", err)
 	}
 
 	// Extract name for transaction message
@@ -2,8 +2,12 @@ package unit_test
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"math/big"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -19,6 +23,7 @@ import (
 // Mock EVM signer for client
 type mockClientEvmSigner struct {
 	address string
+	balance *big.Int // token balance returned by balanceOf; nil when unknown
 }
 
 func (m *mockClientEvmSigner) Address() string {
@@ -101,9 +106,28 @@ func (m *mockClientEvmSigner) ReadContract(
 	functionName string,
 	args ...interface{},
 ) (interface{}, error) {
+	if functionName == evm.FunctionBalanceOf && m.balance != nil {
+		return m.balance, nil
+	}
 	return nil, nil
 }
 
+func (m *mockClientEvmSigner) WriteContract(
+	ctx context.Context,
+	address string,
+	abi []byte,
+	functionName string,
+	args ...interface{},
+) (string, error) {
+	return "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef", nil
+}
+
+func (m *mockClientEvmSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error) {
+	return &evm.TransactionReceipt{
+		Status: evm.TxStatusSuccess,
+	}, nil
+}
+
 // Mock EVM signer for facilitator
 type mockFacilitatorEvmSigner struct {
 	balances map[string]*big.Int
@@ -152,8 +176,11 @@ func (m *mockFacilitatorEvmSigner) ReadContract(
 	args ...interface{},
 ) (interface{}, error) {
 	// Mock authorization state check
-	if functionName == "authorizationState" {
-		// Return false (not used)
+	if functionName == "authorizationState" && len(args) == 2 {
+		// Nonces are keyed by their hex representation
+		if nonce, ok := args[1].([32]byte); ok {
+			return m.nonces["0x"+hex.EncodeToString(nonce[:])], nil
+		}
 		return false, nil
 	}
 	return nil, nil
@@ -374,3 +401,85 @@ func TestEVMDualVersionSupport(t *testing.T) {
 		}
 	})
 }
+
+// TestEVMClientValidityWindow tests that the client honors a configured validity window
+func TestEVMClientValidityWindow(t *testing.T) {
+	ctx := context.Background()
+
+	requirements := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0x9876543210987654321098765432109876543210",
+	}
+
+	tests := []struct {
+		name   string
+		opts   []evmclient.ExactEvmSchemeOption
+		window time.Duration
+	}{
+		{
+			name:   "Default One Hour",
+			window: time.Hour,
+		},
+		{
+			name:   "Custom Fifteen Minutes",
+			opts:   []evmclient.ExactEvmSchemeOption{evmclient.WithValidityWindow(15 * time.Minute)},
+			window: 15 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evmClient := evmclient.NewExactEvmScheme(&mockClientEvmSigner{}, tt.opts...)
+
+			before := time.Now().Unix()
+			payload, err := evmClient.CreatePaymentPayload(ctx, requirements)
+			if err != nil {
+				t.Fatalf("Failed to create payment: %v", err)
+			}
+
+			auth := payload.Payload["authorization"].(map[string]interface{})
+			validBefore, err := strconv.ParseInt(auth["validBefore"].(string), 10, 64)
+			if err != nil {
+				t.Fatalf("Invalid validBefore: %v", err)
+			}
+
+			expected := before + int64(tt.window.Seconds())
+			if validBefore < expected || validBefore > expected+5 {
+				t.Errorf("Expected validBefore near %d, got %d", expected, validBefore)
+			}
+		})
+	}
+}
+
+// TestEVMClientBalanceCheck tests that the client refuses to sign when the payer can't cover the payment
+func TestEVMClientBalanceCheck(t *testing.T) {
+	ctx := context.Background()
+
+	requirements := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0x9876543210987654321098765432109876543210",
+	}
+
+	underfunded := &mockClientEvmSigner{balance: big.NewInt(999999)}
+	_, err := evmclient.NewExactEvmScheme(underfunded).CreatePaymentPayload(ctx, requirements)
+	var paymentErr *x402.PaymentError
+	if !errors.As(err, &paymentErr) || paymentErr.Code != x402.ReasonInsufficientBalance {
+		t.Fatalf("Expected %s error, got %v", x402.ReasonInsufficientBalance, err)
+	}
+
+	// Opting out signs regardless of balance
+	if _, err := evmclient.NewExactEvmScheme(underfunded, evmclient.WithoutBalanceCheck()).CreatePaymentPayload(ctx, requirements); err != nil {
+		t.Errorf("Expected payload without balance check, got %v", err)
+	}
+
+	funded := &mockClientEvmSigner{balance: big.NewInt(1000000)}
+	if _, err := evmclient.NewExactEvmScheme(funded).CreatePaymentPayload(ctx, requirements); err != nil {
+		t.Errorf("Expected payload for funded payer, got %v", err)
+	}
+}
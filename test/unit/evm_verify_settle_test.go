@@ -2,8 +2,14 @@ package unit_test
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
 
 	x402 "x402-go"
 	"x402-go/mechanisms/evm"
@@ -86,3 +92,1455 @@ func TestEVMVerifyAndSettle(t *testing.T) {
 		t.Error("Expected transaction hash")
 	}
 }
+
+// TestEVMVerifyValidityWindow tests that authorizations outside their validity window are rejected
+func TestEVMVerifyValidityWindow(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), nil)
+
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	tests := []struct {
+		name        string
+		validAfter  string
+		validBefore string
+		wantReason  string
+	}{
+		{
+			name:        "expired authorization",
+			validAfter:  "0",
+			validBefore: strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10),
+			wantReason:  "authorization_expired",
+		},
+		{
+			name:        "not yet valid authorization",
+			validAfter:  strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10),
+			validBefore: strconv.FormatInt(time.Now().Add(2*time.Hour).Unix(), 10),
+			wantReason:  "authorization_not_yet_valid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+			if err != nil {
+				t.Fatalf("Failed to create payload: %v", err)
+			}
+
+			auth := payload.Payload["authorization"].(map[string]interface{})
+			auth["validAfter"] = tt.validAfter
+			auth["validBefore"] = tt.validBefore
+
+			_, err = evmFacilitator.Verify(ctx, payload, req)
+			if err == nil {
+				t.Fatal("Expected verification to fail")
+			}
+
+			var ve *x402.VerifyError
+			if !errors.As(err, &ve) {
+				t.Fatalf("Expected VerifyError, got %T", err)
+			}
+			if ve.Reason != tt.wantReason {
+				t.Errorf("Expected reason %s, got %s", tt.wantReason, ve.Reason)
+			}
+		})
+	}
+}
+
+// TestEVMVerifyNonceAlreadyUsed tests that replayed authorizations are rejected during verification
+func TestEVMVerifyNonceAlreadyUsed(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	facilitatorSigner := newMockFacilitatorEvmSigner()
+	evmFacilitator := evmfacilitator.NewExactEvmScheme(facilitatorSigner, nil)
+
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+
+	// First verification succeeds while the nonce is unused
+	if _, err := evmFacilitator.Verify(ctx, payload, req); err != nil {
+		t.Fatalf("Expected verification to succeed, got: %v", err)
+	}
+
+	// Mark the nonce as consumed on-chain
+	nonce := payload.Payload["authorization"].(map[string]interface{})["nonce"].(string)
+	facilitatorSigner.nonces[nonce] = true
+
+	_, err = evmFacilitator.Verify(ctx, payload, req)
+	if err == nil {
+		t.Fatal("Expected verification to fail for used nonce")
+	}
+
+	var ve *x402.VerifyError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Expected VerifyError, got %T", err)
+	}
+	if ve.Reason != "nonce_already_used" {
+		t.Errorf("Expected reason nonce_already_used, got %s", ve.Reason)
+	}
+}
+
+// TestEVMBalanceChecks tests the optional balance pre-flight checks on verify and settle
+func TestEVMBalanceChecks(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	tokenAddress := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   tokenAddress,
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+
+	// Payer balance dropped below the authorized value after signing
+	facilitatorSigner := newMockFacilitatorEvmSigner()
+	facilitatorSigner.balances[clientSigner.Address()+":"+tokenAddress] = big.NewInt(500000)
+
+	t.Run("Settle Rejects Underfunded Payer", func(t *testing.T) {
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(facilitatorSigner, &evmfacilitator.ExactEvmSchemeConfig{
+			CheckBalanceBeforeSettle: true,
+		})
+
+		_, err := evmFacilitator.Settle(ctx, payload, req)
+		var se *x402.SettleError
+		if !errors.As(err, &se) {
+			t.Fatalf("Expected SettleError, got %v", err)
+		}
+		if se.Reason != "insufficient_balance" {
+			t.Errorf("Expected reason insufficient_balance, got %s", se.Reason)
+		}
+	})
+
+	t.Run("Verify Rejects Underfunded Payer", func(t *testing.T) {
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(facilitatorSigner, &evmfacilitator.ExactEvmSchemeConfig{
+			CheckBalanceOnVerify: true,
+		})
+
+		_, err := evmFacilitator.Verify(ctx, payload, req)
+		var ve *x402.VerifyError
+		if !errors.As(err, &ve) {
+			t.Fatalf("Expected VerifyError, got %v", err)
+		}
+		if ve.Reason != "insufficient_balance" {
+			t.Errorf("Expected reason insufficient_balance, got %s", ve.Reason)
+		}
+	})
+
+	t.Run("Checks Disabled By Default", func(t *testing.T) {
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(facilitatorSigner, nil)
+
+		if _, err := evmFacilitator.Verify(ctx, payload, req); err != nil {
+			t.Errorf("Expected verification to succeed without balance check, got: %v", err)
+		}
+	})
+}
+
+// TestEVMVerifyOverpayment tests that authorizations above the required amount are rejected
+// unless the facilitator allows overpayment, since settlement transfers the full signed value
+func TestEVMVerifyOverpayment(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	// Sign an authorization for 100x the required amount so the signature stays valid, while
+	// the payload still accepts the offered terms
+	overpaid := req
+	overpaid.Amount = "100000000"
+
+	payload, err := client.CreatePaymentPayload(ctx, overpaid, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+	payload.Accepted = req
+
+	t.Run("Rejected By Default", func(t *testing.T) {
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), nil)
+
+		_, err := evmFacilitator.Verify(ctx, payload, req)
+		var ve *x402.VerifyError
+		if !errors.As(err, &ve) {
+			t.Fatalf("Expected VerifyError, got %v", err)
+		}
+		if ve.Reason != "amount_exceeds_required" {
+			t.Errorf("Expected reason amount_exceeds_required, got %s", ve.Reason)
+		}
+	})
+
+	t.Run("Settle Refuses Overpayment", func(t *testing.T) {
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), nil)
+
+		_, err := evmFacilitator.Settle(ctx, payload, req)
+		var se *x402.SettleError
+		if !errors.As(err, &se) {
+			t.Fatalf("Expected SettleError, got %v", err)
+		}
+		if se.Reason != "amount_exceeds_required" {
+			t.Errorf("Expected reason amount_exceeds_required, got %s", se.Reason)
+		}
+	})
+
+	t.Run("Accepted When Allowed", func(t *testing.T) {
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), &evmfacilitator.ExactEvmSchemeConfig{
+			AllowOverpayment: true,
+		})
+
+		if _, err := evmFacilitator.Verify(ctx, payload, req); err != nil {
+			t.Errorf("Expected verification to succeed with overpayment allowed, got: %v", err)
+		}
+	})
+}
+
+// TestEVMVerifyMinimumAmount tests that zero-value payments are rejected unless allowed, and
+// that payments below a configured minimum are rejected as dust
+func TestEVMVerifyMinimumAmount(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	newPayload := func(t *testing.T, amount string) (types.PaymentPayload, types.PaymentRequirements) {
+		t.Helper()
+		req := types.PaymentRequirements{
+			Scheme:  evm.SchemeExact,
+			Network: "eip155:8453",
+			Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+			Amount:  amount,
+			PayTo:   "0xabcdef1234567890123456789012345678901234",
+		}
+		payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to create payload: %v", err)
+		}
+		return payload, req
+	}
+
+	expectReason := func(t *testing.T, err error, reason string) {
+		t.Helper()
+		var ve *x402.VerifyError
+		if !errors.As(err, &ve) {
+			t.Fatalf("Expected VerifyError, got %v", err)
+		}
+		if ve.Reason != reason {
+			t.Errorf("Expected reason %s, got %s", reason, ve.Reason)
+		}
+	}
+
+	t.Run("Zero Rejected By Default", func(t *testing.T) {
+		payload, req := newPayload(t, "0")
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), nil)
+
+		_, err := evmFacilitator.Verify(ctx, payload, req)
+		expectReason(t, err, "zero_amount")
+	})
+
+	t.Run("Zero Accepted When Allowed", func(t *testing.T) {
+		payload, req := newPayload(t, "0")
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), &evmfacilitator.ExactEvmSchemeConfig{
+			AllowZeroAmount: true,
+		})
+
+		if _, err := evmFacilitator.Verify(ctx, payload, req); err != nil {
+			t.Errorf("Expected verification to succeed with zero amounts allowed, got: %v", err)
+		}
+	})
+
+	minimums := []evm.MinAmount{
+		{Network: "base", Amount: big.NewInt(100)},
+		{Network: "eip155:8453", Asset: "USDC", Amount: big.NewInt(1000)},
+	}
+
+	t.Run("Dust Rejected", func(t *testing.T) {
+		payload, req := newPayload(t, "999")
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), &evmfacilitator.ExactEvmSchemeConfig{
+			MinAmounts: minimums,
+		})
+
+		_, err := evmFacilitator.Verify(ctx, payload, req)
+		expectReason(t, err, "amount_below_minimum")
+	})
+
+	t.Run("Minimum Accepted", func(t *testing.T) {
+		payload, req := newPayload(t, "1000")
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), &evmfacilitator.ExactEvmSchemeConfig{
+			MinAmounts: minimums,
+		})
+
+		if _, err := evmFacilitator.Verify(ctx, payload, req); err != nil {
+			t.Errorf("Expected verification to succeed at the minimum, got: %v", err)
+		}
+	})
+}
+
+// simulatingFacilitatorEvmSigner is a mock facilitator signer that can simulate contract calls,
+// counting the transactions it would actually send
+type simulatingFacilitatorEvmSigner struct {
+	*mockFacilitatorEvmSigner
+	revert    error
+	simulated []string
+	writes    int
+}
+
+func (m *simulatingFacilitatorEvmSigner) SimulateContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) error {
+	m.simulated = append(m.simulated, functionName)
+	return m.revert
+}
+
+func (m *simulatingFacilitatorEvmSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	m.writes++
+	return m.mockFacilitatorEvmSigner.WriteContract(ctx, address, abi, functionName, args...)
+}
+
+// TestEVMSimulateSettle tests that settlement simulation runs the settle checks and an eth_call
+// of the settlement without sending any transaction
+func TestEVMSimulateSettle(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+
+	t.Run("Would Succeed", func(t *testing.T) {
+		signer := &simulatingFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner()}
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+		result, err := evmFacilitator.SimulateSettle(ctx, payload, req)
+		if err != nil {
+			t.Fatalf("Expected simulation to succeed, got: %v", err)
+		}
+		if !result.Success || result.Transaction != "" || result.Payer != clientSigner.Address() {
+			t.Errorf("Unexpected simulation result: %+v", result)
+		}
+		if len(signer.simulated) != 1 || signer.simulated[0] != evm.FunctionSettlePayment {
+			t.Errorf("Expected settlePayment to be simulated, got %v", signer.simulated)
+		}
+		if signer.writes != 0 {
+			t.Errorf("Expected no transactions, got %d", signer.writes)
+		}
+	})
+
+	t.Run("Reports Decoded Revert", func(t *testing.T) {
+		signer := &simulatingFacilitatorEvmSigner{
+			mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner(),
+			revert:                   &evm.RevertError{Reason: "FiatToken: authorization is used or canceled"},
+		}
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+		_, err := evmFacilitator.SimulateSettle(ctx, payload, req)
+		var se *x402.SettleError
+		if !errors.As(err, &se) {
+			t.Fatalf("Expected SettleError, got %v", err)
+		}
+		if se.Reason != x402.ReasonTransactionSimulationFailed {
+			t.Errorf("Expected reason %s, got %s", x402.ReasonTransactionSimulationFailed, se.Reason)
+		}
+		var revertErr *evm.RevertError
+		if !errors.As(err, &revertErr) || revertErr.Reason != "FiatToken: authorization is used or canceled" {
+			t.Errorf("Expected the decoded revert reason, got %v", err)
+		}
+	})
+
+	t.Run("Runs Settle Checks", func(t *testing.T) {
+		signer := &simulatingFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner()}
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+		underpaid := req
+		underpaid.Amount = "2000000"
+		_, err := evmFacilitator.SimulateSettle(ctx, payload, underpaid)
+		var se *x402.SettleError
+		if !errors.As(err, &se) || se.Reason != x402.ReasonAcceptedMismatch {
+			t.Fatalf("Expected accepted_mismatch, got %v", err)
+		}
+		if len(signer.simulated) != 0 {
+			t.Errorf("Expected nothing to be simulated, got %v", signer.simulated)
+		}
+	})
+
+	t.Run("Unsupported Without Simulating Signer", func(t *testing.T) {
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), nil)
+
+		_, err := evmFacilitator.SimulateSettle(ctx, payload, req)
+		var se *x402.SettleError
+		if !errors.As(err, &se) || se.Reason != x402.ReasonSimulationNotSupported {
+			t.Fatalf("Expected simulation_not_supported, got %v", err)
+		}
+	})
+}
+
+// transferLogFacilitatorEvmSigner is a mock facilitator signer whose receipt for the settlement
+// transaction carries logs, recording the ERC-20 transfers it sends
+type transferLogFacilitatorEvmSigner struct {
+	*mockFacilitatorEvmSigner
+	settlement string
+	logs       []evm.ReceiptLog
+	transfers  [][]interface{}
+}
+
+func (m *transferLogFacilitatorEvmSigner) WaitForTransactionReceipt(ctx context.Context, txHash string) (*evm.TransactionReceipt, error) {
+	if txHash == m.settlement {
+		return &evm.TransactionReceipt{Status: evm.TxStatusSuccess, TxHash: txHash, Logs: m.logs}, nil
+	}
+	return m.mockFacilitatorEvmSigner.WaitForTransactionReceipt(ctx, txHash)
+}
+
+func (m *transferLogFacilitatorEvmSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	if functionName == evm.FunctionTransfer {
+		m.transfers = append(m.transfers, args)
+	}
+	return m.mockFacilitatorEvmSigner.WriteContract(ctx, address, abi, functionName, args...)
+}
+
+// TestEVMRefund tests that refunds are limited to the facilitator's own settlements, validate
+// them on-chain and repay them once
+func TestEVMRefund(t *testing.T) {
+	ctx := context.Background()
+
+	token := common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913")
+	payer := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	payee := common.HexToAddress("0xabcdef1234567890123456789012345678901234")
+	settlement := "0xfeedfeedfeedfeedfeedfeedfeedfeedfeedfeedfeedfeedfeedfeedfeedfeed"
+	settled := &x402.SettleResponse{Success: true, Transaction: settlement, Network: "eip155:8453", Payer: payer.Hex()}
+
+	newSigner := func() *transferLogFacilitatorEvmSigner {
+		return &transferLogFacilitatorEvmSigner{
+			mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner(),
+			settlement:               settlement,
+			logs: []evm.ReceiptLog{{
+				Address: token.Hex(),
+				Topics: []string{
+					evm.ERC20TransferEventTopic,
+					common.BytesToHash(payer.Bytes()).Hex(),
+					common.BytesToHash(payee.Bytes()).Hex(),
+				},
+				Data:  common.LeftPadBytes(big.NewInt(1000000).Bytes(), 32),
+				Index: 2,
+			}},
+		}
+	}
+
+	// newFacilitator returns a facilitator whose ledger records settlement as its own
+	newFacilitator := func(signer evm.FacilitatorEvmSigner, config *evmfacilitator.ExactEvmSchemeConfig) *evmfacilitator.ExactEvmScheme {
+		if config == nil {
+			config = &evmfacilitator.ExactEvmSchemeConfig{}
+		}
+		config.RefundLedger = evmfacilitator.NewMemoryRefundLedger(0)
+		config.RefundLedger.RecordSettlement(settled.Network, settlement)
+		return evmfacilitator.NewExactEvmScheme(signer, config)
+	}
+
+	t.Run("Refunds Once", func(t *testing.T) {
+		signer := newSigner()
+		evmFacilitator := newFacilitator(signer, nil)
+
+		result, err := evmFacilitator.Refund(ctx, settled, "resource unavailable")
+		if err != nil {
+			t.Fatalf("Expected refund to succeed, got: %v", err)
+		}
+		if !result.Success || result.Transaction == "" || result.Payer != payer.Hex() {
+			t.Errorf("Unexpected refund result: %+v", result)
+		}
+		if len(signer.transfers) != 1 {
+			t.Fatalf("Expected 1 transfer, got %d", len(signer.transfers))
+		}
+		if to, ok := signer.transfers[0][0].(common.Address); !ok || to != payer {
+			t.Errorf("Expected a transfer to the payer, got %v", signer.transfers[0][0])
+		}
+		if value, ok := signer.transfers[0][1].(*big.Int); !ok || value.Cmp(big.NewInt(1000000)) != 0 {
+			t.Errorf("Expected a transfer of 1000000, got %v", signer.transfers[0][1])
+		}
+
+		refunds := evmFacilitator.Refunds()
+		if len(refunds) != 1 || refunds[0].Settlement != settlement || refunds[0].Reason != "resource unavailable" {
+			t.Errorf("Unexpected refunds: %+v", refunds)
+		}
+
+		_, err = evmFacilitator.Refund(ctx, settled, "again")
+		var se *x402.SettleError
+		if !errors.As(err, &se) || se.Reason != x402.ReasonAlreadyRefunded {
+			t.Fatalf("Expected already_refunded, got %v", err)
+		}
+	})
+
+	t.Run("Rejects Settlement Without Payer Transfer", func(t *testing.T) {
+		signer := newSigner()
+		signer.logs = nil
+		evmFacilitator := newFacilitator(signer, nil)
+
+		_, err := evmFacilitator.Refund(ctx, settled, "")
+		var se *x402.SettleError
+		if !errors.As(err, &se) || se.Reason != x402.ReasonSettlementNotFound {
+			t.Fatalf("Expected settlement_not_found, got %v", err)
+		}
+		if len(signer.transfers) != 0 {
+			t.Errorf("Expected no transfer, got %d", len(signer.transfers))
+		}
+	})
+
+	t.Run("Rejects Settlement Of Another Facilitator", func(t *testing.T) {
+		signer := newSigner()
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+		_, err := evmFacilitator.Refund(ctx, settled, "")
+		var se *x402.SettleError
+		if !errors.As(err, &se) || se.Reason != x402.ReasonSettlementNotFound {
+			t.Fatalf("Expected settlement_not_found, got %v", err)
+		}
+		if len(signer.transfers) != 0 {
+			t.Errorf("Expected no transfer, got %d", len(signer.transfers))
+		}
+	})
+
+	t.Run("Rejects Transfer Of Unconfigured Token", func(t *testing.T) {
+		signer := newSigner()
+		signer.logs[0].Address = "0x9876543210987654321098765432109876543210"
+		evmFacilitator := newFacilitator(signer, nil)
+
+		_, err := evmFacilitator.Refund(ctx, settled, "")
+		var se *x402.SettleError
+		if !errors.As(err, &se) || se.Reason != x402.ReasonSettlementNotFound {
+			t.Fatalf("Expected settlement_not_found, got %v", err)
+		}
+		if len(signer.transfers) != 0 {
+			t.Errorf("Expected no transfer, got %d", len(signer.transfers))
+		}
+	})
+
+	t.Run("Refunds Own Settlement", func(t *testing.T) {
+		clientSigner := &mockClientEvmSigner{}
+		client := x402.Newx402Client()
+		client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+		req := types.PaymentRequirements{
+			Scheme:  evm.SchemeExact,
+			Network: "eip155:8453",
+			Asset:   token.Hex(),
+			Amount:  "1000000",
+			PayTo:   payee.Hex(),
+		}
+		payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to create payload: %v", err)
+		}
+
+		// The mock sends every transaction with the same hash
+		signer := newSigner()
+		signer.settlement, _ = signer.mockFacilitatorEvmSigner.WriteContract(ctx, "", nil, "")
+		signer.logs[0].Topics[1] = common.BytesToHash(common.HexToAddress(clientSigner.Address()).Bytes()).Hex()
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+		result, err := evmFacilitator.Settle(ctx, payload, req)
+		if err != nil {
+			t.Fatalf("Settle failed: %v", err)
+		}
+		if _, err := evmFacilitator.Refund(ctx, result, ""); err != nil {
+			t.Fatalf("Expected the facilitator's own settlement to be refunded, got: %v", err)
+		}
+		if len(signer.transfers) != 1 {
+			t.Errorf("Expected 1 transfer, got %d", len(signer.transfers))
+		}
+	})
+
+	t.Run("Rejects Underfunded Facilitator", func(t *testing.T) {
+		signer := newSigner()
+		signer.balances[signer.Address()+":"+token.Hex()] = big.NewInt(500000)
+		evmFacilitator := newFacilitator(signer, nil)
+
+		_, err := evmFacilitator.Refund(ctx, settled, "")
+		var se *x402.SettleError
+		if !errors.As(err, &se) || se.Reason != x402.ReasonInsufficientRefundBalance {
+			t.Fatalf("Expected insufficient_refund_balance, got %v", err)
+		}
+
+		// The failed attempt doesn't count as a refund
+		delete(signer.balances, signer.Address()+":"+token.Hex())
+		if _, err := evmFacilitator.Refund(ctx, settled, ""); err != nil {
+			t.Errorf("Expected refund to succeed once funded, got: %v", err)
+		}
+	})
+
+	t.Run("Requires Refund Signer To Be Payee", func(t *testing.T) {
+		evmFacilitator := newFacilitator(newSigner(), &evmfacilitator.ExactEvmSchemeConfig{
+			RefundSigner: &mockClientEvmSigner{},
+		})
+
+		_, err := evmFacilitator.Refund(ctx, settled, "")
+		var se *x402.SettleError
+		if !errors.As(err, &se) || se.Reason != x402.ReasonRecipientMismatch {
+			t.Fatalf("Expected recipient_mismatch, got %v", err)
+		}
+	})
+}
+
+// TestEVMSettlementEvent tests that Settle checks the settlement's transfer event and reports
+// where it was emitted
+func TestEVMSettlementEvent(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	token := common.HexToAddress("0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913")
+	payee := common.HexToAddress("0xabcdef1234567890123456789012345678901234")
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   token.Hex(),
+		Amount:  "1000000",
+		PayTo:   payee.Hex(),
+	}
+
+	payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+
+	// The mock signer sends every transaction with this hash
+	settlement := "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+	transferLog := func(to common.Address, value int64) evm.ReceiptLog {
+		return evm.ReceiptLog{
+			Address: token.Hex(),
+			Topics: []string{
+				evm.ERC20TransferEventTopic,
+				common.BytesToHash(common.HexToAddress(clientSigner.Address()).Bytes()).Hex(),
+				common.BytesToHash(to.Bytes()).Hex(),
+			},
+			Data:  common.LeftPadBytes(big.NewInt(value).Bytes(), 32),
+			Index: 7,
+		}
+	}
+
+	t.Run("Reports Transfer Location", func(t *testing.T) {
+		signer := &transferLogFacilitatorEvmSigner{
+			mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner(),
+			settlement:               settlement,
+			logs:                     []evm.ReceiptLog{transferLog(payee, 1000000)},
+		}
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+		result, err := evmFacilitator.Settle(ctx, payload, req)
+		if err != nil {
+			t.Fatalf("Settle failed: %v", err)
+		}
+		if result.LogIndex == nil || *result.LogIndex != 7 {
+			t.Errorf("Expected log index 7, got %v", result.LogIndex)
+		}
+	})
+
+	t.Run("Rejects Mismatched Transfer", func(t *testing.T) {
+		for name, log := range map[string]evm.ReceiptLog{
+			"recipient": transferLog(common.HexToAddress("0x9999999999999999999999999999999999999999"), 1000000),
+			"value":     transferLog(payee, 1),
+		} {
+			signer := &transferLogFacilitatorEvmSigner{
+				mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner(),
+				settlement:               settlement,
+				logs:                     []evm.ReceiptLog{log},
+			}
+			evmFacilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+			_, err := evmFacilitator.Settle(ctx, payload, req)
+			var se *x402.SettleError
+			if !errors.As(err, &se) || se.Reason != x402.ReasonSettlementEventMismatch {
+				t.Errorf("%s: expected settlement_event_mismatch, got %v", name, err)
+			}
+		}
+	})
+
+	t.Run("Skipped Without Logs", func(t *testing.T) {
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), nil)
+
+		result, err := evmFacilitator.Settle(ctx, payload, req)
+		if err != nil {
+			t.Fatalf("Settle failed: %v", err)
+		}
+		if result.LogIndex != nil {
+			t.Errorf("Expected no log index, got %d", *result.LogIndex)
+		}
+	})
+}
+
+// countingRateLimiter is a PayerRateLimiter allowing a fixed number of requests, recording the
+// payers it was asked about
+type countingRateLimiter struct {
+	remaining int
+	payers    []string
+}
+
+func (l *countingRateLimiter) Allow(payer string) bool {
+	l.payers = append(l.payers, payer)
+	l.remaining--
+	return l.remaining >= 0
+}
+
+// TestEVMRateLimit tests that the rate limiter is consulted with the payer once the signature
+// verifies and rejects requests once exhausted
+func TestEVMRateLimit(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+
+	limiter := &countingRateLimiter{remaining: 1}
+	evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), &evmfacilitator.ExactEvmSchemeConfig{
+		RateLimiter: limiter,
+	})
+
+	// A payload naming the payer without their signature doesn't count against them
+	forged := payload
+	forged.Payload = map[string]interface{}{}
+	for k, v := range payload.Payload {
+		forged.Payload[k] = v
+	}
+	forged.Payload["signature"] = "0x" + strings.Repeat("ab", 65)
+	var ve *x402.VerifyError
+	if _, err := evmFacilitator.Verify(ctx, forged, req); !errors.As(err, &ve) || ve.Reason == x402.ReasonRateLimited {
+		t.Fatalf("Expected a signature failure, got %v", err)
+	}
+	if len(limiter.payers) != 0 {
+		t.Fatalf("Expected an unsigned request not to be counted, got %v", limiter.payers)
+	}
+
+	if _, err := evmFacilitator.Verify(ctx, payload, req); err != nil {
+		t.Fatalf("Expected the first verification to succeed, got: %v", err)
+	}
+
+	_, err = evmFacilitator.Settle(ctx, payload, req)
+	var se *x402.SettleError
+	if !errors.As(err, &se) || se.Reason != x402.ReasonRateLimited {
+		t.Fatalf("Expected rate_limited, got %v", err)
+	}
+	if se.Payer != clientSigner.Address() {
+		t.Errorf("Expected payer %s, got %s", clientSigner.Address(), se.Payer)
+	}
+
+	if len(limiter.payers) != 2 || limiter.payers[0] != clientSigner.Address() {
+		t.Errorf("Expected the payer to be checked twice, got %v", limiter.payers)
+	}
+}
+
+// TestEVMCancelAuthorization tests that the facilitator only relays cancellations of
+// authorizations it verified and hasn't settled
+func TestEVMCancelAuthorization(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	clientScheme := evmclient.NewExactEvmScheme(clientSigner)
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", clientScheme)
+
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	// newVerified returns a facilitator that has verified a fresh payload, and the payload's nonce
+	newVerified := func(t *testing.T, config *evmfacilitator.ExactEvmSchemeConfig) (*evmfacilitator.ExactEvmScheme, types.PaymentPayload, string) {
+		payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to create payload: %v", err)
+		}
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), config)
+		if _, err := evmFacilitator.Verify(ctx, payload, req); err != nil {
+			t.Fatalf("Expected verification to succeed, got: %v", err)
+		}
+		nonce, _ := payload.Payload["authorization"].(map[string]interface{})["nonce"].(string)
+		return evmFacilitator, payload, nonce
+	}
+
+	signCancel := func(t *testing.T, nonce string) evm.ExactEIP3009Cancellation {
+		cancellation, err := clientScheme.SignCancelAuthorization(ctx, "eip155:8453", req.Asset, nonce)
+		if err != nil {
+			t.Fatalf("Failed to sign cancellation: %v", err)
+		}
+		return *cancellation
+	}
+
+	expectReason := func(t *testing.T, err error, reason string) {
+		t.Helper()
+		var se *x402.SettleError
+		if !errors.As(err, &se) || se.Reason != reason {
+			t.Fatalf("Expected %s, got %v", reason, err)
+		}
+	}
+
+	t.Run("verified authorization", func(t *testing.T) {
+		evmFacilitator, _, nonce := newVerified(t, nil)
+
+		result, err := evmFacilitator.CancelAuthorization(ctx, signCancel(t, nonce))
+		if err != nil {
+			t.Fatalf("Expected cancellation to succeed, got: %v", err)
+		}
+		if !result.Success || result.Payer != clientSigner.Address() {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+
+		// The authorization is no longer pending once cancelled
+		_, err = evmFacilitator.CancelAuthorization(ctx, signCancel(t, nonce))
+		expectReason(t, err, x402.ReasonAuthorizationNotVerified)
+	})
+
+	t.Run("unknown nonce", func(t *testing.T) {
+		evmFacilitator, _, _ := newVerified(t, nil)
+
+		unknown := "0x" + strings.Repeat("ab", 32)
+		_, err := evmFacilitator.CancelAuthorization(ctx, signCancel(t, unknown))
+		expectReason(t, err, x402.ReasonAuthorizationNotVerified)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		evmFacilitator, _, nonce := newVerified(t, nil)
+
+		// A signature over another nonce doesn't recover to the authorizer
+		cancellation := signCancel(t, nonce)
+		cancellation.Signature = signCancel(t, "0x"+strings.Repeat("cd", 32)).Signature
+		_, err := evmFacilitator.CancelAuthorization(ctx, cancellation)
+		expectReason(t, err, x402.ReasonInvalidSignature)
+	})
+
+	t.Run("settled authorization", func(t *testing.T) {
+		evmFacilitator, payload, nonce := newVerified(t, nil)
+		if _, err := evmFacilitator.Settle(ctx, payload, req); err != nil {
+			t.Fatalf("Expected settlement to succeed, got: %v", err)
+		}
+
+		_, err := evmFacilitator.CancelAuthorization(ctx, signCancel(t, nonce))
+		expectReason(t, err, x402.ReasonAuthorizationNotVerified)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		limiter := &countingRateLimiter{remaining: 1}
+		evmFacilitator, _, nonce := newVerified(t, &evmfacilitator.ExactEvmSchemeConfig{
+			RateLimiter: limiter,
+		})
+
+		_, err := evmFacilitator.CancelAuthorization(ctx, signCancel(t, nonce))
+		expectReason(t, err, x402.ReasonRateLimited)
+	})
+}
+
+// TestEVMVerifyAcceptedMismatch tests that a payload must accept exactly the requirements it
+// is verified against
+func TestEVMVerifyAcceptedMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+
+	evmFacilitator := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), nil)
+	if _, err := evmFacilitator.Verify(ctx, payload, req); err != nil {
+		t.Fatalf("Expected verification to succeed, got: %v", err)
+	}
+
+	tests := map[string]func(accepted *types.PaymentRequirements){
+		"amount": func(accepted *types.PaymentRequirements) { accepted.Amount = "1" },
+		"asset": func(accepted *types.PaymentRequirements) {
+			accepted.Asset = "0x036CbD53842c5426634e7929541eC2318f3dCF7e"
+		},
+		"payTo": func(accepted *types.PaymentRequirements) {
+			accepted.PayTo = "0x9876543210987654321098765432109876543210"
+		},
+	}
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			tampered := payload
+			tamper(&tampered.Accepted)
+
+			_, err := evmFacilitator.Verify(ctx, tampered, req)
+			var ve *x402.VerifyError
+			if !errors.As(err, &ve) || ve.Reason != x402.ReasonAcceptedMismatch {
+				t.Errorf("Expected accepted_mismatch, got %v", err)
+			}
+		})
+	}
+}
+
+// TestEVMSettleIdempotent tests that settling the same payment again returns the original
+// result without sending another transaction
+func TestEVMSettleIdempotent(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+
+	signer := &simulatingFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner()}
+	evmFacilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+	first, err := evmFacilitator.Settle(ctx, payload, req)
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	second, err := evmFacilitator.Settle(ctx, payload, req)
+	if err != nil {
+		t.Fatalf("Second Settle failed: %v", err)
+	}
+	if second.Transaction != first.Transaction || !second.Success {
+		t.Errorf("Expected the original result, got %+v", second)
+	}
+	if signer.writes != 1 {
+		t.Errorf("Expected 1 transaction, got %d", signer.writes)
+	}
+
+	// Reusing the authorization with other content doesn't hit the cache
+	tampered := payload
+	tampered.Payload = map[string]interface{}{}
+	for k, v := range payload.Payload {
+		tampered.Payload[k] = v
+	}
+	tampered.Payload["signature"] = "0x" + strings.Repeat("ab", 65)
+	if _, err := evmFacilitator.Settle(ctx, tampered, req); err == nil {
+		t.Error("Expected a tampered payload to fail")
+	}
+}
+
+// eoaFacilitatorEvmSigner is a mock facilitator signer for which every address is an EOA, except
+// the facilitator contract unless noContract is set. It records the contract calls it sends.
+type eoaFacilitatorEvmSigner struct {
+	*mockFacilitatorEvmSigner
+	noContract bool
+	calls      []recordedCall
+}
+
+// recordedCall is a contract call sent by a mock signer
+type recordedCall struct {
+	address  string
+	function string
+	args     []interface{}
+}
+
+func (m *eoaFacilitatorEvmSigner) GetCode(ctx context.Context, address string) ([]byte, error) {
+	if !m.noContract && strings.EqualFold(address, evm.FacilitatorContractAddress) {
+		return m.mockFacilitatorEvmSigner.GetCode(ctx, address)
+	}
+	return nil, nil
+}
+
+func (m *eoaFacilitatorEvmSigner) WriteContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (string, error) {
+	m.calls = append(m.calls, recordedCall{address: address, function: functionName, args: args})
+	return m.mockFacilitatorEvmSigner.WriteContract(ctx, address, abi, functionName, args...)
+}
+
+// TestEVMSettleDirectToToken tests that EIP-3009 payments are settled through the token's
+// v,r,s transferWithAuthorization when SettleEOADirectly or SettlementMode asks for it, or
+// when the facilitator contract isn't deployed
+func TestEVMSettleDirectToToken(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	token := "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913"
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   token,
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	settle := func(t *testing.T, signer *eoaFacilitatorEvmSigner, config *evmfacilitator.ExactEvmSchemeConfig) recordedCall {
+		t.Helper()
+
+		// Deployment probes are cached per chain and contract
+		evm.FacilitatorContractCache.Clear()
+		t.Cleanup(evm.FacilitatorContractCache.Clear)
+
+		payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to create payload: %v", err)
+		}
+		result, err := evmfacilitator.NewExactEvmScheme(signer, config).Settle(ctx, payload, req)
+		if err != nil {
+			t.Fatalf("Settle failed: %v", err)
+		}
+		if !result.Success {
+			t.Fatalf("Expected success, got %+v", result)
+		}
+		if len(signer.calls) != 1 {
+			t.Fatalf("Expected 1 transaction, got %d", len(signer.calls))
+		}
+		return signer.calls[0]
+	}
+
+	expectDirect := func(t *testing.T, call recordedCall) {
+		t.Helper()
+
+		if !strings.EqualFold(call.address, token) || call.function != evm.FunctionTransferWithAuthorization {
+			t.Fatalf("Expected transferWithAuthorization on the token, got %s on %s", call.function, call.address)
+		}
+		if len(call.args) != 9 {
+			t.Fatalf("Expected the 9 arguments of the v,r,s overload, got %d", len(call.args))
+		}
+		if v, ok := call.args[6].(uint8); !ok || (v != 27 && v != 28) {
+			t.Errorf("Expected v of 27 or 28, got %v", call.args[6])
+		}
+	}
+
+	t.Run("EOA Directly", func(t *testing.T) {
+		signer := &eoaFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner()}
+		expectDirect(t, settle(t, signer, &evmfacilitator.ExactEvmSchemeConfig{SettleEOADirectly: true}))
+	})
+
+	t.Run("Direct Token Mode", func(t *testing.T) {
+		signer := &eoaFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner()}
+		expectDirect(t, settle(t, signer, &evmfacilitator.ExactEvmSchemeConfig{SettlementMode: evmfacilitator.SettlementModeDirectToken}))
+	})
+
+	t.Run("Auto Without Facilitator Contract", func(t *testing.T) {
+		signer := &eoaFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner(), noContract: true}
+		expectDirect(t, settle(t, signer, nil))
+	})
+
+	t.Run("Through Contract By Default", func(t *testing.T) {
+		signer := &eoaFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner()}
+		call := settle(t, signer, nil)
+
+		if call.function != evm.FunctionSettlePayment {
+			t.Errorf("Expected settlePayment, got %s", call.function)
+		}
+	})
+}
+
+// permitClientEvmSigner is a mock client signer for a token without EIP-3009 that supports
+// EIP-2612 permits, with no allowance granted to the facilitator contract yet
+type permitClientEvmSigner struct {
+	*mockClientEvmSigner
+	nonce *big.Int
+}
+
+func (m *permitClientEvmSigner) ReadContract(ctx context.Context, address string, abi []byte, functionName string, args ...interface{}) (interface{}, error) {
+	switch functionName {
+	case evm.FunctionAuthorizationState, evm.FunctionTransferWithAuthorization:
+		return nil, errors.New("execution reverted")
+	case evm.FunctionDomainSeparator:
+		return [32]byte{}, nil
+	case evm.FunctionNonces:
+		return m.nonce, nil
+	case evm.FunctionAllowance:
+		return big.NewInt(0), nil
+	}
+	return m.mockClientEvmSigner.ReadContract(ctx, address, abi, functionName, args...)
+}
+
+// TestEVMPermit tests that a permit payment signed by the client verifies and settles through
+// the facilitator, and that a permit for the wrong spender, past its deadline, with a nonce it
+// wasn't signed with or below the payment is rejected
+func TestEVMPermit(t *testing.T) {
+	ctx := context.Background()
+
+	// Support probes are cached per chain and token
+	evm.EIP3009SupportCache.Clear()
+	t.Cleanup(evm.EIP3009SupportCache.Clear)
+
+	clientSigner := &permitClientEvmSigner{mockClientEvmSigner: &mockClientEvmSigner{}, nonce: big.NewInt(7)}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	token := "0x50c5725949A6F0c72E6C4a641F24049A917DB0Cb" // DAI on Base
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   token,
+		Amount:  "1000000000000000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+	if payload.Payload["type"] != evm.PayloadTypePermit {
+		t.Fatalf("Expected a permit payload, got %v", payload.Payload["type"])
+	}
+	permit, _ := payload.Payload["permit"].(map[string]interface{})
+	if permit["nonce"] != "7" || !strings.EqualFold(permit["spender"].(string), evm.FacilitatorContractAddress) {
+		t.Fatalf("Unexpected permit: %v", permit)
+	}
+
+	t.Run("verify and settle", func(t *testing.T) {
+		signer := &eoaFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner()}
+		evmFacilitator := evmfacilitator.NewExactEvmScheme(signer, nil)
+
+		if _, err := evmFacilitator.Verify(ctx, payload, req); err != nil {
+			t.Fatalf("Expected verification to succeed, got: %v", err)
+		}
+		result, err := evmFacilitator.Settle(ctx, payload, req)
+		if err != nil {
+			t.Fatalf("Settle failed: %v", err)
+		}
+		if !result.Success {
+			t.Fatalf("Expected success, got %+v", result)
+		}
+
+		// The permit is submitted to the token before the settlement relies on its allowance
+		if len(signer.calls) != 2 {
+			t.Fatalf("Expected 2 transactions, got %d", len(signer.calls))
+		}
+		if call := signer.calls[0]; call.function != evm.FunctionPermit || !strings.EqualFold(call.address, token) {
+			t.Errorf("Expected permit on the token, got %s on %s", call.function, call.address)
+		}
+		if call := signer.calls[1]; call.function != evm.FunctionSettlePayment {
+			t.Errorf("Expected settlePayment, got %s", call.function)
+		}
+	})
+
+	tests := map[string]struct {
+		tamper func(permit map[string]interface{})
+		reason string
+	}{
+		"wrong spender": {
+			tamper: func(permit map[string]interface{}) {
+				permit["spender"] = "0x9876543210987654321098765432109876543210"
+			},
+			reason: x402.ReasonPermitSpenderMismatch,
+		},
+		"expired deadline": {
+			tamper: func(permit map[string]interface{}) { permit["deadline"] = "1" },
+			reason: x402.ReasonPermitExpired,
+		},
+		"bad nonce": {
+			tamper: func(permit map[string]interface{}) { permit["nonce"] = "8" },
+			reason: x402.ReasonInvalidPermitSignature,
+		},
+		"insufficient value": {
+			tamper: func(permit map[string]interface{}) { permit["value"] = "1" },
+			reason: x402.ReasonInsufficientPermitValue,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tampered := payload
+			tampered.Payload = make(map[string]interface{}, len(payload.Payload))
+			for k, v := range payload.Payload {
+				tampered.Payload[k] = v
+			}
+			tamperedPermit := make(map[string]interface{}, len(permit))
+			for k, v := range permit {
+				tamperedPermit[k] = v
+			}
+			tt.tamper(tamperedPermit)
+			tampered.Payload["permit"] = tamperedPermit
+
+			signer := &eoaFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner()}
+			_, err := evmfacilitator.NewExactEvmScheme(signer, nil).Verify(ctx, tampered, req)
+			var ve *x402.VerifyError
+			if !errors.As(err, &ve) || ve.Reason != tt.reason {
+				t.Errorf("Expected %s, got %v", tt.reason, err)
+			}
+		})
+	}
+}
+
+// unreachableFacilitatorEvmSigner is a mock facilitator signer whose RPC endpoint is down
+type unreachableFacilitatorEvmSigner struct {
+	*mockFacilitatorEvmSigner
+}
+
+func (m *unreachableFacilitatorEvmSigner) GetChainID(ctx context.Context) (*big.Int, error) {
+	return nil, errors.New("connection refused")
+}
+
+// TestEVMHealthCheck tests that the exact EVM facilitator reports its RPC, facilitator
+// contract and signer gas balance
+func TestEVMHealthCheck(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("healthy", func(t *testing.T) {
+		config := &evmfacilitator.ExactEvmSchemeConfig{MinSignerBalance: big.NewInt(1000)}
+		health := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), config).HealthCheck(ctx, "eip155:8453")
+
+		if !health.Healthy || !health.RPCReachable {
+			t.Fatalf("Expected a healthy network, got %+v", health)
+		}
+		if health.ContractDeployed == nil || !*health.ContractDeployed {
+			t.Errorf("Expected the facilitator contract to be reported deployed, got %v", health.ContractDeployed)
+		}
+		if len(health.Signers) != 1 || health.Signers[0].Balance != "10000000000" || health.Signers[0].LowBalance {
+			t.Errorf("Unexpected signers: %+v", health.Signers)
+		}
+	})
+
+	t.Run("low balance", func(t *testing.T) {
+		signer := newMockFacilitatorEvmSigner()
+		signer.balances[signer.Address()+":"] = big.NewInt(10)
+		config := &evmfacilitator.ExactEvmSchemeConfig{MinSignerBalance: big.NewInt(1000)}
+		health := evmfacilitator.NewExactEvmScheme(signer, config).HealthCheck(ctx, "eip155:8453")
+
+		if health.Healthy {
+			t.Fatal("Expected a signer out of gas to make the network unhealthy")
+		}
+		if !health.Signers[0].LowBalance || health.Signers[0].Balance != "10" {
+			t.Errorf("Unexpected signers: %+v", health.Signers)
+		}
+	})
+
+	t.Run("wrong chain", func(t *testing.T) {
+		health := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), nil).HealthCheck(ctx, "eip155:1")
+		if health.Healthy || !health.RPCReachable {
+			t.Errorf("Expected an RPC on another chain to be unhealthy, got %+v", health)
+		}
+	})
+
+	t.Run("missing contract", func(t *testing.T) {
+		signer := &eoaFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner(), noContract: true}
+
+		health := evmfacilitator.NewExactEvmScheme(signer, nil).HealthCheck(ctx, "eip155:8453")
+		if !health.Healthy || health.ContractDeployed == nil || *health.ContractDeployed {
+			t.Errorf("Expected auto mode to report the missing contract without failing, got %+v", health)
+		}
+
+		config := &evmfacilitator.ExactEvmSchemeConfig{SettlementMode: evmfacilitator.SettlementModeFacilitatorContract}
+		health = evmfacilitator.NewExactEvmScheme(signer, config).HealthCheck(ctx, "eip155:8453")
+		if health.Healthy {
+			t.Error("Expected a missing contract to fail when settlements require it")
+		}
+	})
+
+	t.Run("RPC down", func(t *testing.T) {
+		signer := &unreachableFacilitatorEvmSigner{newMockFacilitatorEvmSigner()}
+		health := evmfacilitator.NewExactEvmScheme(signer, nil).HealthCheck(ctx, "eip155:8453")
+
+		if health.Healthy || health.RPCReachable || len(health.Errors) == 0 {
+			t.Errorf("Expected an unreachable RPC to be unhealthy, got %+v", health)
+		}
+		if len(health.Signers) != 1 || health.Signers[0].Balance != "" {
+			t.Errorf("Expected signers listed without balances, got %+v", health.Signers)
+		}
+	})
+}
+
+// warningLogger is an x402.Logger that counts warnings
+type warningLogger struct {
+	x402.Logger
+	warnings []string
+}
+
+func (l *warningLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.warnings = append(l.warnings, msg)
+}
+
+// TestEVMSettleLowGas tests that settlement warns about signers low on gas, and refuses with
+// facilitator_low_gas when configured to
+func TestEVMSettleLowGas(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+
+	settle := func(refuse bool) (*x402.SettleResponse, *warningLogger, *eoaFacilitatorEvmSigner, error) {
+		signer := &eoaFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner()}
+		signer.balances[signer.Address()+":"] = big.NewInt(10)
+		logger := &warningLogger{Logger: x402.NoopLogger()}
+
+		payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to create payload: %v", err)
+		}
+		config := &evmfacilitator.ExactEvmSchemeConfig{
+			MinSignerBalance:     big.NewInt(1000),
+			RefuseSettleOnLowGas: refuse,
+			Logger:               logger,
+		}
+		result, err := evmfacilitator.NewExactEvmScheme(signer, config).Settle(ctx, payload, req)
+		return result, logger, signer, err
+	}
+
+	t.Run("warns", func(t *testing.T) {
+		result, logger, _, err := settle(false)
+		if err != nil || !result.Success {
+			t.Fatalf("Expected the settlement to go through, got %+v, %v", result, err)
+		}
+		if len(logger.warnings) != 1 {
+			t.Errorf("Expected a low gas warning, got %v", logger.warnings)
+		}
+	})
+
+	t.Run("refuses", func(t *testing.T) {
+		_, _, signer, err := settle(true)
+		var settleErr *x402.SettleError
+		if !errors.As(err, &settleErr) || settleErr.Reason != x402.ReasonFacilitatorLowGas {
+			t.Fatalf("Expected %s, got %v", x402.ReasonFacilitatorLowGas, err)
+		}
+		if !errors.Is(err, evm.ErrSignerLowGas) {
+			t.Errorf("Expected the error to wrap evm.ErrSignerLowGas, got %v", err)
+		}
+		if len(signer.calls) != 0 {
+			t.Errorf("Expected nothing sent, got %d transactions", len(signer.calls))
+		}
+	})
+}
+
+// TestEVMVerifyAssetNetworkMismatch tests that Verify rejects assets that aren't tokens on the
+// requirements' network with asset_network_mismatch
+func TestEVMVerifyAssetNetworkMismatch(t *testing.T) {
+	ctx := context.Background()
+
+	clientSigner := &mockClientEvmSigner{}
+	client := x402.Newx402Client()
+	client.Register("eip155:8453", evmclient.NewExactEvmScheme(clientSigner))
+
+	req := types.PaymentRequirements{
+		Scheme:  evm.SchemeExact,
+		Network: "eip155:8453",
+		Asset:   "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		Amount:  "1000000",
+		PayTo:   "0xabcdef1234567890123456789012345678901234",
+	}
+	payload, err := client.CreatePaymentPayload(ctx, req, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create payload: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		asset string
+	}{
+		{name: "CAIP-19 asset on another chain", asset: "eip155:1/erc20:0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"},
+		{name: "token without code on the chain", asset: "0x1111111111111111111111111111111111111111"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evm.AssetDeploymentCache.Clear()
+			t.Cleanup(evm.AssetDeploymentCache.Clear)
+
+			requirements := req
+			requirements.Asset = tt.asset
+			mismatched := payload
+			mismatched.Accepted.Asset = tt.asset
+
+			// Every address but the facilitator contract is an EOA
+			signer := &eoaFacilitatorEvmSigner{mockFacilitatorEvmSigner: newMockFacilitatorEvmSigner()}
+			_, err := evmfacilitator.NewExactEvmScheme(signer, nil).Verify(ctx, mismatched, requirements)
+
+			var verifyErr *x402.VerifyError
+			if !errors.As(err, &verifyErr) || verifyErr.Reason != x402.ReasonAssetNetworkMismatch {
+				t.Fatalf("Expected %s, got %v", x402.ReasonAssetNetworkMismatch, err)
+			}
+		})
+	}
+
+	t.Run("CAIP-19 asset on the network", func(t *testing.T) {
+		requirements := req
+		requirements.Asset = "eip155:8453/erc20:" + req.Asset
+		matching := payload
+		matching.Accepted.Asset = requirements.Asset
+
+		result, err := evmfacilitator.NewExactEvmScheme(newMockFacilitatorEvmSigner(), nil).Verify(ctx, matching, requirements)
+		if err != nil || !result.IsValid {
+			t.Fatalf("Expected a valid payment, got %+v, %v", result, err)
+		}
+	})
+}
@@ -0,0 +1,221 @@
+package x402
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// ============================================================================
+// Tracing
+// ============================================================================
+
+// TraceContextExtension is the extensions key carrying trace context between parties.
+// The resource server puts it in PaymentRequired, the client in its payment payload and the
+// resource server again in the payload it forwards to the facilitator, so a payment shows up
+// as one trace across all three. The value is a propagation carrier (e.g. {"traceparent": "..."}).
+const TraceContextExtension = "traceContext"
+
+// Span attribute keys
+const (
+	AttrNetwork       = "x402.network"
+	AttrScheme        = "x402.scheme"
+	AttrPayer         = "x402.payer"
+	AttrAmount        = "x402.amount"
+	AttrFailureReason = "x402.failure_reason"
+	AttrBatchSize     = "x402.batch_size"
+)
+
+// Tracer creates spans around client, resource server and facilitator operations.
+// Tracing is off unless a Tracer is configured. See tracing/otel for an OpenTelemetry implementation.
+type Tracer interface {
+	// Start starts a span named name as a child of any span in ctx
+	Start(ctx context.Context, name string) (context.Context, Span)
+
+	// Inject writes the trace context of ctx into carrier
+	Inject(ctx context.Context, carrier map[string]string)
+
+	// Extract returns ctx joined to the trace context in carrier.
+	// An active span already in ctx takes precedence over carrier.
+	Extract(ctx context.Context, carrier map[string]string) context.Context
+}
+
+// Span is an operation being traced
+type Span interface {
+	// SetAttribute records a string attribute
+	SetAttribute(key, value string)
+
+	// SetFailure marks the span as failed with a reason; err may be nil
+	SetFailure(reason string, err error)
+
+	// End completes the span
+	End()
+}
+
+// noopSpan is used when no Tracer is configured
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, string) {}
+func (noopSpan) SetFailure(string, error)    {}
+func (noopSpan) End()                        {}
+
+// StartSpan starts a span with tracer, or returns a no-op span when tracer is nil
+func StartSpan(ctx context.Context, tracer Tracer, name string) (context.Context, Span) {
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return tracer.Start(ctx, name)
+}
+
+// InjectTraceContext returns a copy of extensions with the trace context of ctx stored under
+// TraceContextExtension. extensions is returned unchanged when tracer is nil.
+func InjectTraceContext(ctx context.Context, tracer Tracer, extensions map[string]interface{}) map[string]interface{} {
+	if tracer == nil {
+		return extensions
+	}
+
+	carrier := make(map[string]string)
+	tracer.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return extensions
+	}
+
+	result := make(map[string]interface{}, len(extensions)+1)
+	for k, v := range extensions {
+		result[k] = v
+	}
+	traceContext := make(map[string]interface{}, len(carrier))
+	for k, v := range carrier {
+		traceContext[k] = v
+	}
+	result[TraceContextExtension] = traceContext
+	return result
+}
+
+// ExtractTraceContext returns ctx joined to the trace context stored in extensions, if any
+func ExtractTraceContext(ctx context.Context, tracer Tracer, extensions map[string]interface{}) context.Context {
+	if tracer == nil {
+		return ctx
+	}
+	carrier := traceCarrier(extensions[TraceContextExtension])
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return tracer.Extract(ctx, carrier)
+}
+
+// traceCarrier converts a decoded TraceContextExtension value into a carrier
+func traceCarrier(value interface{}) map[string]string {
+	var carrier map[string]string
+	switch v := value.(type) {
+	case map[string]string:
+		carrier = v
+	case map[string]interface{}:
+		carrier = make(map[string]string, len(v))
+		for key, val := range v {
+			if s, ok := val.(string); ok {
+				carrier[key] = s
+			}
+		}
+	}
+	return carrier
+}
+
+// WithTracer traces Verify, Settle and SettleBatch, joining the trace context the resource
+// server sends in the payment payload. A nil tracer turns tracing off again.
+func (f *x402Facilitator) WithTracer(tracer Tracer) *x402Facilitator {
+	f.tracer = tracer
+	return f
+}
+
+// facilitatorRequest holds the fields of a raw facilitator request used for metrics and tracing.
+// V1 and V2 share the scheme and network field names; the amount is maxAmountRequired in V1.
+type facilitatorRequest struct {
+	scheme     string
+	network    string
+	amount     string
+	extensions map[string]interface{}
+}
+
+// parseFacilitatorRequest decodes the labels of a raw request, ignoring anything malformed
+func parseFacilitatorRequest(payloadBytes []byte, requirementsBytes []byte) facilitatorRequest {
+	var requirements struct {
+		Scheme            string `json:"scheme"`
+		Network           string `json:"network"`
+		Amount            string `json:"amount"`
+		MaxAmountRequired string `json:"maxAmountRequired"`
+	}
+	_ = json.Unmarshal(requirementsBytes, &requirements)
+
+	req := facilitatorRequest{
+		scheme:  requirements.Scheme,
+		network: requirements.Network,
+		amount:  requirements.Amount,
+	}
+	if req.amount == "" {
+		req.amount = requirements.MaxAmountRequired
+	}
+
+	var payload struct {
+		Extensions map[string]interface{} `json:"extensions"`
+	}
+	if json.Unmarshal(payloadBytes, &payload) == nil {
+		req.extensions = payload.Extensions
+	}
+
+	return req
+}
+
+// startSpan starts a facilitator span joined to the trace context in the request's payload
+func (f *x402Facilitator) startSpan(ctx context.Context, name string, req facilitatorRequest) (context.Context, Span) {
+	if f.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx = ExtractTraceContext(ctx, f.tracer, req.extensions)
+	ctx, span := f.tracer.Start(ctx, name)
+	setSpanAttribute(span, AttrScheme, req.scheme)
+	setSpanAttribute(span, AttrNetwork, req.network)
+	setSpanAttribute(span, AttrAmount, req.amount)
+	return ctx, span
+}
+
+// endSpan records the payer and failure reason (if any) and ends span
+func endSpan(span Span, payer string, reason string, err error) {
+	setSpanAttribute(span, AttrPayer, payer)
+	if reason != "" {
+		span.SetFailure(reason, err)
+	}
+	span.End()
+}
+
+// setSpanAttribute records value unless it is empty
+func setSpanAttribute(span Span, key, value string) {
+	if value != "" {
+		span.SetAttribute(key, value)
+	}
+}
+
+// paymentErrorReason returns the failure reason for a client-side error, or "" for nil
+func paymentErrorReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	var pe *PaymentError
+	if errors.As(err, &pe) && pe.Code != "" {
+		return pe.Code
+	}
+	return errorMetricReason(err)
+}
+
+// errorPayer returns the payer carried by a VerifyError or SettleError
+func errorPayer(err error) string {
+	var ve *VerifyError
+	if errors.As(err, &ve) {
+		return ve.Payer
+	}
+	var se *SettleError
+	if errors.As(err, &se) {
+		return se.Payer
+	}
+	return ""
+}
@@ -0,0 +1,22 @@
+module x402-go/tracing/otel
+
+go 1.24.0
+
+toolchain go1.24.1
+
+replace x402-go => ../..
+
+require (
+	go.opentelemetry.io/otel v1.32.0
+	go.opentelemetry.io/otel/sdk v1.32.0
+	go.opentelemetry.io/otel/trace v1.32.0
+	x402-go v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	go.opentelemetry.io/otel/metric v1.32.0 // indirect
+	golang.org/x/sys v0.36.0 // indirect
+)
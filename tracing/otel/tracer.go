@@ -0,0 +1,97 @@
+// Package otel traces x402 payments with OpenTelemetry.
+//
+//	tracer := otel.NewTracer(tracerProvider)
+//	client := x402.Newx402Client(x402.WithClientTracer(tracer))
+//	server := x402http.Newx402HTTPResourceServer(routes, x402.WithServerTracer(tracer))
+//	facilitator := x402.Newx402Facilitator().WithTracer(tracer)
+//
+// Trace context is carried between the parties with W3C Trace Context, so a
+// payment shows up as one trace from the client through to settlement.
+package otel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	x402 "x402-go"
+)
+
+// InstrumentationName identifies the spans created by this package
+const InstrumentationName = "x402-go"
+
+// Config customizes the tracer
+type Config struct {
+	// Propagator encodes trace context into x402 extensions (default W3C Trace Context and Baggage)
+	Propagator propagation.TextMapPropagator
+}
+
+// Tracer implements x402.Tracer on top of an OpenTelemetry TracerProvider
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+var _ x402.Tracer = (*Tracer)(nil)
+
+// NewTracer creates a tracer that starts spans from provider
+func NewTracer(provider trace.TracerProvider, config ...*Config) *Tracer {
+	cfg := &Config{}
+	if len(config) > 0 && config[0] != nil {
+		cfg = config[0]
+	}
+
+	propagator := cfg.Propagator
+	if propagator == nil {
+		propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+
+	return &Tracer{
+		tracer:     provider.Tracer(InstrumentationName),
+		propagator: propagator,
+	}
+}
+
+// Start implements x402.Tracer
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, x402.Span) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, &otelSpan{span: span}
+}
+
+// Inject implements x402.Tracer
+func (t *Tracer) Inject(ctx context.Context, carrier map[string]string) {
+	t.propagator.Inject(ctx, propagation.MapCarrier(carrier))
+}
+
+// Extract implements x402.Tracer. A valid span context already in ctx (e.g. from HTTP
+// middleware) is kept, so x402 spans nest under the caller's spans.
+func (t *Tracer) Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	return t.propagator.Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// otelSpan adapts an OpenTelemetry span to x402.Span
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) SetAttribute(key, value string) {
+	s.span.SetAttributes(attribute.String(key, value))
+}
+
+func (s *otelSpan) SetFailure(reason string, err error) {
+	s.span.SetAttributes(attribute.String(x402.AttrFailureReason, reason))
+	if err != nil {
+		s.span.RecordError(err)
+	}
+	s.span.SetStatus(codes.Error, reason)
+}
+
+func (s *otelSpan) End() {
+	s.span.End()
+}
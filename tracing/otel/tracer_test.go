@@ -0,0 +1,72 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	x402 "x402-go"
+)
+
+func TestTracerPropagation(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := NewTracer(provider)
+
+	// Client side: start a span and carry it in the extensions
+	clientCtx, clientSpan := tracer.Start(context.Background(), "client")
+	extensions := x402.InjectTraceContext(clientCtx, tracer, nil)
+	clientSpan.End()
+
+	// Facilitator side: join the trace from the extensions
+	ctx := x402.ExtractTraceContext(context.Background(), tracer, extensions)
+	_, span := tracer.Start(ctx, "facilitator")
+	span.SetAttribute(x402.AttrNetwork, "eip155:8453")
+	span.SetFailure(x402.ReasonInvalidSignature, errors.New("bad signature"))
+	span.End()
+
+	ended := recorder.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(ended))
+	}
+	client, facilitator := ended[0], ended[1]
+
+	if facilitator.SpanContext().TraceID() != client.SpanContext().TraceID() {
+		t.Error("Expected both spans to share a trace")
+	}
+	if facilitator.Parent().SpanID() != client.SpanContext().SpanID() {
+		t.Error("Expected the facilitator span to be a child of the client span")
+	}
+	if facilitator.Status().Code != codes.Error || facilitator.Status().Description != x402.ReasonInvalidSignature {
+		t.Errorf("Unexpected status: %+v", facilitator.Status())
+	}
+
+	attributes := make(map[string]string)
+	for _, attr := range facilitator.Attributes() {
+		attributes[string(attr.Key)] = attr.Value.AsString()
+	}
+	if attributes[x402.AttrNetwork] != "eip155:8453" || attributes[x402.AttrFailureReason] != x402.ReasonInvalidSignature {
+		t.Errorf("Unexpected attributes: %v", attributes)
+	}
+}
+
+func TestTracerExtractKeepsActiveSpan(t *testing.T) {
+	provider := sdktrace.NewTracerProvider()
+	tracer := NewTracer(provider)
+
+	remoteCtx, remote := tracer.Start(context.Background(), "remote")
+	extensions := x402.InjectTraceContext(remoteCtx, tracer, nil)
+	remote.End()
+
+	localCtx, local := tracer.Start(context.Background(), "local")
+	defer local.End()
+
+	ctx := x402.ExtractTraceContext(localCtx, tracer, extensions)
+	if ctx != localCtx {
+		t.Error("Expected the active span to take precedence over the carried trace context")
+	}
+}
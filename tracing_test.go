@@ -0,0 +1,167 @@
+package x402
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"x402-go/types"
+)
+
+// recordedSpan is a span captured by recordingTracer
+type recordedSpan struct {
+	id         string
+	parent     string
+	name       string
+	attributes map[string]string
+	reason     string
+	ended      bool
+}
+
+func (s *recordedSpan) SetAttribute(key, value string)    { s.attributes[key] = value }
+func (s *recordedSpan) SetFailure(reason string, _ error) { s.reason = reason }
+func (s *recordedSpan) End()                              { s.ended = true }
+
+type spanIDKey struct{}
+
+// recordingTracer is a Tracer that records spans and propagates span IDs as "traceparent"
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parent, _ := ctx.Value(spanIDKey{}).(string)
+	span := &recordedSpan{
+		id:         fmt.Sprintf("span-%d", len(t.spans)+1),
+		parent:     parent,
+		name:       name,
+		attributes: make(map[string]string),
+	}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanIDKey{}, span.id), span
+}
+
+func (t *recordingTracer) Inject(ctx context.Context, carrier map[string]string) {
+	if id, ok := ctx.Value(spanIDKey{}).(string); ok {
+		carrier["traceparent"] = id
+	}
+}
+
+func (t *recordingTracer) Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if _, ok := ctx.Value(spanIDKey{}).(string); ok {
+		return ctx
+	}
+	if id := carrier["traceparent"]; id != "" {
+		return context.WithValue(ctx, spanIDKey{}, id)
+	}
+	return ctx
+}
+
+func (t *recordingTracer) span(name string) *recordedSpan {
+	for _, span := range t.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func TestTraceContextPropagation(t *testing.T) {
+	ctx := context.Background()
+	tracer := &recordingTracer{}
+
+	requirements := types.PaymentRequirements{
+		Scheme:  "exact",
+		Network: "eip155:1",
+		Asset:   "USDC",
+		Amount:  "1000000",
+		PayTo:   "0xrecipient",
+	}
+
+	// Client: creates the payload and carries its trace context in the extensions
+	client := Newx402Client(WithClientTracer(tracer))
+	client.Register("eip155:1", &mockSchemeNetworkClientV2{scheme: "exact"})
+
+	routeExtensions := map[string]interface{}{"bazaar": map[string]interface{}{"info": "x"}}
+	payload, err := client.CreatePaymentPayload(ctx, requirements, nil, routeExtensions)
+	if err != nil {
+		t.Fatalf("CreatePaymentPayload failed: %v", err)
+	}
+	if _, ok := routeExtensions[TraceContextExtension]; ok {
+		t.Error("Expected the caller's extensions to be left untouched")
+	}
+	if _, ok := payload.Extensions["bazaar"]; !ok {
+		t.Error("Expected existing extensions to be kept")
+	}
+
+	clientSpan := tracer.span("x402.client.CreatePaymentPayload")
+	if clientSpan == nil || !clientSpan.ended {
+		t.Fatalf("Expected an ended client span, got %+v", clientSpan)
+	}
+	if clientSpan.attributes[AttrNetwork] != "eip155:1" || clientSpan.attributes[AttrAmount] != "1000000" {
+		t.Errorf("Unexpected client span attributes: %v", clientSpan.attributes)
+	}
+
+	// Facilitator: joins the trace carried in the payload
+	facilitator := Newx402Facilitator().WithTracer(tracer)
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{scheme: "exact"})
+
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+	if _, err := facilitator.Verify(ctx, payloadBytes, requirementsBytes); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	verifySpan := tracer.span("x402.facilitator.Verify")
+	if verifySpan == nil || !verifySpan.ended {
+		t.Fatalf("Expected an ended verify span, got %+v", verifySpan)
+	}
+	if verifySpan.parent != clientSpan.id {
+		t.Errorf("Expected verify span to be a child of %s, got parent %q", clientSpan.id, verifySpan.parent)
+	}
+	if verifySpan.attributes[AttrPayer] != "0xmockpayer" || verifySpan.attributes[AttrScheme] != "exact" {
+		t.Errorf("Unexpected verify span attributes: %v", verifySpan.attributes)
+	}
+	if verifySpan.reason != "" {
+		t.Errorf("Expected no failure reason, got %q", verifySpan.reason)
+	}
+}
+
+func TestFacilitatorTracingFailure(t *testing.T) {
+	ctx := context.Background()
+	tracer := &recordingTracer{}
+
+	facilitator := Newx402Facilitator().WithTracer(tracer)
+	facilitator.Register([]Network{"eip155:1"}, &mockSchemeNetworkFacilitator{
+		scheme: "exact",
+		settleFunc: func(ctx context.Context, payload types.PaymentPayload, requirements types.PaymentRequirements) (*SettleResponse, error) {
+			return nil, NewSettleError(ReasonTransactionFailed, "0xpayer", "eip155:1", "0xtx", nil)
+		},
+	})
+
+	requirements := types.PaymentRequirements{Scheme: "exact", Network: "eip155:1", Asset: "USDC", Amount: "5", PayTo: "0xrecipient"}
+	payload := types.PaymentPayload{X402Version: 2, Accepted: requirements, Payload: map[string]interface{}{}}
+	payloadBytes, _ := json.Marshal(payload)
+	requirementsBytes, _ := json.Marshal(requirements)
+
+	if _, err := facilitator.Settle(ctx, payloadBytes, requirementsBytes); err == nil {
+		t.Fatal("Expected settle error")
+	}
+
+	span := tracer.span("x402.facilitator.Settle")
+	if span == nil || !span.ended {
+		t.Fatalf("Expected an ended settle span, got %+v", span)
+	}
+	if span.parent != "" {
+		t.Errorf("Expected a root span without trace context, got parent %q", span.parent)
+	}
+	if span.reason != ReasonTransactionFailed || span.attributes[AttrPayer] != "0xpayer" {
+		t.Errorf("Unexpected settle span: reason %q, attributes %v", span.reason, span.attributes)
+	}
+}
@@ -3,6 +3,8 @@ package x402
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"regexp"
 	"strings"
 
 	"x402-go/types"
@@ -46,9 +48,55 @@ func (n Network) Match(pattern Network) bool {
 	return false
 }
 
-// Price represents a price that can be specified in various formats
+// caip19Pattern matches a CAIP-19 asset type: chain_id "/" asset_namespace ":" asset_reference,
+// with an optional "/" token_id
+var caip19Pattern = regexp.MustCompile(`^([-a-z0-9]{3,8}:[-_a-zA-Z0-9]{1,32})/([-a-z0-9]{3,8}):([-.%a-zA-Z0-9]{1,128})(/[-.%a-zA-Z0-9]{1,78})?$`)
+
+// ParseCAIP19 splits a CAIP-19 asset identifier such as "eip155:8453/erc20:0x833589..." into
+// its CAIP-2 network, asset namespace ("erc20") and asset reference (the token address).
+// Identifiers of individual tokens (with a token ID, e.g. NFTs) are rejected.
+func ParseCAIP19(s string) (network, assetNamespace, assetRef string, err error) {
+	match := caip19Pattern.FindStringSubmatch(s)
+	if match == nil {
+		return "", "", "", fmt.Errorf("invalid CAIP-19 asset identifier: %s", s)
+	}
+	if match[4] != "" {
+		return "", "", "", fmt.Errorf("CAIP-19 asset identifier %s names a single token, not an asset type", s)
+	}
+	return match[1], match[2], match[3], nil
+}
+
+// Price represents a price that can be specified in various formats: Money ("$0.001", 0.001),
+// an AssetAmount map, or a UnitPrice
 type Price interface{}
 
+// UnitPrice is a price in an asset's smallest unit (e.g. 1000000 for 1 USDC). Mechanisms charge it
+// as is, without the dollar conversion applied to Money prices, which suits tokens that aren't
+// pegged to the dollar and prices that must be exact on-chain amounts.
+type UnitPrice struct {
+	// Amount in the asset's smallest unit
+	Amount *big.Int
+
+	// Asset address or symbol (e.g. "USDC"). Empty uses the network's default asset.
+	Asset string
+
+	// Extra is copied into the payment requirements
+	Extra map[string]interface{}
+}
+
+// AsUnitPrice returns price as a UnitPrice when it is one (by value or pointer)
+func AsUnitPrice(price Price) (UnitPrice, bool) {
+	switch v := price.(type) {
+	case UnitPrice:
+		return v, true
+	case *UnitPrice:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return UnitPrice{}, false
+}
+
 // AssetAmount represents an amount of a specific asset
 type AssetAmount struct {
 	Asset  string                 `json:"asset"`
@@ -94,6 +142,55 @@ type SettleResponse struct {
 	Payer       string  `json:"payer,omitempty"`
 	Transaction string  `json:"transaction"`
 	Network     Network `json:"network"`
+
+	// BlockNumber and LogIndex locate the settlement's transfer event, for indexing. They
+	// are unset when the mechanism or signer doesn't report them.
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	LogIndex    *uint  `json:"logIndex,omitempty"`
+}
+
+// HealthStatus is the result of a facilitator HealthCheck
+type HealthStatus struct {
+	// Healthy is true when every checked network is healthy
+	Healthy  bool            `json:"healthy"`
+	Networks []NetworkHealth `json:"networks"`
+}
+
+// NetworkHealth reports whether a mechanism can settle on a network
+type NetworkHealth struct {
+	Network Network `json:"network"`
+	Scheme  string  `json:"scheme"`
+	Healthy bool    `json:"healthy"`
+
+	// RPCReachable is true when the network's RPC endpoint answered
+	RPCReachable bool `json:"rpcReachable"`
+
+	// ContractDeployed reports whether the contract the mechanism settles through has code.
+	// It is nil when the mechanism doesn't use one on this network.
+	ContractDeployed *bool `json:"contractDeployed,omitempty"`
+
+	// Signers lists the gas balance of each signer
+	Signers []SignerHealth `json:"signers,omitempty"`
+
+	// Errors explains why the network is unhealthy
+	Errors []string `json:"errors,omitempty"`
+}
+
+// SignerHealth is the native gas token balance of a facilitator signer
+type SignerHealth struct {
+	Address string `json:"address"`
+
+	// Balance in the native token's smallest unit (e.g. wei), empty if it couldn't be read
+	Balance string `json:"balance,omitempty"`
+
+	// LowBalance is true when the balance is below the mechanism's configured threshold
+	LowBalance bool `json:"lowBalance"`
+}
+
+// SettlementRequest is a single payment submitted to SettleBatch
+type SettlementRequest struct {
+	PayloadBytes      []byte
+	RequirementsBytes []byte
 }
 
 // ResourceConfig defines payment configuration for a protected resource
@@ -88,13 +88,28 @@ func MatchPayloadToRequirements(
 			return false, err
 		}
 
-		return payloadPartial.Accepted.Scheme == req.Scheme &&
-			payloadPartial.Accepted.Network == req.Network &&
-			payloadPartial.Accepted.Amount == req.Amount &&
-			payloadPartial.Accepted.Asset == req.Asset &&
-			payloadPartial.Accepted.PayTo == req.PayTo, nil
+		return AcceptedMatches(
+			PaymentRequirements{
+				Scheme:  payloadPartial.Accepted.Scheme,
+				Network: payloadPartial.Accepted.Network,
+				Amount:  payloadPartial.Accepted.Amount,
+				Asset:   payloadPartial.Accepted.Asset,
+				PayTo:   payloadPartial.Accepted.PayTo,
+			},
+			PaymentRequirements{Scheme: req.Scheme, Network: req.Network, Amount: req.Amount, Asset: req.Asset, PayTo: req.PayTo},
+		), nil
 
 	default:
 		return false, fmt.Errorf("unsupported version: %d", version)
 	}
 }
+
+// AcceptedMatches reports whether the requirements a V2 payload accepted are the given
+// requirements: the same scheme, network, amount, asset and payTo
+func AcceptedMatches(accepted, requirements PaymentRequirements) bool {
+	return accepted.Scheme == requirements.Scheme &&
+		accepted.Network == requirements.Network &&
+		accepted.Amount == requirements.Amount &&
+		accepted.Asset == requirements.Asset &&
+		accepted.PayTo == requirements.PayTo
+}
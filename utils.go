@@ -48,43 +48,49 @@ func ValidatePaymentRequirements(r PaymentRequirements) error {
 	return nil
 }
 
-// findByNetworkAndScheme finds a scheme implementation for a given network/scheme combination
-// This supports pattern matching for networks (e.g., "eip155:*")
-func findByNetworkAndScheme[T any](networkMap map[Network]map[string]T, scheme string, network Network) T {
-	var zero T
-
-	// Try exact match first
-	if schemeMap, exists := networkMap[network]; exists {
-		if impl, exists := schemeMap[scheme]; exists {
-			return impl
-		}
+// registerScheme stores impl for a network/scheme combination. Registering the same scheme
+// for the same network again replaces the earlier implementation (last wins) and logs a warning.
+func registerScheme[T any](networkMap map[Network]map[string]T, network Network, scheme string, impl T, logger Logger) {
+	if networkMap[network] == nil {
+		networkMap[network] = make(map[string]T)
 	}
-
-	// Try pattern matching
-	for registeredNetwork, schemeMap := range networkMap {
-		if network.Match(registeredNetwork) || registeredNetwork.Match(network) {
-			if impl, exists := schemeMap[scheme]; exists {
-				return impl
-			}
-		}
+	if _, exists := networkMap[network][scheme]; exists {
+		logger.Warn("replacing registered scheme", "scheme", scheme, "network", string(network))
 	}
+	networkMap[network][scheme] = impl
+}
 
-	return zero
+// findByNetworkAndScheme finds a scheme implementation for a given network/scheme combination
+// This supports pattern matching for networks (e.g., "eip155:*"); an exact registration wins
+func findByNetworkAndScheme[T any](networkMap map[Network]map[string]T, scheme string, network Network) T {
+	return findSchemesByNetwork(networkMap, network)[scheme]
 }
 
-// findSchemesByNetwork finds all schemes for a given network
+// findSchemesByNetwork finds all schemes for a given network.
+// Schemes registered for the exact network take precedence over those registered for its
+// namespace wildcard (e.g., "eip155:8453" over "eip155:*"), scheme by scheme, so a wildcard
+// still provides the schemes the exact registration doesn't.
 func findSchemesByNetwork[T any](networkMap map[Network]map[string]T, network Network) map[string]T {
-	// Try exact match first
-	if schemeMap, exists := networkMap[network]; exists {
-		return schemeMap
+	exact := networkMap[network]
+
+	var wildcard map[string]T
+	if namespace, _, err := network.Parse(); err == nil && !IsWildcardNetwork(network) {
+		wildcard = networkMap[Network(namespace+":*")]
 	}
 
-	// Try pattern matching
-	for registeredNetwork, schemeMap := range networkMap {
-		if network.Match(registeredNetwork) || registeredNetwork.Match(network) {
-			return schemeMap
-		}
+	if len(wildcard) == 0 {
+		return exact
+	}
+	if len(exact) == 0 {
+		return wildcard
 	}
 
-	return nil
+	merged := make(map[string]T, len(exact)+len(wildcard))
+	for scheme, impl := range wildcard {
+		merged[scheme] = impl
+	}
+	for scheme, impl := range exact {
+		merged[scheme] = impl
+	}
+	return merged
 }
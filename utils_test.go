@@ -271,6 +271,7 @@ func TestFindSchemesByNetwork(t *testing.T) {
 			"exact": "base-exact",
 		},
 		"eip155:*": {
+			"exact":    "any-eip155-exact",
 			"wildcard": "any-eip155",
 		},
 	}
@@ -282,24 +283,27 @@ func TestFindSchemesByNetwork(t *testing.T) {
 		isNil    bool
 	}{
 		{
-			name:    "exact match mainnet",
+			name:    "exact match mainnet overrides wildcard",
 			network: "eip155:1",
 			expected: map[string]string{
 				"exact":    "mainnet-exact",
 				"transfer": "mainnet-transfer",
+				"wildcard": "any-eip155",
 			},
 		},
 		{
 			name:    "exact match base",
 			network: "eip155:8453",
 			expected: map[string]string{
-				"exact": "base-exact",
+				"exact":    "base-exact",
+				"wildcard": "any-eip155",
 			},
 		},
 		{
 			name:    "wildcard match",
 			network: "eip155:137", // Polygon, matches wildcard
 			expected: map[string]string{
+				"exact":    "any-eip155-exact",
 				"wildcard": "any-eip155",
 			},
 		},
@@ -393,6 +397,62 @@ func TestNetworkPatternMatching(t *testing.T) {
 	}
 }
 
+func TestParseCAIP19(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantNetwork   string
+		wantNamespace string
+		wantRef       string
+		wantErr       bool
+	}{
+		{
+			name:          "erc20 on Base",
+			input:         "eip155:8453/erc20:0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+			wantNetwork:   "eip155:8453",
+			wantNamespace: "erc20",
+			wantRef:       "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913",
+		},
+		{
+			name:          "native asset",
+			input:         "eip155:1/slip44:60",
+			wantNetwork:   "eip155:1",
+			wantNamespace: "slip44",
+			wantRef:       "60",
+		},
+		{
+			name:          "solana token",
+			input:         "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp/token:EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+			wantNetwork:   "solana:5eykt4UsFv8P8NJdTREpY1vzqKqZKvdp",
+			wantNamespace: "token",
+			wantRef:       "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		},
+		{name: "plain address", input: "0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", wantErr: true},
+		{name: "asset without chain", input: "erc20:0x833589fCD6eDb6E08f4c7C32D4f71b54bdA02913", wantErr: true},
+		{name: "symbol", input: "USDC", wantErr: true},
+		{name: "missing reference", input: "eip155:8453/erc20:", wantErr: true},
+		{name: "token ID", input: "eip155:1/erc721:0x06012c8cf97BEaD5deAe237070F9587f8E7A266d/771769", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network, namespace, ref, err := ParseCAIP19(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseCAIP19(%q) expected an error", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCAIP19(%q) failed: %v", tt.input, err)
+			}
+			if network != tt.wantNetwork || namespace != tt.wantNamespace || ref != tt.wantRef {
+				t.Errorf("ParseCAIP19(%q) = %q, %q, %q, want %q, %q, %q", tt.input, network, namespace, ref, tt.wantNetwork, tt.wantNamespace, tt.wantRef)
+			}
+		})
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) > 0 && len(s) > len(substr) &&